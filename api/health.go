@@ -0,0 +1,213 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Readiness is the JSON object returned by the readiness endpoint.
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// resolverScoreReporter is implemented by the systems that track the health of the resolvers.
+type resolverScoreReporter interface {
+	ResolverScores() []systems.ResolverScore
+}
+
+// HealthServer serves the liveness, readiness and metrics endpoints that allow the enumeration
+// to be deployed as a long-running service behind an orchestrator and scraped by Prometheus.
+type HealthServer struct {
+	sys   systems.System
+	stats func() enum.Stats
+	srv   *http.Server
+}
+
+// NewHealthServer returns a HealthServer reporting on the enumeration and the System it uses.
+func NewHealthServer(e *enum.Enumeration) *HealthServer {
+	s := &HealthServer{
+		sys:   e.Sys,
+		stats: e.Stats,
+	}
+
+	s.srv = &http.Server{
+		Handler:      s.Handler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the http.Handler that serves the health endpoints.
+func (s *HealthServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", getOnly(handleLiveness))
+	mux.HandleFunc("/readyz", getOnly(s.handleReadiness))
+	mux.HandleFunc("/metrics", getOnly(s.handleMetrics))
+	return mux
+}
+
+// Serve accepts connections on the listener until Close is called.
+func (s *HealthServer) Serve(lis net.Listener) error {
+	if err := s.srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Close stops the HealthServer, waiting for the requests in progress until ctx expires.
+func (s *HealthServer) Close(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func handleLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *HealthServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	ready := s.readiness()
+
+	status := http.StatusOK
+	if !ready.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, ready)
+}
+
+// readiness checks that the resolver pool has resolvers that have not been ejected,
+// and that the data sources have been initialized by the System.
+func (s *HealthServer) readiness() *Readiness {
+	ready := &Readiness{
+		Ready: true,
+		Checks: map[string]string{
+			"resolvers":    "ok",
+			"data_sources": "ok",
+		},
+	}
+
+	if s.sys.Pool() == nil {
+		ready.Ready = false
+		ready.Checks["resolvers"] = "the resolver pool has not been initialized"
+	} else if active, ejected := s.resolverCounts(); active == 0 && ejected > 0 {
+		ready.Ready = false
+		ready.Checks["resolvers"] = "all the resolvers have been ejected from the pool"
+	}
+	if s.numDataSources() == 0 {
+		ready.Ready = false
+		ready.Checks["data_sources"] = "the data sources have not been initialized"
+	}
+	return ready
+}
+
+// resolverCounts returns the resolvers in the pool and those ejected for their low success rate.
+// Both are zero when the System does not track the health of the resolvers.
+func (s *HealthServer) resolverCounts() (int, int) {
+	sys, ok := s.sys.(resolverScoreReporter)
+	if !ok {
+		return 0, 0
+	}
+
+	var active, ejected int
+	for _, score := range sys.ResolverScores() {
+		if score.Ejected {
+			ejected++
+		} else {
+			active++
+		}
+	}
+	return active, ejected
+}
+
+func (s *HealthServer) numDataSources() int {
+	var num int
+
+	for _, src := range s.sys.DataSources() {
+		if src != nil {
+			num++
+		}
+	}
+	return num
+}
+
+func (s *HealthServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	s.writeMetrics(w)
+}
+
+// writeMetrics writes the stats of the enumeration in the Prometheus text exposition format.
+func (s *HealthServer) writeMetrics(w io.Writer) {
+	stats := s.stats()
+
+	writeMetric(w, "amass_names_submitted_total", "counter",
+		"The names and addresses submitted by the data sources.", stats.Submitted)
+	writeMetric(w, "amass_names_processed_total", "counter",
+		"The names and addresses released into the pipeline.", stats.Accepted)
+	writeMetric(w, "amass_queue_depth", "gauge",
+		"The names and addresses waiting to be released into the pipeline.", stats.Queued)
+
+	writeHeader(w, "amass_names_rejected_total", "counter", "The names and addresses rejected before entering the pipeline.")
+	for _, reason := range []struct {
+		label string
+		value int
+	}{
+		{"duplicate", stats.Duplicates},
+		{"out_of_scope", stats.OutOfScope},
+		{"excluded", stats.Excluded},
+		{"untrusted", stats.Untrusted},
+		{"recursion_limited", stats.RecursionLimited},
+		{"shared_duplicate", stats.SharedDuplicates},
+	} {
+		fmt.Fprintf(w, "amass_names_rejected_total{reason=%q} %d\n", reason.label, reason.value)
+	}
+	writeMetric(w, "amass_requests_timed_out_total", "counter",
+		"The names and addresses whose processing exceeded the request timeout.", stats.TimedOut)
+	writeMetric(w, "amass_dnssec_bogus_total", "counter",
+		"The names with records that failed the DNSSEC validation.", stats.DNSSECBogus)
+
+	if len(stats.QueryTypes) > 0 {
+		writeHeader(w, "amass_dns_queries_total", "counter", "The DNS queries sent to the resolvers by record type.")
+		for _, qt := range stats.QueryTypes {
+			fmt.Fprintf(w, "amass_dns_queries_total{type=%q} %d\n", qt.Type, qt.Queries)
+		}
+		writeHeader(w, "amass_resolver_errors_total", "counter",
+			"The DNS queries that timed out, or that the resolvers refused or failed to answer, by record type.")
+		for _, qt := range stats.QueryTypes {
+			fmt.Fprintf(w, "amass_resolver_errors_total{type=%q} %d\n", qt.Type, qt.Failures)
+		}
+	}
+	if c := stats.QueryCache; c != nil {
+		writeMetric(w, "amass_query_cache_hits_total", "counter", "The DNS queries answered from the cache.", c.Hits)
+		writeMetric(w, "amass_query_cache_misses_total", "counter", "The DNS queries sent to the resolvers by the cache.", c.Misses)
+	}
+
+	if active, ejected := s.resolverCounts(); active+ejected > 0 {
+		writeHeader(w, "amass_resolvers", "gauge", "The resolvers in the pool by state.")
+		fmt.Fprintf(w, "amass_resolvers{state=\"active\"} %d\n", active)
+		fmt.Fprintf(w, "amass_resolvers{state=\"ejected\"} %d\n", ejected)
+	}
+	writeMetric(w, "amass_data_sources", "gauge", "The data sources initialized by the system.", s.numDataSources())
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeMetric(w io.Writer, name, kind, help string, value int) {
+	writeHeader(w, name, kind, help)
+	fmt.Fprintf(w, "%s %d\n", name, value)
+}
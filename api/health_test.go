@@ -0,0 +1,121 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/resolve"
+	"github.com/caffix/service"
+)
+
+type healthTestService struct {
+	service.BaseService
+}
+
+type healthTestResolver struct {
+	resolve.Resolver
+}
+
+type scoredSystem struct {
+	systems.SimpleSystem
+	scores []systems.ResolverScore
+}
+
+func (s *scoredSystem) ResolverScores() []systems.ResolverScore { return s.scores }
+
+func setupHealthServer(sys systems.System, stats enum.Stats) *httptest.Server {
+	s := &HealthServer{
+		sys:   sys,
+		stats: func() enum.Stats { return stats },
+	}
+	return httptest.NewServer(s.Handler())
+}
+
+func TestReadiness(t *testing.T) {
+	src := new(healthTestService)
+	src.BaseService = *service.NewBaseService(src, "TestSource")
+
+	sys := &scoredSystem{SimpleSystem: systems.SimpleSystem{Resolver: &healthTestResolver{}}}
+	ts := setupHealthServer(sys, enum.Stats{})
+	defer ts.Close()
+
+	var live map[string]string
+	if code := getJSON(t, ts.URL+"/healthz", &live); code != http.StatusOK || live["status"] != "ok" {
+		t.Errorf("the liveness endpoint returned the %d status code and %v", code, live)
+	}
+
+	var ready Readiness
+	if code := getJSON(t, ts.URL+"/readyz", &ready); code != http.StatusServiceUnavailable ||
+		ready.Ready || ready.Checks["resolvers"] != "ok" || ready.Checks["data_sources"] == "ok" {
+		t.Errorf("the system without data sources was reported as ready: %d %v", code, ready)
+	}
+
+	sys.Service = src
+	if code := getJSON(t, ts.URL+"/readyz", &ready); code != http.StatusOK || !ready.Ready {
+		t.Errorf("the initialized system was not reported as ready: %d %v", code, ready)
+	}
+
+	sys.scores = []systems.ResolverScore{{Resolver: "192.0.2.53:53", Ejected: true}}
+	if code := getJSON(t, ts.URL+"/readyz", &ready); code != http.StatusServiceUnavailable || ready.Checks["resolvers"] == "ok" {
+		t.Errorf("the system with all the resolvers ejected was reported as ready: %d %v", code, ready)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	sys := &scoredSystem{
+		SimpleSystem: systems.SimpleSystem{Resolver: &healthTestResolver{}},
+		scores: []systems.ResolverScore{
+			{Resolver: "192.0.2.53:53"},
+			{Resolver: "192.0.2.54:53", Ejected: true},
+		},
+	}
+	ts := setupHealthServer(sys, enum.Stats{
+		Submitted:  12,
+		Accepted:   10,
+		Queued:     3,
+		Duplicates: 2,
+		QueryTypes: []systems.QueryTypeStats{
+			{Type: "A", Queries: 20, Failures: 4},
+			{Type: "TXT", Queries: 5, Failures: 1},
+		},
+	})
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to request the metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != metricsContentType {
+		t.Errorf("the metrics were served with the %s content type", ct)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	metrics := string(body)
+
+	for _, want := range []string{
+		"# TYPE amass_names_processed_total counter\namass_names_processed_total 10\n",
+		"# TYPE amass_queue_depth gauge\namass_queue_depth 3\n",
+		"amass_names_rejected_total{reason=\"duplicate\"} 2\n",
+		"amass_dns_queries_total{type=\"A\"} 20\n",
+		"amass_resolver_errors_total{type=\"A\"} 4\n",
+		"amass_resolver_errors_total{type=\"TXT\"} 1\n",
+		"amass_resolvers{state=\"ejected\"} 1\n",
+		"amass_data_sources 0\n",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("the metrics did not contain %q:\n%s", want, metrics)
+		}
+	}
+	if strings.Contains(metrics, "amass_query_cache") {
+		t.Errorf("the metrics reported the query cache when it was disabled")
+	}
+}
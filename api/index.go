@@ -0,0 +1,325 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package api
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+	"github.com/miekg/dns"
+)
+
+// Name is the JSON object returned for each discovered name.
+type Name struct {
+	Name      string    `json:"name"`
+	Domain    string    `json:"domain"`
+	Addresses []string  `json:"addresses"`
+	Sources   []string  `json:"sources"`
+	Tags      []string  `json:"tags"`
+	Suspect   bool      `json:"suspect,omitempty"`
+	Score     int       `json:"score"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Address is the JSON object returned for each discovered address.
+type Address struct {
+	Address   string    `json:"address"`
+	Names     []string  `json:"names"`
+	Sources   []string  `json:"sources"`
+	Tags      []string  `json:"tags"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type nameEntry struct {
+	name string
+	// The name before the redaction, which the suspect and score functions are called with
+	key       string
+	domain    string
+	addrs     set
+	sources   set
+	tags      set
+	suspect   bool
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+type addrEntry struct {
+	addr      string
+	names     set
+	sources   set
+	tags      set
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// index maintains the results of the enumeration in the order they were discovered, so the
+// pages of a listing remain stable while new results are appended.
+type index struct {
+	sync.Mutex
+	names     map[string]*nameEntry
+	nameOrder []string
+	addrs     map[string]*addrEntry
+	addrOrder []string
+	// Reports the names that HTTP verification suspects are wildcard or parking hits
+	suspect func(name string) bool
+	// Ranks the names by the interesting keywords and the signals collected for them
+	score func(name string) int
+	// Replaces the labels of the names matching the redact patterns before the data is indexed
+	redact func(data pipeline.Data) pipeline.Data
+}
+
+func newIndex() *index {
+	return &index{
+		names: make(map[string]*nameEntry),
+		addrs: make(map[string]*addrEntry),
+	}
+}
+
+// insert adds the DNSRequest or AddrRequest leaving the pipeline to the index.
+func (idx *index) insert(data pipeline.Data) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	now := time.Now()
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		if v == nil || v.Name == "" {
+			return
+		}
+
+		key := strings.ToLower(v.Name)
+		if idx.redact != nil {
+			v = idx.redact(v).(*requests.DNSRequest)
+		}
+
+		n := idx.name(v.Name, v.Domain, now)
+		n.key = key
+		n.sources.insert(v.Source)
+		n.tags.insert(v.Tag)
+		if idx.suspect != nil && idx.suspect(key) {
+			n.suspect = true
+		}
+		for _, rec := range v.Records {
+			if rec.Type != int(dns.TypeA) && rec.Type != int(dns.TypeAAAA) {
+				continue
+			}
+
+			addr := strings.TrimSpace(rec.Data)
+			n.addrs.insert(addr)
+			a := idx.addr(addr, now)
+			a.names.insert(n.name)
+			a.sources.insert(v.Source)
+			a.tags.insert(v.Tag)
+		}
+	case *requests.AddrRequest:
+		if v == nil || v.Address == "" {
+			return
+		}
+
+		a := idx.addr(v.Address, now)
+		a.sources.insert(v.Source)
+		a.tags.insert(v.Tag)
+		if v.Domain != "" {
+			a.names.insert(strings.ToLower(v.Domain))
+		}
+	}
+}
+
+func (idx *index) name(name, domain string, now time.Time) *nameEntry {
+	name = strings.ToLower(name)
+
+	n, found := idx.names[name]
+	if !found {
+		n = &nameEntry{
+			name:      name,
+			domain:    strings.ToLower(domain),
+			addrs:     make(set),
+			sources:   make(set),
+			tags:      make(set),
+			firstSeen: now,
+		}
+		idx.names[name] = n
+		idx.nameOrder = append(idx.nameOrder, name)
+	}
+
+	n.lastSeen = now
+	return n
+}
+
+func (idx *index) addr(addr string, now time.Time) *addrEntry {
+	a, found := idx.addrs[addr]
+	if !found {
+		a = &addrEntry{
+			addr:      addr,
+			names:     make(set),
+			sources:   make(set),
+			tags:      make(set),
+			firstSeen: now,
+		}
+		idx.addrs[addr] = a
+		idx.addrOrder = append(idx.addrOrder, addr)
+	}
+
+	a.lastSeen = now
+	return a
+}
+
+// listNames returns the page of names matching the domain, and the total number of matches.
+// The names are listed from the highest score to the lowest when byScore is true.
+func (idx *index) listNames(domain string, offset, limit int, byScore bool) ([]*Name, int) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	if byScore {
+		return idx.listNamesByScore(domain, offset, limit)
+	}
+
+	var total int
+	items := []*Name{}
+	for _, name := range idx.nameOrder {
+		if !underDomain(name, domain) {
+			continue
+		}
+
+		if total >= offset && len(items) < limit {
+			items = append(items, idx.output(idx.names[name]))
+		}
+		total++
+	}
+	return items, total
+}
+
+func (idx *index) listNamesByScore(domain string, offset, limit int) ([]*Name, int) {
+	var matches []*Name
+	for _, name := range idx.nameOrder {
+		if underDomain(name, domain) {
+			matches = append(matches, idx.output(idx.names[name]))
+		}
+	}
+	// Names with the same score remain in the order they were discovered
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	items := []*Name{}
+	if offset < len(matches) {
+		end := offset + limit
+		if end > len(matches) {
+			end = len(matches)
+		}
+		items = append(items, matches[offset:end]...)
+	}
+	return items, len(matches)
+}
+
+// listAddresses returns the page of addresses associated with names under the domain,
+// and the total number of matches.
+func (idx *index) listAddresses(domain string, offset, limit int) ([]*Address, int) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	var total int
+	items := []*Address{}
+	for _, addr := range idx.addrOrder {
+		a := idx.addrs[addr]
+		if domain != "" && !a.hasNameUnder(domain) {
+			continue
+		}
+
+		if total >= offset && len(items) < limit {
+			items = append(items, a.output())
+		}
+		total++
+	}
+	return items, total
+}
+
+func (idx *index) getName(name string) *Name {
+	idx.Lock()
+	defer idx.Unlock()
+
+	if n, found := idx.names[strings.ToLower(name)]; found {
+		return idx.output(n)
+	}
+	return nil
+}
+
+func (idx *index) getAddress(addr string) *Address {
+	idx.Lock()
+	defer idx.Unlock()
+
+	if a, found := idx.addrs[addr]; found {
+		return a.output()
+	}
+	return nil
+}
+
+func (idx *index) output(n *nameEntry) *Name {
+	o := n.output()
+	if idx.score != nil {
+		o.Score = idx.score(n.key)
+	}
+	return o
+}
+
+func (n *nameEntry) output() *Name {
+	return &Name{
+		Name:      n.name,
+		Domain:    n.domain,
+		Addresses: sortedSlice(n.addrs),
+		Sources:   sortedSlice(n.sources),
+		Tags:      sortedSlice(n.tags),
+		Suspect:   n.suspect,
+		FirstSeen: n.firstSeen,
+		LastSeen:  n.lastSeen,
+	}
+}
+
+func (a *addrEntry) output() *Address {
+	return &Address{
+		Address:   a.addr,
+		Names:     sortedSlice(a.names),
+		Sources:   sortedSlice(a.sources),
+		Tags:      sortedSlice(a.tags),
+		FirstSeen: a.firstSeen,
+		LastSeen:  a.lastSeen,
+	}
+}
+
+func (a *addrEntry) hasNameUnder(domain string) bool {
+	for name := range a.names {
+		if underDomain(name, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// underDomain returns true when the name is the domain or a subdomain of it, or the domain is empty.
+func underDomain(name, domain string) bool {
+	return domain == "" || name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+type set map[string]struct{}
+
+func (s set) insert(item string) {
+	if item != "" {
+		s[item] = struct{}{}
+	}
+}
+
+func sortedSlice(s set) []string {
+	items := make([]string, 0, len(s))
+	for item := range s {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return items
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package api
+
+// schema is the JSON Schema describing the objects returned by the API endpoints.
+const schema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "/api/v1/schema",
+  "title": "Amass enumeration results",
+  "definitions": {
+    "strings": {
+      "type": "array",
+      "items": {"type": "string"}
+    },
+    "name": {
+      "type": "object",
+      "required": ["name", "domain", "addresses", "sources", "tags", "score", "first_seen", "last_seen"],
+      "properties": {
+        "name": {"type": "string", "description": "The discovered fully qualified domain name"},
+        "domain": {"type": "string", "description": "The root domain name the name belongs to"},
+        "addresses": {"$ref": "#/definitions/strings", "description": "The addresses the name resolved to"},
+        "sources": {"$ref": "#/definitions/strings", "description": "The data sources that provided the name"},
+        "tags": {"$ref": "#/definitions/strings", "description": "The categories of the data sources"},
+        "suspect": {"type": "boolean", "description": "Set when HTTP verification suspects a wildcard or parking hit"},
+        "score": {"type": "integer", "description": "Ranks the name by the interesting keywords in its labels and the signals collected for it"},
+        "first_seen": {"type": "string", "format": "date-time"},
+        "last_seen": {"type": "string", "format": "date-time"}
+      }
+    },
+    "address": {
+      "type": "object",
+      "required": ["address", "names", "sources", "tags", "first_seen", "last_seen"],
+      "properties": {
+        "address": {"type": "string", "description": "The discovered IP address"},
+        "names": {"$ref": "#/definitions/strings", "description": "The names that resolved to the address"},
+        "sources": {"$ref": "#/definitions/strings", "description": "The data sources that provided the address"},
+        "tags": {"$ref": "#/definitions/strings", "description": "The categories of the data sources"},
+        "first_seen": {"type": "string", "format": "date-time"},
+        "last_seen": {"type": "string", "format": "date-time"}
+      }
+    },
+    "page": {
+      "type": "object",
+      "required": ["total", "offset", "limit", "items"],
+      "properties": {
+        "total": {"type": "integer", "description": "The number of items matching the query"},
+        "offset": {"type": "integer", "description": "The position of the first item in the page"},
+        "limit": {"type": "integer", "description": "The largest number of items in the page"},
+        "items": {"type": "array", "items": {"oneOf": [{"$ref": "#/definitions/name"}, {"$ref": "#/definitions/address"}]}}
+      }
+    },
+    "error": {
+      "type": "object",
+      "required": ["error"],
+      "properties": {
+        "error": {"type": "string"}
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,213 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package api provides a read-only HTTP API for querying the results of a running enumeration.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/caffix/pipeline"
+)
+
+const (
+	// The number of items returned in a page when the limit parameter is not provided
+	defaultPageLimit = 100
+	// The largest number of items returned in a single page
+	maxPageLimit = 1000
+	// The path prefix of the API endpoints
+	apiPrefix = "/api/v1"
+)
+
+// Page is the JSON object returned by the listing endpoints.
+type Page struct {
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Items  interface{} `json:"items"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the names and addresses discovered by the enumeration as they leave the pipeline.
+type Server struct {
+	index *index
+	srv   *http.Server
+}
+
+// NewServer returns a Server that indexes the results of the enumeration. The Server
+// must be created before the enumeration is started to receive all the results. The names
+// are indexed with the labels matching the redact patterns of the configuration replaced.
+func NewServer(e *enum.Enumeration) *Server {
+	s := &Server{index: newIndex()}
+	s.index.suspect = e.SuspectedWildcard
+	s.index.score = e.NameScore
+	s.index.redact = func(data pipeline.Data) pipeline.Data {
+		return format.RedactData(e.Config, data)
+	}
+
+	e.AddOutputHook(func(data pipeline.Data) {
+		s.index.insert(data)
+	})
+
+	s.srv = &http.Server{
+		Handler:      s.Handler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the http.Handler that serves the API endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(apiPrefix+"/names", getOnly(s.handleNames))
+	mux.HandleFunc(apiPrefix+"/names/", getOnly(s.handleName))
+	mux.HandleFunc(apiPrefix+"/addresses", getOnly(s.handleAddresses))
+	mux.HandleFunc(apiPrefix+"/addresses/", getOnly(s.handleAddress))
+	mux.HandleFunc(apiPrefix+"/schema", getOnly(handleSchema))
+	return mux
+}
+
+// Serve accepts connections on the listener until Close is called.
+func (s *Server) Serve(lis net.Listener) error {
+	if err := s.srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Close stops the Server, waiting for the requests in progress until ctx expires.
+func (s *Server) Close(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func getOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, "only GET requests are supported")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) handleNames(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var byScore bool
+	switch sortBy := r.URL.Query().Get("sort"); sortBy {
+	case "", "discovered":
+	case "score":
+		byScore = true
+	default:
+		writeError(w, http.StatusBadRequest, "the sort parameter must be discovered or score")
+		return
+	}
+
+	items, total := s.index.listNames(domainParam(r), offset, limit, byScore)
+	writeJSON(w, http.StatusOK, &Page{
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+		Items:  items,
+	})
+}
+
+func (s *Server) handleName(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, apiPrefix+"/names/")
+
+	if n := s.index.getName(name); n != nil {
+		writeJSON(w, http.StatusOK, n)
+		return
+	}
+	writeError(w, http.StatusNotFound, "the name has not been discovered")
+}
+
+func (s *Server) handleAddresses(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, total := s.index.listAddresses(domainParam(r), offset, limit)
+	writeJSON(w, http.StatusOK, &Page{
+		Total:  total,
+		Offset: offset,
+		Limit:  limit,
+		Items:  items,
+	})
+}
+
+func (s *Server) handleAddress(w http.ResponseWriter, r *http.Request) {
+	addr := strings.TrimPrefix(r.URL.Path, apiPrefix+"/addresses/")
+
+	if a := s.index.getAddress(addr); a != nil {
+		writeJSON(w, http.StatusOK, a)
+		return
+	}
+	writeError(w, http.StatusNotFound, "the address has not been discovered")
+}
+
+func handleSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(schema))
+}
+
+func domainParam(r *http.Request) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(r.URL.Query().Get("domain")), "."))
+}
+
+// pagination returns the offset and limit parameters of the request.
+func pagination(r *http.Request) (int, int, error) {
+	offset, limit := 0, defaultPageLimit
+	q := r.URL.Query()
+
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("the offset parameter must be a non-negative integer")
+		}
+		offset = n
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("the limit parameter must be a positive integer")
+		}
+		limit = n
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return offset, limit, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &errorResponse{Error: msg})
+}
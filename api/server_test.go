@@ -0,0 +1,214 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+	"github.com/miekg/dns"
+)
+
+func setupTestServer() (*Server, *httptest.Server) {
+	s := &Server{index: newIndex()}
+
+	for _, name := range []string{"www.owasp.org", "api.owasp.org", "www.example.com"} {
+		s.index.insert(&requests.DNSRequest{
+			Name:   name,
+			Domain: "owasp.org",
+			Records: []requests.DNSAnswer{{
+				Name: name,
+				Type: int(dns.TypeA),
+				Data: "192.0.2.1",
+			}},
+			Tag:    requests.DNS,
+			Source: "DNS",
+		})
+	}
+	s.index.insert(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.CERT, Source: "Active Cert"})
+
+	return s, httptest.NewServer(s.Handler())
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to request %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode the response from %s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestListNames(t *testing.T) {
+	_, ts := setupTestServer()
+	defer ts.Close()
+
+	var page struct {
+		Total  int     `json:"total"`
+		Offset int     `json:"offset"`
+		Limit  int     `json:"limit"`
+		Items  []*Name `json:"items"`
+	}
+	if code := getJSON(t, ts.URL+"/api/v1/names?domain=owasp.org&offset=1&limit=1", &page); code != http.StatusOK {
+		t.Fatalf("the names endpoint returned the %d status code", code)
+	}
+	if page.Total != 2 || page.Offset != 1 || page.Limit != 1 {
+		t.Errorf("the page had the total %d, offset %d and limit %d", page.Total, page.Offset, page.Limit)
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "api.owasp.org" {
+		t.Errorf("the page did not contain the second name discovered: %v", page.Items)
+	}
+
+	var e errorResponse
+	if code := getJSON(t, ts.URL+"/api/v1/names?limit=-1", &e); code != http.StatusBadRequest || e.Error == "" {
+		t.Errorf("an invalid limit returned the %d status code", code)
+	}
+}
+
+func TestListNamesByScore(t *testing.T) {
+	s, ts := setupTestServer()
+	defer ts.Close()
+	s.index.score = func(name string) int {
+		return enum.ScoreName(name, "owasp.org", []string{"api"})
+	}
+
+	var page struct {
+		Total int     `json:"total"`
+		Items []*Name `json:"items"`
+	}
+	if code := getJSON(t, ts.URL+"/api/v1/names?domain=owasp.org&sort=score", &page); code != http.StatusOK {
+		t.Fatalf("the names endpoint returned the %d status code", code)
+	}
+	if page.Total != 2 || len(page.Items) != 2 || page.Items[0].Name != "api.owasp.org" || page.Items[0].Score == 0 {
+		t.Errorf("the names were not listed by score: %v", page.Items)
+	}
+
+	var e errorResponse
+	if code := getJSON(t, ts.URL+"/api/v1/names?sort=size", &e); code != http.StatusBadRequest || e.Error == "" {
+		t.Errorf("an invalid sort returned the %d status code", code)
+	}
+}
+
+func TestGetName(t *testing.T) {
+	_, ts := setupTestServer()
+	defer ts.Close()
+
+	var n Name
+	if code := getJSON(t, ts.URL+"/api/v1/names/WWW.owasp.org", &n); code != http.StatusOK {
+		t.Fatalf("the name endpoint returned the %d status code", code)
+	}
+	if len(n.Sources) != 2 || len(n.Addresses) != 1 || n.Addresses[0] != "192.0.2.1" {
+		t.Errorf("the name did not provide the sources and addresses: %v", n)
+	}
+
+	var e errorResponse
+	if code := getJSON(t, ts.URL+"/api/v1/names/missing.owasp.org", &e); code != http.StatusNotFound {
+		t.Errorf("a name not discovered returned the %d status code", code)
+	}
+}
+
+func TestListAddresses(t *testing.T) {
+	s, ts := setupTestServer()
+	defer ts.Close()
+
+	s.index.insert(&requests.AddrRequest{Address: "192.0.2.2", Domain: "owasp.org", Tag: requests.DNS, Source: "Reverse DNS"})
+
+	var page struct {
+		Total int        `json:"total"`
+		Items []*Address `json:"items"`
+	}
+	if code := getJSON(t, ts.URL+"/api/v1/addresses?domain=example.com", &page); code != http.StatusOK {
+		t.Fatalf("the addresses endpoint returned the %d status code", code)
+	}
+	if page.Total != 1 || len(page.Items[0].Names) != 3 {
+		t.Errorf("the addresses were not filtered by the names under the domain: %v", page.Items)
+	}
+
+	var a Address
+	if code := getJSON(t, ts.URL+"/api/v1/addresses/192.0.2.2", &a); code != http.StatusOK || a.Sources[0] != "Reverse DNS" {
+		t.Errorf("the address endpoint returned the %d status code and %v", code, a)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	_, ts := setupTestServer()
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/v1/names", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Failed to send the request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("a POST request returned the %d status code", resp.StatusCode)
+	}
+}
+
+func TestRedactedNames(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.RedactPatterns = []string{"*-int"}
+
+	s := &Server{index: newIndex()}
+	s.index.redact = func(data pipeline.Data) pipeline.Data {
+		return format.RedactData(cfg, data)
+	}
+	s.index.score = func(name string) int {
+		return enum.ScoreName(name, "owasp.org", []string{"vpn"})
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req := &requests.DNSRequest{
+		Name:   "vpn-int.owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{{
+			Name: "vpn-int.owasp.org",
+			Type: int(dns.TypeA),
+			Data: "192.0.2.1",
+		}},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}
+	s.index.insert(req)
+
+	var page struct {
+		Total int     `json:"total"`
+		Items []*Name `json:"items"`
+	}
+	if code := getJSON(t, ts.URL+"/api/v1/names", &page); code != http.StatusOK {
+		t.Fatalf("the names endpoint returned the %d status code", code)
+	}
+	// The score is still obtained for the name discovered before the redaction
+	if page.Total != 1 || page.Items[0].Name != "redacted.owasp.org" || page.Items[0].Score == 0 {
+		t.Errorf("the names were not redacted: %v", page.Items)
+	}
+
+	var e errorResponse
+	if code := getJSON(t, ts.URL+"/api/v1/names/vpn-int.owasp.org", &e); code != http.StatusNotFound {
+		t.Errorf("the name matching the redact pattern returned the %d status code", code)
+	}
+
+	var a Address
+	if code := getJSON(t, ts.URL+"/api/v1/addresses/192.0.2.1", &a); code != http.StatusOK ||
+		len(a.Names) != 1 || a.Names[0] != "redacted.owasp.org" {
+		t.Errorf("the address provided the names %v", a.Names)
+	}
+	// The results shared with the other outputs are not modified
+	if req.Name != "vpn-int.owasp.org" || req.Records[0].Name != "vpn-int.owasp.org" {
+		t.Errorf("the request was modified as %s", req.Name)
+	}
+}
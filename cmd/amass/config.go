@@ -0,0 +1,130 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+)
+
+const (
+	configUsageMsg = "config -check [options]"
+)
+
+type configArgs struct {
+	Options struct {
+		Check bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+	}
+}
+
+func runConfigCommand(clArgs []string) {
+	var args configArgs
+	var help1, help2 bool
+	configCommand := flag.NewFlagSet("config", flag.ContinueOnError)
+
+	configBuf := new(bytes.Buffer)
+	configCommand.SetOutput(configBuf)
+
+	configCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	configCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	configCommand.BoolVar(&args.Options.Check, "check", false, "Probe the data sources with the credentials of the configuration")
+	configCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
+	configCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the configuration file")
+
+	if len(clArgs) < 1 {
+		commandUsage(configUsageMsg, configCommand, configBuf)
+		return
+	}
+	if err := configCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 || !args.Options.Check {
+		commandUsage(configUsageMsg, configCommand, configBuf)
+		return
+	}
+
+	cfg := config.NewConfig()
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err != nil {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Directory != "" {
+		cfg.Dir = args.Filepaths.Directory
+	}
+	// The scripts in the output directory are also data sources
+	createOutputDirectory(cfg)
+	// The probes are sent through the proxy of the configuration
+	if _, err := cfg.ProxyDialer(); err != nil {
+		r.Fprintf(color.Error, "Failed to use the proxy: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Stop the probes when the user interrupts them
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// The system does not build the resolver pool, since no DNS queries are required, and the
+	// responses cached by the data sources are only kept in memory
+	sys := &systems.SimpleSystem{
+		Cfg:   cfg,
+		Graph: netmap.NewGraph(netmap.NewCayleyGraphMemory()),
+	}
+	defer sys.Graph.Close()
+	srcs := datasrcs.SelectedDataSources(cfg, datasrcs.GetAllSources(sys))
+
+	results := datasrcs.CheckCredentials(ctx, cfg, srcs)
+	if len(results) == 0 {
+		fmt.Fprintf(color.Error, "%s\n", yellow("No credentials were found for the selected data sources"))
+		return
+	}
+
+	var invalid bool
+	for _, res := range results {
+		status := green(res.Status)
+		switch res.Status {
+		case requests.CredentialsInvalid:
+			invalid = true
+			status = red(res.Status)
+		case requests.CredentialsUnreachable, requests.CredentialsUnchecked:
+			status = yellow(res.Status)
+		}
+
+		var reason string
+		if res.Reason != "" {
+			reason = ": " + res.Reason
+		}
+		fmt.Fprintf(color.Output, "%-20s %s%s\n", blue(res.Source), status, reason)
+	}
+	// The credentials that need to be replaced cause the command to fail
+	if invalid {
+		os.Exit(1)
+	}
+}
@@ -32,6 +32,8 @@ const (
 type dbArgs struct {
 	Domains *stringset.Set
 	Enum    int
+	Since   string
+	Diff    format.ParseStrings
 	Options struct {
 		DemoMode         bool
 		IPs              bool
@@ -41,6 +43,7 @@ type dbArgs struct {
 		ASNTableSummary  bool
 		DiscoveredNames  bool
 		NoColor          bool
+		Seen             bool
 		ShowAll          bool
 		Silent           bool
 		Sources          bool
@@ -49,6 +52,7 @@ type dbArgs struct {
 		ConfigFile string
 		Directory  string
 		Domains    string
+		Export     string
 		JSONOutput string
 		TermOut    string
 	}
@@ -67,6 +71,7 @@ func runDBCommand(clArgs []string) {
 	dbCommand.BoolVar(&help1, "h", false, "Show the program usage message")
 	dbCommand.BoolVar(&help2, "help", false, "Show the program usage message")
 	dbCommand.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	dbCommand.Var(&args.Diff, "diff", "Two snapshot IDs separated by commas, compared edge by edge")
 	dbCommand.IntVar(&args.Enum, "enum", 0, "Identify an enumeration via an index from the listing")
 	dbCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
 	dbCommand.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
@@ -77,11 +82,14 @@ func runDBCommand(clArgs []string) {
 	dbCommand.BoolVar(&args.Options.ASNTableSummary, "summary", false, "Print Just ASN Table Summary")
 	dbCommand.BoolVar(&args.Options.DiscoveredNames, "names", false, "Print Just Discovered Names")
 	dbCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	dbCommand.BoolVar(&args.Options.Seen, "seen", false, "Print the first and last times the names were observed")
 	dbCommand.BoolVar(&args.Options.ShowAll, "show", false, "Print the results for the enumeration index + domains provided")
 	dbCommand.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
+	dbCommand.StringVar(&args.Since, "since", "", "Export only the changes since the snapshot ID or RFC3339 time provided")
 	dbCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
 	dbCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
 	dbCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
+	dbCommand.StringVar(&args.Filepaths.Export, "export", "", "Path to the JSON Lines file or '-' receiving the graph changes")
 	dbCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
 	dbCommand.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
 
@@ -126,6 +134,14 @@ func runDBCommand(clArgs []string) {
 		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
 		os.Exit(1)
 	}
+	// The snapshots kept by the exports are compared without opening the graph database
+	if len(args.Diff) > 0 {
+		if err := diffGraphSnapshots(&args); err != nil {
+			r.Fprintf(color.Error, "Failed to compare the snapshots: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	srcs := datasrcs.GetAllSources(&systems.LocalSystem{Cfg: cfg})
 	initializeSourceTags(srcs)
@@ -156,6 +172,13 @@ func runDBCommand(clArgs []string) {
 		listEvents(uuids, memDB)
 		return
 	}
+	if args.Filepaths.Export != "" {
+		if err := exportGraphChanges(context.TODO(), &args, memDB); err != nil {
+			r.Fprintf(color.Error, "Failed to export the graph changes: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if args.Options.ShowAll || args.Filepaths.JSONOutput != "" {
 		args.Options.DiscoveredNames = true
 		args.Options.ASNTableSummary = true
@@ -209,22 +232,17 @@ func listEvents(uuids []string, db *netmap.Graph) {
 func showEventData(args *dbArgs, uuids []string, asninfo bool, db *netmap.Graph) {
 	var total int
 	var err error
-	var outfile *os.File
+	var outfile *format.OutputFile
 	var discovered []*requests.Output
 	domains := args.Domains.Slice()
 
 	if args.Filepaths.TermOut != "" {
-		outfile, err = os.OpenFile(args.Filepaths.TermOut, os.O_WRONLY|os.O_CREATE, 0644)
+		outfile, err = format.CreateOutputFile(args.Filepaths.TermOut, false)
 		if err != nil {
 			r.Fprintf(color.Error, "Failed to open the text output file: %v\n", err)
 			os.Exit(1)
 		}
-		defer func() {
-			_ = outfile.Sync()
-			_ = outfile.Close()
-		}()
-		_ = outfile.Truncate(0)
-		_, _ = outfile.Seek(0, 0)
+		defer func() { _ = outfile.Close() }()
 	}
 
 	var cache *requests.ASNCache
@@ -256,6 +274,9 @@ func showEventData(args *dbArgs, uuids []string, asninfo bool, db *netmap.Graph)
 		if ips != "" {
 			ips = " " + ips
 		}
+		if args.Options.Seen && out.FirstSeen != "" {
+			ips += fmt.Sprintf(" [%s -> %s]", out.FirstSeen, out.LastSeen)
+		}
 
 		if args.Options.DiscoveredNames {
 			var written bool
@@ -348,25 +369,21 @@ func writeJSON(args *dbArgs, uuids []string, assets []*requests.Output, db *netm
 		d.Names = append(d.Names, asset)
 	}
 
-	var jsonptr *os.File
+	var jsonptr *format.OutputFile
 	var err error
 
 	// Write to STDOUT and not a file if named "-"
 	if args.Filepaths.JSONOutput == "-" {
-		jsonptr = os.Stdout
+		jsonptr = format.NewOutputFile(os.Stdout, false)
 	} else {
-		jsonptr, err = os.OpenFile(args.Filepaths.JSONOutput, os.O_WRONLY|os.O_CREATE, 0644)
+		jsonptr, err = format.CreateOutputFile(args.Filepaths.JSONOutput, false)
 		if err != nil {
 			r.Fprintf(color.Error, "Failed to open the JSON output file: %v\n", err)
 			return
 		}
 	}
 
-	// Remove previously stored data and encode the JSON
-	_ = jsonptr.Truncate(0)
-	_, _ = jsonptr.Seek(0, 0)
 	_ = json.NewEncoder(jsonptr).Encode(output)
-	_ = jsonptr.Sync()
 	_ = jsonptr.Close()
 }
 
@@ -0,0 +1,193 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/fatih/color"
+)
+
+// The predicates of the edges that point at the node they describe, such as the netblock
+// containing an address and the autonomous system announcing a netblock
+var inboundPredicates = map[string]struct{}{
+	"contains": {},
+	"prefix":   {},
+}
+
+// snapshotDiff is the JSON document describing the edges that differ between two snapshots.
+// The edges are grouped by the node they describe and their predicate. The groups found in both
+// snapshots with different edges are changed, while the other groups are added or removed.
+type snapshotDiff struct {
+	From    *diffSnapshotInfo `json:"from"`
+	To      *diffSnapshotInfo `json:"to"`
+	Added   []*snapshotEdge   `json:"added"`
+	Removed []*snapshotEdge   `json:"removed"`
+	Changed []*edgeChange     `json:"changed"`
+}
+
+type diffSnapshotInfo struct {
+	Snapshot string `json:"snapshot"`
+	Time     string `json:"time"`
+}
+
+// edgeChange provides the node, the predicate, and the nodes at the other end of the edges
+// before and after, such as the addresses a name resolved to or the target of a CNAME.
+type edgeChange struct {
+	Node      string   `json:"node"`
+	Predicate string   `json:"predicate"`
+	Before    []string `json:"before"`
+	After     []string `json:"after"`
+}
+
+// diffGraphSnapshots writes the edge-level differences between the two snapshot IDs as JSON.
+func diffGraphSnapshots(args *dbArgs) error {
+	if len(args.Diff) != 2 {
+		return fmt.Errorf("the diff requires two snapshot IDs, but %d were provided", len(args.Diff))
+	}
+
+	dir := filepath.Join(config.OutputDirectory(args.Filepaths.Directory), exportDirName)
+	prev, err := loadSnapshot(dir, args.Diff[0])
+	if err != nil {
+		return err
+	}
+	cur, err := loadSnapshot(dir, args.Diff[1])
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if path := args.Filepaths.JSONOutput; path != "" && path != "-" {
+		f, err := format.CreateOutputFile(path, false)
+		if err != nil {
+			return fmt.Errorf("failed to open the JSON output file: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	diff := diffSnapshotEdges(prev, cur)
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(diff); err != nil {
+		return err
+	}
+	if f, ok := out.(*format.OutputFile); ok {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+
+	g.Fprintf(color.Error, "Found %d added edges, %d removed edges and %d changed edge groups\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed))
+	return nil
+}
+
+// diffSnapshotEdges returns the edges added, removed and changed between the prev and cur snapshots.
+func diffSnapshotEdges(prev, cur *graphSnapshot) *snapshotDiff {
+	diff := &snapshotDiff{
+		From:    &diffSnapshotInfo{Snapshot: prev.ID, Time: prev.Time},
+		To:      &diffSnapshotInfo{Snapshot: cur.ID, Time: cur.Time},
+		Added:   []*snapshotEdge{},
+		Removed: []*snapshotEdge{},
+		Changed: []*edgeChange{},
+	}
+
+	before := groupSnapshotEdges(prev)
+	after := groupSnapshotEdges(cur)
+
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		b, inPrev := before[k]
+		a, inCur := after[k]
+
+		switch {
+		case !inPrev:
+			diff.Added = append(diff.Added, a.edges...)
+		case !inCur:
+			diff.Removed = append(diff.Removed, b.edges...)
+		case !equalStrings(b.others(), a.others()):
+			diff.Changed = append(diff.Changed, &edgeChange{
+				Node:      a.node,
+				Predicate: a.predicate,
+				Before:    b.others(),
+				After:     a.others(),
+			})
+		}
+	}
+	return diff
+}
+
+type edgeGroup struct {
+	node      string
+	predicate string
+	edges     []*snapshotEdge
+}
+
+// others returns the nodes at the other end of the edges in the group, in a stable order.
+func (eg *edgeGroup) others() []string {
+	var nodes []string
+
+	for _, e := range eg.edges {
+		if _, inbound := inboundPredicates[e.Predicate]; inbound {
+			nodes = append(nodes, e.From)
+		} else {
+			nodes = append(nodes, e.To)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// groupSnapshotEdges groups the edges of the snapshot by the node they describe and the predicate.
+func groupSnapshotEdges(s *graphSnapshot) map[string]*edgeGroup {
+	groups := make(map[string]*edgeGroup)
+
+	for _, e := range s.Edges {
+		node := e.From
+		if _, inbound := inboundPredicates[e.Predicate]; inbound {
+			node = e.To
+		}
+
+		k := node + "\x00" + e.Predicate
+		eg, found := groups[k]
+		if !found {
+			eg = &edgeGroup{node: node, predicate: e.Predicate}
+			groups[k] = eg
+		}
+		eg.edges = append(eg.edges, e)
+	}
+	return groups
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
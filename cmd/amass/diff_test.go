@@ -0,0 +1,87 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSnapshotEdges(t *testing.T) {
+	wwwA1 := &snapshotEdge{From: "www.owasp.org", Predicate: "a_record", To: "192.0.2.1"}
+	wwwA2 := &snapshotEdge{From: "www.owasp.org", Predicate: "a_record", To: "192.0.2.2"}
+	mailA := &snapshotEdge{From: "mail.owasp.org", Predicate: "a_record", To: "192.0.2.1"}
+	block1 := &snapshotEdge{From: "192.0.2.0/24", Predicate: "contains", To: "192.0.2.1"}
+	block2 := &snapshotEdge{From: "192.0.0.0/16", Predicate: "contains", To: "192.0.2.1"}
+
+	tests := []struct {
+		name    string
+		prev    []*snapshotEdge
+		cur     []*snapshotEdge
+		added   []*snapshotEdge
+		removed []*snapshotEdge
+		changed []*edgeChange
+	}{
+		{
+			name: "unchanged edges",
+			prev: []*snapshotEdge{wwwA1, mailA},
+			cur:  []*snapshotEdge{mailA, wwwA1},
+		},
+		{
+			name:  "edge added",
+			prev:  []*snapshotEdge{wwwA1},
+			cur:   []*snapshotEdge{wwwA1, mailA},
+			added: []*snapshotEdge{mailA},
+		},
+		{
+			name:    "edge removed",
+			prev:    []*snapshotEdge{wwwA1, mailA},
+			cur:     []*snapshotEdge{wwwA1},
+			removed: []*snapshotEdge{mailA},
+		},
+		{
+			name: "address changed",
+			prev: []*snapshotEdge{wwwA1},
+			cur:  []*snapshotEdge{wwwA2},
+			changed: []*edgeChange{{
+				Node:      "www.owasp.org",
+				Predicate: "a_record",
+				Before:    []string{"192.0.2.1"},
+				After:     []string{"192.0.2.2"},
+			}},
+		},
+		{
+			name: "inbound edge grouped by the target",
+			prev: []*snapshotEdge{block1},
+			cur:  []*snapshotEdge{block2},
+			changed: []*edgeChange{{
+				Node:      "192.0.2.1",
+				Predicate: "contains",
+				Before:    []string{"192.0.2.0/24"},
+				After:     []string{"192.0.0.0/16"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := &graphSnapshot{ID: "0000000000000001", Edges: tt.prev}
+			cur := &graphSnapshot{ID: "0000000000000002", Edges: tt.cur}
+
+			diff := diffSnapshotEdges(prev, cur)
+			if diff.From.Snapshot != prev.ID || diff.To.Snapshot != cur.ID {
+				t.Errorf("The diff describes the snapshots %s and %s", diff.From.Snapshot, diff.To.Snapshot)
+			}
+			if len(diff.Added) != len(tt.added) || (len(tt.added) > 0 && !reflect.DeepEqual(diff.Added, tt.added)) {
+				t.Errorf("Added = %v, want %v", diff.Added, tt.added)
+			}
+			if len(diff.Removed) != len(tt.removed) || (len(tt.removed) > 0 && !reflect.DeepEqual(diff.Removed, tt.removed)) {
+				t.Errorf("Removed = %v, want %v", diff.Removed, tt.removed)
+			}
+			if len(diff.Changed) != len(tt.changed) || (len(tt.changed) > 0 && !reflect.DeepEqual(diff.Changed, tt.changed)) {
+				t.Errorf("Changed = %v, want %v", diff.Changed, tt.changed)
+			}
+		})
+	}
+}
@@ -24,18 +24,26 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/OWASP/Amass/v3/api"
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs"
 	"github.com/OWASP/Amass/v3/enum"
 	"github.com/OWASP/Amass/v3/format"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
+	"github.com/OWASP/Amass/v3/viz"
+	"github.com/caffix/netmap"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/service"
 	"github.com/caffix/stringset"
 	"github.com/fatih/color"
 )
 
 const enumUsageMsg = "enum [options] -d DOMAIN"
 
+// flushTimeout is the duration queued data is allowed to complete the pipeline after an interrupt.
+const flushTimeout = 30 * time.Second
+
 type enumArgs struct {
 	Addresses         format.ParseIPs
 	ASNs              format.ParseInts
@@ -46,7 +54,9 @@ type enumArgs struct {
 	BruteWordListMask *stringset.Set
 	Blacklist         *stringset.Set
 	Domains           *stringset.Set
+	DoHResolvers      *stringset.Set
 	Excluded          *stringset.Set
+	ExpandASNs        format.ParseInts
 	Included          *stringset.Set
 	Interface         string
 	MaxDNSQueries     int
@@ -54,42 +64,60 @@ type enumArgs struct {
 	MinForRecursive   int
 	Names             *stringset.Set
 	Ports             format.ParseInts
+	Proxy             string
+	ResolveRepeat     int
 	Resolvers         *stringset.Set
+	ScopeURL          string
 	Timeout           int
 	Options           struct {
 		Active          bool
 		BruteForcing    bool
+		CTTail          bool
 		DemoMode        bool
+		Deterministic   bool
+		TrustedOnly     bool
 		IPs             bool
 		IPv4            bool
 		IPv6            bool
+		IPv4Only        bool
+		IPv6Only        bool
+		ImportWebOnly   bool
 		ListSources     bool
 		NoAlts          bool
 		NoColor         bool
 		NoLocalDatabase bool
 		NoRecursive     bool
 		Passive         bool
+		Phased          bool
+		Plan            bool
 		Share           bool
 		Silent          bool
 		Sources         bool
+		TUI             bool
 		Verbose         bool
 	}
 	Filepaths struct {
 		AllFilePrefix    string
 		AltWordlist      format.ParseStrings
+		Baseline         string
 		Blacklist        string
 		BruteWordlist    format.ParseStrings
+		Checkpoint       string
 		ConfigFile       string
 		Directory        string
 		Domains          format.ParseStrings
 		ExcludedSrcs     string
+		Imports          format.ParseStrings
 		IncludedSrcs     string
 		JSONOutput       string
+		JSONStream       string
 		LogFile          string
 		Names            format.ParseStrings
+		Prior            format.ParseStrings
 		Resolvers        format.ParseStrings
 		ScriptsDirectory string
 		TermOut          string
+		Unresolved       string
 	}
 }
 
@@ -98,55 +126,75 @@ func defineEnumArgumentFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.Var(args.AltWordListMask, "awm", "\"hashcat-style\" wordlist masks for name alterations")
 	enumFlags.Var(&args.ASNs, "asn", "ASNs separated by commas (can be used multiple times)")
 	enumFlags.Var(&args.CIDRs, "cidr", "CIDRs separated by commas (can be used multiple times)")
+	enumFlags.Var(&args.ExpandASNs, "expand-asn", "ASNs allowed to bring their prefixes into scope when owning discovered addresses")
 	enumFlags.Var(args.Blacklist, "bl", "Blacklist of subdomain names that will not be investigated")
 	enumFlags.Var(args.BruteWordListMask, "wm", "\"hashcat-style\" wordlist masks for DNS brute forcing")
 	enumFlags.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	enumFlags.Var(args.DoHResolvers, "doh", "DNS-over-HTTPS resolver URLs (can be used multiple times)")
 	enumFlags.Var(args.Excluded, "exclude", "Data source names separated by commas to be excluded")
 	enumFlags.Var(args.Included, "include", "Data source names separated by commas to be included")
 	enumFlags.StringVar(&args.Interface, "iface", "", "Provide the network interface to send traffic through")
 	enumFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Maximum number of DNS queries per second")
 	enumFlags.IntVar(&args.MaxDepth, "max-depth", 0, "Maximum number of subdomain labels for brute forcing")
 	enumFlags.IntVar(&args.MinForRecursive, "min-for-recursive", 1, "Subdomain labels seen before recursive brute forcing (Default: 1)")
+	enumFlags.IntVar(&args.ResolveRepeat, "resolve-repeat", 0, "Number of times each resolved name is resolved again to collect rotating addresses")
 	enumFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 80, 443)")
+	enumFlags.StringVar(&args.Proxy, "proxy", "", "URL of the socks5:// or http(s):// proxy used for outbound connections")
 	enumFlags.Var(args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
+	enumFlags.StringVar(&args.ScopeURL, "scope-url", "", "URL of a JSON document providing the domains, CIDRs and ASNs in scope")
 	enumFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
 }
 
 func defineEnumOptionFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.BoolVar(&args.Options.Active, "active", false, "Attempt zone transfers and certificate name grabs")
 	enumFlags.BoolVar(&args.Options.BruteForcing, "brute", false, "Execute brute forcing after searches")
+	enumFlags.BoolVar(&args.Options.CTTail, "ct-tail", false, "Monitor certificate transparency logs for new names until stopped")
 	enumFlags.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	enumFlags.BoolVar(&args.Options.Deterministic, "deterministic", false, "Discover and output names in a reproducible order, at a reduced speed")
+	enumFlags.BoolVar(&args.Options.TrustedOnly, "trusted-only", false, "Only accept names from trusted data sources")
 	enumFlags.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
+	enumFlags.BoolVar(&args.Options.IPv4Only, "ipv4-only", false, "Only resolve and collect IPv4 addresses")
+	enumFlags.BoolVar(&args.Options.IPv6Only, "ipv6-only", false, "Only resolve and collect IPv6 addresses")
+	enumFlags.BoolVar(&args.Options.ImportWebOnly, "import-web", false, "Only import the scanned hosts with port 80 or 443 open")
 	enumFlags.BoolVar(&args.Options.ListSources, "list", false, "Print the names of all available data sources")
 	enumFlags.BoolVar(&args.Options.NoAlts, "noalts", false, "Disable generation of altered names")
 	enumFlags.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
 	enumFlags.BoolVar(&args.Options.NoLocalDatabase, "nolocaldb", false, "Disable saving data into a local database")
 	enumFlags.BoolVar(&args.Options.NoRecursive, "norecursive", false, "Turn off recursive brute forcing")
 	enumFlags.BoolVar(&args.Options.Passive, "passive", false, "Disable DNS resolution of names and dependent features")
+	enumFlags.BoolVar(&args.Options.Phased, "phased", false, "Run the data sources, brute forcing and alterations as sequential phases")
+	enumFlags.BoolVar(&args.Options.Plan, "plan", false, "Print the data sources, query estimates and resolvers, then exit without enumerating")
 	enumFlags.BoolVar(&args.Options.Share, "share", false, "Share findings with data source providers")
 	enumFlags.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
 	enumFlags.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
+	enumFlags.BoolVar(&args.Options.TUI, "tui", false, "Show a live dashboard of the enumeration in the terminal")
 	enumFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
 }
 
 func defineEnumFilepathFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.StringVar(&args.Filepaths.AllFilePrefix, "oA", "", "Path prefix used for naming all output files")
 	enumFlags.Var(&args.Filepaths.AltWordlist, "aw", "Path to a different wordlist file for alterations")
+	enumFlags.StringVar(&args.Filepaths.Baseline, "baseline", "", "Path to the output of a previous enumeration, so only new discoveries are reported")
 	enumFlags.StringVar(&args.Filepaths.Blacklist, "blf", "", "Path to a file providing blacklisted subdomains")
 	enumFlags.Var(&args.Filepaths.BruteWordlist, "w", "Path to a different wordlist file for brute forcing")
+	enumFlags.StringVar(&args.Filepaths.Checkpoint, "checkpoint", "", "Path to the checkpoint file used to resume an interrupted enumeration")
 	enumFlags.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
 	enumFlags.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the output files")
 	enumFlags.Var(&args.Filepaths.Domains, "df", "Path to a file providing root domain names")
 	enumFlags.StringVar(&args.Filepaths.ExcludedSrcs, "ef", "", "Path to a file providing data sources to exclude")
+	enumFlags.Var(&args.Filepaths.Imports, "import", "Port scanner output providing live hosts, such as nmap:scan.xml or masscan:scan.json")
 	enumFlags.StringVar(&args.Filepaths.IncludedSrcs, "if", "", "Path to a file providing data sources to include")
 	enumFlags.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
+	enumFlags.StringVar(&args.Filepaths.JSONStream, "json-stream", "", "Path to the JSON Lines file written as results are discovered")
 	enumFlags.StringVar(&args.Filepaths.LogFile, "log", "", "Path to the log file where errors will be written")
 	enumFlags.Var(&args.Filepaths.Names, "nf", "Path to a file providing already known subdomain names (from other tools/sources)")
+	enumFlags.Var(&args.Filepaths.Prior, "prior", "Path to the JSON output of a previous enumeration providing names to start from (can be used multiple times)")
 	enumFlags.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing preferred DNS resolvers")
 	enumFlags.StringVar(&args.Filepaths.ScriptsDirectory, "scripts", "", "Path to a directory containing ADS scripts")
 	enumFlags.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
+	enumFlags.StringVar(&args.Filepaths.Unresolved, "unresolved", "", "Path to the JSON Lines file receiving the brute forced and altered names that do not exist")
 }
 
 func runEnumCommand(clArgs []string) {
@@ -178,12 +226,29 @@ func runEnumCommand(clArgs []string) {
 		os.Exit(1)
 	}
 	defer func() { _ = sys.Shutdown() }()
-	sys.SetDataSources(datasrcs.GetAllSources(sys))
+	srcs := datasrcs.GetAllSources(sys)
+	sys.SetDataSources(srcs)
 
 	// Expand data source category names into the associated source names
 	initializeSourceTags(sys.DataSources())
 	cfg.SourceFilter.Sources = expandCategoryNames(cfg.SourceFilter.Sources, generateCategoryMap(sys))
 
+	// Report what the enumeration would do without starting the pipeline
+	if args.Options.Plan {
+		printPlan(cfg, sys, srcs)
+		return
+	}
+	printSourceSummary(cfg, sys, srcs, args.Options.Verbose)
+
+	var baseline *format.Baseline
+	if args.Filepaths.Baseline != "" {
+		baseline, err = format.LoadBaseline(args.Filepaths.Baseline)
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Setup the new enumeration
 	e := enum.NewEnumeration(cfg, sys)
 	if e == nil {
@@ -192,19 +257,68 @@ func runEnumCommand(clArgs []string) {
 	}
 	defer e.Close()
 
+	if err := importScanHosts(e, args); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := importPriorNames(e, args); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.JSONStream != "" {
+		closeStream := setupJSONStream(e, args, baseline)
+		defer closeStream()
+	}
+	if cfg.OutputUnresolved != "" {
+		closeUnresolved := setupUnresolvedOutput(e, cfg)
+		defer closeUnresolved()
+	}
+	if cfg.ElasticSearch != nil {
+		closeElastic := setupElasticOutput(e, cfg, baseline)
+		defer closeElastic()
+	}
+	if cfg.WebhookURL != "" {
+		closeWebhook := setupWebhookOutput(e, cfg, baseline)
+		defer closeWebhook()
+	}
+	if len(cfg.AlertPatterns) > 0 {
+		closeAlerts := setupAlerts(e, cfg, baseline)
+		defer closeAlerts()
+	}
+	if cfg.APIAddr != "" {
+		closeAPI := setupAPIServer(e, cfg)
+		defer closeAPI()
+	}
+	if cfg.HealthAddr != "" {
+		closeHealth := setupHealthServer(e, cfg)
+		defer closeHealth()
+	}
+
 	var wg sync.WaitGroup
 	var outChans []chan *requests.Output
 	// This channel sends the signal for goroutines to terminate
 	done := make(chan struct{})
+	// The interrupts from the user, which the dashboard also sends when asked to quit
+	quit := make(chan os.Signal, 1)
 
-	// Print output only if JSONOutput is not meant for STDOUT
-	if args.Filepaths.JSONOutput != "-" {
+	if dashboardAvailable(args) {
+		wg.Add(1)
+		// This goroutine will draw the dashboard in place of printing the output
+		dashChan := make(chan *requests.Output, 10)
+		go runDashboard(e, sys, args, dashChan, quit, &wg)
+		outChans = append(outChans, dashChan)
+	} else if args.Filepaths.JSONOutput != "-" && args.Filepaths.JSONStream != "-" {
+		// Print output only if JSONOutput is not meant for STDOUT
 		wg.Add(1)
 		// This goroutine will handle printing the output
 		printOutChan := make(chan *requests.Output, 10)
 		go printOutput(e, args, printOutChan, &wg)
 		outChans = append(outChans, printOutChan)
 	}
+	// The dashboard falls back to logging the progress when not attached to a terminal
+	if args.Options.TUI && !args.Options.Silent && !dashboardAvailable(args) {
+		go logProgress(e)
+	}
 
 	wg.Add(1)
 	// This goroutine will handle saving the output to the text file
@@ -217,6 +331,8 @@ func runEnumCommand(clArgs []string) {
 	jsonOutChan := make(chan *requests.Output, 10)
 	go saveJSONOutput(e, args, jsonOutChan, &wg)
 	outChans = append(outChans, jsonOutChan)
+	// Each output sink in the configuration receives every result
+	outChans = append(outChans, startOutputSinks(e, args, &wg)...)
 
 	var ctx context.Context
 	var cancel context.CancelFunc
@@ -228,16 +344,27 @@ func runEnumCommand(clArgs []string) {
 	defer cancel()
 
 	wg.Add(1)
-	go processOutput(ctx, e, outChans, done, &wg)
+	go processOutput(ctx, e, outChans, baseline, done, &wg)
 
 	// Monitor for cancellation by the user
 	go func(c context.CancelFunc) {
-		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 		defer signal.Stop(quit)
 
 		select {
 		case <-quit:
+			// Allow the queued data to be emitted, unless the user signals again
+			fctx, fcancel := context.WithTimeout(ctx, flushTimeout)
+			go func() {
+				select {
+				case <-quit:
+					fcancel()
+				case <-fctx.Done():
+				}
+			}()
+
+			e.StopAndFlush(fctx)
+			fcancel()
 			c()
 		case <-done:
 		case <-ctx.Done():
@@ -252,6 +379,10 @@ func runEnumCommand(clArgs []string) {
 	// Let all the output goroutines know that the enumeration has finished
 	close(done)
 	wg.Wait()
+	printZoneTransfers(e)
+	printDeadZones(e)
+	printPoisonedResolvers(sys)
+	printQueryTypes(sys)
 
 	// If necessary, handle graph database migration
 	if len(e.Sys.GraphDatabases()) > 0 {
@@ -271,13 +402,17 @@ func runEnumCommand(clArgs []string) {
 		}(cancel)
 
 		// Copy the graph of findings into the system graph databases
-		for _, g := range e.Sys.GraphDatabases() {
-			fmt.Fprintf(color.Error, "%s%s%s\n",
-				yellow("Discoveries are being migrated into the "), yellow(g.String()), yellow(" database"))
-
-			if err := e.Graph.Migrate(ctx, g); err != nil {
-				fmt.Fprintf(color.Error, "%s%s%s%s\n",
-					red("The database migration to "), red(g.String()), red(" failed: "), red(err.Error()))
+		if w := sys.GraphWriter(); w != nil {
+			migrateWithFailover(ctx, e, w)
+		} else {
+			for _, g := range e.Sys.GraphDatabases() {
+				fmt.Fprintf(color.Error, "%s%s%s\n",
+					yellow("Discoveries are being migrated into the "), yellow(g.String()), yellow(" database"))
+
+				if err := e.Migrate(ctx, g); err != nil {
+					fmt.Fprintf(color.Error, "%s%s%s%s\n",
+						red("The database migration to "), red(g.String()), red(" failed: "), red(err.Error()))
+				}
 			}
 		}
 	}
@@ -287,6 +422,119 @@ func runEnumCommand(clArgs []string) {
 	}
 }
 
+// migrateWithFailover copies the graph of findings into the endpoint of the graph database currently
+// written to, failing over to the other endpoints when it is lost. While none of the endpoints can be
+// reached, the endpoints are attempted again until the migration is interrupted or times out.
+func migrateWithFailover(ctx context.Context, e *enum.Enumeration, w *systems.GraphWriter) {
+	fmt.Fprintf(color.Error, "%s%s\n", yellow("Discoveries are being migrated into the "), yellow(w.String()))
+
+	err := w.Write(ctx, func(ctx context.Context, g *netmap.Graph) error {
+		return e.Migrate(ctx, g)
+	})
+	if err != nil && w.Buffered() > 0 {
+		err = w.Flush(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(color.Error, "%s%s%s%s\n",
+			red("The database migration to the "), red(w.String()), red(" failed: "), red(err.Error()))
+	}
+}
+
+// printZoneTransfers reports the nameservers that permitted the transfer of a zone.
+func printZoneTransfers(e *enum.Enumeration) {
+	xfrs := e.ZoneTransfers()
+	if len(xfrs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", red("Zone transfers were permitted by the following nameservers:"))
+	for _, x := range xfrs {
+		var partial string
+		if x.Partial {
+			partial = red(" (partial)")
+		}
+
+		fmt.Fprintf(color.Error, "%s %s %s%s\n", green(x.Server+" ("+x.Address+")"),
+			blue("->"), yellow(fmt.Sprintf("%s: %s, %d names", x.Zone, x.Type, x.Names)), partial)
+	}
+}
+
+// printDeadZones reports the root domain names that were skipped by the active techniques.
+func printDeadZones(e *enum.Enumeration) {
+	zones := e.DeadZones()
+	if len(zones) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", yellow("The following zones were dead, so the active techniques were skipped:"))
+	for _, zone := range zones {
+		fmt.Fprintf(color.Error, "%s\n", green(zone))
+	}
+}
+
+// printSourceSummary reports the number of selected data sources that are active, and the sources disabled
+// at startup, such as those with missing or rejected API keys. The disabled sources without credentials in
+// the configuration are only listed in verbose mode, since they were not configured to be used.
+func printSourceSummary(cfg *config.Config, sys *systems.LocalSystem, srcs []service.Service, verbose bool) {
+	active := len(datasrcs.SelectedDataSources(cfg, sys.DataSources()))
+	disabled := sys.DisabledDataSources()
+
+	var lines []string
+	var num int
+	for _, src := range datasrcs.SelectedDataSources(cfg, srcs) {
+		reason, found := disabled[src.String()]
+		if !found {
+			continue
+		}
+
+		num++
+		if dsc := cfg.GetDataSourceConfig(src.String()); verbose || (dsc != nil && dsc.GetCredentials() != nil) {
+			lines = append(lines, fmt.Sprintf("%s: %s", src.String(), reason))
+		}
+	}
+
+	fmt.Fprintf(color.Error, "%s\n", green(fmt.Sprintf("%d data sources are active, %d were disabled", active, num)))
+	for _, line := range lines {
+		fmt.Fprintln(color.Error, yellow(line))
+	}
+}
+
+// printPoisonedResolvers reports the resolvers ejected for answering the queries for nonexistent names.
+func printPoisonedResolvers(sys *systems.LocalSystem) {
+	poisoned := sys.PoisonedResolvers()
+	if len(poisoned) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", red("The following resolvers were ejected for returning poisoned answers:"))
+	for _, addr := range poisoned {
+		fmt.Fprintln(color.Error, yellow(addr))
+	}
+}
+
+// printQueryTypes reports the DNS queries sent for each record type, with the fraction that
+// failed and the average latency, so the query budget spent on each type can be reviewed.
+func printQueryTypes(sys *systems.LocalSystem) {
+	types := sys.QueryTypes()
+	if len(types) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", green("DNS queries sent by record type:"))
+	for _, t := range types {
+		failed := fmt.Sprintf("%5.1f%% failed", 100*float64(t.Failures)/float64(t.Queries))
+		if t.Failures > 0 {
+			failed = red(failed)
+		} else {
+			failed = green(failed)
+		}
+
+		fmt.Fprintf(color.Error, "%s %s %s  avg: %s  max: %s\n", blue(fmt.Sprintf("%-8s", t.Type)),
+			yellow(fmt.Sprintf("%10d queries", t.Queries)), failed, t.Latency.Round(time.Millisecond),
+			t.MaxLatency.Round(time.Millisecond))
+	}
+}
+
 func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 	args := enumArgs{
 		AltWordList:       stringset.New(),
@@ -295,6 +543,7 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		BruteWordListMask: stringset.New(),
 		Blacklist:         stringset.New(),
 		Domains:           stringset.New(),
+		DoHResolvers:      stringset.New(),
 		Excluded:          stringset.New(),
 		Included:          stringset.New(),
 		Names:             stringset.New(),
@@ -392,6 +641,12 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		r.Fprintln(color.Error, "Ports can only be scanned in the active mode")
 		os.Exit(1)
 	}
+	// Fall back to the static scope when the scope endpoint cannot be reached
+	if cfg.ScopeURL != "" {
+		if _, err := cfg.LoadScopeURL(context.Background()); err != nil {
+			r.Fprintf(color.Error, "Failed to load the scope from the URL: %v\n", err)
+		}
+	}
 	if len(cfg.Domains()) == 0 {
 		r.Fprintln(color.Error, "Configuration error: No root domain names were provided")
 		os.Exit(1)
@@ -448,32 +703,108 @@ func saveTextOutput(e *enum.Enumeration, args *enumArgs, output chan *requests.O
 		return
 	}
 
-	outptr, err := os.OpenFile(txtfile, os.O_WRONLY|os.O_CREATE, 0644)
+	outptr, err := format.CreateOutputFile(txtfile, false)
 	if err != nil {
 		r.Fprintf(color.Error, "Failed to open the text output file: %v\n", err)
 		os.Exit(1)
 	}
-	defer func() {
-		_ = outptr.Sync()
-		_ = outptr.Close()
-	}()
+	defer func() { _ = outptr.Close() }()
 
-	_ = outptr.Truncate(0)
-	_, _ = outptr.Seek(0, 0)
 	// Save all the output returned by the enumeration
 	for out := range output {
-		out.Addresses = format.DesiredAddrTypes(out.Addresses, args.Options.IPv4, args.Options.IPv6)
-		if !e.Config.Passive && len(out.Addresses) <= 0 {
-			continue
+		if line, ok := textOutputLine(e, args, out); ok {
+			// Write the line to the output file
+			fmt.Fprint(outptr, line)
 		}
+	}
+}
 
-		source, name, ips := format.OutputLineParts(out, args.Options.Sources,
-			args.Options.IPs || args.Options.IPv4 || args.Options.IPv6, args.Options.DemoMode)
-		if ips != "" {
-			ips = " " + ips
+// textOutputLine returns the line written to text output files for the result, and false
+// when the result is not written without addresses of the desired types.
+func textOutputLine(e *enum.Enumeration, args *enumArgs, out *requests.Output) (string, bool) {
+	out.Addresses = format.DesiredAddrTypes(out.Addresses, args.Options.IPv4, args.Options.IPv6)
+	if !e.Config.Passive && len(out.Addresses) <= 0 {
+		return "", false
+	}
+	format.RedactOutput(e.Config, out)
+
+	source, name, ips := format.OutputLineParts(out, args.Options.Sources,
+		args.Options.IPs || args.Options.IPv4 || args.Options.IPv6, args.Options.DemoMode)
+	if ips != "" {
+		ips = " " + ips
+	}
+	return fmt.Sprintf("%s%s%s\n", source, name, ips), true
+}
+
+// startOutputSinks starts a goroutine for each of the output sinks in the configuration,
+// and returns the channels that provide the results to them.
+func startOutputSinks(e *enum.Enumeration, args *enumArgs, wg *sync.WaitGroup) []chan *requests.Output {
+	var outputs []chan *requests.Output
+
+	for _, sink := range e.Config.Outputs {
+		ch := make(chan *requests.Output, 10)
+
+		wg.Add(1)
+		go writeOutputSink(e, args, sink, ch, wg)
+		outputs = append(outputs, ch)
+	}
+	return outputs
+}
+
+// writeOutputSink writes the results to the file of the output sink. A sink that fails is
+// reported and keeps receiving results, so the other sinks and the enumeration are not affected.
+func writeOutputSink(e *enum.Enumeration, args *enumArgs, sink *config.OutputSink, output chan *requests.Output, wg *sync.WaitGroup) {
+	defer wg.Done()
+	// Continue receiving the results after a failure
+	defer func() {
+		for range output {
 		}
-		// Write the line to the output file
-		fmt.Fprintf(outptr, "%s%s%s\n", source, name, ips)
+	}()
+
+	f, err := format.CreateOutputFile(sink.Path, sink.Compress || e.Config.CompressOutputs)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to open the %s output file: %v\n", sink.Format, err)
+		return
+	}
+
+	var failures int
+	check := func(err error) {
+		if err == nil {
+			return
+		}
+		if failures == 0 {
+			r.Fprintf(color.Error, "Failed to write to the %s output file %s: %v\n", sink.Format, sink.Path, err)
+		}
+		failures++
+	}
+
+	switch sink.Format {
+	case "text":
+		for out := range output {
+			if line, ok := textOutputLine(e, args, out); ok {
+				_, err := io.WriteString(f, line)
+				check(err)
+			}
+		}
+	case "json":
+		enc := json.NewEncoder(f)
+		for out := range output {
+			format.RedactOutput(e.Config, out)
+			check(enc.Encode(out))
+		}
+	default:
+		// The graph formats are written from the graph once the enumeration has completed
+		for range output {
+		}
+
+		nodes, edges := viz.VizData(context.TODO(), e.Graph, []string{e.Config.UUID.String()})
+		check(writeGraphData(sink.Format, f, redactNodes(e.Config, nodes), edges))
+	}
+	// The gzip stream is incomplete when the file is not closed
+	check(f.Close())
+
+	if failures > 1 {
+		r.Fprintf(color.Error, "%d writes to the %s output file %s failed\n", failures, sink.Format, sink.Path)
 	}
 }
 
@@ -492,37 +823,238 @@ func saveJSONOutput(e *enum.Enumeration, args *enumArgs, output chan *requests.O
 		return
 	}
 
-	var jsonptr *os.File
+	var jsonptr *format.OutputFile
 	var err error
 
 	// Write to STDOUT and not a file if named "-"
 	if args.Filepaths.JSONOutput == "-" {
-		jsonptr = os.Stdout
+		jsonptr = format.NewOutputFile(os.Stdout, false)
 	} else {
-		jsonptr, err = os.OpenFile(jsonfile, os.O_WRONLY|os.O_CREATE, 0644)
+		jsonptr, err = format.CreateOutputFile(jsonfile, false)
 		if err != nil {
 			r.Fprintf(color.Error, "Failed to open the JSON output file: %v\n", err)
 			os.Exit(1)
 		}
 	}
-
-	defer func() {
-		_ = jsonptr.Sync()
-		_ = jsonptr.Close()
-	}()
-
-	_ = jsonptr.Truncate(0)
-	_, _ = jsonptr.Seek(0, 0)
+	defer func() { _ = jsonptr.Close() }()
 
 	enc := json.NewEncoder(jsonptr)
 	// Save all the output returned by the enumeration
 	for out := range output {
+		format.RedactOutput(e.Config, out)
 		// Handle encoding the result as JSON
 		_ = enc.Encode(out)
 	}
 }
 
-func processOutput(ctx context.Context, e *enum.Enumeration, outputs []chan *requests.Output, done chan struct{}, wg *sync.WaitGroup) {
+func setupJSONStream(e *enum.Enumeration, args *enumArgs, baseline *format.Baseline) func() {
+	var err error
+	var streamptr *format.OutputFile
+
+	// Write to STDOUT and not a file if named "-"
+	stdout := args.Filepaths.JSONStream == "-"
+	if stdout {
+		streamptr = format.NewOutputFile(os.Stdout, false)
+	} else {
+		streamptr, err = format.CreateOutputFile(args.Filepaths.JSONStream, false)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to open the JSON Lines output file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	w := format.NewJSONLinesWriter(streamptr)
+	e.AddOutputHook(func(data pipeline.Data) {
+		if format.WithinLabelDepth(e.Config, data) && baseline.NewData(data) {
+			_ = w.WriteData(format.RedactData(e.Config, data))
+		}
+	})
+
+	return func() {
+		if !stdout {
+			_ = streamptr.Close()
+		}
+	}
+}
+
+// setupUnresolvedOutput writes the in-scope names generated by the enumeration that do not exist
+// to their own JSON Lines file, keeping them out of the results written to the other outputs.
+func setupUnresolvedOutput(e *enum.Enumeration, cfg *config.Config) func() {
+	f, err := format.CreateOutputFile(cfg.OutputUnresolved, cfg.CompressOutputs)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to open the unresolved names output file: %v\n", err)
+		os.Exit(1)
+	}
+
+	w := format.NewJSONLinesWriter(f)
+	e.AddUnresolvedHook(func(data pipeline.Data) {
+		if req, ok := format.RedactData(e.Config, data).(*requests.DNSRequest); ok {
+			_ = w.WriteUnresolved(req)
+		}
+	})
+
+	return func() {
+		_ = f.Close()
+	}
+}
+
+func setupElasticOutput(e *enum.Enumeration, cfg *config.Config, baseline *format.Baseline) func() {
+	w := format.NewElasticWriter(cfg.ElasticSearch, cfg.ProxyClient())
+	e.AddOutputHook(func(data pipeline.Data) {
+		if format.WithinLabelDepth(e.Config, data) && baseline.NewData(data) {
+			_ = w.WriteData(format.RedactData(e.Config, data))
+		}
+	})
+
+	return func() {
+		w.Close()
+		if dropped := w.Dropped(); dropped > 0 {
+			r.Fprintf(color.Error, "%d results were not indexed into Elasticsearch\n", dropped)
+		}
+	}
+}
+
+func setupWebhookOutput(e *enum.Enumeration, cfg *config.Config, baseline *format.Baseline) func() {
+	w := format.NewWebhookWriter(cfg.WebhookURL, cfg.WebhookHeaders, cfg.ProxyClient())
+	e.AddOutputHook(func(data pipeline.Data) {
+		if format.WithinLabelDepth(e.Config, data) && baseline.NewData(data) {
+			_ = w.WriteData(format.RedactData(e.Config, data))
+		}
+	})
+
+	return func() {
+		w.Close()
+		if dropped := w.Dropped(); dropped > 0 {
+			r.Fprintf(color.Error, "%d results were not delivered to the webhook\n", dropped)
+		}
+	}
+}
+
+// setupAlerts raises an alert for each new name matching the alert patterns, which writes a log line
+// and posts the name to the alert webhook, while the other outputs continue to receive every result.
+func setupAlerts(e *enum.Enumeration, cfg *config.Config, baseline *format.Baseline) func() {
+	var w *format.WebhookWriter
+	if cfg.AlertWebhookURL != "" {
+		w = format.NewWebhookWriter(cfg.AlertWebhookURL, cfg.WebhookHeaders, cfg.ProxyClient())
+	}
+
+	e.AddOutputHook(func(data pipeline.Data) {
+		req, ok := data.(*requests.DNSRequest)
+		if !ok || !baseline.NewData(data) {
+			return
+		}
+
+		pattern := cfg.AlertPattern(req.Name)
+		if pattern == "" {
+			return
+		}
+
+		// The patterns match the full name, while the alerts only carry the redacted name
+		redacted := format.RedactData(e.Config, data)
+		msg := fmt.Sprintf("Alert: %s matched the pattern %s", redacted.(*requests.DNSRequest).Name, pattern)
+		cfg.Log.Print(msg)
+		fgY.Fprintln(color.Error, msg)
+		if w != nil {
+			_ = w.WriteData(redacted)
+		}
+	})
+
+	return func() {
+		if w == nil {
+			return
+		}
+
+		w.Close()
+		if dropped := w.Dropped(); dropped > 0 {
+			r.Fprintf(color.Error, "%d alerts were not delivered to the webhook\n", dropped)
+		}
+	}
+}
+
+// importScanHosts submits the live hosts found in the port scanner output provided by the -import flag.
+func importScanHosts(e *enum.Enumeration, args *enumArgs) error {
+	for _, spec := range args.Filepaths.Imports {
+		hosts, err := format.LoadScanImport(spec)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		for _, h := range hosts {
+			if args.Options.ImportWebOnly && !h.HasWebPort() {
+				continue
+			}
+
+			e.InputScannedHost(h.Address, h.Names, h.Ports...)
+			count++
+		}
+		g.Fprintf(color.Error, "Imported %d hosts from %s\n", count, spec)
+	}
+	return nil
+}
+
+// importPriorNames submits the names found in the previous enumeration output provided by the -prior flag.
+func importPriorNames(e *enum.Enumeration, args *enumArgs) error {
+	for _, path := range args.Filepaths.Prior {
+		names, err := format.LoadOutputNames(path)
+		if err != nil {
+			return err
+		}
+
+		count := e.InputPriorNames(names)
+		g.Fprintf(color.Error, "Imported %d names from %s\n", count, path)
+	}
+	return nil
+}
+
+func setupAPIServer(e *enum.Enumeration, cfg *config.Config) func() {
+	lis, err := net.Listen("tcp", cfg.APIAddr)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to listen on %s: %v\n", cfg.APIAddr, err)
+		os.Exit(1)
+	}
+
+	s := api.NewServer(e)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			r.Fprintf(color.Error, "The API server failed: %v\n", err)
+		}
+	}()
+	g.Fprintf(color.Error, "The results API is listening on http://%s/api/v1\n", lis.Addr().String())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = s.Close(ctx)
+	}
+}
+
+func setupHealthServer(e *enum.Enumeration, cfg *config.Config) func() {
+	lis, err := net.Listen("tcp", cfg.HealthAddr)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to listen on %s: %v\n", cfg.HealthAddr, err)
+		os.Exit(1)
+	}
+
+	s := api.NewHealthServer(e)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			r.Fprintf(color.Error, "The health server failed: %v\n", err)
+		}
+	}()
+	g.Fprintf(color.Error, "The health endpoints are listening on http://%s\n", lis.Addr().String())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = s.Close(ctx)
+	}
+}
+
+func processOutput(ctx context.Context, e *enum.Enumeration, outputs []chan *requests.Output,
+	baseline *format.Baseline, done chan struct{}, wg *sync.WaitGroup) {
 	defer wg.Done()
 	defer func() {
 		// Signal all the other output goroutines to terminate
@@ -540,9 +1072,18 @@ func processOutput(ctx context.Context, e *enum.Enumeration, outputs []chan *req
 			if !o.Complete(e.Config.Passive) || !e.Config.IsDomainInScope(o.Name) {
 				continue
 			}
+			// The names nested deeper than the max label depth are only kept in the graph database
+			if !e.Config.WithinLabelDepth(o.Name) {
+				continue
+			}
+			// Discoveries already reported by the baseline enumeration are not output again
+			if !baseline.NewOutput(o) {
+				continue
+			}
 
+			// Each output goroutine receives its own copy, since some of them modify the result
 			for _, ch := range outputs {
-				ch <- o
+				ch <- o.Clone().(*requests.Output)
 			}
 		}
 	}
@@ -558,7 +1099,10 @@ func processOutput(ctx context.Context, e *enum.Enumeration, outputs []chan *req
 			extract(0)
 			return
 		case <-t.C:
-			extract(100)
+			// The results of deterministic enumerations are only output in order once complete
+			if !e.Config.Deterministic {
+				extract(100)
+			}
 		}
 	}
 }
@@ -704,12 +1248,22 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if len(e.CIDRs) > 0 {
 		conf.CIDRs = e.CIDRs
 	}
+	if len(e.ExpandASNs) > 0 {
+		conf.ExpandByASN = true
+		conf.ExpandASNs = e.ExpandASNs
+	}
 	if len(e.Ports) > 0 {
 		conf.Ports = e.Ports
 	}
 	if e.Filepaths.Directory != "" {
 		conf.Dir = e.Filepaths.Directory
 	}
+	if e.Filepaths.Unresolved != "" {
+		conf.OutputUnresolved = e.Filepaths.Unresolved
+	}
+	if e.Filepaths.Checkpoint != "" {
+		conf.CheckpointPath = e.Filepaths.Checkpoint
+	}
 	if e.Filepaths.ScriptsDirectory != "" {
 		conf.ScriptsDirectory = e.Filepaths.ScriptsDirectory
 	}
@@ -740,15 +1294,23 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if e.MaxDepth != 0 {
 		conf.MaxDepth = e.MaxDepth
 	}
+	if e.ResolveRepeat != 0 {
+		conf.ResolveRepeat = e.ResolveRepeat
+	}
 	if e.Options.Active {
 		conf.Active = true
 		conf.Passive = false
 	}
+	// The phases are overridden first, since the passive mode runs without them
+	if e.Options.Phased {
+		conf.Phased = true
+	}
 	if e.Options.Passive {
 		conf.Passive = true
 		conf.Active = false
 		conf.BruteForcing = false
 		conf.Alterations = false
+		conf.Phased = false
 	}
 	if e.Blacklist.Len() > 0 {
 		conf.Blacklist = e.Blacklist.Slice()
@@ -756,12 +1318,36 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if e.Options.Verbose {
 		conf.Verbose = true
 	}
+	if e.Options.CTTail {
+		conf.CTTail = true
+	}
+	if e.Options.Deterministic {
+		conf.Deterministic = true
+	}
+	if e.Options.TrustedOnly {
+		conf.TrustedOnly = true
+	}
+	if e.Options.IPv4Only {
+		conf.IPv4Only = true
+	}
+	if e.Options.IPv6Only {
+		conf.IPv6Only = true
+	}
 	if e.Resolvers.Len() > 0 {
 		conf.SetResolvers(e.Resolvers.Slice()...)
 	}
+	if e.DoHResolvers.Len() > 0 {
+		conf.DoHResolvers = e.DoHResolvers.Slice()
+	}
 	if e.MaxDNSQueries > 0 {
 		conf.MaxDNSQueries = e.MaxDNSQueries
 	}
+	if e.ScopeURL != "" {
+		conf.ScopeURL = e.ScopeURL
+	}
+	if e.Proxy != "" {
+		conf.Proxy = e.Proxy
+	}
 
 	if e.Included.Len() > 0 {
 		conf.SourceFilter.Include = true
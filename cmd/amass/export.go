@@ -0,0 +1,311 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/caffix/netmap"
+	"github.com/fatih/color"
+)
+
+// The directory within the output directory that keeps the snapshots of previous exports
+const exportDirName = "exports"
+
+var (
+	exportNodeTypes  = []string{netmap.TypeFQDN, netmap.TypeAddr, netmap.TypeNetblock, netmap.TypeAS}
+	snapshotIDRegexp = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+// exportRecord is a single line of the graph export. The op field is add or remove for the
+// nodes and edges, and marker for the final line that identifies the snapshot of the export.
+type exportRecord struct {
+	Op        string `json:"op"`
+	Kind      string `json:"kind,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Type      string `json:"type,omitempty"`
+	From      string `json:"from,omitempty"`
+	Predicate string `json:"predicate,omitempty"`
+	To        string `json:"to,omitempty"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	Snapshot  string `json:"snapshot,omitempty"`
+	Since     string `json:"since,omitempty"`
+	Time      string `json:"time,omitempty"`
+}
+
+type snapshotNode struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+}
+
+type snapshotEdge struct {
+	From      string `json:"from"`
+	Predicate string `json:"predicate"`
+	To        string `json:"to"`
+}
+
+// graphSnapshot holds the nodes and edges of the graph at the time of an export, which the
+// next export is compared with to find the nodes and edges that were added and removed.
+type graphSnapshot struct {
+	ID    string          `json:"id"`
+	Time  string          `json:"time"`
+	Nodes []*snapshotNode `json:"nodes"`
+	Edges []*snapshotEdge `json:"edges"`
+}
+
+// exportGraphChanges writes the nodes and edges added and removed since the marker provided by
+// the -since flag, which is either the snapshot ID of a previous export or an RFC3339 time. All
+// the nodes and edges are written as additions when a marker is not provided, seeding the first sync.
+func exportGraphChanges(ctx context.Context, args *dbArgs, db *netmap.Graph) error {
+	dir := filepath.Join(config.OutputDirectory(args.Filepaths.Directory), exportDirName)
+
+	cur, err := takeSnapshot(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var records []*exportRecord
+	if since := args.Since; since == "" {
+		records = diffSnapshots(&graphSnapshot{}, cur)
+	} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+		records = changesSinceTime(cur, t)
+	} else {
+		prev, err := loadSnapshot(dir, since)
+		if err != nil {
+			return err
+		}
+		records = diffSnapshots(prev, cur)
+	}
+	records = append(records, &exportRecord{
+		Op:       "marker",
+		Snapshot: cur.ID,
+		Since:    args.Since,
+		Time:     cur.Time,
+	})
+
+	var out io.Writer = os.Stdout
+	if args.Filepaths.Export != "-" {
+		f, err := format.CreateOutputFile(args.Filepaths.Export, false)
+		if err != nil {
+			return fmt.Errorf("failed to open the export file: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	// The gzip stream is incomplete when the file is not closed
+	if f, ok := out.(*format.OutputFile); ok {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	// The snapshot is only kept once the export that refers to it has been written
+	if err := saveSnapshot(dir, cur); err != nil {
+		return err
+	}
+
+	g.Fprintf(color.Error, "Exported %d changes, use -since %s for the next export\n", len(records)-1, cur.ID)
+	return nil
+}
+
+// takeSnapshot returns the nodes and edges of the graph in a stable order.
+func takeSnapshot(ctx context.Context, db *netmap.Graph) (*graphSnapshot, error) {
+	s := &graphSnapshot{Time: time.Now().UTC().Format(time.RFC3339)}
+
+	for _, ntype := range exportNodeTypes {
+		nodes, err := db.AllNodesOfType(ctx, ntype)
+		if err != nil {
+			continue
+		}
+
+		for _, node := range nodes {
+			id := db.NodeToID(node)
+			n := &snapshotNode{ID: id, Type: ntype}
+			if first, last := enum.SeenTimes(ctx, db, id); !first.IsZero() {
+				n.FirstSeen = first.Format(time.RFC3339)
+				n.LastSeen = last.Format(time.RFC3339)
+			}
+			s.Nodes = append(s.Nodes, n)
+
+			// The out edges of the asset nodes only reach other assets
+			edges, err := db.ReadOutEdges(ctx, node)
+			if err != nil {
+				continue
+			}
+			for _, e := range edges {
+				s.Edges = append(s.Edges, &snapshotEdge{
+					From:      id,
+					Predicate: e.Predicate,
+					To:        db.NodeToID(e.To),
+				})
+			}
+		}
+	}
+
+	sort.Slice(s.Nodes, func(i, j int) bool {
+		return nodeKey(s.Nodes[i]) < nodeKey(s.Nodes[j])
+	})
+	sort.Slice(s.Edges, func(i, j int) bool {
+		return edgeKey(s.Edges[i]) < edgeKey(s.Edges[j])
+	})
+
+	// The ID only depends on the nodes and edges, so an unchanged graph keeps the same ID
+	h := sha256.New()
+	for _, n := range s.Nodes {
+		fmt.Fprintln(h, nodeKey(n))
+	}
+	for _, e := range s.Edges {
+		fmt.Fprintln(h, edgeKey(e))
+	}
+	s.ID = hex.EncodeToString(h.Sum(nil))[:16]
+	return s, nil
+}
+
+// diffSnapshots returns the removals followed by the additions that turn prev into cur.
+func diffSnapshots(prev, cur *graphSnapshot) []*exportRecord {
+	prevNodes := make(map[string]*snapshotNode, len(prev.Nodes))
+	for _, n := range prev.Nodes {
+		prevNodes[nodeKey(n)] = n
+	}
+	curNodes := make(map[string]*snapshotNode, len(cur.Nodes))
+	for _, n := range cur.Nodes {
+		curNodes[nodeKey(n)] = n
+	}
+	prevEdges := make(map[string]struct{}, len(prev.Edges))
+	for _, e := range prev.Edges {
+		prevEdges[edgeKey(e)] = struct{}{}
+	}
+	curEdges := make(map[string]struct{}, len(cur.Edges))
+	for _, e := range cur.Edges {
+		curEdges[edgeKey(e)] = struct{}{}
+	}
+
+	var records []*exportRecord
+	// Edges are removed before the nodes they connect
+	for _, e := range prev.Edges {
+		if _, found := curEdges[edgeKey(e)]; !found {
+			records = append(records, edgeRecord("remove", e))
+		}
+	}
+	for _, n := range prev.Nodes {
+		if _, found := curNodes[nodeKey(n)]; !found {
+			records = append(records, nodeRecord("remove", n))
+		}
+	}
+	// Nodes are added before the edges that connect them
+	for _, n := range cur.Nodes {
+		if _, found := prevNodes[nodeKey(n)]; !found {
+			records = append(records, nodeRecord("add", n))
+		}
+	}
+	for _, e := range cur.Edges {
+		if _, found := prevEdges[edgeKey(e)]; !found {
+			records = append(records, edgeRecord("add", e))
+		}
+	}
+	return records
+}
+
+// changesSinceTime returns the nodes first seen after the time, and the edges that reach them.
+// Removals cannot be found from the timestamps, so only additions are returned.
+func changesSinceTime(cur *graphSnapshot, since time.Time) []*exportRecord {
+	var records []*exportRecord
+
+	added := make(map[string]struct{})
+	for _, n := range cur.Nodes {
+		if first, err := time.Parse(time.RFC3339, n.FirstSeen); err == nil && first.After(since) {
+			added[n.ID] = struct{}{}
+			records = append(records, nodeRecord("add", n))
+		}
+	}
+	for _, e := range cur.Edges {
+		_, from := added[e.From]
+		_, to := added[e.To]
+		if from || to {
+			records = append(records, edgeRecord("add", e))
+		}
+	}
+	return records
+}
+
+func loadSnapshot(dir, id string) (*graphSnapshot, error) {
+	if !snapshotIDRegexp.MatchString(id) {
+		return nil, fmt.Errorf("%s is not a snapshot ID or RFC3339 time", id)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snapshot %s: %v", id, err)
+	}
+
+	var s graphSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse the snapshot %s: %v", id, err)
+	}
+	return &s, nil
+}
+
+func saveSnapshot(dir string, s *graphSnapshot) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the snapshot directory: %v", err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, s.ID+".json"), data, 0644)
+}
+
+func nodeRecord(op string, n *snapshotNode) *exportRecord {
+	return &exportRecord{
+		Op:        op,
+		Kind:      "node",
+		ID:        n.ID,
+		Type:      n.Type,
+		FirstSeen: n.FirstSeen,
+		LastSeen:  n.LastSeen,
+	}
+}
+
+func edgeRecord(op string, e *snapshotEdge) *exportRecord {
+	return &exportRecord{
+		Op:        op,
+		Kind:      "edge",
+		From:      e.From,
+		Predicate: e.Predicate,
+		To:        e.To,
+	}
+}
+
+func nodeKey(n *snapshotNode) string {
+	return n.Type + "\x00" + n.ID
+}
+
+func edgeKey(e *snapshotEdge) string {
+	return e.From + "\x00" + e.Predicate + "\x00" + e.To
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caffix/netmap"
+)
+
+func recordKeys(records []*exportRecord) []string {
+	var keys []string
+
+	for _, r := range records {
+		if r.Kind == "node" {
+			keys = append(keys, r.Op+" node "+r.ID)
+		} else {
+			keys = append(keys, r.Op+" edge "+r.From+" "+r.Predicate+" "+r.To)
+		}
+	}
+	return keys
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	www := &snapshotNode{ID: "www.owasp.org", Type: netmap.TypeFQDN}
+	mail := &snapshotNode{ID: "mail.owasp.org", Type: netmap.TypeFQDN}
+	addr := &snapshotNode{ID: "192.0.2.1", Type: netmap.TypeAddr}
+	wwwA := &snapshotEdge{From: "www.owasp.org", Predicate: "a_record", To: "192.0.2.1"}
+	mailA := &snapshotEdge{From: "mail.owasp.org", Predicate: "a_record", To: "192.0.2.1"}
+
+	tests := []struct {
+		name     string
+		prev     *graphSnapshot
+		cur      *graphSnapshot
+		expected []string
+	}{
+		{
+			name: "first export",
+			prev: &graphSnapshot{},
+			cur:  &graphSnapshot{Nodes: []*snapshotNode{www, addr}, Edges: []*snapshotEdge{wwwA}},
+			expected: []string{
+				"add node www.owasp.org",
+				"add node 192.0.2.1",
+				"add edge www.owasp.org a_record 192.0.2.1",
+			},
+		},
+		{
+			name:     "unchanged graph",
+			prev:     &graphSnapshot{Nodes: []*snapshotNode{www, addr}, Edges: []*snapshotEdge{wwwA}},
+			cur:      &graphSnapshot{Nodes: []*snapshotNode{www, addr}, Edges: []*snapshotEdge{wwwA}},
+			expected: nil,
+		},
+		{
+			name: "node and edge replaced",
+			prev: &graphSnapshot{Nodes: []*snapshotNode{www, addr}, Edges: []*snapshotEdge{wwwA}},
+			cur:  &graphSnapshot{Nodes: []*snapshotNode{mail, addr}, Edges: []*snapshotEdge{mailA}},
+			expected: []string{
+				"remove edge www.owasp.org a_record 192.0.2.1",
+				"remove node www.owasp.org",
+				"add node mail.owasp.org",
+				"add edge mail.owasp.org a_record 192.0.2.1",
+			},
+		},
+		{
+			name: "graph emptied",
+			prev: &graphSnapshot{Nodes: []*snapshotNode{www, addr}, Edges: []*snapshotEdge{wwwA}},
+			cur:  &graphSnapshot{},
+			expected: []string{
+				"remove edge www.owasp.org a_record 192.0.2.1",
+				"remove node www.owasp.org",
+				"remove node 192.0.2.1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordKeys(diffSnapshots(tt.prev, tt.cur)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("diffSnapshots() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChangesSinceTime(t *testing.T) {
+	old := &snapshotNode{ID: "www.owasp.org", Type: netmap.TypeFQDN, FirstSeen: "2021-01-01T00:00:00Z"}
+	recent := &snapshotNode{ID: "mail.owasp.org", Type: netmap.TypeFQDN, FirstSeen: "2021-06-01T12:00:00Z"}
+	unseen := &snapshotNode{ID: "192.0.2.1", Type: netmap.TypeAddr}
+	cur := &graphSnapshot{
+		Nodes: []*snapshotNode{old, recent, unseen},
+		Edges: []*snapshotEdge{
+			{From: "www.owasp.org", Predicate: "a_record", To: "192.0.2.1"},
+			{From: "mail.owasp.org", Predicate: "a_record", To: "192.0.2.1"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		since    string
+		expected []string
+	}{
+		{
+			name:  "before every node",
+			since: "2020-12-31T00:00:00Z",
+			expected: []string{
+				"add node www.owasp.org",
+				"add node mail.owasp.org",
+				"add edge www.owasp.org a_record 192.0.2.1",
+				"add edge mail.owasp.org a_record 192.0.2.1",
+			},
+		},
+		{
+			name:  "between the nodes",
+			since: "2021-03-01T00:00:00Z",
+			expected: []string{
+				"add node mail.owasp.org",
+				"add edge mail.owasp.org a_record 192.0.2.1",
+			},
+		},
+		{
+			name:     "time zone offset",
+			since:    "2021-06-01T14:00:00+02:00",
+			expected: nil,
+		},
+		{
+			name:     "after every node",
+			since:    "2021-07-01T00:00:00Z",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, err := time.Parse(time.RFC3339, tt.since)
+			if err != nil {
+				t.Fatalf("Failed to parse the time %s: %v", tt.since, err)
+			}
+			if got := recordKeys(changesSinceTime(cur, since)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("changesSinceTime() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@ func runHelpCommand(clArgs []string) {
 		return
 	}
 	switch clArgs[0] {
+	case "config":
+		runConfigCommand(help)
 	case "db":
 		runDBCommand(help)
 	case "dns":
@@ -23,6 +25,8 @@ func runHelpCommand(clArgs []string) {
 		runEnumCommand(help)
 	case "intel":
 		runIntelCommand(help)
+	case "resolvers":
+		runResolversCommand(help)
 	case "track":
 		runTrackCommand(help)
 	case "viz":
@@ -28,7 +28,7 @@ import (
 )
 
 const (
-	intelUsageMsg = "intel [options] [-whois -d DOMAIN] [-addr ADDR -asn ASN -cidr CIDR]"
+	intelUsageMsg = "intel [options] [-whois -d DOMAIN] [-typosquat -d DOMAIN] [-org NAME] [-addr ADDR -asn ASN -cidr CIDR]"
 )
 
 type intelArgs struct {
@@ -36,6 +36,7 @@ type intelArgs struct {
 	ASNs             format.ParseASNs
 	CIDRs            format.ParseCIDRs
 	OrganizationName string
+	OrganizationID   string
 	Domains          *stringset.Set
 	Excluded         *stringset.Set
 	Included         *stringset.Set
@@ -43,6 +44,7 @@ type intelArgs struct {
 	Ports            format.ParseInts
 	Resolvers        *stringset.Set
 	Timeout          int
+	TypoSquatMax     int
 	Options          struct {
 		Active       bool
 		DemoMode     bool
@@ -52,6 +54,7 @@ type intelArgs struct {
 		ListSources  bool
 		ReverseWhois bool
 		Sources      bool
+		TypoSquat    bool
 		Verbose      bool
 	}
 	Filepaths struct {
@@ -70,7 +73,8 @@ func defineIntelArgumentFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.Var(&args.Addresses, "addr", "IPs and ranges (192.168.1.1-254) separated by commas")
 	intelFlags.Var(&args.ASNs, "asn", "ASNs separated by commas (can be used multiple times)")
 	intelFlags.Var(&args.CIDRs, "cidr", "CIDRs separated by commas (can be used multiple times)")
-	intelFlags.StringVar(&args.OrganizationName, "org", "", "Search string provided against AS description information")
+	intelFlags.StringVar(&args.OrganizationName, "org", "", "Organization name searched for in RDAP and AS description information")
+	intelFlags.StringVar(&args.OrganizationID, "org-id", "", "ID of the organization to use when the -org search matches several")
 	intelFlags.Var(args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	intelFlags.Var(args.Excluded, "exclude", "Data source names separated by commas to be excluded")
 	intelFlags.Var(args.Included, "include", "Data source names separated by commas to be included")
@@ -78,6 +82,7 @@ func defineIntelArgumentFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 80, 443)")
 	intelFlags.Var(args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
 	intelFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
+	intelFlags.IntVar(&args.TypoSquatMax, "typosquat-max", intel.DefaultTypoSquatLimit, "Maximum number of typo-squatting permutations checked for each domain")
 }
 
 func defineIntelOptionFlags(intelFlags *flag.FlagSet, args *intelArgs) {
@@ -89,6 +94,7 @@ func defineIntelOptionFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.BoolVar(&args.Options.ListSources, "list", false, "Print additional information")
 	intelFlags.BoolVar(&args.Options.ReverseWhois, "whois", false, "All provided domains are run through reverse whois")
 	intelFlags.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
+	intelFlags.BoolVar(&args.Options.TypoSquat, "typosquat", false, "Report the typo-squatting permutations of the domains that are registered or resolve")
 	intelFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
 }
 
@@ -166,7 +172,7 @@ func runIntelCommand(clArgs []string) {
 	}
 
 	// Some input validation
-	if !args.Options.ReverseWhois && args.OrganizationName == "" && !args.Options.ListSources &&
+	if !args.Options.ReverseWhois && !args.Options.TypoSquat && args.OrganizationName == "" && !args.Options.ListSources &&
 		len(args.Addresses) == 0 && len(args.CIDRs) == 0 && len(args.ASNs) == 0 {
 		commandUsage(intelUsageMsg, intelCommand, intelBuf)
 		os.Exit(1)
@@ -200,16 +206,6 @@ func runIntelCommand(clArgs []string) {
 	}
 	sys.SetDataSources(datasrcs.GetAllSources(sys))
 
-	if args.OrganizationName != "" {
-		var asns []int
-		for _, entry := range sys.Cache().DescriptionSearch(args.OrganizationName) {
-			asns = append(asns, entry.ASN)
-		}
-		if len(asns) > 0 {
-			printNetblocks(asns, cfg, sys)
-		}
-		return
-	}
 	// Check if the user requested additional ASN & netblock information
 	if args.Options.ListSources && len(args.ASNs) > 0 {
 		printNetblocks(args.ASNs, cfg, sys)
@@ -222,7 +218,14 @@ func runIntelCommand(clArgs []string) {
 		os.Exit(1)
 	}
 
-	if args.Options.ReverseWhois {
+	if args.OrganizationName != "" {
+		cand := selectOrganization(ic, &args)
+
+		args.Options.IPs = false
+		args.Options.IPv4 = false
+		args.Options.IPv6 = false
+		go func() { _ = ic.OrganizationDomains(cand) }()
+	} else if args.Options.ReverseWhois {
 		if len(ic.Config.Domains()) == 0 {
 			r.Fprintln(color.Error, "No root domain names were provided")
 			os.Exit(1)
@@ -232,6 +235,13 @@ func runIntelCommand(clArgs []string) {
 		args.Options.IPv4 = false
 		args.Options.IPv6 = false
 		go func() { _ = ic.ReverseWhois() }()
+	} else if args.Options.TypoSquat {
+		if len(ic.Config.Domains()) == 0 {
+			r.Fprintln(color.Error, "No root domain names were provided")
+			os.Exit(1)
+		}
+
+		go func() { _ = ic.TypoSquats(context.Background(), args.TypoSquatMax) }()
 	} else {
 		var ctx context.Context
 		var cancel context.CancelFunc
@@ -259,6 +269,40 @@ func runIntelCommand(clArgs []string) {
 	processIntelOutput(ic, &args)
 }
 
+// selectOrganization returns the organization matching the -org and -org-id flags, with its registered
+// domains and netblocks added to the scope. The candidates are listed when the user needs to select one.
+func selectOrganization(ic *intel.Collection, args *intelArgs) *intel.OrgCandidate {
+	ctx := context.Background()
+
+	cands, err := ic.OrganizationCandidates(ctx, args.OrganizationName)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	cand, err := intel.SelectOrgCandidate(cands, args.OrganizationID)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		if len(cands) > 1 && args.OrganizationID == "" {
+			for _, c := range cands {
+				fmt.Fprintf(color.Error, "%s %s %s\n", yellow(c.ID), green("-"), green(c.Name+" ("+c.Source+")"))
+			}
+			r.Fprintln(color.Error, "Select one of the organizations using the -org-id flag")
+		}
+		os.Exit(1)
+	}
+
+	ic.OrganizationScope(ctx, cand)
+	fmt.Fprintf(color.Error, "%s%s %s %s\n", blue("Organization: "), yellow(cand.ID), green("-"), green(cand.Name))
+	for _, asn := range cand.ASNs {
+		fmt.Fprintf(color.Error, "%s\n", yellow(fmt.Sprintf("\tAS%d", asn)))
+	}
+	for _, cidr := range cand.Netblocks {
+		fmt.Fprintf(color.Error, "%s\n", yellow(fmt.Sprintf("\t%s", cidr)))
+	}
+	return cand
+}
+
 func printNetblocks(asns []int, cfg *config.Config, sys systems.System) {
 	for _, asn := range asns {
 		systems.PopulateCache(context.Background(), asn, sys)
@@ -284,19 +328,14 @@ func processIntelOutput(ic *intel.Collection, args *intelArgs) {
 		txtfile = args.Filepaths.TermOut
 	}
 
-	var outptr *os.File
+	var outptr *format.OutputFile
 	if txtfile != "" {
-		outptr, err = os.OpenFile(txtfile, os.O_WRONLY|os.O_CREATE, 0644)
+		outptr, err = format.CreateOutputFile(txtfile, false)
 		if err != nil {
 			r.Fprintf(color.Error, "Failed to open the text output file: %v\n", err)
 			os.Exit(1)
 		}
-		defer func() {
-			_ = outptr.Sync()
-			_ = outptr.Close()
-		}()
-		_ = outptr.Truncate(0)
-		_, _ = outptr.Seek(0, 0)
+		defer func() { _ = outptr.Close() }()
 	}
 
 	// Collect all the names returned by the intelligence collection
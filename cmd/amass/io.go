@@ -7,9 +7,11 @@ import (
 	"context"
 	"math/rand"
 	"net"
+	"sort"
 	"time"
 
 	"github.com/OWASP/Amass/v3/enum"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/caffix/netmap"
 	"github.com/caffix/service"
@@ -25,11 +27,31 @@ func init() {
 
 // ExtractOutput is a convenience method for obtaining new discoveries made by the enumeration process.
 func ExtractOutput(ctx context.Context, e *enum.Enumeration, filter *stringset.Set, asinfo bool, limit int) []*requests.Output {
+	var outputs []*requests.Output
+
 	if e.Config.Passive {
-		return EventNames(ctx, e.Graph, e.Config.UUID.String(), filter)
+		outputs = EventNames(ctx, e.Graph, e.Config.UUID.String(), filter)
+		for _, o := range outputs {
+			o.Score = e.NameScore(o.Name)
+			o.Provenance = e.Provenance(o.Name)
+		}
+	} else {
+		outputs = EventOutput(ctx, e.Graph, e.Config.UUID.String(), filter, asinfo, e.Sys.Cache(), limit)
+		for _, o := range outputs {
+			o.Suspect = e.SuspectedWildcard(o.Name)
+			o.Score = e.NameScore(o.Name)
+			o.Provenance = e.Provenance(o.Name)
+			o.Authoritative = e.ResolvedAuthoritatively(o.Name)
+			o.DNSSEC = e.DNSSECStatus(o.Name)
+		}
 	}
 
-	return EventOutput(ctx, e.Graph, e.Config.UUID.String(), filter, asinfo, e.Sys.Cache(), limit)
+	if e.Config.Deterministic {
+		sort.SliceStable(outputs, func(i, j int) bool {
+			return outputs[i].Name < outputs[j].Name
+		})
+	}
+	return outputs
 }
 
 type outLookup map[string]*requests.Output
@@ -169,10 +191,19 @@ func buildNameInfo(ctx context.Context, g *netmap.Graph, uuid string, names []st
 
 		n := netmap.Node(name)
 		if srcs, err := g.NodeSources(ctx, n, uuid); err == nil && len(srcs) > 0 {
-			results[name] = &requests.Output{
+			o := &requests.Output{
 				Name:    name,
 				Sources: srcs,
 			}
+			if u := amassdns.ToUnicode(name); u != name {
+				o.UnicodeName = u
+			}
+
+			if first, last := enum.SeenTimes(ctx, g, name); !first.IsZero() {
+				o.FirstSeen = first.Format(time.RFC3339)
+				o.LastSeen = last.Format(time.RFC3339)
+			}
+			results[name] = o
 		}
 	}
 
@@ -197,6 +228,9 @@ func initializeSourceTags(srcs []service.Service) {
 	sourceTags["DNS Zone XFR"] = requests.AXFR
 	sourceTags["Active Crawl"] = requests.CRAWL
 	sourceTags["Active Cert"] = requests.CERT
+	sourceTags["Active VHost"] = requests.CERT
+	sourceTags["mDNS"] = requests.LOCAL
+	sourceTags["LLMNR"] = requests.LOCAL
 
 	for _, src := range srcs {
 		sourceTags[src.String()] = src.Description()
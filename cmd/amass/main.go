@@ -48,7 +48,7 @@ import (
 )
 
 const (
-	mainUsageMsg         = "intel|enum|viz|track|db|dns [options]"
+	mainUsageMsg         = "intel|enum|viz|track|db|dns|resolvers|serve|config [options]"
 	exampleConfigFileURL = "https://github.com/OWASP/Amass/blob/master/examples/config.ini"
 	userGuideURL         = "https://github.com/OWASP/Amass/blob/master/doc/user_guide.md"
 	tutorialURL          = "https://github.com/OWASP/Amass/blob/master/doc/tutorial.md"
@@ -80,7 +80,10 @@ func commandUsage(msg string, cmdFlagSet *flag.FlagSet, errBuf *bytes.Buffer) {
 		g.Fprintf(color.Error, "\t%-11s - Visualize enumeration results\n", "amass viz")
 		g.Fprintf(color.Error, "\t%-11s - Track differences between enumerations\n", "amass track")
 		g.Fprintf(color.Error, "\t%-11s - Manipulate the Amass graph database\n", "amass db")
-		g.Fprintf(color.Error, "\t%-11s - Resolve DNS names at high performance\n\n", "amass dns")
+		g.Fprintf(color.Error, "\t%-11s - Resolve DNS names at high performance\n", "amass dns")
+		g.Fprintf(color.Error, "\t%-11s - Benchmark the DNS resolvers\n", "amass resolvers")
+		g.Fprintf(color.Error, "\t%-11s - Serve the gRPC enumeration control API\n", "amass serve")
+		g.Fprintf(color.Error, "\t%-11s - Check the data source credentials of the configuration\n\n", "amass config")
 	}
 
 	g.Fprintf(color.Error, "The user's guide can be found here: \n%s\n\n", userGuideURL)
@@ -117,6 +120,8 @@ func main() {
 	}
 
 	switch os.Args[1] {
+	case "config":
+		runConfigCommand(os.Args[2:])
 	case "db":
 		runDBCommand(os.Args[2:])
 	case "dns":
@@ -125,6 +130,10 @@ func main() {
 		runEnumCommand(os.Args[2:])
 	case "intel":
 		runIntelCommand(os.Args[2:])
+	case "resolvers":
+		runResolversCommand(os.Args[2:])
+	case "serve":
+		runServeCommand(os.Args[2:])
 	case "track":
 		runTrackCommand(os.Args[2:])
 	case "viz":
@@ -226,6 +235,17 @@ func expandCategoryNames(names []string, categories map[string][]string) []strin
 }
 
 func openGraphDatabase(dir string, cfg *config.Config) *netmap.Graph {
+	// The backend selected by the configuration is used instead of the other graph databases
+	if dbs := cfg.SelectedDatabases(dir); len(dbs) > 0 {
+		// The first endpoint that can be reached is used
+		for _, db := range dbs {
+			if g, err := systems.OpenGraphDatabase(db); err == nil {
+				return g
+			}
+		}
+		return nil
+	}
+
 	for _, db := range cfg.GraphDBs {
 		if !db.Primary {
 			continue
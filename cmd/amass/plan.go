@@ -0,0 +1,125 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/resolve"
+	"github.com/caffix/service"
+	"github.com/fatih/color"
+	"github.com/miekg/dns"
+)
+
+const planProbeTimeout = 5 * time.Second
+
+type resolverProbe struct {
+	Resolver string
+	Latency  time.Duration
+	Err      error
+}
+
+// printPlan reports the data sources, DNS query estimates and resolvers that the enumeration
+// would use, without sending any of the enumeration traffic.
+func printPlan(cfg *config.Config, sys systems.System, all []service.Service) {
+	mode := "normal"
+	if cfg.Active {
+		mode = "active"
+	} else if cfg.Passive {
+		mode = "passive"
+	}
+
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Domains:"), green(strings.Join(cfg.Domains(), ", ")))
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Mode:"), yellow(mode))
+	for _, domain := range cfg.Domains() {
+		fmt.Fprintf(color.Output, "%s %s %s\n", blue("Domain:"), green(domain),
+			yellow(fmt.Sprintf("brute forcing: %t (%d words), alterations: %t",
+				cfg.BruteForcingFor(domain), len(cfg.WordlistFor(domain)), cfg.AlterationsFor(domain))))
+	}
+	fmt.Fprintln(color.Output)
+
+	// Data sources that failed to start, such as those missing API keys, are not available
+	for _, line := range DataSourceInfo(datasrcs.SelectedDataSources(cfg, all), sys) {
+		fmt.Fprintln(color.Output, line)
+	}
+	fmt.Fprintln(color.Output)
+
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Estimated initial DNS queries:"),
+		yellow(fmt.Sprintf("%d", enum.PlannedQueries(cfg))))
+	// The input source keeps this many names and addresses in flight
+	fmt.Fprintf(color.Output, "%s %s\n\n", blue("Maximum concurrent DNS queries:"),
+		yellow(fmt.Sprintf("%d", cfg.MaxDNSQueries)))
+
+	if cfg.Passive {
+		return
+	}
+
+	var unreachable int
+	probes := probeResolvers(cfg)
+	for _, p := range probes {
+		if p.Err != nil {
+			unreachable++
+			fmt.Fprintf(color.Output, "%s %s %s\n", blue("Resolver:"), green(p.Resolver), red("unreachable: "+p.Err.Error()))
+			continue
+		}
+		fmt.Fprintf(color.Output, "%s %s %s\n", blue("Resolver:"), green(p.Resolver),
+			yellow(fmt.Sprintf("reachable (%s)", p.Latency.Round(time.Millisecond))))
+	}
+	fmt.Fprintf(color.Output, "%s %s\n", blue("Reachable resolvers:"),
+		yellow(fmt.Sprintf("%d of %d", len(probes)-unreachable, len(probes))))
+}
+
+// probeResolvers queries each configured resolver, or the baseline resolvers when
+// none have been configured, for the NS records of the root zone.
+func probeResolvers(cfg *config.Config) []*resolverProbe {
+	var resolvers []resolve.Resolver
+
+	addrs := cfg.Resolvers
+	if len(addrs) == 0 && len(cfg.DoHResolvers) == 0 {
+		addrs = config.DefaultBaselineResolvers
+	}
+	// The proxy was validated when the system was created
+	proxy, _ := cfg.ProxyDialer()
+	for _, addr := range addrs {
+		if r := systems.NewResolver(addr, proxy, config.DefaultQueriesPerPublicResolver, cfg.Log); r != nil {
+			resolvers = append(resolvers, r)
+		}
+	}
+	for _, u := range cfg.DoHResolvers {
+		if r := systems.NewDoHResolver(u, proxy, config.DefaultQueriesPerPublicResolver, cfg.Log); r != nil {
+			resolvers = append(resolvers, r)
+		}
+	}
+
+	ch := make(chan *resolverProbe, len(resolvers))
+	for _, r := range resolvers {
+		go func(res resolve.Resolver) {
+			defer res.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), planProbeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			_, err := res.Query(ctx, resolve.QueryMsg(".", dns.TypeNS), resolve.PriorityHigh, nil)
+			ch <- &resolverProbe{
+				Resolver: res.String(),
+				Latency:  time.Since(start),
+				Err:      err,
+			}
+		}(r)
+	}
+
+	var probes []*resolverProbe
+	for i := 0; i < len(resolvers); i++ {
+		probes = append(probes, <-ch)
+	}
+	return probes
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/stringset"
+	"github.com/fatih/color"
+)
+
+const (
+	resolversUsageMsg = "resolvers -benchmark [options]"
+)
+
+type resolversArgs struct {
+	Queries   int
+	Resolvers *stringset.Set
+	Options   struct {
+		Benchmark bool
+	}
+	Filepaths struct {
+		ConfigFile string
+		Directory  string
+		Resolvers  format.ParseStrings
+	}
+}
+
+func runResolversCommand(clArgs []string) {
+	args := &resolversArgs{Resolvers: stringset.New()}
+	var help1, help2 bool
+	resolversCommand := flag.NewFlagSet("resolvers", flag.ContinueOnError)
+
+	resolversBuf := new(bytes.Buffer)
+	resolversCommand.SetOutput(resolversBuf)
+
+	resolversCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	resolversCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	resolversCommand.BoolVar(&args.Options.Benchmark, "benchmark", false, "Measure the throughput, loss and latency of each resolver")
+	resolversCommand.IntVar(&args.Queries, "n", systems.DefaultBenchmarkQueries, "Number of queries sent to each resolver")
+	resolversCommand.Var(args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
+	resolversCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
+	resolversCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the configuration file")
+	resolversCommand.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing preferred DNS resolvers")
+
+	if len(clArgs) < 1 {
+		commandUsage(resolversUsageMsg, resolversCommand, resolversBuf)
+		return
+	}
+	if err := resolversCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 || !args.Options.Benchmark {
+		commandUsage(resolversUsageMsg, resolversCommand, resolversBuf)
+		return
+	}
+
+	for _, f := range args.Filepaths.Resolvers {
+		list, err := config.GetListFromFile(f)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to parse the resolver file: %v\n", err)
+			os.Exit(1)
+		}
+		args.Resolvers.InsertMany(list...)
+	}
+
+	cfg := config.NewConfig()
+	// Check if a configuration file was provided, and if so, load the settings
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err != nil && args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	// Override configuration file settings with command-line arguments
+	if err := cfg.UpdateConfig(args); err != nil {
+		r.Fprintf(color.Error, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Seed the default pseudo-random number generator
+	rand.Seed(time.Now().UTC().UnixNano())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Stop the benchmark when the user interrupts it
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	g.Fprintf(color.Error, "Sending %d queries to each resolver\n", args.Queries)
+	results, total, err := systems.BenchmarkResolvers(ctx, cfg, args.Queries)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	for _, res := range results {
+		printBenchmarkResult(res.Resolver, res)
+	}
+	fmt.Fprintln(color.Output)
+	printBenchmarkResult(fmt.Sprintf("%d resolvers", len(results)), total)
+}
+
+func printBenchmarkResult(label string, res *systems.BenchmarkResult) {
+	loss := fmt.Sprintf("%5.1f%% loss", res.Loss*100)
+	if res.Loss > 0 {
+		loss = red(loss)
+	} else {
+		loss = green(loss)
+	}
+
+	fmt.Fprintf(color.Output, "%s %s %s  p50: %s  p90: %s  p99: %s\n", blue(fmt.Sprintf("%-28s", label)),
+		yellow(fmt.Sprintf("%8.1f qps", res.QPS)), loss, res.P50.Round(time.Millisecond),
+		res.P90.Round(time.Millisecond), res.P99.Round(time.Millisecond))
+}
+
+// OverrideConfig assigns the resolvers provided on the command-line to the configuration.
+func (a resolversArgs) OverrideConfig(conf *config.Config) error {
+	if a.Filepaths.Directory != "" {
+		conf.Dir = a.Filepaths.Directory
+	}
+	if a.Resolvers.Len() > 0 {
+		conf.SetResolvers(a.Resolvers.Slice()...)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/OWASP/Amass/v3/rpc"
+	"github.com/fatih/color"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	serveUsageMsg = "serve [options]"
+	defaultListen = "127.0.0.1:50051"
+)
+
+func runServeCommand(clArgs []string) {
+	var listen, certFile, keyFile, clientCAFile, token string
+	var help1, help2 bool
+	serveCommand := flag.NewFlagSet("serve", flag.ContinueOnError)
+
+	serveBuf := new(bytes.Buffer)
+	serveCommand.SetOutput(serveBuf)
+
+	serveCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	serveCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	serveCommand.StringVar(&listen, "listen", defaultListen, "Address the gRPC enumeration service listens on")
+	serveCommand.StringVar(&certFile, "tls-cert", "", "Path to the TLS certificate file of the service")
+	serveCommand.StringVar(&keyFile, "tls-key", "", "Path to the TLS private key file of the service")
+	serveCommand.StringVar(&clientCAFile, "tls-client-ca", "", "Path to the CA certificates that must have issued the client certificates")
+	serveCommand.StringVar(&token, "token", os.Getenv("AMASS_SERVE_TOKEN"), "Token the clients must provide (default: $AMASS_SERVE_TOKEN)")
+
+	if err := serveCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		commandUsage(serveUsageMsg, serveCommand, serveBuf)
+		return
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		r.Fprintln(color.Error, "The -tls-cert and -tls-key flags must be provided together")
+		os.Exit(1)
+	}
+	if clientCAFile != "" && certFile == "" {
+		r.Fprintln(color.Error, "The -tls-client-ca flag requires the -tls-cert and -tls-key flags")
+		os.Exit(1)
+	}
+	// The service can start enumerations, so it is only exposed beyond the host when the clients
+	// are authenticated. TLS alone protects the connections, but accepts any client
+	if !loopbackAddress(listen) && token == "" && clientCAFile == "" {
+		r.Fprintf(color.Error, "The %s address is not a loopback address, so -token or -tls-client-ca must be provided\n", listen)
+		os.Exit(1)
+	}
+
+	var opts []grpc.ServerOption
+	if certFile != "" {
+		tlsConfig, err := serveTLSConfig(certFile, keyFile, clientCAFile)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the TLS certificates: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to listen on %s: %v\n", listen, err)
+		os.Exit(1)
+	}
+
+	gs := grpc.NewServer(opts...)
+	srv := rpc.NewServer()
+	srv.SetToken(token)
+	srv.Register(gs)
+
+	// Stop the running enumerations when the user interrupts the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		<-quit
+		gs.Stop()
+	}()
+
+	g.Fprintf(color.Error, "The enumeration service is listening on %s\n", lis.Addr().String())
+	if err := gs.Serve(lis); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// serveTLSConfig returns the TLS configuration of the service, which requires the clients to provide
+// a certificate issued by the CA certificates in the clientCAFile when provided.
+func serveTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no CA certificates were found in " + clientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
+
+// loopbackAddress returns true when the host of the listen address is a loopback address.
+func loopbackAddress(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -89,20 +89,20 @@ func shareFindings(e *enum.Enumeration, cfg *config.Config) {
 		}
 
 		if creds := dscfgs[ds].GetCredentials(); creds != nil {
-			if err := sendFindings(info[1], &buf, info[0], creds.Key); err != nil {
+			if err := sendFindings(cfg, info[1], &buf, info[0], creds.Key); err != nil {
 				fmt.Fprintf(color.Error, "%s%s: %s\n", red("Failed to share findings with "), red(ds), red(err.Error()))
 			}
 		}
 	}
 }
 
-func sendFindings(url string, data io.Reader, hdr, key string) error {
+func sendFindings(cfg *config.Config, url string, data io.Reader, hdr, key string) error {
 	headers := map[string]string{
 		"Content-Encoding": "gzip",
 		hdr:                key,
 	}
 
-	_, err := http.RequestWebPage(context.Background(), url, data, headers, nil)
+	_, err := http.RequestWebPage(cfg.ProxyContext(context.Background()), url, data, headers, nil)
 	return err
 }
 
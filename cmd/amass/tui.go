@@ -0,0 +1,398 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+const (
+	// dashboardRefresh is how often the terminal dashboard is redrawn
+	dashboardRefresh = time.Second
+	// The number of recent discoveries shown by the dashboard
+	dashboardRecent = 10
+	// The number of data sources listed by the dashboard, starting with the highest yield
+	dashboardSources = 10
+)
+
+// The escape sequences that switch to the alternate screen buffer and hide the cursor, and back again
+const (
+	enterDashboard = "\x1b[?1049h\x1b[?25l"
+	leaveDashboard = "\x1b[?25h\x1b[?1049l"
+	clearDashboard = "\x1b[H\x1b[2J"
+)
+
+// dashboard maintains the state shown by the terminal dashboard of a running enumeration.
+type dashboard struct {
+	sync.Mutex
+	e        *enum.Enumeration
+	sys      *systems.LocalSystem
+	args     *enumArgs
+	paused   bool
+	quitting bool
+	closed   bool
+	progress *enum.ProgressUpdate
+	total    int
+	yields   map[string]int
+	printed  []string
+	tags     map[string]int
+	asns     map[int]*format.ASNSummaryData
+}
+
+// dashboardAvailable returns true when the dashboard was requested and the
+// standard input and output are both attached to a terminal.
+func dashboardAvailable(args *enumArgs) bool {
+	if !args.Options.TUI || args.Options.Silent || args.Filepaths.JSONOutput == "-" || args.Filepaths.JSONStream == "-" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// runDashboard draws the live dashboard of the enumeration in place of the printed output, until the
+// output channel is closed, and then prints the output that would have been printed during the run.
+// The 'p' key pauses and resumes the enumeration, and the 'q' key sends an interrupt on the quit
+// channel, so the enumeration is stopped as gracefully as by the signal.
+func runDashboard(e *enum.Enumeration, sys *systems.LocalSystem, args *enumArgs,
+	output chan *requests.Output, quit chan os.Signal, wg *sync.WaitGroup) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to start the dashboard: %v\n", err)
+		printOutput(e, args, output, wg)
+		return
+	}
+	defer wg.Done()
+
+	d := &dashboard{
+		e:      e,
+		sys:    sys,
+		args:   args,
+		yields: make(map[string]int),
+		tags:   make(map[string]int),
+		asns:   make(map[int]*format.ASNSummaryData),
+	}
+
+	fmt.Fprint(color.Output, enterDashboard)
+
+	go d.readKeys(quit)
+	d.consume(output)
+
+	d.Lock()
+	d.closed = true
+	d.Unlock()
+	fmt.Fprint(color.Output, leaveDashboard)
+	_ = term.Restore(fd, state)
+	d.printOutput()
+}
+
+// consume updates the dashboard with the discoveries and progress of the enumeration until the output channel is closed.
+func (d *dashboard) consume(output chan *requests.Output) {
+	t := time.NewTicker(dashboardRefresh)
+	defer t.Stop()
+
+	progress := d.e.Progress()
+	d.draw()
+	for {
+		select {
+		case out, ok := <-output:
+			if !ok {
+				return
+			}
+			d.addOutput(out)
+		case update, ok := <-progress:
+			if !ok {
+				progress = nil
+				continue
+			}
+
+			d.Lock()
+			d.progress = &update
+			d.Unlock()
+		case <-t.C:
+			d.draw()
+		}
+	}
+}
+
+func (d *dashboard) addOutput(out *requests.Output) {
+	out.Addresses = format.DesiredAddrTypes(out.Addresses, d.args.Options.IPv4, d.args.Options.IPv6)
+	if !d.e.Config.Passive && len(out.Addresses) <= 0 {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	d.total++
+	if !d.args.Options.Passive {
+		format.UpdateSummaryData(out, d.tags, d.asns)
+	}
+	for _, src := range out.Sources {
+		d.yields[src]++
+	}
+
+	source, name, ips := format.OutputLineParts(out, d.args.Options.Sources,
+		d.args.Options.IPs || d.args.Options.IPv4 || d.args.Options.IPv6, d.args.Options.DemoMode)
+	if ips != "" {
+		ips = " " + ips
+	}
+
+	d.printed = append(d.printed, blue(source)+green(name)+yellow(ips))
+}
+
+// readKeys handles the keys pressed while the dashboard is shown. The terminal is in raw
+// mode, so Ctrl-C is received as a key instead of generating the interrupt signal.
+func (d *dashboard) readKeys(quit chan os.Signal) {
+	buf := make([]byte, 1)
+
+	for {
+		if n, err := os.Stdin.Read(buf); err != nil {
+			return
+		} else if n == 0 {
+			continue
+		}
+
+		d.Lock()
+		closed := d.closed
+		d.Unlock()
+		if closed {
+			return
+		}
+
+		switch buf[0] {
+		case 'p', 'P', ' ':
+			d.togglePause()
+		case 'q', 'Q', 3:
+			d.Lock()
+			d.quitting = true
+			d.Unlock()
+			// A second quit stops the enumeration without waiting for the queued data
+			select {
+			case quit <- os.Interrupt:
+			default:
+			}
+		}
+		d.draw()
+	}
+}
+
+func (d *dashboard) togglePause() {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.quitting {
+		return
+	}
+	if d.paused {
+		d.e.Resume()
+	} else {
+		d.e.Pause()
+	}
+	d.paused = !d.paused
+}
+
+func (d *dashboard) draw() {
+	d.Lock()
+	if d.closed {
+		d.Unlock()
+		return
+	}
+	lines := d.lines()
+	d.Unlock()
+
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	var b strings.Builder
+	b.WriteString(clearDashboard)
+	for i, line := range lines {
+		if i > 0 {
+			// The output is not post-processed while the terminal is in raw mode
+			b.WriteString("\r\n")
+		}
+		b.WriteString(truncateLine(line, width))
+	}
+	fmt.Fprint(color.Output, b.String())
+}
+
+func (d *dashboard) lines() []string {
+	state := green("running")
+	if d.quitting {
+		state = red("stopping")
+	} else if d.paused {
+		state = yellow("paused")
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s %s  %s", blue("OWASP Amass"), green(strings.Join(d.e.Config.Domains(), ", ")), state),
+		"",
+	}
+
+	if p := d.progress; p != nil {
+		line := fmt.Sprintf("%s %s  %s %s  %s %s  %s %s", blue("Elapsed:"), yellow(p.Elapsed.Round(time.Second).String()),
+			blue("Queued:"), yellow(fmt.Sprint(p.Queued)), blue("Processed:"), yellow(fmt.Sprint(p.Processed)),
+			blue("Active sources:"), yellow(fmt.Sprint(p.ActiveSources)))
+		if p.ETA != nil {
+			line += fmt.Sprintf("  %s %s", blue("ETA:"), yellow(p.ETA.Round(time.Second).String()))
+		}
+		lines = append(lines, line)
+	} else {
+		lines = append(lines, blue("Waiting for the first progress update"))
+	}
+
+	stats := d.e.Stats()
+	lines = append(lines, fmt.Sprintf("%s %s  %s %s  %s %s  %s %s", blue("Submitted:"), yellow(fmt.Sprint(stats.Submitted)),
+		blue("Accepted:"), yellow(fmt.Sprint(stats.Accepted)), blue("Duplicates:"), yellow(fmt.Sprint(stats.Duplicates)),
+		blue("Out of scope:"), yellow(fmt.Sprint(stats.OutOfScope))))
+	lines = append(lines, d.resolversLine(stats.QueryCache))
+
+	lines = append(lines, "", blue(fmt.Sprintf("Names discovered: %d", d.total)))
+	for _, y := range d.topYields() {
+		lines = append(lines, fmt.Sprintf("  %s %s", green(fmt.Sprintf("%-24s", y.source)), yellow(fmt.Sprint(y.count))))
+	}
+
+	lines = append(lines, "", blue("Recent discoveries:"))
+	for i := len(d.printed) - 1; i >= 0 && i >= len(d.printed)-dashboardRecent; i-- {
+		lines = append(lines, "  "+d.printed[i])
+	}
+
+	lines = append(lines, "", blue("p: pause / resume   q: quit"))
+	return lines
+}
+
+// resolversLine reports the resolvers still in the pool and the DNS query cache counters.
+func (d *dashboard) resolversLine(cache *systems.QueryCacheStats) string {
+	cfg := d.e.Config
+
+	var resolvers string
+	if scores := d.sys.ResolverScores(); len(scores) > 0 {
+		var active int
+		for _, s := range scores {
+			if !s.Ejected {
+				active++
+			}
+		}
+		resolvers = fmt.Sprintf("%d active, %d ejected", active, len(scores)-active)
+	} else if num := len(cfg.Resolvers) + len(cfg.DoHResolvers); num > 0 {
+		resolvers = fmt.Sprintf("%d configured", num)
+	} else {
+		resolvers = "public"
+	}
+	if poisoned := len(d.sys.PoisonedResolvers()); poisoned > 0 {
+		resolvers += fmt.Sprintf(", %d poisoned", poisoned)
+	}
+
+	line := fmt.Sprintf("%s %s", blue("Resolvers:"), yellow(resolvers))
+	if c := d.progress; c != nil && c.Concurrency != nil {
+		line += fmt.Sprintf("  %s %s", blue("Concurrency:"), yellow(fmt.Sprint(c.Concurrency.Limit)))
+	}
+	if c := d.progress; c != nil && c.BruteRate != nil {
+		line += fmt.Sprintf("  %s %s", blue("Brute rate:"), yellow(fmt.Sprintf("%.0f/s", c.BruteRate.Rate)))
+	}
+	if cache != nil {
+		line += fmt.Sprintf("  %s %s", blue("Cache hits:"),
+			yellow(fmt.Sprintf("%d / %d", cache.Hits, cache.Hits+cache.Misses)))
+	}
+	return line
+}
+
+type sourceYield struct {
+	source string
+	count  int
+}
+
+// topYields returns the data sources with the most names discovered, in descending order.
+func (d *dashboard) topYields() []sourceYield {
+	var yields []sourceYield
+	for src, count := range d.yields {
+		yields = append(yields, sourceYield{source: src, count: count})
+	}
+
+	sort.Slice(yields, func(i, j int) bool {
+		if yields[i].count == yields[j].count {
+			return yields[i].source < yields[j].source
+		}
+		return yields[i].count > yields[j].count
+	})
+	if len(yields) > dashboardSources {
+		yields = yields[:dashboardSources]
+	}
+	return yields
+}
+
+// printOutput prints the discoveries and the summary normally printed during the enumeration, once the dashboard has been closed.
+func (d *dashboard) printOutput() {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, line := range d.printed {
+		fmt.Fprintln(color.Output, line)
+	}
+
+	if d.total == 0 {
+		r.Println("No names were discovered")
+	} else if !d.args.Options.Passive {
+		format.PrintEnumerationSummary(d.total, d.tags, d.asns, d.args.Options.DemoMode)
+	}
+}
+
+// truncateLine shortens the line to the width of the terminal, without counting the color escape sequences.
+func truncateLine(line string, width int) string {
+	var b strings.Builder
+	var visible int
+	var escape bool
+
+	for _, c := range line {
+		switch {
+		case c == '\x1b':
+			escape = true
+		case escape:
+			if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+				escape = false
+			}
+		default:
+			if visible >= width {
+				continue
+			}
+			visible++
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// logProgress writes the progress updates of the enumeration as log lines, in place of
+// the dashboard when the standard output is not attached to a terminal.
+func logProgress(e *enum.Enumeration) {
+	for p := range e.Progress() {
+		stats := e.Stats()
+
+		line := fmt.Sprintf("Elapsed: %s, Queued: %d, Processed: %d, Active sources: %d, Accepted: %d, Duplicates: %d",
+			p.Elapsed.Round(time.Second), p.Queued, p.Processed, p.ActiveSources, stats.Accepted, stats.Duplicates)
+		if p.ETA != nil {
+			line += fmt.Sprintf(", ETA: %s", p.ETA.Round(time.Second))
+		}
+		if p.BruteRate != nil && p.BruteRate.Rate < float64(p.BruteRate.Max) {
+			line += fmt.Sprintf(", Brute rate: %.0f/s", p.BruteRate.Rate)
+		}
+		fmt.Fprintln(color.Error, line)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		width    int
+		expected string
+	}{
+		{name: "shorter than the width", line: "www.owasp.org", width: 20, expected: "www.owasp.org"},
+		{name: "longer than the width", line: "www.owasp.org", width: 3, expected: "www"},
+		{name: "escape sequences not counted", line: "\x1b[32mwww.owasp.org\x1b[0m", width: 3, expected: "\x1b[32mwww\x1b[0m"},
+		{name: "zero width", line: "www.owasp.org", width: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateLine(tt.line, tt.width); got != tt.expected {
+				t.Errorf("truncateLine() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDashboardTopYields(t *testing.T) {
+	d := &dashboard{yields: map[string]int{"Crtsh": 5, "Brute Forcing": 9, "DNS": 5}}
+	for i := 0; i < dashboardSources; i++ {
+		d.yields[fmt.Sprintf("Source%d", i)] = 1
+	}
+
+	yields := d.topYields()
+	if len(yields) != dashboardSources {
+		t.Fatalf("%d data sources were shown", len(yields))
+	}
+	// The data sources with the same yield are shown in alphabetical order
+	if yields[0].source != "Brute Forcing" || yields[1].source != "Crtsh" || yields[2].source != "DNS" {
+		t.Errorf("The data sources were shown in the order %v", yields)
+	}
+}
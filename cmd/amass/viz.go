@@ -7,29 +7,34 @@ import (
 	"bytes"
 	"context"
 	"flag"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/format"
 	"github.com/OWASP/Amass/v3/viz"
 	"github.com/caffix/stringset"
 	"github.com/fatih/color"
 )
 
 const (
-	vizUsageMsg = "viz -d3|-dot||-gexf|-graphistry|-maltego [options]"
+	vizUsageMsg = "viz -d3|-dot||-gexf|-graphml|-graphistry|-maltego|-bloodhound [options]"
 )
 
 type vizArgs struct {
 	Domains *stringset.Set
 	Enum    int
 	Options struct {
+		BloodHound bool
 		D3         bool
 		DOT        bool
 		GEXF       bool
+		GraphML    bool
 		Graphistry bool
 		Maltego    bool
 		NoColor    bool
@@ -64,9 +69,11 @@ func runVizCommand(clArgs []string) {
 	vizCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
 	vizCommand.StringVar(&args.Filepaths.Input, "i", "", "The Amass data operations JSON file")
 	vizCommand.StringVar(&args.Filepaths.Output, "o", "", "Path to the directory for output files being generated")
+	vizCommand.BoolVar(&args.Options.BloodHound, "bloodhound", false, "Generate the BloodHound OpenGraph JSON file")
 	vizCommand.BoolVar(&args.Options.D3, "d3", false, "Generate the D3 v4 force simulation HTML file")
 	vizCommand.BoolVar(&args.Options.DOT, "dot", false, "Generate the DOT output file")
 	vizCommand.BoolVar(&args.Options.GEXF, "gexf", false, "Generate the Gephi Graph Exchange XML Format (GEXF) file")
+	vizCommand.BoolVar(&args.Options.GraphML, "graphml", false, "Generate the GraphML file")
 	vizCommand.BoolVar(&args.Options.Graphistry, "graphistry", false, "Generate the Graphistry JSON file")
 	vizCommand.BoolVar(&args.Options.Maltego, "maltego", false, "Generate the Maltego csv file")
 	vizCommand.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
@@ -95,8 +102,8 @@ func runVizCommand(clArgs []string) {
 	}
 
 	// Make sure at least one graph file format has been identified on the command-line
-	if !args.Options.D3 && !args.Options.DOT &&
-		!args.Options.GEXF && !args.Options.Graphistry && !args.Options.Maltego {
+	if !args.Options.D3 && !args.Options.DOT && !args.Options.GEXF && !args.Options.GraphML &&
+		!args.Options.Graphistry && !args.Options.Maltego && !args.Options.BloodHound {
 		r.Fprintln(color.Error, "At least one file format must be selected")
 		os.Exit(1)
 	}
@@ -186,6 +193,10 @@ func runVizCommand(clArgs []string) {
 		path := filepath.Join(dir, "amass.gexf")
 		err = writeGraphOutputFile("gexf", path, nodes, edges)
 	}
+	if args.Options.GraphML {
+		path := filepath.Join(dir, "amass.graphml")
+		err = writeGraphOutputFile("graphml", path, nodes, edges)
+	}
 	if args.Options.Graphistry {
 		path := filepath.Join(dir, "amass_graphistry.json")
 		err = writeGraphOutputFile("graphistry", path, nodes, edges)
@@ -194,6 +205,10 @@ func runVizCommand(clArgs []string) {
 		path := filepath.Join(dir, "amass_maltego.csv")
 		err = writeGraphOutputFile("maltego", path, nodes, edges)
 	}
+	if args.Options.BloodHound {
+		path := filepath.Join(dir, "amass_bloodhound.json")
+		err = writeGraphOutputFile("bloodhound", path, nodes, edges)
+	}
 
 	if err != nil {
 		r.Fprintf(color.Error, "Failed to write the output file: %v\n", err)
@@ -202,30 +217,52 @@ func runVizCommand(clArgs []string) {
 }
 
 func writeGraphOutputFile(t string, path string, nodes []viz.Node, edges []viz.Edge) error {
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	f, err := format.CreateOutputFile(path, false)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = f.Sync()
+
+	if err := writeGraphData(t, f, nodes, edges); err != nil {
 		_ = f.Close()
-	}()
+		return err
+	}
+	return f.Close()
+}
 
-	_ = f.Truncate(0)
-	_, _ = f.Seek(0, 0)
+// writeGraphData writes the nodes and edges to the writer in the graph format t.
+// redactNodes replaces the labels of the node names that match the redact patterns of the configuration.
+func redactNodes(cfg *config.Config, nodes []viz.Node) []viz.Node {
+	if !cfg.Redacting() {
+		return nodes
+	}
+
+	for i, n := range nodes {
+		if label := cfg.RedactName(n.Label); label != n.Label {
+			nodes[i].Label = label
+			nodes[i].Title = strings.Replace(n.Title, n.Label, label, 1)
+		}
+	}
+	return nodes
+}
+
+func writeGraphData(t string, w io.Writer, nodes []viz.Node, edges []viz.Edge) error {
+	var err error
 
 	switch t {
 	case "d3":
-		err = viz.WriteD3Data(f, nodes, edges)
+		err = viz.WriteD3Data(w, nodes, edges)
 	case "dot":
-		err = viz.WriteDOTData(f, nodes, edges)
+		err = viz.WriteDOTData(w, nodes, edges)
 	case "gexf":
-		err = viz.WriteGEXFData(f, nodes, edges)
+		err = viz.WriteGEXFData(w, nodes, edges)
+	case "graphml":
+		err = viz.WriteGraphMLData(w, nodes, edges)
 	case "graphistry":
-		err = viz.WriteGraphistryData(f, nodes, edges)
+		err = viz.WriteGraphistryData(w, nodes, edges)
 	case "maltego":
-		viz.WriteMaltegoData(f, nodes, edges)
+		viz.WriteMaltegoData(w, nodes, edges)
+	case "bloodhound":
+		err = viz.WriteBloodHoundData(w, nodes, edges)
 	}
-
 	return err
 }
@@ -0,0 +1,89 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/caffix/stringset"
+	"github.com/go-ini/ini"
+)
+
+// AlertPattern returns the first of the AlertPatterns matched by the name in the parameter.
+// An empty string is returned when the name does not match any of the patterns.
+func (c *Config) AlertPattern(name string) string {
+	c.alertLock.Lock()
+	defer c.alertLock.Unlock()
+
+	if len(c.alertRegexps) != len(c.AlertPatterns) {
+		c.alertRegexps, _ = compileWildcards("alert", c.AlertPatterns)
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	for i, re := range c.alertRegexps {
+		if re.MatchString(n) {
+			return c.AlertPatterns[i]
+		}
+	}
+	return ""
+}
+
+func (c *Config) checkAlerts() error {
+	c.alertLock.Lock()
+	defer c.alertLock.Unlock()
+
+	res, err := compileWildcards("alert", c.AlertPatterns)
+	if err != nil {
+		return err
+	}
+	c.alertRegexps = res
+
+	if c.AlertWebhookURL == "" {
+		return nil
+	}
+	if u, err := url.Parse(c.AlertWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s is not a valid alert webhook URL", c.AlertWebhookURL)
+	}
+	return nil
+}
+
+// compileWildcards converts the wildcard patterns, such as *vpn*, into anchored regular expressions.
+// The asterisk matches any number of characters and the question mark matches a single character.
+// The kind of the patterns is used in the errors returned.
+func compileWildcards(kind string, patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+
+	for _, p := range patterns {
+		pattern := strings.ToLower(strings.TrimSpace(p))
+		if pattern == "" {
+			return nil, fmt.Errorf("the %s pattern %q is empty", kind, p)
+		}
+
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("the %s pattern %s is not valid: %v", kind, p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func (c *Config) loadAlertSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("alerts")
+	if err != nil {
+		return nil
+	}
+
+	if sec.HasKey("pattern") {
+		c.AlertPatterns = stringset.Deduplicate(sec.Key("pattern").ValueWithShadows())
+	}
+	c.AlertWebhookURL = strings.TrimSpace(sec.Key("webhook").String())
+	return nil
+}
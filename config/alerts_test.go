@@ -0,0 +1,60 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestConfigAlertPattern(t *testing.T) {
+	c := &Config{AlertPatterns: []string{"*vpn*", "admin?.*"}}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"vpn.owasp.org", "*vpn*"},
+		{"SSLVPN-01.owasp.org", "*vpn*"},
+		{"admin1.owasp.org", "admin?.*"},
+		{"admin.owasp.org", ""},
+		{"www.owasp.org", ""},
+	}
+	for _, tt := range tests {
+		if got := c.AlertPattern(tt.name); got != tt.want {
+			t.Errorf("AlertPattern(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadAlertSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[alerts]
+		pattern = *vpn*
+		pattern = *admin*
+		webhook = https://alerts.example.com/amass
+		`),
+	)
+	if err := c.loadAlertSettings(cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(c.AlertPatterns) != 2 {
+		t.Errorf("The alert patterns were loaded as %v", c.AlertPatterns)
+	}
+	if c.AlertWebhookURL != "https://alerts.example.com/amass" {
+		t.Errorf("The alert webhook URL was loaded as %s", c.AlertWebhookURL)
+	}
+	if err := c.CheckSettings(); err != nil {
+		t.Errorf("The alert settings were rejected: %v", err)
+	}
+}
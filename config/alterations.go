@@ -0,0 +1,136 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultAlterationTemplateLimit is the largest number of names generated from the
+// alteration templates for each resolved name.
+const DefaultAlterationTemplateLimit = 1000
+
+var (
+	placeholderRE = regexp.MustCompile(`^[a-z0-9_]+$`)
+	literalRE     = regexp.MustCompile(`^[a-z0-9_.-]+$`)
+)
+
+// AlterationTemplate is a naming convention, such as {app}-{env}-{region}, for the labels to the
+// left of the root domain name. The placeholders are filled with the tokens found in discovered
+// names that follow the same convention.
+type AlterationTemplate struct {
+	Parts []TemplatePart
+	re    *regexp.Regexp
+}
+
+// TemplatePart is either the literal text or a placeholder of an AlterationTemplate.
+type TemplatePart struct {
+	Literal     string
+	Placeholder string
+}
+
+// ParseAlterationTemplate returns the AlterationTemplate for the template string provided.
+func ParseAlterationTemplate(tmpl string) (*AlterationTemplate, error) {
+	tmpl = strings.ToLower(strings.TrimSpace(tmpl))
+	if tmpl == "" {
+		return nil, fmt.Errorf("the alteration template cannot be empty")
+	}
+
+	t := new(AlterationTemplate)
+	expr := "^"
+	for rest := tmpl; rest != ""; {
+		start := strings.Index(rest, "{")
+		if start == -1 {
+			start = len(rest)
+		}
+		if lit := rest[:start]; lit != "" {
+			if !literalRE.MatchString(lit) {
+				return nil, fmt.Errorf("the alteration template %s contains invalid characters", tmpl)
+			}
+			t.Parts = append(t.Parts, TemplatePart{Literal: lit})
+			expr += regexp.QuoteMeta(lit)
+		}
+		if start == len(rest) {
+			break
+		}
+
+		end := strings.Index(rest[start:], "}")
+		if end == -1 {
+			return nil, fmt.Errorf("the alteration template %s has an unclosed placeholder", tmpl)
+		}
+
+		name := rest[start+1 : start+end]
+		if !placeholderRE.MatchString(name) {
+			return nil, fmt.Errorf("the alteration template %s has an invalid placeholder name", tmpl)
+		}
+		if n := len(t.Parts); n > 0 && t.Parts[n-1].Placeholder != "" {
+			return nil, fmt.Errorf("the alteration template %s must separate the placeholders with literal text", tmpl)
+		}
+		t.Parts = append(t.Parts, TemplatePart{Placeholder: name})
+		expr += "([a-z0-9_]+)"
+		rest = rest[start+end+1:]
+	}
+	if len(t.Placeholders()) == 0 {
+		return nil, fmt.Errorf("the alteration template %s does not contain a placeholder", tmpl)
+	}
+
+	t.re = regexp.MustCompile(expr + "$")
+	return t, nil
+}
+
+// Placeholders returns the unique placeholder names in the order they appear in the template.
+func (t *AlterationTemplate) Placeholders() []string {
+	var names []string
+	seen := make(map[string]struct{})
+
+	for _, p := range t.Parts {
+		if p.Placeholder == "" {
+			continue
+		}
+		if _, found := seen[p.Placeholder]; !found {
+			seen[p.Placeholder] = struct{}{}
+			names = append(names, p.Placeholder)
+		}
+	}
+	return names
+}
+
+// Match returns the placeholder values when the labels follow the naming convention, and nil otherwise.
+func (t *AlterationTemplate) Match(labels string) map[string]string {
+	m := t.re.FindStringSubmatch(strings.ToLower(labels))
+	if m == nil {
+		return nil
+	}
+
+	values := make(map[string]string)
+	i := 1
+	for _, p := range t.Parts {
+		if p.Placeholder == "" {
+			continue
+		}
+		// A placeholder used more than once must have the same value each time
+		if v, found := values[p.Placeholder]; found && v != m[i] {
+			return nil
+		}
+		values[p.Placeholder] = m[i]
+		i++
+	}
+	return values
+}
+
+// Expand returns the labels built from the template using the placeholder values provided.
+func (t *AlterationTemplate) Expand(values map[string]string) string {
+	var b strings.Builder
+
+	for _, p := range t.Parts {
+		if p.Placeholder == "" {
+			b.WriteString(p.Literal)
+			continue
+		}
+		b.WriteString(values[p.Placeholder])
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import "testing"
+
+func TestParseAlterationTemplate(t *testing.T) {
+	for _, tmpl := range []string{"", "api", "{app", "{app}{env}", "{App Name}-prod", "{app}/{env}"} {
+		if _, err := ParseAlterationTemplate(tmpl); err == nil {
+			t.Errorf("%s was accepted as an alteration template", tmpl)
+		}
+	}
+
+	tmpl, err := ParseAlterationTemplate("{app}-{env}.{region}")
+	if err != nil {
+		t.Fatalf("Failed to parse the alteration template: %v", err)
+	}
+	if phs := tmpl.Placeholders(); len(phs) != 3 || phs[0] != "app" || phs[2] != "region" {
+		t.Errorf("the template returned the placeholders %v", phs)
+	}
+}
+
+func TestAlterationTemplateMatch(t *testing.T) {
+	tmpl, _ := ParseAlterationTemplate("{app}-{env}-{app}")
+
+	values := tmpl.Match("Billing-prod-billing")
+	if values == nil || values["app"] != "billing" || values["env"] != "prod" {
+		t.Fatalf("the labels did not match the template: %v", values)
+	}
+	if got := tmpl.Expand(map[string]string{"app": "auth", "env": "dev"}); got != "auth-dev-auth" {
+		t.Errorf("the template expanded to %s", got)
+	}
+
+	for _, labels := range []string{"billing-prod-auth", "billing-prod", "www.billing-prod-billing"} {
+		if v := tmpl.Match(labels); v != nil {
+			t.Errorf("%s matched the template with the values %v", labels, v)
+		}
+	}
+}
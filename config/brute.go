@@ -5,6 +5,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
@@ -24,6 +25,10 @@ func (c *Config) loadBruteForceSettings(cfg *ini.File) error {
 	c.Recursive = bruteforce.Key("recursive").MustBool(true)
 	c.MinForRecursive = bruteforce.Key("minimum_for_recursive").MustInt(0)
 	c.MaxDepth = bruteforce.Key("max_depth").MustInt(0)
+	c.MaxBruteCandidates = bruteforce.Key("max_candidates").MustInt(0)
+	c.BruteMaxRate = bruteforce.Key("max_rate").MustInt(DefaultBruteMaxRate)
+	c.TokenExpansion = bruteforce.Key("token_expansion").MustBool(false)
+	c.TokenExpansionLimit = bruteforce.Key("token_expansion_limit").MustInt(DefaultTokenExpansionLimit)
 
 	if bruteforce.HasKey("wordlist_file") {
 		for _, wordlist := range bruteforce.Key("wordlist_file").ValueWithShadows() {
@@ -56,6 +61,13 @@ func (c *Config) loadAlterationSettings(cfg *ini.File) error {
 	c.AddNumbers = alterations.Key("add_numbers").MustBool(true)
 	c.MinForWordFlip = alterations.Key("minimum_for_word_flip").MustInt(2)
 	c.EditDistance = alterations.Key("edit_distance").MustInt(1)
+	c.AlterationTemplateLimit = alterations.Key("template_limit").MustInt(DefaultAlterationTemplateLimit)
+
+	for _, tmpl := range alterations.Key("template").ValueWithShadows() {
+		if tmpl = strings.TrimSpace(tmpl); tmpl != "" {
+			c.AlterationTemplates = append(c.AlterationTemplates, tmpl)
+		}
+	}
 
 	if alterations.HasKey("wordlist_file") {
 		for _, wordlist := range alterations.Key("wordlist_file").ValueWithShadows() {
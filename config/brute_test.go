@@ -27,6 +27,10 @@ func TestConfigloadBruteForceSettings(t *testing.T) {
 			enabled = true
 			recursive = true
 			minimum_for_recursive = 1
+			max_candidates = 5000
+			max_rate = 250
+			token_expansion = true
+			token_expansion_limit = 500
 			#wordlist_file = /dev/null
 			#wordlist_file = /dev/null
 			`)},
@@ -38,6 +42,15 @@ func TestConfigloadBruteForceSettings(t *testing.T) {
 				if c.MinForRecursive != 1 {
 					t.Errorf("Config.loadBruteForceSettings() error = %v", "MinForRecursive not equal")
 				}
+				if c.MaxBruteCandidates != 5000 {
+					t.Errorf("Config.loadBruteForceSettings() error = %v", "MaxBruteCandidates not equal")
+				}
+				if c.BruteMaxRate != 250 {
+					t.Errorf("Config.loadBruteForceSettings() error = %v", "BruteMaxRate not equal")
+				}
+				if !c.TokenExpansion || c.TokenExpansionLimit != 500 {
+					t.Errorf("Config.loadBruteForceSettings() error = %v", "TokenExpansion not set")
+				}
 			},
 		},
 		{
@@ -140,6 +153,24 @@ func TestConfigloadAlterationSettings(t *testing.T) {
 			assertionFunc: func(t *testing.T, c *Config) {
 			},
 		},
+		{
+			name: "success - enabled, with a template",
+			args: args{cfg: []byte(`
+			[alterations]
+			enabled: true
+			template: {app}-{env}
+			template_limit: 50
+			`)},
+			wantErr: false,
+			assertionFunc: func(t *testing.T, c *Config) {
+				if len(c.AlterationTemplates) != 1 || c.AlterationTemplates[0] != "{app}-{env}" {
+					t.Errorf("Config.loadAlterationSettings(): templates were %v", c.AlterationTemplates)
+				}
+				if c.AlterationTemplateLimit != 50 {
+					t.Errorf("Config.loadAlterationSettings(): template limit was %d", c.AlterationTemplateLimit)
+				}
+			},
+		},
 		{
 			name: "success - enabled",
 			args: args{cfg: []byte(`
@@ -0,0 +1,73 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/caffix/stringset"
+	"golang.org/x/net/publicsuffix"
+)
+
+// CertOrgMaxDomains is the largest number of root domains named by a certificate issued to one of the
+// CertOrgs that are added to the scope. Certificates naming more domains are shared between the customers
+// of a CDN or hosting provider, and their names are not brought into scope.
+const CertOrgMaxDomains = 10
+
+// IsCertOrg returns true when the certificate subject organization or organizational unit matches one
+// of the CertOrgs, regardless of the case and surrounding whitespace.
+func (c *Config) IsCertOrg(org string) bool {
+	org = strings.TrimSpace(org)
+	if org == "" {
+		return false
+	}
+
+	for _, o := range c.CertOrgs {
+		if strings.EqualFold(strings.TrimSpace(o), org) {
+			return true
+		}
+	}
+	return false
+}
+
+// CertOrgDomains returns the root domain names, not already in scope, of the names from a certificate
+// issued to the subject organizations and organizational units provided. Nothing is returned when none
+// of the organizations match the CertOrgs, or the certificate names more than CertOrgMaxDomains domains.
+func (c *Config) CertOrgDomains(orgs, names []string) []string {
+	var match bool
+	for _, org := range orgs {
+		if c.IsCertOrg(org) {
+			match = true
+			break
+		}
+	}
+	if !match {
+		return nil
+	}
+
+	domains := stringset.New()
+	defer domains.Close()
+
+	for _, name := range names {
+		n := strings.ToLower(strings.Trim(strings.TrimSpace(dns.RemoveAsteriskLabel(name)), "."))
+		if d, err := publicsuffix.EffectiveTLDPlusOne(n); err == nil {
+			domains.Insert(d)
+		}
+	}
+	if domains.Len() > CertOrgMaxDomains {
+		return nil
+	}
+
+	var added []string
+	for _, d := range domains.Slice() {
+		if c.WhichDomain(d) == "" {
+			added = append(added, d)
+		}
+	}
+
+	sort.Strings(added)
+	return added
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCertOrgDomains(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("owasp.org")
+	c.CertOrgs = []string{"OWASP Foundation"}
+
+	names := []string{"www.owasp.org", "*.appsecusa.org", "appsec.eu", "mail.appsec.eu"}
+	domains := c.CertOrgDomains([]string{"  owasp foundation "}, names)
+	if len(domains) != 2 || domains[0] != "appsec.eu" || domains[1] != "appsecusa.org" {
+		t.Errorf("The domains named by the certificate of the organization were not returned: %v", domains)
+	}
+
+	if domains := c.CertOrgDomains([]string{"Other Foundation"}, names); len(domains) != 0 {
+		t.Errorf("The domains named by the certificate of another organization were returned: %v", domains)
+	}
+
+	// Certificates naming the domains of many customers are shared by CDNs and hosting providers
+	var shared []string
+	for i := 0; i <= CertOrgMaxDomains; i++ {
+		shared = append(shared, fmt.Sprintf("www.customer%d.com", i))
+	}
+	if domains := c.CertOrgDomains([]string{"OWASP Foundation"}, shared); len(domains) != 0 {
+		t.Errorf("The domains named by a shared certificate were returned: %v", domains)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -20,7 +21,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/resources"
 	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
@@ -34,6 +37,56 @@ const (
 	systemCfgDir   = "/etc"
 )
 
+// DefaultReverseDNSMaxPrefix is the IPv4 prefix length of the largest netblock swept when ReverseDNSMaxPrefix is not set.
+const DefaultReverseDNSMaxPrefix = 22
+
+// DefaultBruteMaxRate is the most brute forcing names resolved per second when BruteMaxRate is not set.
+const DefaultBruteMaxRate = 1000
+
+// DefaultTokenExpansionLimit is the most names generated from the learned tokens when TokenExpansionLimit is not set.
+const DefaultTokenExpansionLimit = 10000
+
+// DefaultRequestTimeout is the longest duration a name or address is processed by a pipeline stage when not configured.
+const DefaultRequestTimeout = time.Minute
+
+const (
+	// DefaultDNSCacheTTL is the duration that DNS query results are cached when not configured.
+	DefaultDNSCacheTTL = 5 * time.Minute
+	// DefaultDNSCacheSize is the number of DNS query results cached when DNSCacheSize is not set.
+	DefaultDNSCacheSize = 100000
+	// DefaultNegativeCacheTTL is the longest duration that NXDOMAIN responses are cached when not configured.
+	DefaultNegativeCacheTTL = 5 * time.Minute
+)
+
+const (
+	// DefaultFilterSize is the number of names the bloom and cuckoo filters hold when FilterSize is not set.
+	DefaultFilterSize = 1 << 22
+	// DefaultFilterFPRate is the false positive rate of the bloom and cuckoo filters when FilterFPRate is not set.
+	DefaultFilterFPRate = 0.001
+
+	maxFilterSize   = 1 << 30
+	maxFilterFPRate = 0.1
+)
+
+// DefaultResolveRepeatInterval is the duration between the repeated resolutions of a name when
+// ResolveRepeatInterval is not set.
+const DefaultResolveRepeatInterval = 10 * time.Second
+
+// The largest number of times a discovered name can be resolved again
+const maxResolveRepeat = 10
+
+// DefaultSharedFilterTTL is the duration that a name handled by an enumeration sharing the datastore
+// is skipped by the other enumerations when SharedFilterTTL is not set.
+const DefaultSharedFilterTTL = time.Hour
+
+// DefaultInterestingKeywords are the name tokens that raise the score of a discovered name when
+// InterestingKeywords is not set.
+var DefaultInterestingKeywords = []string{
+	"admin", "api", "vpn", "staging", "stage", "internal", "dev", "test", "uat", "qa",
+	"jenkins", "git", "jira", "backup", "db", "sql", "secure", "login", "sso", "auth",
+	"portal", "remote", "intranet", "corp", "beta", "debug", "console", "dashboard", "grafana",
+}
+
 // Updater allows an object to implement a method that updates a configuration.
 type Updater interface {
 	OverrideConfig(*Config) error
@@ -49,6 +102,15 @@ type Config struct {
 	// Logger for error messages
 	Log *log.Logger
 
+	// Receives the leveled messages with fields such as the source name, instead of Log, when set
+	Logger Logger
+
+	// Callbacks assigned before the enumeration starts that receive each validated name and address,
+	// along with the domain, tag and data source. They are called from a dedicated goroutine, and the
+	// results are dropped when the consumer falls behind, which is reported by the DroppedCallbacks statistic
+	OnNewName func(name, domain, tag, source string)
+	OnNewAddr func(addr, domain, tag, source string)
+
 	// Share activates the process that shares findings with providers for service credits
 	Share bool `ini:"share"`
 
@@ -64,6 +126,15 @@ type Config struct {
 	// The graph databases used by the system / enumerations
 	GraphDBs []*Database
 
+	// The graph database backend, such as memory, local, sqlite, postgres or mysql, and the DSN used to
+	// connect with it, which select the only graph database used instead of the GraphDBs
+	GraphDBType string
+	GraphDBDSN  string
+
+	// The data source names of the postgres or mysql graph database endpoints that follow the DSN,
+	// in the order that the writes fail over to them when the current endpoint is lost
+	GraphDBEndpoints []string
+
 	// The maximum number of concurrent DNS queries
 	MaxDNSQueries int `ini:"maximum_dns_queries"`
 
@@ -79,9 +150,28 @@ type Config struct {
 	// ASNs specified as in scope
 	ASNs []int
 
+	// Bring the prefixes announced by the ASNs owning the discovered addresses into scope,
+	// when the ASN has been allowlisted in ExpandASNs
+	ExpandByASN bool
+
+	// The ASNs allowed to have their prefixes brought into scope by ExpandByASN
+	ExpandASNs []int
+
+	// The HTTP(S) endpoint that provides additional scope as a JSON document
+	ScopeURL string
+
+	// The bearer token sent in requests to the scope endpoint
+	ScopeToken string
+
+	// The interval between fetches of the scope endpoint
+	ScopeRefresh time.Duration
+
 	// The ports that will be checked for certificates
 	Ports []int
 
+	// The certificate subject organizations that bring the names of their certificates into scope
+	CertOrgs []string
+
 	// The list of words to use when generating names
 	Wordlist []string
 
@@ -97,6 +187,19 @@ type Config struct {
 	// Maximum depth for bruteforcing
 	MaxDepth int
 
+	// The total number of brute forcing names attempted during the enumeration, or zero for no limit
+	MaxBruteCandidates int
+
+	// The ceiling of the brute forcing names resolved per second, which is lowered adaptively
+	// when the DNS queries are lost to rate limiting, or zero to resolve the names unpaced
+	BruteMaxRate int
+
+	// Will the tokens of the discovered names be learned and combined into new brute forcing names?
+	TokenExpansion bool
+
+	// The total number of names generated from the learned tokens during the enumeration
+	TokenExpansionLimit int
+
 	// Will discovered subdomain name alterations be generated?
 	Alterations    bool
 	FlipWords      bool
@@ -106,6 +209,10 @@ type Config struct {
 	MinForWordFlip int
 	EditDistance   int
 	AltWordlist    []string
+	// Naming conventions whose placeholders are filled with the tokens of discovered names
+	AlterationTemplates []string
+	// The largest number of names generated from the templates for each resolved name
+	AlterationTemplateLimit int
 
 	// Only access the data sources for names and return results?
 	Passive bool
@@ -117,6 +224,17 @@ type Config struct {
 	Blacklist     []string
 	blacklistLock sync.Mutex
 
+	// Regular expressions matching the discovered names that will be dropped
+	ExcludePatterns []string
+	excludeLock     sync.Mutex
+	excludeRegexps  []*regexp.Regexp
+
+	// Regular expressions that the discovered names must match, in addition to belonging to a
+	// root domain name, when any are provided
+	ScopeRegex     []string
+	scopeRegexLock sync.Mutex
+	scopeRegexps   []*regexp.Regexp
+
 	// A list of data sources that should not be utilized
 	SourceFilter struct {
 		Include bool // true = include, false = exclude
@@ -126,15 +244,246 @@ type Config struct {
 	// The minimum number of minutes that data source responses will be reused
 	MinimumTTL int
 
+	// The names discovered per minute, below which the data sources of the next tier are activated
+	TierThreshold float64
+
+	// Data sources that are trusted, or not trusted, regardless of the type of the source
+	TrustedSources   []string
+	UntrustedSources []string
+
+	// Only accept the names and addresses from trusted sources, along with those provided by
+	// the user and generated by the enumeration, such as brute forcing and alterations
+	TrustedOnly bool
+
+	// The most generations that names may be derived from a name provided by an untrusted data source,
+	// such as by recursive brute forcing and alterations, or zero for no limit. The names descending
+	// from trusted sources are derived without a limit
+	MaxRecursionDepthUntrusted int
+
+	// The HTTP headers, such as the User-Agent, set on the requests of all the data sources,
+	// and the User-Agent values rotated across the requests
+	HTTPHeaders map[string]string
+	UserAgents  []string
+
+	// The number of requests per minute permitted for each data source, keyed by the source name
+	SourceRateLimits map[string]int
+	srcRateLock      sync.Mutex
+	srcLastRequest   map[string]time.Time
+
 	// Type of DNS records to query for
 	RecordTypes []string
 
+	// Restrict the enumeration to a single address family
+	IPv4Only bool `ini:"ipv4_only"`
+	IPv6Only bool `ini:"ipv6_only"`
+
 	// Resolver settings
 	Resolvers []string
 
+	// DNS-over-HTTPS resolver URLs (e.g. https://dns.google/dns-query)
+	DoHResolvers []string
+
+	// The DNS record types that each resolver, identified by the address or DoH URL, is not sent
+	ResolverTypeExclusions map[string][]string
+
+	// The socks5:// or http(s):// proxy used for outbound TCP connections and HTTP requests
+	Proxy       string `ini:"proxy"`
+	proxyLock   sync.Mutex
+	proxy       *amassnet.Proxy
+	proxyParsed string
+
 	// Option for verbose logging and output
 	Verbose bool
 
+	// The type of filter used to detect duplicate names ("bloom" or "cuckoo")
+	FilterType string `ini:"filter_type"`
+
+	// The duration that names remain in a cuckoo filter before being evicted
+	FilterTTL time.Duration `ini:"filter_ttl"`
+
+	// The number of names held by the bloom or cuckoo filter, which sets the memory used
+	FilterSize int `ini:"filter_size"`
+
+	// The rate at which the bloom or cuckoo filter reports a name never seen as a duplicate
+	FilterFPRate float64 `ini:"filter_fp_rate"`
+
+	// The file used to restore and save the filter state across enumerations
+	FilterStatePath string `ini:"filter_state_path"`
+
+	// The file that NSEC3 hashes collected during active zone walking are appended to, for offline cracking
+	NSEC3HashesPath string `ini:"nsec3_hashes_path"`
+
+	// The file used to periodically save the enumeration state, so an interrupted enumeration can be resumed
+	CheckpointPath string `ini:"checkpoint_path"`
+
+	// The idle durations that passive and non-passive enumerations wait for new data
+	MinWaitForData time.Duration `ini:"minimum_wait_for_data"`
+	MaxWaitForData time.Duration `ini:"maximum_wait_for_data"`
+
+	// The longest duration the enumeration runs before being stopped, while still providing its output
+	MaxDuration time.Duration `ini:"max_duration"`
+
+	// Monitor the certificate transparency logs for new names until the enumeration is stopped
+	CTTail bool `ini:"ct_tail"`
+
+	// The certificate transparency logs monitored when CTTail is enabled
+	CTLogs []string `ini:"ct_logs"`
+
+	// The duration that DNS wildcard detection results are cached for each subdomain
+	WildcardCacheTTL time.Duration `ini:"wildcard_cache_ttl"`
+
+	// Perform reverse DNS sweeps across the netblocks enclosing the in-scope addresses
+	ReverseDNS bool `ini:"reverse_dns"`
+
+	// The IPv4 prefix length of the largest netblock swept, and the equivalent size for IPv6
+	ReverseDNSMaxPrefix int `ini:"reverse_dns_max_prefix"`
+
+	// Discover the hostnames on the local network segment using mDNS and LLMNR
+	EnableMDNS bool `ini:"enable_mdns"`
+
+	// Brute force the virtual hosts of in-scope addresses by sending candidate names as the TLS SNI
+	VHostBrute bool `ini:"vhost_brute"`
+
+	// The duration that DNS query results are reused within the enumeration
+	DNSCacheTTL time.Duration `ini:"dns_cache_ttl"`
+
+	// The longest duration that NXDOMAIN responses are reused within the enumeration, which is
+	// shortened to the negative caching TTL of the SOA record returned with the response
+	NegativeCacheTTL time.Duration `ini:"negative_cache_ttl"`
+
+	// The number of DNS query results cached before the least recently used are evicted
+	DNSCacheSize int `ini:"dns_cache_size"`
+
+	// The subnets sent in the EDNS Client Subnet option of additional address queries for each name
+	ECSSubnets []string `ini:"ecs_subnets"`
+
+	// The DNS record types, such as MX, SRV and TXT, queried for each name instead of CNAME, A and AAAA
+	QueryTypes []string `ini:"query_types"`
+
+	// The number of times each resolved name is resolved again, collecting the addresses of hosts
+	// that rotate through many of them, and the duration waited between the resolutions
+	ResolveRepeat         int           `ini:"resolve_repeat"`
+	ResolveRepeatInterval time.Duration `ini:"resolve_repeat_interval"`
+
+	// The backend, such as graph, shared by the enumerations that skip the names handled by
+	// each other, and the duration that a handled name is skipped
+	SharedFilter    string        `ini:"shared_filter"`
+	SharedFilterTTL time.Duration `ini:"shared_filter_ttl"`
+
+	// Compare the web server responses for discovered names with the response for a nonexistent
+	// sibling, flagging the names that are suspected wildcard or parking hits
+	HTTPVerify bool `ini:"http_verify"`
+
+	// The name tokens that raise the score used to rank and prioritize the discovered names
+	InterestingKeywords []string `ini:"interesting_keywords"`
+
+	// Serialize the generation and resolution of names, and release them in order by name, so two
+	// enumerations over the same inputs and data sources produce the same results in the same order
+	Deterministic bool `ini:"deterministic"`
+
+	// Run the enumeration in sequential phases: the data sources first, then brute forcing over the
+	// names they discovered, and then the alterations over the combined set. Each phase starts once
+	// the names released by the prior phase have drained from the input source
+	Phased bool `ini:"phased"`
+
+	// The longest duration a zone transfer with a single nameserver is waited for
+	ZoneTransferTimeout time.Duration `ini:"zone_transfer_timeout"`
+
+	// The longest duration a name or address is processed by a stage of the pipeline, such as the DNS
+	// resolution, before the processing is canceled and the slot is released for the queued data
+	RequestTimeout time.Duration `ini:"request_timeout"`
+
+	// The number of times a DNS query is performed again after a timeout, SERVFAIL or REFUSED
+	DNSRetries int `ini:"dns_retries"`
+
+	// The ceiling for the DNS queries in flight, which are adjusted to the observed error rate
+	// and latency of the resolvers when provided
+	MaxConcurrency int `ini:"max_concurrency"`
+
+	// The number of names and addresses provided by the data sources that are evaluated at once,
+	// separately from the data discovered and generated by the active DNS resolution
+	PassiveConcurrency int `ini:"passive_concurrency"`
+
+	// The number of names resolved, and DNS queries in flight, at once for the active DNS resolution,
+	// which does not compete with the data sources for the intake of the enumeration
+	ActiveConcurrency int `ini:"active_concurrency"`
+
+	// The maximum number of DNS queries in flight for the zones served by the same authoritative server
+	MaxQueriesPerServer int `ini:"max_queries_per_server"`
+
+	// The local addresses the DNS queries sent to the resolvers are bound to in turn, which must be
+	// assigned to the interfaces of the host
+	SourceIPs []string `ini:"source_ips"`
+
+	// Send the queries for the names in each zone of the scope to the authoritative servers of the
+	// zone, instead of the recursive resolvers, which are used when the servers fail to answer
+	QueryAuthoritative bool `ini:"query_authoritative"`
+
+	// Request the DNSSEC records with the queries and validate the chain of trust of the answers
+	// from the root trust anchor, tagging each record as secure, insecure or bogus
+	ValidateDNSSEC bool `ini:"validate_dnssec"`
+
+	// Drop the records that fail the DNSSEC validation, instead of only flagging them
+	DNSSECDropBogus bool `ini:"dnssec_drop_bogus"`
+
+	// Skip the brute forcing and alterations for the root domain names without NS records or
+	// addresses at the apex, which are checked at the start of the enumeration
+	SkipDeadZones bool `ini:"skip_dead_zones"`
+
+	// The success rate that a resolver must fall below before being ejected from the pool
+	ResolverEjectThreshold float64 `ini:"resolver_eject_threshold"`
+
+	// Eject the resolvers that answer the queries for nonexistent names, such as a random canary
+	// name queried when each resolver is added to the pool, with injected addresses
+	DetectPoisonedResolvers bool `ini:"detect_poisoned_resolvers"`
+
+	// The address the read-only HTTP API serving the results discovered so far listens on
+	APIAddr string `ini:"api_addr"`
+
+	// The address the liveness, readiness and metrics endpoints for service deployments listen on
+	HealthAddr string `ini:"health_addr"`
+
+	// The files that each receive the results of the enumeration in their own format
+	Outputs []*OutputSink
+
+	// The JSON Lines file that receives the in-scope names generated by brute forcing and alterations
+	// that do not exist, which are kept out of the other outputs
+	OutputUnresolved string
+
+	// Compress the files written by the output sinks and the unresolved names file with gzip,
+	// as is done for any output path ending in the .gz extension
+	CompressOutputs bool
+
+	// The largest number of labels beneath the root domain name of the names written to the outputs,
+	// while the enumeration and the graph database still receive the deeper names. Zero has no limit
+	MaxLabelDepth int
+
+	// The Elasticsearch index that receives the results as they are discovered
+	ElasticSearch *ElasticSearch
+
+	// The URL that the results are posted to, in batches of JSON objects, as they are discovered
+	WebhookURL string
+
+	// The headers sent with each webhook request, such as the credentials
+	WebhookHeaders map[string]string
+
+	// Wildcard patterns, such as *vpn*, matching the discovered names that raise an alert
+	AlertPatterns []string
+	alertLock     sync.Mutex
+	alertRegexps  []*regexp.Regexp
+
+	// The URL that each alert is posted to, in addition to the log line written for the alert
+	AlertWebhookURL string
+
+	// Wildcard patterns, such as *-int, matching the labels of discovered names that are replaced
+	// by a placeholder in the files and services receiving the results, but not in the graph database
+	RedactPatterns []string
+	redactLock     sync.Mutex
+	redactRegexps  []*regexp.Regexp
+
+	// The brute forcing and alteration settings overridden for each root domain name
+	DomainOverrides map[string]*DomainSettings
+
 	// The root domain names that the enumeration will target
 	domains []string
 
@@ -163,6 +512,14 @@ func NewConfig() *Config {
 		EditDistance:   1,
 		Recursive:      true,
 		MinimumTTL:     1440,
+		DNSCacheTTL:    DefaultDNSCacheTTL,
+
+		NegativeCacheTTL: DefaultNegativeCacheTTL,
+		RequestTimeout:   DefaultRequestTimeout,
+		BruteMaxRate:     DefaultBruteMaxRate,
+
+		TokenExpansionLimit:     DefaultTokenExpansionLimit,
+		AlterationTemplateLimit: DefaultAlterationTemplateLimit,
 	}
 
 	c.calcDNSQueriesMax()
@@ -174,14 +531,29 @@ func (c *Config) UpdateConfig(update Updater) error {
 	return update.OverrideConfig(c)
 }
 
+// ReverseDNSPrefixLen returns the prefix length of the largest netblock swept for the address family.
+func (c *Config) ReverseDNSPrefixLen(ipv6 bool) int {
+	prefix := DefaultReverseDNSMaxPrefix
+	if c.ReverseDNSMaxPrefix > 0 {
+		prefix = c.ReverseDNSMaxPrefix
+	}
+	// The IPv6 netblock contains the same number of addresses as the IPv4 netblock
+	if ipv6 {
+		prefix += 128 - 32
+	}
+	return prefix
+}
+
 // CheckSettings runs some sanity checks on the configuration options selected.
 func (c *Config) CheckSettings() error {
 	var err error
 
-	if c.BruteForcing {
-		if c.Passive {
-			return errors.New("brute forcing cannot be performed without DNS resolution")
-		} else if len(c.Wordlist) == 0 {
+	if c.BruteForcing && c.Passive {
+		return errors.New("brute forcing cannot be performed without DNS resolution")
+	}
+	// The default wordlist is also loaded for the domains that enable brute forcing
+	if c.BruteForcing || c.VHostBrute || c.anyDomainEnables(func(s *DomainSettings) *bool { return s.BruteForcing }) {
+		if len(c.Wordlist) == 0 {
 			f, err := resources.GetResourceFile("namelist.txt")
 			if err != nil {
 				return err
@@ -196,7 +568,229 @@ func (c *Config) CheckSettings() error {
 	if c.Passive && c.Active {
 		return errors.New("active enumeration cannot be performed without DNS resolution")
 	}
-	if c.Alterations {
+	if c.EnableMDNS && c.Passive {
+		return errors.New("mDNS and LLMNR discovery cannot be performed without DNS resolution")
+	}
+	if c.VHostBrute && !c.Active {
+		return errors.New("virtual host brute forcing requires active enumeration")
+	}
+	if c.HTTPVerify && !c.Active {
+		return errors.New("HTTP verification requires active enumeration")
+	}
+	if c.Phased && c.Passive {
+		return errors.New("the phased execution mode cannot be used without DNS resolution")
+	}
+	if c.ValidateDNSSEC && c.Passive {
+		return errors.New("the DNSSEC validation cannot be used without DNS resolution")
+	}
+	if c.DNSSECDropBogus && !c.ValidateDNSSEC {
+		return errors.New("the bogus records can only be dropped when the DNSSEC validation is enabled")
+	}
+	if c.SkipDeadZones && c.Passive {
+		return errors.New("the dead zones cannot be detected without DNS resolution")
+	}
+	if c.ReverseDNS && c.Passive {
+		return errors.New("reverse DNS sweeps cannot be performed without DNS resolution")
+	}
+	if c.ReverseDNSMaxPrefix < 0 || c.ReverseDNSMaxPrefix > 32 {
+		return errors.New("the reverse DNS maximum prefix length must be between 0 and 32")
+	}
+	if c.IPv4Only && c.IPv6Only {
+		return errors.New("the IPv4 only and IPv6 only settings cannot both be enabled")
+	}
+	for _, u := range c.DoHResolvers {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("%s is not a valid DNS-over-HTTPS resolver URL", u)
+		}
+	}
+	if err := c.checkResolverTypeExclusions(); err != nil {
+		return err
+	}
+	if c.Proxy != "" {
+		if _, err := amassnet.ParseProxyURL(c.Proxy); err != nil {
+			return err
+		}
+	}
+	switch strings.ToLower(c.FilterType) {
+	case "", "bloom", "cuckoo":
+	default:
+		return fmt.Errorf("%s is not a supported filter type", c.FilterType)
+	}
+	if c.FilterTTL < 0 {
+		return errors.New("the filter TTL cannot be negative")
+	}
+	if c.FilterSize < 0 || c.FilterSize > maxFilterSize {
+		return fmt.Errorf("the filter size must be between 0 and %d", maxFilterSize)
+	}
+	if c.FilterFPRate < 0 || c.FilterFPRate > maxFilterFPRate {
+		return fmt.Errorf("the filter false positive rate must be between 0 and %g", maxFilterFPRate)
+	}
+	if c.MinWaitForData < 0 || c.MaxWaitForData < 0 {
+		return errors.New("the wait for data durations cannot be negative")
+	}
+	if c.MinWaitForData > 0 && c.MaxWaitForData > 0 && c.MaxWaitForData < c.MinWaitForData {
+		return errors.New("the maximum wait for data cannot be less than the minimum")
+	}
+	if c.TierThreshold < 0 {
+		return errors.New("the data source tier threshold cannot be negative")
+	}
+	if c.MaxDuration < 0 {
+		return errors.New("the maximum enumeration duration cannot be negative")
+	}
+	for _, src := range c.TrustedSources {
+		if c.IsUntrustedSource(src) {
+			return fmt.Errorf("the %s data source cannot be both trusted and untrusted", src)
+		}
+	}
+	for _, u := range c.CTLogs {
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("%s is not a valid certificate transparency log URL", u)
+		}
+	}
+	if err := c.compileExcludePatterns(); err != nil {
+		return err
+	}
+	if err := c.compileScopeRegex(); err != nil {
+		return err
+	}
+	if c.WildcardCacheTTL < 0 {
+		return errors.New("the wildcard cache TTL cannot be negative")
+	}
+	if c.DNSCacheTTL < 0 || c.DNSCacheSize < 0 {
+		return errors.New("the DNS cache TTL and size cannot be negative")
+	}
+	if c.NegativeCacheTTL < 0 {
+		return errors.New("the negative cache TTL cannot be negative")
+	}
+	for _, subnet := range c.ECSSubnets {
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			return fmt.Errorf("%s is not a valid EDNS client subnet", subnet)
+		}
+	}
+	if err := c.checkQueryTypes(); err != nil {
+		return err
+	}
+	if c.ResolveRepeat < 0 || c.ResolveRepeat > maxResolveRepeat {
+		return fmt.Errorf("the number of repeated resolutions must be between 0 and %d", maxResolveRepeat)
+	}
+	if c.ResolveRepeatInterval < 0 {
+		return errors.New("the repeated resolution interval cannot be negative")
+	}
+	if c.ResolveRepeat > 0 && c.Passive {
+		return errors.New("names cannot be resolved again without DNS resolution")
+	}
+	if c.SharedFilter != "" && c.SharedFilter != "graph" {
+		return fmt.Errorf("%s is not a supported shared filter backend", c.SharedFilter)
+	}
+	if c.SharedFilterTTL < 0 {
+		return errors.New("the shared filter TTL cannot be negative")
+	}
+	for _, kw := range c.InterestingKeywords {
+		if strings.TrimSpace(kw) == "" {
+			return errors.New("the interesting keywords cannot be empty")
+		}
+	}
+	if c.MaxBruteCandidates < 0 {
+		return errors.New("the maximum number of brute forcing candidates cannot be negative")
+	}
+	if c.MaxRecursionDepthUntrusted < 0 {
+		return errors.New("the maximum recursion depth for untrusted sources cannot be negative")
+	}
+	if c.BruteMaxRate < 0 {
+		return errors.New("the maximum brute forcing rate cannot be negative")
+	}
+	if c.TokenExpansionLimit < 0 {
+		return errors.New("the limit of the names generated from the learned tokens cannot be negative")
+	}
+	if c.DNSRetries < 0 {
+		return errors.New("the number of DNS retries cannot be negative")
+	}
+	if c.ZoneTransferTimeout < 0 {
+		return errors.New("the zone transfer timeout cannot be negative")
+	}
+	if c.ExpandByASN && len(c.ExpandASNs) == 0 {
+		return errors.New("the ASNs allowed to expand the scope must be provided")
+	}
+	if c.RequestTimeout < 0 {
+		return errors.New("the request timeout cannot be negative")
+	}
+	if c.MaxConcurrency < 0 {
+		return errors.New("the maximum concurrency cannot be negative")
+	}
+	if c.PassiveConcurrency < 0 {
+		return errors.New("the passive concurrency cannot be negative")
+	}
+	if c.ActiveConcurrency < 0 {
+		return errors.New("the active concurrency cannot be negative")
+	}
+	if c.ActiveConcurrency > 0 && c.Passive {
+		return errors.New("the active concurrency cannot be used without DNS resolution")
+	}
+	if c.MaxQueriesPerServer < 0 {
+		return errors.New("the maximum queries per authoritative server cannot be negative")
+	}
+	for _, ip := range c.SourceIPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("%s is not a valid source IP address", ip)
+		}
+	}
+	if c.ResolverEjectThreshold < 0 || c.ResolverEjectThreshold > 1 {
+		return errors.New("the resolver eject threshold must be between zero and one")
+	}
+	if c.APIAddr != "" {
+		if _, _, err := net.SplitHostPort(c.APIAddr); err != nil {
+			return fmt.Errorf("%s is not a valid API address: %v", c.APIAddr, err)
+		}
+	}
+	if c.HealthAddr != "" {
+		if _, _, err := net.SplitHostPort(c.HealthAddr); err != nil {
+			return fmt.Errorf("%s is not a valid health address: %v", c.HealthAddr, err)
+		}
+	}
+	for _, tmpl := range c.AlterationTemplates {
+		if _, err := ParseAlterationTemplate(tmpl); err != nil {
+			return err
+		}
+	}
+	if c.AlterationTemplateLimit < 0 {
+		return errors.New("the alteration template limit cannot be negative")
+	}
+	for _, sink := range c.Outputs {
+		if err := sink.check(); err != nil {
+			return err
+		}
+	}
+	if c.MaxLabelDepth < 0 {
+		return errors.New("the max label depth cannot be negative")
+	}
+	if c.ElasticSearch != nil {
+		if err := c.ElasticSearch.check(); err != nil {
+			return err
+		}
+	}
+	if c.WebhookURL != "" {
+		if err := c.checkWebhook(); err != nil {
+			return err
+		}
+	}
+	if err := c.checkGraphDBType(); err != nil {
+		return err
+	}
+	if err := c.checkAlerts(); err != nil {
+		return err
+	}
+	if err := c.checkRedactions(); err != nil {
+		return err
+	}
+	if c.ScopeURL != "" {
+		if u, err := url.Parse(c.ScopeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s is not a valid scope URL", c.ScopeURL)
+		}
+	}
+	if c.ScopeRefresh < 0 {
+		return errors.New("the scope refresh interval cannot be negative")
+	}
+	if c.Alterations || c.anyDomainEnables(func(s *DomainSettings) *bool { return s.Alterations }) {
 		if len(c.AltWordlist) == 0 {
 			f, err := resources.GetResourceFile("alterations.txt")
 			if err != nil {
@@ -224,10 +818,19 @@ func (c *Config) CheckSettings() error {
 
 // LoadSettings parses settings from an .ini file and assigns them to the Config.
 func (c *Config) LoadSettings(path string) error {
+	return c.loadSettings(path)
+}
+
+// LoadSettingsData parses settings from the contents of an .ini file and assigns them to the Config.
+func (c *Config) LoadSettingsData(data []byte) error {
+	return c.loadSettings(data)
+}
+
+func (c *Config) loadSettings(source interface{}) error {
 	cfg, err := ini.LoadSources(ini.LoadOptions{
 		Insensitive:  true,
 		AllowShadows: true,
-	}, path)
+	}, source)
 	if err != nil {
 		return fmt.Errorf("failed to load the configuration file: %v", err)
 	}
@@ -251,7 +854,12 @@ func (c *Config) LoadSettings(path string) error {
 		c.loadScopeSettings,
 		c.loadAlterationSettings,
 		c.loadBruteForceSettings,
+		c.loadDomainSettings,
 		c.loadDatabaseSettings,
+		c.loadElasticSettings,
+		c.loadWebhookSettings,
+		c.loadAlertSettings,
+		c.loadOutputSettings,
 		c.loadDataSourceSettings,
 	}
 	for _, load := range loads {
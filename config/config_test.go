@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestCheckSettings(t *testing.T) {
@@ -148,6 +149,363 @@ func TestConfigCheckSettings(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "negative wait for data duration",
+			fields: fields{
+				&Config{MinWaitForData: -time.Second},
+			},
+			wantErr: true,
+		},
+		{
+			name: "maximum wait for data less than the minimum",
+			fields: fields{
+				&Config{MinWaitForData: time.Minute, MaxWaitForData: time.Second},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative wildcard cache TTL",
+			fields: fields{
+				&Config{WildcardCacheTTL: -time.Second},
+			},
+			wantErr: true,
+		},
+		{
+			name: "reverse DNS in passive mode",
+			fields: fields{
+				&Config{ReverseDNS: true, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative DNS cache size",
+			fields: fields{
+				&Config{DNSCacheSize: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid EDNS client subnet",
+			fields: fields{
+				&Config{ECSSubnets: []string{"192.0.2.1"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid source IP address",
+			fields: fields{
+				&Config{SourceIPs: []string{"192.0.2.300"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty interesting keyword",
+			fields: fields{
+				&Config{InterestingKeywords: []string{"admin", " "}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative maximum brute forcing candidates",
+			fields: fields{
+				&Config{MaxBruteCandidates: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative token expansion limit",
+			fields: fields{
+				&Config{TokenExpansionLimit: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative maximum recursion depth for untrusted sources",
+			fields: fields{
+				&Config{MaxRecursionDepthUntrusted: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative maximum brute forcing rate",
+			fields: fields{
+				&Config{BruteMaxRate: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative filter size",
+			fields: fields{
+				&Config{FilterSize: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "filter false positive rate out of range",
+			fields: fields{
+				&Config{FilterFPRate: 0.5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid webhook URL",
+			fields: fields{
+				&Config{WebhookURL: "hooks.example.com/amass"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many repeated resolutions",
+			fields: fields{
+				&Config{ResolveRepeat: 50},
+			},
+			wantErr: true,
+		},
+		{
+			name: "repeated resolutions while passive",
+			fields: fields{
+				&Config{ResolveRepeat: 3, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty alert pattern",
+			fields: fields{
+				&Config{AlertPatterns: []string{"*vpn*", " "}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid alert webhook URL",
+			fields: fields{
+				&Config{AlertPatterns: []string{"*vpn*"}, AlertWebhookURL: "ftp://alerts.example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported query type",
+			fields: fields{
+				&Config{QueryTypes: []string{"A", "AXFR"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "graph database without a DSN",
+			fields: fields{
+				&Config{GraphDBType: "postgres"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "graph database endpoints without a networked type",
+			fields: fields{
+				&Config{GraphDBType: "local", GraphDBEndpoints: []string{"postgres://db2.example.com/amass"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sqlite graph database without a DSN",
+			fields: fields{
+				&Config{GraphDBType: "sqlite"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported graph database type",
+			fields: fields{
+				&Config{GraphDBType: "neo4j"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported shared filter",
+			fields: fields{
+				&Config{SharedFilter: "redis"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative queries per server",
+			fields: fields{
+				&Config{MaxQueriesPerServer: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative DNS retries",
+			fields: fields{
+				&Config{DNSRetries: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "resolver eject threshold above one",
+			fields: fields{
+				&Config{ResolverEjectThreshold: 1.5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mDNS discovery during a passive enumeration",
+			fields: fields{
+				&Config{EnableMDNS: true, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "vhost brute forcing without active enumeration",
+			fields: fields{
+				&Config{VHostBrute: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "HTTP verification without active enumeration",
+			fields: fields{
+				&Config{HTTPVerify: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "phased execution without DNS resolution",
+			fields: fields{
+				&Config{Phased: true, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DNSSEC validation without DNS resolution",
+			fields: fields{
+				&Config{ValidateDNSSEC: true, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative passive concurrency",
+			fields: fields{
+				&Config{PassiveConcurrency: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "active concurrency without DNS resolution",
+			fields: fields{
+				&Config{ActiveConcurrency: 10, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dead zone detection without DNS resolution",
+			fields: fields{
+				&Config{SkipDeadZones: true, Passive: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dropping bogus records without DNSSEC validation",
+			fields: fields{
+				&Config{DNSSECDropBogus: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max duration",
+			fields: fields{
+				&Config{MaxDuration: -time.Minute},
+			},
+			wantErr: true,
+		},
+		{
+			name: "API address without a port",
+			fields: fields{
+				&Config{APIAddr: "127.0.0.1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "health address without a port",
+			fields: fields{
+				&Config{HealthAddr: "localhost"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative zone transfer timeout",
+			fields: fields{
+				&Config{ZoneTransferTimeout: -time.Second},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ASN expansion without allowlisted ASNs",
+			fields: fields{
+				&Config{ExpandByASN: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative maximum concurrency",
+			fields: fields{
+				&Config{MaxConcurrency: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "alteration template without a placeholder",
+			fields: fields{
+				&Config{AlterationTemplates: []string{"api-prod"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative alteration template limit",
+			fields: fields{
+				&Config{AlterationTemplateLimit: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max label depth",
+			fields: fields{
+				&Config{MaxLabelDepth: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported output sink format",
+			fields: fields{
+				&Config{Outputs: []*OutputSink{{Format: "xml", Path: "amass.xml"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Elasticsearch URL",
+			fields: fields{
+				&Config{ElasticSearch: &ElasticSearch{URL: "elastic.example.com:9200"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid scope URL",
+			fields: fields{
+				&Config{ScopeURL: "ftp://inventory.example.com/scope"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid exclude pattern",
+			fields: fields{
+				&Config{ExcludePatterns: []string{`^node-[0-9a-f+\.`}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid scope regex",
+			fields: fields{
+				&Config{ScopeRegex: []string{`^api-(.*\.prod\.`}},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
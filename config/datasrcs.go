@@ -8,15 +8,21 @@ import (
 	"math/rand"
 	"strings"
 
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
 )
 
 // DataSourceConfig contains the configurations specific to a data source.
 type DataSourceConfig struct {
-	Name  string
-	TTL   int `ini:"ttl"`
-	creds map[string]*Credentials
+	Name      string
+	TTL       int `ini:"ttl"`
+	RateLimit int `ini:"rate_limit"`
+	Tier      int `ini:"tier"`
+	// The HTTP headers and User-Agent values that take precedence over the global settings
+	Headers    map[string]string `ini:"-"`
+	UserAgents []string          `ini:"-"`
+	creds      map[string]*Credentials
 }
 
 // Credentials contains values required for authenticating with web APIs.
@@ -49,6 +55,47 @@ func (c *Config) GetDataSourceConfig(source string) *DataSourceConfig {
 	return c.datasrcConfigs[key]
 }
 
+// DataSourceTier returns the tier assigned to the data source name argument.
+// Data sources without a tier belong to the first tier, numbered zero.
+func (c *Config) DataSourceTier(source string) int {
+	c.Lock()
+	defer c.Unlock()
+
+	if dsc, found := c.datasrcConfigs[strings.ToLower(strings.TrimSpace(source))]; found && dsc.Tier > 0 {
+		return dsc.Tier
+	}
+	return 0
+}
+
+// HeaderProfile returns the HTTP headers and User-Agent values applied to the requests of the named
+// data source. The headers of the data source replace the global headers of the same name, and the
+// User-Agent values of the data source replace the global list. Nil is returned when none are configured.
+func (c *Config) HeaderProfile(source string) *http.HeaderProfile {
+	c.Lock()
+	defer c.Unlock()
+
+	hp := &http.HeaderProfile{
+		Headers:    make(map[string]string, len(c.HTTPHeaders)),
+		UserAgents: c.UserAgents,
+	}
+	for k, v := range c.HTTPHeaders {
+		hp.Headers[k] = v
+	}
+	if dsc, found := c.datasrcConfigs[strings.ToLower(strings.TrimSpace(source))]; found {
+		for k, v := range dsc.Headers {
+			hp.Headers[k] = v
+		}
+		if len(dsc.UserAgents) > 0 {
+			hp.UserAgents = dsc.UserAgents
+		}
+	}
+
+	if len(hp.Headers) == 0 && len(hp.UserAgents) == 0 {
+		return nil
+	}
+	return hp
+}
+
 // AddCredentials adds the Credentials provided to the configuration.
 func (dsc *DataSourceConfig) AddCredentials(cred *Credentials) error {
 	if cred == nil || cred.Name == "" {
@@ -86,6 +133,32 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 			c.MinimumTTL = ttl
 		}
 	}
+	if sec.HasKey("tier_threshold") {
+		if threshold, err := sec.Key("tier_threshold").Float64(); err == nil {
+			c.TierThreshold = threshold
+		}
+	}
+	if sec.HasKey("trusted_only") {
+		if only, err := sec.Key("trusted_only").Bool(); err == nil {
+			c.TrustedOnly = only
+		}
+	}
+	if sec.HasKey("max_recursion_depth_untrusted") {
+		if depth, err := sec.Key("max_recursion_depth_untrusted").Int(); err == nil {
+			c.MaxRecursionDepthUntrusted = depth
+		}
+	}
+
+	headers, agents, err := loadHTTPHeaders(sec)
+	if err != nil {
+		return err
+	}
+	if len(headers) > 0 {
+		c.HTTPHeaders = headers
+	}
+	if len(agents) > 0 {
+		c.UserAgents = agents
+	}
 
 	for _, child := range sec.ChildSections() {
 		name := strings.Split(child.Name(), ".")[1]
@@ -96,6 +169,14 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 			c.SourceFilter.Include = false
 			continue
 		}
+		if name == "trusted" {
+			c.TrustedSources = stringset.Deduplicate(child.Key("data_source").ValueWithShadows())
+			continue
+		}
+		if name == "untrusted" {
+			c.UntrustedSources = stringset.Deduplicate(child.Key("data_source").ValueWithShadows())
+			continue
+		}
 
 		dsc := c.GetDataSourceConfig(name)
 		// Parse the Database information and assign to the Config
@@ -106,6 +187,12 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 		if c.MinimumTTL > dsc.TTL {
 			dsc.TTL = c.MinimumTTL
 		}
+		if dsc.RateLimit > 0 {
+			c.SetSourceRateLimit(name, dsc.RateLimit)
+		}
+		if dsc.Headers, dsc.UserAgents, err = loadHTTPHeaders(child); err != nil {
+			return err
+		}
 		// Check for data source credentials
 		for _, cr := range child.ChildSections() {
 			setName := strings.Split(cr.Name(), ".")[2]
@@ -122,3 +209,50 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 
 	return nil
 }
+
+// loadHTTPHeaders returns the headers provided as "Name: value" by the header keys
+// of the section, and the User-Agent values provided by the user_agent keys.
+func loadHTTPHeaders(sec *ini.Section) (map[string]string, []string, error) {
+	var headers map[string]string
+
+	if sec.HasKey("header") {
+		headers = make(map[string]string)
+
+		for _, h := range sec.Key("header").ValueWithShadows() {
+			parts := strings.SplitN(h, ":", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, nil, fmt.Errorf("the %s header must be provided as the name and value separated by a colon", h)
+			}
+			headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	var agents []string
+	if sec.HasKey("user_agent") {
+		for _, ua := range sec.Key("user_agent").ValueWithShadows() {
+			if ua = strings.TrimSpace(ua); ua != "" {
+				agents = append(agents, ua)
+			}
+		}
+	}
+	return headers, agents, nil
+}
+
+// IsTrustedSource returns true if the data source was added to the TrustedSources setting.
+func (c *Config) IsTrustedSource(source string) bool {
+	return containsSource(c.TrustedSources, source)
+}
+
+// IsUntrustedSource returns true if the data source was added to the UntrustedSources setting.
+func (c *Config) IsUntrustedSource(source string) bool {
+	return containsSource(c.UntrustedSources, source)
+}
+
+func containsSource(sources []string, source string) bool {
+	for _, src := range sources {
+		if strings.EqualFold(strings.TrimSpace(src), strings.TrimSpace(source)) {
+			return true
+		}
+	}
+	return false
+}
@@ -80,12 +80,21 @@ func TestLoadDataSourceSettings(t *testing.T) {
 		[]byte(`
 		[data_sources]
 		minimum_ttl = 1440
+		tier_threshold = 2.5
+		max_recursion_depth_untrusted = 2
+		header = X-Requested-By: Amass
+		header = Accept: application/json
+		user_agent = agent-one
+		user_agent = agent-two
 
 		[data_sources.disabled]
 		data_source = CommonCrawl
 
 		[data_sources.AlienVault]
 		ttl = 4320
+		tier = 2
+		header = Accept: text/plain
+		user_agent = alienvault-agent
 		[data_sources.AlienVault.Credentials]
 		apikey = fake
 
@@ -98,9 +107,15 @@ func TestLoadDataSourceSettings(t *testing.T) {
 	if err := c.loadDataSourceSettings(cfg); err != nil {
 		t.Errorf("Failed to parse the data source settings: %v", err)
 	}
-	if c.MinimumTTL != 1440 {
+	if c.MinimumTTL != 1440 || c.TierThreshold != 2.5 {
 		t.Errorf("Failed to load global data source settings")
 	}
+	if c.MaxRecursionDepthUntrusted != 2 {
+		t.Errorf("Failed to load the maximum recursion depth for untrusted sources")
+	}
+	if c.DataSourceTier("AlienVault") != 2 || c.DataSourceTier("BinaryEdge") != 0 {
+		t.Errorf("Failed to load the data source tiers")
+	}
 
 	dsc := c.GetDataSourceConfig("AlienVault")
 	if dsc == nil {
@@ -109,4 +124,38 @@ func TestLoadDataSourceSettings(t *testing.T) {
 	if creds := dsc.GetCredentials(); creds == nil || creds.Key != "fake" {
 		t.Errorf("Failed to load data source credentials")
 	}
+
+	hp := c.HeaderProfile("alienvault")
+	if hp == nil || hp.Headers["Accept"] != "text/plain" || hp.Headers["X-Requested-By"] != "Amass" ||
+		len(hp.UserAgents) != 1 || hp.UserAgents[0] != "alienvault-agent" {
+		t.Errorf("The data source headers did not take precedence over the global headers: %v", hp)
+	}
+	if hp := c.HeaderProfile("BinaryEdge"); hp == nil || hp.Headers["Accept"] != "application/json" || len(hp.UserAgents) != 2 {
+		t.Errorf("The global headers were not applied to the data source: %v", hp)
+	}
+
+	cfg, _ = ini.LoadSources(ini.LoadOptions{Insensitive: true, AllowShadows: true}, []byte(`
+		[data_sources]
+		header = X-Missing-Value
+		`))
+	if err := NewConfig().loadDataSourceSettings(cfg); err == nil {
+		t.Errorf("Failed to report the header without a value")
+	}
+}
+
+func TestHeaderProfile(t *testing.T) {
+	c := NewConfig()
+
+	if hp := c.HeaderProfile("Shodan"); hp != nil {
+		t.Errorf("A profile was returned without the headers configured: %v", hp)
+	}
+
+	c.HTTPHeaders = map[string]string{"User-Agent": "custom"}
+	c.GetDataSourceConfig("Shodan").Headers = map[string]string{"X-Key": "value"}
+	if hp := c.HeaderProfile("SHODAN"); hp == nil || len(hp.Headers) != 2 || hp.Headers["User-Agent"] != "custom" {
+		t.Errorf("The global and data source headers were not combined: %v", hp)
+	}
+	if len(c.HTTPHeaders) != 1 {
+		t.Errorf("The global headers were modified by the data source headers")
+	}
 }
@@ -0,0 +1,144 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffix/stringset"
+	"github.com/go-ini/ini"
+)
+
+const domainSettingsPrefix = "domain_settings."
+
+// DomainSettings overrides the brute forcing and alteration settings for a root domain name.
+// A nil field, or an empty wordlist, leaves the global setting in place for the domain.
+type DomainSettings struct {
+	BruteForcing *bool
+	Wordlist     []string
+	Alterations  *bool
+	AltWordlist  []string
+}
+
+// SetDomainSettings assigns the settings that override the global configuration for the root domain name.
+func (c *Config) SetDomainSettings(domain string, settings *DomainSettings) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.DomainOverrides == nil {
+		c.DomainOverrides = make(map[string]*DomainSettings)
+	}
+	c.DomainOverrides[strings.ToLower(strings.TrimSpace(domain))] = settings
+}
+
+// DomainSettingsFor returns the settings overridden for the root domain name that the name
+// in the parameter belongs to, or nil when no settings have been overridden.
+func (c *Config) DomainSettingsFor(name string) *DomainSettings {
+	domain := c.WhichDomain(name)
+	if domain == "" {
+		return nil
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	return c.DomainOverrides[domain]
+}
+
+// BruteForcingFor returns true if brute forcing is enabled for the domain of the name in the parameter.
+func (c *Config) BruteForcingFor(name string) bool {
+	if s := c.DomainSettingsFor(name); s != nil && s.BruteForcing != nil {
+		return *s.BruteForcing
+	}
+	return c.BruteForcing
+}
+
+// WordlistFor returns the brute forcing wordlist for the domain of the name in the parameter.
+func (c *Config) WordlistFor(name string) []string {
+	if s := c.DomainSettingsFor(name); s != nil && len(s.Wordlist) > 0 {
+		return s.Wordlist
+	}
+	return c.Wordlist
+}
+
+// AlterationsFor returns true if name alterations are enabled for the domain of the name in the parameter.
+func (c *Config) AlterationsFor(name string) bool {
+	if s := c.DomainSettingsFor(name); s != nil && s.Alterations != nil {
+		return *s.Alterations
+	}
+	return c.Alterations
+}
+
+// AltWordlistFor returns the alterations wordlist for the domain of the name in the parameter.
+func (c *Config) AltWordlistFor(name string) []string {
+	if s := c.DomainSettingsFor(name); s != nil && len(s.AltWordlist) > 0 {
+		return s.AltWordlist
+	}
+	return c.AltWordlist
+}
+
+// anyDomainEnables returns true if the settings of at least one domain enable the feature.
+func (c *Config) anyDomainEnables(enabled func(s *DomainSettings) *bool) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, s := range c.DomainOverrides {
+		if b := enabled(s); b != nil && *b {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) loadDomainSettings(cfg *ini.File) error {
+	for _, sec := range cfg.Sections() {
+		if !strings.HasPrefix(sec.Name(), domainSettingsPrefix) {
+			continue
+		}
+
+		domain := strings.TrimPrefix(sec.Name(), domainSettingsPrefix)
+		if domain == "" {
+			continue
+		}
+
+		settings := new(DomainSettings)
+		if sec.HasKey("brute_forcing") {
+			enabled := sec.Key("brute_forcing").MustBool(false)
+			settings.BruteForcing = &enabled
+		}
+		if sec.HasKey("alterations") {
+			enabled := sec.Key("alterations").MustBool(false)
+			settings.Alterations = &enabled
+		}
+
+		for _, wordlist := range sec.Key("wordlist_file").ValueWithShadows() {
+			if wordlist == "" {
+				continue
+			}
+
+			list, err := GetListFromFile(wordlist)
+			if err != nil {
+				return fmt.Errorf("Unable to load the file in the %s wordlist_file setting: %s: %v", sec.Name(), wordlist, err)
+			}
+			settings.Wordlist = append(settings.Wordlist, list...)
+		}
+		for _, wordlist := range sec.Key("alt_wordlist_file").ValueWithShadows() {
+			if wordlist == "" {
+				continue
+			}
+
+			list, err := GetListFromFile(wordlist)
+			if err != nil {
+				return fmt.Errorf("Unable to load the file in the %s alt_wordlist_file setting: %s: %v", sec.Name(), wordlist, err)
+			}
+			settings.AltWordlist = append(settings.AltWordlist, list...)
+		}
+
+		settings.Wordlist = stringset.Deduplicate(settings.Wordlist)
+		settings.AltWordlist = stringset.Deduplicate(settings.AltWordlist)
+		c.SetDomainSettings(domain, settings)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDomainSettings(t *testing.T) {
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := ioutil.WriteFile(wordlist, []byte("dev\nstaging\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConfig()
+	c.AddDomains("owasp.org", "example.com")
+	data := []byte("[bruteforce]\nenabled = false\n\n[domain_settings.owasp.org]\nbrute_forcing = true\n" +
+		"wordlist_file = " + wordlist + "\nalterations = false\n\n[data_sources]\n")
+	if err := c.LoadSettingsData(data); err != nil {
+		t.Fatalf("LoadSettingsData() error = %v", err)
+	}
+	if err := c.CheckSettings(); err != nil {
+		t.Fatalf("CheckSettings() error = %v", err)
+	}
+
+	if !c.BruteForcingFor("www.owasp.org") || c.BruteForcingFor("www.example.com") {
+		t.Errorf("Brute forcing was not only enabled for the overridden domain")
+	}
+	got := c.WordlistFor("owasp.org")
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"dev", "staging"}) {
+		t.Errorf("WordlistFor() returned %v for the overridden domain", got)
+	}
+	if got := c.WordlistFor("example.com"); len(got) == 0 || len(got) == 2 {
+		t.Errorf("WordlistFor() did not return the default wordlist for the other domain")
+	}
+	if c.AlterationsFor("www.owasp.org") || !c.AlterationsFor("www.example.com") {
+		t.Errorf("Alterations were not only disabled for the overridden domain")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/go-ini/ini"
+)
+
+// DefaultElasticIndex is the Elasticsearch index used when the index has not been provided.
+const DefaultElasticIndex = "amass"
+
+// ElasticSearch contains the values required for indexing results into Elasticsearch.
+type ElasticSearch struct {
+	URL      string `ini:"url"`
+	Index    string `ini:"index"`
+	Username string `ini:"username"`
+	Password string `ini:"password"`
+	APIKey   string `ini:"api_key"`
+}
+
+func (c *Config) loadElasticSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("elasticsearch")
+	if err != nil {
+		return nil
+	}
+
+	es := new(ElasticSearch)
+	if err := sec.MapTo(es); err != nil {
+		return fmt.Errorf("error mapping the elasticsearch settings: %v", err)
+	}
+	if es.URL == "" {
+		return nil
+	}
+
+	c.ElasticSearch = es
+	return nil
+}
+
+func (es *ElasticSearch) check() error {
+	if u, err := url.Parse(es.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s is not a valid Elasticsearch URL", es.URL)
+	}
+	if es.Index == "" {
+		es.Index = DefaultElasticIndex
+	}
+	return nil
+}
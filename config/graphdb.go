@@ -4,6 +4,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/go-ini/ini"
@@ -26,6 +28,15 @@ func (c *Config) loadDatabaseSettings(cfg *ini.File) error {
 		return nil
 	}
 
+	c.GraphDBType = strings.ToLower(strings.TrimSpace(sec.Key("type").String()))
+	c.GraphDBDSN = strings.TrimSpace(sec.Key("dsn").String())
+
+	for _, dsn := range sec.Key("endpoint").ValueWithShadows() {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			c.GraphDBEndpoints = append(c.GraphDBEndpoints, dsn)
+		}
+	}
+
 	if sec.HasKey("local_database") {
 		if localdb, err := sec.Key("local_database").Bool(); err == nil {
 			c.LocalDatabase = localdb
@@ -68,3 +79,65 @@ func (c *Config) LocalDatabaseSettings(dbs []*Database) *Database {
 
 	return bolt
 }
+
+// SelectedDatabase returns the Database for the backend selected by the GraphDBType, which is used
+// instead of the local database and the GraphDBs. Nil is returned when a backend was not selected.
+// The local backend stores the graph in the output directory when the DSN is not provided, and the
+// first of the GraphDBEndpoints is used for the other backends.
+func (c *Config) SelectedDatabase(dir string) *Database {
+	if c.GraphDBType == "" {
+		return nil
+	}
+
+	db := &Database{
+		System:  c.GraphDBType,
+		Primary: true,
+		URL:     c.GraphDBDSN,
+	}
+	if db.URL == "" && len(c.GraphDBEndpoints) > 0 {
+		db.URL = c.GraphDBEndpoints[0]
+	}
+	if db.System == "local" && db.URL == "" {
+		db.URL = OutputDirectory(dir)
+	}
+	return db
+}
+
+// SelectedDatabases returns the Database of each endpoint of the backend selected by the GraphDBType,
+// starting with the SelectedDatabase and followed by the other GraphDBEndpoints in order.
+func (c *Config) SelectedDatabases(dir string) []*Database {
+	db := c.SelectedDatabase(dir)
+	if db == nil {
+		return nil
+	}
+
+	dbs := []*Database{db}
+	for _, dsn := range c.GraphDBEndpoints {
+		if dsn != db.URL {
+			dbs = append(dbs, &Database{
+				System: db.System,
+				URL:    dsn,
+			})
+		}
+	}
+	return dbs
+}
+
+func (c *Config) checkGraphDBType() error {
+	switch c.GraphDBType {
+	case "", "memory", "local", "sqlite":
+		if c.GraphDBType == "sqlite" && c.GraphDBDSN == "" {
+			return errors.New("the sqlite graph database requires the path of the database file as the DSN")
+		}
+		if len(c.GraphDBEndpoints) > 0 {
+			return errors.New("the graph database endpoints require the postgres or mysql type")
+		}
+	case "postgres", "mysql":
+		if c.GraphDBDSN == "" && len(c.GraphDBEndpoints) == 0 {
+			return fmt.Errorf("the %s graph database requires a DSN", c.GraphDBType)
+		}
+	default:
+		return fmt.Errorf("%s is not a supported graph database type", c.GraphDBType)
+	}
+	return nil
+}
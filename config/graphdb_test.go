@@ -82,3 +82,81 @@ func TestLocalDatabaseSettings(t *testing.T) {
 		t.Errorf("LocalDatabaseSettings failed")
 	}
 }
+
+func TestSelectedDatabase(t *testing.T) {
+	c := NewConfig()
+	if db := c.SelectedDatabase(""); db != nil {
+		t.Errorf("A database was selected without the graph database type")
+	}
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{},
+		[]byte(`
+		[graphdbs]
+		type = Postgres
+		dsn = postgres://amass@db.example.com:5432/amass?sslmode=disable
+		`),
+	)
+	if err := c.loadDatabaseSettings(cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	db := c.SelectedDatabase("")
+	if db == nil || db.System != "postgres" || !db.Primary || db.URL != c.GraphDBDSN {
+		t.Errorf("The postgres database was not selected: %v", db)
+	}
+
+	c.GraphDBType = "local"
+	c.GraphDBDSN = ""
+	if db := c.SelectedDatabase("/tmp/amass"); db == nil || db.URL != "/tmp/amass" {
+		t.Errorf("The local database was not stored in the output directory: %v", db)
+	}
+}
+
+func TestSelectedDatabases(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[graphdbs]
+		type = postgres
+		dsn = postgres://amass@db1.example.com:5432/amass
+		endpoint = postgres://amass@db2.example.com:5432/amass
+		endpoint = postgres://amass@db1.example.com:5432/amass
+		endpoint = postgres://amass@db3.example.com:5432/amass
+		`),
+	)
+	if err := c.loadDatabaseSettings(cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(c.GraphDBEndpoints) != 3 {
+		t.Fatalf("Loaded %d graph database endpoints, expected 3", len(c.GraphDBEndpoints))
+	}
+
+	dbs := c.SelectedDatabases("")
+	expected := []string{
+		"postgres://amass@db1.example.com:5432/amass",
+		"postgres://amass@db2.example.com:5432/amass",
+		"postgres://amass@db3.example.com:5432/amass",
+	}
+	if len(dbs) != len(expected) {
+		t.Fatalf("Selected %d graph database endpoints, expected %d", len(dbs), len(expected))
+	}
+	for i, db := range dbs {
+		if db.System != "postgres" || db.URL != expected[i] || db.Primary != (i == 0) {
+			t.Errorf("Endpoint %d was %v, expected %s", i, *db, expected[i])
+		}
+	}
+
+	c.GraphDBDSN = ""
+	if err := c.checkGraphDBType(); err != nil {
+		t.Errorf("The endpoints were not accepted without the DSN: %v", err)
+	}
+	if db := c.SelectedDatabase(""); db == nil || db.URL != expected[1] {
+		t.Errorf("The first endpoint was not selected without the DSN: %v", db)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// Logger receives the leveled messages of the enumeration, the resolvers and the data sources,
+// so they can be routed into a structured logging library, such as zap or logrus.
+type Logger interface {
+	Debug(msg string, fields ...LogField)
+	Info(msg string, fields ...LogField)
+	Warn(msg string, fields ...LogField)
+	Error(msg string, fields ...LogField)
+}
+
+// LogField is a key and value attached to a log message.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// SourceField returns the field identifying the data source or subsystem that logged the message.
+func SourceField(name string) LogField {
+	return LogField{Key: "source", Value: name}
+}
+
+// ZoneField returns the field identifying the DNS zone that the message is about.
+func ZoneField(zone string) LogField {
+	return LogField{Key: "zone", Value: zone}
+}
+
+// Leveled returns the Logger receiving the leveled messages. When the Logger field is not
+// set, the messages are written to the Log field without the level and fields, as before.
+func (c *Config) Leveled() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return NewStdLogger(c.Log)
+}
+
+// NewStdLogger returns a Logger that writes the messages to the standard library Logger, without
+// the level and fields. The messages are discarded when the standard library Logger is nil.
+func NewStdLogger(l *log.Logger) Logger {
+	return &stdLogger{log: l}
+}
+
+type stdLogger struct {
+	log *log.Logger
+}
+
+func (s *stdLogger) Debug(msg string, fields ...LogField) { s.print(msg) }
+func (s *stdLogger) Info(msg string, fields ...LogField)  { s.print(msg) }
+func (s *stdLogger) Warn(msg string, fields ...LogField)  { s.print(msg) }
+func (s *stdLogger) Error(msg string, fields ...LogField) { s.print(msg) }
+
+func (s *stdLogger) print(msg string) {
+	if s.log != nil {
+		s.log.Print(msg)
+	}
+}
+
+// NewLoggerWriter returns a Writer that sends each line written to the Logger at the info level,
+// with the fields provided. It allows the Log field, which is used by the resolvers, to be routed
+// into the Logger using log.New(config.NewLoggerWriter(l, config.SourceField("resolvers")), "", 0).
+func NewLoggerWriter(l Logger, fields ...LogField) io.Writer {
+	return &loggerWriter{
+		logger: l,
+		fields: fields,
+	}
+}
+
+type loggerWriter struct {
+	logger Logger
+	fields []LogField
+}
+
+func (w *loggerWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.logger.Info(line, w.fields...)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+type testLogger struct {
+	levels []string
+	msgs   []string
+	fields [][]LogField
+}
+
+func (t *testLogger) Debug(msg string, fields ...LogField) { t.add("debug", msg, fields) }
+func (t *testLogger) Info(msg string, fields ...LogField)  { t.add("info", msg, fields) }
+func (t *testLogger) Warn(msg string, fields ...LogField)  { t.add("warn", msg, fields) }
+func (t *testLogger) Error(msg string, fields ...LogField) { t.add("error", msg, fields) }
+
+func (t *testLogger) add(level, msg string, fields []LogField) {
+	t.levels = append(t.levels, level)
+	t.msgs = append(t.msgs, msg)
+	t.fields = append(t.fields, fields)
+}
+
+func TestLeveledWritesToLog(t *testing.T) {
+	var buf bytes.Buffer
+
+	c := NewConfig()
+	c.Log = log.New(&buf, "", 0)
+	c.Leveled().Warn("AlienVault: API key data was not provided", SourceField("AlienVault"))
+
+	if got := buf.String(); got != "AlienVault: API key data was not provided\n" {
+		t.Errorf("the message was written to the Log as %q", got)
+	}
+
+	// The messages are discarded without a Log
+	c.Log = nil
+	c.Leveled().Error("Script: Failed to load script")
+}
+
+func TestLeveledUsesLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := new(testLogger)
+
+	c := NewConfig()
+	c.Log = log.New(&buf, "", 0)
+	c.Logger = l
+	c.Leveled().Info("DNS wildcard detected", SourceField("Resolvers"), ZoneField("owasp.org"))
+
+	if buf.Len() != 0 {
+		t.Errorf("the message was also written to the Log: %q", buf.String())
+	}
+	if len(l.msgs) != 1 || l.levels[0] != "info" || len(l.fields[0]) != 2 {
+		t.Fatalf("the Logger did not receive the message: %+v", l)
+	}
+	if f := l.fields[0][1]; f.Key != "zone" || f.Value != "owasp.org" {
+		t.Errorf("the zone field was received as %+v", f)
+	}
+}
+
+func TestNewLoggerWriter(t *testing.T) {
+	l := new(testLogger)
+	logger := log.New(NewLoggerWriter(l, SourceField("Resolvers")), "", 0)
+
+	logger.Print("first")
+	logger.Print("second\n\nthird")
+
+	if got := strings.Join(l.msgs, ","); got != "first,second,third" {
+		t.Errorf("the Logger received the lines %s", got)
+	}
+	for i, fields := range l.fields {
+		if l.levels[i] != "info" || len(fields) != 1 || fields[0].Value != "Resolvers" {
+			t.Errorf("line %d was received with the level %s and fields %+v", i, l.levels[i], fields)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffix/stringset"
+	"github.com/go-ini/ini"
+)
+
+// OutputFormats are the formats accepted by the output sinks.
+var OutputFormats = []string{"text", "json", "d3", "dot", "gexf", "graphml", "graphistry", "maltego", "bloodhound"}
+
+// OutputSink identifies a file that receives the results of the enumeration in a specific format.
+type OutputSink struct {
+	Format string
+	Path   string
+	// The results are compressed with gzip, which is set for the paths ending in the .gz extension
+	Compress bool
+}
+
+// ParseOutputSink returns the OutputSink for a value in the FORMAT:PATH form.
+func ParseOutputSink(value string) (*OutputSink, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("%s is not an output sink in the FORMAT:PATH form", value)
+	}
+
+	sink := &OutputSink{
+		Format: strings.ToLower(strings.TrimSpace(parts[0])),
+		Path:   strings.TrimSpace(parts[1]),
+	}
+	sink.Compress = strings.HasSuffix(strings.ToLower(sink.Path), ".gz")
+	if err := sink.check(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (c *Config) loadOutputSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("output")
+	if err != nil {
+		return nil
+	}
+
+	for _, value := range sec.Key("sink").ValueWithShadows() {
+		if value == "" {
+			continue
+		}
+
+		sink, err := ParseOutputSink(value)
+		if err != nil {
+			return err
+		}
+		c.Outputs = append(c.Outputs, sink)
+	}
+
+	if sec.HasKey("unresolved") {
+		c.OutputUnresolved = strings.TrimSpace(sec.Key("unresolved").String())
+	}
+
+	if sec.HasKey("compress") {
+		c.CompressOutputs = sec.Key("compress").MustBool(false)
+	}
+
+	if sec.HasKey("max_label_depth") {
+		c.MaxLabelDepth = sec.Key("max_label_depth").MustInt(0)
+	}
+
+	if sec.HasKey("redact") {
+		c.RedactPatterns = stringset.Deduplicate(sec.Key("redact").ValueWithShadows())
+	}
+	return nil
+}
+
+// WithinLabelDepth returns true when the name has no more than MaxLabelDepth labels beneath its root
+// domain name, such as one for the direct subdomains. The names that are not in scope are always
+// within the depth, as are all the names when MaxLabelDepth is zero.
+func (c *Config) WithinLabelDepth(name string) bool {
+	if c.MaxLabelDepth <= 0 {
+		return true
+	}
+
+	n := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	domain := c.WhichDomain(n)
+	if domain == "" {
+		return true
+	}
+
+	sub := strings.TrimSuffix(strings.TrimSuffix(n, domain), ".")
+	if sub == "" {
+		return true
+	}
+	return len(strings.Split(sub, ".")) <= c.MaxLabelDepth
+}
+
+func (s *OutputSink) check() error {
+	for _, f := range OutputFormats {
+		if s.Format == f {
+			if s.Path == "" {
+				return fmt.Errorf("the %s output sink requires a path", s.Format)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a supported output format", s.Format)
+}
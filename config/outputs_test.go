@@ -0,0 +1,87 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadOutputSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[output]
+		sink = json:/tmp/amass.json
+		sink = TEXT:/tmp/amass.txt
+		sink = graphml:C:\amass\amass.graphml
+		sink = json:/tmp/amass.json.GZ
+		compress = true
+		max_label_depth = 1
+		`),
+	)
+	if err := c.loadOutputSettings(cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	expected := []OutputSink{
+		{Format: "json", Path: "/tmp/amass.json"},
+		{Format: "text", Path: "/tmp/amass.txt"},
+		{Format: "graphml", Path: `C:\amass\amass.graphml`},
+		{Format: "json", Path: "/tmp/amass.json.GZ", Compress: true},
+	}
+	if len(c.Outputs) != len(expected) {
+		t.Fatalf("Loaded %d output sinks, expected %d", len(c.Outputs), len(expected))
+	}
+	for i, sink := range c.Outputs {
+		if *sink != expected[i] {
+			t.Errorf("Output sink %d was %v, expected %v", i, *sink, expected[i])
+		}
+	}
+	if !c.CompressOutputs {
+		t.Error("The compress setting was not loaded")
+	}
+	if c.MaxLabelDepth != 1 {
+		t.Error("The max_label_depth setting was not loaded")
+	}
+}
+
+func TestWithinLabelDepth(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("owasp.org")
+
+	for name, want := range map[string]bool{
+		"owasp.org":           true,
+		"www.owasp.org":       true,
+		"WWW.OWASP.org.":      true,
+		"dev.www.owasp.org":   false,
+		"a.dev.www.owasp.org": false,
+		"dev.www.example.com": true,
+		"192.168.1.1":         true,
+	} {
+		if !c.WithinLabelDepth(name) {
+			t.Errorf("%s was filtered without a max label depth", name)
+		}
+
+		c.MaxLabelDepth = 1
+		if got := c.WithinLabelDepth(name); got != want {
+			t.Errorf("WithinLabelDepth(%s) returned %t, expected %t", name, got, want)
+		}
+		c.MaxLabelDepth = 0
+	}
+}
+
+func TestParseOutputSink(t *testing.T) {
+	for _, value := range []string{"json", "json:", "xml:/tmp/amass.xml", ":/tmp/amass.txt"} {
+		if _, err := ParseOutputSink(value); err == nil {
+			t.Errorf("%s was accepted as an output sink", value)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"context"
+	"net/http"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+)
+
+// ProxyDialer returns the proxy of the configuration, which is nil when the Proxy setting is empty.
+// The proxy is parsed again once the setting has been changed.
+func (c *Config) ProxyDialer() (*amassnet.Proxy, error) {
+	c.proxyLock.Lock()
+	defer c.proxyLock.Unlock()
+
+	if c.proxyParsed == c.Proxy {
+		return c.proxy, nil
+	}
+
+	p, err := amassnet.NewProxy(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	c.proxy = p
+	c.proxyParsed = c.Proxy
+	return p, nil
+}
+
+// ProxyContext returns a copy of the context carrying the proxy of the configuration, which is used
+// by the TCP connections and HTTP requests made with the context. The proxy settings of other
+// configurations in the process are not affected.
+func (c *Config) ProxyContext(ctx context.Context) context.Context {
+	p, err := c.ProxyDialer()
+	if err != nil {
+		c.Log.Printf("The proxy %s could not be used: %v", c.Proxy, err)
+		return ctx
+	}
+	return amassnet.WithProxy(ctx, p)
+}
+
+// ProxyClient returns the HTTP client sending the requests through the proxy of the configuration,
+// for the client libraries that do not send the requests with the contexts carrying the proxy.
+func (c *Config) ProxyClient() *http.Client {
+	p, err := c.ProxyDialer()
+	if err != nil {
+		c.Log.Printf("The proxy %s could not be used: %v", c.Proxy, err)
+	}
+	return amasshttp.ProxyClient(p)
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+)
+
+func TestProxyContext(t *testing.T) {
+	c1 := NewConfig()
+	c1.Proxy = "http://127.0.0.1:3128"
+	c2 := NewConfig()
+	c2.Proxy = "socks5://127.0.0.1:1080"
+	direct := NewConfig()
+
+	// The configurations in the same process keep their own proxies
+	if p := amassnet.ContextProxy(c1.ProxyContext(context.Background())); p == nil || p.URL().Host != "127.0.0.1:3128" {
+		t.Errorf("The context of the first configuration carried the proxy %v", p)
+	}
+	if p := amassnet.ContextProxy(c2.ProxyContext(context.Background())); p == nil || p.URL().Scheme != "socks5" {
+		t.Errorf("The context of the second configuration carried the proxy %v", p)
+	}
+	if p := amassnet.ContextProxy(direct.ProxyContext(context.Background())); p != nil {
+		t.Errorf("The context of the configuration without a proxy carried the proxy %v", p)
+	}
+
+	// The proxy is parsed again once the setting has been changed
+	c1.Proxy = "http://127.0.0.1:8080"
+	if p, err := c1.ProxyDialer(); err != nil || p == nil || p.URL().Host != "127.0.0.1:8080" {
+		t.Errorf("ProxyDialer returned %v, %v after the setting was changed", p, err)
+	}
+
+	c1.Proxy = "ftp://127.0.0.1:21"
+	if _, err := c1.ProxyDialer(); err == nil {
+		t.Error("ProxyDialer did not fail for the unsupported proxy")
+	}
+}
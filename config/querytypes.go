@@ -0,0 +1,52 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// The DNS record types that can be queried for each discovered name
+var queryTypes = map[string]uint16{
+	"A":     dns.TypeA,
+	"AAAA":  dns.TypeAAAA,
+	"CNAME": dns.TypeCNAME,
+	"MX":    dns.TypeMX,
+	"NS":    dns.TypeNS,
+	"SOA":   dns.TypeSOA,
+	"SPF":   dns.TypeSPF,
+	"SRV":   dns.TypeSRV,
+	"TXT":   dns.TypeTXT,
+}
+
+// QueryRecordTypes returns the DNS record types of the QueryTypes in the order provided,
+// without the duplicates. Nil is returned when the QueryTypes are not set.
+func (c *Config) QueryRecordTypes() []uint16 {
+	var types []uint16
+
+	seen := make(map[uint16]struct{})
+	for _, qt := range c.QueryTypes {
+		t, found := queryTypes[strings.ToUpper(strings.TrimSpace(qt))]
+		if !found {
+			continue
+		}
+		if _, dup := seen[t]; !dup {
+			seen[t] = struct{}{}
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func (c *Config) checkQueryTypes() error {
+	for _, qt := range c.QueryTypes {
+		if _, found := queryTypes[strings.ToUpper(strings.TrimSpace(qt))]; !found {
+			return fmt.Errorf("%s is not a DNS record type that can be queried for each name", qt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// maxRateLimitWait is the longest period a data source waits before checking its limit again,
+// so that limits adjusted during the enumeration go into effect promptly.
+const maxRateLimitWait = time.Second
+
+// SetSourceRateLimit assigns the number of requests per minute permitted for the named data source.
+// A value of zero or less removes the limit, and the change is honored by a running enumeration.
+func (c *Config) SetSourceRateLimit(source string, perMinute int) {
+	c.srcRateLock.Lock()
+	defer c.srcRateLock.Unlock()
+
+	key := strings.ToLower(strings.TrimSpace(source))
+	if key == "" {
+		return
+	}
+
+	if c.SourceRateLimits == nil {
+		c.SourceRateLimits = make(map[string]int)
+	}
+
+	if perMinute <= 0 {
+		delete(c.SourceRateLimits, key)
+		return
+	}
+	c.SourceRateLimits[key] = perMinute
+}
+
+// SourceRateLimit returns the number of requests per minute permitted for the named data source,
+// or zero when the data source has no limit.
+func (c *Config) SourceRateLimit(source string) int {
+	c.srcRateLock.Lock()
+	defer c.srcRateLock.Unlock()
+
+	return c.sourceRateLimit(strings.ToLower(strings.TrimSpace(source)))
+}
+
+// The caller must hold the rate limit lock.
+func (c *Config) sourceRateLimit(key string) int {
+	if limit, found := c.SourceRateLimits[key]; found {
+		return limit
+	}
+	// Keys assigned directly to the exported map may not be lowercase
+	for name, limit := range c.SourceRateLimits {
+		if strings.EqualFold(name, key) {
+			return limit
+		}
+	}
+	return 0
+}
+
+// CheckSourceRateLimit blocks until the named data source is permitted to send another
+// request according to its per minute rate limit, or the context expires.
+func (c *Config) CheckSourceRateLimit(ctx context.Context, source string) {
+	key := strings.ToLower(strings.TrimSpace(source))
+
+	for {
+		c.srcRateLock.Lock()
+		limit := c.sourceRateLimit(key)
+		if limit <= 0 {
+			c.srcRateLock.Unlock()
+			return
+		}
+
+		if c.srcLastRequest == nil {
+			c.srcLastRequest = make(map[string]time.Time)
+		}
+
+		now := time.Now()
+		next := c.srcLastRequest[key].Add(time.Minute / time.Duration(limit))
+		if !now.Before(next) {
+			c.srcLastRequest[key] = now
+			c.srcRateLock.Unlock()
+			return
+		}
+		c.srcRateLock.Unlock()
+
+		wait := next.Sub(now)
+		if wait > maxRateLimitWait {
+			wait = maxRateLimitWait
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetSourceRateLimit(t *testing.T) {
+	c := NewConfig()
+
+	c.SetSourceRateLimit("DNSDB", 30)
+	if limit := c.SourceRateLimit("dnsdb"); limit != 30 {
+		t.Errorf("Expected a rate limit of 30, but got %d", limit)
+	}
+
+	c.SetSourceRateLimit("DNSDB", 0)
+	if limit := c.SourceRateLimit("DNSDB"); limit != 0 {
+		t.Errorf("Expected the rate limit to be removed, but got %d", limit)
+	}
+}
+
+func TestCheckSourceRateLimit(t *testing.T) {
+	c := NewConfig()
+	// Permit one request every 100 milliseconds
+	c.SetSourceRateLimit("DNSDB", 600)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		c.CheckSourceRateLimit(context.Background(), "DNSDB")
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("The rate limit was not honored, since three requests took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.SetSourceRateLimit("DNSDB", 1)
+	start = time.Now()
+	c.CheckSourceRateLimit(ctx, "DNSDB")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("The expired context did not release the rate limit check")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"strings"
+
+	"github.com/OWASP/Amass/v3/net/dns"
+)
+
+// RedactPlaceholder replaces the labels of names matching the RedactPatterns.
+const RedactPlaceholder = "redacted"
+
+// Redacting returns true when the configuration has patterns for the labels to be redacted.
+func (c *Config) Redacting() bool {
+	return len(c.RedactPatterns) > 0
+}
+
+// RedactName returns the name with each label matching one of the RedactPatterns replaced by the
+// RedactPlaceholder. Only the labels beneath the root domain name are redacted, and names that
+// are not in scope, such as IP addresses, are returned unchanged. Redacted names are returned
+// in the punycode form.
+func (c *Config) RedactName(name string) string {
+	if !c.Redacting() {
+		return name
+	}
+
+	domain := c.WhichDomain(name)
+	if domain == "" {
+		return name
+	}
+
+	c.redactLock.Lock()
+	defer c.redactLock.Unlock()
+
+	if len(c.redactRegexps) != len(c.RedactPatterns) {
+		c.redactRegexps, _ = compileWildcards("redact", c.RedactPatterns)
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	if a, err := dns.ToASCII(n); err == nil {
+		n = a
+	}
+	sub := strings.TrimSuffix(strings.TrimSuffix(n, domain), ".")
+	if sub == "" || sub == n {
+		return name
+	}
+
+	var changed bool
+	labels := strings.Split(sub, ".")
+	for i, label := range labels {
+		for _, re := range c.redactRegexps {
+			if re.MatchString(label) {
+				labels[i] = RedactPlaceholder
+				changed = true
+				break
+			}
+		}
+	}
+	if !changed {
+		return name
+	}
+	return strings.Join(labels, ".") + "." + domain
+}
+
+func (c *Config) checkRedactions() error {
+	c.redactLock.Lock()
+	defer c.redactLock.Unlock()
+
+	res, err := compileWildcards("redact", c.RedactPatterns)
+	if err != nil {
+		return err
+	}
+
+	c.redactRegexps = res
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestConfigRedactName(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("owasp.org")
+	c.RedactPatterns = []string{"*-int", "corp"}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"www.owasp.org", "www.owasp.org"},
+		{"db-int.owasp.org", "redacted.owasp.org"},
+		{"host.CORP.owasp.org", "host.redacted.owasp.org"},
+		{"app-int.corp.owasp.org", "redacted.redacted.owasp.org"},
+		{"owasp.org", "owasp.org"},
+		{"db-int.example.com", "db-int.example.com"},
+		{"192.168.1.1", "192.168.1.1"},
+	}
+	for _, tt := range tests {
+		if got := c.RedactName(tt.name); got != tt.want {
+			t.Errorf("RedactName(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRedactSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[output]
+		redact = *-int
+		redact = corp
+		`),
+	)
+	if err := c.loadOutputSettings(cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(c.RedactPatterns) != 2 {
+		t.Errorf("The redact patterns were loaded as %v", c.RedactPatterns)
+	}
+	if err := c.CheckSettings(); err != nil {
+		t.Errorf("The redact settings were rejected: %v", err)
+	}
+
+	c.RedactPatterns = append(c.RedactPatterns, " ")
+	if err := c.CheckSettings(); err == nil {
+		t.Error("The empty redact pattern was accepted")
+	}
+}
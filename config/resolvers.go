@@ -9,12 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"strconv"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
+	"github.com/miekg/dns"
 )
 
 // DefaultQueriesPerPublicResolver is the number of queries sent to each public DNS resolver per second.
@@ -102,13 +104,95 @@ func (c *Config) loadResolverSettings(cfg *ini.File) error {
 	}
 
 	c.Resolvers = stringset.Deduplicate(sec.Key("resolver").ValueWithShadows())
-	if len(c.Resolvers) == 0 {
+	if sec.HasKey("doh_resolver") {
+		c.DoHResolvers = stringset.Deduplicate(sec.Key("doh_resolver").ValueWithShadows())
+	}
+	if sec.HasKey("exclude_types") {
+		for _, line := range sec.Key("exclude_types").ValueWithShadows() {
+			// The resolver is followed by the record types, separated by spaces or commas
+			fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+			if len(fields) < 2 {
+				return fmt.Errorf("the resolvers exclude_types setting %q must provide a resolver and the record types", line)
+			}
+			c.AddResolverTypeExclusions(fields[0], fields[1:]...)
+		}
+	}
+	if len(c.Resolvers) == 0 && len(c.DoHResolvers) == 0 {
 		return errors.New("no resolver keys were found in the resolvers section")
 	}
 
 	return nil
 }
 
+// AddResolverTypeExclusions prevents the DNS record types provided from being queried using the resolver.
+func (c *Config) AddResolverTypeExclusions(resolver string, types ...string) {
+	c.Lock()
+	defer c.Unlock()
+
+	r := strings.TrimSpace(resolver)
+	if r == "" {
+		return
+	}
+	if c.ResolverTypeExclusions == nil {
+		c.ResolverTypeExclusions = make(map[string][]string)
+	}
+
+loop:
+	for _, t := range types {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t == "" {
+			continue
+		}
+		for _, excluded := range c.ResolverTypeExclusions[r] {
+			if excluded == t {
+				continue loop
+			}
+		}
+		c.ResolverTypeExclusions[r] = append(c.ResolverTypeExclusions[r], t)
+	}
+}
+
+// ResolverExcludedTypes returns the DNS record types that are not queried using the resolver, which is
+// matched by the address, with port 53 assumed when not provided, or by the DNS-over-HTTPS resolver URL.
+func (c *Config) ResolverExcludedTypes(resolver string) []uint16 {
+	key := resolverKey(resolver)
+
+	var types []uint16
+	for r, excluded := range c.ResolverTypeExclusions {
+		if resolverKey(r) != key {
+			continue
+		}
+		for _, t := range excluded {
+			if qtype, found := dns.StringToType[strings.ToUpper(strings.TrimSpace(t))]; found {
+				types = append(types, qtype)
+			}
+		}
+	}
+	return types
+}
+
+func (c *Config) checkResolverTypeExclusions() error {
+	for r, types := range c.ResolverTypeExclusions {
+		for _, t := range types {
+			if _, found := dns.StringToType[strings.ToUpper(strings.TrimSpace(t))]; !found {
+				return fmt.Errorf("%s is not a DNS record type that can be excluded for the resolver %s", t, r)
+			}
+		}
+	}
+	return nil
+}
+
+func resolverKey(resolver string) string {
+	r := strings.ToLower(strings.TrimSpace(resolver))
+
+	if strings.HasPrefix(r, "https://") {
+		return r
+	}
+	if _, _, err := net.SplitHostPort(r); err != nil {
+		return net.JoinHostPort(strings.Trim(r, "[]"), "53")
+	}
+	return r
+}
+
 func (c *Config) calcDNSQueriesMax() {
 	c.MaxDNSQueries = len(c.Resolvers) * DefaultQueriesPerPublicResolver
 }
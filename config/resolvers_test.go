@@ -7,6 +7,9 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/go-ini/ini"
+	"github.com/miekg/dns"
 )
 
 func TestConfigSetResolvers(t *testing.T) {
@@ -42,3 +45,35 @@ func TestConfigSetResolvers(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigResolverTypeExclusions(t *testing.T) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{Insensitive: true, AllowShadows: true}, []byte(`
+[resolvers]
+resolver = 192.168.1.1
+exclude_types = 192.168.1.1 TXT,SRV
+exclude_types = https://dns.example.com/dns-query txt
+`))
+	if err != nil {
+		t.Fatalf("the settings failed to parse: %v", err)
+	}
+
+	c := NewConfig()
+	if err := c.loadResolverSettings(cfg); err != nil {
+		t.Fatalf("the settings failed to load: %v", err)
+	}
+
+	if got := c.ResolverExcludedTypes("192.168.1.1:53"); !reflect.DeepEqual(got, []uint16{dns.TypeTXT, dns.TypeSRV}) {
+		t.Errorf("the types excluded for the resolver were %v", got)
+	}
+	if got := c.ResolverExcludedTypes("https://DNS.example.com/dns-query"); !reflect.DeepEqual(got, []uint16{dns.TypeTXT}) {
+		t.Errorf("the types excluded for the DoH resolver were %v", got)
+	}
+	if got := c.ResolverExcludedTypes("192.168.1.2"); len(got) != 0 {
+		t.Errorf("types were excluded for another resolver: %v", got)
+	}
+
+	c.AddResolverTypeExclusions("192.168.1.3", "NOTATYPE")
+	if err := c.checkResolverTypeExclusions(); err == nil {
+		t.Error("an unknown record type was accepted")
+	}
+}
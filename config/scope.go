@@ -39,9 +39,9 @@ func (c *Config) AddDomain(domain string) {
 	c.Lock()
 	defer c.Unlock()
 
-	// Check that the domain string is not empty
-	d := strings.TrimSpace(domain)
-	if d == "" {
+	// Check that the domain string is not empty, and use the punycode form of internationalized names
+	d, err := dns.ToASCII(domain)
+	if err != nil || d == "" {
 		return
 	}
 	// Check that it is a domain with at least two labels
@@ -94,6 +94,10 @@ func (c *Config) IsDomainInScope(name string) bool {
 // WhichDomain returns the domain in the config list that the DNS name in the parameter ends with.
 func (c *Config) WhichDomain(name string) string {
 	n := strings.ToLower(strings.TrimSpace(name))
+	// Internationalized names match the domains in either the Unicode or punycode form
+	if a, err := dns.ToASCII(n); err == nil {
+		n = a
+	}
 
 	for _, d := range c.Domains() {
 		if hasPathSuffix(n, d) {
@@ -124,6 +128,9 @@ func (c *Config) IsAddressInScope(addr string) bool {
 		return false
 	}
 
+	c.Lock()
+	defer c.Unlock()
+
 	if len(c.Addresses) == 0 && len(c.CIDRs) == 0 {
 		return true
 	}
@@ -142,6 +149,47 @@ func (c *Config) IsAddressInScope(addr string) bool {
 	return false
 }
 
+// IsASNExpandable returns true when ExpandByASN is enabled and the asn parameter has been allowlisted.
+func (c *Config) IsASNExpandable(asn int) bool {
+	if !c.ExpandByASN || asn <= 0 {
+		return false
+	}
+
+	for _, a := range c.ExpandASNs {
+		if a == asn {
+			return true
+		}
+	}
+	return false
+}
+
+// AddScopeCIDR adds the netblock to the CIDRs in scope, and returns true when it was not already in scope.
+func (c *Config) AddScopeCIDR(cidr *net.IPNet) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if containsCIDR(c.CIDRs, cidr) {
+		return false
+	}
+
+	c.CIDRs = append(c.CIDRs, cidr)
+	return true
+}
+
+// IsAddressFamilyAllowed returns true if the IP address belongs to an address
+// family permitted by the IPv4Only and IPv6Only settings.
+func (c *Config) IsAddressFamilyAllowed(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	if ipv4 := ip.To4() != nil; (c.IPv4Only && !ipv4) || (c.IPv6Only && ipv4) {
+		return false
+	}
+	return true
+}
+
 // BlacklistSubdomain adds a subdomain name to the config blacklist.
 func (c *Config) BlacklistSubdomain(name string) {
 	c.blacklistLock.Lock()
@@ -170,6 +218,85 @@ func (c *Config) Blacklisted(name string) bool {
 	return false
 }
 
+// IsExcluded returns true if the name in the parameter matches one of the ExcludePatterns.
+func (c *Config) IsExcluded(name string) bool {
+	c.excludeLock.Lock()
+	defer c.excludeLock.Unlock()
+
+	if len(c.excludeRegexps) != len(c.ExcludePatterns) {
+		c.excludeRegexps, _ = compilePatterns("exclude", c.ExcludePatterns)
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	for _, re := range c.excludeRegexps {
+		if re.MatchString(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) compileExcludePatterns() error {
+	c.excludeLock.Lock()
+	defer c.excludeLock.Unlock()
+
+	res, err := compilePatterns("exclude", c.ExcludePatterns)
+	if err != nil {
+		return err
+	}
+
+	c.excludeRegexps = res
+	return nil
+}
+
+// MatchesScopeRegex returns true if the name in the parameter matches one of the ScopeRegex
+// expressions, or when no expressions have been provided.
+func (c *Config) MatchesScopeRegex(name string) bool {
+	c.scopeRegexLock.Lock()
+	defer c.scopeRegexLock.Unlock()
+
+	if len(c.ScopeRegex) == 0 {
+		return true
+	}
+	if len(c.scopeRegexps) != len(c.ScopeRegex) {
+		c.scopeRegexps, _ = compilePatterns("scope", c.ScopeRegex)
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	for _, re := range c.scopeRegexps {
+		if re.MatchString(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) compileScopeRegex() error {
+	c.scopeRegexLock.Lock()
+	defer c.scopeRegexLock.Unlock()
+
+	res, err := compilePatterns("scope", c.ScopeRegex)
+	if err != nil {
+		return err
+	}
+
+	c.scopeRegexps = res
+	return nil
+}
+
+func compilePatterns(kind string, patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("the %s pattern %s is not a valid regular expression: %v", kind, p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
 func (c *Config) loadScopeSettings(cfg *ini.File) error {
 	scope, err := cfg.GetSection("scope")
 	if err != nil {
@@ -204,6 +331,37 @@ func (c *Config) loadScopeSettings(cfg *ini.File) error {
 		}
 	}
 
+	if scope.HasKey("url") {
+		c.ScopeURL = scope.Key("url").String()
+	}
+	if scope.HasKey("token") {
+		c.ScopeToken = scope.Key("token").String()
+	}
+	if scope.HasKey("refresh") {
+		refresh, err := scope.Key("refresh").Duration()
+		if err != nil {
+			return err
+		}
+		c.ScopeRefresh = refresh
+	}
+
+	if scope.HasKey("expand_by_asn") {
+		expand, err := scope.Key("expand_by_asn").Bool()
+		if err != nil {
+			return err
+		}
+		c.ExpandByASN = expand
+	}
+	if scope.HasKey("expand_asn") {
+		for _, asn := range scope.Key("expand_asn").ValueWithShadows() {
+			c.ExpandASNs = uniqueIntAppend(c.ExpandASNs, asn)
+		}
+	}
+
+	if scope.HasKey("cert_org") {
+		c.CertOrgs = stringset.Deduplicate(scope.Key("cert_org").ValueWithShadows())
+	}
+
 	if scope.HasKey("port") {
 		for _, port := range scope.Key("port").ValueWithShadows() {
 			c.Ports = uniqueIntAppend(c.Ports, port)
@@ -222,6 +380,16 @@ func (c *Config) loadScopeSettings(cfg *ini.File) error {
 		c.Blacklist = stringset.Deduplicate(blacklisted.Key("subdomain").ValueWithShadows())
 	}
 
+	// Load up the regular expressions for names that will be dropped
+	if excluded, err := cfg.GetSection("scope.excluded"); err == nil {
+		c.ExcludePatterns = stringset.Deduplicate(excluded.Key("pattern").ValueWithShadows())
+	}
+
+	// Load up the regular expressions that the names within the root domains must match
+	if regex, err := cfg.GetSection("scope.regex"); err == nil {
+		c.ScopeRegex = stringset.Deduplicate(regex.Key("pattern").ValueWithShadows())
+	}
+
 	return nil
 }
 
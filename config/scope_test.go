@@ -9,6 +9,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
 )
 
@@ -61,6 +62,36 @@ func TestConfigAddDomains(t *testing.T) {
 	}
 }
 
+func TestConfigInternationalizedDomains(t *testing.T) {
+	c := new(Config)
+	c.AddDomains("Bücher.de", "xn--ls8h.la")
+
+	got := stringset.New(c.Domains()...)
+	defer got.Close()
+	if got.Len() != 2 || !got.Has("xn--bcher-kva.de") || !got.Has("xn--ls8h.la") {
+		t.Fatalf("The domains were not added in the punycode form: %v", got.Slice())
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"www.bücher.de", "xn--bcher-kva.de"},
+		{"WWW.XN--BCHER-KVA.DE", "xn--bcher-kva.de"},
+		{"api.💩.la", "xn--ls8h.la"},
+		// The mixed script homograph is a different domain
+		{"www.bücher.de.pаypal.com", ""},
+	}
+	for _, tt := range tests {
+		if got := c.WhichDomain(tt.name); got != tt.want {
+			t.Errorf("WhichDomain(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if c.IsDomainInScope(tt.name) != (tt.want != "") {
+			t.Errorf("IsDomainInScope(%q) did not return %t", tt.name, tt.want != "")
+		}
+	}
+}
+
 func TestConfigParseIPsParseRange(t *testing.T) {
 	type args struct {
 		s string
@@ -172,6 +203,30 @@ func TestConfigParseIPsSet(t *testing.T) {
 	}
 }
 
+func TestConfigIsAddressFamilyAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *Config
+		addr     string
+		expected bool
+	}{
+		{"no restriction", &Config{}, "2001:db8::1", true},
+		{"IPv4 only with IPv4", &Config{IPv4Only: true}, "192.168.1.1", true},
+		{"IPv4 only with IPv6", &Config{IPv4Only: true}, "2001:db8::1", false},
+		{"IPv6 only with IPv4", &Config{IPv6Only: true}, "192.168.1.1", false},
+		{"IPv6 only with IPv6", &Config{IPv6Only: true}, "2001:db8::1", true},
+		{"invalid address", &Config{}, "owasp.org", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.IsAddressFamilyAllowed(tt.addr); got != tt.expected {
+				t.Errorf("IsAddressFamilyAllowed(%s) = %v, expected %v", tt.addr, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestConfigBlacklistSubdomain(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -200,6 +255,78 @@ func TestConfigBlacklistSubdomain(t *testing.T) {
 	}
 }
 
+func TestConfigIsExcluded(t *testing.T) {
+	c := &Config{ExcludePatterns: []string{`^node-[0-9a-f]+\.`, `\.staging\.owasp\.org$`}}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"node-3fa9.owasp.org", true},
+		{"NODE-3FA9.owasp.org", true},
+		{"www.staging.owasp.org", true},
+		{"www.owasp.org", false},
+		{"mynode-3fa9.owasp.org", false},
+	}
+	for _, tt := range tests {
+		if got := c.IsExcluded(tt.name); got != tt.want {
+			t.Errorf("IsExcluded(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigMatchesScopeRegex(t *testing.T) {
+	c := &Config{ScopeRegex: []string{`^api-.*\.prod\.owasp\.org$`, `^www\.owasp\.org$`}}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"api-v2.prod.owasp.org", true},
+		{"API-V2.PROD.owasp.org", true},
+		{"www.owasp.org", true},
+		{"api-v2.staging.owasp.org", false},
+		{"mail.owasp.org", false},
+	}
+	for _, tt := range tests {
+		if got := c.MatchesScopeRegex(tt.name); got != tt.want {
+			t.Errorf("MatchesScopeRegex(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if c := new(Config); !c.MatchesScopeRegex("mail.owasp.org") {
+		t.Errorf("MatchesScopeRegex() returned false without any expressions")
+	}
+}
+
+func TestConfigIsASNExpandable(t *testing.T) {
+	c := NewConfig()
+	c.ExpandASNs = []int{26808}
+
+	if c.IsASNExpandable(26808) {
+		t.Errorf("The ASN was expandable while ExpandByASN was disabled")
+	}
+
+	c.ExpandByASN = true
+	if !c.IsASNExpandable(26808) {
+		t.Errorf("The allowlisted ASN was not expandable")
+	}
+	if c.IsASNExpandable(13335) {
+		t.Errorf("The ASN missing from the allowlist was expandable")
+	}
+	if c.IsASNExpandable(0) {
+		t.Errorf("The unknown ASN was expandable")
+	}
+
+	_, cidr, _ := net.ParseCIDR("104.154.0.0/15")
+	if !c.AddScopeCIDR(cidr) {
+		t.Errorf("The netblock was not added to the scope")
+	}
+	if c.AddScopeCIDR(cidr) {
+		t.Errorf("The netblock was added to the scope twice")
+	}
+}
+
 func TestLoadScopeSettings(t *testing.T) {
 	type args struct {
 		cfg []byte
@@ -364,6 +491,43 @@ func TestLoadScopeSettings(t *testing.T) {
 			assertionFunc: func(t *testing.T, c *Config) {
 			},
 		},
+		{
+			name: "success - valid pattern in section scope.regex",
+			args: args{cfg: []byte(`
+			[scope]
+			[scope.regex]
+			pattern = ^api-.*\.prod\.example\.com$
+			`)},
+			wantErr: false,
+			assertionFunc: func(t *testing.T, c *Config) {
+				if len(c.ScopeRegex) != 1 || c.ScopeRegex[0] != `^api-.*\.prod\.example\.com$` {
+					t.Errorf("Config.loadScopeSettings() - failed to load the scope regex, got %v", c.ScopeRegex)
+				}
+			},
+		},
+		{
+			name: "success - valid ASN expansion in section scope",
+			args: args{cfg: []byte(`
+			[scope]
+			expand_by_asn = true
+			expand_asn = 26808
+			`)},
+			wantErr: false,
+			assertionFunc: func(t *testing.T, c *Config) {
+				if !c.ExpandByASN || len(c.ExpandASNs) != 1 || c.ExpandASNs[0] != 26808 {
+					t.Errorf("Config.loadScopeSettings() - failed to load the ASN expansion, got %v %v", c.ExpandByASN, c.ExpandASNs)
+				}
+			},
+		},
+		{
+			name: "failure - invalid expand_by_asn in section scope",
+			args: args{cfg: []byte(`
+			[scope]
+			expand_by_asn = maybe
+			`)},
+			wantErr:       true,
+			assertionFunc: func(t *testing.T, c *Config) {},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
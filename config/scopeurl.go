@@ -0,0 +1,162 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+)
+
+const (
+	// DefaultScopeRefresh is the interval between scope fetches when ScopeRefresh is not set
+	DefaultScopeRefresh = 10 * time.Minute
+
+	scopeFetchTimeout = 30 * time.Second
+	maxScopeDocSize   = 10 << 20
+)
+
+var scopeClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:       amassnet.ProxyURL,
+		DialContext: amassnet.DirectDialContext,
+	},
+}
+
+// ScopeDocument is the JSON document returned by the ScopeURL endpoint.
+type ScopeDocument struct {
+	Domains   []string `json:"domains"`
+	Addresses []string `json:"addresses"`
+	CIDRs     []string `json:"cidrs"`
+	ASNs      []int    `json:"asns"`
+}
+
+// LoadScopeURL fetches the scope document from the ScopeURL endpoint and adds the
+// domains, addresses, CIDRs and ASNs to the configuration. The scope already in the
+// configuration is left unchanged when the fetch fails. The root domain names that
+// were not previously in scope are returned.
+func (c *Config) LoadScopeURL(ctx context.Context) ([]string, error) {
+	if c.ScopeURL == "" {
+		return nil, nil
+	}
+
+	doc, err := c.fetchScopeDocument(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []net.IP
+	for _, addr := range doc.Addresses {
+		var ips parseIPs
+
+		if err := ips.Set(strings.TrimSpace(addr)); err != nil {
+			return nil, fmt.Errorf("scope URL: %v", err)
+		}
+		addrs = append(addrs, ips...)
+	}
+
+	var cidrs []*net.IPNet
+	for _, cidr := range doc.CIDRs {
+		_, ipnet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("scope URL: %v", err)
+		}
+		cidrs = append(cidrs, ipnet)
+	}
+
+	var added []string
+	for _, d := range doc.Domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || c.DomainRegex(d) != nil {
+			continue
+		}
+
+		c.AddDomain(d)
+		if c.DomainRegex(d) != nil {
+			added = append(added, d)
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, addr := range addrs {
+		if !containsIP(c.Addresses, addr) {
+			c.Addresses = append(c.Addresses, addr)
+		}
+	}
+	for _, cidr := range cidrs {
+		if !containsCIDR(c.CIDRs, cidr) {
+			c.CIDRs = append(c.CIDRs, cidr)
+		}
+	}
+	for _, asn := range doc.ASNs {
+		c.ASNs = uniqueIntAppend(c.ASNs, strconv.Itoa(asn))
+	}
+	return added, nil
+}
+
+// ScopeRefreshInterval returns the duration between fetches of the ScopeURL endpoint.
+func (c *Config) ScopeRefreshInterval() time.Duration {
+	if c.ScopeRefresh > 0 {
+		return c.ScopeRefresh
+	}
+	return DefaultScopeRefresh
+}
+
+func (c *Config) fetchScopeDocument(ctx context.Context) (*ScopeDocument, error) {
+	ctx, cancel := context.WithTimeout(c.ProxyContext(ctx), scopeFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.ScopeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("scope URL: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.ScopeToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.ScopeToken)
+	}
+
+	resp, err := scopeClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scope URL: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scope URL: %s returned status code %d", c.ScopeURL, resp.StatusCode)
+	}
+
+	var doc ScopeDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxScopeDocSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("scope URL: failed to decode the scope document: %v", err)
+	}
+	return &doc, nil
+}
+
+func containsIP(addrs []net.IP, ip net.IP) bool {
+	for _, addr := range addrs {
+		if addr.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsCIDR(cidrs []*net.IPNet, ipnet *net.IPNet) bool {
+	for _, cidr := range cidrs {
+		if cidr.String() == ipnet.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadScopeURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"domains": ["owasp.org", "example.com"], "addresses": ["192.168.1.1"],
+			"cidrs": ["10.0.0.0/8"], "asns": [26808]}`))
+	}))
+	defer ts.Close()
+
+	c := NewConfig()
+	c.AddDomain("example.com")
+	c.ScopeURL = ts.URL
+	c.ScopeToken = "secret"
+
+	added, err := c.LoadScopeURL(context.Background())
+	if err != nil {
+		t.Fatalf("LoadScopeURL returned an error: %v", err)
+	}
+	if len(added) != 1 || added[0] != "owasp.org" {
+		t.Errorf("LoadScopeURL returned %v as the added domains", added)
+	}
+	if !c.IsDomainInScope("www.owasp.org") {
+		t.Errorf("The domain from the scope URL was not in scope")
+	}
+	if !c.IsAddressInScope("10.1.1.1") || !c.IsAddressInScope("192.168.1.1") {
+		t.Errorf("The addresses from the scope URL were not in scope")
+	}
+	if len(c.ASNs) != 1 || c.ASNs[0] != 26808 {
+		t.Errorf("The ASN from the scope URL was not added")
+	}
+
+	// The scope must be unchanged after a failed fetch
+	c.ScopeToken = "wrong"
+	if _, err := c.LoadScopeURL(context.Background()); err == nil {
+		t.Errorf("LoadScopeURL did not return an error for the rejected request")
+	}
+	if !c.IsDomainInScope("www.owasp.org") || !c.IsDomainInScope("example.com") {
+		t.Errorf("The scope was modified by the failed fetch")
+	}
+}
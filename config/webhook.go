@@ -0,0 +1,62 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+// ParseWebhookHeader returns the name and value of a header in the "Name: value" form.
+func ParseWebhookHeader(header string) (string, string, error) {
+	parts := strings.SplitN(header, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", fmt.Errorf("%s is not a header in the 'Name: value' form", header)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+func (c *Config) loadWebhookSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("webhook")
+	if err != nil {
+		return nil
+	}
+
+	c.WebhookURL = strings.TrimSpace(sec.Key("url").String())
+	if c.WebhookURL == "" {
+		return nil
+	}
+
+	for _, header := range sec.Key("header").ValueWithShadows() {
+		if header == "" {
+			continue
+		}
+
+		name, value, err := ParseWebhookHeader(header)
+		if err != nil {
+			return err
+		}
+		if c.WebhookHeaders == nil {
+			c.WebhookHeaders = make(map[string]string)
+		}
+		c.WebhookHeaders[name] = value
+	}
+	return nil
+}
+
+func (c *Config) checkWebhook() error {
+	if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s is not a valid webhook URL", c.WebhookURL)
+	}
+	for name := range c.WebhookHeaders {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("the webhook headers cannot have an empty name")
+		}
+	}
+	return nil
+}
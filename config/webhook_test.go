@@ -0,0 +1,49 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadWebhookSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[webhook]
+		url = https://hooks.example.com/amass
+		header = Authorization: Bearer Secret
+		header = X-Source: amass
+		`),
+	)
+	if err := c.loadWebhookSettings(cfg); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if c.WebhookURL != "https://hooks.example.com/amass" {
+		t.Errorf("The webhook URL was loaded as %s", c.WebhookURL)
+	}
+	if len(c.WebhookHeaders) != 2 || c.WebhookHeaders["Authorization"] != "Bearer Secret" ||
+		c.WebhookHeaders["X-Source"] != "amass" {
+		t.Errorf("The webhook headers were loaded as %v", c.WebhookHeaders)
+	}
+	if err := c.CheckSettings(); err != nil {
+		t.Errorf("The webhook settings were rejected: %v", err)
+	}
+}
+
+func TestParseWebhookHeader(t *testing.T) {
+	for _, header := range []string{"Authorization", ": Bearer Secret", ""} {
+		if _, _, err := ParseWebhookHeader(header); err == nil {
+			t.Errorf("%q was accepted as a webhook header", header)
+		}
+	}
+}
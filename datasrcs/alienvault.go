@@ -6,6 +6,7 @@ package datasrcs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net"
@@ -52,15 +53,29 @@ func (a *AlienVault) OnStart() error {
 	a.creds = a.sys.Config().GetDataSourceConfig(a.String()).GetCredentials()
 
 	if a.creds == nil {
-		a.sys.Config().Log.Printf("%s: API key data was not provided", a.String())
+		a.sys.Config().Leveled().Warn(fmt.Sprintf("%s: API key data was not provided", a.String()), config.SourceField(a.String()))
 	}
 
 	a.SetRateLimit(1)
 	return nil
 }
 
+// ProbeCredentials requests the details of the account owning the API key.
+func (a *AlienVault) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := a.sys.Config().GetDataSourceConfig(a.String()).GetCredentials()
+	if creds == nil || creds.Key == "" {
+		return requests.CredentialsInvalid, errors.New("the API key was not provided")
+	}
+
+	headers := map[string]string{"X-OTX-API-KEY": creds.Key}
+	_, err := http.RequestWebPage(ctx, "https://otx.alienvault.com/api/v1/user/me", nil, headers, nil)
+	return credentialStatus(err), err
+}
+
 // OnRequest implements the Service interface.
 func (a *AlienVault) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, a)
+
 	check := true
 
 	switch req := args.(type) {
@@ -115,7 +130,7 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 	}
 
 	u := a.getURL(req.Domain) + "passive_dns"
-	page, err := http.RequestWebPage(ctx, u, nil, a.getHeaders(), nil)
+	page, err := requestWebPage(ctx, a, u, nil, a.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return
@@ -190,7 +205,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 
 	headers := a.getHeaders()
 	u := a.getURL(req.Domain) + "url_list"
-	page, err := http.RequestWebPage(ctx, u, nil, headers, nil)
+	page, err := requestWebPage(ctx, a, u, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return
@@ -226,7 +241,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 		for cur := m.PageNum + 1; cur <= pages; cur++ {
 			a.CheckRateLimit()
 			pageURL := u + "?page=" + strconv.Itoa(cur)
-			page, err = http.RequestWebPage(ctx, pageURL, nil, headers, nil)
+			page, err = requestWebPage(ctx, a, pageURL, nil, headers, nil)
 			if err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 					fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
@@ -290,7 +305,7 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 	headers := a.getHeaders()
 	for _, email := range emails {
 		pageURL := a.getReverseWhoisURL(email)
-		page, err := http.RequestWebPage(ctx, pageURL, nil, headers, nil)
+		page, err := requestWebPage(ctx, a, pageURL, nil, headers, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 				fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
@@ -339,7 +354,7 @@ func (a *AlienVault) queryWhoisForEmails(ctx context.Context, req *requests.Whoi
 		return emails.Slice()
 	}
 
-	page, err := http.RequestWebPage(ctx, u, nil, a.getHeaders(), nil)
+	page, err := requestWebPage(ctx, a, u, nil, a.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return emails.Slice()
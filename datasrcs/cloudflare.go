@@ -5,6 +5,7 @@ package datasrcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/OWASP/Amass/v3/config"
@@ -45,15 +46,38 @@ func (c *Cloudflare) OnStart() error {
 	c.creds = c.sys.Config().GetDataSourceConfig(c.String()).GetCredentials()
 
 	if c.creds == nil || c.creds.Key == "" {
-		c.sys.Config().Log.Printf("%s: API key data was not provided", c.String())
+		c.sys.Config().Leveled().Warn(fmt.Sprintf("%s: API key data was not provided", c.String()), config.SourceField(c.String()))
 	}
 
 	c.SetRateLimit(2)
 	return nil
 }
 
+// ProbeCredentials verifies the API token with the service, without accessing any zones.
+func (c *Cloudflare) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := c.sys.Config().GetDataSourceConfig(c.String()).GetCredentials()
+	if creds == nil || creds.Key == "" {
+		return requests.CredentialsInvalid, errors.New("the API token was not provided")
+	}
+
+	api, err := cloudflare.NewWithAPIToken(creds.Key, cloudflare.HTTPClient(c.sys.Config().ProxyClient()))
+	if err != nil {
+		return requests.CredentialsInvalid, err
+	}
+
+	_, err = api.VerifyAPIToken(ctx)
+	var reqErr *cloudflare.APIRequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode >= 400 && reqErr.StatusCode < 500 {
+		return requests.CredentialsInvalid, err
+	} else if err != nil {
+		return requests.CredentialsUnreachable, err
+	}
+	return requests.CredentialsValid, nil
+}
+
 // OnRequest implements the Service interface.
 func (c *Cloudflare) OnRequest(ctx context.Context, args service.Args) {
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		c.dnsRequest(ctx, req)
 		c.CheckRateLimit()
@@ -77,17 +101,19 @@ func (c *Cloudflare) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
 
-	api, err := cloudflare.NewWithAPIToken(c.creds.Key)
+	api, err := cloudflare.NewWithAPIToken(c.creds.Key, cloudflare.HTTPClient(cfg.ProxyClient()))
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
 	}
 
+	checkSourceRateLimit(ctx, c)
 	zones, err := api.ListZones(ctx, req.Domain)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
 	}
 
 	for _, zone := range zones {
+		checkSourceRateLimit(ctx, c)
 		records, err := api.DNSRecords(ctx, zone.ID, cloudflare.DNSRecord{})
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
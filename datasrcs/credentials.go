@@ -0,0 +1,123 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/service"
+)
+
+// The time allowed for each data source to probe its service with the credentials
+const credentialProbeTimeout = 20 * time.Second
+
+// CredentialCheck is the result of probing a data source service with the credentials of the configuration.
+type CredentialCheck struct {
+	Source string
+	// One of the requests.Credentials statuses
+	Status string
+	// The reason the credentials are invalid or could not be checked
+	Reason string
+}
+
+// credentialProber is implemented by the data sources that can send a minimal authenticated request
+// to their service, such as a request for the account details, without querying for any domain names.
+type credentialProber interface {
+	ProbeCredentials(ctx context.Context) (string, error)
+}
+
+// The reasons the credentials of the data sources below cannot be probed. The services only accept
+// the credentials with the queries for the domain names, which are often charged against the quota.
+var unprobedCredentials = map[string]string{
+	"360PassiveDNS": "the service only accepts the key with the queries for the domain names",
+	"Ahrefs":        "each request of the service is charged against the units of the subscription",
+	"BufferOver":    "the key is optional and only accepted with the queries for the domain names",
+	"BuiltWith":     "each request of the service is charged against the credits of the key",
+	"C99":           "the service only accepts the key with the queries for the domain names",
+	"Chaos":         "the service only accepts the key with the queries for the domain names",
+	"CIRCL":         "the service only accepts the credentials with the queries for the domain names",
+	"DNSRepo":       "the service only accepts the key with the queries for the domain names",
+	"HackerTarget":  "the key is optional, and the requests without it are accepted within the daily quota",
+	"PentestTools":  "the service only accepts the key with the scans of the domain names",
+	"ThreatBook":    "the service only accepts the key with the queries for the domain names",
+	"ZETAlytics":    "the service only accepts the key with the queries for the domain names",
+}
+
+// CheckCredentials probes the services of the data sources that have credentials in the configuration
+// and returns the results sorted by the source names. The data sources are not started, and the
+// sources that do not support the probe are reported as unchecked. The probes are sent through the
+// proxy of the configuration.
+func CheckCredentials(ctx context.Context, cfg *config.Config, srcs []service.Service) []*CredentialCheck {
+	ctx = cfg.ProxyContext(ctx)
+
+	var checked []service.Service
+	for _, src := range srcs {
+		if dsc := cfg.GetDataSourceConfig(src.String()); dsc != nil && dsc.GetCredentials() != nil {
+			checked = append(checked, src)
+		}
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*CredentialCheck, len(checked))
+	for i, src := range checked {
+		wg.Add(1)
+
+		go func(i int, src service.Service) {
+			defer wg.Done()
+
+			results[i] = checkCredentials(ctx, src)
+		}(i, src)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Source < results[j].Source
+	})
+	return results
+}
+
+func checkCredentials(ctx context.Context, src service.Service) *CredentialCheck {
+	check := &CredentialCheck{Source: src.String(), Status: requests.CredentialsUnchecked}
+
+	if reason, found := unprobedCredentials[src.String()]; found {
+		check.Reason = reason
+		return check
+	}
+
+	p, ok := src.(credentialProber)
+	if !ok {
+		check.Reason = "the data source does not support probing the credentials"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialProbeTimeout)
+	defer cancel()
+
+	status, err := p.ProbeCredentials(ctx)
+	check.Status = status
+	if err != nil {
+		check.Reason = strings.TrimPrefix(err.Error(), src.String()+": ")
+	}
+	return check
+}
+
+// credentialStatus returns the status for the error of a request sent by http.RequestWebPage,
+// which is invalid when the service responded that the request was not authorized.
+func credentialStatus(err error) string {
+	if err == nil {
+		return requests.CredentialsValid
+	}
+
+	e := err.Error()
+	if strings.HasPrefix(e, "401") || strings.HasPrefix(e, "403") {
+		return requests.CredentialsInvalid
+	}
+	return requests.CredentialsUnreachable
+}
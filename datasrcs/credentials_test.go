@@ -0,0 +1,202 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/datasrcs/scripting"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resources"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+)
+
+type probedSource struct {
+	service.BaseService
+	status string
+}
+
+func newProbedSource(name, status string) *probedSource {
+	p := &probedSource{status: status}
+
+	p.BaseService = *service.NewBaseService(p, name)
+	return p
+}
+
+func (p *probedSource) ProbeCredentials(ctx context.Context) (string, error) {
+	if p.status != requests.CredentialsValid {
+		return p.status, errors.New(p.String() + ": the probe failed")
+	}
+	return p.status, nil
+}
+
+type unprobedSource struct {
+	service.BaseService
+}
+
+func TestCheckCredentials(t *testing.T) {
+	cfg := config.NewConfig()
+	for _, name := range []string{"Valid", "Invalid", "Unprobed"} {
+		dsc := cfg.GetDataSourceConfig(name)
+		if err := dsc.AddCredentials(&config.Credentials{Name: "account", Key: "secret"}); err != nil {
+			t.Fatalf("Failed to add the credentials for %s: %v", name, err)
+		}
+	}
+
+	unprobed := &unprobedSource{}
+	unprobed.BaseService = *service.NewBaseService(unprobed, "Unprobed")
+	srcs := []service.Service{
+		newProbedSource("Valid", requests.CredentialsValid),
+		newProbedSource("Invalid", requests.CredentialsInvalid),
+		// Only the data sources with credentials in the configuration are probed
+		newProbedSource("Missing", requests.CredentialsInvalid),
+		unprobed,
+	}
+
+	results := CheckCredentials(context.Background(), cfg, srcs)
+	if len(results) != 3 {
+		t.Fatalf("%d data sources were checked instead of three", len(results))
+	}
+	for i, want := range []CredentialCheck{
+		{Source: "Invalid", Status: requests.CredentialsInvalid, Reason: "the probe failed"},
+		{Source: "Unprobed", Status: requests.CredentialsUnchecked},
+		{Source: "Valid", Status: requests.CredentialsValid},
+	} {
+		if got := results[i]; got.Source != want.Source || got.Status != want.Status ||
+			(want.Reason != "" && got.Reason != want.Reason) {
+			t.Errorf("The check result was %v instead of %v", *got, want)
+		}
+	}
+}
+
+func TestCredentialStatus(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{nil, requests.CredentialsValid},
+		{errors.New("401: 401 Unauthorized"), requests.CredentialsInvalid},
+		{errors.New("403: 403 Forbidden"), requests.CredentialsInvalid},
+		{errors.New("503: 503 Service Unavailable"), requests.CredentialsUnreachable},
+		{errors.New("dial tcp: i/o timeout"), requests.CredentialsUnreachable},
+	} {
+		if got := credentialStatus(test.err); got != test.want {
+			t.Errorf("The error %v was reported as %s instead of %s", test.err, got, test.want)
+		}
+	}
+}
+
+// credentialedTypes returns the names of the data source types with methods that obtain the credentials.
+func credentialedTypes(t *testing.T) map[string]bool {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse the data sources: %v", err)
+	}
+
+	types := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || fn.Body == nil {
+					continue
+				}
+
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "GetCredentials" {
+						if star, ok := fn.Recv.List[0].Type.(*ast.StarExpr); ok {
+							if id, ok := star.X.(*ast.Ident); ok {
+								types[id.Name] = true
+							}
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+	return types
+}
+
+func TestCredentialedSourcesProbed(t *testing.T) {
+	sys := &systems.SimpleSystem{Cfg: config.NewConfig()}
+	// The data sources that use credentials, and if they support probing them
+	credentialed := make(map[string]bool)
+
+	types := credentialedTypes(t)
+	for _, src := range GetAllSources(sys) {
+		if _, ok := src.(*scripting.Script); ok {
+			continue
+		}
+		if name := reflect.TypeOf(src).Elem().Name(); types[name] {
+			_, probed := src.(credentialProber)
+			credentialed[src.String()] = probed
+			delete(types, name)
+		}
+	}
+	for name := range types {
+		t.Errorf("The %s type obtains the credentials, but is not returned by GetAllSources", name)
+	}
+
+	scripts, err := resources.GetDefaultScripts()
+	if err != nil {
+		t.Fatalf("Failed to obtain the default scripts: %v", err)
+	}
+
+	re := regexp.MustCompile(`(?m)^name = "([^"]+)"`)
+	for _, script := range scripts {
+		m := re.FindStringSubmatch(script)
+		if m == nil {
+			t.Fatalf("The name of the script could not be found:\n%s", script)
+		}
+		if strings.Contains(script, "credentials") {
+			credentialed[m[1]] = strings.Contains(script, "function validate(")
+		}
+	}
+
+	for name, probed := range credentialed {
+		_, listed := unprobedCredentials[name]
+		if !probed && !listed {
+			t.Errorf("The credentials of %s cannot be probed, and the reason is not provided", name)
+		} else if probed && listed {
+			t.Errorf("The credentials of %s can be probed, but the reason they cannot is provided", name)
+		}
+	}
+	for name := range unprobedCredentials {
+		if _, found := credentialed[name]; !found {
+			t.Errorf("The reason is provided for %s, which does not use credentials", name)
+		}
+	}
+}
+
+func TestCheckCredentialsUnprobed(t *testing.T) {
+	cfg := config.NewConfig()
+	dsc := cfg.GetDataSourceConfig("Chaos")
+	if err := dsc.AddCredentials(&config.Credentials{Name: "account", Key: "secret"}); err != nil {
+		t.Fatalf("Failed to add the credentials: %v", err)
+	}
+
+	// The probe of the data sources listed is not attempted
+	results := CheckCredentials(context.Background(), cfg, []service.Service{
+		newProbedSource("Chaos", requests.CredentialsInvalid),
+	})
+	if len(results) != 1 || results[0].Status != requests.CredentialsUnchecked ||
+		results[0].Reason != unprobedCredentials["Chaos"] {
+		t.Errorf("The check results were %v", results)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+	"github.com/caffix/stringset"
+)
+
+const (
+	ctTailInterval = time.Minute
+	// The number of entries requested from the log at once
+	ctBatchSize = 256
+	// The number of entries processed during each poll before skipping ahead to the newest entries
+	ctMaxEntriesPerPoll = 10000
+)
+
+// DefaultCTLogs are the certificate transparency logs tailed when the configuration does not provide any.
+var DefaultCTLogs = []string{
+	"https://ct.googleapis.com/logs/argon2022/",
+	"https://ct.googleapis.com/logs/xenon2022/",
+	"https://ct.cloudflare.com/logs/nimbus2022/",
+	"https://yeti2022.ct.digicert.com/log/",
+	"https://oak.ct.letsencrypt.org/2022/",
+}
+
+type ctSTH struct {
+	TreeSize uint64 `json:"tree_size"`
+}
+
+type ctEntries struct {
+	Entries []struct {
+		LeafInput []byte `json:"leaf_input"`
+		ExtraData []byte `json:"extra_data"`
+	} `json:"entries"`
+}
+
+// CTTail is the Service that monitors certificate transparency logs for newly issued certificates.
+type CTTail struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	lock       sync.Mutex
+	tails      map[*eventbus.EventBus]struct{}
+}
+
+// NewCTTail returns he object initialized, but not yet started.
+func NewCTTail(sys systems.System) *CTTail {
+	c := &CTTail{
+		SourceType: requests.CERT,
+		sys:        sys,
+		tails:      make(map[*eventbus.EventBus]struct{}),
+	}
+
+	c.BaseService = *service.NewBaseService(c, "CTTail")
+	return c
+}
+
+// Description implements the Service interface.
+func (c *CTTail) Description() string {
+	return c.SourceType
+}
+
+// OnStart implements the Service interface.
+func (c *CTTail) OnStart() error {
+	c.SetRateLimit(1)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (c *CTTail) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, c)
+
+	if req, ok := args.(*requests.DNSRequest); ok {
+		c.dnsRequest(ctx, req)
+	}
+}
+
+func (c *CTTail) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+	if !cfg.CTTail || !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	// The logs are tailed once for each enumeration, and the names
+	// are checked against all the domains in scope
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, found := c.tails[bus]; found {
+		return
+	}
+
+	c.tails[bus] = struct{}{}
+	go c.tail(ctx, cfg, bus)
+}
+
+func (c *CTTail) tail(ctx context.Context, cfg *config.Config, bus *eventbus.EventBus) {
+	defer func() {
+		c.lock.Lock()
+		delete(c.tails, bus)
+		c.lock.Unlock()
+	}()
+
+	logs := cfg.CTLogs
+	if len(logs) == 0 {
+		logs = DefaultCTLogs
+	}
+
+	filter := stringset.New()
+	defer filter.Close()
+
+	var wg sync.WaitGroup
+	for _, log := range logs {
+		wg.Add(1)
+		go c.tailLog(ctx, strings.TrimRight(log, "/"), filter, &wg)
+	}
+	wg.Wait()
+}
+
+func (c *CTTail) tailLog(ctx context.Context, log string, filter *stringset.Set, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	_, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	// Only the certificates logged after the tailing begins are considered
+	next, err := c.treeSize(ctx, log)
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), log, err))
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityLow, fmt.Sprintf("%s: Tailing %s from entry %d", c.String(), log, next))
+
+	t := time.NewTicker(ctTailInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		size, err := c.treeSize(ctx, log)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityLow, fmt.Sprintf("%s: %s: %v", c.String(), log, err))
+			continue
+		}
+		if size > next && size-next > ctMaxEntriesPerPoll {
+			bus.Publish(requests.LogTopic, eventbus.PriorityLow,
+				fmt.Sprintf("%s: %s: Skipped %d entries to keep up with the log", c.String(), log, size-next-ctMaxEntriesPerPoll))
+			next = size - ctMaxEntriesPerPoll
+		}
+
+		for next < size {
+			end := next + ctBatchSize
+			if end > size {
+				end = size
+			}
+
+			num, err := c.processEntries(ctx, log, next, end-1, filter)
+			if err != nil {
+				bus.Publish(requests.LogTopic, eventbus.PriorityLow, fmt.Sprintf("%s: %s: %v", c.String(), log, err))
+				break
+			}
+			if num == 0 {
+				break
+			}
+			// The log may return fewer entries than requested
+			next += uint64(num)
+		}
+	}
+}
+
+func (c *CTTail) treeSize(ctx context.Context, log string) (uint64, error) {
+	page, err := http.RequestWebPage(ctx, log+"/ct/v1/get-sth", nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var sth ctSTH
+	if err := json.Unmarshal([]byte(page), &sth); err != nil {
+		return 0, err
+	}
+	return sth.TreeSize, nil
+}
+
+func (c *CTTail) processEntries(ctx context.Context, log string, start, end uint64, filter *stringset.Set) (int, error) {
+	cfg, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	c.CheckRateLimit()
+	u := fmt.Sprintf("%s/ct/v1/get-entries?start=%d&end=%d", log, start, end)
+	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries ctEntries
+	if err := json.Unmarshal([]byte(page), &entries); err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries.Entries {
+		cert, err := parseCTLeafCert(entry.LeafInput, entry.ExtraData)
+		if err != nil {
+			continue
+		}
+
+		names := http.NamesFromCert(cert)
+		// The certificates issued to the organizations in scope bring the domains they name into scope
+		for _, domain := range cfg.CertOrgDomains(http.CertOrganizations(cert), names) {
+			cfg.AddDomain(domain)
+			bus.Publish(requests.NewScopeTopic, eventbus.PriorityHigh, domain, "Certificate organization")
+		}
+
+		for _, name := range names {
+			name = strings.ToLower(name)
+			// Only the names within scope are tracked to keep the filter small
+			if filter.Has(name) || cfg.WhichDomain(name) == "" {
+				continue
+			}
+
+			filter.Insert(name)
+			genNewNameEvent(ctx, c.sys, c, name)
+		}
+	}
+	return len(entries.Entries), nil
+}
+
+// parseCTLeafCert extracts the certificate or pre-certificate from a log entry, as described in RFC 6962.
+func parseCTLeafCert(leaf, extra []byte) (*x509.Certificate, error) {
+	// The version, leaf type, timestamp and entry type precede the entry
+	if len(leaf) < 12 {
+		return nil, errors.New("the leaf input is too short")
+	}
+
+	var der []byte
+	var err error
+	switch binary.BigEndian.Uint16(leaf[10:12]) {
+	case 0:
+		der, err = readASN1Cert(leaf[12:])
+	case 1:
+		// The full pre-certificate is the first element of the extra data
+		der, err = readASN1Cert(extra)
+	default:
+		err = errors.New("the log entry type is not supported")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+func readASN1Cert(data []byte) ([]byte, error) {
+	if len(data) < 3 {
+		return nil, errors.New("the certificate length is missing")
+	}
+
+	l := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+	if len(data) < 3+l {
+		return nil, errors.New("the certificate is truncated")
+	}
+	return data[3 : 3+l], nil
+}
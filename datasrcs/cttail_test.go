@@ -0,0 +1,109 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testCTCert(t *testing.T, names ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+	return der
+}
+
+// asn1Cert prefixes the certificate with the 24-bit length used by the log entries.
+func asn1Cert(der []byte) []byte {
+	l := len(der)
+	return append([]byte{byte(l >> 16), byte(l >> 8), byte(l)}, der...)
+}
+
+// ctLeaf returns the leaf input with the version, leaf type, timestamp and entry type, followed by the entry.
+func ctLeaf(entryType uint16, entry []byte) []byte {
+	leaf := make([]byte, 12)
+	binary.BigEndian.PutUint64(leaf[2:10], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	binary.BigEndian.PutUint16(leaf[10:12], entryType)
+	return append(leaf, entry...)
+}
+
+func TestParseCTLeafCert(t *testing.T) {
+	der := testCTCert(t, "www.owasp.org", "mail.owasp.org")
+	// The pre-certificate entry provides the issuer key hash and the TBS certificate in the leaf
+	precert := append(make([]byte, 32), asn1Cert([]byte("tbs"))...)
+
+	tests := []struct {
+		name    string
+		leaf    []byte
+		extra   []byte
+		wantErr bool
+	}{
+		{name: "certificate entry", leaf: ctLeaf(0, asn1Cert(der))},
+		{name: "pre-certificate entry", leaf: ctLeaf(1, precert), extra: asn1Cert(der)},
+		{name: "short leaf input", leaf: ctLeaf(0, nil)[:10], wantErr: true},
+		{name: "unsupported entry type", leaf: ctLeaf(2, asn1Cert(der)), wantErr: true},
+		{name: "missing certificate length", leaf: ctLeaf(0, []byte{0}), wantErr: true},
+		{name: "truncated certificate", leaf: ctLeaf(0, asn1Cert(der)[:100]), wantErr: true},
+		{name: "pre-certificate without the extra data", leaf: ctLeaf(1, precert), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := parseCTLeafCert(tt.leaf, tt.extra)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCTLeafCert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(cert.DNSNames) != 2 || cert.DNSNames[0] != "www.owasp.org" || cert.DNSNames[1] != "mail.owasp.org" {
+				t.Errorf("The certificate names %v were parsed from the entry", cert.DNSNames)
+			}
+		})
+	}
+}
+
+func TestCTEntriesDecoding(t *testing.T) {
+	leaf := ctLeaf(0, asn1Cert(testCTCert(t, "vpn.owasp.org")))
+	page := `{"entries": [{"leaf_input": "` + base64.StdEncoding.EncodeToString(leaf) + `", "extra_data": ""}]}`
+
+	var entries ctEntries
+	if err := json.Unmarshal([]byte(page), &entries); err != nil {
+		t.Fatalf("Failed to decode the entries: %v", err)
+	}
+	if len(entries.Entries) != 1 {
+		t.Fatalf("%d entries were decoded", len(entries.Entries))
+	}
+
+	cert, err := parseCTLeafCert(entries.Entries[0].LeafInput, entries.Entries[0].ExtraData)
+	if err != nil {
+		t.Fatalf("The decoded entry was not parsed: %v", err)
+	}
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != "vpn.owasp.org" {
+		t.Errorf("The certificate names %v were parsed from the decoded entry", cert.DNSNames)
+	}
+}
@@ -50,7 +50,7 @@ func (d *DNSDB) OnStart() error {
 	d.creds = d.sys.Config().GetDataSourceConfig(d.String()).GetCredentials()
 
 	if d.creds == nil || d.creds.Key == "" {
-		d.sys.Config().Log.Printf("%s: API key data was not provided", d.String())
+		d.sys.Config().Leveled().Warn(fmt.Sprintf("%s: API key data was not provided", d.String()), config.SourceField(d.String()))
 	}
 
 	d.SetRateLimit(1)
@@ -62,15 +62,33 @@ func (d *DNSDB) checkConfig() error {
 
 	if creds == nil || creds.Key == "" {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", d.String())
-		d.sys.Config().Log.Print(estr)
+		d.sys.Config().Leveled().Error(estr, config.SourceField(d.String()))
 		return errors.New(estr)
 	}
 
 	return nil
 }
 
+// ProbeCredentials requests the rate limits of the API key, which does not count against the quota.
+func (d *DNSDB) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := d.sys.Config().GetDataSourceConfig(d.String()).GetCredentials()
+	if creds == nil || creds.Key == "" {
+		return requests.CredentialsInvalid, errors.New("the API key was not provided")
+	}
+
+	headers := map[string]string{
+		"X-API-Key": creds.Key,
+		"Accept":    "application/json",
+	}
+
+	_, err := http.RequestWebPage(ctx, "https://api.dnsdb.info/lookup/rate_limit", nil, headers, nil)
+	return credentialStatus(err), err
+}
+
 // OnRequest implements the Service interface.
 func (d *DNSDB) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, d)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		d.dnsRequest(ctx, req)
 		d.CheckRateLimit()
@@ -102,7 +120,7 @@ func (d *DNSDB) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	url := d.getURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, d, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), url, err))
 		return
@@ -5,10 +5,13 @@ package datasrcs
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 
 	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -48,7 +51,7 @@ func (f *FOFA) OnStart() error {
 	if f.creds == nil || f.creds.Username == "" || f.creds.Key == "" {
 		estr := fmt.Sprintf("%s: Email address or API key data was not provided", f.String())
 
-		f.sys.Config().Log.Print(estr)
+		f.sys.Config().Leveled().Error(estr, config.SourceField(f.String()))
 		return errors.New(estr)
 	}
 
@@ -56,8 +59,36 @@ func (f *FOFA) OnStart() error {
 	return nil
 }
 
+// ProbeCredentials requests the details of the account, which the service returns with an error
+// field, instead of an error status, when the email address or API key is rejected.
+func (f *FOFA) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := f.sys.Config().GetDataSourceConfig(f.String()).GetCredentials()
+	if creds == nil || creds.Username == "" || creds.Key == "" {
+		return requests.CredentialsInvalid, errors.New("the email address and API key were not provided")
+	}
+
+	u := "https://fofa.so/api/v1/info/my?" + url.Values{"email": {creds.Username}, "key": {creds.Key}}.Encode()
+	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	if err != nil {
+		return credentialStatus(err), err
+	}
+
+	var info struct {
+		Error  bool   `json:"error"`
+		ErrMsg string `json:"errmsg"`
+	}
+	if err := json.Unmarshal([]byte(page), &info); err != nil {
+		return requests.CredentialsUnreachable, fmt.Errorf("failed to parse the account details: %v", err)
+	}
+	if info.Error {
+		return requests.CredentialsInvalid, errors.New(info.ErrMsg)
+	}
+	return requests.CredentialsValid, nil
+}
+
 // OnRequest implements the Service interface.
 func (f *FOFA) OnRequest(ctx context.Context, args service.Args) {
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		f.dnsRequest(ctx, req)
 		f.CheckRateLimit()
@@ -86,8 +117,11 @@ func (f *FOFA) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: Failed to create FOFA client", f.String()))
 		return
 	}
+	// Send the requests through the proxy of the configuration
+	client.Client = cfg.ProxyClient()
 
 	for i := 1; i <= 10; i++ {
+		checkSourceRateLimit(ctx, f)
 		results, err := client.QueryAsArray(uint(i), []byte(fmt.Sprintf("domain=\"%s\"", req.Domain)), []byte("domain"))
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", f.String(), err))
@@ -6,6 +6,7 @@ package datasrcs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
@@ -73,7 +74,7 @@ func (n *NetworksDB) OnStart() error {
 	n.creds = n.sys.Config().GetDataSourceConfig(n.String()).GetCredentials()
 
 	if n.creds == nil || n.creds.Key == "" {
-		n.sys.Config().Log.Printf("%s: API key data was not provided", n.String())
+		n.sys.Config().Leveled().Warn(fmt.Sprintf("%s: API key data was not provided", n.String()), config.SourceField(n.String()))
 		n.SourceType = requests.SCRAPE
 		n.hasAPIKey = false
 	}
@@ -82,8 +83,22 @@ func (n *NetworksDB) OnStart() error {
 	return nil
 }
 
+// ProbeCredentials requests the details of the API key, including the remaining quota.
+func (n *NetworksDB) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := n.sys.Config().GetDataSourceConfig(n.String()).GetCredentials()
+	if creds == nil || creds.Key == "" {
+		return requests.CredentialsInvalid, errors.New("the API key was not provided")
+	}
+
+	headers := map[string]string{"X-Api-Key": creds.Key}
+	_, err := http.RequestWebPage(ctx, networksdbBaseURL+"/api/key", nil, headers, nil)
+	return credentialStatus(err), err
+}
+
 // OnRequest implements the Service interface.
 func (n *NetworksDB) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, n)
+
 	check := true
 
 	switch req := args.(type) {
@@ -129,7 +144,7 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 	}
 
 	u := n.getIPURL(addr)
-	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := requestWebPage(ctx, n, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -145,7 +160,7 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	numRateLimitChecks(n, 3)
 	u = networksdbBaseURL + matches[1]
-	page, err = http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err = requestWebPage(ctx, n, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -191,7 +206,7 @@ func (n *NetworksDB) executeASNQuery(ctx context.Context, asn int, addr string,
 
 	numRateLimitChecks(n, 3)
 	u := n.getASNURL(asn)
-	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := requestWebPage(ctx, n, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -371,7 +386,7 @@ func (n *NetworksDB) apiIPQuery(ctx context.Context, addr string) (string, strin
 	u := n.getAPIIPURL()
 	params := url.Values{"ip": {addr}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := requestWebPage(ctx, n, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return "", ""
@@ -419,7 +434,7 @@ func (n *NetworksDB) apiOrgInfoQuery(ctx context.Context, id string) []int {
 	u := n.getAPIOrgInfoURL()
 	params := url.Values{"id": {id}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := requestWebPage(ctx, n, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return []int{}
@@ -462,7 +477,7 @@ func (n *NetworksDB) apiASNInfoQuery(ctx context.Context, asn int) *requests.ASN
 	u := n.getAPIASNInfoURL()
 	params := url.Values{"asn": {strconv.Itoa(asn)}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := requestWebPage(ctx, n, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return nil
@@ -517,7 +532,7 @@ func (n *NetworksDB) apiNetblocksQuery(ctx context.Context, asn int) *stringset.
 	u := n.getAPINetblocksURL()
 	params := url.Values{"asn": {strconv.Itoa(asn)}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := requestWebPage(ctx, n, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return netblocks
@@ -575,7 +590,7 @@ func (n *NetworksDB) whoisRequest(ctx context.Context, req *requests.WhoisReques
 
 	numRateLimitChecks(n, 2)
 	u := n.getDomainToIPURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := requestWebPage(ctx, n, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -600,7 +615,7 @@ func (n *NetworksDB) whoisRequest(ctx context.Context, req *requests.WhoisReques
 
 		numRateLimitChecks(n, 3)
 		u = networksdbBaseURL + match[1]
-		page, err = http.RequestWebPage(ctx, u, nil, nil, nil)
+		page, err = requestWebPage(ctx, n, u, nil, nil, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 			continue
@@ -623,7 +638,7 @@ func (n *NetworksDB) whoisRequest(ctx context.Context, req *requests.WhoisReques
 		first, last := amassnet.FirstLast(cidr)
 		u := n.getDomainsInNetworkURL(first.String(), last.String())
 
-		page, err = http.RequestWebPage(ctx, u, nil, nil, nil)
+		page, err = requestWebPage(ctx, n, u, nil, nil, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 			continue
@@ -13,7 +13,6 @@ import (
 	"time"
 
 	amassnet "github.com/OWASP/Amass/v3/net"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -90,6 +89,8 @@ func (r *RADb) registryRADbURL(registry string) string {
 
 // OnRequest implements the Service interface.
 func (r *RADb) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, r)
+
 	if req, ok := args.(*requests.ASNRequest); ok {
 		r.asnRequest(ctx, req)
 		r.CheckRateLimit()
@@ -118,7 +119,7 @@ func (r *RADb) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	url := r.getIPURL("arin", addr)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, r, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -179,7 +180,7 @@ func (r *RADb) executeASNQuery(ctx context.Context, asn int, addr, prefix string
 	numRateLimitChecks(r, 2)
 	url := r.getASNURL("arin", strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, r, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -267,7 +268,7 @@ func (r *RADb) netblocks(ctx context.Context, asn int) *stringset.Set {
 	numRateLimitChecks(r, 2)
 	url := r.getNetblocksURL(strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, r, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return netblocks
@@ -356,7 +357,7 @@ func (r *RADb) ipToASN(ctx context.Context, cidr string) int {
 		r.addr = ip
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.sys.Config().ProxyContext(context.Background()), 10*time.Second)
 	defer cancel()
 
 	conn, err := amassnet.DialContext(ctx, "tcp", r.addr+":43")
@@ -11,6 +11,7 @@ import (
 )
 
 // Wrapper so that scripts can obtain the configuration for the current enumeration.
+// The optional name argument selects the settings overridden for the domain of the name.
 func (s *Script) config(L *lua.LState) int {
 	ctx, err := extractContext(L.CheckUserData(1))
 	if err != nil {
@@ -24,6 +25,7 @@ func (s *Script) config(L *lua.LState) int {
 		return 1
 	}
 
+	name := L.OptString(2, "")
 	r := L.NewTable()
 	if cfg.Active {
 		r.RawSetString("mode", lua.LString("active"))
@@ -93,14 +95,14 @@ func (s *Script) config(L *lua.LState) int {
 	r.RawSetString("scope", scope)
 
 	tb = L.NewTable()
-	tb.RawSetString("active", lua.LBool(cfg.BruteForcing))
+	tb.RawSetString("active", lua.LBool(cfg.BruteForcingFor(name)))
 	tb.RawSetString("recursive", lua.LBool(cfg.Recursive))
 	tb.RawSetString("min_for_recursive", lua.LNumber(cfg.MinForRecursive))
 	tb.RawSetString("max_depth", lua.LNumber(cfg.MaxDepth))
 	r.RawSetString("brute_forcing", tb)
 
 	tb = L.NewTable()
-	tb.RawSetString("active", lua.LBool(cfg.Alterations))
+	tb.RawSetString("active", lua.LBool(cfg.AlterationsFor(name)))
 	tb.RawSetString("flip_words", lua.LBool(cfg.FlipWords))
 	tb.RawSetString("flip_numbers", lua.LBool(cfg.FlipNumbers))
 	tb.RawSetString("add_words", lua.LBool(cfg.AddWords))
@@ -165,12 +167,13 @@ func (s *Script) inScope(L *lua.LState) int {
 }
 
 // Wrapper so that scripts can obtain the brute force wordlist for the current enumeration.
+// The optional name argument selects the wordlist overridden for the domain of the name.
 func (s *Script) bruteWordlist(L *lua.LState) int {
 	tb := L.NewTable()
 
 	if ctx, err := extractContext(L.CheckUserData(1)); err == nil {
 		if cfg, _, err := requests.ContextConfigBus(ctx); err == nil {
-			for _, word := range cfg.Wordlist {
+			for _, word := range cfg.WordlistFor(L.OptString(2, "")) {
 				tb.Append(lua.LString(word))
 			}
 		}
@@ -185,12 +188,13 @@ func (s *Script) bruteWordlist(L *lua.LState) int {
 }
 
 // Wrapper so that scripts can obtain the alteration wordlist for the current enumeration.
+// The optional name argument selects the wordlist overridden for the domain of the name.
 func (s *Script) altWordlist(L *lua.LState) int {
 	tb := L.NewTable()
 
 	if ctx, err := extractContext(L.CheckUserData(1)); err == nil {
 		if cfg, _, err := requests.ContextConfigBus(ctx); err == nil {
-			for _, word := range cfg.AltWordlist {
+			for _, word := range cfg.AltWordlistFor(L.OptString(2, "")) {
 				tb.Append(lua.LString(word))
 			}
 		}
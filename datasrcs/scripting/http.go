@@ -147,6 +147,8 @@ func (s *Script) req(ctx context.Context, url, data string, headers map[string]s
 	}
 
 	numRateLimitChecks(s, s.seconds)
+	// Each request sent by the script is charged against the per minute rate limit of the data source
+	cfg.CheckSourceRateLimit(ctx, s.String())
 	resp, err := http.RequestWebPage(ctx, url, body, headers, auth)
 	if err != nil {
 		if cfg.Verbose {
@@ -0,0 +1,78 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package scripting
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestRequestSourceRateLimit(t *testing.T) {
+	var lock sync.Mutex
+	var hits int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		hits++
+		lock.Unlock()
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	ctx, sys := setupMockScriptEnv(fmt.Sprintf(`
+		name="paginated"
+		type="testing"
+
+		function vertical(ctx, domain)
+			for i=1,3 do
+				request(ctx, {['url']="%s/?page=" .. i})
+			end
+			new_name(ctx, "www." .. domain)
+		end
+	`, ts.URL))
+	if ctx == nil || sys == nil {
+		t.Fatal("Failed to initialize the scripting environment")
+	}
+	defer func() { _ = sys.Shutdown() }()
+
+	cfg, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		t.Fatal("Failed to obtain the config and event bus")
+	}
+	// Permit one request every 100 milliseconds
+	cfg.SetSourceRateLimit("paginated", 600)
+
+	ch := make(chan *requests.DNSRequest, 1)
+	fn := func(req *requests.DNSRequest) {
+		ch <- req
+	}
+	bus.Subscribe(requests.NewNameTopic, fn)
+	defer bus.Unsubscribe(requests.NewNameTopic, fn)
+
+	domain := "owasp.org"
+	cfg.AddDomain(domain)
+	start := time.Now()
+	sys.DataSources()[0].Request(ctx, &requests.DNSRequest{Domain: domain})
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The vertical callback did not complete")
+	}
+	// Each page requested by the callback is charged against the rate limit
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("The three requests of the callback took %v", elapsed)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if hits != 3 {
+		t.Errorf("The service received %d requests", hits)
+	}
+}
@@ -21,10 +21,12 @@ func genNewNameEvent(ctx context.Context, srv service.Service, name string) {
 	if cfg, bus, err := requests.ContextConfigBus(ctx); err == nil {
 		if domain := cfg.WhichDomain(name); domain != "" {
 			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-				Name:   name,
-				Domain: domain,
-				Tag:    srv.Description(),
-				Source: srv.String(),
+				Name:       name,
+				Domain:     domain,
+				Tag:        srv.Description(),
+				Source:     srv.String(),
+				Provenance: requests.ContextProvenance(ctx, srv.Description(), srv.String()),
+				Depth:      requests.ContextDepth(ctx),
 			})
 		}
 	}
@@ -358,3 +358,42 @@ func TestAssociated(t *testing.T) {
 		}
 	}
 }
+
+func TestOrganizationCallback(t *testing.T) {
+	ctx, sys := setupMockScriptEnv(`
+		name="organization"
+		type="testing"
+
+		function horizontal(ctx, domain)
+			associated(ctx, domain, "horizontal.com")
+		end
+
+		function organization(ctx, org)
+			associated(ctx, org, "example.com")
+		end
+	`)
+	if ctx == nil || sys == nil {
+		t.Fatal("Failed to initialize the scripting environment")
+	}
+	defer func() { _ = sys.Shutdown() }()
+
+	_, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		t.Fatal("Failed to obtain the config and event bus")
+	}
+
+	ch := make(chan *requests.WhoisRequest, 2)
+	fn := func(req *requests.WhoisRequest) {
+		ch <- req
+	}
+
+	bus.Subscribe(requests.NewWhoisTopic, fn)
+	defer bus.Unsubscribe(requests.NewWhoisTopic, fn)
+
+	sys.DataSources()[0].Request(ctx, &requests.WhoisRequest{Company: "Example Inc"})
+
+	req := <-ch
+	if req.Domain != "Example Inc" || len(req.NewDomains) != 1 || req.NewDomains[0] != "example.com" {
+		t.Errorf("Incorrect output for the organization callback, got: %v", req)
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -21,13 +23,21 @@ import (
 	luajson "layeh.com/gopher-json"
 )
 
+// Identifies the messages logged while a script is loaded, before the script name is known
+var scriptField = config.SourceField("Script")
+
+// The longest duration the validate callback of a script is waited for at startup
+const validateTimeout = 10 * time.Second
+
 // Script callback functions
 type callbacks struct {
 	Start      lua.LValue
 	Stop       lua.LValue
 	Check      lua.LValue
+	Validate   lua.LValue
 	Vertical   lua.LValue
 	Horizontal lua.LValue
+	Org        lua.LValue
 	Address    lua.LValue
 	Asn        lua.LValue
 	Resolved   lua.LValue
@@ -70,8 +80,8 @@ func NewScript(script string, sys systems.System) *Script {
 	if err := L.DoString(script); err != nil {
 		msg := fmt.Sprintf("Script: Failed to load script: %v", err)
 
-		sys.Config().Log.Print(msg)
-		sys.Config().Log.Print(script)
+		sys.Config().Leveled().Error(msg, scriptField)
+		sys.Config().Leveled().Debug(script, scriptField)
 		return nil
 	}
 
@@ -80,8 +90,8 @@ func NewScript(script string, sys systems.System) *Script {
 	if err != nil {
 		msg := fmt.Sprintf("Script: Failed to obtain the script type: %v", err)
 
-		sys.Config().Log.Print(msg)
-		sys.Config().Log.Print(script)
+		sys.Config().Leveled().Error(msg, scriptField)
+		sys.Config().Leveled().Debug(script, scriptField)
 		return nil
 	}
 
@@ -90,8 +100,8 @@ func NewScript(script string, sys systems.System) *Script {
 	if err != nil {
 		msg := fmt.Sprintf("Script: Failed to obtain the script name: %v", err)
 
-		sys.Config().Log.Print(msg)
-		sys.Config().Log.Print(script)
+		sys.Config().Leveled().Error(msg, scriptField)
+		sys.Config().Leveled().Debug(script, scriptField)
 		return nil
 	}
 	s.BaseService = *service.NewBaseService(s, name)
@@ -142,8 +152,10 @@ func (s *Script) assignCallbacks() {
 		Start:      L.GetGlobal("start"),
 		Stop:       L.GetGlobal("stop"),
 		Check:      L.GetGlobal("check"),
+		Validate:   L.GetGlobal("validate"),
 		Vertical:   L.GetGlobal("vertical"),
 		Horizontal: L.GetGlobal("horizontal"),
+		Org:        L.GetGlobal("organization"),
 		Address:    L.GetGlobal("address"),
 		Asn:        L.GetGlobal("asn"),
 		Resolved:   L.GetGlobal("resolved"),
@@ -200,7 +212,7 @@ func (s *Script) OnStart() error {
 			Protect: true,
 		})
 		if err != nil {
-			s.sys.Config().Log.Print(fmt.Sprintf("%s: start callback: %v", s.String(), err))
+			s.sys.Config().Leveled().Error(fmt.Sprintf("%s: start callback: %v", s.String(), err), config.SourceField(s.String()))
 		}
 	}
 
@@ -208,7 +220,10 @@ func (s *Script) OnStart() error {
 		s.SetRateLimit(1)
 	}
 
-	return s.checkConfig()
+	if err := s.checkConfig(); err != nil {
+		return err
+	}
+	return s.validateConfig()
 }
 
 // OnStop implements the Service interface.
@@ -226,7 +241,7 @@ func (s *Script) OnStop() error {
 		if err != nil {
 			err = fmt.Errorf("%s: stop callback: %v", s.String(), err)
 
-			s.sys.Config().Log.Print(err.Error())
+			s.sys.Config().Leveled().Error(err.Error(), config.SourceField(s.String()))
 		}
 	}
 
@@ -250,7 +265,7 @@ func (s *Script) checkConfig() error {
 	if err != nil {
 		estr := fmt.Sprintf("%s: check callback: %v", s.String(), err)
 
-		s.sys.Config().Log.Print(estr)
+		s.sys.Config().Leveled().Error(estr, config.SourceField(s.String()))
 		return errors.New(estr)
 	}
 
@@ -263,15 +278,92 @@ func (s *Script) checkConfig() error {
 	}
 
 	estr := fmt.Sprintf("%s: check callback failed for the configuration", s.String())
-	s.sys.Config().Log.Print(estr)
+	s.sys.Config().Leveled().Error(estr, config.SourceField(s.String()))
 	return errors.New(estr)
 }
 
+// validateConfig probes the service with the credentials of the configuration, using the validate
+// callback of the script. The script is only disabled when the callback reports that the service
+// rejected the credentials, since the probe can also fail for reasons such as a network outage.
+func (s *Script) validateConfig() error {
+	if s.cbs.Validate.Type() == lua.LTNil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+
+	ret, err := s.callValidate(ctx)
+	if err != nil {
+		s.sys.Config().Leveled().Warn(fmt.Sprintf("%s: validate callback: %v", s.String(), err), config.SourceField(s.String()))
+		return nil
+	}
+
+	if passed, ok := ret.(lua.LBool); ok && !bool(passed) {
+		estr := fmt.Sprintf("%s: the credentials were rejected by the service", s.String())
+		s.sys.Config().Leveled().Error(estr, config.SourceField(s.String()))
+		return errors.New(estr)
+	}
+	return nil
+}
+
+// ProbeCredentials returns the result of the validate callback of the script, which reports the
+// credentials as valid when it returns true, invalid when it returns false, and the service as
+// unreachable when it returns nil or raises an error. The script does not need to be started.
+func (s *Script) ProbeCredentials(ctx context.Context) (string, error) {
+	s.active.Lock()
+	defer s.active.Unlock()
+
+	if s.cbs.Validate.Type() == lua.LTNil {
+		return requests.CredentialsUnchecked, errors.New("the script does not define the validate callback")
+	}
+
+	ret, err := s.callValidate(ctx)
+	if err != nil {
+		return requests.CredentialsUnreachable, err
+	}
+	if passed, ok := ret.(lua.LBool); ok {
+		if bool(passed) {
+			return requests.CredentialsValid, nil
+		}
+		return requests.CredentialsInvalid, errors.New("the credentials were rejected by the service")
+	}
+	return requests.CredentialsUnreachable, errors.New("the service could not be reached")
+}
+
+// callValidate executes the validate callback of the script and returns the value returned.
+func (s *Script) callValidate(ctx context.Context) (lua.LValue, error) {
+	L := s.luaState
+
+	bus := eventbus.NewEventBus()
+	defer bus.Stop()
+
+	ctx = context.WithValue(ctx, requests.ContextConfig, s.sys.Config())
+	ctx = s.sys.Config().ProxyContext(ctx)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+
+	if err := L.CallByParam(lua.P{
+		Fn:      s.cbs.Validate,
+		NRet:    1,
+		Protect: true,
+	}, s.contextToUserData(ctx)); err != nil {
+		return lua.LNil, err
+	}
+
+	ret := L.Get(-1)
+	L.Pop(1)
+	return ret, nil
+}
+
 // OnRequest implements the Service interface.
 func (s *Script) OnRequest(ctx context.Context, args service.Args) {
 	s.active.Lock()
 	defer s.active.Unlock()
 
+	if cfg, _, err := requests.ContextConfigBus(ctx); err == nil {
+		ctx = http.WithHeaderProfile(ctx, cfg.HeaderProfile(s.String()))
+	}
+
 	switch req := args.(type) {
 	case *requests.DNSRequest:
 		if s.cbs.Vertical.Type() != lua.LTNil && req != nil && req.Domain != "" {
@@ -294,8 +386,10 @@ func (s *Script) OnRequest(ctx context.Context, args service.Args) {
 			s.asnRequest(ctx, req)
 		}
 	case *requests.WhoisRequest:
-		if s.cbs.Horizontal.Type() != lua.LTNil {
+		if s.cbs.Horizontal.Type() != lua.LTNil && req != nil && req.Domain != "" {
 			s.whoisRequest(ctx, req)
+		} else if s.cbs.Org.Type() != lua.LTNil && req != nil && req.Domain == "" && req.Company != "" {
+			s.orgRequest(ctx, req)
 		}
 	}
 }
@@ -339,6 +433,9 @@ func (s *Script) resolvedRequest(ctx context.Context, req *requests.ResolvedRequ
 		return
 	}
 
+	// The names generated by the callback are derived from the resolved name
+	ctx = requests.WithDiscoveryParent(ctx, req.Name, req.Depth, requests.ProvenanceChain(req.Tag, req.Source, req.Provenance))
+
 	records := L.NewTable()
 	for _, rec := range req.Records {
 		tb := L.NewTable()
@@ -373,6 +470,9 @@ func (s *Script) subdomainRequest(ctx context.Context, req *requests.SubdomainRe
 		return
 	}
 
+	// The names generated by the callback are derived from the subdomain name
+	ctx = requests.WithDiscoveryParent(ctx, req.Name, req.Depth, requests.ProvenanceChain(req.Tag, req.Source, req.Provenance))
+
 	err = L.CallByParam(lua.P{
 		Fn:      s.cbs.Subdomain,
 		NRet:    0,
@@ -456,3 +556,27 @@ func (s *Script) whoisRequest(ctx context.Context, req *requests.WhoisRequest) {
 			fmt.Sprintf("%s: horizontal callback: %v", s.String(), err))
 	}
 }
+
+func (s *Script) orgRequest(ctx context.Context, req *requests.WhoisRequest) {
+	L := s.luaState
+
+	if err := checkContextExpired(ctx); err != nil {
+		return
+	}
+
+	_, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	err = L.CallByParam(lua.P{
+		Fn:      s.cbs.Org,
+		NRet:    0,
+		Protect: true,
+	}, s.contextToUserData(ctx), lua.LString(req.Company))
+
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("%s: organization callback: %v", s.String(), err))
+	}
+}
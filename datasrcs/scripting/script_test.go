@@ -5,6 +5,8 @@ package scripting
 
 import (
 	"context"
+	"fmt"
+	"testing"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/requests"
@@ -37,3 +39,63 @@ func newMockSystem(cfg *config.Config) systems.System {
 		ASNCache: requests.NewASNCache(),
 	}
 }
+
+func TestValidateCallback(t *testing.T) {
+	for _, passed := range []bool{true, false} {
+		script := `
+			name="validate"
+			type="testing"
+
+			function validate(ctx)
+				return ` + fmt.Sprintf("%t", passed) + `
+			end
+		`
+
+		sys := newMockSystem(config.NewConfig())
+		s := NewScript(script, sys)
+		if s == nil {
+			t.Fatal("Failed to load the script")
+		}
+
+		err := s.Start()
+		if passed && err != nil {
+			t.Errorf("The data source was disabled after the credentials were accepted: %v", err)
+		} else if !passed && err == nil {
+			t.Error("The data source was not disabled after the credentials were rejected")
+		}
+		_ = s.Stop()
+	}
+}
+
+func TestProbeCredentials(t *testing.T) {
+	for ret, want := range map[string]string{
+		"true":               requests.CredentialsValid,
+		"false":              requests.CredentialsInvalid,
+		"nil":                requests.CredentialsUnreachable,
+		"error(\"timeout\")": requests.CredentialsUnreachable,
+	} {
+		script := `
+			name="probe"
+			type="testing"
+
+			function validate(ctx)
+				return ` + ret + `
+			end
+		`
+
+		s := NewScript(script, newMockSystem(config.NewConfig()))
+		if s == nil {
+			t.Fatal("Failed to load the script")
+		}
+
+		// The credentials are probed without starting the data source
+		if status, _ := s.ProbeCredentials(context.Background()); status != want {
+			t.Errorf("The validate callback returning %s was reported as %s instead of %s", ret, status, want)
+		}
+	}
+
+	s := NewScript("name=\"none\"\ntype=\"testing\"", newMockSystem(config.NewConfig()))
+	if status, _ := s.ProbeCredentials(context.Background()); status != requests.CredentialsUnchecked {
+		t.Errorf("The script without the validate callback was reported as %s", status)
+	}
+}
@@ -5,10 +5,12 @@ package datasrcs
 
 import (
 	"context"
+	"io"
 	"sort"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs/scripting"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -20,11 +22,14 @@ import (
 func GetAllSources(sys systems.System) []service.Service {
 	srvs := []service.Service{
 		NewAlienVault(sys),
+		NewAlterationTemplates(sys),
 		NewCloudflare(sys),
+		NewCTTail(sys),
 		NewDNSDB(sys),
 		NewFOFA(sys),
 		NewNetworksDB(sys),
 		NewRADb(sys),
+		NewTokenExpansion(sys),
 		NewTwitter(sys),
 		NewUmbrella(sys),
 	}
@@ -82,10 +87,12 @@ func genNewNameEvent(ctx context.Context, sys systems.System, srv service.Servic
 
 	if domain := cfg.WhichDomain(name); domain != "" {
 		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-			Name:   name,
-			Domain: domain,
-			Tag:    srv.Description(),
-			Source: srv.String(),
+			Name:       name,
+			Domain:     domain,
+			Tag:        srv.Description(),
+			Source:     srv.String(),
+			Provenance: requests.ContextProvenance(ctx, srv.Description(), srv.String()),
+			Depth:      requests.ContextDepth(ctx),
 		})
 	}
 }
@@ -95,3 +102,25 @@ func numRateLimitChecks(srv service.Service, num int) {
 		srv.CheckRateLimit()
 	}
 }
+
+// checkSourceRateLimit blocks until the per minute rate limit configured for the data source permits another request.
+func checkSourceRateLimit(ctx context.Context, srv service.Service) {
+	if cfg, _, err := requests.ContextConfigBus(ctx); err == nil {
+		cfg.CheckSourceRateLimit(ctx, srv.String())
+	}
+}
+
+// requestWebPage sends the request for the data source once the per minute rate limit configured for it
+// permits another request, so each request sent by the data source is charged against the limit.
+func requestWebPage(ctx context.Context, srv service.Service, u string, body io.Reader, hvals map[string]string, auth *http.BasicAuth) (string, error) {
+	checkSourceRateLimit(ctx, srv)
+	return http.RequestWebPage(ctx, u, body, hvals, auth)
+}
+
+// withSourceHeaders returns the context carrying the HTTP headers configured for the data source.
+func withSourceHeaders(ctx context.Context, srv service.Service) context.Context {
+	if cfg, _, err := requests.ContextConfigBus(ctx); err == nil {
+		return http.WithHeaderProfile(ctx, cfg.HeaderProfile(srv.String()))
+	}
+	return ctx
+}
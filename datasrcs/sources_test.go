@@ -0,0 +1,45 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+)
+
+func TestRequestWebPageRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	cfg := config.NewConfig()
+	// Permit one request every 100 milliseconds
+	cfg.SetSourceRateLimit("Paginated", 600)
+	bus := eventbus.NewEventBus()
+	defer bus.Stop()
+
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+
+	// Each page requested for the same request of the enumeration is charged against the rate limit
+	src := newProbedSource("Paginated", requests.CredentialsValid)
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		if _, err := requestWebPage(ctx, src, fmt.Sprintf("%s/?page=%d", ts.URL, i), nil, nil, nil); err != nil {
+			t.Fatalf("The request for page %d failed: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("The rate limit was not honored, since three requests took %v", elapsed)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+	"github.com/caffix/stringset"
+)
+
+// AlterationTemplates is the Service that generates names from the naming conventions provided
+// by the alteration templates, using the tokens found in the resolved names that follow them.
+type AlterationTemplates struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	templates  []*config.AlterationTemplate
+	// The placeholder values learned for each domain and template
+	tokens map[string][]map[string][]string
+	filter *stringset.Set
+}
+
+// NewAlterationTemplates returns the object initialized, but not yet started.
+func NewAlterationTemplates(sys systems.System) *AlterationTemplates {
+	a := &AlterationTemplates{
+		SourceType: requests.ALT,
+		sys:        sys,
+		tokens:     make(map[string][]map[string][]string),
+	}
+
+	a.BaseService = *service.NewBaseService(a, "Alteration Templates")
+	return a
+}
+
+// Description implements the Service interface.
+func (a *AlterationTemplates) Description() string {
+	return a.SourceType
+}
+
+// OnStart implements the Service interface.
+func (a *AlterationTemplates) OnStart() error {
+	a.filter = stringset.New()
+
+	for _, tmpl := range a.sys.Config().AlterationTemplates {
+		t, err := config.ParseAlterationTemplate(tmpl)
+		if err != nil {
+			a.sys.Config().Leveled().Error(fmt.Sprintf("%s: %v", a.String(), err), config.SourceField(a.String()))
+			continue
+		}
+		a.templates = append(a.templates, t)
+	}
+	return nil
+}
+
+// OnStop implements the Service interface.
+func (a *AlterationTemplates) OnStop() error {
+	a.filter.Close()
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (a *AlterationTemplates) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.ResolvedRequest); ok && req != nil && len(req.Records) > 0 {
+		a.resolvedRequest(ctx, req)
+	}
+}
+
+func (a *AlterationTemplates) resolvedRequest(ctx context.Context, req *requests.ResolvedRequest) {
+	cfg, _, err := requests.ContextConfigBus(ctx)
+	if err != nil || cfg.Passive || len(a.templates) == 0 || !cfg.AlterationsFor(req.Name) {
+		return
+	}
+
+	name := strings.ToLower(req.Name)
+	domain := strings.ToLower(cfg.WhichDomain(name))
+	if domain == "" || !strings.HasSuffix(name, "."+domain) {
+		return
+	}
+
+	limit := cfg.AlterationTemplateLimit
+	if limit == 0 {
+		limit = config.DefaultAlterationTemplateLimit
+	}
+
+	// The generated names are derived from the resolved name
+	ctx = requests.WithDiscoveryParent(ctx, req.Name, req.Depth, requests.ProvenanceChain(req.Tag, req.Source, req.Provenance))
+
+	labels := strings.TrimSuffix(name, "."+domain)
+	for _, n := range a.expand(domain, labels, limit) {
+		genNewNameEvent(ctx, a.sys, a, n+"."+domain)
+	}
+}
+
+// expand learns the tokens of the labels and returns the new labels that combine them with the
+// tokens learned from previous names. No more than limit labels are returned.
+func (a *AlterationTemplates) expand(domain, labels string, limit int) []string {
+	learned, found := a.tokens[domain]
+	if !found {
+		learned = make([]map[string][]string, len(a.templates))
+		for i := range learned {
+			learned[i] = make(map[string][]string)
+		}
+		a.tokens[domain] = learned
+	}
+	a.filter.Insert(labels + "." + domain)
+
+	var results []string
+	for i, t := range a.templates {
+		values := t.Match(labels)
+		if values == nil {
+			continue
+		}
+
+		added := make(map[string]string)
+		for ph, v := range values {
+			if !containsToken(learned[i][ph], v) {
+				learned[i][ph] = append(learned[i][ph], v)
+				added[ph] = v
+			}
+		}
+		// Only the combinations that include a new token can produce new labels
+		for ph, v := range added {
+			tokens := make(map[string][]string, len(learned[i]))
+			for k, list := range learned[i] {
+				tokens[k] = list
+			}
+			tokens[ph] = []string{v}
+
+			for _, l := range combineTokens(t, tokens, limit-len(results)) {
+				if n := l + "." + domain; !a.filter.Has(n) {
+					a.filter.Insert(n)
+					results = append(results, l)
+				}
+			}
+			if len(results) >= limit {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// combineTokens returns up to limit labels built from the template with every combination of the tokens.
+func combineTokens(t *config.AlterationTemplate, tokens map[string][]string, limit int) []string {
+	phs := t.Placeholders()
+	idx := make([]int, len(phs))
+	values := make(map[string]string, len(phs))
+
+	var results []string
+	for len(results) < limit {
+		for i, ph := range phs {
+			if len(tokens[ph]) == 0 {
+				return results
+			}
+			values[ph] = tokens[ph][idx[i]]
+		}
+		results = append(results, t.Expand(values))
+
+		// Advance to the next combination of tokens
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(tokens[phs[i]]) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			break
+		}
+	}
+	return results
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/stringset"
+)
+
+func TestAlterationTemplatesExpand(t *testing.T) {
+	tmpl, err := config.ParseAlterationTemplate("{app}-{env}")
+	if err != nil {
+		t.Fatalf("Failed to parse the alteration template: %v", err)
+	}
+
+	a := &AlterationTemplates{
+		templates: []*config.AlterationTemplate{tmpl},
+		tokens:    make(map[string][]map[string][]string),
+		filter:    stringset.New(),
+	}
+	defer a.filter.Close()
+
+	if got := a.expand("owasp.org", "billing-prod", 10); len(got) != 0 {
+		t.Errorf("the first name following the template generated %v", got)
+	}
+	if got := a.expand("owasp.org", "www", 10); len(got) != 0 {
+		t.Errorf("a name not following the template generated %v", got)
+	}
+
+	got := a.expand("owasp.org", "auth-dev", 10)
+	sort.Strings(got)
+	if len(got) != 2 || got[0] != "auth-prod" || got[1] != "billing-dev" {
+		t.Errorf("the tokens of the two names generated %v", got)
+	}
+	if got := a.expand("owasp.org", "auth-dev", 10); len(got) != 0 {
+		t.Errorf("a name already processed generated %v", got)
+	}
+	if got := a.expand("owasp.org", "api-stage", 1); len(got) != 1 {
+		t.Errorf("the limit was not enforced on the labels %v", got)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+	"github.com/caffix/stringset"
+)
+
+// TokenExpansion is the Service that learns the tokens of the resolved names, such as api and prod
+// from api-prod, and brute forces the names combining the new tokens with those learned before.
+type TokenExpansion struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	// The tokens learned for each domain, in the order they were found
+	tokens    map[string][]string
+	generated int
+	filter    *stringset.Set
+}
+
+// NewTokenExpansion returns the object initialized, but not yet started.
+func NewTokenExpansion(sys systems.System) *TokenExpansion {
+	te := &TokenExpansion{
+		SourceType: requests.BRUTE,
+		sys:        sys,
+		tokens:     make(map[string][]string),
+	}
+
+	te.BaseService = *service.NewBaseService(te, "Token Expansion")
+	return te
+}
+
+// Description implements the Service interface.
+func (te *TokenExpansion) Description() string {
+	return te.SourceType
+}
+
+// OnStart implements the Service interface.
+func (te *TokenExpansion) OnStart() error {
+	te.filter = stringset.New()
+	return nil
+}
+
+// OnStop implements the Service interface.
+func (te *TokenExpansion) OnStop() error {
+	te.filter.Close()
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (te *TokenExpansion) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.ResolvedRequest); ok && req != nil && len(req.Records) > 0 {
+		te.resolvedRequest(ctx, req)
+	}
+}
+
+func (te *TokenExpansion) resolvedRequest(ctx context.Context, req *requests.ResolvedRequest) {
+	cfg, _, err := requests.ContextConfigBus(ctx)
+	if err != nil || cfg.Passive || !cfg.TokenExpansion || !cfg.BruteForcingFor(req.Name) {
+		return
+	}
+
+	name := strings.ToLower(req.Name)
+	domain := strings.ToLower(cfg.WhichDomain(name))
+	if domain == "" || !strings.HasSuffix(name, "."+domain) {
+		return
+	}
+
+	limit := cfg.TokenExpansionLimit
+	if limit == 0 {
+		limit = config.DefaultTokenExpansionLimit
+	}
+	if limit -= te.generated; limit <= 0 {
+		return
+	}
+
+	// The generated names are derived from the resolved name
+	ctx = requests.WithDiscoveryParent(ctx, req.Name, req.Depth, requests.ProvenanceChain(req.Tag, req.Source, req.Provenance))
+
+	for _, n := range te.expand(domain, strings.TrimSuffix(name, "."+domain), limit) {
+		te.generated++
+		genNewNameEvent(ctx, te.sys, te, n)
+	}
+}
+
+// expand learns the tokens of the labels and returns the names, under the parent of the first label,
+// made of each new token of the first label alone and joined with the other tokens learned for the
+// domain. No more than limit names are returned, and the names already generated or resolved are skipped.
+func (te *TokenExpansion) expand(domain, labels string, limit int) []string {
+	te.filter.Insert(labels + "." + domain)
+
+	first, parent := labels, domain
+	if parts := strings.SplitN(labels, ".", 2); len(parts) == 2 {
+		first, parent = parts[0], parts[1]+"."+domain
+	}
+
+	var added []string
+	for _, t := range labelTokens(labels) {
+		if !containsToken(te.tokens[domain], t) {
+			te.tokens[domain] = append(te.tokens[domain], t)
+			// The tokens of the parent labels are learned for the names found in other positions
+			if containsToken(labelTokens(first), t) {
+				added = append(added, t)
+			}
+		}
+	}
+
+	var results []string
+	// Returns false once the limit has been reached
+	try := func(label string) bool {
+		if n := label + "." + parent; !te.filter.Has(n) {
+			te.filter.Insert(n)
+			results = append(results, n)
+		}
+		return len(results) < limit
+	}
+
+	for _, t := range added {
+		if !try(t) {
+			return results
+		}
+
+		for _, other := range te.tokens[domain] {
+			if other == t {
+				continue
+			}
+			if !try(t+"-"+other) || !try(other+"-"+t) {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// labelTokens returns the distinct tokens separated by dots, hyphens and underscores in the labels.
+// The single characters and numbers are skipped, since they rarely carry the naming conventions.
+func labelTokens(labels string) []string {
+	var tokens []string
+
+	for _, t := range strings.FieldsFunc(labels, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	}) {
+		if len(t) < 2 || strings.Trim(t, "0123456789") == "" || containsToken(tokens, t) {
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
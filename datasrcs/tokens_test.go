@@ -0,0 +1,40 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/caffix/stringset"
+)
+
+func TestTokenExpansionExpand(t *testing.T) {
+	te := &TokenExpansion{
+		tokens: make(map[string][]string),
+		filter: stringset.New(),
+	}
+	defer te.filter.Close()
+
+	got := te.expand("owasp.org", "api-prod", 10)
+	sort.Strings(got)
+	if len(got) != 3 || got[0] != "api.owasp.org" || got[1] != "prod-api.owasp.org" || got[2] != "prod.owasp.org" {
+		t.Errorf("the tokens of the first name generated %v", got)
+	}
+
+	got = te.expand("owasp.org", "billing.dev", 10)
+	sort.Strings(got)
+	if len(got) != 6 || got[0] != "api-billing.dev.owasp.org" || got[5] != "prod-billing.dev.owasp.org" {
+		t.Errorf("the new token was not combined with the learned tokens under the parent: %v", got)
+	}
+	if got := te.expand("owasp.org", "api-prod", 10); len(got) != 0 {
+		t.Errorf("a name already processed generated %v", got)
+	}
+	if got := te.expand("owasp.org", "auth-01", 2); len(got) != 2 {
+		t.Errorf("the limit was not enforced on the names %v", got)
+	}
+	if got := te.tokens["owasp.org"]; len(got) != 5 {
+		t.Errorf("the numbers were learned as tokens: %v", got)
+	}
+}
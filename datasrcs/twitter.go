@@ -51,13 +51,14 @@ func (t *Twitter) OnStart() error {
 	t.creds = t.sys.Config().GetDataSourceConfig(t.String()).GetCredentials()
 
 	if t.creds == nil || t.creds.Key == "" || t.creds.Secret == "" {
-		t.sys.Config().Log.Printf("%s: API key data was not provided", t.String())
+		t.sys.Config().Leveled().Warn(fmt.Sprintf("%s: API key data was not provided", t.String()), config.SourceField(t.String()))
 	} else {
 		if bearer, err := t.getBearerToken(); err == nil {
 			config := &oauth2.Config{}
 			token := &oauth2.Token{AccessToken: bearer}
 			// OAuth2 http.Client will automatically authorize Requests
-			httpClient := config.Client(context.Background(), token)
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, t.sys.Config().ProxyClient())
+			httpClient := config.Client(ctx, token)
 			// Twitter client
 			t.client = twitter.NewClient(httpClient)
 		}
@@ -73,7 +74,7 @@ func (t *Twitter) checkConfig() error {
 
 	if creds == nil || creds.Key == "" || creds.Secret == "" {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", t.String())
-		t.sys.Config().Log.Print(estr)
+		t.sys.Config().Leveled().Error(estr, config.SourceField(t.String()))
 		return errors.New(estr)
 	}
 
@@ -82,6 +83,8 @@ func (t *Twitter) checkConfig() error {
 
 // OnRequest implements the Service interface.
 func (t *Twitter) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, t)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		t.dnsRequest(ctx, req)
 		t.CheckRateLimit()
@@ -107,6 +110,7 @@ func (t *Twitter) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		Query: req.Domain,
 		Count: 100,
 	}
+	checkSourceRateLimit(ctx, t)
 	search, _, err := t.client.Search.Tweets(searchParams)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", t.String(), err))
@@ -128,14 +132,30 @@ func (t *Twitter) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 }
 
-func (t *Twitter) getBearerToken() (string, error) {
+// ProbeCredentials requests a bearer token with the API key and secret, without searching the tweets.
+func (t *Twitter) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := t.sys.Config().GetDataSourceConfig(t.String()).GetCredentials()
+	if creds == nil || creds.Key == "" || creds.Secret == "" {
+		return requests.CredentialsInvalid, errors.New("the API key and secret were not provided")
+	}
+
+	_, err := t.requestToken(ctx, creds)
+	return credentialStatus(err), err
+}
+
+func (t *Twitter) requestToken(ctx context.Context, creds *config.Credentials) (string, error) {
 	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded;charset=UTF-8"}
-	page, err := http.RequestWebPage(context.Background(), "https://api.twitter.com/oauth2/token",
+
+	return http.RequestWebPage(ctx, "https://api.twitter.com/oauth2/token",
 		strings.NewReader("grant_type=client_credentials"), headers,
 		&http.BasicAuth{
-			Username: t.creds.Key,
-			Password: t.creds.Secret,
+			Username: creds.Key,
+			Password: creds.Secret,
 		})
+}
+
+func (t *Twitter) getBearerToken() (string, error) {
+	page, err := t.requestToken(t.sys.Config().ProxyContext(context.Background()), t.creds)
 	if err != nil {
 		return "", fmt.Errorf("token request failed: %+v", err)
 	}
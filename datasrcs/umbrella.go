@@ -52,7 +52,7 @@ func (u *Umbrella) OnStart() error {
 	u.creds = u.sys.Config().GetDataSourceConfig(u.String()).GetCredentials()
 
 	if u.creds == nil || u.creds.Key == "" {
-		u.sys.Config().Log.Printf("%s: API key data was not provided", u.String())
+		u.sys.Config().Leveled().Warn(fmt.Sprintf("%s: API key data was not provided", u.String()), config.SourceField(u.String()))
 	}
 
 	u.SetRateLimit(2)
@@ -65,15 +65,29 @@ func (u *Umbrella) checkConfig() error {
 
 	if creds == nil || creds.Key == "" {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", u.String())
-		u.sys.Config().Log.Print(estr)
+		u.sys.Config().Leveled().Error(estr, config.SourceField(u.String()))
 		return errors.New(estr)
 	}
 
 	return nil
 }
 
+// ProbeCredentials requests the list of the domain categories, which does not query for any domain names.
+func (u *Umbrella) ProbeCredentials(ctx context.Context) (string, error) {
+	creds := u.sys.Config().GetDataSourceConfig(u.String()).GetCredentials()
+	if creds == nil || creds.Key == "" {
+		return requests.CredentialsInvalid, errors.New("the API key was not provided")
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + creds.Key}
+	_, err := http.RequestWebPage(ctx, "https://investigate.api.umbrella.com/domains/categories", nil, headers, nil)
+	return credentialStatus(err), err
+}
+
 // OnRequest implements the Service interface.
 func (u *Umbrella) OnRequest(ctx context.Context, args service.Args) {
+	ctx = withSourceHeaders(ctx, u)
+
 	check := true
 
 	switch req := args.(type) {
@@ -111,7 +125,7 @@ func (u *Umbrella) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	headers := u.restHeaders()
 	url := u.restDNSURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, u, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -145,7 +159,7 @@ func (u *Umbrella) addrRequest(ctx context.Context, req *requests.AddrRequest) {
 
 	headers := u.restHeaders()
 	url := u.restAddrURL(req.Address)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, u, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -190,7 +204,7 @@ func (u *Umbrella) executeASNAddrQuery(ctx context.Context, req *requests.ASNReq
 
 	headers := u.restHeaders()
 	url := u.restAddrToASNURL(req.Address)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, u, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -252,7 +266,7 @@ func (u *Umbrella) executeASNQuery(ctx context.Context, req *requests.ASNRequest
 
 	headers := u.restHeaders()
 	url := u.restASNToCIDRsURL(req.ASN)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, u, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -355,7 +369,7 @@ func (u *Umbrella) queryWhois(ctx context.Context, domain string) *whoisRecord {
 	whoisURL := u.whoisRecordURL(domain)
 
 	u.CheckRateLimit()
-	record, err := http.RequestWebPage(ctx, whoisURL, nil, headers, nil)
+	record, err := requestWebPage(ctx, u, whoisURL, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), whoisURL, err))
 		return nil
@@ -385,7 +399,7 @@ func (u *Umbrella) queryReverseWhois(ctx context.Context, apiURL string) []strin
 	for count, more := 0, true; more; count = count + 500 {
 		u.CheckRateLimit()
 		fullAPIURL := fmt.Sprintf("%s&offset=%d", apiURL, count)
-		record, err := http.RequestWebPage(ctx, fullAPIURL, nil, headers, nil)
+		record, err := requestWebPage(ctx, u, fullAPIURL, nil, headers, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), apiURL, err))
 			return domains.Slice()
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/OWASP/Amass/v3/config"
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
@@ -76,11 +77,17 @@ func (a *activeTask) Process(ctx context.Context, data pipeline.Data, tp pipelin
 	}
 
 	if ok {
-		a.queue.Append(&taskArgs{
+		priority := queue.PriorityNormal
+		// Interesting names are verified ahead of the others on large result sets
+		if req, isName := data.(*requests.DNSRequest); isName && a.enum.NameScore(req.Name) >= priorityScore {
+			priority = queue.PriorityHigh
+		}
+
+		a.queue.AppendPriority(&taskArgs{
 			Ctx:    ctx,
 			Data:   data.Clone(),
 			Params: tp,
-		})
+		}, priority)
 	}
 
 	return data, nil
@@ -155,7 +162,7 @@ func (a *activeTask) crawlName(ctx context.Context, req *requests.DNSRequest, tp
 		names, err := http.Crawl(ctx, u, cfg.Domains(), 50, a.enum.crawlFilter)
 		if err != nil {
 			if cfg.Verbose {
-				cfg.Log.Printf("Active Crawl: %v", err)
+				cfg.Leveled().Debug(fmt.Sprintf("Active Crawl: %v", err), config.SourceField("Active Crawl"))
 			}
 			continue
 		}
@@ -182,24 +189,36 @@ func (a *activeTask) certEnumeration(ctx context.Context, req *requests.AddrRequ
 		return
 	}
 
-	for _, name := range http.PullCertificateNames(ctx, req.Address, a.enum.Config.Ports) {
-		select {
-		case <-ctx.Done():
-			return
-		default:
+	for _, cert := range http.PullCertificates(ctx, req.Address, a.enum.Config.Ports) {
+		names := http.NamesFromCert(cert)
+		// The certificates issued to the organizations in scope bring the domains they name into scope
+		for _, domain := range a.enum.Config.CertOrgDomains(http.CertOrganizations(cert), names) {
+			a.enum.addScope(domain, "Certificate organization")
 		}
 
-		if n := strings.TrimSpace(name); n != "" {
-			if domain := a.enum.Config.WhichDomain(n); domain != "" {
-				a.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-					Name:   n,
-					Domain: domain,
-					Tag:    requests.CERT,
-					Source: "Active Cert",
-				}, tp)
+		for _, name := range names {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if n := strings.TrimSpace(name); n != "" {
+				if domain := a.enum.Config.WhichDomain(n); domain != "" {
+					a.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
+						Name:   n,
+						Domain: domain,
+						Tag:    requests.CERT,
+						Source: "Active Cert",
+					}, tp)
+				}
 			}
 		}
 	}
+
+	if a.enum.Config.VHostBrute {
+		a.vhostBrute(ctx, req, tp)
+	}
 }
 
 func (a *activeTask) zoneTransfer(ctx context.Context, req *requests.ZoneXFRRequest, tp pipeline.TaskParams) {
@@ -220,13 +239,45 @@ func (a *activeTask) zoneTransfer(ctx context.Context, req *requests.ZoneXFRRequ
 		return
 	}
 
-	reqs, err := ZoneTransfer(req.Name, req.Domain, addr)
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("DNS: Zone XFR failed: %s: %v", req.Server, err))
+	timeout := a.enum.Config.ZoneTransferTimeout
+	if timeout <= 0 {
+		timeout = defaultZoneTransferTimeout
+	}
+	// The incremental transfer is only attempted when the server refuses the full transfer
+	for _, qtype := range []uint16{dns.TypeAXFR, dns.TypeIXFR} {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		xtype := dns.TypeToString[qtype]
+		reqs, partial, err := transferZone(ctx, req.Name, req.Domain, addr, qtype, timeout)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("DNS: Zone %s failed: %s: %v", xtype, req.Server, err))
+			continue
+		}
+
+		msg := fmt.Sprintf("DNS: Zone %s permitted by %s (%s) for %s: %d names", xtype, req.Server, addr, req.Name, len(reqs))
+		if partial {
+			msg += ", the transfer ended before the complete zone was received"
+		}
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+		a.enum.addZoneTransfer(&ZoneTransferResult{
+			Zone:    req.Name,
+			Server:  req.Server,
+			Address: addr,
+			Type:    xtype,
+			Names:   len(reqs),
+			Partial: partial,
+		})
+		a.sendZoneRequests(ctx, reqs, tp)
 		return
 	}
+}
 
+func (a *activeTask) sendZoneRequests(ctx context.Context, reqs []*requests.DNSRequest, tp pipeline.TaskParams) {
 	for _, req := range reqs {
 		// Zone Transfers can reveal DNS wildcards
 		if name := amassdns.RemoveAsteriskLabel(req.Name); len(name) < len(req.Name) {
@@ -247,7 +298,7 @@ func (a *activeTask) zoneTransfer(ctx context.Context, req *requests.ZoneXFRRequ
 func (a *activeTask) zoneWalk(ctx context.Context, req *requests.ZoneXFRRequest, tp pipeline.TaskParams) {
 	defer func() { a.tokenPool <- struct{}{} }()
 
-	cfg, bus, err := requests.ContextConfigBus(ctx)
+	_, bus, err := requests.ContextConfigBus(ctx)
 	if err != nil {
 		return
 	}
@@ -270,19 +321,66 @@ func (a *activeTask) zoneWalk(ctx context.Context, req *requests.ZoneXFRRequest,
 			fmt.Sprintf("DNS: Zone Walk failed: %s: %v", req.Name, err))
 		return
 	}
+	// Zones signed with NSEC3 cannot be walked, but the hashes can be cracked
+	if len(names) == 0 {
+		a.nsec3Walk(ctx, r, req, tp)
+		return
+	}
 
 	for _, nsec := range names {
-		name := resolve.RemoveLastDot(nsec.NextDomain)
+		a.zoneWalkName(ctx, resolve.RemoveLastDot(nsec.NextDomain), tp)
+	}
+}
 
-		if domain := cfg.WhichDomain(name); domain != "" {
-			a.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-				Name:   name,
-				Domain: domain,
-				Tag:    requests.DNS,
-				Source: "NSEC Walk",
-			}, tp)
+func (a *activeTask) nsec3Walk(ctx context.Context, r resolve.Resolver, req *requests.ZoneXFRRequest, tp pipeline.TaskParams) {
+	cfg, bus, err := requests.ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	z := collectNSEC3(ctx, r, req.Name)
+	if z == nil {
+		return
+	}
+	defer z.Hashes.Close()
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("DNS: Zone Walk: %s: collected %d NSEC3 hashes (salt: %s, iterations: %d)",
+		req.Name, z.Hashes.Len(), z.Salt, z.Iterations))
+	if path := cfg.NSEC3HashesPath; path != "" {
+		if err := saveNSEC3Hashes(path, z); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("DNS: Zone Walk: failed to save the NSEC3 hashes to %s: %v", path, err))
 		}
 	}
+
+	for _, name := range z.crack(cfg.WordlistFor(req.Name)) {
+		a.zoneWalkName(ctx, name, tp)
+	}
+}
+
+// zoneWalkName submits the in-scope name discovered while walking a zone.
+func (a *activeTask) zoneWalkName(ctx context.Context, name string, tp pipeline.TaskParams) {
+	domain := a.enum.Config.WhichDomain(name)
+	if domain == "" {
+		return
+	}
+	// Zone walking can reveal DNS wildcards
+	if base := amassdns.RemoveAsteriskLabel(name); len(base) < len(name) {
+		pipeline.SendData(ctx, "dns", &requests.DNSRequest{
+			Name:   "www." + base,
+			Domain: domain,
+			Tag:    requests.DNS,
+			Source: "DNS",
+		}, tp)
+		return
+	}
+
+	a.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
+		Name:   name,
+		Domain: domain,
+		Tag:    requests.DNS,
+		Source: "NSEC Walk",
+	}, tp)
 }
 
 func (a *activeTask) nameserverAddr(ctx context.Context, server string) (string, error) {
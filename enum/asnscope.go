@@ -0,0 +1,113 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+)
+
+// The largest number of reverse DNS sweeps performed across a prefix brought into scope by its ASN
+const maxASNSweepBlocks = 256
+
+// expandByASN brings the prefixes announced by the ASN into scope when the configuration
+// allowlists the ASN, and requests the remaining prefixes from the data sources.
+func (e *Enumeration) expandByASN(ctx context.Context, asn int) {
+	if !e.Config.IsASNExpandable(asn) {
+		return
+	}
+
+	e.expandLock.Lock()
+	if _, found := e.expanded[asn]; found {
+		e.expandLock.Unlock()
+		return
+	}
+	e.expanded[asn] = struct{}{}
+	e.expandLock.Unlock()
+
+	e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("ASN expansion: The prefixes announced by AS%d are being brought into scope", asn))
+	if as := e.Sys.Cache().ASNSearch(asn); as != nil {
+		e.addASNPrefixes(as)
+	}
+	// The RDAP and BGP data sources provide the remaining prefixes announced by the ASN
+	for _, src := range e.activeSources() {
+		src.Request(ctx, &requests.ASNRequest{ASN: asn})
+	}
+}
+
+func (e *Enumeration) asnExpanded(asn int) bool {
+	e.expandLock.Lock()
+	defer e.expandLock.Unlock()
+
+	_, found := e.expanded[asn]
+	return found
+}
+
+// asnScopeUpdate receives the ASN information published by the data sources.
+func (e *Enumeration) asnScopeUpdate(req *requests.ASNRequest) {
+	if req != nil && e.asnExpanded(req.ASN) {
+		e.addASNPrefixes(req)
+	}
+}
+
+// addASNPrefixes adds the prefixes of the ASN to the CIDRs in scope and sweeps the newly added prefixes.
+func (e *Enumeration) addASNPrefixes(req *requests.ASNRequest) {
+	for _, prefix := range append([]string{req.Prefix}, req.Netblocks...) {
+		_, cidr, err := net.ParseCIDR(prefix)
+		if err != nil || !e.Config.AddScopeCIDR(cidr) {
+			continue
+		}
+
+		if src := e.source(); src != nil {
+			src.queueNetblockSweeps(cidr)
+		}
+	}
+}
+
+// queueNetblockSweeps schedules the reverse DNS sweeps across the prefix, split into netblocks of the maximum sweep size.
+func (r *enumSource) queueNetblockSweeps(cidr *net.IPNet) {
+	ones, bits := cidr.Mask.Size()
+	if !r.enum.Config.IsAddressFamilyAllowed(cidr.IP.String()) {
+		return
+	}
+
+	max := r.enum.Config.ReverseDNSPrefixLen(bits == 128)
+	if ones >= max {
+		r.queueSweepBlock(cidr)
+		return
+	}
+
+	num := maxASNSweepBlocks
+	if diff := max - ones; diff < 8 {
+		num = 1 << uint(diff)
+	} else if diff > 8 {
+		r.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityLow,
+			fmt.Sprintf("ASN expansion: Only the first %d netblocks of %s are swept", maxASNSweepBlocks, cidr))
+	}
+
+	mask := net.CIDRMask(max, bits)
+	base := new(big.Int).SetBytes(cidr.IP.Mask(cidr.Mask))
+	for i := 0; i < num; i++ {
+		offset := new(big.Int).Lsh(big.NewInt(int64(i)), uint(bits-max))
+
+		ip := make(net.IP, bits/8)
+		new(big.Int).Add(base, offset).FillBytes(ip)
+		r.queueSweepBlock(&net.IPNet{IP: ip, Mask: mask})
+	}
+}
+
+func (r *enumSource) queueSweepBlock(cidr *net.IPNet) {
+	if r.rdnsFilter.Has(cidr.String()) {
+		return
+	}
+
+	r.rdnsFilter.Insert(cidr.String())
+	r.rdnsSweeps.Append(cidr)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"net"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/queue"
+	"github.com/caffix/stringset"
+)
+
+func TestAddASNPrefixesSweepsNetblocks(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ExpandByASN = true
+	cfg.ExpandASNs = []int{26808}
+	cfg.ReverseDNSMaxPrefix = 24
+
+	r := newTestEnumSource(cfg)
+	r.rdnsSweeps = queue.NewQueue()
+	r.rdnsFilter = stringset.New()
+	defer r.rdnsFilter.Close()
+	r.enum.nameSrc = r
+	r.enum.Bus = eventbus.NewEventBus()
+	defer r.enum.Bus.Stop()
+	r.enum.expanded = map[int]struct{}{26808: {}}
+
+	r.enum.asnScopeUpdate(&requests.ASNRequest{
+		ASN:       26808,
+		Prefix:    "192.0.8.0/22",
+		Netblocks: []string{"192.0.8.0/22", "198.51.100.0/24"},
+	})
+	// The prefixes announced by other ASNs are not brought into scope
+	r.enum.asnScopeUpdate(&requests.ASNRequest{ASN: 13335, Prefix: "203.0.113.0/24"})
+
+	if got := len(cfg.CIDRs); got != 2 {
+		t.Errorf("Expected 2 prefixes in scope, got %d", got)
+	}
+
+	expected := map[string]bool{
+		"192.0.8.0/24":    true,
+		"192.0.9.0/24":    true,
+		"192.0.10.0/24":   true,
+		"192.0.11.0/24":   true,
+		"198.51.100.0/24": true,
+	}
+	if got := r.rdnsSweeps.Len(); got != len(expected) {
+		t.Errorf("Expected %d netblocks to be swept, got %d", len(expected), got)
+	}
+	for {
+		e, ok := r.rdnsSweeps.Next()
+		if !ok {
+			break
+		}
+		if cidr := e.(*net.IPNet); !expected[cidr.String()] {
+			t.Errorf("The netblock %s was not expected to be swept", cidr)
+		}
+	}
+}
+
+func TestQueueNetblockSweepsLimit(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ReverseDNSMaxPrefix = 24
+
+	r := newTestEnumSource(cfg)
+	r.rdnsSweeps = queue.NewQueue()
+	r.rdnsFilter = stringset.New()
+	defer r.rdnsFilter.Close()
+	r.enum.Bus = eventbus.NewEventBus()
+	defer r.enum.Bus.Stop()
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/8")
+	r.queueNetblockSweeps(cidr)
+	if got := r.rdnsSweeps.Len(); got != maxASNSweepBlocks {
+		t.Errorf("Expected %d netblocks to be swept, got %d", maxASNSweepBlocks, got)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The fewest brute forcing names resolved before the pacing rate is adjusted
+	brutePaceWindow = 50
+	// The fraction of names with lost queries in a window that slows the brute forcing down
+	bruteLossThreshold = 0.05
+	// The factor applied to the rate when the losses rise
+	bruteBackoff = 0.5
+	// The fraction of the ceiling regained after each window with few losses
+	bruteRecovery = 0.1
+	// The rate is never lowered below one name per second
+	bruteMinRate = 1.0
+	// The times a brute forcing name is resolved again before it is considered nonexistent
+	bruteRetries = 2
+)
+
+// reserveBruteCandidate returns true when another brute forcing name can be attempted within the
+// MaxBruteCandidates budget. The first rejection is logged, since the coverage is then partial.
+func (e *Enumeration) reserveBruteCandidate() bool {
+	max := e.Config.MaxBruteCandidates
+	if max <= 0 {
+		return true
+	}
+
+	e.bruteLock.Lock()
+	defer e.bruteLock.Unlock()
+
+	if e.bruteCount < max {
+		e.bruteCount++
+		return true
+	}
+	if !e.bruteCapped {
+		e.bruteCapped = true
+		e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("Brute forcing: The limit of %d candidates was reached, so coverage is partial", max))
+	}
+	return false
+}
+
+// releaseBruteCandidate returns a reservation for a brute forcing name that was not attempted.
+func (e *Enumeration) releaseBruteCandidate() {
+	if e.Config.MaxBruteCandidates <= 0 {
+		return
+	}
+
+	e.bruteLock.Lock()
+	defer e.bruteLock.Unlock()
+
+	if e.bruteCount > 0 {
+		e.bruteCount--
+	}
+}
+
+// BruteRateStats provides the current state of the adaptive brute forcing pacing.
+type BruteRateStats struct {
+	// The brute forcing names resolved per second
+	Rate float64
+	// The ceiling provided by the BruteMaxRate configuration
+	Max int
+	// The fraction of names with lost queries during the last window
+	LossRate float64
+}
+
+// BruteRate returns the state of the brute forcing pacing, or nil when the names are not paced.
+func (e *Enumeration) BruteRate() *BruteRateStats {
+	return e.brutePace.stats()
+}
+
+// brutePacer spaces out the resolution of the brute forcing names. The rate starts at the ceiling,
+// is halved for each window in which the queries for too many names were lost to timeouts, SERVFAIL
+// or REFUSED responses, which is how resolvers enforce their rate limits, and recovers gradually
+// once the losses subside.
+type brutePacer struct {
+	sync.Mutex
+	max      float64
+	rate     float64
+	next     time.Time
+	count    int
+	lost     int
+	lastLoss float64
+}
+
+func newBrutePacer(max int) *brutePacer {
+	if max <= 0 {
+		return nil
+	}
+
+	return &brutePacer{
+		max:  float64(max),
+		rate: float64(max),
+	}
+}
+
+// wait blocks until the next brute forcing name can be resolved at the current rate. False is
+// returned when the context expires first.
+func (p *brutePacer) wait(ctx context.Context) bool {
+	if p == nil {
+		return true
+	}
+
+	p.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	delay := p.next.Sub(now)
+	p.next = p.next.Add(time.Duration(float64(time.Second) / p.rate))
+	p.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+	}
+	return true
+}
+
+// record adds the outcome of a brute forcing name and adjusts the rate at the end of each window.
+func (p *brutePacer) record(lost bool) {
+	if p == nil {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.count++
+	if lost {
+		p.lost++
+	}
+	if p.count < brutePaceWindow {
+		return
+	}
+
+	p.lastLoss = float64(p.lost) / float64(p.count)
+	if p.lastLoss > bruteLossThreshold {
+		p.rate *= bruteBackoff
+	} else {
+		p.rate += p.max * bruteRecovery
+	}
+
+	if p.rate < bruteMinRate {
+		p.rate = bruteMinRate
+	}
+	if p.rate > p.max {
+		p.rate = p.max
+	}
+	p.count, p.lost = 0, 0
+}
+
+// slowed returns true while the rate is below the ceiling, since the resolvers have recently been
+// losing queries and the NXDOMAIN responses received meanwhile may have been caused by the limits.
+func (p *brutePacer) slowed() bool {
+	if p == nil {
+		return false
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	return p.rate < p.max
+}
+
+func (p *brutePacer) stats() *BruteRateStats {
+	if p == nil {
+		return nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	return &BruteRateStats{
+		Rate:     p.rate,
+		Max:      int(p.max),
+		LossRate: p.lastLoss,
+	}
+}
+
+// lostQuery returns true for the resolver errors of the queries that did not receive an answer
+// from the servers, as opposed to the names that do not exist.
+func lostQuery(err error) bool {
+	rerr, ok := err.(*resolve.ResolveError)
+	if !ok {
+		return false
+	}
+
+	switch rerr.Rcode {
+	case resolve.TimeoutRcode, resolve.ResolverErrRcode, dns.RcodeServerFailure, dns.RcodeRefused:
+		return true
+	}
+	return false
+}
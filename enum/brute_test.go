@@ -0,0 +1,161 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+func TestBruteCandidateBudget(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.MaxBruteCandidates = 2
+
+	e := &Enumeration{
+		Config: cfg,
+		Bus:    eventbus.NewEventBus(),
+	}
+	defer e.Bus.Stop()
+
+	if !e.reserveBruteCandidate() || !e.reserveBruteCandidate() {
+		t.Fatal("the candidates within the budget were not permitted")
+	}
+	if e.reserveBruteCandidate() {
+		t.Error("a candidate beyond the budget was permitted")
+	}
+	if !e.bruteCapped {
+		t.Error("reaching the budget was not recorded")
+	}
+
+	// A duplicate name gives back its place in the budget
+	e.releaseBruteCandidate()
+	if !e.reserveBruteCandidate() {
+		t.Error("the candidate released from the budget was not available again")
+	}
+
+	cfg.MaxBruteCandidates = 0
+	for i := 0; i < 10; i++ {
+		if !e.reserveBruteCandidate() {
+			t.Fatal("a candidate was rejected without a budget")
+		}
+	}
+}
+
+func TestBrutePacerAdaptsToLoss(t *testing.T) {
+	if newBrutePacer(0) != nil {
+		t.Error("a pacer was returned without a rate ceiling")
+	}
+
+	p := newBrutePacer(100)
+	for i := 0; i < brutePaceWindow; i++ {
+		p.record(i%5 == 0)
+	}
+	if st := p.stats(); st.Rate != 50 || st.LossRate != 0.2 {
+		t.Errorf("the rate was not lowered when the losses rose: %+v", st)
+	}
+	if !p.slowed() {
+		t.Error("the pacer did not report being slowed")
+	}
+
+	for i := 0; i < 10*brutePaceWindow; i++ {
+		p.record(false)
+	}
+	if st := p.stats(); st.Rate != 100 || st.Max != 100 {
+		t.Errorf("the rate did not recover to the ceiling once the losses subsided: %+v", st)
+	}
+	if p.slowed() {
+		t.Error("the pacer reported being slowed at the ceiling")
+	}
+
+	for i := 0; i < 20*brutePaceWindow; i++ {
+		p.record(true)
+	}
+	if st := p.stats(); st.Rate != bruteMinRate {
+		t.Errorf("the rate was lowered below the minimum: %+v", st)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.wait(context.Background())
+	if p.wait(ctx) {
+		t.Error("the pacer did not give up on the expired context")
+	}
+}
+
+// lossyResolver times out the first queries for each name beginning with "flaky",
+// and every query for the names beginning with "lost".
+type lossyResolver struct {
+	batchResolver
+	sync.Mutex
+	queries map[string]int
+}
+
+func (r *lossyResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	name := msg.Question[0].Name
+
+	r.Lock()
+	r.queries[name]++
+	n := r.queries[name]
+	r.Unlock()
+
+	if strings.HasPrefix(name, "lost") || (strings.HasPrefix(name, "flaky") && n <= 2) {
+		return nil, &resolve.ResolveError{Err: "timeout", Rcode: resolve.TimeoutRcode}
+	}
+	return r.batchResolver.Query(ctx, msg, priority, retry)
+}
+
+func TestProcessDNSRequestBruteRetries(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.AddDomain("example.com")
+
+	res := &lossyResolver{queries: make(map[string]int)}
+	r := newTestEnumSource(cfg)
+	r.enum.nameSrc = r
+	r.enum.Sys = &systems.SimpleSystem{Resolver: res}
+	r.enum.brutePace = newBrutePacer(1000)
+	dt := newDNSTask(r.enum)
+
+	var unresolved []string
+	r.enum.AddUnresolvedHook(func(data pipeline.Data) {
+		unresolved = append(unresolved, data.(*requests.DNSRequest).Name)
+	})
+
+	flaky := &requests.DNSRequest{Name: "flaky.owasp.org", Domain: "owasp.org", Tag: requests.BRUTE, Source: "Brute Forcing"}
+	if out, err := dt.processDNSRequest(context.Background(), flaky, nil); err != nil || out == nil {
+		t.Errorf("the name with lost queries was not resolved again: %v", err)
+	}
+
+	lost := &requests.DNSRequest{Name: "lost.example.com", Domain: "example.com", Tag: requests.BRUTE, Source: "Brute Forcing"}
+	if out, _ := dt.processDNSRequest(context.Background(), lost, nil); out != nil {
+		t.Error("the name without answers continued through the pipeline")
+	}
+	if n := res.queries["lost.example.com."]; n != (bruteRetries+1)*len(initialQueryTypes(cfg)) {
+		t.Errorf("the name with lost queries was resolved %d times", n)
+	}
+
+	// The names from other sources are not resolved again
+	cert := &requests.DNSRequest{Name: "lost.owasp.org", Domain: "owasp.org", Tag: requests.CERT, Source: "Crtsh"}
+	_, _ = dt.processDNSRequest(context.Background(), cert, nil)
+	if n := res.queries["lost.owasp.org."]; n != len(initialQueryTypes(cfg)) {
+		t.Errorf("the name from a data source was resolved %d times", n)
+	}
+
+	if st := r.stats.snapshot(); st.BruteRetried != 3 || st.BruteLost != 1 {
+		t.Errorf("the brute forcing retries were not counted: %+v", st)
+	}
+	if len(unresolved) != 0 {
+		t.Errorf("the names with lost queries were reported as unresolved: %v", unresolved)
+	}
+}
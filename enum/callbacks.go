@@ -0,0 +1,75 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+)
+
+// The number of results buffered for the OnNewName and OnNewAddr callbacks
+const callbackBufferSize = 1000
+
+// startCallbacks delivers the results leaving the pipeline to the OnNewName and OnNewAddr callbacks
+// of the configuration from a dedicated goroutine, so that a slow consumer cannot block the pipeline.
+func (e *Enumeration) startCallbacks() {
+	if e.Config.OnNewName == nil && e.Config.OnNewAddr == nil {
+		return
+	}
+
+	e.callbacks = make(chan pipeline.Data, callbackBufferSize)
+	go e.processCallbacks()
+}
+
+// queueCallback buffers the result for delivery, and the result is dropped when the buffer is full.
+func (e *Enumeration) queueCallback(data pipeline.Data) {
+	if e.callbacks == nil {
+		return
+	}
+
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		if e.Config.OnNewName == nil || v == nil || v.Name == "" {
+			return
+		}
+	case *requests.AddrRequest:
+		if e.Config.OnNewAddr == nil || v == nil || v.Address == "" {
+			return
+		}
+	default:
+		return
+	}
+
+	select {
+	case e.callbacks <- data:
+	default:
+		e.nameSrc.stats.update(func(st *Stats) {
+			st.DroppedCallbacks++
+		})
+	}
+}
+
+func (e *Enumeration) processCallbacks() {
+	for {
+		select {
+		case data := <-e.callbacks:
+			e.runCallback(data)
+		case <-e.done:
+			// Deliver the results buffered before the enumeration completed
+			for len(e.callbacks) > 0 {
+				e.runCallback(<-e.callbacks)
+			}
+			return
+		}
+	}
+}
+
+func (e *Enumeration) runCallback(data pipeline.Data) {
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		e.Config.OnNewName(v.Name, v.Domain, v.Tag, v.Source)
+	case *requests.AddrRequest:
+		e.Config.OnNewAddr(v.Address, v.Domain, v.Tag, v.Source)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestCallbacksDropWhenBlocked(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var delivered int32
+
+	cfg := config.NewConfig()
+	cfg.OnNewName = func(name, domain, tag, source string) {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		<-release
+		atomic.AddInt32(&delivered, 1)
+	}
+
+	e := &Enumeration{
+		Config:  cfg,
+		done:    make(chan struct{}),
+		nameSrc: &enumSource{},
+	}
+	e.startCallbacks()
+
+	name := func(i int) *requests.DNSRequest {
+		return &requests.DNSRequest{
+			Name:   fmt.Sprintf("www%d.owasp.org", i),
+			Domain: "owasp.org",
+			Tag:    requests.DNS,
+			Source: "DNS",
+		}
+	}
+
+	// The callback blocks while holding the first name
+	e.queueCallback(name(0))
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The callback was not called")
+	}
+
+	dropped := 3
+	for i := 1; i <= callbackBufferSize+dropped; i++ {
+		e.queueCallback(name(i))
+	}
+	// The addresses are not buffered without the OnNewAddr callback
+	e.queueCallback(&requests.AddrRequest{Address: "192.0.2.1", Domain: "owasp.org"})
+
+	if st := e.nameSrc.stats.snapshot(); st.DroppedCallbacks != dropped {
+		t.Errorf("%d results were counted as dropped instead of %d", st.DroppedCallbacks, dropped)
+	}
+
+	close(release)
+	close(e.done)
+	expected := int32(callbackBufferSize + 1)
+	for deadline := time.Now().Add(5 * time.Second); atomic.LoadInt32(&delivered) < expected; {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of the %d buffered results were delivered", atomic.LoadInt32(&delivered), expected)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
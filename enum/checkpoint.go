@@ -0,0 +1,200 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/queue"
+)
+
+const checkpointInterval = time.Minute
+
+// checkpoint is the enumeration state persisted to Config.CheckpointPath.
+type checkpoint struct {
+	Filter    []byte
+	Count     int
+	Zones     []string
+	Trusted   checkpointQueue
+	Untrusted checkpointQueue
+}
+
+// checkpointQueue holds the requests waiting on one of the input source queues.
+type checkpointQueue struct {
+	Names []*requests.DNSRequest
+	Addrs []*requests.AddrRequest
+}
+
+// loadCheckpoint restores the state of an enumeration that did not run to completion.
+// It returns false when no checkpoint was restored.
+func (r *enumSource) loadCheckpoint() bool {
+	path := r.enum.Config.CheckpointPath
+	if path == "" {
+		return false
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.enum.queueLog(fmt.Sprintf("Failed to read the checkpoint from %s: %v", path, err))
+		}
+		return false
+	}
+
+	var cp checkpoint
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cp); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to decode the checkpoint from %s: %v", path, err))
+		return false
+	}
+	if err := r.filter.UnmarshalBinary(cp.Filter); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to restore the filter from the checkpoint %s: %v", path, err))
+		// Start with a fresh filter instead of a partially restored one
+		r.filter.Close()
+		r.filter = newNameFilter(r.enum.Config)
+		return false
+	}
+
+	r.count = cp.Count
+	r.restored = true
+	r.zones.InsertMany(cp.Zones...)
+	for _, req := range cp.Trusted.Names {
+		r.queue.Append(req)
+	}
+	for _, req := range cp.Trusted.Addrs {
+		r.queue.Append(req)
+	}
+	for _, req := range cp.Untrusted.Names {
+		r.untrusted.Append(req)
+	}
+	for _, req := range cp.Untrusted.Addrs {
+		r.untrusted.Append(req)
+	}
+
+	r.enum.queueLog(fmt.Sprintf("Resuming the enumeration from the checkpoint %s: %d requests queued",
+		path, r.queueLen()))
+	return true
+}
+
+// saveCheckpoint writes the enumeration state to the checkpoint file, replacing the previous one.
+func (r *enumSource) saveCheckpoint() {
+	path := r.enum.Config.CheckpointPath
+	if path == "" {
+		return
+	}
+
+	r.filterLock.Lock()
+	fdata, err := r.filter.MarshalBinary()
+	count := r.count
+	r.filterLock.Unlock()
+	if err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to save the filter to the checkpoint %s: %v", path, err))
+		return
+	}
+
+	// Queued elements cannot be released while the queues are copied
+	r.dataLock.Lock()
+	trusted := snapshotQueue(r.queue)
+	untrusted := snapshotQueue(r.untrusted)
+	r.dataLock.Unlock()
+
+	cp := &checkpoint{
+		Filter:    fdata,
+		Count:     count,
+		Zones:     r.zones.Slice(),
+		Trusted:   trusted,
+		Untrusted: untrusted,
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to encode the checkpoint %s: %v", path, err))
+		return
+	}
+	// Write to a temporary file first, so a crash cannot leave a truncated checkpoint
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to save the checkpoint %s: %v", path, err))
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to save the checkpoint %s: %v", path, err))
+	}
+}
+
+// removeCheckpoint deletes the checkpoint of an enumeration that ran to completion.
+func (r *enumSource) removeCheckpoint() {
+	path := r.enum.Config.CheckpointPath
+	if path == "" {
+		return
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		r.enum.queueLog(fmt.Sprintf("Failed to remove the checkpoint %s: %v", path, err))
+	}
+}
+
+// markCompleted causes the checkpoint to be removed, instead of saved, when the input source is stopped.
+func (r *enumSource) markCompleted() {
+	r.checkpointLock.Lock()
+	defer r.checkpointLock.Unlock()
+
+	r.completed = true
+}
+
+func (r *enumSource) finishCheckpoint() {
+	if r.enum.Config.CheckpointPath == "" {
+		return
+	}
+
+	r.checkpointLock.Lock()
+	completed := r.completed
+	r.checkpointLock.Unlock()
+
+	if completed {
+		r.removeCheckpoint()
+		return
+	}
+	r.saveCheckpoint()
+}
+
+func (r *enumSource) manageCheckpoints() {
+	t := time.NewTicker(checkpointInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-t.C:
+			r.saveCheckpoint()
+		}
+	}
+}
+
+// snapshotQueue returns the requests on the queue, leaving the queue in the same order.
+func snapshotQueue(q queue.Queue) checkpointQueue {
+	var elements []interface{}
+	q.Process(func(e interface{}) {
+		elements = append(elements, e)
+	})
+
+	var cq checkpointQueue
+	for _, e := range elements {
+		q.Append(e)
+
+		switch v := e.(type) {
+		case *requests.DNSRequest:
+			cq.Names = append(cq.Names, v)
+		case *requests.AddrRequest:
+			cq.Addrs = append(cq.Addrs, v)
+		}
+	}
+	return cq
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.CheckpointPath = filepath.Join(t.TempDir(), "checkpoint.dat")
+
+	r := newTestEnumSource(cfg)
+	if !r.accept("www.owasp.org", requests.DNS, "DNS", true) {
+		t.Fatal("The name was not accepted")
+	}
+	r.zones.Insert("owasp.org")
+	r.appendData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org"}, true)
+	r.appendData(&requests.AddrRequest{Address: "192.168.1.1", Domain: "owasp.org"}, false)
+
+	r.saveCheckpoint()
+	if r.queueLen() != 2 {
+		t.Errorf("Saving the checkpoint changed the length of the queues to %d", r.queueLen())
+	}
+
+	resumed := newTestEnumSource(cfg)
+	if !resumed.loadCheckpoint() {
+		t.Fatal("The checkpoint was not restored")
+	}
+	if resumed.count != 1 || !resumed.zones.Has("owasp.org") {
+		t.Errorf("The checkpoint restored a count of %d and zones %v", resumed.count, resumed.zones.Slice())
+	}
+	if resumed.queue.Len() != 1 || resumed.untrusted.Len() != 1 {
+		t.Errorf("The checkpoint restored %d trusted and %d untrusted requests",
+			resumed.queue.Len(), resumed.untrusted.Len())
+	}
+	if resumed.accept("www.owasp.org", requests.DNS, "DNS", true) {
+		t.Error("The name accepted before the checkpoint was accepted again")
+	}
+
+	resumed.markCompleted()
+	resumed.finishCheckpoint()
+	if _, err := os.Stat(cfg.CheckpointPath); !os.IsNotExist(err) {
+		t.Error("The checkpoint was not removed after the enumeration completed")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"strings"
+	"sync"
+)
+
+// The maximum number of CNAME records followed from the first alias in a chain
+const maxCNAMEChainLength = 10
+
+// cnameChains tracks the CNAME records followed during the enumeration, so that the
+// targets of chains are resolved once and loops do not cause endless resubmission.
+type cnameChains struct {
+	sync.Mutex
+	targets map[string]string
+	depth   map[string]int
+}
+
+func newCNAMEChains() *cnameChains {
+	return &cnameChains{
+		targets: make(map[string]string),
+		depth:   make(map[string]int),
+	}
+}
+
+// follow records the CNAME from the alias to the target and returns true
+// if the target should be submitted for resolution.
+func (c *cnameChains) follow(alias, target string) bool {
+	alias = strings.ToLower(alias)
+	target = strings.ToLower(target)
+	if alias == target {
+		return false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if _, found := c.targets[alias]; found {
+		return false
+	}
+	c.targets[alias] = target
+	// The target has already been resolved, either by an earlier chain or as an alias in this loop
+	if _, resolved := c.targets[target]; resolved {
+		return false
+	}
+	if _, submitted := c.depth[target]; submitted {
+		return false
+	}
+
+	depth := c.depth[alias] + 1
+	c.depth[target] = depth
+	return depth <= maxCNAMEChainLength
+}
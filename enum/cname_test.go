@@ -0,0 +1,55 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCNAMEChainsMultiHop(t *testing.T) {
+	c := newCNAMEChains()
+
+	chain := []string{"www.owasp.org", "web.owasp.org", "lb.owasp.org", "edge.owasp.org"}
+	for i := 0; i < len(chain)-1; i++ {
+		if !c.follow(chain[i], chain[i+1]) {
+			t.Errorf("The CNAME from %s to %s was not followed", chain[i], chain[i+1])
+		}
+	}
+	// A second alias joining the chain must not cause the shared targets to be resolved again
+	if c.follow("ftp.owasp.org", "lb.owasp.org") {
+		t.Errorf("The target shared with an earlier chain was followed again")
+	}
+	if c.follow("www.owasp.org", "web.owasp.org") {
+		t.Errorf("The CNAME of an alias already followed was followed again")
+	}
+}
+
+func TestCNAMEChainsLoop(t *testing.T) {
+	c := newCNAMEChains()
+
+	if c.follow("a.owasp.org", "a.owasp.org") {
+		t.Errorf("The CNAME that points to itself was followed")
+	}
+	if !c.follow("b.owasp.org", "c.owasp.org") || !c.follow("c.owasp.org", "d.owasp.org") {
+		t.Fatalf("The CNAME chain was not followed")
+	}
+	if c.follow("d.owasp.org", "b.owasp.org") {
+		t.Errorf("The CNAME that closes the loop was followed")
+	}
+}
+
+func TestCNAMEChainsMaxLength(t *testing.T) {
+	c := newCNAMEChains()
+
+	var followed int
+	for i := 0; i < 2*maxCNAMEChainLength; i++ {
+		if c.follow(fmt.Sprintf("h%d.owasp.org", i), fmt.Sprintf("h%d.owasp.org", i+1)) {
+			followed++
+		}
+	}
+	if followed != maxCNAMEChainLength {
+		t.Errorf("Expected %d CNAME records to be followed, but %d were", maxCNAMEChainLength, followed)
+	}
+}
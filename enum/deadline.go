@@ -0,0 +1,64 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/pipeline"
+)
+
+// deadlineTask is a pipeline Task that processes each data element with a context canceled after
+// the request timeout of the configuration, so a stalled query cannot hold the slot of the stage.
+type deadlineTask struct {
+	enum  *Enumeration
+	stage string
+	task  pipeline.Task
+}
+
+// withDeadline returns the task processing each data element within the request timeout.
+func (e *Enumeration) withDeadline(stage string, task pipeline.Task) pipeline.Task {
+	return &deadlineTask{
+		enum:  e,
+		stage: stage,
+		task:  task,
+	}
+}
+
+// Process implements the pipeline Task interface.
+func (d *deadlineTask) Process(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) (pipeline.Data, error) {
+	timeout := d.enum.Config.RequestTimeout
+	if timeout <= 0 {
+		return d.task.Process(ctx, data, tp)
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := d.task.Process(rctx, data, tp)
+	// The cancellation of the enumeration is not a timeout of the request
+	if ctx.Err() != nil || rctx.Err() != context.DeadlineExceeded {
+		return out, err
+	}
+
+	d.enum.updateStats(func(st *Stats) { st.TimedOut++ })
+	d.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityLow,
+		fmt.Sprintf("Request timeout: %s was canceled in the %s stage after %s", dataName(data), d.stage, timeout))
+	// The records acquired before the deadline continue through the pipeline, while
+	// the error caused by the deadline must not stop the remaining data elements
+	return out, nil
+}
+
+func dataName(data pipeline.Data) string {
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		return v.Name
+	case *requests.AddrRequest:
+		return v.Address
+	}
+	return fmt.Sprintf("%T", data)
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/pipeline"
+)
+
+func TestDeadlineTaskCancelsStalledRequests(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.RequestTimeout = 50 * time.Millisecond
+
+	r := newTestEnumSource(cfg)
+	r.enum.nameSrc = r
+	r.enum.Bus = eventbus.NewEventBus()
+	defer r.enum.Bus.Stop()
+
+	stalled := pipeline.TaskFunc(func(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) (pipeline.Data, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	task := r.enum.withDeadline("dns", stalled)
+
+	start := time.Now()
+	out, err := task.Process(context.Background(), &requests.DNSRequest{Name: "www.owasp.org"}, nil)
+	if err != nil {
+		t.Errorf("The deadline returned an error that would stop the pipeline: %v", err)
+	}
+	if out != nil {
+		t.Error("The canceled request was returned by the task")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("The stalled request was not canceled by the deadline")
+	}
+	if got := r.enum.Stats().TimedOut; got != 1 {
+		t.Errorf("Expected one timed out request, got %d", got)
+	}
+
+	// The cancellation of the enumeration is not counted as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := task.Process(ctx, &requests.DNSRequest{Name: "dev.owasp.org"}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancellation of the enumeration to be returned, got %v", err)
+	}
+	if got := r.enum.Stats().TimedOut; got != 1 {
+		t.Errorf("The cancellation of the enumeration was counted as a timeout")
+	}
+}
+
+func TestDeadlineTaskDisabled(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.RequestTimeout = 0
+
+	r := newTestEnumSource(cfg)
+	r.enum.nameSrc = r
+
+	passthrough := pipeline.TaskFunc(func(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) (pipeline.Data, error) {
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("The context had a deadline while the request timeout was disabled")
+		}
+		return data, nil
+	})
+
+	req := &requests.DNSRequest{Name: "www.owasp.org"}
+	if out, err := r.enum.withDeadline("dns", passthrough).Process(context.Background(), req, nil); err != nil || out != req {
+		t.Error("The data element was not returned by the task")
+	}
+}
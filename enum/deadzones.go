@@ -0,0 +1,92 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// The number of root domain names checked for liveness at the same time
+const maxZoneLivenessChecks int = 25
+
+// checkDeadZones queries the apex of each root domain name and disables the brute forcing and
+// alterations for the domains that are dead. A domain is dead when its apex returns NXDOMAIN, or
+// has neither NS records nor addresses. The domains that could not be queried are considered alive.
+func (e *Enumeration) checkDeadZones() {
+	domains := e.Config.Domains()
+	sem := make(chan struct{}, maxZoneLivenessChecks)
+
+	var wg sync.WaitGroup
+	for _, domain := range domains {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(d string) {
+			defer func() { <-sem; wg.Done() }()
+
+			if zoneDead(e.ctx, e.Sys.Pool(), d) {
+				e.markDeadZone(d)
+			}
+		}(domain)
+	}
+	wg.Wait()
+}
+
+func zoneDead(ctx context.Context, r resolve.Resolver, domain string) bool {
+	for _, qtype := range []uint16{dns.TypeNS, dns.TypeA, dns.TypeAAAA} {
+		resp, err := r.Query(ctx, resolve.QueryMsg(domain, qtype), resolve.PriorityCritical, resolve.PoolRetryPolicy)
+		if resp != nil && resp.Rcode == dns.RcodeNameError {
+			return true
+		}
+		if err != nil || resp == nil || resp.Rcode != dns.RcodeSuccess {
+			return false
+		}
+
+		for _, rr := range resp.Answer {
+			if rr.Header().Rrtype == qtype {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// markDeadZone keeps the domain as dead and overrides its settings to skip the active techniques,
+// while leaving the other settings of the domain in place.
+func (e *Enumeration) markDeadZone(domain string) {
+	e.deadLock.Lock()
+	e.deadZones = append(e.deadZones, domain)
+	e.deadLock.Unlock()
+
+	disabled := false
+	settings := new(config.DomainSettings)
+	if s := e.Config.DomainSettingsFor(domain); s != nil {
+		*settings = *s
+	}
+	settings.BruteForcing = &disabled
+	settings.Alterations = &disabled
+	e.Config.SetDomainSettings(domain, settings)
+
+	e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("The zone %s is dead, so the active techniques will be skipped", domain))
+}
+
+// DeadZones returns the root domain names that were found to be dead at the start of the enumeration.
+func (e *Enumeration) DeadZones() []string {
+	e.deadLock.Lock()
+	defer e.deadLock.Unlock()
+
+	zones := append([]string(nil), e.deadZones...)
+	sort.Strings(zones)
+	return zones
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// livenessResolver serves the owasp.org zone, the address of the apex of dev.example.com, and
+// returns NXDOMAIN for the expired.com zone. The other names have no records.
+type livenessResolver struct{}
+
+func (r *livenessResolver) String() string { return "liveness" }
+func (r *livenessResolver) Len() int       { return 0 }
+func (r *livenessResolver) Stop()          {}
+func (r *livenessResolver) Stopped() bool  { return false }
+
+func (r *livenessResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	q := msg.Question[0]
+	hdr := dns.RR_Header{Name: q.Name, Rrtype: q.Qtype, Class: dns.ClassINET, Ttl: 60}
+	switch {
+	case q.Name == "expired.com.":
+		resp.Rcode = dns.RcodeNameError
+	case q.Name == "owasp.org." && q.Qtype == dns.TypeNS:
+		resp.Answer = append(resp.Answer, &dns.NS{Hdr: hdr, Ns: "ns1.owasp.org."})
+	case q.Name == "dev.example.com." && q.Qtype == dns.TypeA:
+		resp.Answer = append(resp.Answer, &dns.A{Hdr: hdr, A: net.ParseIP("192.0.2.1")})
+	}
+	return resp, nil
+}
+
+func (r *livenessResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestCheckDeadZones(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.BruteForcing = true
+	cfg.Alterations = true
+	cfg.SkipDeadZones = true
+	for _, d := range []string{"owasp.org", "dev.example.com", "expired.com", "empty.net"} {
+		cfg.AddDomain(d)
+	}
+	cfg.SetDomainSettings("empty.net", &config.DomainSettings{Wordlist: []string{"www"}})
+
+	e := &Enumeration{
+		Config: cfg,
+		Bus:    eventbus.NewEventBus(),
+		Sys:    &systems.SimpleSystem{Resolver: &livenessResolver{}},
+	}
+	defer e.Bus.Stop()
+	e.ctx = context.Background()
+
+	e.checkDeadZones()
+	if zones := e.DeadZones(); len(zones) != 2 || zones[0] != "empty.net" || zones[1] != "expired.com" {
+		t.Errorf("The dead zones were not detected: %v", zones)
+	}
+	for _, d := range []string{"owasp.org", "dev.example.com"} {
+		if !cfg.BruteForcingFor(d) || !cfg.AlterationsFor(d) {
+			t.Errorf("The active techniques were skipped for the live zone %s", d)
+		}
+	}
+	for _, d := range []string{"www.expired.com", "empty.net"} {
+		if cfg.BruteForcingFor(d) || cfg.AlterationsFor(d) {
+			t.Errorf("The active techniques were not skipped for %s", d)
+		}
+	}
+	if list := cfg.WordlistFor("empty.net"); len(list) != 1 || list[0] != "www" {
+		t.Errorf("The settings of the dead zone were not preserved: %v", list)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/OWASP/Amass/v3/config"
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/caffix/eventbus"
@@ -25,6 +26,24 @@ var InitialQueryTypes = []uint16{
 	dns.TypeAAAA,
 }
 
+// initialQueryTypes returns the record types queried for each name, which are the InitialQueryTypes
+// unless the configuration selects the types, permitted by the address family settings.
+func initialQueryTypes(cfg *config.Config) []uint16 {
+	var types []uint16
+
+	selected := InitialQueryTypes
+	if qt := cfg.QueryRecordTypes(); len(qt) > 0 {
+		selected = qt
+	}
+	for _, t := range selected {
+		if (t == dns.TypeA && cfg.IPv6Only) || (t == dns.TypeAAAA && cfg.IPv4Only) {
+			continue
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
 // dNSTask is the task that handles all DNS name resolution requests within the pipeline.
 type dNSTask struct {
 	enum *Enumeration
@@ -133,8 +152,109 @@ func (dt *dNSTask) processDNSRequest(ctx context.Context, req *requests.DNSReque
 	if req == nil || !req.Valid() {
 		return nil, nil
 	}
+
+	// The brute forcing names are paced, and resolved again when the queries appear to have been lost
+	pace := dt.enum.brutePace
+	if req.Tag != requests.BRUTE {
+		pace = nil
+	}
+
+	var nxdomain, lost, wildcard, authoritative bool
+	for attempt := 0; ; attempt++ {
+		if !pace.wait(ctx) {
+			return nil, nil
+		}
+
+		nxdomain, lost = false, false
+		var err error
+		wildcard, authoritative, err = resolveRecords(ctx, dt.enum.Config, dt.enum.Sys.Pool(), dt.enum.dnssec, req, func(err error) {
+			if rerr, ok := err.(*resolve.ResolveError); ok && rerr.Rcode == dns.RcodeNameError {
+				nxdomain = true
+			}
+			if lostQuery(err) {
+				lost = true
+			}
+			dt.handleResolverError(ctx, err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if pace == nil {
+			break
+		}
+
+		pace.record(lost)
+		if len(req.Records) > 0 || wildcard || attempt >= bruteRetries || ctx.Err() != nil {
+			break
+		}
+		// The NXDOMAIN responses received while the resolvers are rate limiting are confirmed
+		if !lost && !(nxdomain && pace.slowed()) {
+			break
+		}
+		dt.enum.updateStats(func(st *Stats) { st.BruteRetried++ })
+	}
+	if authoritative {
+		dt.enum.authNames.Insert(strings.ToLower(req.Name))
+	}
+	if v := dt.enum.dnssec; v != nil && len(req.Records) > 0 && v.record(req.Name, req.Records) == requests.DNSSECBogus {
+		if dt.handleBogusRecords(ctx, req); len(req.Records) == 0 {
+			return nil, nil
+		}
+	}
+
+	if len(req.Records) > 0 {
+		return req, nil
+	}
+	if wildcard {
+		dt.enum.nameSrc.holdWildcardName(req)
+	} else if pace != nil && lost {
+		// The name was not shown to be nonexistent
+		dt.enum.updateStats(func(st *Stats) { st.BruteLost++ })
+	} else if nxdomain && unresolvedCandidate(req.Tag) && dt.enum.Config.IsDomainInScope(req.Name) {
+		dt.enum.runUnresolvedHooks(req)
+	}
+	return nil, nil
+}
+
+// handleBogusRecords reports the name with records that failed the DNSSEC validation, since they may
+// have been tampered with, and removes the records when the configuration drops the bogus records.
+func (dt *dNSTask) handleBogusRecords(ctx context.Context, req *requests.DNSRequest) {
+	dt.enum.updateStats(func(st *Stats) { st.DNSSECBogus++ })
+	if _, bus, err := requests.ContextConfigBus(ctx); err == nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("DNS: DNSSEC validation failed for the records of %s", req.Name))
+	}
+
+	if !dt.enum.Config.DNSSECDropBogus {
+		return
+	}
+
+	var records []requests.DNSAnswer
+	for _, rec := range req.Records {
+		if rec.DNSSEC != requests.DNSSECBogus {
+			records = append(records, rec)
+		}
+	}
+	req.Records = records
+}
+
+// unresolvedCandidate returns true for the tags of the names generated by the enumeration.
+func unresolvedCandidate(tag string) bool {
+	return tag == requests.BRUTE || tag == requests.ALT || tag == requests.GUESS
+}
+
+// resolveRecords queries the record types selected by the configuration for the name in the request,
+// appending the answers to the records of the request. The answers of an untrusted name matched by
+// the DNS wildcard for the record type are skipped, and true is returned for wildcard when no other
+// records were found, since a zone may only have a wildcard for some types. True is returned for
+// authoritative when the answers were provided by an authoritative server of the zone. The resolver
+// errors are provided to fail. The answers are tagged with their DNSSEC validation status when the
+// validator is provided.
+func resolveRecords(ctx context.Context, cfg *config.Config, pool resolve.Resolver, v *dnssecValidator,
+	req *requests.DNSRequest, fail func(error)) (wildcard, authoritative bool, err error) {
+	num := len(req.Records)
 loop:
-	for _, t := range InitialQueryTypes {
+	for _, t := range initialQueryTypes(cfg) {
 		select {
 		case <-ctx.Done():
 			break loop
@@ -142,11 +262,16 @@ loop:
 		}
 
 		msg := resolve.QueryMsg(req.Name, t)
-		resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolve.PriorityLow, resolve.PoolRetryPolicy)
+		if v != nil {
+			requestDNSSEC(msg)
+		}
+
+		resp, err := pool.Query(ctx, msg, resolve.PriorityLow, resolve.PoolRetryPolicy)
 		if err == nil && resp != nil && len(resp.Answer) > 0 {
-			if !requests.TrustedTag(req.Tag) &&
-				dt.enum.Sys.Pool().WildcardType(ctx, resp, req.Domain) != resolve.WildcardTypeNone {
-				break
+			if !requests.TrustedSource(cfg, req.Tag, req.Source) &&
+				pool.WildcardType(ctx, resp, req.Domain) != resolve.WildcardTypeNone {
+				wildcard = true
+				continue
 			}
 
 			ans := resolve.ExtractAnswers(resp)
@@ -159,22 +284,39 @@ loop:
 				continue
 			}
 
-			req.Records = append(req.Records, convertAnswers(rr)...)
+			answers := convertAnswers(rr)
+			// The answers provided by the authoritative servers of the zone are tagged as such
+			if resp.Authoritative {
+				for i := range answers {
+					answers[i].Authoritative = true
+				}
+				authoritative = true
+			}
+			if v != nil {
+				v.tagAnswers(ctx, pool, resp, answers, cfg.WhichDomain)
+			}
+
+			req.Records = append(req.Records, answers...)
 			if t == dns.TypeCNAME {
 				break
 			}
 		} else {
 			if err != nil && err.Error() == "All resolvers have been stopped" {
-				return nil, err
+				return wildcard, authoritative, err
+			}
+			// The response codes of the failed queries are reported like the errors of the resolvers
+			if err == nil && resp != nil && resp.Rcode != dns.RcodeSuccess {
+				err = &resolve.ResolveError{
+					Err:   fmt.Sprintf("DNS query for %s returned %s", req.Name, dns.RcodeToString[resp.Rcode]),
+					Rcode: resp.Rcode,
+				}
+			}
+			if fail != nil {
+				fail(err)
 			}
-			dt.handleResolverError(ctx, err)
 		}
 	}
-
-	if len(req.Records) > 0 {
-		return req, nil
-	}
-	return nil, nil
+	return wildcard && len(req.Records) == num, authoritative, nil
 }
 
 func (dt *dNSTask) handleResolverError(ctx context.Context, e error) {
@@ -303,14 +445,15 @@ func (dt *dNSTask) queryServiceNames(ctx context.Context, req *requests.DNSReque
 
 	wg.Add(len(popularSRVRecords))
 	for _, name := range popularSRVRecords {
-		go dt.querySingleServiceName(ctx, name+"."+req.Name, req.Domain, &wg, tp)
+		go dt.querySingleServiceName(ctx, name+"."+req.Name, req, &wg, tp)
 	}
 
 	wg.Wait()
 }
 
-func (dt *dNSTask) querySingleServiceName(ctx context.Context, name, domain string, wg *sync.WaitGroup, tp pipeline.TaskParams) {
+func (dt *dNSTask) querySingleServiceName(ctx context.Context, name string, parent *requests.DNSRequest, wg *sync.WaitGroup, tp pipeline.TaskParams) {
 	defer wg.Done()
+	domain := parent.Domain
 
 	select {
 	case <-ctx.Done():
@@ -336,11 +479,13 @@ func (dt *dNSTask) querySingleServiceName(ctx context.Context, name, domain stri
 	}
 
 	req := &requests.DNSRequest{
-		Name:    name,
-		Domain:  domain,
-		Records: convertAnswers(rr),
-		Tag:     requests.DNS,
-		Source:  "DNS",
+		Name:       name,
+		Domain:     domain,
+		Records:    convertAnswers(rr),
+		Tag:        requests.DNS,
+		Source:     "DNS",
+		Provenance: derivedProvenance(parent, requests.DNS, "DNS"),
+		Depth:      parent.Depth + 1,
 	}
 
 	if req.Valid() && dt.enum.Sys.Pool().WildcardType(ctx, resp, domain) == resolve.WildcardTypeNone {
@@ -379,6 +524,10 @@ func (dt *dNSTask) reverseDNSQuery(ctx context.Context, addr string, tp pipeline
 	if amassdns.RemoveAsteriskLabel(answer) != answer {
 		return false
 	}
+	// The reverse names of the hosts imported from port scanner output are in scope
+	if dt.enum.scannedAddress(addr) {
+		dt.enum.addScanScope(answer)
+	}
 	// Check that the name discovered is in scope
 	d := dt.enum.Config.WhichDomain(answer)
 	if d == "" {
@@ -0,0 +1,115 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+func TestProcessDNSRequestUnresolvedHooks(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.AddDomain("example.com")
+
+	r := newTestEnumSource(cfg)
+	r.enum.Sys = &systems.SimpleSystem{Resolver: &batchResolver{}}
+	dt := newDNSTask(r.enum)
+
+	var lock sync.Mutex
+	var unresolved []string
+	r.enum.AddUnresolvedHook(func(data pipeline.Data) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		unresolved = append(unresolved, data.(*requests.DNSRequest).Name)
+	})
+
+	for _, req := range []*requests.DNSRequest{
+		// Resolved by the batch resolver
+		{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.BRUTE, Source: "Brute Forcing"},
+		// Generated names that do not exist
+		{Name: "dev.example.com", Domain: "example.com", Tag: requests.BRUTE, Source: "Brute Forcing"},
+		{Name: "dev1.example.com", Domain: "example.com", Tag: requests.ALT, Source: "Alterations"},
+		// Names from the data sources that do not exist are not candidates
+		{Name: "old.example.com", Domain: "example.com", Tag: requests.CERT, Source: "Crtsh"},
+	} {
+		out, err := dt.processDNSRequest(context.Background(), req, nil)
+		if err != nil {
+			t.Fatalf("Failed to process %s: %v", req.Name, err)
+		}
+		if req.Name == "www.owasp.org" && out == nil {
+			t.Error("The resolved name did not continue through the pipeline")
+		} else if req.Name != "www.owasp.org" && out != nil {
+			t.Errorf("The unresolved name %s continued through the pipeline", req.Name)
+		}
+	}
+
+	if len(unresolved) != 2 || unresolved[0] != "dev.example.com" || unresolved[1] != "dev1.example.com" {
+		t.Errorf("The unresolved candidates were not provided to the hook: %v", unresolved)
+	}
+}
+
+// ipv4WildcardResolver answers the A and AAAA queries, while the zone only has a wildcard for the A records.
+type ipv4WildcardResolver struct {
+	batchResolver
+}
+
+func (r *ipv4WildcardResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	q := msg.Question[0]
+	switch q.Qtype {
+	case dns.TypeA:
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.IPv4(192, 168, 1, 1),
+		})
+	case dns.TypeAAAA:
+		if q.Name == "ipv6.owasp.org." {
+			resp.Answer = append(resp.Answer, &dns.AAAA{
+				Hdr:  dns.RR_Header{Name: q.Name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 60},
+				AAAA: net.ParseIP("2001:db8::1"),
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (r *ipv4WildcardResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	if msg.Question[0].Qtype == dns.TypeA {
+		return resolve.WildcardTypeStatic
+	}
+	return resolve.WildcardTypeNone
+}
+
+func TestResolveRecordsWildcardTypes(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	pool := &ipv4WildcardResolver{}
+
+	req := &requests.DNSRequest{Name: "ipv6.owasp.org", Domain: "owasp.org", Tag: requests.BRUTE, Source: "Brute Forcing"}
+	wildcard, _, err := resolveRecords(context.Background(), cfg, pool, nil, req, nil)
+	if err != nil || wildcard {
+		t.Fatalf("The name with AAAA records was dropped by the DNS wildcard for the A records: %v", err)
+	}
+	if len(req.Records) != 1 || uint16(req.Records[0].Type) != dns.TypeAAAA {
+		t.Errorf("The records matched by the DNS wildcard were kept: %v", req.Records)
+	}
+
+	req = &requests.DNSRequest{Name: "ipv4.owasp.org", Domain: "owasp.org", Tag: requests.BRUTE, Source: "Brute Forcing"}
+	if wildcard, _, _ = resolveRecords(context.Background(), cfg, pool, nil, req, nil); !wildcard || len(req.Records) != 0 {
+		t.Errorf("The name only matched by the DNS wildcard was not reported: %v", req.Records)
+	}
+}
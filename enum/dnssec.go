@@ -0,0 +1,495 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// The DS record of the root zone KSK-2017, which anchors the chain of trust
+var rootTrustAnchor = &dns.DS{
+	Hdr:        dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET},
+	KeyTag:     20326,
+	Algorithm:  dns.RSASHA256,
+	DigestType: dns.SHA256,
+	Digest:     "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+}
+
+type rrsetKey struct {
+	name  string
+	qtype uint16
+}
+
+// dnssecZone holds the validated keys of a signed zone, or the insecure or bogus status of its chain.
+type dnssecZone struct {
+	status string
+	keys   []*dns.DNSKEY
+}
+
+// dnssecValidator checks the signatures of the answers using the keys of the signing zone, which are
+// validated along the DS records of the parent zones up to a trust anchor. The delegations without DS
+// records are only insecure when the secure parent zone signed the proof that the DS records do not exist.
+type dnssecValidator struct {
+	sync.Mutex
+	anchors   map[string][]*dns.DS
+	zones     map[string]*dnssecZone
+	enclosing map[string]string
+	names     map[string]string
+	now       func() time.Time
+}
+
+func newDNSSECValidator() *dnssecValidator {
+	return &dnssecValidator{
+		anchors:   map[string][]*dns.DS{".": {rootTrustAnchor}},
+		zones:     make(map[string]*dnssecZone),
+		enclosing: make(map[string]string),
+		names:     make(map[string]string),
+		now:       time.Now,
+	}
+}
+
+// requestDNSSEC sets the DO bit of the query, so the resolvers provide the RRSIG records of the answers.
+func requestDNSSEC(msg *dns.Msg) {
+	if opt := msg.IsEdns0(); opt != nil {
+		opt.SetDo()
+		return
+	}
+	msg.SetEdns0(dns.DefaultMsgSize, true)
+}
+
+// tagAnswers sets the validation status of each answer extracted from the response.
+func (v *dnssecValidator) tagAnswers(ctx context.Context, r resolve.Resolver,
+	resp *dns.Msg, answers []requests.DNSAnswer, zoneOf func(name string) string) {
+	statuses := v.validateResponse(ctx, r, resp, zoneOf)
+
+	for i, a := range answers {
+		status, found := statuses[rrsetKey{name: a.Name, qtype: uint16(a.Type)}]
+		if !found {
+			status = requests.DNSSECInsecure
+		}
+		answers[i].DNSSEC = status
+	}
+}
+
+// validateResponse returns the validation status of each RRset in the answer section of the response.
+// The RRsets without signatures that belong to the domains provided by zoneOf are validated using the
+// closest zone containing them, so the answers of a secure zone that had their signatures stripped are
+// bogus, while the answers of an unsigned zone delegated by the secure zone are insecure.
+func (v *dnssecValidator) validateResponse(ctx context.Context,
+	r resolve.Resolver, resp *dns.Msg, zoneOf func(name string) string) map[rrsetKey]string {
+	rrsets := make(map[rrsetKey][]dns.RR)
+	sigs := make(map[rrsetKey][]*dns.RRSIG)
+
+	for _, rr := range resp.Answer {
+		name := strings.ToLower(resolve.RemoveLastDot(rr.Header().Name))
+
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			k := rrsetKey{name: name, qtype: sig.TypeCovered}
+			sigs[k] = append(sigs[k], sig)
+			continue
+		}
+
+		k := rrsetKey{name: name, qtype: rr.Header().Rrtype}
+		rrsets[k] = append(rrsets[k], rr)
+	}
+
+	statuses := make(map[rrsetKey]string, len(rrsets))
+	for k, rrset := range rrsets {
+		statuses[k] = v.validateRRset(ctx, r, rrset, sigs[k], zoneOf(k.name))
+	}
+	return statuses
+}
+
+func (v *dnssecValidator) validateRRset(ctx context.Context,
+	r resolve.Resolver, rrset []dns.RR, sigs []*dns.RRSIG, domain string) string {
+	owner := rrset[0].Header().Name
+
+	if len(sigs) == 0 {
+		if domain == "" {
+			return requests.DNSSECInsecure
+		}
+		// The answers without signatures are only insecure when the zone containing them is proven insecure
+		if zone, ok := v.enclosingZone(ctx, r, owner); ok && v.zone(ctx, r, zone).status != requests.DNSSECInsecure {
+			return requests.DNSSECBogus
+		}
+		return requests.DNSSECInsecure
+	}
+
+	signer := sigs[0].SignerName
+	// The signing zone must contain the records
+	if !dns.IsSubDomain(signer, owner) {
+		return requests.DNSSECBogus
+	}
+
+	z := v.zone(ctx, r, signer)
+	if z.status != requests.DNSSECSecure {
+		return z.status
+	}
+	if v.verify(rrset, sigs, z.keys) {
+		return requests.DNSSECSecure
+	}
+	return requests.DNSSECBogus
+}
+
+// verify returns true when one of the signatures over the RRset was created with the keys
+// and is within the validity period.
+func (v *dnssecValidator) verify(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY) bool {
+	now := v.now()
+
+	for _, sig := range sigs {
+		if !sig.ValidityPeriod(now) {
+			continue
+		}
+
+		for _, key := range keys {
+			if key.KeyTag() == sig.KeyTag && key.Algorithm == sig.Algorithm && sig.Verify(key, rrset) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// zone returns the validated keys of the zone, or the status of its chain of trust. The zones that
+// could not be queried are treated as insecure without keeping the result, so they are tried again.
+func (v *dnssecValidator) zone(ctx context.Context, r resolve.Resolver, name string) *dnssecZone {
+	name = dns.Fqdn(strings.ToLower(name))
+
+	v.Lock()
+	z, found := v.zones[name]
+	v.Unlock()
+	if found {
+		return z
+	}
+
+	z, ok := v.validateZone(ctx, r, name)
+	if ok {
+		v.Lock()
+		v.zones[name] = z
+		v.Unlock()
+	}
+	return z
+}
+
+func (v *dnssecValidator) validateZone(ctx context.Context, r resolve.Resolver, zone string) (*dnssecZone, bool) {
+	insecure := &dnssecZone{status: requests.DNSSECInsecure}
+	bogus := &dnssecZone{status: requests.DNSSECBogus}
+
+	dss, anchored := v.anchors[zone]
+	if !anchored {
+		if zone == "." {
+			return insecure, true
+		}
+
+		resp := v.query(ctx, r, zone, dns.TypeDS)
+		if resp == nil {
+			return insecure, false
+		}
+
+		dsset, dssigs := rrsetFromAnswers(resp, zone, dns.TypeDS)
+		if len(dsset) == 0 {
+			return v.unsignedDelegation(ctx, r, zone, resp)
+		}
+		if len(dssigs) == 0 {
+			return bogus, true
+		}
+
+		// The DS records are signed by the parent zone
+		parent := dssigs[0].SignerName
+		if strings.EqualFold(parent, zone) || !dns.IsSubDomain(parent, zone) {
+			return bogus, true
+		}
+		if p := v.zone(ctx, r, parent); p.status != requests.DNSSECSecure {
+			return p, true
+		} else if !v.verify(dsset, dssigs, p.keys) {
+			return bogus, true
+		}
+
+		for _, rr := range dsset {
+			dss = append(dss, rr.(*dns.DS))
+		}
+	}
+
+	resp := v.query(ctx, r, zone, dns.TypeDNSKEY)
+	if resp == nil {
+		return insecure, false
+	}
+
+	keyset, keysigs := rrsetFromAnswers(resp, zone, dns.TypeDNSKEY)
+	var keys, ksks []*dns.DNSKEY
+	for _, rr := range keyset {
+		key := rr.(*dns.DNSKEY)
+
+		keys = append(keys, key)
+		if matchesDS(key, dss) {
+			ksks = append(ksks, key)
+		}
+	}
+	// The DNSKEY RRset is signed by a key referenced by the DS records
+	if len(ksks) == 0 || !v.verify(keyset, keysigs, ksks) {
+		return bogus, true
+	}
+	return &dnssecZone{status: requests.DNSSECSecure, keys: keys}, true
+}
+
+// unsignedDelegation returns the status of the zone delegated without DS records, which is insecure when
+// the parent zone is not secure, or when the secure parent zone signed the proof that the DS records do
+// not exist. Otherwise, the DS records could have been removed from the response, so the zone is bogus.
+func (v *dnssecValidator) unsignedDelegation(ctx context.Context,
+	r resolve.Resolver, zone string, resp *dns.Msg) (*dnssecZone, bool) {
+	insecure := &dnssecZone{status: requests.DNSSECInsecure}
+
+	parent, ok := v.enclosingZone(ctx, r, parentName(zone))
+	if !ok {
+		return insecure, false
+	}
+
+	p := v.zone(ctx, r, parent)
+	if p.status != requests.DNSSECSecure {
+		return &dnssecZone{status: p.status}, true
+	}
+	if v.deniesDS(zone, resp, p.keys) {
+		return insecure, true
+	}
+	return &dnssecZone{status: requests.DNSSECBogus}, true
+}
+
+// deniesDS returns true when the NSEC or NSEC3 records of the response, signed by the keys of the parent
+// zone, prove that the delegation of the zone has no DS records.
+func (v *dnssecValidator) deniesDS(zone string, resp *dns.Msg, keys []*dns.DNSKEY) bool {
+	rrsets := make(map[rrsetKey][]dns.RR)
+	sigs := make(map[rrsetKey][]*dns.RRSIG)
+	for _, rr := range resp.Ns {
+		name := strings.ToLower(rr.Header().Name)
+
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			k := rrsetKey{name: name, qtype: sig.TypeCovered}
+			sigs[k] = append(sigs[k], sig)
+		} else if t := rr.Header().Rrtype; t == dns.TypeNSEC || t == dns.TypeNSEC3 {
+			k := rrsetKey{name: name, qtype: t}
+			rrsets[k] = append(rrsets[k], rr)
+		}
+	}
+
+	var nsec3s []*dns.NSEC3
+	for k, rrset := range rrsets {
+		if !v.verify(rrset, sigs[k], keys) {
+			continue
+		}
+
+		for _, rr := range rrset {
+			switch n := rr.(type) {
+			case *dns.NSEC:
+				if strings.EqualFold(n.Hdr.Name, zone) && delegationWithoutDS(n.TypeBitMap) {
+					return true
+				}
+			case *dns.NSEC3:
+				if n.Match(zone) && delegationWithoutDS(n.TypeBitMap) {
+					return true
+				}
+				nsec3s = append(nsec3s, n)
+			}
+		}
+	}
+	return optOutDelegation(zone, nsec3s)
+}
+
+// optOutDelegation returns true when the NSEC3 records prove the closest encloser of the zone, and an
+// NSEC3 record with the opt-out flag covers the next closer name, so the delegation can be unsigned.
+func optOutDelegation(zone string, nsec3s []*dns.NSEC3) bool {
+	labels := dns.SplitDomainName(zone)
+
+	for i := 1; i < len(labels); i++ {
+		encloser := dns.Fqdn(strings.Join(labels[i:], "."))
+		next := dns.Fqdn(strings.Join(labels[i-1:], "."))
+
+		var matched bool
+		for _, n := range nsec3s {
+			if n.Match(encloser) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		for _, n := range nsec3s {
+			if n.Flags&1 == 1 && n.Cover(next) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// delegationWithoutDS returns true when the types of the NSEC or NSEC3 record belong to a delegation
+// point that does not have DS records.
+func delegationWithoutDS(types []uint16) bool {
+	var ns bool
+
+	for _, t := range types {
+		switch t {
+		case dns.TypeNS:
+			ns = true
+		case dns.TypeDS, dns.TypeSOA:
+			return false
+		}
+	}
+	return ns
+}
+
+// enclosingZone returns the closest zone containing the name, which is the first of the name and its
+// ancestors found to be the apex of a zone by the SOA queries. The SOA record in the authority section
+// of the responses provides the zone directly. False is returned when the zone could not be found.
+func (v *dnssecValidator) enclosingZone(ctx context.Context, r resolve.Resolver, name string) (string, bool) {
+	name = dns.Fqdn(strings.ToLower(name))
+
+	var walked []string
+	keep := func(zone string) (string, bool) {
+		v.Lock()
+		defer v.Unlock()
+
+		for _, n := range walked {
+			v.enclosing[n] = zone
+		}
+		return zone, true
+	}
+
+	labels := dns.SplitDomainName(name)
+	for i := range labels {
+		candidate := dns.Fqdn(strings.Join(labels[i:], "."))
+
+		v.Lock()
+		zone, found := v.enclosing[candidate]
+		v.Unlock()
+		if found {
+			return keep(zone)
+		}
+
+		walked = append(walked, candidate)
+		resp := v.exchange(ctx, r, candidate, dns.TypeSOA)
+		if resp == nil {
+			return "", false
+		}
+
+		for _, rr := range resp.Answer {
+			if rr.Header().Rrtype == dns.TypeSOA && strings.EqualFold(rr.Header().Name, candidate) {
+				return keep(candidate)
+			}
+		}
+		for _, rr := range resp.Ns {
+			if owner := strings.ToLower(rr.Header().Name); rr.Header().Rrtype == dns.TypeSOA &&
+				owner != candidate && dns.IsSubDomain(owner, candidate) {
+				walked = append(walked, owner)
+				return keep(owner)
+			}
+		}
+	}
+	return keep(".")
+}
+
+// parentName returns the name without its first label.
+func parentName(name string) string {
+	labels := dns.SplitDomainName(name)
+	if len(labels) <= 1 {
+		return "."
+	}
+	return dns.Fqdn(strings.Join(labels[1:], "."))
+}
+
+func (v *dnssecValidator) query(ctx context.Context, r resolve.Resolver, name string, qtype uint16) *dns.Msg {
+	if resp := v.exchange(ctx, r, name, qtype); resp != nil && resp.Rcode == dns.RcodeSuccess {
+		return resp
+	}
+	return nil
+}
+
+// exchange returns the response of the query, including the responses for names that do not exist.
+func (v *dnssecValidator) exchange(ctx context.Context, r resolve.Resolver, name string, qtype uint16) *dns.Msg {
+	msg := resolve.QueryMsg(name, qtype)
+	requestDNSSEC(msg)
+
+	resp, err := r.Query(ctx, msg, resolve.PriorityHigh, resolve.PoolRetryPolicy)
+	if err != nil || resp == nil || (resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError) {
+		return nil
+	}
+	return resp
+}
+
+// rrsetFromAnswers returns the records of the type owned by the name, along with their signatures.
+func rrsetFromAnswers(resp *dns.Msg, name string, qtype uint16) ([]dns.RR, []*dns.RRSIG) {
+	var rrset []dns.RR
+	var sigs []*dns.RRSIG
+
+	for _, rr := range resp.Answer {
+		if !strings.EqualFold(rr.Header().Name, name) {
+			continue
+		}
+
+		if sig, ok := rr.(*dns.RRSIG); ok && sig.TypeCovered == qtype {
+			sigs = append(sigs, sig)
+		} else if rr.Header().Rrtype == qtype {
+			rrset = append(rrset, rr)
+		}
+	}
+	return rrset, sigs
+}
+
+func matchesDS(key *dns.DNSKEY, dss []*dns.DS) bool {
+	for _, ds := range dss {
+		if key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+			continue
+		}
+		if d := key.ToDS(ds.DigestType); d != nil && strings.EqualFold(d.Digest, ds.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+// record keeps the validation status of the name, which is bogus when any of the records are bogus,
+// and secure when all the records are secure. The status is returned.
+func (v *dnssecValidator) record(name string, records []requests.DNSAnswer) string {
+	status := requests.DNSSECSecure
+	for _, rec := range records {
+		if rec.DNSSEC == requests.DNSSECBogus {
+			status = requests.DNSSECBogus
+			break
+		}
+		if rec.DNSSEC != requests.DNSSECSecure {
+			status = requests.DNSSECInsecure
+		}
+	}
+
+	name = strings.ToLower(name)
+	v.Lock()
+	defer v.Unlock()
+
+	if v.names[name] != requests.DNSSECBogus {
+		v.names[name] = status
+	}
+	return status
+}
+
+// DNSSECStatus returns the DNSSEC validation status of the records resolved for the name,
+// which is empty when the validation is not enabled or the name was not resolved.
+func (e *Enumeration) DNSSECStatus(name string) string {
+	if e.dnssec == nil {
+		return ""
+	}
+
+	e.dnssec.Lock()
+	defer e.dnssec.Unlock()
+
+	return e.dnssec.names[strings.ToLower(name)]
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"crypto"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+type signingKey struct {
+	key  *dns.DNSKEY
+	priv crypto.Signer
+}
+
+func newSigningKey(t *testing.T, zone string) *signingKey {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+
+	priv, err := key.Generate(256)
+	if err != nil {
+		t.Fatalf("Failed to generate the key for %s: %v", zone, err)
+	}
+	return &signingKey{key: key, priv: priv.(crypto.Signer)}
+}
+
+func (k *signingKey) sign(t *testing.T, rrset ...dns.RR) *dns.RRSIG {
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: rrset[0].Header().Name, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 3600},
+		Algorithm:  k.key.Algorithm,
+		SignerName: k.key.Hdr.Name,
+		KeyTag:     k.key.KeyTag(),
+		Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+		Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+	}
+
+	if err := sig.Sign(k.priv, rrset); err != nil {
+		t.Fatalf("Failed to sign the %s RRset: %v", rrset[0].Header().Name, err)
+	}
+	return sig
+}
+
+// signedResolver serves the signed org and owasp.org zones, along with the unsigned example.com zone.
+// The owasp.org zone delegates the unsigned dev.owasp.org and dev3.owasp.org zones, with the NSEC and
+// NSEC3 proofs that their DS records do not exist, and the unsigned stripped.owasp.org zone without a proof.
+type signedResolver struct {
+	answers map[rrsetKey][]dns.RR
+	sigs    map[rrsetKey][]dns.RR
+	zones   map[string]*dns.SOA
+	denials map[rrsetKey][]dns.RR
+}
+
+func (r *signedResolver) String() string { return "signed" }
+func (r *signedResolver) Len() int       { return 0 }
+func (r *signedResolver) Stop()          {}
+func (r *signedResolver) Stopped() bool  { return false }
+
+func (r *signedResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	q := msg.Question[0]
+	k := rrsetKey{name: q.Name, qtype: q.Qtype}
+	resp.Answer = append(resp.Answer, r.answers[k]...)
+	// The signatures are only provided when requested with the DO bit
+	do := msg.IsEdns0() != nil && msg.IsEdns0().Do()
+	if do {
+		resp.Answer = append(resp.Answer, r.sigs[k]...)
+	}
+
+	// The responses without answers provide the SOA record of the zone containing the name
+	if len(resp.Answer) == 0 {
+		if soa := r.closestZone(q.Name); soa != nil {
+			resp.Ns = append(resp.Ns, soa)
+		}
+		for _, rr := range r.denials[k] {
+			if _, sig := rr.(*dns.RRSIG); !sig || do {
+				resp.Ns = append(resp.Ns, rr)
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (r *signedResolver) closestZone(name string) *dns.SOA {
+	for labels := dns.SplitDomainName(name); len(labels) > 0; labels = labels[1:] {
+		if soa, found := r.zones[dns.Fqdn(strings.Join(labels, "."))]; found {
+			return soa
+		}
+	}
+	return nil
+}
+
+func (r *signedResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func newSignedResolver(t *testing.T) (*signedResolver, *dns.DS) {
+	r := &signedResolver{
+		answers: make(map[rrsetKey][]dns.RR),
+		sigs:    make(map[rrsetKey][]dns.RR),
+		zones:   make(map[string]*dns.SOA),
+		denials: make(map[rrsetKey][]dns.RR),
+	}
+	org := newSigningKey(t, "org.")
+	owasp := newSigningKey(t, "owasp.org.")
+
+	add := func(signer *signingKey, rr dns.RR) {
+		k := rrsetKey{name: rr.Header().Name, qtype: rr.Header().Rrtype}
+
+		r.answers[k] = append(r.answers[k], rr)
+		if signer != nil {
+			r.sigs[k] = []dns.RR{signer.sign(t, r.answers[k]...)}
+		}
+	}
+	addr := func(name, ip string) *dns.A {
+		return &dns.A{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP(ip),
+		}
+	}
+
+	zone := func(name string) {
+		soa := &dns.SOA{
+			Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+			Ns:     "ns." + name,
+			Mbox:   "hostmaster." + name,
+			Serial: 1,
+		}
+
+		r.zones[name] = soa
+		r.answers[rrsetKey{name: name, qtype: dns.TypeSOA}] = []dns.RR{soa}
+	}
+	// The denial of existence of the DS records of the delegation, signed by the parent zone
+	deny := func(zone string, rr dns.RR) {
+		k := rrsetKey{name: zone, qtype: dns.TypeDS}
+		r.denials[k] = append(r.denials[k], rr, owasp.sign(t, rr))
+	}
+
+	for _, name := range []string{"org.", "owasp.org.", "example.com.", "dev.owasp.org.", "dev3.owasp.org.", "stripped.owasp.org."} {
+		zone(name)
+	}
+	deny("dev.owasp.org.", &dns.NSEC{
+		Hdr:        dns.RR_Header{Name: "dev.owasp.org.", Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 3600},
+		NextDomain: "dev3.owasp.org.",
+		TypeBitMap: []uint16{dns.TypeNS, dns.TypeRRSIG, dns.TypeNSEC},
+	})
+	deny("dev3.owasp.org.", &dns.NSEC3{
+		Hdr: dns.RR_Header{Name: dns.HashName("dev3.owasp.org.", dns.SHA1, 1, "AB") + ".owasp.org.",
+			Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 3600},
+		Hash:       dns.SHA1,
+		Iterations: 1,
+		SaltLength: 1,
+		Salt:       "AB",
+		HashLength: 20,
+		NextDomain: dns.HashName("zzz.owasp.org.", dns.SHA1, 1, "AB"),
+		TypeBitMap: []uint16{dns.TypeNS},
+	})
+
+	add(org, org.key)
+	add(owasp, owasp.key)
+	add(org, owasp.key.ToDS(dns.SHA256))
+	add(owasp, addr("www.owasp.org.", "192.0.2.1"))
+	add(nil, addr("nosig.owasp.org.", "192.0.2.3"))
+	add(nil, addr("www.example.com.", "192.0.2.4"))
+	add(nil, addr("www.dev.owasp.org.", "192.0.2.5"))
+	add(nil, addr("www.dev3.owasp.org.", "192.0.2.6"))
+	add(nil, addr("www.stripped.owasp.org.", "192.0.2.7"))
+	// The address was changed after the record was signed
+	add(owasp, addr("bad.owasp.org.", "192.0.2.2"))
+	r.answers[rrsetKey{name: "bad.owasp.org.", qtype: dns.TypeA}][0].(*dns.A).A = net.ParseIP("192.0.2.66")
+
+	return r, org.key.ToDS(dns.SHA256)
+}
+
+func TestValidateDNSSEC(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.AddDomain("example.com")
+	cfg.ValidateDNSSEC = true
+
+	res, anchor := newSignedResolver(t)
+	r := newTestEnumSource(cfg)
+	r.enum.Sys = &systems.SimpleSystem{Resolver: res}
+	r.enum.dnssec = newDNSSECValidator()
+	r.enum.dnssec.anchors = map[string][]*dns.DS{"org.": {anchor}}
+	dt := newDNSTask(r.enum)
+
+	for _, test := range []struct {
+		name   string
+		domain string
+		status string
+	}{
+		{"www.owasp.org", "owasp.org", requests.DNSSECSecure},
+		{"bad.owasp.org", "owasp.org", requests.DNSSECBogus},
+		// The signatures of the records in the signed zone were stripped
+		{"nosig.owasp.org", "owasp.org", requests.DNSSECBogus},
+		{"www.example.com", "example.com", requests.DNSSECInsecure},
+		// The unsigned child zones of the signed zone, with the proofs that their DS records do not exist
+		{"www.dev.owasp.org", "owasp.org", requests.DNSSECInsecure},
+		{"www.dev3.owasp.org", "owasp.org", requests.DNSSECInsecure},
+		// The DS records of the child zone could have been stripped, since their absence was not proven
+		{"www.stripped.owasp.org", "owasp.org", requests.DNSSECBogus},
+	} {
+		req := &requests.DNSRequest{Name: test.name, Domain: test.domain, Tag: requests.DNS, Source: "DNS"}
+
+		out, err := dt.processDNSRequest(context.Background(), req, nil)
+		if err != nil || out == nil {
+			t.Fatalf("Failed to resolve %s: %v", test.name, err)
+		}
+		if len(req.Records) != 1 || req.Records[0].DNSSEC != test.status {
+			t.Errorf("The records of %s were not tagged as %s: %v", test.name, test.status, req.Records)
+		}
+		if status := r.enum.DNSSECStatus(test.name); status != test.status {
+			t.Errorf("The DNSSEC status of %s was %s instead of %s", test.name, status, test.status)
+		}
+	}
+
+	cfg.DNSSECDropBogus = true
+	req := &requests.DNSRequest{Name: "bad.owasp.org", Domain: "owasp.org", Tag: requests.DNS, Source: "DNS"}
+	if out, err := dt.processDNSRequest(context.Background(), req, nil); err != nil || out != nil {
+		t.Errorf("The name with bogus records continued through the pipeline: %v", req.Records)
+	}
+
+	// The names of the unsigned child zone are not dropped
+	req = &requests.DNSRequest{Name: "www.dev.owasp.org", Domain: "owasp.org", Tag: requests.DNS, Source: "DNS"}
+	if out, err := dt.processDNSRequest(context.Background(), req, nil); err != nil || out == nil {
+		t.Errorf("The name of the unsigned child zone was dropped: %v", req.Records)
+	}
+}
+
+func TestValidateDNSSECExpiredSignatures(t *testing.T) {
+	res, anchor := newSignedResolver(t)
+	v := newDNSSECValidator()
+	v.anchors = map[string][]*dns.DS{"org.": {anchor}}
+	v.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+	requestDNSSEC(msg)
+	resp, _ := res.Query(context.Background(), msg, resolve.PriorityNormal, nil)
+
+	zoneOf := func(name string) string { return "owasp.org" }
+	if statuses := v.validateResponse(context.Background(), res, resp, zoneOf); len(statuses) != 1 ||
+		statuses[rrsetKey{name: "www.owasp.org", qtype: dns.TypeA}] != requests.DNSSECBogus {
+		t.Errorf("The records with expired signatures were not bogus: %v", statuses)
+	}
+}
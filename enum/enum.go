@@ -5,11 +5,14 @@ package enum
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -24,25 +27,70 @@ const (
 	maxDNSPipelineTasks    int = 7500
 	maxStorePipelineTasks  int = 50
 	maxActivePipelineTasks int = 25
+	maxVerifyPipelineTasks int = 25
 )
 
+// OutputHook is called for each result that leaves the enumeration pipeline.
+type OutputHook func(data pipeline.Data)
+
 // Enumeration is the object type used to execute a DNS enumeration.
 type Enumeration struct {
-	Config      *config.Config
-	Bus         *eventbus.EventBus
-	Sys         systems.System
-	Graph       *netmap.Graph
+	Config *config.Config
+	Bus    *eventbus.EventBus
+	Sys    systems.System
+	Graph  *netmap.Graph
+
 	closedOnce  sync.Once
 	logQueue    queue.Queue
 	ctx         context.Context
+	cancel      context.CancelFunc
 	srcs        []service.Service
+	tiers       *sourceTiers
 	done        chan struct{}
 	doneOnce    sync.Once
 	crawlFilter *stringset.Set
-	nameSrc     *enumSource
+	suspects    *stringset.Set
+	authNames   *stringset.Set
+	webLock     sync.Mutex
+	webServers  map[string]*http.Fingerprint
+	provLock    sync.Mutex
+	provenance  map[string][]requests.DiscoveryStep
+	scanLock    sync.Mutex
+	scanAddrs   map[string][]int
+	expandLock  sync.Mutex
+	expanded    map[int]struct{}
+	bruteLock   sync.Mutex
+	bruteCount  int
+	bruteCapped bool
+	brutePace   *brutePacer
+	phases      *enumPhases
+	dnssec      *dnssecValidator
+	xfrLock     sync.Mutex
+	xfrs        []*ZoneTransferResult
+	deadLock    sync.Mutex
+	deadZones   []string
 	subTask     *subdomainTask
 	dnsTask     *dNSTask
 	store       *dataManager
+	hookLock    sync.Mutex
+	hooks       []OutputHook
+	unresolved  []OutputHook
+	callbacks   chan pipeline.Data
+	// Protects the pipeline input source created by Start, and the pause or flush requested before it
+	srcLock      sync.Mutex
+	nameSrc      *enumSource
+	pausePending bool
+	flushPending bool
+	// Protects the input source and the data submitted before Start
+	inputLock sync.Mutex
+	inputSrc  *enumSource
+	pending   []pipeline.Data
+	// Protects the progress channel and the progressClosed field
+	progressLock   sync.Mutex
+	progress       chan ProgressUpdate
+	progressClosed bool
+	// Paces the names generated by the enumeration when it shares the System with others
+	orchestrator *Orchestrator
 }
 
 // NewEnumeration returns an initialized Enumeration that has not been started yet.
@@ -56,12 +104,25 @@ func NewEnumeration(cfg *config.Config, sys systems.System) *Enumeration {
 		logQueue:    queue.NewQueue(),
 		done:        make(chan struct{}),
 		crawlFilter: stringset.New(),
+		suspects:    stringset.New(),
+		authNames:   stringset.New(),
+		webServers:  make(map[string]*http.Fingerprint),
+		scanAddrs:   make(map[string][]int),
+		expanded:    make(map[int]struct{}),
 	}
+	e.tiers = newSourceTiers(cfg, e.srcs)
 
 	if cfg.Passive {
 		return e
 	}
 
+	e.brutePace = newBrutePacer(cfg.BruteMaxRate)
+	if cfg.Phased {
+		e.phases = newEnumPhases()
+	}
+	if cfg.ValidateDNSSEC {
+		e.dnssec = newDNSSECValidator()
+	}
 	e.dnsTask = newDNSTask(e)
 	e.subTask = newSubdomainTask(e)
 	e.store = newDataManager(e)
@@ -74,37 +135,168 @@ func (e *Enumeration) Close() {
 		e.Bus.Stop()
 		e.Graph.Close()
 		e.crawlFilter.Close()
+		e.suspects.Close()
+		e.authNames.Close()
 	})
 }
 
+func (e *Enumeration) stopped() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+	}
+	return false
+}
+
 func (e *Enumeration) stop() {
 	e.doneOnce.Do(func() {
 		close(e.done)
 	})
 }
 
+// AddOutputHook registers a hook that receives the results as they are discovered.
+func (e *Enumeration) AddOutputHook(hook OutputHook) {
+	e.hookLock.Lock()
+	defer e.hookLock.Unlock()
+
+	e.hooks = append(e.hooks, hook)
+}
+
+// AddUnresolvedHook registers a hook that receives the in-scope names generated by brute forcing,
+// alterations and guessing that returned NXDOMAIN, which do not leave the pipeline as results.
+// The hook is called from the DNS resolution stage, and is required to be safe for concurrent use.
+func (e *Enumeration) AddUnresolvedHook(hook OutputHook) {
+	e.hookLock.Lock()
+	defer e.hookLock.Unlock()
+
+	e.unresolved = append(e.unresolved, hook)
+}
+
+func (e *Enumeration) runUnresolvedHooks(req *requests.DNSRequest) {
+	e.hookLock.Lock()
+	hooks := e.unresolved
+	e.hookLock.Unlock()
+
+	for _, hook := range hooks {
+		hook(req)
+	}
+}
+
+func (e *Enumeration) runOutputHooks(data pipeline.Data) {
+	e.hookLock.Lock()
+	hooks := e.hooks
+	e.hookLock.Unlock()
+
+	for _, hook := range hooks {
+		hook(data)
+	}
+}
+
+// source returns the pipeline input source, which is nil until the enumeration has been started.
+func (e *Enumeration) source() *enumSource {
+	e.srcLock.Lock()
+	defer e.srcLock.Unlock()
+
+	return e.nameSrc
+}
+
+// setSource makes the input source available to the callers of the enumeration, and applies
+// the pause or flush requested before the enumeration was started.
+func (e *Enumeration) setSource(src *enumSource) {
+	e.srcLock.Lock()
+	defer e.srcLock.Unlock()
+
+	e.nameSrc = src
+	if e.flushPending {
+		src.markDraining()
+	} else if e.pausePending {
+		src.pause()
+	}
+}
+
+// Pause blocks the release of new data into the enumeration pipeline until Resume is called.
+// The enumeration is started paused when Pause is called before Start.
+func (e *Enumeration) Pause() {
+	e.srcLock.Lock()
+	defer e.srcLock.Unlock()
+
+	e.pausePending = true
+	if e.nameSrc != nil {
+		e.nameSrc.pause()
+	}
+}
+
+// Resume releases an enumeration previously paused and restarts the idle timer.
+func (e *Enumeration) Resume() {
+	e.srcLock.Lock()
+	defer e.srcLock.Unlock()
+
+	e.pausePending = false
+	if e.nameSrc != nil {
+		e.nameSrc.unpause()
+	}
+}
+
+// StopAndFlush stops the enumeration from accepting new names and addresses from the
+// data sources, while allowing the data already queued to complete the pipeline. The
+// enumeration is terminated immediately when ctx expires before the queue is empty.
+// When called before Start, the enumeration stops accepting the data once started.
+func (e *Enumeration) StopAndFlush(ctx context.Context) {
+	e.srcLock.Lock()
+	e.flushPending = true
+	if e.nameSrc != nil {
+		e.nameSrc.markDraining()
+		// A paused enumeration would never release the queued data
+		e.nameSrc.unpause()
+	}
+	e.srcLock.Unlock()
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		e.stop()
+	}
+}
+
 // Start begins the vertical domain correlation process.
 func (e *Enumeration) Start(ctx context.Context) error {
 	if err := e.Config.CheckSettings(); err != nil {
 		return err
 	}
 	e.setupContext(ctx)
+	stopTimer := e.enforceMaxDuration()
+	defer stopTimer()
 
 	// The pipeline input source will receive all the names
-	e.nameSrc = newEnumSource(e)
+	e.setSource(newEnumSource(e))
+	e.startCallbacks()
 	e.startupAndCleanup()
 	defer e.stop()
 
+	if e.Config.ScopeURL != "" {
+		if _, err := e.Config.LoadScopeURL(e.ctx); err != nil {
+			e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh, err.Error())
+		}
+		go e.refreshScope()
+	}
+	if e.Config.EnableMDNS {
+		go e.localDiscovery(e.ctx)
+	}
+
 	var stages []pipeline.Stage
 	if !e.Config.Passive {
 		stages = append(stages, pipeline.FIFO("", e.dnsTask.blacklistTaskFunc()))
 		stages = append(stages, pipeline.FIFO("root", e.dnsTask.rootTaskFunc()))
-		stages = append(stages, pipeline.DynamicPool("dns", e.dnsTask, e.min()))
+		stages = append(stages, e.poolStage("dns", e.withDeadline("dns", e.dnsTask), e.min(), true))
 	}
 
 	stages = append(stages, pipeline.FIFO("filter", e.filterTaskFunc()))
+	if e.Config.HTTPVerify {
+		stages = append(stages, e.poolStage("verify", e.withDeadline("verify", newHTTPVerifier(e)), maxVerifyPipelineTasks, false))
+	}
 	if !e.Config.Passive {
-		stages = append(stages, pipeline.FixedPool("store", e.store, maxStorePipelineTasks))
+		stages = append(stages, e.poolStage("store", e.store, maxStorePipelineTasks, false))
 		stages = append(stages, pipeline.FIFO("", e.subTask))
 	}
 	if e.Config.Active {
@@ -118,7 +310,13 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	 * by the user and names acquired from the graph database can be brought
 	 * into the enumeration
 	 */
+	// The dead zones are found before the names in them are submitted to the active techniques
+	if e.Config.SkipDeadZones && !e.Config.Passive {
+		e.checkDeadZones()
+	}
+
 	var wg sync.WaitGroup
+	e.releasePendingInput(e.nameSrc)
 	wg.Add(4)
 	go e.submitKnownNames(&wg)
 	go e.submitProvidedNames(&wg)
@@ -135,11 +333,19 @@ func (e *Enumeration) Start(ctx context.Context) error {
 		e.store.signalDone <- struct{}{}
 		<-e.store.confirmDone
 	}
+	e.storeMetadata(e.ctx)
+	// The checkpoint is only kept for enumerations that were interrupted
+	if err == nil && e.ctx.Err() == nil && !e.stopped() && !e.nameSrc.draining() {
+		e.nameSrc.markCompleted()
+	}
 	return err
 }
 
 func (e *Enumeration) min() int {
 	num := e.Config.MaxDNSQueries
+	if c := e.Config.ActiveConcurrency; c > 0 && (num < 1 || c < num) {
+		num = c
+	}
 	if num > maxDNSPipelineTasks {
 		return maxDNSPipelineTasks
 	}
@@ -156,23 +362,35 @@ func (e *Enumeration) startupAndCleanup() {
 	 */
 	e.Bus.Subscribe(requests.NewNameTopic, e.nameSrc.dataSourceName)
 	e.Bus.Subscribe(requests.LogTopic, e.queueLog)
+	e.Bus.Subscribe(requests.NewScopeTopic, e.releaseScope)
 	if !e.Config.Passive {
 		e.Bus.Subscribe(requests.NewAddrTopic, e.nameSrc.dataSourceAddr)
 		e.Bus.Subscribe(requests.NewASNTopic, e.Sys.Cache().Update)
+		if e.Config.ExpandByASN {
+			e.Bus.Subscribe(requests.NewASNTopic, e.asnScopeUpdate)
+		}
 	}
 
 	go e.periodicLogging()
+	go e.periodicProgress()
+	if e.tiers.remaining() {
+		go e.manageTiers()
+	}
 	go func() {
 		<-e.done
 		e.Bus.Unsubscribe(requests.NewNameTopic, e.nameSrc.dataSourceName)
 		e.Bus.Unsubscribe(requests.LogTopic, e.queueLog)
+		e.Bus.Unsubscribe(requests.NewScopeTopic, e.releaseScope)
 
 		if !e.Config.Passive {
 			e.Bus.Unsubscribe(requests.NewAddrTopic, e.nameSrc.dataSourceAddr)
 			e.Bus.Unsubscribe(requests.NewASNTopic, e.Sys.Cache().Update)
-			e.nameSrc.Stop()
+			if e.Config.ExpandByASN {
+				e.Bus.Unsubscribe(requests.NewASNTopic, e.asnScopeUpdate)
+			}
 			e.subTask.Stop()
 		}
+		e.nameSrc.Stop()
 		e.writeLogs(true)
 	}()
 }
@@ -189,8 +407,26 @@ func (e *Enumeration) setupContext(ctx context.Context) {
 	}()
 
 	newctx = context.WithValue(newctx, requests.ContextConfig, e.Config)
+	newctx = e.Config.ProxyContext(newctx)
 	newctx = context.WithValue(newctx, requests.ContextEventBus, e.Bus)
 	e.ctx = newctx
+	e.cancel = cancel
+}
+
+// enforceMaxDuration cancels the enumeration context once the maximum duration has elapsed.
+// The returned function must be called to release the timer.
+func (e *Enumeration) enforceMaxDuration() func() bool {
+	max := e.Config.MaxDuration
+	if max <= 0 {
+		return func() bool { return false }
+	}
+
+	t := time.AfterFunc(max, func() {
+		e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("The enumeration ended after reaching the maximum duration of %s", max))
+		e.cancel()
+	})
+	return t.Stop
 }
 
 // Release the root domain names to the input source and each data source.
@@ -198,16 +434,53 @@ func (e *Enumeration) submitDomainNames(wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for _, domain := range e.Config.Domains() {
-		req := &requests.DNSRequest{
-			Name:   domain,
-			Domain: domain,
-			Tag:    requests.DNS,
-			Source: "DNS",
-		}
+		e.submitDomainName(domain)
+	}
+}
 
-		e.nameSrc.dataSourceName(req)
-		for _, src := range e.srcs {
-			src.Request(e.ctx, req.Clone().(*requests.DNSRequest))
+func (e *Enumeration) submitDomainName(domain string) {
+	req := &requests.DNSRequest{
+		Name:   domain,
+		Domain: domain,
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}
+
+	// The input source sanitizes its copy while the request is cloned for the data sources
+	e.nameSrc.dataSourceName(req.Clone().(*requests.DNSRequest))
+	// Zones restored from a checkpoint have already been requested from the data sources
+	if e.nameSrc.zones.Has(domain) {
+		return
+	}
+
+	e.nameSrc.zones.Insert(domain)
+	for _, src := range e.activeSources() {
+		src.Request(e.ctx, req.Clone().(*requests.DNSRequest))
+	}
+}
+
+// Periodically fetch the scope endpoint and release the newly added root domain names.
+// The scope already in the configuration continues to be used when a fetch fails.
+func (e *Enumeration) refreshScope() {
+	t := time.NewTicker(e.Config.ScopeRefreshInterval())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-t.C:
+			domains, err := e.Config.LoadScopeURL(e.ctx)
+			if err != nil {
+				e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh, err.Error())
+				continue
+			}
+
+			for _, domain := range domains {
+				e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+					fmt.Sprintf("Scope URL: %s has been added to the scope", domain))
+				e.submitDomainName(domain)
+			}
 		}
 	}
 }
@@ -220,7 +493,7 @@ func (e *Enumeration) submitASNs(wg *sync.WaitGroup) {
 	for _, asn := range e.Config.ASNs {
 		req := &requests.ASNRequest{ASN: asn}
 
-		for _, src := range e.srcs {
+		for _, src := range e.activeSources() {
 			src.Request(e.ctx, req.Clone().(*requests.ASNRequest))
 		}
 	}
@@ -228,6 +501,8 @@ func (e *Enumeration) submitASNs(wg *sync.WaitGroup) {
 
 func (e *Enumeration) makeOutputSink() pipeline.SinkFunc {
 	return pipeline.SinkFunc(func(ctx context.Context, data pipeline.Data) error {
+		e.runOutputHooks(data)
+		e.queueCallback(data)
 		if !e.Config.Passive {
 			return nil
 		}
@@ -236,6 +511,8 @@ func (e *Enumeration) makeOutputSink() pipeline.SinkFunc {
 		if ok && req != nil && req.Name != "" && e.Config.IsDomainInScope(req.Name) {
 			if _, err := e.Graph.UpsertFQDN(e.ctx, req.Name, req.Source, e.Config.UUID.String()); err != nil {
 				e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh, err.Error())
+			} else {
+				e.markSeen(e.ctx, req.Name)
 			}
 		}
 		return nil
@@ -350,6 +627,18 @@ func (e *Enumeration) submitProvidedNames(wg *sync.WaitGroup) {
 	}
 }
 
+// poolStage returns the stage executing the task concurrently, or one data element
+// at a time, in the order received, when the enumeration is deterministic.
+func (e *Enumeration) poolStage(id string, task pipeline.Task, size int, dynamic bool) pipeline.Stage {
+	if e.Config.Deterministic {
+		return pipeline.FIFO(id, task)
+	}
+	if dynamic {
+		return pipeline.DynamicPool(id, task, size)
+	}
+	return pipeline.FixedPool(id, task, size)
+}
+
 func (e *Enumeration) queueLog(msg string) {
 	e.logQueue.Append(msg)
 }
@@ -366,9 +655,7 @@ func (e *Enumeration) writeLogs(all bool) {
 			break
 		}
 
-		if e.Config.Log != nil {
-			e.Config.Log.Print(msg.(string))
-		}
+		e.Config.Leveled().Info(msg.(string), logFields(msg.(string))...)
 
 		if !all && i >= num {
 			break
@@ -376,6 +663,15 @@ func (e *Enumeration) writeLogs(all bool) {
 	}
 }
 
+// logFields returns the source field for the messages that start with the name of the
+// data source or subsystem that published them, such as "AlienVault: ...".
+func logFields(msg string) []config.LogField {
+	if i := strings.Index(msg, ": "); i > 0 && !strings.ContainsAny(msg[:i], ":\n") {
+		return []config.LogField{config.SourceField(msg[:i])}
+	}
+	return nil
+}
+
 func (e *Enumeration) periodicLogging() {
 	t := time.NewTimer(5 * time.Second)
 
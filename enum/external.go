@@ -0,0 +1,221 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/pipeline"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// The source assigned to names and addresses submitted without one
+	externalInputSource = "External Input"
+	// The source assigned to the hosts imported from port scanner output
+	scanImportSource = "Scan Import"
+	// The source assigned to the names imported from the output of a previous enumeration
+	priorOutputSource = "Prior Output"
+)
+
+// InputName submits a name from a source outside of the enumeration, such as a message queue
+// of hostnames observed in network traffic. The name receives the same scope checks, filtering
+// and resolution as names from the data sources.
+//
+// InputName is safe to call from any goroutine. Names submitted before Start are held and
+// released once the pipeline has been set up, and names submitted after the enumeration
+// has completed, or while it is being stopped, are dropped. The enumeration completes after
+// waiting for new data for the MaxWaitForData duration, which producers that submit names
+// slowly should increase.
+//
+// Internationalized names can be submitted in the Unicode or punycode form, and are converted to
+// the punycode form. The names rejected by requests.NormalizeName, such as names containing labels
+// that are not valid internationalized labels, are dropped.
+func (e *Enumeration) InputName(req *requests.DNSRequest) {
+	if req == nil || req.Name == "" {
+		return
+	}
+
+	name, err := requests.NormalizeName(req.Name)
+	if err != nil {
+		return
+	}
+
+	req = req.Clone().(*requests.DNSRequest)
+	req.Name = name
+	if req.Domain == "" {
+		req.Domain = e.Config.WhichDomain(req.Name)
+	}
+	if req.Tag == "" {
+		req.Tag = requests.EXTERNAL
+	}
+	if req.Source == "" {
+		req.Source = externalInputSource
+	}
+
+	e.input(req)
+}
+
+// InputAddress submits an address from a source outside of the enumeration. Only addresses
+// with the InScope field set are investigated, and the addresses rejected by requests.NormalizeAddress
+// are dropped. The lifecycle is the same as InputName.
+func (e *Enumeration) InputAddress(req *requests.AddrRequest) {
+	if req == nil || req.Address == "" {
+		return
+	}
+
+	addr, err := requests.NormalizeAddress(req.Address)
+	if err != nil {
+		return
+	}
+
+	req = req.Clone().(*requests.AddrRequest)
+	req.Address = addr
+	if req.Tag == "" {
+		req.Tag = requests.EXTERNAL
+	}
+	if req.Source == "" {
+		req.Source = externalInputSource
+	}
+
+	e.input(req)
+}
+
+// InputScannedHost submits a live host found by a port scanner as an in-scope address. The names
+// reported by the scanner, and the name later found by the reverse DNS query of the address, are
+// treated as in scope, adding their root domain names to the enumeration. The open ports are stored
+// on the address in the graph once the enumeration completes. The lifecycle is the same as InputName.
+func (e *Enumeration) InputScannedHost(addr string, names []string, ports ...int) {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return
+	}
+
+	e.scanLock.Lock()
+	e.scanAddrs[addr] = append(e.scanAddrs[addr], ports...)
+	e.scanLock.Unlock()
+
+	e.InputAddress(&requests.AddrRequest{
+		Address: addr,
+		InScope: true,
+		Tag:     requests.EXTERNAL,
+		Source:  scanImportSource,
+	})
+	for _, name := range names {
+		e.addScanScope(name)
+		e.InputName(&requests.DNSRequest{
+			Name:   name,
+			Tag:    requests.EXTERNAL,
+			Source: scanImportSource,
+		})
+	}
+}
+
+// InputPriorNames submits the names found in the output of a previous enumeration, so recursive
+// brute forcing and alterations build on them as soon as the enumeration starts. Only the names
+// in scope are submitted, and they pass through the same filter as the names from the data
+// sources. The number of names submitted is returned. The lifecycle is the same as InputName.
+func (e *Enumeration) InputPriorNames(names []string) int {
+	var count int
+
+	for _, name := range names {
+		if !e.Config.IsDomainInScope(name) {
+			continue
+		}
+
+		e.InputName(&requests.DNSRequest{
+			Name:   name,
+			Tag:    requests.EXTERNAL,
+			Source: priorOutputSource,
+		})
+		count++
+	}
+	return count
+}
+
+// scannedAddress returns true when the address was imported from port scanner output.
+func (e *Enumeration) scannedAddress(addr string) bool {
+	e.scanLock.Lock()
+	defer e.scanLock.Unlock()
+
+	_, found := e.scanAddrs[addr]
+	return found
+}
+
+// addScanScope adds the root domain name of a name belonging to a scanned host to the scope.
+func (e *Enumeration) addScanScope(name string) {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(strings.Trim(name, ".")))
+	if err != nil {
+		return
+	}
+
+	e.addScope(domain, "Scan import")
+}
+
+// addScope adds the root domain name to the scope, and submits the domain once the enumeration
+// has started. The reason identifies what brought the domain into scope in the log message.
+func (e *Enumeration) addScope(domain, reason string) {
+	e.scanLock.Lock()
+	if e.Config.DomainRegex(domain) != nil {
+		e.scanLock.Unlock()
+		return
+	}
+	e.Config.AddDomain(domain)
+	e.scanLock.Unlock()
+
+	e.releaseScope(domain, reason)
+}
+
+// releaseScope submits the root domain name added to the scope, such as by a data source, once the
+// enumeration has started. The domains added before Start are submitted with the rest of the scope.
+func (e *Enumeration) releaseScope(domain, reason string) {
+	e.inputLock.Lock()
+	started := e.inputSrc != nil
+	e.inputLock.Unlock()
+	if started {
+		e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("%s: %s has been added to the scope", reason, domain))
+		e.submitDomainName(domain)
+	}
+}
+
+func (e *Enumeration) input(data pipeline.Data) {
+	e.inputLock.Lock()
+	defer e.inputLock.Unlock()
+
+	if e.stopped() {
+		return
+	}
+	// The pipeline has not been set up yet
+	if e.inputSrc == nil {
+		e.pending = append(e.pending, data)
+		return
+	}
+
+	e.submitInput(e.inputSrc, data)
+}
+
+// releasePendingInput attaches the input source and submits the data held since before Start.
+func (e *Enumeration) releasePendingInput(src *enumSource) {
+	e.inputLock.Lock()
+	defer e.inputLock.Unlock()
+
+	e.inputSrc = src
+	for _, data := range e.pending {
+		e.submitInput(src, data)
+	}
+	e.pending = nil
+}
+
+func (e *Enumeration) submitInput(src *enumSource, data pipeline.Data) {
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		src.dataSourceName(v)
+	case *requests.AddrRequest:
+		src.dataSourceAddr(v)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestInputBeforeStartAndAfterStop(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	e := &Enumeration{
+		Config: cfg,
+		done:   make(chan struct{}),
+	}
+
+	e.InputName(&requests.DNSRequest{Name: "WWW.owasp.org"})
+	e.InputAddress(&requests.AddrRequest{Address: "192.168.1.1", InScope: true})
+	if len(e.pending) != 2 {
+		t.Fatalf("Expected 2 requests held until Start, but %d were held", len(e.pending))
+	}
+
+	req, ok := e.pending[0].(*requests.DNSRequest)
+	if !ok || req.Name != "www.owasp.org" || req.Domain != "owasp.org" ||
+		req.Tag != requests.EXTERNAL || req.Source != externalInputSource {
+		t.Errorf("The name was not held with the expected values: %+v", e.pending[0])
+	}
+
+	e.stop()
+	e.InputName(&requests.DNSRequest{Name: "ftp.owasp.org"})
+	if len(e.pending) != 2 {
+		t.Errorf("The name submitted after the enumeration stopped was not dropped")
+	}
+}
+
+func TestInputInternationalizedName(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("xn--bcher-kva.de")
+
+	e := &Enumeration{
+		Config: cfg,
+		done:   make(chan struct{}),
+	}
+
+	e.InputName(&requests.DNSRequest{Name: " Shop.Bücher.de. "})
+	e.InputName(&requests.DNSRequest{Name: "bü_cher.bücher.de"})
+	if len(e.pending) != 1 {
+		t.Fatalf("Expected the invalid name to be dropped, but %d requests were held", len(e.pending))
+	}
+
+	req := e.pending[0].(*requests.DNSRequest)
+	if req.Name != "shop.xn--bcher-kva.de" || req.Domain != "xn--bcher-kva.de" {
+		t.Errorf("The name was not held in the punycode form: %s (%s)", req.Name, req.Domain)
+	}
+}
+
+func TestInputScannedHost(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	e := &Enumeration{
+		Config:    cfg,
+		done:      make(chan struct{}),
+		scanAddrs: make(map[string][]int),
+	}
+
+	e.InputScannedHost("192.0.2.1", []string{"host1.example.com."}, 80, 443)
+	if len(e.pending) != 2 {
+		t.Fatalf("Expected the address and name held until Start, but %d requests were held", len(e.pending))
+	}
+	if req, ok := e.pending[0].(*requests.AddrRequest); !ok || !req.InScope || req.Source != scanImportSource {
+		t.Errorf("The address was not held as an in-scope scan import: %+v", e.pending[0])
+	}
+	if !cfg.IsDomainInScope("www.example.com") {
+		t.Errorf("The root domain of the name reported by the scanner was not added to the scope")
+	}
+	if !e.scannedAddress("192.0.2.1") || e.scannedAddress("192.0.2.2") {
+		t.Errorf("The scanned addresses were not tracked")
+	}
+	if ports := e.scanAddrs["192.0.2.1"]; len(ports) != 2 || ports[0] != 80 || ports[1] != 443 {
+		t.Errorf("The open ports of the scanned address were not tracked: %v", ports)
+	}
+}
+
+func TestInputPriorNames(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	e := &Enumeration{
+		Config: cfg,
+		done:   make(chan struct{}),
+	}
+
+	if n := e.InputPriorNames([]string{"www.owasp.org", "www.example.com", "api.owasp.org"}); n != 2 {
+		t.Errorf("Expected the 2 names in scope to be submitted, but %d were submitted", n)
+	}
+	if len(e.pending) != 2 {
+		t.Fatalf("Expected 2 names held until Start, but %d were held", len(e.pending))
+	}
+	if req, ok := e.pending[1].(*requests.DNSRequest); !ok || req.Name != "api.owasp.org" ||
+		req.Domain != "owasp.org" || req.Source != priorOutputSource {
+		t.Errorf("The name was not held with the expected values: %+v", e.pending[1])
+	}
+}
@@ -4,14 +4,21 @@
 package enum
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/filter"
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
@@ -25,40 +32,103 @@ const (
 	defaultSweepSize  = 100
 	activeSweepSize   = 200
 	numDataItemsInput = 100
+	// The number of trusted elements released before an untrusted element is considered
+	maxTrustedRun = 10
+	// The number of untrusted elements queued before intake blocks for the queue to drain
+	maxUntrustedQueued = 10000
 )
 
 // enumSource handles the filtering and release of new Data in the enumeration.
 type enumSource struct {
-	enum        *Enumeration
-	queue       queue.Queue
-	dups        queue.Queue
-	sweeps      queue.Queue
-	filter      *stringset.Set
+	enum       *Enumeration
+	queue      queue.Queue
+	untrusted  queue.Queue
+	trustedRun int
+	dups       queue.Queue
+	sweeps     queue.Queue
+	filterLock sync.Mutex
+	intakeLock sync.Mutex
+	filter     filter.Filter
+	count      int
+	// Set once the filter was restored, so the root domain names in it are accepted again by the run
+	restored    bool
+	rootsSeen   *stringset.Set
+	stats       intakeStats
 	sweepFilter *stringset.Set
-	subre       *regexp.Regexp
-	done        chan struct{}
-	tokens      chan struct{}
-	doneOnce    sync.Once
-	maxSlots    int
+	rdnsSweeps  queue.Queue
+	rdnsFilter  *stringset.Set
+	rdnsActive  int32
+	// The names resolved again, and the queries reserved for them, when ResolveRepeat is set
+	repeats       queue.Queue
+	repeatFilter  *stringset.Set
+	repeatActive  int32
+	repeatLock    sync.Mutex
+	repeatQueries int
+	repeatCapped  bool
+	// Consulted before a name is resolved when the enumerations share a datastore
+	sharedLock sync.Mutex
+	shared     sharedFilter
+	subre      *regexp.Regexp
+	done       chan struct{}
+	drain      chan struct{}
+	drainOnce  sync.Once
+	tokens     chan struct{}
+	// The tokens of the data discovered and generated by the enumeration, which share the tokens
+	// of the data sources unless separate concurrency limits have been configured
+	activeTokens chan struct{}
+	doneOnce     sync.Once
+	maxSlots     int
+	waitFor      time.Duration
+	pauseLock    sync.Mutex
+	resume       chan struct{}
+	heldLock     sync.Mutex
+	held         map[string]*requests.DNSRequest
+	dataLock     sync.Mutex
+	released     int
+	zones        *stringset.Set
+	// Protects the completed field
+	checkpointLock sync.Mutex
+	completed      bool
 }
 
 // newEnumSource returns an initialized input source for the enumeration pipeline.
 func newEnumSource(e *Enumeration) *enumSource {
 	r := &enumSource{
-		enum:        e,
-		queue:       queue.NewQueue(),
-		dups:        queue.NewQueue(),
-		sweeps:      queue.NewQueue(),
-		filter:      stringset.New(),
-		sweepFilter: stringset.New(),
-		subre:       dns.AnySubdomainRegex(),
-		done:        make(chan struct{}),
-		tokens:      make(chan struct{}, numDataItemsInput),
-		maxSlots:    e.Config.MaxDNSQueries,
+		enum:         e,
+		queue:        newOrderedQueue(e.Config),
+		untrusted:    newOrderedQueue(e.Config),
+		dups:         queue.NewQueue(),
+		sweeps:       queue.NewQueue(),
+		filter:       newNameFilter(e.Config),
+		sweepFilter:  stringset.New(),
+		rdnsSweeps:   queue.NewQueue(),
+		rdnsFilter:   stringset.New(),
+		repeats:      queue.NewQueue(),
+		repeatFilter: stringset.New(),
+		subre:        dns.AnySubdomainRegex(),
+		done:         make(chan struct{}),
+		drain:        make(chan struct{}),
+		tokens:       newIntakeTokens(e.Config.PassiveConcurrency),
+		maxSlots:     e.Config.MaxDNSQueries,
+		waitFor:      waitForData(e.Config),
+		held:         make(map[string]*requests.DNSRequest),
+		zones:        stringset.New(),
+		rootsSeen:    stringset.New(),
 	}
 
-	for i := 0; i < numDataItemsInput; i++ {
-		r.tokens <- struct{}{}
+	r.activeTokens = r.tokens
+	if e.Config.PassiveConcurrency > 0 || e.Config.ActiveConcurrency > 0 {
+		r.activeTokens = newIntakeTokens(e.Config.ActiveConcurrency)
+	}
+	if !e.Config.Passive {
+		r.shared = newSharedFilter(e)
+	}
+	// The checkpoint takes precedence over the filter state, since it includes the filter
+	if !r.loadCheckpoint() {
+		r.loadFilterState()
+	}
+	if e.Config.CheckpointPath != "" {
+		go r.manageCheckpoints()
 	}
 
 	// Monitor the enumeration for completion or termination
@@ -74,17 +144,185 @@ func newEnumSource(e *Enumeration) *enumSource {
 	if !e.Config.Passive {
 		go r.checkForData()
 	}
+	// The prefixes brought into scope by their ASN are swept even when the reverse DNS sweeps are disabled
+	if (e.Config.ReverseDNS || e.Config.ExpandByASN) && !e.Config.Passive {
+		go r.processReverseSweeps()
+	}
+	if e.Config.ResolveRepeat > 0 && !e.Config.Passive {
+		go r.processRepeats()
+	}
 	go r.processDupNames()
 	return r
 }
 
+// newIntakeTokens returns the channel holding the tokens for the data evaluated at once.
+func newIntakeTokens(num int) chan struct{} {
+	if num <= 0 {
+		num = numDataItemsInput
+	}
+
+	tokens := make(chan struct{}, num)
+	for i := 0; i < num; i++ {
+		tokens <- struct{}{}
+	}
+	return tokens
+}
+
+// intakeTokens returns the tokens for the data, which are the active tokens for the data provided by
+// the pipeline stages and the names generated by the enumeration, and the passive tokens otherwise.
+func (r *enumSource) intakeTokens(data pipeline.Data, tp pipeline.TaskParams) chan struct{} {
+	if r.activeTokens == nil {
+		return r.tokens
+	}
+	if tp != nil {
+		return r.activeTokens
+	}
+	if req, ok := data.(*requests.DNSRequest); ok &&
+		(req.Tag == requests.BRUTE || req.Tag == requests.ALT || req.Tag == requests.GUESS) {
+		return r.activeTokens
+	}
+	return r.tokens
+}
+
+// newNameFilter returns the filter type selected in the configuration.
+func newNameFilter(cfg *config.Config) filter.Filter {
+	switch strings.ToLower(cfg.FilterType) {
+	case "bloom":
+		return filter.NewBloomFilter(uint(filterSize(cfg)), filterFPRate(cfg))
+	case "cuckoo":
+		return filter.NewCuckooFilter(uint(filterSize(cfg)), filterFPRate(cfg), cfg.FilterTTL)
+	}
+	return filter.NewStringFilter()
+}
+
+// filterSize returns the number of names held by the bloom and cuckoo filters.
+func filterSize(cfg *config.Config) int {
+	if cfg.FilterSize > 0 {
+		return cfg.FilterSize
+	}
+	return config.DefaultFilterSize
+}
+
+// filterFPRate returns the false positive rate of the bloom and cuckoo filters.
+func filterFPRate(cfg *config.Config) float64 {
+	if cfg.FilterFPRate > 0 {
+		return cfg.FilterFPRate
+	}
+	return config.DefaultFilterFPRate
+}
+
+// waitForData returns the idle duration that Next waits for new data to arrive.
+func waitForData(cfg *config.Config) time.Duration {
+	if cfg.Passive && cfg.MinWaitForData > 0 {
+		return cfg.MinWaitForData
+	}
+	if !cfg.Passive && cfg.MaxWaitForData > 0 {
+		return cfg.MaxWaitForData
+	}
+	return waitForDuration
+}
+
 func (r *enumSource) Stop() {
 	r.markDone()
+	// The checkpoint must capture the queues before they are emptied
+	r.finishCheckpoint()
 	r.queue.Process(func(e interface{}) {})
+	r.untrusted.Process(func(e interface{}) {})
 	r.dups.Process(func(e interface{}) {})
 	r.sweeps.Process(func(e interface{}) {})
+	r.rdnsSweeps.Process(func(e interface{}) {})
+	r.repeats.Process(func(e interface{}) {})
+	r.saveFilterState()
 	r.filter.Close()
 	r.sweepFilter.Close()
+	r.rdnsFilter.Close()
+	r.repeatFilter.Close()
+	r.zones.Close()
+
+	r.sharedLock.Lock()
+	if r.shared != nil {
+		r.shared.Close()
+		r.shared = nil
+	}
+	r.sharedLock.Unlock()
+}
+
+// filterState is the filter persisted to Config.FilterStatePath, along with the number of names
+// inserted into it, so the bloom filter is still reset once it holds the maximum number of names.
+type filterState struct {
+	Filter []byte
+	Count  int
+}
+
+func (r *enumSource) loadFilterState() {
+	path := r.enum.Config.FilterStatePath
+	if path == "" {
+		return
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.enum.queueLog(fmt.Sprintf("Failed to read the filter state from %s: %v", path, err))
+		}
+		return
+	}
+
+	var state filterState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to decode the filter state from %s: %v", path, err))
+		return
+	}
+	if err := r.filter.UnmarshalBinary(state.Filter); err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to restore the filter state from %s: %v", path, err))
+		// Start with a fresh filter instead of a partially restored one
+		r.filter.Close()
+		r.filter = newNameFilter(r.enum.Config)
+		return
+	}
+
+	r.count = state.Count
+	r.restored = true
+}
+
+func (r *enumSource) saveFilterState() {
+	path := r.enum.Config.FilterStatePath
+	if path == "" {
+		return
+	}
+
+	r.filterLock.Lock()
+	fdata, err := r.filter.MarshalBinary()
+	state := &filterState{Filter: fdata, Count: r.count}
+	r.filterLock.Unlock()
+
+	var buf bytes.Buffer
+	if err == nil {
+		err = gob.NewEncoder(&buf).Encode(state)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		r.enum.queueLog(fmt.Sprintf("Failed to save the filter state to %s: %v", path, err))
+	}
+}
+
+// markDraining causes the input source to stop accepting data from the data
+// sources, and to complete once the queued data has been released.
+func (r *enumSource) markDraining() {
+	r.drainOnce.Do(func() {
+		close(r.drain)
+	})
+}
+
+func (r *enumSource) draining() bool {
+	select {
+	case <-r.drain:
+		return true
+	default:
+	}
+	return false
 }
 
 func (r *enumSource) markDone() {
@@ -94,18 +332,34 @@ func (r *enumSource) markDone() {
 }
 
 func (r *enumSource) dataSourceName(req *requests.DNSRequest) {
-	if req == nil || req.Name == "" {
+	if req == nil || req.Name == "" || r.draining() {
 		return
 	}
-	if r.enum.Config.IsDomainInScope(req.Name) {
+
+	inscope := r.enum.Config.IsDomainInScope(req.Name)
+	r.stats.update(func(st *Stats) {
+		st.Submitted++
+		if !inscope {
+			st.OutOfScope++
+		}
+	})
+	if inscope {
 		r.pipelineData(r.enum.ctx, req, nil)
 	}
 }
 
 func (r *enumSource) dataSourceAddr(req *requests.AddrRequest) {
-	if req != nil && req.Address != "" {
-		r.pipelineData(r.enum.ctx, req, nil)
+	if req == nil || req.Address == "" || r.draining() {
+		return
 	}
+
+	r.stats.update(func(st *Stats) {
+		st.Submitted++
+		if !req.InScope || !r.enum.Config.IsAddressFamilyAllowed(req.Address) {
+			st.OutOfScope++
+		}
+	})
+	r.pipelineData(r.enum.ctx, req, nil)
 }
 
 func (r *enumSource) pipelineData(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) {
@@ -117,22 +371,29 @@ func (r *enumSource) pipelineData(ctx context.Context, data pipeline.Data, tp pi
 	default:
 	}
 
+	tokens := r.intakeTokens(data, tp)
 	switch v := data.(type) {
 	case *requests.DNSRequest:
 		if v != nil && v.Valid() {
-			<-r.tokens
-			go r.newName(ctx, v, tp)
+			<-tokens
+			r.intake(func() {
+				defer func() { tokens <- struct{}{} }()
+				r.newName(ctx, v, tp)
+			})
 		}
 	case *requests.AddrRequest:
-		if v != nil && v.Valid() {
-			<-r.tokens
-			go r.newAddr(ctx, v, tp)
+		// Drop addresses from the address family excluded by the configuration
+		if v != nil && v.Valid() && r.enum.Config.IsAddressFamilyAllowed(v.Address) {
+			<-tokens
+			r.intake(func() {
+				defer func() { tokens <- struct{}{} }()
+				r.newAddr(ctx, v, tp)
+			})
 		}
 	}
 }
 
 func (r *enumSource) newName(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) {
-	defer func() { r.tokens <- struct{}{} }()
 	// Clean up the newly discovered name and domain
 	requests.SanitizeDNSRequest(req)
 	// Check that the name is valid
@@ -148,23 +409,64 @@ func (r *enumSource) newName(ctx context.Context, req *requests.DNSRequest, tp p
 		}
 	}
 
+	// Drop names matching the exclude patterns before they enter the pipeline
+	if r.enum.Config.IsExcluded(req.Name) {
+		r.stats.update(func(st *Stats) { st.Excluded++ })
+		return
+	}
+	// Names within the root domains are also required to match one of the scope expressions
+	if !r.enum.Config.MatchesScopeRegex(req.Name) {
+		r.stats.update(func(st *Stats) { st.OutOfScope++ })
+		return
+	}
+
+	// The names descending from untrusted sources are only derived to the configured depth
+	if r.beyondUntrustedDepth(req) {
+		r.stats.update(func(st *Stats) { st.RecursionLimited++ })
+		return
+	}
+
+	// Brute forcing stops once the candidate budget has been spent
+	brute := req.Tag == requests.BRUTE
+	if brute && !r.enum.reserveBruteCandidate() {
+		return
+	}
+
 	if r.accept(req.Name, req.Tag, req.Source, true) {
-		r.queue.Append(req)
+		// Names handled recently by another enumeration sharing the datastore are not resolved again
+		if !r.sharedClaim(req) {
+			if brute {
+				r.enum.releaseBruteCandidate()
+			}
+			return
+		}
+
+		r.enum.recordProvenance(req.Name, req.Provenance)
+		r.appendData(req, requests.TrustedSource(r.enum.Config, req.Tag, req.Source))
+	} else if brute {
+		// Duplicate names do not count against the budget
+		r.enum.releaseBruteCandidate()
 	}
 }
 
 func (r *enumSource) newAddr(ctx context.Context, req *requests.AddrRequest, tp pipeline.TaskParams) {
-	defer func() { r.tokens <- struct{}{} }()
 
-	if !req.InScope || tp == nil || !r.accept(req.Address, req.Tag, req.Source, false) {
+	if !req.InScope || !r.accept(req.Address, req.Tag, req.Source, false) {
 		return
 	}
-
-	r.sendAddr(ctx, req, tp)
+	// The addresses submitted from outside the pipeline are released by the input source
+	if tp == nil {
+		r.appendData(req, requests.TrustedSource(r.enum.Config, req.Tag, req.Source))
+	} else {
+		r.sendAddr(ctx, req, tp)
+	}
 	// Does the address fall into a reserved address range?
 	if yes, _ := amassnet.IsReservedAddress(req.Address); !yes {
 		// Queue the request for later use in reverse DNS sweeps
 		r.sweeps.Append(req)
+		if r.enum.Config.ReverseDNS {
+			r.queueReverseSweep(req)
+		}
 	}
 }
 
@@ -178,11 +480,56 @@ func (r *enumSource) sendAddr(ctx context.Context, req *requests.AddrRequest, tp
 	}, tp)
 }
 
+// verifiedTag returns true for the untrusted tags of the names that are not reported by the data
+// sources, since they were provided by the user or generated by the enumeration and are only
+// discovered when resolved.
+func verifiedTag(tag string) bool {
+	return tag == requests.EXTERNAL || tag == requests.BRUTE || tag == requests.ALT || tag == requests.GUESS
+}
+
+// beyondUntrustedDepth returns true when the name was derived more generations than allowed by
+// MaxRecursionDepthUntrusted from a name provided by an untrusted data source. The chain of a
+// name provided by the user or generated without a parent name is not limited.
+func (r *enumSource) beyondUntrustedDepth(req *requests.DNSRequest) bool {
+	max := r.enum.Config.MaxRecursionDepthUntrusted
+	if max <= 0 || req.Depth <= max {
+		return false
+	}
+
+	origin := requests.OriginStep(req.Tag, req.Source, req.Provenance)
+	return !verifiedTag(origin.Tag) && !requests.TrustedSource(r.enum.Config, origin.Tag, origin.Source)
+}
+
+// rootDomain returns true when the name is one of the root domain names of the enumeration.
+func (r *enumSource) rootDomain(name string) bool {
+	for _, domain := range r.enum.Config.Domains() {
+		if strings.EqualFold(name, domain) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *enumSource) accept(s, tag, source string, name bool) bool {
-	trusted := requests.TrustedTag(tag)
+	r.filterLock.Lock()
+	defer r.filterLock.Unlock()
+
+	trusted := requests.TrustedSource(r.enum.Config, tag, source)
+	// The root domain names found in a restored filter are enumerated once more by the run
+	if name && r.restored && r.rootDomain(s) && !r.rootsSeen.Has(s) {
+		r.rootsSeen.Insert(s)
+		r.insert(s + strconv.FormatBool(trusted))
+		return true
+	}
+	// The trusted-only mode rejects untrusted names outright, instead of reconsidering them later
+	if !trusted && r.enum.Config.TrustedOnly && !verifiedTag(tag) {
+		r.stats.update(func(st *Stats) { st.Untrusted++ })
+		return false
+	}
 	// Do not submit names from untrusted sources, after already receiving the name
 	// from a trusted source
 	if !trusted && r.filter.Has(s+strconv.FormatBool(true)) {
+		r.stats.update(func(st *Stats) { st.Duplicates++ })
 		if name {
 			r.dups.Append(&requests.DNSRequest{
 				Name:   s,
@@ -195,6 +542,7 @@ func (r *enumSource) accept(s, tag, source string, name bool) bool {
 	// At most, a FQDN will be accepted from an untrusted source first, and then
 	// reconsidered from a trusted data source
 	if r.filter.Has(s + strconv.FormatBool(trusted)) {
+		r.stats.update(func(st *Stats) { st.Duplicates++ })
 		if name {
 			r.dups.Append(&requests.DNSRequest{
 				Name:   s,
@@ -205,53 +553,220 @@ func (r *enumSource) accept(s, tag, source string, name bool) bool {
 		return false
 	}
 
-	r.filter.Insert(s + strconv.FormatBool(trusted))
+	r.insert(s + strconv.FormatBool(trusted))
 	return true
 }
 
+// insert adds the element to the filter of accepted names and addresses. The lock must be held by the caller.
+func (r *enumSource) insert(element string) {
+	// The bloom filter cannot release individual elements, so it is
+	// reset once the maximum number of elements has been inserted
+	if r.count >= filterSize(r.enum.Config) && strings.EqualFold(r.enum.Config.FilterType, "bloom") {
+		r.filter.Close()
+		r.filter = newNameFilter(r.enum.Config)
+		r.count = 0
+	}
+
+	r.filter.Insert(element)
+	r.count++
+	r.stats.update(func(st *Stats) { st.Accepted++ })
+}
+
 // Next implements the pipeline InputSource interface.
 func (r *enumSource) Next(ctx context.Context) bool {
 	select {
+	case <-ctx.Done():
+		r.markDone()
+		return false
 	case <-r.done:
 		return false
 	default:
 	}
 
-	if !r.queue.Empty() {
+	r.waitWhilePaused()
+	if r.queueLen() > 0 {
 		return true
 	}
+	if r.draining() {
+		r.markDone()
+		return false
+	}
 
-	t := time.NewTimer(waitForDuration)
+	t := time.NewTimer(r.waitFor)
 	defer t.Stop()
 
 	for {
 		select {
+		case <-ctx.Done():
+			r.markDone()
+			return false
 		case <-r.done:
 			return false
 		case <-t.C:
+			// The idle timer restarts once the enumeration has been resumed, and
+			// tailing the certificate transparency logs continues until stopped
+			if r.waitWhilePaused() || r.enum.Config.CTTail || r.sweepingReverseDNS() || r.resolvingRepeats() {
+				t.Reset(r.waitFor)
+				continue
+			}
+			// The data sources in the next tier are given the chance to provide more names
+			if r.enum.activateNextTier() {
+				t.Reset(r.waitFor)
+				continue
+			}
+			// The next phase starts once the names found by the prior phase have reached the data sources
+			if r.enum.phases != nil && (r.enum.subTask.queue.Len() > 0 || r.enum.startNextPhase()) {
+				t.Reset(r.waitFor)
+				continue
+			}
 			r.markDone()
 			return false
+		case <-r.drain:
+			if r.queueLen() == 0 {
+				r.markDone()
+				return false
+			}
 		case <-r.queue.Signal():
-			if !r.queue.Empty() {
-				return true
+		case <-r.untrusted.Signal():
+		}
+
+		if r.waitWhilePaused() {
+			if !t.Stop() {
+				<-t.C
 			}
+			t.Reset(r.waitFor)
+		}
+		if r.queueLen() > 0 {
+			return true
 		}
 	}
 }
 
+func (r *enumSource) pause() {
+	r.pauseLock.Lock()
+	defer r.pauseLock.Unlock()
+
+	if r.resume == nil {
+		r.resume = make(chan struct{})
+	}
+}
+
+func (r *enumSource) unpause() {
+	r.pauseLock.Lock()
+	defer r.pauseLock.Unlock()
+
+	if r.resume != nil {
+		close(r.resume)
+		r.resume = nil
+	}
+}
+
+func (r *enumSource) paused() bool {
+	r.pauseLock.Lock()
+	defer r.pauseLock.Unlock()
+
+	return r.resume != nil
+}
+
+// waitWhilePaused blocks until the input source is resumed or done,
+// and returns true if the input source had been paused.
+func (r *enumSource) waitWhilePaused() bool {
+	r.pauseLock.Lock()
+	resume := r.resume
+	r.pauseLock.Unlock()
+
+	if resume == nil {
+		return false
+	}
+
+	select {
+	case <-r.done:
+	case <-resume:
+	}
+	return true
+}
+
 // Data implements the pipeline InputSource interface.
 func (r *enumSource) Data() pipeline.Data {
+	r.dataLock.Lock()
+	defer r.dataLock.Unlock()
+
 	var data pipeline.Data
 
-	if element, ok := r.queue.Next(); ok {
-		if d, good := element.(pipeline.Data); good {
-			data = d
-		}
+	// Trusted elements are released first, while periodically allowing
+	// an untrusted element through so the untrusted queue is not starved
+	first, second := r.queue, r.untrusted
+	if r.trustedRun >= maxTrustedRun {
+		first, second = r.untrusted, r.queue
+	}
+
+	element, ok := first.Next()
+	if !ok {
+		first = second
+		element, ok = second.Next()
+	}
+
+	if !ok {
+		return data
+	}
+
+	if first == r.queue {
+		r.trustedRun++
+	} else {
+		r.trustedRun = 0
+	}
+	if d, good := element.(pipeline.Data); good {
+		data = d
+		r.released++
 	}
 
 	return data
 }
 
+// intake evaluates the new data in its own goroutine, or one element at a time,
+// in the order received, when the enumeration is deterministic.
+func (r *enumSource) intake(fn func()) {
+	if !r.enum.Config.Deterministic {
+		go fn()
+		return
+	}
+
+	r.intakeLock.Lock()
+	defer r.intakeLock.Unlock()
+
+	fn()
+}
+
+// appendData places the element on the queue selected by the trust of the data source.
+func (r *enumSource) appendData(data pipeline.Data, trusted bool) {
+	if trusted {
+		r.queue.Append(data)
+		return
+	}
+
+	// Keep the untrusted queue bounded by holding intake until it drains
+	for r.untrusted.Len() >= maxUntrustedQueued {
+		select {
+		case <-r.done:
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	r.untrusted.Append(data)
+}
+
+// numReleased returns the number of elements released into the pipeline.
+func (r *enumSource) numReleased() int {
+	r.dataLock.Lock()
+	defer r.dataLock.Unlock()
+
+	return r.released
+}
+
+func (r *enumSource) queueLen() int {
+	return r.queue.Len() + r.untrusted.Len()
+}
+
 // Error implements the pipeline InputSource interface.
 func (r *enumSource) Error() error {
 	return nil
@@ -265,8 +780,12 @@ func (r *enumSource) checkForData() {
 		default:
 		}
 
-		needed := r.maxSlots - r.queue.Len()
-		if needed <= 0 {
+		if r.draining() {
+			return
+		}
+
+		needed := r.slots() - r.queueLen()
+		if needed <= 0 || r.paused() {
 			time.Sleep(250 * time.Millisecond)
 			continue
 		}
@@ -281,6 +800,15 @@ func (r *enumSource) checkForData() {
 	}
 }
 
+// slots returns the number of queued elements that checkForData keeps available in the enumeration,
+// which is divided among the enumerations running concurrently under an Orchestrator.
+func (r *enumSource) slots() int {
+	if o := r.enum.orchestrator; o != nil {
+		return o.share(r.maxSlots)
+	}
+	return r.maxSlots
+}
+
 // This goroutine ensures that duplicate names from other sources are shown in the Graph.
 func (r *enumSource) processDupNames() {
 	uuid := r.enum.Config.UUID.String()
@@ -374,12 +902,12 @@ func (r *enumSource) sweepAddrs(ctx context.Context, req *requests.AddrRequest)
 		if a := ip.String(); !r.sweepFilter.Has(a) {
 			count++
 			r.sweepFilter.Insert(a)
-			r.queue.Append(&requests.AddrRequest{
+			r.appendData(&requests.AddrRequest{
 				Address: a,
 				Domain:  req.Domain,
 				Tag:     req.Tag,
 				Source:  req.Source,
-			})
+			}, requests.TrustedSource(r.enum.Config, req.Tag, req.Source))
 		}
 	}
 	return count
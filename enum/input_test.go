@@ -0,0 +1,564 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/filter"
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/netmap"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/queue"
+	"github.com/caffix/stringset"
+)
+
+func newTestEnumSource(cfg *config.Config) *enumSource {
+	return &enumSource{
+		enum: &Enumeration{
+			Config:   cfg,
+			logQueue: queue.NewQueue(),
+		},
+		queue:     newOrderedQueue(cfg),
+		untrusted: newOrderedQueue(cfg),
+		filter:    filter.NewStringFilter(),
+		dups:      queue.NewQueue(),
+		zones:     stringset.New(),
+		rootsSeen: stringset.New(),
+		done:      make(chan struct{}),
+		drain:     make(chan struct{}),
+	}
+}
+
+func TestAcceptUntrustedSourceDemoted(t *testing.T) {
+	name := "www.owasp.org"
+
+	// By default, the certificate source reconsiders the name after the scraper
+	r := newTestEnumSource(config.NewConfig())
+	if !r.accept(name, requests.SCRAPE, "Bing", true) {
+		t.Fatal("The name was not accepted from the first source")
+	}
+	if !r.accept(name, requests.CERT, "Crtsh", true) {
+		t.Error("The name was not reconsidered from the trusted source")
+	}
+
+	cfg := config.NewConfig()
+	cfg.UntrustedSources = []string{"Crtsh"}
+	r = newTestEnumSource(cfg)
+	if !r.accept(name, requests.SCRAPE, "Bing", true) {
+		t.Fatal("The name was not accepted from the first source")
+	}
+	if r.accept(name, requests.CERT, "Crtsh", true) {
+		t.Error("The demoted source bypassed the filtering of untrusted sources")
+	}
+	if st := r.stats.snapshot(); st.Duplicates != 1 {
+		t.Errorf("The duplicate name was counted %d times", st.Duplicates)
+	}
+}
+
+func TestNewNameInternationalizedForms(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("bücher.de")
+
+	r := newTestEnumSource(cfg)
+	r.subre = dns.AnySubdomainRegex()
+	r.tokens = make(chan struct{}, 3)
+
+	for _, name := range []string{"www.bücher.de", "WWW.XN--BCHER-KVA.DE", "www.xn--bcher-kva.de."} {
+		r.newName(context.Background(), &requests.DNSRequest{
+			Name:   name,
+			Domain: "bücher.de",
+			Tag:    requests.DNS,
+			Source: "DNS",
+		}, nil)
+	}
+
+	if r.queueLen() != 1 {
+		t.Fatalf("Expected the equivalent forms to be accepted once, but %d names were queued", r.queueLen())
+	}
+	if st := r.stats.snapshot(); st.Duplicates != 2 {
+		t.Errorf("Expected two of the equivalent forms to be duplicates, but %d were counted", st.Duplicates)
+	}
+
+	e, _ := r.queue.Next()
+	if req := e.(*requests.DNSRequest); req.Name != "www.xn--bcher-kva.de" || req.Domain != "xn--bcher-kva.de" {
+		t.Errorf("The name was not released in the punycode form: %s (%s)", req.Name, req.Domain)
+	}
+}
+
+func TestNewNameRecordsProvenance(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	r := newTestEnumSource(cfg)
+	r.subre = dns.AnySubdomainRegex()
+	r.tokens = make(chan struct{}, 2)
+
+	parent := &requests.DNSRequest{
+		Name:   "dev.owasp.org",
+		Domain: "owasp.org",
+		Tag:    requests.CERT,
+		Source: "Crtsh",
+	}
+	r.newName(context.Background(), parent, nil)
+	r.newName(context.Background(), &requests.DNSRequest{
+		Name:       "mail.dev.owasp.org",
+		Domain:     "owasp.org",
+		Tag:        requests.DNS,
+		Source:     "DNS",
+		Provenance: derivedProvenance(parent, requests.DNS, "DNS"),
+	}, nil)
+
+	if p := r.enum.Provenance("dev.owasp.org"); p != nil {
+		t.Errorf("Provenance was recorded for the name provided by the data source: %v", p)
+	}
+	p := r.enum.Provenance("mail.dev.owasp.org")
+	if len(p) != 2 || p[0].Source != "Crtsh" || p[1].From != "dev.owasp.org" || p[1].Tag != requests.DNS {
+		t.Errorf("The provenance of the derived name was not recorded: %v", p)
+	}
+}
+
+func TestNewNameUntrustedRecursionDepth(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.MaxRecursionDepthUntrusted = 1
+
+	r := newTestEnumSource(cfg)
+	r.subre = dns.AnySubdomainRegex()
+	r.tokens = make(chan struct{}, 10)
+
+	scraped := &requests.DNSRequest{Name: "dev.owasp.org", Domain: "owasp.org", Tag: requests.SCRAPE, Source: "Bing"}
+	cert := &requests.DNSRequest{Name: "vpn.owasp.org", Domain: "owasp.org", Tag: requests.CERT, Source: "Crtsh"}
+	derive := func(parent *requests.DNSRequest, name string) *requests.DNSRequest {
+		return &requests.DNSRequest{
+			Name:       name,
+			Domain:     "owasp.org",
+			Tag:        requests.BRUTE,
+			Source:     "Brute Forcing",
+			Provenance: derivedProvenance(parent, requests.BRUTE, "Brute Forcing"),
+			Depth:      parent.Depth + 1,
+		}
+	}
+
+	first := derive(scraped, "api.dev.owasp.org")
+	second := derive(first, "www.api.dev.owasp.org")
+	trusted := derive(derive(cert, "api.vpn.owasp.org"), "www.api.vpn.owasp.org")
+	for _, req := range []*requests.DNSRequest{scraped, first, second, trusted} {
+		r.newName(context.Background(), req, nil)
+	}
+
+	names := stringset.New()
+	defer names.Close()
+	for _, q := range []queue.Queue{r.queue, r.untrusted} {
+		for {
+			e, ok := q.Next()
+			if !ok {
+				break
+			}
+			names.Insert(e.(*requests.DNSRequest).Name)
+		}
+	}
+	if names.Len() != 3 || !names.Has("api.dev.owasp.org") || names.Has("www.api.dev.owasp.org") || !names.Has("www.api.vpn.owasp.org") {
+		t.Errorf("The names derived from the untrusted source were not limited by the depth: %v", names.Slice())
+	}
+	if st := r.stats.snapshot(); st.RecursionLimited != 1 {
+		t.Errorf("The names beyond the depth were not counted: %d", st.RecursionLimited)
+	}
+}
+
+func TestAcceptTrustedSourcePromoted(t *testing.T) {
+	name := "www.owasp.org"
+
+	cfg := config.NewConfig()
+	cfg.TrustedSources = []string{"PrivateDNS"}
+	r := newTestEnumSource(cfg)
+	if !r.accept(name, requests.API, "PrivateDNS", true) {
+		t.Fatal("The name was not accepted from the promoted source")
+	}
+	if r.accept(name, requests.SCRAPE, "Bing", true) {
+		t.Error("The untrusted source was accepted after the promoted source")
+	}
+}
+
+func TestAcceptTrustedOnly(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TrustedOnly = true
+	cfg.TrustedSources = []string{"PrivateDNS"}
+	r := newTestEnumSource(cfg)
+
+	if r.accept("www.owasp.org", requests.SCRAPE, "Bing", true) {
+		t.Error("The name from the untrusted source was accepted")
+	}
+	if !r.accept("www.owasp.org", requests.CERT, "Crtsh", true) {
+		t.Error("The name from the trusted source was rejected")
+	}
+	if !r.accept("vpn.owasp.org", requests.API, "PrivateDNS", true) {
+		t.Error("The name from the promoted source was rejected")
+	}
+	if !r.accept("dev.owasp.org", requests.BRUTE, "Brute Forcing", true) {
+		t.Error("The name generated by brute forcing was rejected")
+	}
+	if !r.accept("ftp.owasp.org", requests.EXTERNAL, "User Input", true) {
+		t.Error("The name provided by the user was rejected")
+	}
+	if st := r.stats.snapshot(); st.Untrusted != 1 || st.Accepted != 4 {
+		t.Errorf("The rejected names were counted %d times, and the accepted names %d times", st.Untrusted, st.Accepted)
+	}
+}
+
+func TestNextExitsWhenCanceled(t *testing.T) {
+	r := newTestEnumSource(config.NewConfig())
+	r.waitFor = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	result := make(chan bool, 1)
+	go func() { result <- r.Next(ctx) }()
+
+	select {
+	case more := <-result:
+		if more {
+			t.Error("Next reported more data after the context was canceled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next did not return after the context was canceled")
+	}
+
+	select {
+	case <-r.done:
+	default:
+		t.Error("The input source was not marked done after the context was canceled")
+	}
+}
+
+func TestDataDeterministicOrder(t *testing.T) {
+	names := []string{"www.owasp.org", "api.owasp.org", "mail.owasp.org"}
+
+	for _, deterministic := range []bool{false, true} {
+		cfg := config.NewConfig()
+		cfg.Deterministic = deterministic
+		r := newTestEnumSource(cfg)
+
+		for _, name := range names {
+			r.appendData(&requests.DNSRequest{Name: name, Domain: "owasp.org"}, true)
+		}
+
+		var got []string
+		for i := 0; i < len(names); i++ {
+			if req, ok := r.Data().(*requests.DNSRequest); ok {
+				got = append(got, req.Name)
+			}
+		}
+
+		expected := names
+		if deterministic {
+			expected = []string{"api.owasp.org", "mail.owasp.org", "www.owasp.org"}
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("%d elements were released instead of %d", len(got), len(expected))
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("Deterministic %t: the elements were released in the order %v", deterministic, got)
+				break
+			}
+		}
+	}
+}
+
+type stageParams struct{}
+
+func (stageParams) Registry() pipeline.StageRegistry { return nil }
+
+func TestIntakeTokens(t *testing.T) {
+	r := newTestEnumSource(config.NewConfig())
+	r.tokens = newIntakeTokens(2)
+	r.activeTokens = newIntakeTokens(3)
+
+	if len(r.tokens) != 2 || len(r.activeTokens) != 3 {
+		t.Fatalf("The tokens were not filled to the concurrency limits: %d, %d", len(r.tokens), len(r.activeTokens))
+	}
+	if len(newIntakeTokens(0)) != numDataItemsInput {
+		t.Errorf("The default number of tokens was not provided without a concurrency limit")
+	}
+
+	for _, test := range []struct {
+		data   pipeline.Data
+		tp     pipeline.TaskParams
+		active bool
+	}{
+		{&requests.DNSRequest{Name: "www.owasp.org", Tag: requests.CERT, Source: "Crtsh"}, nil, false},
+		{&requests.AddrRequest{Address: "192.0.2.1", Tag: requests.API, Source: "Shodan"}, nil, false},
+		{&requests.DNSRequest{Name: "dev.owasp.org", Tag: requests.BRUTE, Source: "Brute Forcing"}, nil, true},
+		{&requests.DNSRequest{Name: "dev1.owasp.org", Tag: requests.ALT, Source: "Alterations"}, nil, true},
+		// The names found in the DNS responses are provided by the pipeline stages
+		{&requests.DNSRequest{Name: "mail.owasp.org", Tag: requests.DNS, Source: "DNS"}, stageParams{}, true},
+	} {
+		if got := r.intakeTokens(test.data, test.tp) == r.activeTokens; got != test.active {
+			t.Errorf("The data %v was given the active tokens: %t", test.data, got)
+		}
+	}
+}
+
+func TestDataReleasesUntrustedAfterTrustedRun(t *testing.T) {
+	r := newTestEnumSource(config.NewConfig())
+
+	for i := 0; i < 2*maxTrustedRun; i++ {
+		r.appendData(&requests.DNSRequest{Name: fmt.Sprintf("www%d.owasp.org", i), Domain: "owasp.org"}, true)
+	}
+	r.appendData(&requests.DNSRequest{Name: "scraped1.owasp.org", Domain: "owasp.org"}, false)
+	r.appendData(&requests.DNSRequest{Name: "scraped2.owasp.org", Domain: "owasp.org"}, false)
+
+	var untrusted []int
+	for i := 0; i < 2*maxTrustedRun+2; i++ {
+		req, ok := r.Data().(*requests.DNSRequest)
+		if !ok {
+			t.Fatalf("Only %d elements were released", i)
+		}
+		if strings.HasPrefix(req.Name, "scraped") {
+			untrusted = append(untrusted, i)
+		}
+	}
+
+	// An untrusted element follows each run of trusted elements
+	if len(untrusted) != 2 || untrusted[0] != maxTrustedRun || untrusted[1] != 2*maxTrustedRun+1 {
+		t.Errorf("The untrusted elements were released at the positions %v", untrusted)
+	}
+	if r.Data() != nil {
+		t.Error("Data was released after the queues were empty")
+	}
+}
+
+func TestAppendDataBlocksAtUntrustedBound(t *testing.T) {
+	r := newTestEnumSource(config.NewConfig())
+
+	for i := 0; i < maxUntrustedQueued; i++ {
+		r.untrusted.Append(&requests.DNSRequest{Name: fmt.Sprintf("www%d.owasp.org", i), Domain: "owasp.org"})
+	}
+
+	appended := make(chan struct{})
+	go func() {
+		r.appendData(&requests.DNSRequest{Name: "scraped.owasp.org", Domain: "owasp.org"}, false)
+		close(appended)
+	}()
+
+	// Trusted elements are not held by the bound
+	r.appendData(&requests.DNSRequest{Name: "vpn.owasp.org", Domain: "owasp.org"}, true)
+	select {
+	case <-appended:
+		t.Fatal("The untrusted element was appended while the queue was at the bound")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if _, ok := r.untrusted.Next(); !ok {
+		t.Fatal("The untrusted queue was empty")
+	}
+	select {
+	case <-appended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The untrusted element was not appended once the queue drained")
+	}
+	if r.untrusted.Len() != maxUntrustedQueued || r.queue.Len() != 1 {
+		t.Errorf("The queues hold %d untrusted and %d trusted elements", r.untrusted.Len(), r.queue.Len())
+	}
+}
+
+func TestNextBlocksWhilePaused(t *testing.T) {
+	r := newTestEnumSource(config.NewConfig())
+	r.waitFor = time.Minute
+	r.enum.nameSrc = r
+
+	r.enum.Pause()
+	r.appendData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org"}, true)
+
+	result := make(chan bool, 1)
+	go func() { result <- r.Next(context.Background()) }()
+
+	select {
+	case <-result:
+		t.Fatal("Next returned while the enumeration was paused")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	r.enum.Resume()
+	select {
+	case more := <-result:
+		if !more {
+			t.Fatal("Next reported no more data after the enumeration was resumed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next did not return after the enumeration was resumed")
+	}
+	if req, ok := r.Data().(*requests.DNSRequest); !ok || req.Name != "www.owasp.org" {
+		t.Errorf("The queued name was not released after the enumeration was resumed")
+	}
+}
+
+func TestStopAndFlushDrainsQueue(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	r := newTestEnumSource(cfg)
+	r.waitFor = time.Minute
+	r.enum.ctx = context.Background()
+	r.enum.done = make(chan struct{})
+	r.enum.nameSrc = r
+
+	names := []string{"www.owasp.org", "mail.owasp.org", "vpn.owasp.org"}
+	for _, name := range names {
+		r.appendData(&requests.DNSRequest{Name: name, Domain: "owasp.org"}, true)
+	}
+	// The paused enumeration is resumed to release the queue
+	r.enum.Pause()
+
+	stopped := make(chan struct{})
+	go func() {
+		r.enum.StopAndFlush(context.Background())
+		close(stopped)
+	}()
+
+	// The pipeline releases the queued names, and the enumeration completes once Next returns false
+	var released []string
+	for r.Next(context.Background()) {
+		if req, ok := r.Data().(*requests.DNSRequest); ok {
+			released = append(released, req.Name)
+		}
+		// The names provided while draining are not accepted
+		r.dataSourceName(&requests.DNSRequest{Name: "late.owasp.org", Domain: "owasp.org", Tag: requests.CERT, Source: "Crtsh"})
+	}
+
+	select {
+	case <-stopped:
+		t.Fatal("StopAndFlush returned before the enumeration completed")
+	default:
+	}
+	close(r.enum.done)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("StopAndFlush did not return after the enumeration completed")
+	}
+
+	if len(released) != len(names) {
+		t.Errorf("The names %v were released instead of %v", released, names)
+	}
+	if st := r.stats.snapshot(); st.Submitted != 0 {
+		t.Errorf("%d names were accepted while draining", st.Submitted)
+	}
+}
+
+func TestFilterStateEnumeratesRootDomainsAgain(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.Deterministic = true
+	cfg.FilterStatePath = filepath.Join(t.TempDir(), "filter.dat")
+
+	run := func() *enumSource {
+		r := newTestEnumSource(cfg)
+		r.subre = dns.AnySubdomainRegex()
+		r.tokens = newIntakeTokens(1)
+		r.enum.ctx = context.Background()
+		r.enum.nameSrc = r
+		return r
+	}
+	released := func(r *enumSource) []string {
+		var names []string
+
+		for r.queue.Len() > 0 {
+			if req, ok := r.Data().(*requests.DNSRequest); ok {
+				names = append(names, req.Name)
+			}
+		}
+		return names
+	}
+
+	first := run()
+	first.enum.submitDomainName("owasp.org")
+	first.dataSourceName(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS, Source: "DNS"})
+	if names := released(first); len(names) != 2 {
+		t.Fatalf("The first run released the names %v", names)
+	}
+	first.saveFilterState()
+
+	second := run()
+	second.loadFilterState()
+	if !second.restored || second.count != first.count {
+		t.Fatalf("The filter state restored a count of %d instead of %d", second.count, first.count)
+	}
+
+	// The root domain is enumerated again, while the names found by the first run are not
+	second.enum.submitDomainName("owasp.org")
+	second.dataSourceName(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS, Source: "DNS"})
+	second.enum.submitDomainName("owasp.org")
+	if names := released(second); len(names) != 1 || names[0] != "owasp.org" {
+		t.Errorf("The second run released the names %v instead of the root domain", names)
+	}
+}
+
+func TestPauseRightAfterStart(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.Passive = true
+
+	sys := &systems.SimpleSystem{
+		Cfg:     cfg,
+		Service: newTierTestService("FreeAPI"),
+		Graph:   netmap.NewGraph(netmap.NewCayleyGraphMemory()),
+	}
+	defer sys.Graph.Close()
+	e := NewEnumeration(cfg, sys)
+	defer e.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	finished := make(chan error, 1)
+	go func() { finished <- e.Start(ctx) }()
+	// The pause is kept until the input source has been created by Start
+	e.Pause()
+
+	for src := e.source(); ; src = e.source() {
+		if src != nil {
+			if !src.paused() {
+				t.Error("The enumeration paused right after Start was not paused")
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = e.Stats()
+
+	e.Resume()
+	e.StopAndFlush(ctx)
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("The enumeration did not complete after StopAndFlush")
+	}
+}
+
+func TestStopAndFlushBeforeStart(t *testing.T) {
+	r := newTestEnumSource(config.NewConfig())
+	r.enum.done = make(chan struct{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	r.enum.Pause()
+	r.enum.StopAndFlush(ctx)
+	// The input source created by Start does not accept the data, and releases the queue
+	r.enum.setSource(r)
+	if !r.draining() || r.paused() {
+		t.Error("The flush requested before Start was not applied to the input source")
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+)
+
+const (
+	mdnsAddr  = "224.0.0.251:5353"
+	llmnrAddr = "224.0.0.252:5355"
+	// The name queried to enumerate the service types advertised over mDNS
+	mdnsServicesName = "_services._dns-sd._udp.local."
+	// The interval between the mDNS service browsing queries
+	mdnsQueryInterval = time.Minute
+)
+
+// localDiscovery listens for mDNS and LLMNR traffic on the local network segment, and browses
+// the services advertised over mDNS, submitting the hostnames learned as in-scope names.
+// The listeners are closed once the context expires or the enumeration is done.
+func (e *Enumeration) localDiscovery(ctx context.Context) {
+	filter := stringset.New()
+	defer filter.Close()
+
+	found := make(chan *localName, 100)
+	mdns, err := listenLocal(ctx, mdnsAddr, "mDNS", found)
+	if err != nil {
+		e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("mDNS: failed to listen: %v", err))
+	}
+	if _, err := listenLocal(ctx, llmnrAddr, "LLMNR", found); err != nil {
+		e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("LLMNR: failed to listen: %v", err))
+	}
+	if mdns != nil {
+		go browseMDNS(ctx, mdns)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.done:
+			return
+		case n := <-found:
+			if filter.Has(n.Name) {
+				continue
+			}
+			filter.Insert(n.Name)
+
+			for _, name := range localCandidates(e.Config, n.Name) {
+				e.InputName(&requests.DNSRequest{
+					Name:   name,
+					Domain: e.Config.WhichDomain(name),
+					Tag:    requests.LOCAL,
+					Source: n.Source,
+				})
+			}
+		}
+	}
+}
+
+type localName struct {
+	Name   string
+	Source string
+}
+
+// listenLocal joins the multicast group and sends the names found in each message received.
+func listenLocal(ctx context.Context, addr, source string, found chan *localName) (*net.UDPConn, error) {
+	gaddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, gaddr)
+	if err != nil {
+		return nil, err
+	}
+	// Unblock the read loop when the enumeration is stopped
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go func() {
+		buf := make([]byte, dns.MaxMsgSize)
+
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+
+			msg := new(dns.Msg)
+			if err := msg.Unpack(buf[:n]); err != nil {
+				continue
+			}
+			if source == "mDNS" {
+				queryServiceInstances(conn, gaddr, msg)
+			}
+
+			for _, name := range localNamesFromMsg(msg) {
+				select {
+				case <-ctx.Done():
+					return
+				case found <- &localName{Name: name, Source: source}:
+				}
+			}
+		}
+	}()
+	return conn, nil
+}
+
+// browseMDNS periodically asks the mDNS responders for the service types they advertise.
+func browseMDNS(ctx context.Context, conn *net.UDPConn) {
+	gaddr, err := net.ResolveUDPAddr("udp4", mdnsAddr)
+	if err != nil {
+		return
+	}
+
+	t := time.NewTicker(mdnsQueryInterval)
+	defer t.Stop()
+
+	for {
+		sendLocalQuery(conn, gaddr, mdnsServicesName, dns.TypePTR)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// queryServiceInstances requests the instances of the service types listed in the mDNS response,
+// since the SRV records of the instances provide the hostnames.
+func queryServiceInstances(conn *net.UDPConn, gaddr *net.UDPAddr, msg *dns.Msg) {
+	for _, rr := range append(msg.Answer, msg.Extra...) {
+		if ptr, ok := rr.(*dns.PTR); ok && strings.EqualFold(ptr.Hdr.Name, mdnsServicesName) {
+			sendLocalQuery(conn, gaddr, ptr.Ptr, dns.TypePTR)
+		}
+	}
+}
+
+func sendLocalQuery(conn *net.UDPConn, gaddr *net.UDPAddr, name string, qtype uint16) {
+	msg := resolve.QueryMsg(name, qtype)
+	// Multicast DNS queries do not request recursion
+	msg.RecursionDesired = false
+	msg.Id = 0
+
+	if data, err := msg.Pack(); err == nil {
+		_, _ = conn.WriteToUDP(data, gaddr)
+	}
+}
+
+// localNamesFromMsg returns the hostnames found in the questions and records of the message.
+func localNamesFromMsg(msg *dns.Msg) []string {
+	names := stringset.New()
+	defer names.Close()
+
+	for _, q := range msg.Question {
+		if msg.Opcode == dns.OpcodeQuery && (q.Qtype == dns.TypeA || q.Qtype == dns.TypeAAAA || q.Qtype == dns.TypeANY) {
+			names.Insert(q.Name)
+		}
+	}
+	for _, rr := range append(append(msg.Answer, msg.Ns...), msg.Extra...) {
+		switch v := rr.(type) {
+		case *dns.A:
+			names.Insert(v.Hdr.Name)
+		case *dns.AAAA:
+			names.Insert(v.Hdr.Name)
+		case *dns.SRV:
+			names.Insert(v.Target)
+		}
+	}
+
+	var hosts []string
+	for _, name := range names.Slice() {
+		name = strings.ToLower(resolve.RemoveLastDot(name))
+		// Service labels do not identify hosts
+		if name != "" && !strings.HasPrefix(name, "_") {
+			hosts = append(hosts, name)
+		}
+	}
+	return hosts
+}
+
+// localCandidates returns the in-scope names for a hostname learned on the local network segment.
+// Names within the .local domain and single label names are tried under each root domain name.
+func localCandidates(cfg *config.Config, name string) []string {
+	if domain := cfg.WhichDomain(name); domain != "" {
+		return []string{name}
+	}
+
+	host := strings.TrimSuffix(name, ".local")
+	if host == "" || strings.Contains(host, ".") {
+		return nil
+	}
+
+	var names []string
+	for _, domain := range cfg.Domains() {
+		names = append(names, host+"."+domain)
+	}
+	return names
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/miekg/dns"
+)
+
+func TestLocalNamesFromMsg(t *testing.T) {
+	msg := new(dns.Msg)
+	msg.Response = true
+	msg.Answer = []dns.RR{
+		&dns.PTR{Hdr: dns.RR_Header{Name: "_http._tcp.local.", Rrtype: dns.TypePTR}, Ptr: "Printer._http._tcp.local."},
+		&dns.SRV{Hdr: dns.RR_Header{Name: "Printer._http._tcp.local.", Rrtype: dns.TypeSRV}, Target: "printer.local."},
+	}
+	msg.Extra = []dns.RR{
+		&dns.A{Hdr: dns.RR_Header{Name: "NAS.local.", Rrtype: dns.TypeA}, A: net.ParseIP("192.168.1.10")},
+	}
+
+	names := localNamesFromMsg(msg)
+	sort.Strings(names)
+	if expected := []string{"nas.local", "printer.local"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected the names %v, but found %v", expected, names)
+	}
+}
+
+func TestLocalCandidates(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomains("owasp.org", "example.com")
+
+	names := localCandidates(cfg, "printer.local")
+	sort.Strings(names)
+	if expected := []string{"printer.example.com", "printer.owasp.org"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected the candidates %v, but found %v", expected, names)
+	}
+	if names := localCandidates(cfg, "www.owasp.org"); len(names) != 1 || names[0] != "www.owasp.org" {
+		t.Errorf("The in-scope name was not provided as the only candidate: %v", names)
+	}
+	if names := localCandidates(cfg, "host.corp.local"); len(names) != 0 {
+		t.Errorf("The multiple label name outside of the scope provided candidates: %v", names)
+	}
+}
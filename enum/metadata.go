@@ -0,0 +1,60 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/caffix/netmap"
+)
+
+// The node property predicates that record the metadata collected for an asset
+const (
+	PortPredicate = "port"
+	TechPredicate = "tech"
+)
+
+// storeMetadata records the open ports of the scanned addresses and the technologies announced by
+// the web servers as properties of the nodes in the graph, so they are available to the visualizations.
+// Only the nodes discovered by the enumeration receive the properties.
+func (e *Enumeration) storeMetadata(ctx context.Context) {
+	e.scanLock.Lock()
+	ports := make(map[string][]int, len(e.scanAddrs))
+	for addr, list := range e.scanAddrs {
+		ports[addr] = append([]int(nil), list...)
+	}
+	e.scanLock.Unlock()
+
+	for addr, list := range ports {
+		if _, err := e.Graph.ReadNode(ctx, addr, netmap.TypeAddr); err != nil {
+			continue
+		}
+
+		for _, port := range list {
+			_ = e.Graph.UpsertProperty(ctx, netmap.Node(addr), PortPredicate, strconv.Itoa(port))
+		}
+	}
+
+	e.webLock.Lock()
+	servers := make(map[string]*http.Fingerprint, len(e.webServers))
+	for name, fp := range e.webServers {
+		servers[name] = fp
+	}
+	e.webLock.Unlock()
+
+	for name, fp := range servers {
+		if len(fp.Technologies) == 0 {
+			continue
+		}
+		if _, err := e.Graph.ReadNode(ctx, name, netmap.TypeFQDN); err != nil {
+			continue
+		}
+
+		for _, tech := range fp.Technologies {
+			_ = e.Graph.UpsertProperty(ctx, netmap.Node(name), TechPredicate, tech)
+		}
+	}
+}
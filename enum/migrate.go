@@ -0,0 +1,97 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"time"
+
+	"github.com/caffix/netmap"
+	"github.com/caffix/stringset"
+)
+
+// Migrate copies the findings of the enumeration into the graph database, which can already hold the
+// findings of previous enumerations. The nodes and edges already stored are not duplicated, and the
+// properties that hold a single value, such as the last seen time of a name and the finish time of
+// the event, are merged, so the findings can be migrated into the same graph database again.
+func (e *Enumeration) Migrate(ctx context.Context, g *netmap.Graph) error {
+	if err := e.Graph.Migrate(ctx, g); err != nil {
+		return err
+	}
+
+	uuid := e.Config.UUID.String()
+	if err := mergeEventTimes(ctx, g, uuid); err != nil {
+		return err
+	}
+
+	names := e.Graph.EventFQDNs(ctx, uuid)
+	nodes := stringset.New(names...)
+	defer nodes.Close()
+
+	if pairs, err := e.Graph.NamesToAddrs(ctx, uuid, names...); err == nil {
+		for _, p := range pairs {
+			nodes.Insert(p.Addr)
+		}
+	}
+	// Each name and address is left with the first seen time preserved from previous
+	// enumerations, and the last seen time of this enumeration
+	for _, node := range nodes.Slice() {
+		if node != "" {
+			_ = MarkSeen(ctx, g, node, time.Time{})
+		}
+	}
+	return nil
+}
+
+// mergeEventTimes leaves the event with the earliest start time and the latest finish time,
+// since each migration of the event adds the times it held when the migration was performed.
+func mergeEventTimes(ctx context.Context, g *netmap.Graph, uuid string) error {
+	event, err := g.ReadNode(ctx, uuid, netmap.TypeEvent)
+	if err != nil {
+		return nil
+	}
+
+	props, err := g.ReadProperties(ctx, event, "start", "finish")
+	if err != nil {
+		return err
+	}
+
+	var start, finish *netmap.Property
+	var extra []*netmap.Property
+	for _, p := range props {
+		t, ok := p.Value.Native().(time.Time)
+		if !ok {
+			continue
+		}
+
+		switch p.Predicate {
+		case "start":
+			if start == nil || t.Before(start.Value.Native().(time.Time)) {
+				if start != nil {
+					extra = append(extra, start)
+				}
+				start = p
+				continue
+			}
+		case "finish":
+			if finish == nil || t.After(finish.Value.Native().(time.Time)) {
+				if finish != nil {
+					extra = append(extra, finish)
+				}
+				finish = p
+				continue
+			}
+		default:
+			continue
+		}
+		extra = append(extra, p)
+	}
+
+	for _, p := range extra {
+		if err := g.DeleteProperty(ctx, event, p.Predicate, p.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/netmap"
+)
+
+// runTestEnumeration stores the same findings that an enumeration of owasp.org would discover.
+func runTestEnumeration(t *testing.T, cfg *config.Config) *Enumeration {
+	ctx := context.Background()
+	e := &Enumeration{
+		Config: cfg,
+		Graph:  netmap.NewGraph(netmap.NewCayleyGraphMemory()),
+	}
+
+	uuid := cfg.UUID.String()
+	if _, err := e.Graph.UpsertFQDN(ctx, "www.owasp.org", "DNS", uuid); err != nil {
+		t.Fatalf("Failed to insert the name: %v", err)
+	}
+	if err := e.Graph.UpsertA(ctx, "www.owasp.org", "192.168.1.1", "DNS", uuid); err != nil {
+		t.Fatalf("Failed to insert the address: %v", err)
+	}
+	if err := e.Graph.UpsertCNAME(ctx, "ftp.owasp.org", "www.owasp.org", "DNS", uuid); err != nil {
+		t.Fatalf("Failed to insert the alias: %v", err)
+	}
+	e.markSeen(ctx, "www.owasp.org")
+	e.markSeen(ctx, "192.168.1.1")
+	return e
+}
+
+func graphCounts(ctx context.Context, g *netmap.Graph) (int, int, int) {
+	names, _ := g.AllNodesOfType(ctx, netmap.TypeFQDN)
+	addrs, _ := g.AllNodesOfType(ctx, netmap.TypeAddr)
+
+	var edges int
+	for _, n := range append(names, addrs...) {
+		if count, err := g.CountOutEdges(ctx, n); err == nil {
+			edges += count
+		}
+	}
+	return len(names), len(addrs), edges
+}
+
+// duplicateQuads returns the quads that are stored more than once in the graph.
+func duplicateQuads(ctx context.Context, g *netmap.Graph) []string {
+	var dups []string
+
+	seen := make(map[string]bool)
+	for _, quad := range strings.Split(g.DumpGraph(ctx), "\n") {
+		if quad == "" {
+			continue
+		}
+		if seen[quad] {
+			dups = append(dups, quad)
+		}
+		seen[quad] = true
+	}
+	return dups
+}
+
+// duplicateEdges returns the edges leaving the nodes of the graph that are stored more than once.
+func duplicateEdges(ctx context.Context, g *netmap.Graph) []string {
+	var nodes []netmap.Node
+	for _, ntype := range []string{netmap.TypeFQDN, netmap.TypeAddr, netmap.TypeEvent} {
+		if n, err := g.AllNodesOfType(ctx, ntype); err == nil {
+			nodes = append(nodes, n...)
+		}
+	}
+
+	var dups []string
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		edges, err := g.ReadOutEdges(ctx, node)
+		if err != nil {
+			continue
+		}
+
+		for _, edge := range edges {
+			key := g.NodeToID(edge.From) + " " + edge.Predicate + " " + g.NodeToID(edge.To)
+			if seen[key] {
+				dups = append(dups, key)
+			}
+			seen[key] = true
+		}
+	}
+	return dups
+}
+
+func TestMigrateIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := netmap.NewGraph(netmap.NewCayleyGraphMemory())
+	defer db.Close()
+
+	first := runTestEnumeration(t, config.NewConfig())
+	defer first.Graph.Close()
+	if err := first.Migrate(ctx, db); err != nil {
+		t.Fatalf("The first migration failed: %v", err)
+	}
+	names, addrs, edges := graphCounts(ctx, db)
+
+	// The second enumeration has its own configuration and event, and discovers the same findings
+	// later, so the times it stores differ from the first
+	time.Sleep(1100 * time.Millisecond)
+	second := runTestEnumeration(t, config.NewConfig())
+	defer second.Graph.Close()
+	if first.Config.UUID.String() == second.Config.UUID.String() {
+		t.Fatal("The enumerations were given the same UUID")
+	}
+	if err := second.Migrate(ctx, db); err != nil {
+		t.Fatalf("The migration of the second enumeration failed: %v", err)
+	}
+
+	if n, a, e := graphCounts(ctx, db); n != names || a != addrs || e != edges {
+		t.Errorf("The counts changed from %d names, %d addresses and %d edges to %d, %d and %d",
+			names, addrs, edges, n, a, e)
+	}
+	if events := db.EventList(ctx); len(events) != 2 {
+		t.Errorf("The graph held the events %v after both enumerations were migrated", events)
+	}
+	if dups := duplicateQuads(ctx, db); len(dups) > 0 {
+		t.Errorf("The quads were stored more than once: %v", dups)
+	}
+	if dups := duplicateEdges(ctx, db); len(dups) > 0 {
+		t.Errorf("The edges were stored more than once: %v", dups)
+	}
+	if first, last := SeenTimes(ctx, db, "www.owasp.org"); !last.After(first) {
+		t.Errorf("The last seen time was not moved forward by the second enumeration: %v, %v", first, last)
+	}
+	for _, node := range []string{"www.owasp.org", "192.168.1.1"} {
+		if props, err := db.ReadProperties(ctx, node, FirstSeenPredicate, LastSeenPredicate); err != nil || len(props) != 2 {
+			t.Errorf("%s held the seen times %v instead of one first and one last seen time", node, props)
+		}
+	}
+
+	// Migrating the enumerations again leaves the graph unchanged
+	quads := len(strings.Split(db.DumpGraph(ctx), "\n"))
+	for _, e := range []*Enumeration{first, second} {
+		if err := e.Migrate(ctx, db); err != nil {
+			t.Fatalf("The enumeration failed to be migrated again: %v", err)
+		}
+	}
+	if q := len(strings.Split(db.DumpGraph(ctx), "\n")); q != quads {
+		t.Errorf("The graph grew from %d to %d quads when the enumerations were migrated again", quads, q)
+	}
+	if dups := duplicateEdges(ctx, db); len(dups) > 0 {
+		t.Errorf("The edges were stored more than once after migrating again: %v", dups)
+	}
+}
@@ -28,7 +28,7 @@ type subdomainTask struct {
 func newSubdomainTask(e *Enumeration) *subdomainTask {
 	r := &subdomainTask{
 		enum:            e,
-		queue:           queue.NewQueue(),
+		queue:           newOrderedQueue(e.Config),
 		cnames:          stringset.New(),
 		withinWildcards: stringset.New(),
 		timesChan:       make(chan *timesReq, 10),
@@ -73,12 +73,14 @@ func (r *subdomainTask) Process(ctx context.Context, data pipeline.Data, tp pipe
 	}
 
 	if r.checkForSubdomains(ctx, req, tp) {
-		r.queue.Append(&requests.ResolvedRequest{
-			Name:    req.Name,
-			Domain:  req.Domain,
-			Records: req.Records,
-			Tag:     req.Tag,
-			Source:  req.Source,
+		r.enqueue(&requests.ResolvedRequest{
+			Name:       req.Name,
+			Domain:     req.Domain,
+			Records:    req.Records,
+			Tag:        req.Tag,
+			Source:     req.Source,
+			Provenance: req.Provenance,
+			Depth:      req.Depth,
 		})
 	}
 	return req, nil
@@ -119,17 +121,26 @@ func (r *subdomainTask) checkForSubdomains(ctx context.Context, req *requests.DN
 		Tag:    req.Tag,
 		Source: req.Source,
 		Times:  times,
+		// The subdomain was discovered along with the name
+		Provenance: req.Provenance,
+		Depth:      req.Depth,
 	}
 
-	r.queue.Append(subreq)
+	r.enqueue(subreq)
 	if times == 1 {
 		pipeline.SendData(ctx, "root", subreq, tp)
 	}
 	return true
 }
 
+// enqueue appends the request for the data sources, and keeps it for the later phases of a phased enumeration.
+func (r *subdomainTask) enqueue(element interface{}) {
+	r.enum.phases.record(element)
+	r.queue.Append(element)
+}
+
 func (r *subdomainTask) subWithinWildcard(ctx context.Context, name, domain string) bool {
-	for _, t := range InitialQueryTypes {
+	for _, t := range initialQueryTypes(r.enum.Config) {
 		select {
 		case <-ctx.Done():
 			return false
@@ -161,25 +172,26 @@ loop:
 		default:
 		}
 
+		// The names are generated in a stable order when the enumeration is deterministic
 		element, ok := r.queue.Next()
 		if !ok {
 			break loop
 		}
 
-		for _, src := range r.enum.srcs {
+		for _, src := range r.enum.activeSources() {
 			switch v := element.(type) {
 			case *requests.ResolvedRequest:
 				src.Request(r.enum.ctx, v)
-				if r.enum.Config.Alterations && src.String() == "Alterations" {
-					count += len(r.enum.Config.AltWordlist)
+				if r.enum.Config.AlterationsFor(v.Name) && src.String() == "Alterations" {
+					count += len(r.enum.Config.AltWordlistFor(v.Name))
 				}
-				if r.enum.Config.BruteForcing && src.String() == "Brute Forcing" && r.enum.Config.MinForRecursive == 0 {
-					count += len(r.enum.Config.Wordlist)
+				if r.enum.Config.BruteForcingFor(v.Name) && src.String() == "Brute Forcing" && r.enum.Config.MinForRecursive == 0 {
+					count += len(r.enum.Config.WordlistFor(v.Name))
 				}
 			case *requests.SubdomainRequest:
 				src.Request(r.enum.ctx, v)
-				if r.enum.Config.BruteForcing && src.String() == "Brute Forcing" && v.Times >= r.enum.Config.MinForRecursive {
-					count += len(r.enum.Config.Wordlist)
+				if r.enum.Config.BruteForcingFor(v.Name) && src.String() == "Brute Forcing" && v.Times >= r.enum.Config.MinForRecursive {
+					count += len(r.enum.Config.WordlistFor(v.Name))
 				}
 			}
 		}
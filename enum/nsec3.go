@@ -0,0 +1,124 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+)
+
+// The number of nonexistent names queried to collect the NSEC3 hashes of a zone
+const nsec3Probes = 50
+
+// nsec3Lock serializes writes to the file in Config.NSEC3HashesPath
+var nsec3Lock sync.Mutex
+
+// nsec3Zone holds the NSEC3 parameters and hashed owner names collected for a zone.
+type nsec3Zone struct {
+	Zone       string
+	Salt       string
+	Iterations uint16
+	Hashes     *stringset.Set
+}
+
+// collectNSEC3 queries nonexistent names in the zone and collects the hashed owner names
+// from the NSEC3 records in the denial of existence responses. Nil is returned when the
+// zone does not provide NSEC3 records.
+func collectNSEC3(ctx context.Context, r resolve.Resolver, zone string) *nsec3Zone {
+	var z *nsec3Zone
+
+	for i := 0; i < nsec3Probes; i++ {
+		select {
+		case <-ctx.Done():
+			return z
+		default:
+		}
+
+		name := fmt.Sprintf("%x.%s", rand.Int63(), zone)
+		resp, _ := r.Query(ctx, resolve.WalkMsg(name, dns.TypeA), resolve.PriorityHigh, nil)
+		if resp == nil {
+			continue
+		}
+
+		var found bool
+		for _, rr := range resp.Ns {
+			nsec3, ok := rr.(*dns.NSEC3)
+			if !ok || nsec3.Hash != dns.SHA1 {
+				continue
+			}
+
+			if z == nil {
+				z = &nsec3Zone{
+					Zone:       zone,
+					Salt:       nsec3.Salt,
+					Iterations: nsec3.Iterations,
+					Hashes:     stringset.New(),
+				}
+			}
+
+			found = true
+			owner := strings.Split(nsec3.Hdr.Name, ".")[0]
+			z.Hashes.InsertMany(strings.ToUpper(owner), strings.ToUpper(nsec3.NextDomain))
+		}
+		// Stop probing zones that do not use NSEC3
+		if !found && z == nil {
+			return nil
+		}
+	}
+	return z
+}
+
+// crack returns the names built from the words that match the collected hashes.
+func (z *nsec3Zone) crack(words []string) []string {
+	var names []string
+
+	for _, word := range words {
+		name := strings.ToLower(strings.TrimSpace(word)) + "." + z.Zone
+		if h := dns.HashName(dns.Fqdn(name), dns.SHA1, z.Iterations, z.Salt); h != "" && z.Hashes.Has(h) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// hashcatLines returns the collected hashes in the format accepted by the hashcat NSEC3 mode.
+func (z *nsec3Zone) hashcatLines() []string {
+	salt := z.Salt
+	if salt == "-" {
+		salt = ""
+	}
+
+	var lines []string
+	for _, h := range z.Hashes.Slice() {
+		lines = append(lines, fmt.Sprintf("%s:.%s:%s:%d", strings.ToLower(h), z.Zone, salt, z.Iterations))
+	}
+	return lines
+}
+
+// saveNSEC3Hashes appends the hashes collected for the zone to the file in the path parameter.
+func saveNSEC3Hashes(path string, z *nsec3Zone) error {
+	nsec3Lock.Lock()
+	defer nsec3Lock.Unlock()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, line := range z.hashcatLines() {
+		if _, err := fmt.Fprintln(f, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
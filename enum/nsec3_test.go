@@ -0,0 +1,71 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// nsec3Resolver answers every query with a denial of existence containing a single NSEC3 record.
+type nsec3Resolver struct {
+	owner string
+	next  string
+}
+
+func (r *nsec3Resolver) String() string { return "nsec3" }
+func (r *nsec3Resolver) Len() int       { return 0 }
+func (r *nsec3Resolver) Stop()          {}
+func (r *nsec3Resolver) Stopped() bool  { return false }
+
+func (r *nsec3Resolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	resp.Rcode = dns.RcodeNameError
+	resp.Ns = []dns.RR{&dns.NSEC3{
+		Hdr:        dns.RR_Header{Name: r.owner + ".owasp.org.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET},
+		Hash:       dns.SHA1,
+		Iterations: 10,
+		SaltLength: 4,
+		Salt:       "AABBCCDD",
+		NextDomain: r.next,
+	}}
+	return resp, &resolve.ResolveError{Err: "NXDOMAIN", Rcode: dns.RcodeNameError}
+}
+
+func (r *nsec3Resolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestCollectAndCrackNSEC3(t *testing.T) {
+	r := &nsec3Resolver{
+		owner: dns.HashName("owasp.org.", dns.SHA1, 10, "AABBCCDD"),
+		next:  dns.HashName("www.owasp.org.", dns.SHA1, 10, "AABBCCDD"),
+	}
+
+	z := collectNSEC3(context.Background(), r, "owasp.org")
+	if z == nil {
+		t.Fatal("The NSEC3 records were not collected")
+	}
+	defer z.Hashes.Close()
+
+	if z.Hashes.Len() != 2 || z.Iterations != 10 || z.Salt != "AABBCCDD" {
+		t.Errorf("The NSEC3 parameters were not collected: %d hashes, salt %s, %d iterations",
+			z.Hashes.Len(), z.Salt, z.Iterations)
+	}
+
+	names := z.crack([]string{"ftp", "www", "mail"})
+	if len(names) != 1 || names[0] != "www.owasp.org" {
+		t.Errorf("The wordlist did not crack the expected hash: %v", names)
+	}
+
+	lines := z.hashcatLines()
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], ":.owasp.org:AABBCCDD:10") {
+		t.Errorf("The hashes were not provided in the hashcat format: %v", lines)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+// Orchestrator runs multiple enumerations concurrently within the process. The enumerations share
+// the resolver pool and data sources of the System, while each enumeration keeps its own configuration,
+// filters, graph and output. The names generated by the enumerations, such as the brute forcing and
+// altered names, are paced by an equal share of the DNS queries allowed by the System configuration,
+// so an enumeration generating many names cannot starve the others of the shared resolvers.
+type Orchestrator struct {
+	sync.Mutex
+	Sys     systems.System
+	enums   []*Enumeration
+	active  int
+	started bool
+}
+
+// NewOrchestrator returns an Orchestrator for the enumerations that share the System.
+func NewOrchestrator(sys systems.System) *Orchestrator {
+	return &Orchestrator{Sys: sys}
+}
+
+// Add returns a new Enumeration for the configuration that shares the System of the orchestrator.
+// The enumerations must be added before Start is called.
+func (o *Orchestrator) Add(cfg *config.Config) (*Enumeration, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.started {
+		return nil, errors.New("the enumerations cannot be added after the orchestrator was started")
+	}
+	// The system configuration provides the DNS queries the enumeration is paced by when not configured
+	if cfg.MaxDNSQueries == 0 {
+		cfg.MaxDNSQueries = o.Sys.Config().MaxDNSQueries
+	}
+
+	e := NewEnumeration(cfg, o.Sys)
+	if e == nil {
+		return nil, errors.New("failed to setup the enumeration")
+	}
+
+	e.orchestrator = o
+	o.enums = append(o.enums, e)
+	return e, nil
+}
+
+// Enumerations returns the enumerations managed by the orchestrator, in the order they were added.
+func (o *Orchestrator) Enumerations() []*Enumeration {
+	o.Lock()
+	defer o.Unlock()
+
+	return append([]*Enumeration(nil), o.enums...)
+}
+
+// Start runs the enumerations concurrently and returns once all of them have completed. The
+// error returned reports each of the enumerations that failed, identified by the root domain names.
+func (o *Orchestrator) Start(ctx context.Context) error {
+	o.Lock()
+	if o.started {
+		o.Unlock()
+		return errors.New("the orchestrator has already been started")
+	}
+	o.started = true
+	enums := append([]*Enumeration(nil), o.enums...)
+	o.active = len(enums)
+	o.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(enums))
+	for i, e := range enums {
+		wg.Add(1)
+		go func(i int, e *Enumeration) {
+			defer wg.Done()
+			// The enumerations still running receive the share of the completed enumeration
+			defer o.release()
+
+			errs[i] = e.Start(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var msgs []string
+	for i, err := range errs {
+		if err != nil {
+			domains := strings.Join(enums[i].Config.Domains(), ", ")
+			msgs = append(msgs, fmt.Sprintf("the enumeration of %s failed: %v", domains, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// Close cleans up the resources instantiated by the enumerations. The System is not shut down.
+func (o *Orchestrator) Close() {
+	for _, e := range o.Enumerations() {
+		e.Close()
+	}
+}
+
+func (o *Orchestrator) release() {
+	o.Lock()
+	defer o.Unlock()
+
+	o.active--
+}
+
+// share returns the queued elements an enumeration limited to max elements is allowed, which is
+// an equal share of the DNS queries allowed by the System among the running enumerations.
+func (o *Orchestrator) share(max int) int {
+	o.Lock()
+	active := o.active
+	o.Unlock()
+
+	total := max
+	if cfg := o.Sys.Config(); cfg != nil && cfg.MaxDNSQueries > 0 {
+		total = cfg.MaxDNSQueries
+	}
+	if active > 1 {
+		total /= active
+	}
+
+	if total > max {
+		total = max
+	}
+	if total < 1 {
+		total = 1
+	}
+	return total
+}
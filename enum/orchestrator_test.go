@@ -0,0 +1,60 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+func TestOrchestratorShare(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.MaxDNSQueries = 900
+	o := NewOrchestrator(&systems.SimpleSystem{Cfg: cfg})
+
+	tests := []struct {
+		active int
+		max    int
+		want   int
+	}{
+		{1, 900, 900},
+		{3, 900, 300},
+		{3, 200, 200},
+		{2, 2000, 450},
+		{1000, 900, 1},
+	}
+	for _, tt := range tests {
+		o.active = tt.active
+		if got := o.share(tt.max); got != tt.want {
+			t.Errorf("share(%d) with %d active enumerations = %d, want %d", tt.max, tt.active, got, tt.want)
+		}
+	}
+
+	o.active = 3
+	r := &enumSource{enum: &Enumeration{orchestrator: o}, maxSlots: 900}
+	if got := r.slots(); got != 300 {
+		t.Errorf("The enumeration was allowed %d slots, expected 300", got)
+	}
+	o.release()
+	if got := r.slots(); got != 450 {
+		t.Errorf("The enumeration was allowed %d slots after another completed, expected 450", got)
+	}
+}
+
+func TestOrchestratorAddAfterStart(t *testing.T) {
+	o := NewOrchestrator(&systems.SimpleSystem{Cfg: config.NewConfig()})
+
+	if err := o.Start(context.Background()); err != nil {
+		t.Errorf("Start returned an error without any enumerations: %v", err)
+	}
+	if _, err := o.Add(config.NewConfig()); err == nil {
+		t.Errorf("The enumeration was added after the orchestrator was started")
+	}
+	if err := o.Start(context.Background()); err == nil {
+		t.Errorf("The orchestrator was started twice")
+	}
+}
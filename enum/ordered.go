@@ -0,0 +1,152 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/queue"
+)
+
+// newOrderedQueue returns the queue for the elements released by the enumeration, which releases
+// the element with the lowest name or address next when the enumeration is deterministic, so the
+// release order does not depend on the order the data sources provided the elements in.
+func newOrderedQueue(cfg *config.Config) queue.Queue {
+	if !cfg.Deterministic {
+		return queue.NewQueue()
+	}
+	return &orderedQueue{signal: make(chan struct{}, 2)}
+}
+
+type orderedElement struct {
+	data     interface{}
+	key      string
+	priority int
+	seq      uint64
+}
+
+// orderedHeap is a min-heap of the elements keyed by elementKey. The elements with a higher
+// priority come first, and the elements with the same key are kept in the order appended.
+type orderedHeap []*orderedElement
+
+func (h orderedHeap) Len() int { return len(h) }
+
+func (h orderedHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	if h[i].key != h[j].key {
+		return h[i].key < h[j].key
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h orderedHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *orderedHeap) Push(x interface{}) {
+	*h = append(*h, x.(*orderedElement))
+}
+
+func (h *orderedHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	element := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return element
+}
+
+// orderedQueue implements the queue.Queue interface with the elements kept in an orderedHeap.
+type orderedQueue struct {
+	sync.Mutex
+	signal   chan struct{}
+	elements orderedHeap
+	seq      uint64
+}
+
+// Append implements the queue.Queue interface.
+func (q *orderedQueue) Append(data interface{}) {
+	q.AppendPriority(data, queue.PriorityNormal)
+}
+
+// AppendPriority implements the queue.Queue interface.
+func (q *orderedQueue) AppendPriority(data interface{}, priority int) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.seq++
+	heap.Push(&q.elements, &orderedElement{
+		data:     data,
+		key:      elementKey(data),
+		priority: priority,
+		seq:      q.seq,
+	})
+	q.sendSignal()
+}
+
+// Signal implements the queue.Queue interface.
+func (q *orderedQueue) Signal() <-chan struct{} {
+	return q.signal
+}
+
+// The caller must hold the lock.
+func (q *orderedQueue) sendSignal() {
+	// Send the signal up to two times, so the data cannot remain on the queue without a signal
+	for i := 0; i < 2 && len(q.signal) <= 1; i++ {
+		q.signal <- struct{}{}
+	}
+}
+
+// Next implements the queue.Queue interface.
+func (q *orderedQueue) Next() (interface{}, bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.elements.Len() == 0 {
+		return nil, false
+	}
+
+	element := heap.Pop(&q.elements).(*orderedElement)
+	if q.elements.Len() > 0 {
+		q.sendSignal()
+	}
+	return element.data, true
+}
+
+// Process implements the queue.Queue interface.
+func (q *orderedQueue) Process(callback func(interface{})) {
+	for element, ok := q.Next(); ok; element, ok = q.Next() {
+		callback(element)
+	}
+}
+
+// Empty implements the queue.Queue interface.
+func (q *orderedQueue) Empty() bool {
+	return q.Len() == 0
+}
+
+// Len implements the queue.Queue interface.
+func (q *orderedQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+
+	return q.elements.Len()
+}
+
+func elementKey(e interface{}) string {
+	switch v := e.(type) {
+	case *requests.DNSRequest:
+		return v.Name
+	case *requests.AddrRequest:
+		return v.Address
+	case *requests.ResolvedRequest:
+		return v.Name
+	case *requests.SubdomainRequest:
+		return v.Name
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+const (
+	phaseDiscovery = iota
+	phaseBruteForcing
+	phaseAlterations
+)
+
+// The data sources that generate the names of the later phases
+var phaseSources = map[string]int{
+	"Brute Forcing":   phaseBruteForcing,
+	"Token Expansion": phaseBruteForcing,
+	"Alterations":     phaseAlterations,
+}
+
+var phaseNames = []string{"discovery", "brute forcing", "alterations"}
+
+// enumPhases gates the brute forcing and alterations data sources when the enumeration is phased.
+// The names found by each phase are kept, so the data sources of the following phase receive
+// the names discovered before they were started.
+type enumPhases struct {
+	sync.Mutex
+	current int
+	found   []interface{}
+}
+
+func newEnumPhases() *enumPhases {
+	return &enumPhases{current: phaseDiscovery}
+}
+
+// sourcePhase returns the phase the data source is started in.
+func sourcePhase(src service.Service) int {
+	if phase, found := phaseSources[src.String()]; found {
+		return phase
+	}
+	return phaseDiscovery
+}
+
+// started returns true when the phase of the data source has been reached.
+func (ep *enumPhases) started(src service.Service) bool {
+	if ep == nil {
+		return true
+	}
+
+	ep.Lock()
+	defer ep.Unlock()
+
+	return sourcePhase(src) <= ep.current
+}
+
+// record keeps the resolved name or subdomain for the data sources of the phases not yet started.
+func (ep *enumPhases) record(element interface{}) {
+	if ep == nil {
+		return
+	}
+
+	ep.Lock()
+	defer ep.Unlock()
+
+	if ep.current < phaseAlterations {
+		ep.found = append(ep.found, element)
+	}
+}
+
+// next starts the following phase and returns it, along with the names found by the earlier phases.
+func (ep *enumPhases) next() (int, []interface{}, bool) {
+	if ep == nil {
+		return 0, nil, false
+	}
+
+	ep.Lock()
+	defer ep.Unlock()
+
+	if ep.current >= phaseAlterations {
+		return 0, nil, false
+	}
+
+	ep.current++
+	found := append([]interface{}(nil), ep.found...)
+	if ep.current >= phaseAlterations {
+		ep.found = nil
+	}
+	return ep.current, found, true
+}
+
+// startNextPhase sends the root domain names and the names found by the earlier phases to the data
+// sources of the next phase that has any. It returns false when all the phases have been started.
+func (e *Enumeration) startNextPhase() bool {
+	for {
+		phase, found, ok := e.phases.next()
+		if !ok {
+			return false
+		}
+
+		var srcs []service.Service
+		for _, src := range e.activeSources() {
+			if sourcePhase(src) == phase {
+				srcs = append(srcs, src)
+			}
+		}
+		if len(srcs) == 0 {
+			continue
+		}
+
+		var names []string
+		for _, src := range srcs {
+			names = append(names, src.String())
+		}
+		e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("Starting the %s phase with %d names found: %s", phaseNames[phase], len(found), strings.Join(names, ", ")))
+
+		for _, domain := range e.nameSrc.zones.Slice() {
+			req := &requests.DNSRequest{
+				Name:   domain,
+				Domain: domain,
+				Tag:    requests.DNS,
+				Source: "DNS",
+			}
+
+			for _, src := range srcs {
+				src.Request(e.ctx, req.Clone().(*requests.DNSRequest))
+			}
+		}
+		for _, element := range found {
+			for _, src := range srcs {
+				switch v := element.(type) {
+				case *requests.ResolvedRequest:
+					src.Request(e.ctx, v.Clone().(*requests.ResolvedRequest))
+				case *requests.SubdomainRequest:
+					src.Request(e.ctx, v.Clone().(*requests.SubdomainRequest))
+				}
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/queue"
+	"github.com/caffix/service"
+)
+
+type phaseTestService struct {
+	service.BaseService
+	sync.Mutex
+	names []string
+}
+
+func newPhaseTestService(name string) *phaseTestService {
+	s := new(phaseTestService)
+	s.BaseService = *service.NewBaseService(s, name)
+	return s
+}
+
+func (s *phaseTestService) Request(ctx context.Context, args service.Args) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch v := args.(type) {
+	case *requests.DNSRequest:
+		s.names = append(s.names, v.Name)
+	case *requests.ResolvedRequest:
+		s.names = append(s.names, v.Name)
+	case *requests.SubdomainRequest:
+		s.names = append(s.names, v.Name)
+	}
+}
+
+func (s *phaseTestService) requested() []string {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]string(nil), s.names...)
+}
+
+func TestPhasedSources(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Phased = true
+
+	passive := newPhaseTestService("Crtsh")
+	brute := newPhaseTestService("Brute Forcing")
+	alts := newPhaseTestService("Alterations")
+
+	r := newTestEnumSource(cfg)
+	r.rdnsSweeps = queue.NewQueue()
+	r.waitFor = 10 * time.Millisecond
+	r.zones.Insert("owasp.org")
+
+	e := r.enum
+	e.ctx = context.Background()
+	e.Bus = eventbus.NewEventBus()
+	e.nameSrc = r
+	e.srcs = []service.Service{passive, brute, alts}
+	e.phases = newEnumPhases()
+	e.subTask = &subdomainTask{enum: e, queue: queue.NewQueue()}
+
+	if active := e.activeSources(); len(active) != 1 || active[0] != passive {
+		t.Fatalf("Expected only the discovery data source to be active, but %d were active", len(active))
+	}
+
+	e.subTask.enqueue(&requests.ResolvedRequest{Name: "www.owasp.org", Domain: "owasp.org"})
+	if e.subTask.OutputRequests(10); len(passive.requested()) != 1 || len(brute.requested()) != 0 {
+		t.Fatalf("The resolved name was not only sent to the discovery data source")
+	}
+
+	if !e.startNextPhase() {
+		t.Fatal("The brute forcing phase was not started")
+	}
+	e.subTask.enqueue(&requests.ResolvedRequest{Name: "dev.owasp.org", Domain: "owasp.org"})
+	if e.subTask.OutputRequests(10); len(alts.requested()) != 0 {
+		t.Fatal("The resolved name was sent to the alterations before their phase")
+	}
+
+	// The idle input source starts the alterations phase instead of completing the enumeration
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if r.Next(ctx) || ctx.Err() != nil {
+		t.Fatal("The input source did not complete once all the phases were started")
+	}
+
+	if got := brute.requested(); len(got) != 3 || got[0] != "owasp.org" || got[1] != "www.owasp.org" || got[2] != "dev.owasp.org" {
+		t.Errorf("The brute forcing was not provided the names found by the discovery phase: %v", got)
+	}
+	if got := alts.requested(); len(got) != 3 || got[1] != "www.owasp.org" || got[2] != "dev.owasp.org" {
+		t.Errorf("The alterations were not provided the names found by the earlier phases: %v", got)
+	}
+	if active := e.activeSources(); len(active) != 3 {
+		t.Errorf("Expected all the data sources to be active, but %d were active", len(active))
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import "github.com/OWASP/Amass/v3/config"
+
+// PlannedQueries returns the number of DNS queries expected for the root domain names, the
+// provided names and the brute forcing of each root domain name. The queries generated by
+// later discoveries, such as recursive brute forcing and alterations, cannot be estimated.
+func PlannedQueries(cfg *config.Config) int {
+	if cfg.Passive {
+		return 0
+	}
+
+	var brute int
+	names := len(cfg.ProvidedNames)
+	for _, domain := range cfg.Domains() {
+		names++
+		if cfg.BruteForcingFor(domain) {
+			brute += len(cfg.WordlistFor(domain))
+		}
+	}
+	if max := cfg.MaxBruteCandidates; max > 0 && brute > max {
+		brute = max
+	}
+	return (names + brute) * len(initialQueryTypes(cfg))
+}
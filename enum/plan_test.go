@@ -0,0 +1,48 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestPlannedQueries(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomains("owasp.org", "example.com")
+	cfg.ProvidedNames = []string{"www.owasp.org"}
+	cfg.Wordlist = []string{"dev", "mail", "vpn"}
+
+	enabled := true
+	cfg.SetDomainSettings("owasp.org", &config.DomainSettings{BruteForcing: &enabled})
+	// The root domain names and the provided name, plus three brute forced names for owasp.org
+	if got, want := PlannedQueries(cfg), 6*len(InitialQueryTypes); got != want {
+		t.Errorf("PlannedQueries() = %d, want %d", got, want)
+	}
+
+	cfg.IPv4Only = true
+	if got, want := PlannedQueries(cfg), 6*(len(InitialQueryTypes)-1); got != want {
+		t.Errorf("PlannedQueries() = %d, want %d for IPv4 only", got, want)
+	}
+
+	// Only two of the brute forced names fit within the budget
+	cfg.MaxBruteCandidates = 2
+	if got, want := PlannedQueries(cfg), 5*(len(InitialQueryTypes)-1); got != want {
+		t.Errorf("PlannedQueries() = %d, want %d with the brute forcing budget", got, want)
+	}
+}
+
+func TestPlannedQueriesSelectedTypes(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.QueryTypes = []string{"cname", "A", "MX", "TXT", "a"}
+
+	if got := initialQueryTypes(cfg); len(got) != 4 {
+		t.Errorf("Expected the four selected record types to be queried, got %v", got)
+	}
+	if got, want := PlannedQueries(cfg), 4; got != want {
+		t.Errorf("PlannedQueries() = %d, want %d with the selected record types", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"time"
+
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+const progressInterval = 5 * time.Second
+
+// ProgressUpdate is periodically emitted on the channel returned by Progress.
+type ProgressUpdate struct {
+	Timestamp time.Time
+	Elapsed   time.Duration
+
+	// The names and addresses waiting to be released into the pipeline
+	Queued int
+
+	// The names and addresses released into the pipeline
+	Processed int
+
+	// The data sources with requests still being handled
+	ActiveSources int
+
+	// The state of the adaptive DNS query concurrency, when the maximum concurrency is configured
+	Concurrency *systems.ConcurrencyStats
+
+	// The state of the adaptive brute forcing pacing, when the brute forcing rate is limited
+	BruteRate *BruteRateStats
+
+	// The rough time remaining, which is only estimated for enumerations with a known
+	// amount of work, such as brute forcing without recursion or alterations
+	ETA *time.Duration
+}
+
+// concurrencyReporter is implemented by the systems that adapt the DNS query concurrency.
+type concurrencyReporter interface {
+	Concurrency() *systems.ConcurrencyStats
+}
+
+// Progress returns a channel that receives periodic updates until the enumeration completes.
+// Only the latest update is buffered, so a slow consumer receives the most recent progress.
+func (e *Enumeration) Progress() <-chan ProgressUpdate {
+	e.progressLock.Lock()
+	defer e.progressLock.Unlock()
+
+	if e.progress == nil {
+		e.progress = make(chan ProgressUpdate, 1)
+		if e.progressClosed {
+			close(e.progress)
+		}
+	}
+	return e.progress
+}
+
+func (e *Enumeration) periodicProgress() {
+	start := time.Now()
+	t := time.NewTicker(progressInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			e.sendProgress(e.progressUpdate(start, time.Now()))
+			e.closeProgress()
+			return
+		case now := <-t.C:
+			e.sendProgress(e.progressUpdate(start, now))
+		}
+	}
+}
+
+func (e *Enumeration) progressUpdate(start, now time.Time) ProgressUpdate {
+	update := ProgressUpdate{
+		Timestamp: now,
+		Elapsed:   now.Sub(start),
+		Queued:    e.nameSrc.queueLen(),
+		Processed: e.nameSrc.numReleased(),
+	}
+
+	for _, src := range e.srcs {
+		if src.Len() > 0 {
+			update.ActiveSources++
+		}
+	}
+	if sys, ok := e.Sys.(concurrencyReporter); ok {
+		update.Concurrency = sys.Concurrency()
+	}
+	update.BruteRate = e.BruteRate()
+
+	if !e.boundedWork() || update.Processed == 0 {
+		return update
+	}
+	// The brute forcing of each root domain name determines the work remaining
+	total := len(e.Config.Domains()) * len(e.Config.Wordlist)
+	if max := e.Config.MaxBruteCandidates; max > 0 && total > max {
+		total = max
+	}
+	if known := update.Processed + update.Queued; known > total {
+		total = known
+	}
+
+	rate := float64(update.Processed) / float64(update.Elapsed)
+	eta := time.Duration(float64(total-update.Processed) / rate)
+	update.ETA = &eta
+	return update
+}
+
+// boundedWork returns true when the amount of work performed by the enumeration can be estimated.
+func (e *Enumeration) boundedWork() bool {
+	cfg := e.Config
+
+	return cfg.BruteForcing && len(cfg.Wordlist) > 0 && !cfg.Recursive &&
+		!cfg.Alterations && !cfg.Passive && !cfg.CTTail
+}
+
+func (e *Enumeration) sendProgress(update ProgressUpdate) {
+	e.progressLock.Lock()
+	defer e.progressLock.Unlock()
+
+	if e.progress == nil || e.progressClosed {
+		return
+	}
+	// Replace the stale update when the consumer has fallen behind
+	select {
+	case <-e.progress:
+	default:
+	}
+	e.progress <- update
+}
+
+func (e *Enumeration) closeProgress() {
+	e.progressLock.Lock()
+	defer e.progressLock.Unlock()
+
+	if e.progress != nil && !e.progressClosed {
+		close(e.progress)
+	}
+	e.progressClosed = true
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestProgressUpdateETA(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.BruteForcing = true
+	cfg.Recursive = false
+	cfg.Alterations = false
+	cfg.Wordlist = []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+
+	r := newTestEnumSource(cfg)
+	r.released = 5
+	e := r.enum
+	e.nameSrc = r
+
+	start := time.Now()
+	update := e.progressUpdate(start, start.Add(5*time.Second))
+	if update.Processed != 5 {
+		t.Errorf("The update reported %d processed elements", update.Processed)
+	}
+	if update.ETA == nil || *update.ETA != 5*time.Second {
+		t.Errorf("The update did not estimate the five seconds remaining: %v", update.ETA)
+	}
+
+	cfg.Recursive = true
+	if update := e.progressUpdate(start, start.Add(5*time.Second)); update.ETA != nil {
+		t.Errorf("An ETA was estimated for an unbounded enumeration: %v", *update.ETA)
+	}
+}
+
+func TestProgressChannelClosed(t *testing.T) {
+	e := &Enumeration{Config: config.NewConfig()}
+
+	ch := e.Progress()
+	e.sendProgress(ProgressUpdate{Processed: 1})
+	e.sendProgress(ProgressUpdate{Processed: 2})
+	e.closeProgress()
+
+	if update, ok := <-ch; !ok || update.Processed != 2 {
+		t.Errorf("The channel did not provide the latest update: %+v", update)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("The channel was not closed after the enumeration completed")
+	}
+	if _, ok := <-e.Progress(); ok {
+		t.Error("Progress returned an open channel after the enumeration completed")
+	}
+}
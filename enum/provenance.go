@@ -0,0 +1,37 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// Provenance returns the chain of discovery steps that derived the name, such as the alteration of
+// a name brute forced beneath a subdomain found in a certificate. Nil is returned for names that
+// were provided directly by a data source.
+func (e *Enumeration) Provenance(name string) []requests.DiscoveryStep {
+	e.provLock.Lock()
+	defer e.provLock.Unlock()
+
+	return append([]requests.DiscoveryStep(nil), e.provenance[strings.ToLower(name)]...)
+}
+
+// recordProvenance keeps the chain that first derived the accepted name.
+func (e *Enumeration) recordProvenance(name string, chain []requests.DiscoveryStep) {
+	if len(chain) == 0 {
+		return
+	}
+
+	e.provLock.Lock()
+	defer e.provLock.Unlock()
+
+	if e.provenance == nil {
+		e.provenance = make(map[string][]requests.DiscoveryStep)
+	}
+	if key := strings.ToLower(name); e.provenance[key] == nil {
+		e.provenance[key] = append([]requests.DiscoveryStep(nil), chain...)
+	}
+}
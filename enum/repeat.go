@@ -0,0 +1,215 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The total number of additional queries performed by the repeated resolutions
+	maxRepeatQueries = 100000
+	// The number of names resolved again concurrently
+	maxRepeatResolutions = 25
+)
+
+// repeatResolution tracks a name that is resolved again and the addresses found for it so far.
+type repeatResolution struct {
+	name      string
+	domain    string
+	remaining int
+	next      time.Time
+	addrs     map[string]struct{}
+}
+
+// repeatQueryTypes returns the address record types queried when a name is resolved again.
+func repeatQueryTypes(cfg *config.Config) []uint16 {
+	var types []uint16
+
+	for _, t := range initialQueryTypes(cfg) {
+		if t == dns.TypeA || t == dns.TypeAAAA {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// repeatInterval returns the duration waited between the repeated resolutions of a name.
+func repeatInterval(cfg *config.Config) time.Duration {
+	if cfg.ResolveRepeatInterval > 0 {
+		return cfg.ResolveRepeatInterval
+	}
+	return config.DefaultResolveRepeatInterval
+}
+
+// queueRepeat schedules the repeated resolutions of a name that resolved to addresses. Each name
+// is scheduled once, and names are no longer scheduled once the query budget has been spent.
+func (r *enumSource) queueRepeat(req *requests.DNSRequest) {
+	n := r.enum.Config.ResolveRepeat
+	if n <= 0 || r.repeatFilter.Has(req.Name) {
+		return
+	}
+
+	addrs := make(map[string]struct{})
+	for _, rec := range req.Records {
+		if t := uint16(rec.Type); t == dns.TypeA || t == dns.TypeAAAA {
+			addrs[strings.TrimSpace(rec.Data)] = struct{}{}
+		}
+	}
+	if len(addrs) == 0 {
+		return
+	}
+
+	cost := n * len(repeatQueryTypes(r.enum.Config))
+	r.repeatLock.Lock()
+	if r.repeatQueries+cost > maxRepeatQueries {
+		exhausted := !r.repeatCapped
+		r.repeatCapped = true
+		r.repeatLock.Unlock()
+
+		if exhausted {
+			r.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("The limit of %d repeated resolution queries has been reached", maxRepeatQueries))
+		}
+		return
+	}
+	r.repeatQueries += cost
+	r.repeatLock.Unlock()
+
+	r.repeatFilter.Insert(req.Name)
+	atomic.AddInt32(&r.repeatActive, 1)
+	r.repeats.Append(&repeatResolution{
+		name:      req.Name,
+		domain:    req.Domain,
+		remaining: n,
+		next:      time.Now().Add(repeatInterval(r.enum.Config)),
+		addrs:     addrs,
+	})
+}
+
+// resolvingRepeats returns true while repeated resolutions remain to be performed.
+func (r *enumSource) resolvingRepeats() bool {
+	return atomic.LoadInt32(&r.repeatActive) > 0
+}
+
+func (r *enumSource) processRepeats() {
+	sem := make(chan struct{}, maxRepeatResolutions)
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-r.repeats.Signal():
+		}
+
+		for {
+			e, ok := r.repeats.Next()
+			if !ok {
+				break
+			}
+
+			rr, good := e.(*repeatResolution)
+			if !good {
+				continue
+			}
+			// The names are queued in the order they are due, since the interval does not change
+			if wait := time.Until(rr.next); wait > 0 {
+				t := time.NewTimer(wait)
+				select {
+				case <-r.done:
+					t.Stop()
+					return
+				case <-t.C:
+				}
+			}
+
+			select {
+			case <-r.done:
+				return
+			case sem <- struct{}{}:
+			}
+			go func() {
+				defer func() { <-sem }()
+
+				r.repeatResolve(r.enum.ctx, rr)
+			}()
+		}
+	}
+}
+
+// repeatResolve resolves the name again, submits the addresses that were not found before, and
+// schedules the next resolution of the name.
+func (r *enumSource) repeatResolve(ctx context.Context, rr *repeatResolution) {
+	// The responses cached during the previous resolutions would hide the rotated addresses
+	qctx := systems.BypassQueryCache(ctx)
+
+	for _, t := range repeatQueryTypes(r.enum.Config) {
+		select {
+		case <-ctx.Done():
+			atomic.AddInt32(&r.repeatActive, -1)
+			return
+		default:
+		}
+
+		resp, err := r.enum.Sys.Pool().Query(qctx, resolve.QueryMsg(rr.name, t), resolve.PriorityLow, resolve.PoolRetryPolicy)
+		if err != nil || resp == nil {
+			continue
+		}
+
+		for _, a := range resolve.AnswersByType(resolve.ExtractAnswers(resp), t) {
+			addr := strings.TrimSpace(a.Data)
+			if _, found := rr.addrs[addr]; found || addr == "" {
+				continue
+			}
+
+			rr.addrs[addr] = struct{}{}
+			r.repeatAddr(ctx, rr, addr, t)
+		}
+	}
+
+	rr.remaining--
+	if rr.remaining <= 0 {
+		atomic.AddInt32(&r.repeatActive, -1)
+		return
+	}
+	rr.next = time.Now().Add(repeatInterval(r.enum.Config))
+	r.repeats.Append(rr)
+}
+
+// repeatAddr stores the relationship between the name and the newly found address, and
+// submits the address, so it is investigated like the addresses found by the first resolution.
+func (r *enumSource) repeatAddr(ctx context.Context, rr *repeatResolution, addr string, qtype uint16) {
+	var err error
+	uuid := r.enum.Config.UUID.String()
+
+	if qtype == dns.TypeA {
+		err = r.enum.Graph.UpsertA(ctx, rr.name, addr, "DNS", uuid)
+	} else {
+		err = r.enum.Graph.UpsertAAAA(ctx, rr.name, addr, "DNS", uuid)
+	}
+	if err != nil {
+		r.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("%s failed to insert the repeated resolution address: %v", r.enum.Graph, err))
+		return
+	}
+
+	r.enum.InputAddress(&requests.AddrRequest{
+		Address: addr,
+		InScope: true,
+		Domain:  rr.domain,
+		Tag:     requests.DNS,
+		Source:  "DNS",
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/netmap"
+	"github.com/caffix/queue"
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+)
+
+// rotatingResolver answers each A query with two addresses, advancing by one address per query.
+type rotatingResolver struct {
+	sync.Mutex
+	queries int
+}
+
+func (r *rotatingResolver) String() string { return "rotating" }
+func (r *rotatingResolver) Len() int       { return 0 }
+func (r *rotatingResolver) Stop()          {}
+func (r *rotatingResolver) Stopped() bool  { return false }
+
+func (r *rotatingResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.queries++
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	for i := 0; i < 2; i++ {
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.IPv4(192, 168, 1, byte(r.queries+i)),
+		})
+	}
+	return resp, nil
+}
+
+func (r *rotatingResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func newTestRepeatSource(cfg *config.Config) *enumSource {
+	r := newTestEnumSource(cfg)
+	r.repeats = queue.NewQueue()
+	r.repeatFilter = stringset.New()
+	r.enum.Bus = eventbus.NewEventBus()
+	r.enum.Graph = netmap.NewGraph(netmap.NewCayleyGraphMemory())
+	r.enum.Sys = &systems.SimpleSystem{Resolver: &rotatingResolver{}}
+	r.enum.ctx = context.Background()
+	r.enum.done = make(chan struct{})
+	return r
+}
+
+func TestRepeatResolve(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.IPv4Only = true
+	cfg.ResolveRepeat = 2
+
+	r := newTestRepeatSource(cfg)
+	defer r.enum.Graph.Close()
+
+	req := &requests.DNSRequest{
+		Name:    "www.owasp.org",
+		Domain:  "owasp.org",
+		Records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "192.168.1.1"}},
+	}
+	r.queueRepeat(req)
+	// Names are only scheduled once
+	r.queueRepeat(req)
+	if r.repeats.Len() != 1 || !r.resolvingRepeats() {
+		t.Fatalf("Expected the name to be scheduled once, but %d resolutions were queued", r.repeats.Len())
+	}
+
+	for i := 0; i < cfg.ResolveRepeat; i++ {
+		e, ok := r.repeats.Next()
+		if !ok {
+			t.Fatalf("Resolution %d of the name was not scheduled", i+1)
+		}
+		r.repeatResolve(r.enum.ctx, e.(*repeatResolution))
+	}
+
+	if r.repeats.Len() != 0 || r.resolvingRepeats() {
+		t.Errorf("The name was scheduled after the last repeated resolution")
+	}
+	// The first address was known, and the second address was returned by both resolutions
+	var addrs []string
+	for _, data := range r.enum.pending {
+		if a, ok := data.(*requests.AddrRequest); ok && a.InScope {
+			addrs = append(addrs, a.Address)
+		}
+	}
+	if len(addrs) != 2 || addrs[0] != "192.168.1.2" || addrs[1] != "192.168.1.3" {
+		t.Errorf("Expected the new addresses 192.168.1.2 and 192.168.1.3 to be submitted once, got %v", addrs)
+	}
+}
+
+func TestRepeatQueryBudget(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.ResolveRepeat = 3
+	cfg.IPv4Only = true
+
+	r := newTestRepeatSource(cfg)
+	defer r.enum.Graph.Close()
+	r.repeatQueries = maxRepeatQueries - 2
+
+	r.queueRepeat(&requests.DNSRequest{
+		Name:    "www.owasp.org",
+		Domain:  "owasp.org",
+		Records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "192.168.1.1"}},
+	})
+	if r.repeats.Len() != 0 || !r.repeatCapped {
+		t.Errorf("The name was scheduled after the query budget was spent")
+	}
+}
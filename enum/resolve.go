@@ -0,0 +1,115 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"golang.org/x/net/publicsuffix"
+)
+
+// ResolveNames resolves the names using the pool of resolvers built for the configuration, without
+// the other machinery of an enumeration. The record types, concurrency, retries and DNS wildcard
+// detection of the configuration are used, and the requests are returned for the names that
+// resolved without matching a DNS wildcard, ordered by name.
+func ResolveNames(ctx context.Context, names []string, cfg *config.Config) ([]*requests.DNSRequest, error) {
+	pool, err := systems.NewResolverPool(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Stop()
+
+	return resolveNames(ctx, cfg, pool, names), nil
+}
+
+func resolveNames(ctx context.Context, cfg *config.Config, pool resolve.Resolver, names []string) []*requests.DNSRequest {
+	unique := stringset.New()
+	defer unique.Close()
+
+	for _, name := range names {
+		if n := strings.ToLower(resolve.RemoveLastDot(strings.TrimSpace(name))); n != "" {
+			unique.Insert(n)
+		}
+	}
+
+	num := cfg.MaxDNSQueries
+	if num <= 0 {
+		num = config.DefaultQueriesPerPublicResolver
+	}
+	if n := unique.Len(); num > n {
+		num = n
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var results []*requests.DNSRequest
+	queue := make(chan string, num)
+
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for name := range queue {
+				if req := resolveName(ctx, cfg, pool, name); req != nil {
+					lock.Lock()
+					results = append(results, req)
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+
+loop:
+	for _, name := range unique.Slice() {
+		select {
+		case <-ctx.Done():
+			break loop
+		case queue <- name:
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// resolveName returns the request for the name with the records resolved, or nil when the
+// name did not resolve or the answers were matched by a DNS wildcard.
+func resolveName(ctx context.Context, cfg *config.Config, pool resolve.Resolver, name string) *requests.DNSRequest {
+	domain := cfg.WhichDomain(name)
+	if domain == "" {
+		if d, err := publicsuffix.EffectiveTLDPlusOne(name); err == nil {
+			domain = d
+		}
+	}
+	if domain == "" {
+		return nil
+	}
+
+	// The names provided by the caller are not trusted, so they are checked for DNS wildcards
+	req := &requests.DNSRequest{
+		Name:   name,
+		Domain: domain,
+		Tag:    requests.EXTERNAL,
+		Source: "ResolveNames",
+	}
+
+	wildcard, _, err := resolveRecords(ctx, cfg, pool, nil, req, nil)
+	if err != nil || wildcard || len(req.Records) == 0 {
+		return nil
+	}
+	return req
+}
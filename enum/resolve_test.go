@@ -0,0 +1,72 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// batchResolver answers the A queries for the names beneath owasp.org, and reports
+// the names beneath wild.owasp.org as matching a DNS wildcard.
+type batchResolver struct{}
+
+func (r *batchResolver) String() string { return "batch" }
+func (r *batchResolver) Len() int       { return 0 }
+func (r *batchResolver) Stop()          {}
+func (r *batchResolver) Stopped() bool  { return false }
+
+func (r *batchResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	q := msg.Question[0]
+	if q.Qtype != dns.TypeA || !strings.HasSuffix(q.Name, ".owasp.org.") {
+		resp.Rcode = dns.RcodeNameError
+		return resp, nil
+	}
+
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   net.IPv4(192, 168, 1, 1),
+	})
+	return resp, nil
+}
+
+func (r *batchResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	if strings.HasSuffix(msg.Question[0].Name, ".wild.owasp.org.") {
+		return resolve.WildcardTypeStatic
+	}
+	return resolve.WildcardTypeNone
+}
+
+func TestResolveNames(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.MaxDNSQueries = 2
+
+	names := []string{"WWW.owasp.org", "mail.owasp.org.", "www.owasp.org", "abc.wild.owasp.org", "www.example.com", ""}
+	results := resolveNames(context.Background(), cfg, &batchResolver{}, names)
+
+	if len(results) != 2 || results[0].Name != "mail.owasp.org" || results[1].Name != "www.owasp.org" {
+		var got []string
+		for _, req := range results {
+			got = append(got, req.Name)
+		}
+		t.Fatalf("The resolved names were not returned in order without duplicates: %v", got)
+	}
+	for _, req := range results {
+		if req.Domain != "owasp.org" {
+			t.Errorf("The domain of %s was not assigned: %s", req.Name, req.Domain)
+		}
+		if len(req.Records) != 1 || req.Records[0].Data != "192.168.1.1" {
+			t.Errorf("The records of %s were not resolved: %v", req.Name, req.Records)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// The number of PTR queries performed concurrently during a reverse DNS sweep
+const maxReverseSweepQueries = 25
+
+// queueReverseSweep schedules a reverse DNS sweep of the netblock enclosing the address.
+func (r *enumSource) queueReverseSweep(req *requests.AddrRequest) {
+	ip := net.ParseIP(req.Address)
+	if ip == nil || !r.enum.Config.IsAddressFamilyAllowed(req.Address) {
+		return
+	}
+
+	if cidr := r.sweepNetblock(ip); cidr != nil {
+		r.queueSweepBlock(cidr)
+	}
+}
+
+// sweepNetblock returns the netblock enclosing the address, narrowed to the maximum sweep size.
+func (r *enumSource) sweepNetblock(ip net.IP) *net.IPNet {
+	cidr := r.addrCIDR(ip.String())
+
+	bits := 32
+	ipv6 := amassnet.IsIPv6(ip)
+	if ipv6 {
+		bits = 128
+	}
+
+	max := r.enum.Config.ReverseDNSPrefixLen(ipv6)
+	if ones, _ := cidr.Mask.Size(); ones >= max {
+		return cidr
+	}
+
+	mask := net.CIDRMask(max, bits)
+	return &net.IPNet{
+		IP:   ip.Mask(mask),
+		Mask: mask,
+	}
+}
+
+// sweepingReverseDNS returns true while reverse DNS sweeps are in progress or remain queued.
+func (r *enumSource) sweepingReverseDNS() bool {
+	return atomic.LoadInt32(&r.rdnsActive) == 1 || r.rdnsSweeps.Len() > 0
+}
+
+func (r *enumSource) processReverseSweeps() {
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-r.rdnsSweeps.Signal():
+		}
+
+		for {
+			e, ok := r.rdnsSweeps.Next()
+			if !ok {
+				break
+			}
+
+			if cidr, good := e.(*net.IPNet); good {
+				atomic.StoreInt32(&r.rdnsActive, 1)
+				r.reverseSweep(r.enum.ctx, cidr)
+				atomic.StoreInt32(&r.rdnsActive, 0)
+			}
+		}
+	}
+}
+
+// reverseSweep performs PTR queries across the netblock and submits the in-scope
+// names discovered, as if they were provided by a data source.
+func (r *enumSource) reverseSweep(ctx context.Context, cidr *net.IPNet) {
+	sem := make(chan struct{}, maxReverseSweepQueries)
+
+	for _, ip := range amassnet.AllHosts(cidr) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.done:
+			return
+		case sem <- struct{}{}:
+		}
+
+		go func(addr string) {
+			defer func() { <-sem }()
+
+			if name := r.reverseLookup(ctx, addr); name != "" {
+				r.dataSourceName(&requests.DNSRequest{
+					Name:   name,
+					Domain: r.enum.Config.WhichDomain(name),
+					Tag:    requests.DNS,
+					Source: "Reverse DNS Sweep",
+				})
+			}
+		}(ip.String())
+	}
+
+	// Wait for the last queries to complete
+	for i := 0; i < maxReverseSweepQueries; i++ {
+		sem <- struct{}{}
+	}
+}
+
+// reverseLookup returns the in-scope name from the PTR record of the address.
+func (r *enumSource) reverseLookup(ctx context.Context, addr string) string {
+	msg := resolve.ReverseMsg(addr)
+	if msg == nil {
+		return ""
+	}
+
+	resp, err := r.enum.Sys.Pool().Query(ctx, msg, resolve.PriorityLow, resolve.PoolRetryPolicy)
+	if err != nil {
+		return ""
+	}
+
+	rr := resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypePTR)
+	if len(rr) == 0 {
+		return ""
+	}
+
+	name := strings.ToLower(resolve.RemoveLastDot(rr[0].Data))
+	if amassdns.RemoveAsteriskLabel(name) != name || r.enum.Config.WhichDomain(name) == "" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"net"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+func TestSweepNetblock(t *testing.T) {
+	cache := requests.NewASNCache()
+	cache.Update(&requests.ASNRequest{
+		Address: "72.237.4.1",
+		ASN:     26808,
+		Prefix:  "72.237.0.0/16",
+	})
+	cache.Update(&requests.ASNRequest{
+		Address: "104.16.0.1",
+		ASN:     13335,
+		Prefix:  "104.16.0.0/24",
+	})
+
+	r := newTestEnumSource(config.NewConfig())
+	r.enum.Sys = &systems.SimpleSystem{ASNCache: cache}
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"72.237.4.113", "72.237.4.0/22"},
+		{"104.16.0.10", "104.16.0.0/24"},
+		{"2001:db8::1", "2001:db8::/118"},
+	}
+	for _, tt := range tests {
+		if got := r.sweepNetblock(net.ParseIP(tt.addr)); got.String() != tt.want {
+			t.Errorf("sweepNetblock(%s) = %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+}
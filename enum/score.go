@@ -0,0 +1,127 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+)
+
+const (
+	// The score added for each token that is an interesting keyword
+	keywordScore = 10
+	// The score added for each token that contains an interesting keyword, such as myadmin
+	partialKeywordScore = 5
+	// The score added when HTTP verification received a response from a web server for the name
+	webServerScore = 5
+	// Names with at least this score are sent through active enumeration ahead of the others
+	priorityScore = keywordScore
+)
+
+// ScoreName returns the interestingness of the labels to the left of the domain, which is the sum of
+// the scores for the tokens that match the keywords. The name is not changed or filtered by the score.
+func ScoreName(name, domain string, keywords []string) int {
+	name = strings.Trim(strings.ToLower(name), ".")
+	domain = strings.Trim(strings.ToLower(domain), ".")
+
+	labels := name
+	if domain != "" {
+		if name == domain || !strings.HasSuffix(name, "."+domain) {
+			return 0
+		}
+		labels = strings.TrimSuffix(name, "."+domain)
+	}
+	return scoreTokens(nameTokens(labels), keywords)
+}
+
+// nameTokens splits the text on the characters that are not letters or digits, and on the boundaries
+// between letters and digits, so vpn-01 and vpn01 both provide the vpn token.
+func nameTokens(text string) []string {
+	var tokens []string
+	var cur []rune
+	var digits bool
+
+	flush := func() {
+		if len(cur) > 0 {
+			tokens = append(tokens, string(cur))
+			cur = nil
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if d := unicode.IsDigit(r); d != digits {
+			flush()
+			digits = d
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return tokens
+}
+
+func scoreTokens(tokens, keywords []string) int {
+	var score int
+
+	for _, token := range tokens {
+		best := 0
+		for _, kw := range keywords {
+			kw = strings.ToLower(strings.TrimSpace(kw))
+			if kw == "" {
+				continue
+			}
+			if token == kw {
+				best = keywordScore
+				break
+			}
+			// Short keywords, such as db, are only matched as complete tokens
+			if len(kw) >= 3 && strings.Contains(token, kw) {
+				best = partialKeywordScore
+			}
+		}
+		score += best
+	}
+	return score
+}
+
+// NameScore returns the score of the discovered name, which adds the signals collected during
+// the enumeration, such as the web server response found by HTTP verification, to ScoreName.
+func (e *Enumeration) NameScore(name string) int {
+	name = strings.ToLower(name)
+	keywords := e.Config.InterestingKeywords
+	if len(keywords) == 0 {
+		keywords = config.DefaultInterestingKeywords
+	}
+
+	score := ScoreName(name, e.Config.WhichDomain(name), keywords)
+	// The response for a suspected wildcard or parking hit says nothing about the name
+	if e.SuspectedWildcard(name) {
+		return score
+	}
+	if fp := e.webServer(name); fp != nil {
+		score += webServerScore + scoreTokens(nameTokens(fp.Title), keywords)
+	}
+	return score
+}
+
+func (e *Enumeration) addWebServer(name string, fp *http.Fingerprint) {
+	e.webLock.Lock()
+	defer e.webLock.Unlock()
+
+	if _, found := e.webServers[name]; !found {
+		e.webServers[name] = fp
+	}
+}
+
+func (e *Enumeration) webServer(name string) *http.Fingerprint {
+	e.webLock.Lock()
+	defer e.webLock.Unlock()
+
+	return e.webServers[name]
+}
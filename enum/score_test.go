@@ -0,0 +1,65 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/caffix/stringset"
+)
+
+func TestScoreName(t *testing.T) {
+	keywords := []string{"admin", "api", "vpn", "db"}
+
+	tests := []struct {
+		name  string
+		score int
+	}{
+		{"www.owasp.org", 0},
+		{"api.owasp.org", keywordScore},
+		{"vpn01.owasp.org", keywordScore},
+		{"admin-api.owasp.org", 2 * keywordScore},
+		{"myadmin.owasp.org", partialKeywordScore},
+		{"dbx.owasp.org", 0},
+		{"API.Internal.owasp.org", keywordScore},
+		{"owasp.org", 0},
+		{"api.example.com", 0},
+	}
+
+	for _, test := range tests {
+		if score := ScoreName(test.name, "owasp.org", keywords); score != test.score {
+			t.Errorf("%s received the score %d instead of %d", test.name, score, test.score)
+		}
+	}
+}
+
+func TestNameScoreSignals(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	e := &Enumeration{
+		Config:     cfg,
+		suspects:   stringset.New(),
+		webServers: make(map[string]*http.Fingerprint),
+	}
+	defer e.suspects.Close()
+
+	name := "staging.owasp.org"
+	base := e.NameScore(name)
+	if base != keywordScore {
+		t.Errorf("the default keywords provided the score %d", base)
+	}
+
+	e.addWebServer(name, &http.Fingerprint{StatusCode: 200, Title: "Jenkins Dashboard"})
+	if score := e.NameScore(name); score != base+webServerScore+2*keywordScore {
+		t.Errorf("the web server signals provided the score %d", score)
+	}
+
+	e.suspects.Insert(name)
+	if score := e.NameScore(name); score != base {
+		t.Errorf("the suspected wildcard hit received the score %d", score)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"time"
+
+	"github.com/caffix/netmap"
+)
+
+// The node property predicates that record when an asset was observed
+const (
+	FirstSeenPredicate = "first_seen"
+	LastSeenPredicate  = "last_seen"
+)
+
+// SeenTimes returns the first and last times the node was observed according to the graph.
+// Zero times are returned when the node does not carry the properties.
+func SeenTimes(ctx context.Context, g *netmap.Graph, node string) (time.Time, time.Time) {
+	firsts, lasts, err := readSeenTimes(ctx, g, node)
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+
+	return earliest(firsts), latest(lasts)
+}
+
+// MarkSeen records that the node was observed at time t, preserving the first seen time already
+// stored and moving the last seen time forward. When t is the zero time, the timestamps already
+// stored are merged, which is required after a migration has combined the values of two graphs.
+func MarkSeen(ctx context.Context, g *netmap.Graph, node string, t time.Time) error {
+	firsts, lasts, err := readSeenTimes(ctx, g, node)
+	if err != nil {
+		return err
+	}
+
+	if !t.IsZero() {
+		t = t.UTC().Truncate(time.Second)
+		firsts = append(firsts, t)
+		lasts = append(lasts, t)
+	}
+	if len(firsts) == 0 || len(lasts) == 0 {
+		return nil
+	}
+
+	if err := replaceSeenTime(ctx, g, node, FirstSeenPredicate, earliest(firsts)); err != nil {
+		return err
+	}
+	return replaceSeenTime(ctx, g, node, LastSeenPredicate, latest(lasts))
+}
+
+func (e *Enumeration) markSeen(ctx context.Context, node string) {
+	_ = MarkSeen(ctx, e.Graph, node, time.Now())
+}
+
+func readSeenTimes(ctx context.Context, g *netmap.Graph, node string) ([]time.Time, []time.Time, error) {
+	props, err := g.ReadProperties(ctx, netmap.Node(node), FirstSeenPredicate, LastSeenPredicate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var firsts, lasts []time.Time
+	for _, p := range props {
+		t, err := time.Parse(time.RFC3339, valueString(p))
+		if err != nil {
+			continue
+		}
+
+		switch p.Predicate {
+		case FirstSeenPredicate:
+			firsts = append(firsts, t)
+		case LastSeenPredicate:
+			lasts = append(lasts, t)
+		}
+	}
+	return firsts, lasts, nil
+}
+
+// replaceSeenTime leaves the node with a single value for the predicate.
+func replaceSeenTime(ctx context.Context, g *netmap.Graph, node, predicate string, t time.Time) error {
+	props, err := g.ReadProperties(ctx, netmap.Node(node), predicate)
+	if err != nil {
+		return err
+	}
+
+	value := t.Format(time.RFC3339)
+	var found bool
+	for _, p := range props {
+		if valueString(p) == value && !found {
+			found = true
+			continue
+		}
+		if err := g.DeleteProperty(ctx, netmap.Node(node), predicate, p.Value); err != nil {
+			return err
+		}
+	}
+
+	if found {
+		return nil
+	}
+	return g.UpsertProperty(ctx, netmap.Node(node), predicate, value)
+}
+
+func valueString(p *netmap.Property) string {
+	if s, ok := p.Value.Native().(string); ok {
+		return s
+	}
+	return p.Value.String()
+}
+
+func earliest(times []time.Time) time.Time {
+	var t time.Time
+	for _, v := range times {
+		if t.IsZero() || v.Before(t) {
+			t = v
+		}
+	}
+	return t
+}
+
+func latest(times []time.Time) time.Time {
+	var t time.Time
+	for _, v := range times {
+		if v.After(t) {
+			t = v
+		}
+	}
+	return t
+}
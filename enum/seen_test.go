@@ -0,0 +1,79 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caffix/netmap"
+)
+
+func TestMarkSeen(t *testing.T) {
+	ctx := context.Background()
+	g := netmap.NewGraph(netmap.NewCayleyGraphMemory())
+	defer g.Close()
+
+	name := "www.owasp.org"
+	if _, err := g.UpsertFQDN(ctx, name, "DNS", "event"); err != nil {
+		t.Fatalf("Failed to insert the FQDN: %v", err)
+	}
+	if first, last := SeenTimes(ctx, g, name); !first.IsZero() || !last.IsZero() {
+		t.Errorf("a node never marked returned the times %v and %v", first, last)
+	}
+
+	start := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+	later := start.Add(time.Hour)
+	for _, ts := range []time.Time{later, start, later.Add(-time.Minute)} {
+		if err := MarkSeen(ctx, g, name, ts); err != nil {
+			t.Fatalf("Failed to mark the node as seen: %v", err)
+		}
+	}
+
+	if first, last := SeenTimes(ctx, g, name); !first.Equal(start) || !last.Equal(later) {
+		t.Errorf("the node was first seen %v and last seen %v", first, last)
+	}
+	if n, _ := g.CountProperties(ctx, netmap.Node(name), FirstSeenPredicate, LastSeenPredicate); n != 2 {
+		t.Errorf("the node had %d timestamp properties", n)
+	}
+
+	if err := MarkSeen(ctx, g, "missing.owasp.org", start); err == nil {
+		t.Errorf("marking a node that does not exist did not return an error")
+	}
+}
+
+func TestMarkSeenMerge(t *testing.T) {
+	ctx := context.Background()
+	g := netmap.NewGraph(netmap.NewCayleyGraphMemory())
+	defer g.Close()
+
+	addr := "192.0.2.1"
+	if err := g.UpsertA(ctx, "www.owasp.org", addr, "DNS", "event"); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	// Simulate a migration that added the timestamps of a new enumeration to a previous one
+	times := []string{"2021-01-01T00:00:00Z", "2021-01-02T00:00:00Z", "2021-06-01T00:00:00Z", "2021-06-02T00:00:00Z"}
+	for i, ts := range times {
+		pred := FirstSeenPredicate
+		if i%2 == 1 {
+			pred = LastSeenPredicate
+		}
+		if err := g.UpsertProperty(ctx, netmap.Node(addr), pred, ts); err != nil {
+			t.Fatalf("Failed to insert the property: %v", err)
+		}
+	}
+
+	if err := MarkSeen(ctx, g, addr, time.Time{}); err != nil {
+		t.Fatalf("Failed to merge the timestamps: %v", err)
+	}
+
+	first, last := SeenTimes(ctx, g, addr)
+	if first.Format(time.RFC3339) != times[0] || last.Format(time.RFC3339) != times[3] {
+		t.Errorf("the merged node was first seen %v and last seen %v", first, last)
+	}
+	if n, _ := g.CountProperties(ctx, netmap.Node(addr), FirstSeenPredicate, LastSeenPredicate); n != 2 {
+		t.Errorf("the merged node had %d timestamp properties", n)
+	}
+}
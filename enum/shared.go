@@ -0,0 +1,137 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/netmap"
+	"github.com/caffix/stringset"
+)
+
+const (
+	// The node type and property predicate that record when an enumeration claimed a name
+	sharedFilterNodeType  = "shared_filter"
+	sharedFilterPredicate = "claimed"
+)
+
+// sharedFilter is consulted by the enumerations sharing a datastore before a name is resolved.
+type sharedFilter interface {
+	// Claim returns false when another enumeration handled the name within the TTL
+	Claim(ctx context.Context, name string) (bool, error)
+	Close()
+}
+
+// graphSharedFilter records the names claimed by each enumeration in the shared graph database.
+type graphSharedFilter struct {
+	graph   *netmap.Graph
+	ttl     time.Duration
+	lock    sync.Mutex
+	claimed *stringset.Set
+}
+
+func newGraphSharedFilter(g *netmap.Graph, ttl time.Duration) *graphSharedFilter {
+	return &graphSharedFilter{
+		graph:   g,
+		ttl:     ttl,
+		claimed: stringset.New(),
+	}
+}
+
+// Claim implements the sharedFilter interface.
+func (f *graphSharedFilter) Claim(ctx context.Context, name string) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	// Names already claimed by this enumeration, such as those reconsidered from a trusted source, are not checked again
+	if f.claimed.Has(name) {
+		return true, nil
+	}
+
+	id := sharedFilterNodeType + ":" + name
+	node, err := f.graph.UpsertNode(ctx, id, sharedFilterNodeType)
+	if err != nil {
+		return false, err
+	}
+
+	props, err := f.graph.ReadProperties(ctx, node, sharedFilterPredicate)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	for _, p := range props {
+		if t, err := time.Parse(time.RFC3339, valueString(p)); err == nil && now.Sub(t) < f.ttl {
+			return false, nil
+		}
+	}
+	for _, p := range props {
+		if err := f.graph.DeleteProperty(ctx, node, sharedFilterPredicate, p.Value); err != nil {
+			return false, err
+		}
+	}
+	if err := f.graph.UpsertProperty(ctx, node, sharedFilterPredicate, now.Format(time.RFC3339)); err != nil {
+		return false, err
+	}
+
+	f.claimed.Insert(name)
+	return true, nil
+}
+
+// Close implements the sharedFilter interface.
+func (f *graphSharedFilter) Close() {
+	f.claimed.Close()
+}
+
+// newSharedFilter returns the sharedFilter selected by the configuration, which uses the first graph
+// database of the system. Nil is returned when the shared filter is not used.
+func newSharedFilter(e *Enumeration) sharedFilter {
+	if e.Config.SharedFilter != "graph" || e.Sys == nil {
+		return nil
+	}
+
+	graphs := e.Sys.GraphDatabases()
+	if len(graphs) == 0 {
+		return nil
+	}
+
+	ttl := e.Config.SharedFilterTTL
+	if ttl <= 0 {
+		ttl = config.DefaultSharedFilterTTL
+	}
+	return newGraphSharedFilter(graphs[0], ttl)
+}
+
+// sharedClaim returns false when the shared filter reports that another enumeration handled the name
+// recently. The enumeration continues with the local filter only once the shared filter has failed.
+func (r *enumSource) sharedClaim(req *requests.DNSRequest) bool {
+	r.sharedLock.Lock()
+	sf := r.shared
+	r.sharedLock.Unlock()
+	// Root domain names are always handled, since they start the investigation of the domain
+	if sf == nil || req.Name == req.Domain {
+		return true
+	}
+
+	ok, err := sf.Claim(r.enum.ctx, req.Name)
+	if err != nil {
+		r.sharedLock.Lock()
+		if r.shared != nil {
+			r.shared = nil
+			r.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("The shared filter failed and only the local filter will be used: %v", err))
+		}
+		r.sharedLock.Unlock()
+		return true
+	}
+	if !ok {
+		r.stats.update(func(st *Stats) { st.SharedDuplicates++ })
+	}
+	return ok
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/netmap"
+)
+
+type failingSharedFilter struct{}
+
+func (f *failingSharedFilter) Claim(ctx context.Context, name string) (bool, error) {
+	return false, errors.New("the backend is unavailable")
+}
+
+func (f *failingSharedFilter) Close() {}
+
+func newTestSharedSource(cfg *config.Config, sf sharedFilter) *enumSource {
+	r := newTestEnumSource(cfg)
+	r.subre = dns.AnySubdomainRegex()
+	r.tokens = make(chan struct{}, 10)
+	r.shared = sf
+	r.enum.ctx = context.Background()
+	r.enum.Bus = eventbus.NewEventBus()
+	return r
+}
+
+func TestSharedFilterSkipsClaimedNames(t *testing.T) {
+	g := netmap.NewGraph(netmap.NewCayleyGraphMemory())
+	defer g.Close()
+
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	// Both enumerations share the graph database
+	first := newTestSharedSource(cfg, newGraphSharedFilter(g, time.Hour))
+	second := newTestSharedSource(cfg, newGraphSharedFilter(g, time.Hour))
+
+	for _, r := range []*enumSource{first, second} {
+		for _, name := range []string{"owasp.org", "www.owasp.org"} {
+			r.newName(context.Background(), &requests.DNSRequest{
+				Name:   name,
+				Domain: "owasp.org",
+				Tag:    requests.DNS,
+				Source: "DNS",
+			}, nil)
+		}
+	}
+
+	if first.queueLen() != 2 {
+		t.Errorf("Expected the first enumeration to release both names, but %d were queued", first.queueLen())
+	}
+	// The root domain name is always handled
+	if second.queueLen() != 1 {
+		t.Errorf("Expected the second enumeration to release only the root domain, but %d names were queued", second.queueLen())
+	}
+	if st := second.stats.snapshot(); st.SharedDuplicates != 1 {
+		t.Errorf("Expected one name to be skipped by the shared filter, but %d were counted", st.SharedDuplicates)
+	}
+}
+
+func TestSharedFilterExpiredClaim(t *testing.T) {
+	g := netmap.NewGraph(netmap.NewCayleyGraphMemory())
+	defer g.Close()
+
+	ctx := context.Background()
+	if ok, err := newGraphSharedFilter(g, time.Hour).Claim(ctx, "www.owasp.org"); !ok || err != nil {
+		t.Fatalf("The name was not claimed: %v", err)
+	}
+	// The claim is older than the TTL of the other enumeration
+	if ok, err := newGraphSharedFilter(g, time.Nanosecond).Claim(ctx, "www.owasp.org"); !ok || err != nil {
+		t.Errorf("The expired claim prevented the name from being claimed again: %v", err)
+	}
+}
+
+func TestSharedFilterFailureDegrades(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	r := newTestSharedSource(cfg, &failingSharedFilter{})
+	r.newName(context.Background(), &requests.DNSRequest{
+		Name:   "www.owasp.org",
+		Domain: "owasp.org",
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}, nil)
+
+	if r.queueLen() != 1 {
+		t.Errorf("The name was not released after the shared filter failed")
+	}
+	if r.shared != nil {
+		t.Errorf("The enumeration continued to use the failed shared filter")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"sync"
+
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+// Stats provides the intake metrics collected by the enumeration input source.
+type Stats struct {
+	// The number of names and addresses submitted by the data sources
+	Submitted int
+
+	// The number of names and addresses that were released into the pipeline
+	Accepted int
+
+	// The number of names and addresses waiting to be released into the pipeline
+	Queued int
+
+	// The number of names and addresses rejected for having already been seen
+	Duplicates int
+
+	// The number of names and addresses rejected for being outside the scope
+	OutOfScope int
+
+	// The number of names dropped for matching the exclude patterns
+	Excluded int
+
+	// The number of results dropped because the OnNewName and OnNewAddr callbacks fell behind
+	DroppedCallbacks int
+
+	// The number of names skipped for having been handled recently by another enumeration sharing the datastore
+	SharedDuplicates int
+
+	// The number of names and addresses rejected for coming from untrusted sources in the trusted-only mode
+	Untrusted int
+
+	// The number of names dropped for being derived too many generations from a name provided by an untrusted source
+	RecursionLimited int
+
+	// The number of names and addresses whose processing was canceled for exceeding the request timeout
+	TimedOut int
+
+	// The number of times the brute forcing names were resolved again, since the queries appeared
+	// to have been lost or an NXDOMAIN response was received while the resolvers were rate limiting
+	BruteRetried int
+
+	// The number of brute forcing names given up on after the retries without being shown to be nonexistent
+	BruteLost int
+
+	// The number of names with records that failed the DNSSEC validation
+	DNSSECBogus int
+
+	// The hits and misses of the DNS query cache, including the cached NXDOMAIN responses,
+	// when the system caches the query results
+	QueryCache *systems.QueryCacheStats
+
+	// The counters and latencies of the DNS queries sent for each record type
+	QueryTypes []systems.QueryTypeStats
+}
+
+// queryCacheReporter is implemented by the systems that cache the DNS query results.
+type queryCacheReporter interface {
+	QueryCache() *systems.QueryCacheStats
+}
+
+// queryTypeReporter is implemented by the systems that track the DNS queries by record type.
+type queryTypeReporter interface {
+	QueryTypes() []systems.QueryTypeStats
+}
+
+// intakeStats maintains the Stats counters for concurrent data sources.
+type intakeStats struct {
+	sync.Mutex
+	stats Stats
+}
+
+func (s *intakeStats) update(f func(stats *Stats)) {
+	s.Lock()
+	defer s.Unlock()
+
+	f(&s.stats)
+}
+
+func (s *intakeStats) snapshot() Stats {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.stats
+}
+
+// updateStats applies the update to the counters of the input source, once the enumeration has started.
+func (e *Enumeration) updateStats(f func(stats *Stats)) {
+	if src := e.source(); src != nil {
+		src.stats.update(f)
+	}
+}
+
+// Stats returns the intake metrics collected since the enumeration was started.
+func (e *Enumeration) Stats() Stats {
+	var stats Stats
+	if src := e.source(); src != nil {
+		stats = src.stats.snapshot()
+		stats.Queued = src.queueLen()
+	}
+	if sys, ok := e.Sys.(queryCacheReporter); ok {
+		stats.QueryCache = sys.QueryCache()
+	}
+	if sys, ok := e.Sys.(queryTypeReporter); ok {
+		stats.QueryTypes = sys.QueryTypes()
+	}
+	return stats
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestStatsCounters(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.Deterministic = true
+	cfg.ExcludePatterns = []string{`^internal\.`}
+
+	r := newTestEnumSource(cfg)
+	r.subre = dns.AnySubdomainRegex()
+	r.tokens = newIntakeTokens(1)
+	r.enum.ctx = context.Background()
+	r.enum.nameSrc = r
+
+	for _, name := range []string{
+		"www.owasp.org",
+		"www.owasp.org",
+		"mail.owasp.org",
+		"www.example.com",
+		"internal.owasp.org",
+	} {
+		r.dataSourceName(&requests.DNSRequest{
+			Name:   name,
+			Domain: "owasp.org",
+			Tag:    requests.CERT,
+			Source: "Crtsh",
+		})
+	}
+	r.dataSourceAddr(&requests.AddrRequest{Address: "192.0.2.1", Domain: "owasp.org", InScope: true, Tag: requests.DNS, Source: "DNS"})
+	r.dataSourceAddr(&requests.AddrRequest{Address: "198.51.100.1", Domain: "example.com", Tag: requests.DNS, Source: "DNS"})
+
+	st := r.enum.Stats()
+	expected := Stats{
+		Submitted:  7,
+		Accepted:   3,
+		Queued:     3,
+		Duplicates: 1,
+		OutOfScope: 2,
+		Excluded:   1,
+	}
+	if st.Submitted != expected.Submitted || st.Accepted != expected.Accepted || st.Queued != expected.Queued ||
+		st.Duplicates != expected.Duplicates || st.OutOfScope != expected.OutOfScope || st.Excluded != expected.Excluded {
+		t.Errorf("Stats() = %+v, want %+v", st, expected)
+	}
+}
@@ -27,6 +27,7 @@ import (
 type dataManager struct {
 	enum        *Enumeration
 	queue       queue.Queue
+	cnames      *cnameChains
 	signalDone  chan struct{}
 	confirmDone chan struct{}
 }
@@ -36,6 +37,7 @@ func newDataManager(e *Enumeration) *dataManager {
 	dm := &dataManager{
 		enum:        e,
 		queue:       queue.NewQueue(),
+		cnames:      newCNAMEChains(),
 		signalDone:  make(chan struct{}, 2),
 		confirmDone: make(chan struct{}, 2),
 	}
@@ -64,6 +66,9 @@ func (dm *dataManager) Process(ctx context.Context, data pipeline.Data, tp pipel
 		}
 		if err := dm.dnsRequest(ctx, v, tp); err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, err.Error())
+		} else if len(v.Records) > 0 {
+			dm.enum.markSeen(ctx, v.Name)
+			dm.enum.nameSrc.queueRepeat(v)
 		}
 	case *requests.AddrRequest:
 		if v == nil {
@@ -137,19 +142,28 @@ func (dm *dataManager) insertCNAME(ctx context.Context, req *requests.DNSRequest
 		return errors.New("failed to extract a FQDN from the DNS answer data")
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
-	if err != nil || domain == "" {
-		return errors.New("failed to extract a domain name from the FQDN")
+	domain := cfg.WhichDomain(target)
+	if domain == "" {
+		domain, err = publicsuffix.EffectiveTLDPlusOne(target)
+		if err != nil || domain == "" {
+			return errors.New("failed to extract a domain name from the FQDN")
+		}
 	}
 	if err := dm.enum.Graph.UpsertCNAME(ctx, req.Name, target, req.Source, cfg.UUID.String()); err != nil {
 		return fmt.Errorf("%s failed to insert CNAME: %v", dm.enum.Graph, err)
 	}
+	// Targets already followed, including those that would close a loop, are not submitted again
+	if !dm.cnames.follow(req.Name, target) {
+		return nil
+	}
 	// Important - Allows chained CNAME records to be resolved until an A/AAAA record
 	dm.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-		Name:   target,
-		Domain: strings.ToLower(domain),
-		Tag:    requests.DNS,
-		Source: "DNS",
+		Name:       target,
+		Domain:     strings.ToLower(domain),
+		Tag:        requests.CNAME,
+		Source:     "DNS",
+		Provenance: derivedProvenance(req, requests.CNAME, "DNS"),
+		Depth:      req.Depth + 1,
 	}, tp)
 	return nil
 }
@@ -167,6 +181,7 @@ func (dm *dataManager) insertA(ctx context.Context, req *requests.DNSRequest, re
 	if err := dm.enum.Graph.UpsertA(ctx, req.Name, addr, req.Source, cfg.UUID.String()); err != nil {
 		return fmt.Errorf("%s failed to insert A record: %v", dm.enum.Graph, err)
 	}
+	dm.enum.markSeen(ctx, addr)
 
 	dm.enum.checkForMissedWildcards(addr)
 	dm.enum.nameSrc.pipelineData(ctx, &requests.AddrRequest{
@@ -192,6 +207,7 @@ func (dm *dataManager) insertAAAA(ctx context.Context, req *requests.DNSRequest,
 	if err := dm.enum.Graph.UpsertAAAA(ctx, req.Name, addr, req.Source, cfg.UUID.String()); err != nil {
 		return fmt.Errorf("%s failed to insert AAAA record: %v", dm.enum.Graph, err)
 	}
+	dm.enum.markSeen(ctx, addr)
 
 	dm.enum.checkForMissedWildcards(addr)
 	dm.enum.nameSrc.pipelineData(ctx, &requests.AddrRequest{
@@ -224,10 +240,12 @@ func (dm *dataManager) insertPTR(ctx context.Context, req *requests.DNSRequest,
 	}
 	// Important - Allows the target DNS name to be resolved in the forward direction
 	dm.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-		Name:   target,
-		Domain: domain,
-		Tag:    requests.DNS,
-		Source: "Reverse DNS",
+		Name:       target,
+		Domain:     domain,
+		Tag:        requests.DNS,
+		Source:     "Reverse DNS",
+		Provenance: derivedProvenance(req, requests.DNS, "Reverse DNS"),
+		Depth:      req.Depth + 1,
 	}, tp)
 	return nil
 }
@@ -248,10 +266,12 @@ func (dm *dataManager) insertSRV(ctx context.Context, req *requests.DNSRequest,
 	}
 	if domain := cfg.WhichDomain(target); domain != "" {
 		dm.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-			Name:   target,
-			Domain: domain,
-			Tag:    requests.DNS,
-			Source: "DNS",
+			Name:       target,
+			Domain:     domain,
+			Tag:        requests.DNS,
+			Source:     "DNS",
+			Provenance: derivedProvenance(req, requests.DNS, "DNS"),
+			Depth:      req.Depth + 1,
 		}, tp)
 	}
 	return nil
@@ -277,10 +297,12 @@ func (dm *dataManager) insertNS(ctx context.Context, req *requests.DNSRequest, r
 	}
 	if d := strings.ToLower(domain); target != d {
 		dm.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-			Name:   target,
-			Domain: d,
-			Tag:    requests.DNS,
-			Source: "DNS",
+			Name:       target,
+			Domain:     d,
+			Tag:        requests.DNS,
+			Source:     "DNS",
+			Provenance: derivedProvenance(req, requests.DNS, "DNS"),
+			Depth:      req.Depth + 1,
 		}, tp)
 	}
 	return nil
@@ -306,10 +328,12 @@ func (dm *dataManager) insertMX(ctx context.Context, req *requests.DNSRequest, r
 	}
 	if d := strings.ToLower(domain); target != d {
 		dm.enum.nameSrc.pipelineData(ctx, &requests.DNSRequest{
-			Name:   target,
-			Domain: d,
-			Tag:    requests.DNS,
-			Source: "DNS",
+			Name:       target,
+			Domain:     d,
+			Tag:        requests.DNS,
+			Source:     "DNS",
+			Provenance: derivedProvenance(req, requests.DNS, "DNS"),
+			Depth:      req.Depth + 1,
 		}, tp)
 	}
 	return nil
@@ -491,14 +515,16 @@ func (dm *dataManager) findInfraInfo(ctx context.Context, req *requests.AddrRequ
 	uuid := dm.enum.Config.UUID.String()
 	if r := dm.enum.Sys.Cache().AddrSearch(req.Address); r != nil {
 		_ = graph.UpsertInfrastructure(ctx, r.ASN, r.Description, req.Address, r.Prefix, r.Source, uuid)
+		dm.enum.expandByASN(ctx, r.ASN)
 		return
 	}
-	for _, src := range dm.enum.srcs {
+	for _, src := range dm.enum.activeSources() {
 		src.Request(ctx, &requests.ASNRequest{Address: req.Address})
 	}
 	for i := 0; i < 120; i++ {
 		if r := dm.enum.Sys.Cache().AddrSearch(req.Address); r != nil {
 			_ = graph.UpsertInfrastructure(ctx, r.ASN, r.Description, req.Address, r.Prefix, r.Source, uuid)
+			dm.enum.expandByASN(ctx, r.ASN)
 			return
 		}
 		time.Sleep(time.Second)
@@ -533,3 +559,9 @@ func fakePrefix(addr string) string {
 	mask := net.CIDRMask(bits, total)
 	return fmt.Sprintf("%s/%d", ip.Mask(mask).String(), bits)
 }
+
+// derivedProvenance returns the provenance of a name derived from the name in the request
+// by the technique identified by the tag and source.
+func derivedProvenance(req *requests.DNSRequest, tag, source string) []requests.DiscoveryStep {
+	return requests.DeriveProvenance(req.Name, requests.ProvenanceChain(req.Tag, req.Source, req.Provenance), tag, source)
+}
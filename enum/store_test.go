@@ -0,0 +1,55 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	mdns "github.com/miekg/dns"
+)
+
+func TestInsertTXTExtractsSPFNames(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	// The names are accepted before the test inspects the queue
+	cfg.Deterministic = true
+
+	r := newTestEnumSource(cfg)
+	r.subre = dns.AnySubdomainRegex()
+	r.tokens = make(chan struct{}, 1)
+	r.tokens <- struct{}{}
+	r.enum.nameSrc = r
+	dm := &dataManager{enum: r.enum}
+
+	bus := eventbus.NewEventBus()
+	defer bus.Stop()
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+
+	req := &requests.DNSRequest{
+		Name:   "owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{{
+			Name: "owasp.org",
+			Type: int(mdns.TypeTXT),
+			Data: "v=spf1 include:_spf.mail.owasp.org include:_spf.google.com ~all",
+		}},
+	}
+	if err := dm.insertTXT(ctx, req, 0, nil); err != nil {
+		t.Fatalf("The TXT record was not inserted: %v", err)
+	}
+
+	// Only the included name within the scope is submitted
+	if r.queueLen() != 1 {
+		t.Fatalf("Expected one name to be extracted from the SPF record, but %d were queued", r.queueLen())
+	}
+	if e, _ := r.queue.Next(); e.(*requests.DNSRequest).Name != "_spf.mail.owasp.org" {
+		t.Errorf("The SPF include was extracted as %s", e.(*requests.DNSRequest).Name)
+	}
+}
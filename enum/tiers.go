@@ -0,0 +1,169 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+const (
+	// The names discovered per minute, below which the next tier is activated, when not configured
+	defaultTierThreshold = 5
+	// The interval the discovery rate is measured across before activating the next tier
+	tierCheckInterval = time.Minute
+)
+
+// sourceTiers groups the data sources by tier. The sources of later tiers only receive
+// requests once the earlier tiers have stopped discovering enough names.
+type sourceTiers struct {
+	sync.Mutex
+	tiers  [][]service.Service
+	nums   []int
+	active int
+}
+
+func newSourceTiers(cfg *config.Config, srcs []service.Service) *sourceTiers {
+	groups := make(map[int][]service.Service)
+	for _, src := range srcs {
+		tier := cfg.DataSourceTier(src.String())
+		groups[tier] = append(groups[tier], src)
+	}
+
+	st := new(sourceTiers)
+	for num := range groups {
+		st.nums = append(st.nums, num)
+	}
+	sort.Ints(st.nums)
+	for _, num := range st.nums {
+		st.tiers = append(st.tiers, groups[num])
+	}
+	return st
+}
+
+// activeSources returns the data sources in the tiers that have been activated.
+func (st *sourceTiers) activeSources() []service.Service {
+	st.Lock()
+	defer st.Unlock()
+
+	var srcs []service.Service
+	for i := 0; i <= st.active && i < len(st.tiers); i++ {
+		srcs = append(srcs, st.tiers[i]...)
+	}
+	return srcs
+}
+
+// remaining returns true if some tiers have not been activated.
+func (st *sourceTiers) remaining() bool {
+	st.Lock()
+	defer st.Unlock()
+
+	return st.active < len(st.tiers)-1
+}
+
+// next activates the following tier and returns its number and data sources.
+func (st *sourceTiers) next() (int, []service.Service, bool) {
+	st.Lock()
+	defer st.Unlock()
+
+	if st.active >= len(st.tiers)-1 {
+		return 0, nil, false
+	}
+
+	st.active++
+	return st.nums[st.active], st.tiers[st.active], true
+}
+
+// activeSources returns the data sources that currently receive requests from the enumeration.
+// The brute forcing and alterations are held back until their phase when the enumeration is phased.
+func (e *Enumeration) activeSources() []service.Service {
+	srcs := e.srcs
+	if e.tiers != nil {
+		srcs = e.tiers.activeSources()
+	}
+	if e.phases == nil {
+		return srcs
+	}
+
+	var started []service.Service
+	for _, src := range srcs {
+		if e.phases.started(src) {
+			started = append(started, src)
+		}
+	}
+	return started
+}
+
+// activateNextTier sends the root domain names and ASNs already requested from the earlier
+// tiers to the data sources of the next tier. It returns false when all tiers are active.
+func (e *Enumeration) activateNextTier() bool {
+	if e.tiers == nil {
+		return false
+	}
+
+	num, srcs, ok := e.tiers.next()
+	if !ok {
+		return false
+	}
+
+	var names []string
+	for _, src := range srcs {
+		names = append(names, src.String())
+	}
+	e.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Activating the data sources in tier %d: %s", num, strings.Join(names, ", ")))
+
+	for _, domain := range e.nameSrc.zones.Slice() {
+		req := &requests.DNSRequest{
+			Name:   domain,
+			Domain: domain,
+			Tag:    requests.DNS,
+			Source: "DNS",
+		}
+
+		for _, src := range srcs {
+			src.Request(e.ctx, req.Clone().(*requests.DNSRequest))
+		}
+	}
+	for _, asn := range e.Config.ASNs {
+		for _, src := range srcs {
+			src.Request(e.ctx, &requests.ASNRequest{ASN: asn})
+		}
+	}
+	return true
+}
+
+// manageTiers activates the next tier each time the discovery rate falls below the threshold.
+func (e *Enumeration) manageTiers() {
+	threshold := e.Config.TierThreshold
+	if threshold == 0 {
+		threshold = defaultTierThreshold
+	}
+
+	t := time.NewTicker(tierCheckInterval)
+	defer t.Stop()
+
+	last := e.Stats().Accepted
+	for e.tiers.remaining() {
+		select {
+		case <-e.done:
+			return
+		case <-t.C:
+		}
+
+		accepted := e.Stats().Accepted
+		if rate := float64(accepted-last) / tierCheckInterval.Minutes(); rate < threshold {
+			e.activateNextTier()
+		}
+		last = accepted
+	}
+}
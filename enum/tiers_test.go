@@ -0,0 +1,57 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/service"
+)
+
+type tierTestService struct {
+	service.BaseService
+}
+
+func newTierTestService(name string) service.Service {
+	s := new(tierTestService)
+	s.BaseService = *service.NewBaseService(s, name)
+	return s
+}
+
+func TestSourceTiers(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.GetDataSourceConfig("PaidAPI").Tier = 2
+	cfg.GetDataSourceConfig("SlowAPI").Tier = 1
+
+	srcs := []service.Service{
+		newTierTestService("PaidAPI"),
+		newTierTestService("FreeAPI"),
+		newTierTestService("SlowAPI"),
+		newTierTestService("Scraper"),
+	}
+	st := newSourceTiers(cfg, srcs)
+
+	if active := st.activeSources(); len(active) != 2 || !st.remaining() {
+		t.Fatalf("Expected only the two untiered sources to be active, but %d were active", len(active))
+	}
+
+	expected := []string{"SlowAPI", "PaidAPI"}
+	for i, name := range expected {
+		num, next, ok := st.next()
+		if !ok || len(next) != 1 || next[0].String() != name {
+			t.Fatalf("Activation %d did not provide the %s data source", i+1, name)
+		}
+		if num != i+1 {
+			t.Errorf("The %s data source was activated as tier %d", name, num)
+		}
+	}
+
+	if _, _, ok := st.next(); ok || st.remaining() {
+		t.Errorf("A tier remained after all the tiers were activated")
+	}
+	if active := st.activeSources(); len(active) != len(srcs) {
+		t.Errorf("Expected all %d sources to be active, but %d were active", len(srcs), len(active))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/pipeline"
+)
+
+// The longest duration a single web server response is waited for during HTTP verification
+const verifyTimeout = 15 * time.Second
+
+var verifySchemes = []string{"https", "http"}
+
+// verifyBaseline holds the response fingerprints for a name that cannot exist under a subdomain.
+type verifyBaseline struct {
+	ready chan struct{}
+	fps   map[string]*http.Fingerprint
+}
+
+// httpVerifier is the pipeline task that compares the web server responses for the discovered
+// names with the responses for a nonexistent sibling. Names that receive the same response are
+// marked as suspected wildcard or parking hits, and continue through the pipeline unchanged.
+type httpVerifier struct {
+	sync.Mutex
+	enum      *Enumeration
+	baselines map[string]*verifyBaseline
+}
+
+func newHTTPVerifier(e *Enumeration) *httpVerifier {
+	return &httpVerifier{
+		enum:      e,
+		baselines: make(map[string]*verifyBaseline),
+	}
+}
+
+// Process implements the pipeline Task interface.
+func (v *httpVerifier) Process(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) (pipeline.Data, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil
+	default:
+	}
+
+	req, ok := data.(*requests.DNSRequest)
+	if !ok || req == nil || len(req.Records) == 0 {
+		return data, nil
+	}
+
+	name := strings.ToLower(req.Name)
+	domain := strings.ToLower(req.Domain)
+	// Root domain names do not have a sibling within the scope
+	if name == domain || !strings.HasSuffix(name, "."+domain) {
+		return data, nil
+	}
+
+	parent := name[strings.Index(name, ".")+1:]
+	suspect, fp := v.suspected(ctx, name, v.baseline(ctx, parent))
+	if suspect {
+		v.enum.suspects.Insert(name)
+		v.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("HTTP verification: %s receives the response for nonexistent names under %s", name, parent))
+	} else if fp != nil {
+		v.enum.addWebServer(name, fp)
+	}
+	return data, nil
+}
+
+// suspected returns true when the name receives the baseline response for any scheme. Otherwise,
+// the first response received for the name is returned as a signal for the score of the name.
+func (v *httpVerifier) suspected(ctx context.Context, name string, base *verifyBaseline) (bool, *http.Fingerprint) {
+	var first *http.Fingerprint
+
+	for _, scheme := range verifySchemes {
+		fp, err := fingerprint(ctx, scheme, name)
+		if err != nil {
+			continue
+		}
+		if fp.Equal(base.fps[scheme]) {
+			return true, nil
+		}
+		if first == nil {
+			first = fp
+		}
+	}
+	return false, first
+}
+
+// baseline returns the fingerprints for a nonexistent name under the subdomain, which are
+// only requested once for each subdomain.
+func (v *httpVerifier) baseline(ctx context.Context, sub string) *verifyBaseline {
+	v.Lock()
+	base, found := v.baselines[sub]
+	if !found {
+		base = &verifyBaseline{
+			ready: make(chan struct{}),
+			fps:   make(map[string]*http.Fingerprint),
+		}
+		v.baselines[sub] = base
+	}
+	v.Unlock()
+
+	if found {
+		select {
+		case <-ctx.Done():
+			return &verifyBaseline{}
+		case <-base.ready:
+		}
+		return base
+	}
+
+	name := fmt.Sprintf("%x.%s", rand.Int63(), sub)
+	for _, scheme := range verifySchemes {
+		if fp, err := fingerprint(ctx, scheme, name); err == nil {
+			base.fps[scheme] = fp
+		}
+	}
+	close(base.ready)
+	return base
+}
+
+func fingerprint(ctx context.Context, scheme, host string) (*http.Fingerprint, error) {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	return http.ResponseFingerprint(ctx, scheme, host)
+}
+
+// SuspectedWildcard returns true when HTTP verification found that the name receives the same
+// web server response as names that cannot exist, which indicates a DNS wildcard or parked domain.
+func (e *Enumeration) SuspectedWildcard(name string) bool {
+	return e.suspects.Has(strings.ToLower(name))
+}
+
+// ResolvedAuthoritatively returns true when the answers for the name were provided by an
+// authoritative server of the zone, such as when the queries are sent to the zone's own servers.
+func (e *Enumeration) ResolvedAuthoritatively(name string) bool {
+	return e.authNames.Has(strings.ToLower(name))
+}
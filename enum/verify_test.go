@@ -0,0 +1,42 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/net/http"
+)
+
+func TestHTTPVerifierSuspected(t *testing.T) {
+	ts := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		fmt.Fprint(w, "<html><head><title>Parked</title></head></html>")
+	}))
+	defer ts.Close()
+
+	host := strings.TrimPrefix(ts.URL, "http://")
+	parked, err := http.ResponseFingerprint(context.Background(), "http", host)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint the response: %v", err)
+	}
+
+	v := newHTTPVerifier(nil)
+	base := &verifyBaseline{fps: map[string]*http.Fingerprint{"http": parked}}
+	if suspect, _ := v.suspected(context.Background(), host, base); !suspect {
+		t.Errorf("the name receiving the baseline response was not suspected")
+	}
+
+	base.fps["http"] = &http.Fingerprint{StatusCode: nethttp.StatusNotFound, Title: "Not Found"}
+	if suspect, fp := v.suspected(context.Background(), host, base); suspect || !fp.Equal(parked) {
+		t.Errorf("the name receiving a distinct response was suspected or the response was not returned")
+	}
+	if suspect, _ := v.suspected(context.Background(), host, &verifyBaseline{}); suspect {
+		t.Errorf("the name was suspected without a baseline response")
+	}
+}
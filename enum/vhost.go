@@ -0,0 +1,94 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The port that virtual host brute forcing sends the candidate names to
+	vhostPort = 443
+	// The number of TLS handshakes performed concurrently against a single address
+	maxVHostQueries = 5
+)
+
+// vhostBrute sends names from the wordlist as the TLS SNI to the in-scope address, and
+// records the names that cause the server to present a distinct certificate valid for the name.
+// This discovers virtual hosts that are not found in public DNS.
+func (a *activeTask) vhostBrute(ctx context.Context, req *requests.AddrRequest, tp pipeline.TaskParams) {
+	domain := a.enum.Config.WhichDomain(req.Domain)
+	if domain == "" {
+		return
+	}
+	// The certificate presented for a name that cannot exist identifies the default virtual host
+	def, err := http.PeerCertificate(ctx, req.Address, vhostPort, fmt.Sprintf("%x.%s", rand.Int63(), domain))
+	if err != nil {
+		return
+	}
+
+	sem := make(chan struct{}, maxVHostQueries)
+	var wg sync.WaitGroup
+loop:
+	for _, word := range a.enum.Config.WordlistFor(domain) {
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer func() { <-sem; wg.Done() }()
+
+			if vhostHit(ctx, req.Address, vhostPort, name, def) {
+				a.vhostFound(ctx, req, name, domain, tp)
+			}
+		}(strings.ToLower(strings.TrimSpace(word)) + "." + domain)
+	}
+	wg.Wait()
+}
+
+// vhostHit returns true if the server presents a certificate for the name that differs from the default.
+func vhostHit(ctx context.Context, addr string, port int, name string, def *x509.Certificate) bool {
+	cert, err := http.PeerCertificate(ctx, addr, port, name)
+	if err != nil || bytes.Equal(cert.Raw, def.Raw) {
+		return false
+	}
+	return cert.VerifyHostname(name) == nil
+}
+
+// vhostFound enters the virtual host into the pipeline with a record for the address, since
+// the name is unlikely to resolve.
+func (a *activeTask) vhostFound(ctx context.Context, req *requests.AddrRequest, name, domain string, tp pipeline.TaskParams) {
+	qtype := dns.TypeA
+	if amassnet.IsIPv6(net.ParseIP(req.Address)) {
+		qtype = dns.TypeAAAA
+	}
+
+	pipeline.SendData(ctx, "filter", &requests.DNSRequest{
+		Name:   name,
+		Domain: domain,
+		Records: []requests.DNSAnswer{{
+			Name: name,
+			Type: int(qtype),
+			Data: req.Address,
+		}},
+		Tag:    requests.CERT,
+		Source: "Active VHost",
+	}, tp)
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/net/http"
+)
+
+func testCertificate(t *testing.T, name string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestVHostHit(t *testing.T) {
+	def := testCertificate(t, "default.owasp.org")
+	vhost := testCertificate(t, "admin.owasp.org")
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName == "admin.owasp.org" {
+				return &vhost, nil
+			}
+			return &def, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start the TLS listener: %v", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				_ = c.(*tls.Conn).Handshake()
+			}(conn)
+		}
+	}()
+
+	ctx := context.Background()
+	port := ln.Addr().(*net.TCPAddr).Port
+	defCert, err := http.PeerCertificate(ctx, "127.0.0.1", port, "nonexistent.owasp.org")
+	if err != nil {
+		t.Fatalf("Failed to obtain the default certificate: %v", err)
+	}
+
+	if !vhostHit(ctx, "127.0.0.1", port, "admin.owasp.org", defCert) {
+		t.Errorf("The virtual host with a distinct certificate was not found")
+	}
+	if vhostHit(ctx, "127.0.0.1", port, "www.owasp.org", defCert) {
+		t.Errorf("The name served the default certificate and was reported as a virtual host")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/resolve"
+)
+
+// The number of names dropped as DNS wildcards that are held for a later invalidation
+const maxHeldWildcardNames = 10000
+
+// InvalidateWildcard discards the cached DNS wildcard verdicts for the zone and the
+// subdomains within it, then releases the names previously dropped as wildcards in the
+// zone back into the pipeline. The released names bypass the filter of names already seen.
+func (e *Enumeration) InvalidateWildcard(zone string) {
+	zone = strings.ToLower(resolve.RemoveLastDot(strings.TrimSpace(zone)))
+	if zone == "" {
+		return
+	}
+
+	if e.Sys != nil {
+		if wi, ok := e.Sys.Pool().(systems.WildcardInvalidator); ok {
+			wi.InvalidateWildcard(zone)
+		}
+	}
+	src := e.source()
+	if src == nil {
+		return
+	}
+
+	for _, req := range src.releaseWildcardNames(zone) {
+		src.appendData(req, requests.TrustedSource(e.Config, req.Tag, req.Source))
+	}
+}
+
+func (r *enumSource) holdWildcardName(req *requests.DNSRequest) {
+	r.heldLock.Lock()
+	defer r.heldLock.Unlock()
+
+	if len(r.held) >= maxHeldWildcardNames {
+		return
+	}
+
+	name := strings.ToLower(req.Name)
+	r.held[name] = &requests.DNSRequest{
+		Name:   name,
+		Domain: req.Domain,
+		Tag:    req.Tag,
+		Source: req.Source,
+	}
+}
+
+func (r *enumSource) releaseWildcardNames(zone string) []*requests.DNSRequest {
+	r.heldLock.Lock()
+	defer r.heldLock.Unlock()
+
+	var released []*requests.DNSRequest
+	for name, req := range r.held {
+		if name == zone || strings.HasSuffix(name, "."+zone) {
+			released = append(released, req)
+			delete(r.held, name)
+		}
+	}
+	return released
+}
@@ -16,51 +16,88 @@ import (
 	"github.com/miekg/dns"
 )
 
+// The longest duration a zone transfer is waited for, when the timeout has not been configured
+const defaultZoneTransferTimeout = 25 * time.Second
+
+// The port that zone transfers are requested from
+var zoneTransferPort = "53"
+
+// ZoneTransferResult identifies a nameserver that permitted a zone transfer, which is itself
+// a finding worth reporting.
+type ZoneTransferResult struct {
+	Zone    string
+	Server  string
+	Address string
+	// The type of transfer permitted, AXFR or IXFR
+	Type string
+	// The number of names returned by the transfer
+	Names int
+	// Set when the transfer ended before the complete zone was received
+	Partial bool
+}
+
 // ZoneTransfer attempts a DNS zone transfer using the provided server.
 // The returned slice contains all the records discovered from the zone transfer.
 func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
+	results, _, err := transferZone(context.Background(), sub, domain, server, dns.TypeAXFR, defaultZoneTransferTimeout)
+	return results, err
+}
+
+// transferZone attempts the AXFR or IXFR of the zone using the server address provided, and
+// returns the records received before the transfer completed or failed. The boolean is true
+// when the server stopped sending the zone part way through the transfer.
+func transferZone(ctx context.Context, sub, domain, server string, qtype uint16, timeout time.Duration) ([]*requests.DNSRequest, bool, error) {
 	var results []*requests.DNSRequest
 
-	// Set the maximum time allowed for making the connection
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// The timeout applies to the entire exchange with the server
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	addr := net.JoinHostPort(server, "53")
+	addr := net.JoinHostPort(server, zoneTransferPort)
 	conn, err := amassnet.DialContext(ctx, "tcp", addr)
 	if err != nil {
-		return results, fmt.Errorf("zone xfr error: Failed to obtain TCP connection to [%s]: %v", addr, err)
+		return results, false, fmt.Errorf("zone xfr error: Failed to obtain TCP connection to [%s]: %v", addr, err)
 	}
 	defer conn.Close()
 
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
 	xfr := &dns.Transfer{
 		Conn:        &dns.Conn{Conn: conn},
-		ReadTimeout: 15 * time.Second,
+		ReadTimeout: timeout,
 	}
 
 	m := &dns.Msg{}
-	m.SetAxfr(dns.Fqdn(sub))
+	if qtype == dns.TypeIXFR {
+		// A serial of zero requests all the changes made to the zone
+		m.SetIxfr(dns.Fqdn(sub), 0, ".", ".")
+	} else {
+		m.SetAxfr(dns.Fqdn(sub))
+	}
 
 	in, err := xfr.In(m, "")
 	if err != nil {
-		return results, fmt.Errorf("DNS zone transfer error for [%s]: %v", addr, err)
+		return results, false, fmt.Errorf("DNS zone transfer error for [%s]: %v", addr, err)
 	}
 
+	var xfrErr error
 	for en := range in {
-		reqs := getXfrRequests(en, domain)
-		if reqs == nil {
+		if en.Error != nil {
+			xfrErr = en.Error
 			continue
 		}
 
-		results = append(results, reqs...)
+		results = append(results, getXfrRequests(en, domain)...)
+	}
+	if xfrErr != nil && len(results) == 0 {
+		return results, false, fmt.Errorf("DNS zone transfer error for [%s]: %v", addr, xfrErr)
 	}
-	return results, nil
+	return results, xfrErr != nil, nil
 }
 
 func getXfrRequests(en *dns.Envelope, domain string) []*requests.DNSRequest {
-	if en.Error != nil {
-		return nil
-	}
-
 	reqs := make(map[string]*requests.DNSRequest)
 	for _, a := range en.RR {
 		var record requests.DNSAnswer
@@ -139,3 +176,18 @@ func realName(hdr dns.RR_Header) string {
 
 	return resolve.RemoveLastDot(pieces[len(pieces)-1])
 }
+
+func (e *Enumeration) addZoneTransfer(result *ZoneTransferResult) {
+	e.xfrLock.Lock()
+	defer e.xfrLock.Unlock()
+
+	e.xfrs = append(e.xfrs, result)
+}
+
+// ZoneTransfers returns the nameservers that permitted a zone transfer during the enumeration.
+func (e *Enumeration) ZoneTransfers() []*ZoneTransferResult {
+	e.xfrLock.Lock()
+	defer e.xfrLock.Unlock()
+
+	return append([]*ZoneTransferResult(nil), e.xfrs...)
+}
@@ -4,9 +4,16 @@
 package enum
 
 import (
+	"context"
 	"flag"
+	"net"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
 )
 
 const TestDomain string = "owasp-amass.com"
@@ -59,3 +66,63 @@ func TestZoneTransfer(t *testing.T) {
 		}
 	}
 }
+
+func TestTransferZoneTypes(t *testing.T) {
+	zone := "owasp.org."
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		// Only the incremental transfer is permitted by this server
+		if req.Question[0].Qtype != dns.TypeIXFR {
+			m := new(dns.Msg)
+			m.SetRcode(req, dns.RcodeRefused)
+			_ = w.WriteMsg(m)
+			return
+		}
+
+		soa, _ := dns.NewRR(zone + " 3600 IN SOA ns1.owasp.org. admin.owasp.org. 1 3600 600 86400 300")
+		a, _ := dns.NewRR("www." + zone + " 3600 IN A 192.0.2.1")
+		cname, _ := dns.NewRR("vpn." + zone + " 3600 IN CNAME www.owasp.org.")
+
+		ch := make(chan *dns.Envelope, 1)
+		tr := new(dns.Transfer)
+		go func() {
+			ch <- &dns.Envelope{RR: []dns.RR{soa, a, cname, soa}}
+			close(ch)
+		}()
+		_ = tr.Out(w, req, ch)
+		w.Hijack()
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := &dns.Server{Listener: lis, Handler: handler}
+	go func() { _ = srv.ActivateAndServe() }()
+	defer func() { _ = srv.Shutdown() }()
+
+	host, port, _ := net.SplitHostPort(lis.Addr().String())
+	saved := zoneTransferPort
+	zoneTransferPort = port
+	defer func() { zoneTransferPort = saved }()
+
+	if _, _, err := transferZone(context.Background(), "owasp.org", "owasp.org", host, dns.TypeAXFR, 5*time.Second); err == nil {
+		t.Errorf("the refused AXFR did not return an error")
+	}
+
+	reqs, partial, err := transferZone(context.Background(), "owasp.org", "owasp.org", host, dns.TypeIXFR, 5*time.Second)
+	if err != nil || partial {
+		t.Fatalf("the IXFR failed with partial %t: %v", partial, err)
+	}
+
+	names := stringset.New()
+	defer names.Close()
+	for _, req := range reqs {
+		if req.Tag != requests.AXFR {
+			t.Errorf("%s had the %s tag", req.Name, req.Tag)
+		}
+		names.Insert(req.Name)
+	}
+	if !names.Has("www.owasp.org") || !names.Has("vpn.owasp.org") {
+		t.Errorf("the IXFR returned the names %v", names.Slice())
+	}
+}
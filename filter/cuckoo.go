@@ -0,0 +1,210 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package filter
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	boom "github.com/tylertreat/BoomFilters"
+)
+
+const minEvictInterval = time.Second
+
+// cuckooEntry records when an element was inserted, identified by its fingerprint,
+// so the elements themselves are not kept in memory.
+type cuckooEntry struct {
+	Fingerprint uint64
+	Added       time.Time
+}
+
+// cuckooFilter is a Filter backed by a cuckoo filter, which supports the deletion of
+// elements. Entries are evicted once they become older than the TTL, and the oldest
+// entries are evicted early when the filter reaches capacity.
+type cuckooFilter struct {
+	sync.Mutex
+	filter  *boom.CuckooFilter
+	ttl     time.Duration
+	entries *list.List
+	index   map[uint64]*list.Element
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewCuckooFilter returns a Filter backed by a cuckoo filter optimized to store num
+// elements with the fpRate false-positive rate. A ttl greater than zero causes
+// elements to be removed from the filter after that duration has elapsed.
+func NewCuckooFilter(num uint, fpRate float64, ttl time.Duration) Filter {
+	c := &cuckooFilter{
+		filter:  boom.NewCuckooFilter(num, fpRate),
+		ttl:     ttl,
+		entries: list.New(),
+		index:   make(map[uint64]*list.Element),
+		done:    make(chan struct{}),
+	}
+
+	if ttl > 0 {
+		go c.manageEvictions()
+	}
+	return c
+}
+
+// Insert implements the Filter interface.
+func (c *cuckooFilter) Insert(element string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.add(&cuckooEntry{
+		Fingerprint: fingerprint(element),
+		Added:       time.Now(),
+	})
+}
+
+// Has implements the Filter interface.
+func (c *cuckooFilter) Has(element string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.filter.Test(fingerprintKey(fingerprint(element)))
+}
+
+// Delete implements the Deleter interface.
+func (c *cuckooFilter) Delete(element string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	e, found := c.index[fingerprint(element)]
+	if !found {
+		return false
+	}
+	return c.remove(e)
+}
+
+// Close implements the Filter interface.
+func (c *cuckooFilter) Close() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.filter.Reset()
+	c.entries.Init()
+	c.index = make(map[uint64]*list.Element)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (c *cuckooFilter) MarshalBinary() ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	entries := make([]*cuckooEntry, 0, c.entries.Len())
+	for e := c.entries.Front(); e != nil; e = e.Next() {
+		entries = append(entries, e.Value.(*cuckooEntry))
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entries); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// Elements that have exceeded the TTL are not restored.
+func (c *cuckooFilter) UnmarshalBinary(data []byte) error {
+	var entries []*cuckooEntry
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entries); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	for _, e := range entries {
+		if c.ttl > 0 && now.Sub(e.Added) >= c.ttl {
+			continue
+		}
+		if c.filter.Count() >= c.filter.Capacity() {
+			break
+		}
+		c.add(e)
+	}
+	return nil
+}
+
+// The caller must hold the lock.
+func (c *cuckooFilter) add(entry *cuckooEntry) {
+	if _, found := c.index[entry.Fingerprint]; found {
+		return
+	}
+	// Make room by releasing the oldest entries, rather than permitting
+	// the filter to silently drop a fingerprint
+	for c.filter.Count() >= c.filter.Capacity() && c.entries.Len() > 0 {
+		c.remove(c.entries.Front())
+	}
+
+	if err := c.filter.Add(fingerprintKey(entry.Fingerprint)); err != nil {
+		return
+	}
+	c.index[entry.Fingerprint] = c.entries.PushBack(entry)
+}
+
+// The caller must hold the lock.
+func (c *cuckooFilter) remove(e *list.Element) bool {
+	entry := c.entries.Remove(e).(*cuckooEntry)
+
+	delete(c.index, entry.Fingerprint)
+	return c.filter.TestAndRemove(fingerprintKey(entry.Fingerprint))
+}
+
+func (c *cuckooFilter) evictExpired(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for e := c.entries.Front(); e != nil && now.Sub(e.Value.(*cuckooEntry).Added) >= c.ttl; e = c.entries.Front() {
+		c.remove(e)
+	}
+}
+
+func (c *cuckooFilter) manageEvictions() {
+	interval := c.ttl / 4
+	if interval < minEvictInterval {
+		interval = minEvictInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-t.C:
+			c.evictExpired(now)
+		}
+	}
+}
+
+func fingerprint(element string) uint64 {
+	h := fnv.New64a()
+
+	_, _ = h.Write([]byte(element))
+	return h.Sum64()
+}
+
+func fingerprintKey(fp uint64) []byte {
+	key := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(key, fp)
+	return key
+}
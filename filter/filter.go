@@ -0,0 +1,121 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package filter
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"sync"
+
+	"github.com/caffix/stringset"
+	boom "github.com/tylertreat/BoomFilters"
+)
+
+// Filter is the object type for performing string filtering.
+type Filter interface {
+	// Insert adds the element string argument to the filter.
+	Insert(element string)
+
+	// Has returns true if the filter already contains the element string argument.
+	Has(element string) bool
+
+	// Close releases resources allocated by the filter.
+	Close()
+
+	// MarshalBinary and UnmarshalBinary save and restore the filter state.
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Deleter is implemented by filters that support the removal of elements.
+type Deleter interface {
+	// Delete removes the element string argument from the filter and
+	// returns true if the element was a member.
+	Delete(element string) bool
+}
+
+// stringFilter is a Filter that tracks every element exactly.
+type stringFilter struct {
+	*stringset.Set
+}
+
+// NewStringFilter returns a Filter that tracks every element exactly.
+func NewStringFilter() Filter {
+	return &stringFilter{Set: stringset.New()}
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s *stringFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(s.Slice()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *stringFilter) UnmarshalBinary(data []byte) error {
+	var elements []string
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&elements); err != nil {
+		return err
+	}
+
+	s.InsertMany(elements...)
+	return nil
+}
+
+// bloomFilter is a Filter backed by a classic Bloom filter of fixed capacity.
+type bloomFilter struct {
+	sync.Mutex
+	filter *boom.BloomFilter
+}
+
+// NewBloomFilter returns a Filter backed by a Bloom filter optimized to store
+// num elements with the fpRate false-positive rate.
+func NewBloomFilter(num uint, fpRate float64) Filter {
+	return &bloomFilter{filter: boom.NewBloomFilter(num, fpRate)}
+}
+
+// Insert implements the Filter interface.
+func (b *bloomFilter) Insert(element string) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.filter.Add([]byte(element))
+}
+
+// Has implements the Filter interface.
+func (b *bloomFilter) Has(element string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.filter.Test([]byte(element))
+}
+
+// Close implements the Filter interface.
+func (b *bloomFilter) Close() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.filter.Reset()
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (b *bloomFilter) MarshalBinary() ([]byte, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.filter.GobEncode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (b *bloomFilter) UnmarshalBinary(data []byte) error {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.filter.GobDecode(data)
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterImplementations(t *testing.T) {
+	filters := map[string]Filter{
+		"string": NewStringFilter(),
+		"bloom":  NewBloomFilter(1000, 0.001),
+		"cuckoo": NewCuckooFilter(1000, 0.001, 0),
+	}
+
+	for name, f := range filters {
+		if f.Has("www.owasp.org") {
+			t.Errorf("%s filter reported an element before it was inserted", name)
+		}
+
+		f.Insert("www.owasp.org")
+		if !f.Has("www.owasp.org") {
+			t.Errorf("%s filter did not report the inserted element", name)
+		}
+
+		f.Close()
+	}
+}
+
+func TestCuckooFilterDelete(t *testing.T) {
+	f := NewCuckooFilter(1000, 0.001, 0)
+	defer f.Close()
+
+	d, ok := f.(Deleter)
+	if !ok {
+		t.Fatal("The cuckoo filter does not implement the Deleter interface")
+	}
+
+	f.Insert("www.owasp.org")
+	if !d.Delete("www.owasp.org") {
+		t.Errorf("Delete did not report the element as a member")
+	}
+	if f.Has("www.owasp.org") {
+		t.Errorf("The element was still present after being deleted")
+	}
+	if d.Delete("www.owasp.org") {
+		t.Errorf("Delete reported success for an element no longer in the filter")
+	}
+	if c := f.(*cuckooFilter); c.entries.Len() != 0 || len(c.index) != 0 {
+		t.Errorf("The entry was kept after the element was deleted")
+	}
+}
+
+func TestCuckooFilterTTL(t *testing.T) {
+	f := NewCuckooFilter(1000, 0.001, time.Minute)
+	defer f.Close()
+
+	c := f.(*cuckooFilter)
+	c.Insert("old.owasp.org")
+	c.entries.Front().Value.(*cuckooEntry).Added = time.Now().Add(-2 * time.Minute)
+	c.Insert("new.owasp.org")
+
+	c.evictExpired(time.Now())
+	if c.Has("old.owasp.org") {
+		t.Errorf("The expired element was not evicted")
+	}
+	if !c.Has("new.owasp.org") {
+		t.Errorf("The element within the TTL was evicted")
+	}
+}
+
+func TestCuckooFilterCapacity(t *testing.T) {
+	f := NewCuckooFilter(8, 0.001, 0)
+	defer f.Close()
+
+	c := f.(*cuckooFilter)
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+	for _, n := range names {
+		c.Insert(n + ".owasp.org")
+	}
+
+	if uint(len(c.index)) > c.filter.Capacity() {
+		t.Errorf("The filter holds %d elements, which exceeds the capacity", len(c.index))
+	}
+	if c.entries.Len() != len(c.index) {
+		t.Errorf("The filter holds %d entries for %d elements", c.entries.Len(), len(c.index))
+	}
+	if last := names[len(names)-1] + ".owasp.org"; !c.Has(last) {
+		t.Errorf("The most recent element was evicted")
+	}
+}
+
+func TestFilterMarshalBinary(t *testing.T) {
+	filters := map[string]func() Filter{
+		"string": func() Filter { return NewStringFilter() },
+		"bloom":  func() Filter { return NewBloomFilter(1000, 0.001) },
+		"cuckoo": func() Filter { return NewCuckooFilter(1000, 0.001, time.Hour) },
+	}
+
+	for name, create := range filters {
+		f := create()
+		f.Insert("www.owasp.org")
+
+		data, err := f.MarshalBinary()
+		f.Close()
+		if err != nil {
+			t.Errorf("%s filter failed to marshal the state: %v", name, err)
+			continue
+		}
+
+		restored := create()
+		if err := restored.UnmarshalBinary(data); err != nil {
+			t.Errorf("%s filter failed to unmarshal the state: %v", name, err)
+		} else if !restored.Has("www.owasp.org") {
+			t.Errorf("%s filter did not restore the inserted element", name)
+		}
+		restored.Close()
+	}
+}
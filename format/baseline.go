@@ -0,0 +1,160 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/stringset"
+)
+
+// Baseline contains the names and addresses reported by a previous enumeration.
+type Baseline struct {
+	names *stringset.Set
+	addrs *stringset.Set
+}
+
+// NewBaseline returns an empty Baseline.
+func NewBaseline() *Baseline {
+	return &Baseline{
+		names: stringset.New(),
+		addrs: stringset.New(),
+	}
+}
+
+// LoadBaseline reads the names and addresses from a previous enumeration output file.
+// The JSON, JSON Lines and text output formats are accepted.
+func LoadBaseline(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := NewBaseline()
+	if err := b.Read(f); err != nil {
+		return nil, fmt.Errorf("failed to read the baseline %s: %v", path, err)
+	}
+	return b, nil
+}
+
+// Read adds the names and addresses found in the enumeration output to the baseline.
+func (b *Baseline) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "{") {
+			if err := b.readJSON(line); err != nil {
+				return err
+			}
+			continue
+		}
+		b.readText(line)
+	}
+	return scanner.Err()
+}
+
+func (b *Baseline) readJSON(line string) error {
+	var entry struct {
+		Name      string                 `json:"name"`
+		Address   string                 `json:"address"`
+		Addresses []requests.AddressInfo `json:"addresses"`
+	}
+
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return err
+	}
+
+	b.addName(entry.Name)
+	b.addAddr(entry.Address)
+	for _, addr := range entry.Addresses {
+		b.addAddr(addr.Address.String())
+	}
+	return nil
+}
+
+// readText parses a line of the text output, in the form "[source] name addr,addr".
+func (b *Baseline) readText(line string) {
+	if strings.HasPrefix(line, "[") {
+		if i := strings.Index(line, "]"); i != -1 {
+			line = line[i+1:]
+		}
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+
+	b.addName(fields[0])
+	if len(fields) > 1 {
+		for _, addr := range strings.Split(fields[1], ",") {
+			b.addAddr(addr)
+		}
+	}
+}
+
+func (b *Baseline) addName(name string) {
+	if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+		b.names.Insert(name)
+	}
+}
+
+func (b *Baseline) addAddr(addr string) {
+	if ip := net.ParseIP(strings.TrimSpace(addr)); ip != nil {
+		b.addrs.Insert(ip.String())
+	}
+}
+
+// Len returns the number of names and addresses in the baseline.
+func (b *Baseline) Len() int {
+	return b.names.Len() + b.addrs.Len()
+}
+
+// NewOutput returns true if the output contains a name or address that is not in the baseline.
+// A nil Baseline considers all output to be new.
+func (b *Baseline) NewOutput(out *requests.Output) bool {
+	if b == nil || !b.names.Has(strings.ToLower(out.Name)) {
+		return true
+	}
+
+	for _, addr := range out.Addresses {
+		if addr.Address != nil && !b.addrs.Has(addr.Address.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewData returns true if the DNSRequest or AddrRequest provided is not in the baseline.
+// A nil Baseline considers all data to be new.
+func (b *Baseline) NewData(data pipeline.Data) bool {
+	if b == nil {
+		return true
+	}
+
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		return !b.names.Has(strings.ToLower(v.Name))
+	case *requests.AddrRequest:
+		if ip := net.ParseIP(v.Address); ip != nil {
+			return !b.addrs.Has(ip.String())
+		}
+	}
+	return true
+}
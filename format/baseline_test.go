@@ -0,0 +1,59 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestBaseline(t *testing.T) {
+	data := `{"name":"www.owasp.org","domain":"owasp.org","addresses":[{"ip":"192.168.1.1","cidr":"192.168.1.0/24"}]}
+{"type":"address","address":"192.168.1.2","domain":"owasp.org","tag":"dns","source":"DNS"}
+[Crtsh] mail.owasp.org 192.168.1.3,192.168.1.4
+ftp.owasp.org
+`
+
+	b := NewBaseline()
+	if err := b.Read(strings.NewReader(data)); err != nil {
+		t.Fatalf("Failed to read the baseline: %v", err)
+	}
+	if b.Len() != 7 {
+		t.Errorf("Expected 7 names and addresses in the baseline, but read %d", b.Len())
+	}
+
+	known := &requests.Output{
+		Name:      "www.owasp.org",
+		Addresses: []requests.AddressInfo{{Address: net.ParseIP("192.168.1.1")}},
+	}
+	if b.NewOutput(known) {
+		t.Errorf("The output already in the baseline was considered new")
+	}
+
+	known.Addresses = append(known.Addresses, requests.AddressInfo{Address: net.ParseIP("192.168.1.5")})
+	if !b.NewOutput(known) {
+		t.Errorf("The output with a new address was not considered new")
+	}
+	if !b.NewOutput(&requests.Output{Name: "dev.owasp.org"}) {
+		t.Errorf("The output with a new name was not considered new")
+	}
+
+	if b.NewData(&requests.DNSRequest{Name: "mail.owasp.org"}) {
+		t.Errorf("The name already in the baseline was considered new")
+	}
+	if b.NewData(&requests.AddrRequest{Address: "192.168.1.4"}) {
+		t.Errorf("The address already in the baseline was considered new")
+	}
+	if !b.NewData(&requests.DNSRequest{Name: "dev.owasp.org"}) {
+		t.Errorf("The new name was not considered new")
+	}
+
+	var none *Baseline
+	if !none.NewOutput(known) || !none.NewData(&requests.DNSRequest{Name: "ftp.owasp.org"}) {
+		t.Errorf("A nil baseline did not consider all output to be new")
+	}
+}
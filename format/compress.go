@@ -0,0 +1,97 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"compress/gzip"
+	"os"
+	"strings"
+	"sync"
+)
+
+// OutputFile is a file receiving results that compresses the data written to it with gzip when
+// requested. Flush writes the data compressed so far, so the file can be read while the enumeration
+// continues, and Close completes the gzip stream, so the file is not truncated.
+type OutputFile struct {
+	sync.Mutex
+	f      *os.File
+	gz     *gzip.Writer
+	closed bool
+}
+
+// CompressedPath returns true when the path ends in the .gz extension.
+func CompressedPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".gz")
+}
+
+// CreateOutputFile creates or truncates the file at the path. The data written is compressed
+// with gzip when compress is true or the path ends in the .gz extension.
+func CreateOutputFile(path string, compress bool) (*OutputFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewOutputFile(f, compress || CompressedPath(path)), nil
+}
+
+// NewOutputFile returns an OutputFile that writes to the file provided, such as os.Stdout.
+func NewOutputFile(f *os.File, compress bool) *OutputFile {
+	o := &OutputFile{f: f}
+
+	if compress {
+		o.gz = gzip.NewWriter(f)
+	}
+	return o
+}
+
+// Write implements the io.Writer interface.
+func (o *OutputFile) Write(p []byte) (int, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.closed {
+		return 0, os.ErrClosed
+	}
+	if o.gz != nil {
+		return o.gz.Write(p)
+	}
+	return o.f.Write(p)
+}
+
+// Flush writes the compressed data buffered so far and commits the file to stable storage.
+func (o *OutputFile) Flush() error {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.closed {
+		return os.ErrClosed
+	}
+	if o.gz != nil {
+		if err := o.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return o.f.Sync()
+}
+
+// Close completes the gzip stream and closes the file. Calling Close again has no effect.
+func (o *OutputFile) Close() error {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+
+	var err error
+	if o.gz != nil {
+		err = o.gz.Close()
+	}
+	_ = o.f.Sync()
+	if cerr := o.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bufio"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestCompressedOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "amass.jsonl.gz")
+
+	f, err := CreateOutputFile(path, false)
+	if err != nil {
+		t.Fatalf("Failed to create the output file: %v", err)
+	}
+
+	w := NewJSONLinesWriter(f)
+	for i := 0; i < 10; i++ {
+		if err := w.WriteData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS}); err != nil {
+			t.Fatalf("Failed to write the line: %v", err)
+		}
+	}
+
+	// The lines flushed so far can be read before the stream is completed
+	if count := countGzipLines(t, path, false); count != 10 {
+		t.Errorf("%d flushed lines were read before the file was closed", count)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close the output file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("Closing the output file again returned an error: %v", err)
+	}
+	if _, err := f.Write([]byte("late\n")); err == nil {
+		t.Error("The write after the file was closed did not fail")
+	}
+	if count := countGzipLines(t, path, true); count != 10 {
+		t.Errorf("%d lines were read from the completed gzip stream", count)
+	}
+}
+
+func countGzipLines(t *testing.T, path string, complete bool) int {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the compressed file: %v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("The file was not compressed with gzip: %v", err)
+	}
+
+	var count int
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		count++
+	}
+	if err := scanner.Err(); complete && err != nil {
+		t.Errorf("The gzip stream was not completed: %v", err)
+	}
+	return count
+}
@@ -0,0 +1,19 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+)
+
+// WithinLabelDepth returns true when the DNSRequest provided is within the max label depth of the
+// configuration, so it is written to the outputs. The other data, such as addresses, is always written.
+func WithinLabelDepth(cfg *config.Config, data pipeline.Data) bool {
+	if req, ok := data.(*requests.DNSRequest); ok {
+		return cfg.WithinLabelDepth(req.Name)
+	}
+	return true
+}
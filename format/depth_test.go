@@ -0,0 +1,27 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestWithinLabelDepth(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.MaxLabelDepth = 1
+
+	if !WithinLabelDepth(cfg, &requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org"}) {
+		t.Error("The direct subdomain was not within the max label depth")
+	}
+	if WithinLabelDepth(cfg, &requests.DNSRequest{Name: "dev.www.owasp.org", Domain: "owasp.org"}) {
+		t.Error("The nested subdomain was within the max label depth")
+	}
+	if !WithinLabelDepth(cfg, &requests.AddrRequest{Address: "192.168.1.1", Domain: "owasp.org"}) {
+		t.Error("The address was filtered by the max label depth")
+	}
+}
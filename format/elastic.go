@@ -0,0 +1,239 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+	"github.com/caffix/pipeline"
+)
+
+const (
+	// The number of documents buffered before new documents are dropped
+	elasticQueueSize = 10000
+	// The maximum number of documents sent in a single bulk request
+	elasticBatchSize = 500
+	// The longest duration a document waits before the batch is sent
+	elasticFlushInterval = 5 * time.Second
+	// The number of times a bulk request is attempted before the batch is dropped
+	elasticAttempts = 3
+	// The time allowed for each bulk request to complete
+	elasticTimeout = 30 * time.Second
+)
+
+// ElasticWriter indexes enumeration results into Elasticsearch using the bulk API.
+// Documents are dropped, and counted, when Elasticsearch cannot keep up with the enumeration.
+type ElasticWriter struct {
+	cfg       *config.ElasticSearch
+	client    *http.Client
+	docs      chan *JSONLine
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+	dropped   uint64
+}
+
+// NewElasticWriter returns an ElasticWriter that sends results to the index in the configuration,
+// through the client when one is provided, such as the client sending the requests through a proxy.
+func NewElasticWriter(cfg *config.ElasticSearch, client *http.Client) *ElasticWriter {
+	if client == nil {
+		client = amasshttp.DefaultClient
+	}
+
+	w := &ElasticWriter{
+		cfg:    cfg,
+		client: client,
+		docs:   make(chan *JSONLine, elasticQueueSize),
+		done:   make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.processDocuments()
+	return w
+}
+
+// WriteData queues the DNSRequest or AddrRequest provided for indexing without blocking.
+// Other data types are ignored.
+func (w *ElasticWriter) WriteData(data pipeline.Data) error {
+	doc := NewJSONLine(data)
+	if doc == nil {
+		return nil
+	}
+
+	select {
+	case <-w.done:
+		return fmt.Errorf("the Elasticsearch writer has been closed")
+	default:
+	}
+
+	select {
+	case w.docs <- doc:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped returns the number of documents that were not indexed.
+func (w *ElasticWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close sends the documents remaining in the queue and stops the writer.
+func (w *ElasticWriter) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+	})
+}
+
+func (w *ElasticWriter) processDocuments() {
+	defer w.wg.Done()
+
+	t := time.NewTicker(elasticFlushInterval)
+	defer t.Stop()
+
+	batch := make([]*JSONLine, 0, elasticBatchSize)
+	for {
+		select {
+		case <-w.done:
+			// Send the documents remaining in the queue before returning
+			for len(w.docs) > 0 {
+				batch = append(batch, <-w.docs)
+				if len(batch) >= elasticBatchSize {
+					w.sendBatch(batch)
+					batch = batch[:0]
+				}
+			}
+			if len(batch) > 0 {
+				w.sendBatch(batch)
+			}
+			return
+		case doc := <-w.docs:
+			batch = append(batch, doc)
+			if len(batch) < elasticBatchSize {
+				continue
+			}
+		case <-t.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		w.sendBatch(batch)
+		batch = batch[:0]
+	}
+}
+
+func (w *ElasticWriter) sendBatch(batch []*JSONLine) {
+	body, err := w.bulkBody(batch)
+	if err != nil {
+		atomic.AddUint64(&w.dropped, uint64(len(batch)))
+		return
+	}
+
+	backoff := time.Second
+	for i := 0; i < elasticAttempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		failed, err := w.bulkRequest(body)
+		if err == nil {
+			atomic.AddUint64(&w.dropped, uint64(failed))
+			return
+		}
+	}
+	atomic.AddUint64(&w.dropped, uint64(len(batch)))
+}
+
+// bulkBody returns the newline delimited JSON expected by the bulk API for the batch.
+func (w *ElasticWriter) bulkBody(batch []*JSONLine) ([]byte, error) {
+	action, err := json.Marshal(map[string]interface{}{
+		"index": map[string]string{"_index": w.cfg.Index},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range batch {
+		buf.Write(action)
+		buf.WriteByte('\n')
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// bulkRequest sends the body to the bulk API and returns the number of documents that failed to be indexed.
+// An error is returned when the request should be attempted again.
+func (w *ElasticWriter) bulkRequest(body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), elasticTimeout)
+	defer cancel()
+
+	u := strings.TrimSuffix(w.cfg.URL, "/") + "/_bulk"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+w.cfg.APIKey)
+	} else if w.cfg.Username != "" {
+		req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Elasticsearch is overloaded or unavailable
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return 0, fmt.Errorf("the bulk request returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return strings.Count(string(body), "\n") / 2, nil
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || !result.Errors {
+		return 0, nil
+	}
+
+	var failed int
+	for _, item := range result.Items {
+		for _, action := range item {
+			if action.Status < 200 || action.Status >= 300 {
+				failed++
+			}
+		}
+	}
+	return failed, nil
+}
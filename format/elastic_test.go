@@ -0,0 +1,81 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestElasticWriter(t *testing.T) {
+	var lock sync.Mutex
+	var indexed int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("The bulk request was sent to %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "elastic" || pass != "secret" {
+			t.Errorf("The bulk request did not provide the credentials")
+		}
+
+		var lines int
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			if !json.Valid(scanner.Bytes()) {
+				t.Errorf("The bulk request contained an invalid line: %q", scanner.Text())
+			}
+			lines++
+		}
+
+		lock.Lock()
+		indexed += lines / 2
+		lock.Unlock()
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer srv.Close()
+
+	w := NewElasticWriter(&config.ElasticSearch{
+		URL:      srv.URL,
+		Index:    config.DefaultElasticIndex,
+		Username: "elastic",
+		Password: "secret",
+	}, nil)
+	for i := 0; i < 1200; i++ {
+		_ = w.WriteData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS})
+	}
+	w.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if total := uint64(indexed) + w.Dropped(); total != 1200 {
+		t.Errorf("Expected 1200 documents to be indexed or dropped, but %d were accounted for", total)
+	}
+	if w.Dropped() != 0 {
+		t.Errorf("%d documents were dropped", w.Dropped())
+	}
+}
+
+func TestElasticWriterItemErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":429}}]}`))
+	}))
+	defer srv.Close()
+
+	w := NewElasticWriter(&config.ElasticSearch{URL: srv.URL, Index: config.DefaultElasticIndex}, nil)
+	_ = w.WriteData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS})
+	_ = w.WriteData(&requests.AddrRequest{Address: "192.168.1.1", Domain: "owasp.org", Tag: requests.DNS})
+	w.Close()
+
+	if w.Dropped() != 1 {
+		t.Errorf("Expected the failed item to be counted as dropped, but %d were dropped", w.Dropped())
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+)
+
+// JSONLine is the object written for each result by a JSONLinesWriter.
+type JSONLine struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	// The Unicode form of an internationalized name
+	UnicodeName string               `json:"unicode_name,omitempty"`
+	Address     string               `json:"address,omitempty"`
+	Domain      string               `json:"domain"`
+	Records     []requests.DNSAnswer `json:"records,omitempty"`
+	Tag         string               `json:"tag"`
+	Source      string               `json:"source"`
+	Timestamp   time.Time            `json:"timestamp"`
+	// The discovery steps that derived the name
+	Provenance []requests.DiscoveryStep `json:"provenance,omitempty"`
+}
+
+// JSONLinesWriter writes enumeration results as newline delimited JSON objects.
+type JSONLinesWriter struct {
+	sync.Mutex
+	out io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLinesWriter returns a JSONLinesWriter that writes results to out.
+func NewJSONLinesWriter(out io.Writer) *JSONLinesWriter {
+	return &JSONLinesWriter{
+		out: out,
+		enc: json.NewEncoder(out),
+	}
+}
+
+// WriteData writes the DNSRequest or AddrRequest provided as a single line and flushes the writer.
+// Other data types are ignored.
+func (w *JSONLinesWriter) WriteData(data pipeline.Data) error {
+	line := NewJSONLine(data)
+	if line == nil {
+		return nil
+	}
+	return w.writeLine(line)
+}
+
+// WriteUnresolved writes the name that does not exist as a single line with the unresolved type.
+func (w *JSONLinesWriter) WriteUnresolved(req *requests.DNSRequest) error {
+	line := NewJSONLine(req)
+	if line == nil {
+		return nil
+	}
+
+	line.Type = "unresolved"
+	return w.writeLine(line)
+}
+
+func (w *JSONLinesWriter) writeLine(line *JSONLine) error {
+	// Serialize the writers so that lines are not interleaved
+	w.Lock()
+	defer w.Unlock()
+
+	if err := w.enc.Encode(line); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+// NewJSONLine returns the JSONLine for the DNSRequest or AddrRequest provided.
+// Nil is returned for other data types.
+func NewJSONLine(data pipeline.Data) *JSONLine {
+	var line *JSONLine
+
+	switch v := data.(type) {
+	case *requests.DNSRequest:
+		line = &JSONLine{
+			Type:       "name",
+			Name:       v.Name,
+			Domain:     v.Domain,
+			Records:    v.Records,
+			Tag:        v.Tag,
+			Source:     v.Source,
+			Provenance: v.Provenance,
+		}
+		if u := amassdns.ToUnicode(v.Name); u != v.Name {
+			line.UnicodeName = u
+		}
+	case *requests.AddrRequest:
+		line = &JSONLine{
+			Type:    "address",
+			Address: v.Address,
+			Domain:  v.Domain,
+			Tag:     v.Tag,
+			Source:  v.Source,
+		}
+	default:
+		return nil
+	}
+
+	line.Timestamp = time.Now()
+	return line
+}
+
+func (w *JSONLinesWriter) flush() error {
+	switch f := w.out.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Sync() error }:
+		return f.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestJSONLinesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLinesWriter(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = w.WriteData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = w.WriteData(&requests.AddrRequest{Address: "192.168.1.1", Domain: "owasp.org", Tag: requests.DNS})
+		}()
+	}
+	wg.Wait()
+
+	var count int
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line JSONLine
+
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Failed to unmarshal the line %q: %v", scanner.Text(), err)
+		}
+		if line.Type != "name" && line.Type != "address" {
+			t.Errorf("The line has an unexpected type: %s", line.Type)
+		}
+		count++
+	}
+	if count != 100 {
+		t.Errorf("Expected 100 lines, but %d were written", count)
+	}
+}
+
+func TestNewJSONLineUnicodeName(t *testing.T) {
+	line := NewJSONLine(&requests.DNSRequest{Name: "www.xn--bcher-kva.de", Domain: "xn--bcher-kva.de"})
+	if line.Name != "www.xn--bcher-kva.de" || line.UnicodeName != "www.bücher.de" {
+		t.Errorf("The line did not provide both forms of the name: %s and %s", line.Name, line.UnicodeName)
+	}
+
+	if line = NewJSONLine(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org"}); line.UnicodeName != "" {
+		t.Errorf("The line provided a Unicode form of an ASCII name: %s", line.UnicodeName)
+	}
+}
+
+func TestJSONLinesWriterUnresolved(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLinesWriter(&buf)
+
+	if err := w.WriteUnresolved(&requests.DNSRequest{Name: "dev.owasp.org", Domain: "owasp.org", Tag: requests.BRUTE}); err != nil {
+		t.Fatalf("Failed to write the unresolved name: %v", err)
+	}
+
+	var line JSONLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Failed to unmarshal the line %q: %v", buf.String(), err)
+	}
+	if line.Type != "unresolved" || line.Name != "dev.owasp.org" || line.Tag != requests.BRUTE {
+		t.Errorf("The unresolved name was not written with the unresolved type: %+v", line)
+	}
+}
@@ -219,6 +219,11 @@ func OutputLineParts(out *requests.Output, src, addrs, demo bool) (source, name,
 	name = out.Name
 	if demo {
 		name = censorDomain(name)
+	} else if out.UnicodeName != "" {
+		name += " (" + out.UnicodeName + ")"
+	}
+	if out.Suspect {
+		name += " (suspected wildcard)"
 	}
 	return
 }
@@ -0,0 +1,66 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/caffix/stringset"
+)
+
+// LoadOutputNames reads the names from the JSON output of a previous enumeration. The files written
+// by the -json and -json-stream flags are accepted, and the names are returned in the order found.
+func LoadOutputNames(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := ReadOutputNames(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the names from %s: %v", path, err)
+	}
+	return names, nil
+}
+
+// ReadOutputNames returns the names found in the JSON or JSON Lines enumeration output, without duplicates.
+// The objects describing addresses are skipped.
+func ReadOutputNames(r io.Reader) ([]string, error) {
+	filter := stringset.New()
+	defer filter.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, err
+		}
+		if entry.Type != "" && entry.Type != "name" {
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimSpace(entry.Name))
+		if name != "" && !filter.Has(name) {
+			filter.Insert(name)
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
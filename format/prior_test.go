@@ -0,0 +1,30 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOutputNames(t *testing.T) {
+	output := `{"name":"www.owasp.org","domain":"owasp.org","addresses":[{"ip":"192.168.1.1"}],"tag":"dns","sources":["DNS"]}
+{"type":"name","name":"API.owasp.org","domain":"owasp.org","tag":"cert","source":"Crtsh"}
+{"type":"address","address":"192.168.1.2","domain":"owasp.org","tag":"dns","source":"DNS"}
+
+{"name":"www.owasp.org","domain":"owasp.org","addresses":[],"tag":"api","sources":["Shodan"]}
+`
+
+	names, err := ReadOutputNames(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Failed to read the output: %v", err)
+	}
+	if len(names) != 2 || names[0] != "www.owasp.org" || names[1] != "api.owasp.org" {
+		t.Errorf("The names were read as %v", names)
+	}
+
+	if _, err := ReadOutputNames(strings.NewReader("www.owasp.org 192.168.1.1\n")); err == nil {
+		t.Error("The text output was accepted as JSON output")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"github.com/OWASP/Amass/v3/config"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/pipeline"
+)
+
+// RedactOutput replaces the labels of the names in the Output that match the redact patterns
+// of the configuration. The Output is modified in place.
+func RedactOutput(cfg *config.Config, out *requests.Output) {
+	if !cfg.Redacting() {
+		return
+	}
+
+	if name := cfg.RedactName(out.Name); name != out.Name {
+		out.Name = name
+		out.UnicodeName = ""
+		if u := amassdns.ToUnicode(name); u != name {
+			out.UnicodeName = u
+		}
+	}
+	redactProvenance(cfg, out.Provenance)
+}
+
+// RedactData returns a copy of the DNSRequest or AddrRequest provided with the labels of the names
+// that match the redact patterns of the configuration replaced. The data is returned unchanged
+// when nothing is redacted, so the results shared with other outputs are never modified.
+func RedactData(cfg *config.Config, data pipeline.Data) pipeline.Data {
+	if !cfg.Redacting() {
+		return data
+	}
+
+	req, ok := data.(*requests.DNSRequest)
+	if !ok {
+		return data
+	}
+
+	c := req.Clone().(*requests.DNSRequest)
+	c.Name = cfg.RedactName(c.Name)
+	for i := range c.Records {
+		c.Records[i].Name = cfg.RedactName(c.Records[i].Name)
+		c.Records[i].Data = cfg.RedactName(c.Records[i].Data)
+	}
+	redactProvenance(cfg, c.Provenance)
+	return c
+}
+
+func redactProvenance(cfg *config.Config, steps []requests.DiscoveryStep) {
+	for i := range steps {
+		if steps[i].From != "" {
+			steps[i].From = cfg.RedactName(steps[i].From)
+		}
+	}
+}
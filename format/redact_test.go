@@ -0,0 +1,47 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestRedactData(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.RedactPatterns = []string{"*-int"}
+
+	req := &requests.DNSRequest{
+		Name:    "db-int.owasp.org",
+		Domain:  "owasp.org",
+		Records: []requests.DNSAnswer{{Name: "db-int.owasp.org", Type: 5, Data: "www.owasp.org"}},
+		Provenance: []requests.DiscoveryStep{
+			{Tag: requests.CERT, Source: "Crtsh"},
+			{Tag: requests.ALT, Source: "Alterations", From: "app-int.owasp.org"},
+		},
+	}
+
+	red, ok := RedactData(cfg, req).(*requests.DNSRequest)
+	if !ok || red == req {
+		t.Fatal("The redacted data was not a copy of the request")
+	}
+	if red.Name != "redacted.owasp.org" || red.Records[0].Name != "redacted.owasp.org" || red.Records[0].Data != "www.owasp.org" {
+		t.Errorf("The names of the request were redacted as %s and %v", red.Name, red.Records)
+	}
+	if red.Provenance[1].From != "redacted.owasp.org" {
+		t.Errorf("The provenance of the request was redacted as %v", red.Provenance)
+	}
+	if req.Name != "db-int.owasp.org" || req.Records[0].Name != "db-int.owasp.org" || req.Provenance[1].From != "app-int.owasp.org" {
+		t.Error("The request shared with the other outputs was modified")
+	}
+
+	out := &requests.Output{Name: "db-int.owasp.org", Domain: "owasp.org"}
+	RedactOutput(cfg, out)
+	if out.Name != "redacted.owasp.org" {
+		t.Errorf("The output was redacted as %s", out.Name)
+	}
+}
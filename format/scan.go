@@ -0,0 +1,238 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+)
+
+// The scanner output formats accepted by LoadScanImport.
+const (
+	ScanFormatNmap    = "nmap"
+	ScanFormatMasscan = "masscan"
+)
+
+// ScanHost is a live host found in the output of a port scanner.
+type ScanHost struct {
+	Address string
+	// The names that the scanner found for the host, such as the reverse DNS name
+	Names []string
+	// The open TCP ports found on the host
+	Ports []int
+}
+
+// HasWebPort returns true when port 80 or 443 is open on the host.
+func (h *ScanHost) HasWebPort() bool {
+	for _, port := range h.Ports {
+		if port == 80 || port == 443 {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadScanImport reads the hosts from the scanner output identified by the spec, which
+// is the format followed by the path, such as nmap:scan.xml or masscan:scan.json.
+func LoadScanImport(spec string) ([]*ScanHost, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("%s must provide the scan format and path, such as nmap:scan.xml", spec)
+	}
+
+	kind := strings.ToLower(strings.TrimSpace(parts[0]))
+	if kind != ScanFormatNmap && kind != ScanFormatMasscan {
+		return nil, fmt.Errorf("%s is not a supported scan format", kind)
+	}
+
+	f, err := os.Open(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hosts []*ScanHost
+	if kind == ScanFormatNmap {
+		hosts, err = ReadNmapXML(f)
+	} else {
+		hosts, err = ReadMasscanJSON(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the %s scan %s: %v", kind, parts[1], err)
+	}
+	return hosts, nil
+}
+
+type nmapRun struct {
+	Hosts []struct {
+		Status struct {
+			State string `xml:"state,attr"`
+		} `xml:"status"`
+		Addresses []struct {
+			Addr     string `xml:"addr,attr"`
+			AddrType string `xml:"addrtype,attr"`
+		} `xml:"address"`
+		Hostnames []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"hostnames>hostname"`
+		Ports []struct {
+			Protocol string `xml:"protocol,attr"`
+			PortID   int    `xml:"portid,attr"`
+			State    struct {
+				State string `xml:"state,attr"`
+			} `xml:"state"`
+		} `xml:"ports>port"`
+	} `xml:"host"`
+}
+
+// ReadNmapXML returns the hosts that are up in the Nmap XML output.
+func ReadNmapXML(r io.Reader) ([]*ScanHost, error) {
+	var run nmapRun
+	if err := xml.NewDecoder(r).Decode(&run); err != nil {
+		return nil, err
+	}
+
+	hosts := newScanHosts()
+	for _, h := range run.Hosts {
+		if h.Status.State != "" && h.Status.State != "up" {
+			continue
+		}
+
+		for _, a := range h.Addresses {
+			if a.AddrType != "ipv4" && a.AddrType != "ipv6" {
+				continue
+			}
+
+			host := hosts.get(a.Addr)
+			if host == nil {
+				continue
+			}
+			for _, n := range h.Hostnames {
+				host.addName(n.Name)
+			}
+			for _, p := range h.Ports {
+				if p.Protocol == "tcp" && p.State.State == "open" {
+					host.addPort(p.PortID)
+				}
+			}
+		}
+	}
+	return hosts.list, nil
+}
+
+type masscanHost struct {
+	IP    string `json:"ip"`
+	Ports []struct {
+		Port   int    `json:"port"`
+		Proto  string `json:"proto"`
+		Status string `json:"status"`
+	} `json:"ports"`
+}
+
+// ReadMasscanJSON returns the hosts in the masscan JSON output. The objects are also read one
+// per line, since masscan leaves a trailing comma in the array when it is interrupted.
+func ReadMasscanJSON(r io.Reader) ([]*ScanHost, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*masscanHost
+	if err := json.Unmarshal(data, &results); err != nil {
+		results = nil
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ",")
+			if line == "" || line == "[" || line == "]" {
+				continue
+			}
+
+			var m masscanHost
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				return nil, err
+			}
+			results = append(results, &m)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts := newScanHosts()
+	for _, m := range results {
+		host := hosts.get(m.IP)
+		if host == nil {
+			continue
+		}
+		for _, p := range m.Ports {
+			if (p.Proto == "" || p.Proto == "tcp") && (p.Status == "" || p.Status == "open") {
+				host.addPort(p.Port)
+			}
+		}
+	}
+	return hosts.list, nil
+}
+
+// scanHosts merges the entries for the same address, keeping the order they were found in.
+type scanHosts struct {
+	hosts map[string]*ScanHost
+	list  []*ScanHost
+}
+
+func newScanHosts() *scanHosts {
+	return &scanHosts{hosts: make(map[string]*ScanHost)}
+}
+
+func (s *scanHosts) get(addr string) *ScanHost {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return nil
+	}
+
+	addr = ip.String()
+	host, found := s.hosts[addr]
+	if !found {
+		host = &ScanHost{Address: addr}
+		s.hosts[addr] = host
+		s.list = append(s.list, host)
+	}
+	return host
+}
+
+func (h *ScanHost) addName(name string) {
+	name = strings.Trim(strings.ToLower(strings.TrimSpace(name)), ".")
+	if name == "" {
+		return
+	}
+
+	for _, n := range h.Names {
+		if n == name {
+			return
+		}
+	}
+	h.Names = append(h.Names, name)
+}
+
+func (h *ScanHost) addPort(port int) {
+	if port <= 0 {
+		return
+	}
+
+	for _, p := range h.Ports {
+		if p == port {
+			return
+		}
+	}
+	h.Ports = append(h.Ports, port)
+}
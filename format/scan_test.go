@@ -0,0 +1,73 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadNmapXML(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<nmaprun scanner="nmap">
+<host><status state="up"/>
+<address addr="192.0.2.1" addrtype="ipv4"/><address addr="00:11:22:33:44:55" addrtype="mac"/>
+<hostnames><hostname name="www.owasp.org" type="PTR"/></hostnames>
+<ports>
+<port protocol="tcp" portid="443"><state state="open"/></port>
+<port protocol="tcp" portid="22"><state state="closed"/></port>
+<port protocol="udp" portid="53"><state state="open"/></port>
+</ports></host>
+<host><status state="down"/><address addr="192.0.2.2" addrtype="ipv4"/></host>
+<host><status state="up"/><address addr="2001:db8::1" addrtype="ipv6"/>
+<ports><port protocol="tcp" portid="22"><state state="open"/></port></ports></host>
+</nmaprun>`
+
+	hosts, err := ReadNmapXML(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read the Nmap XML: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("%d hosts were read instead of 2", len(hosts))
+	}
+
+	h := hosts[0]
+	if h.Address != "192.0.2.1" || len(h.Names) != 1 || h.Names[0] != "www.owasp.org" || len(h.Ports) != 1 || !h.HasWebPort() {
+		t.Errorf("the first host was not read correctly: %+v", h)
+	}
+	if hosts[1].Address != "2001:db8::1" || hosts[1].HasWebPort() {
+		t.Errorf("the second host was not read correctly: %+v", hosts[1])
+	}
+}
+
+func TestReadMasscanJSON(t *testing.T) {
+	// The array left unterminated by an interrupted scan
+	data := `[
+{   "ip": "192.0.2.1",   "timestamp": "1616000000", "ports": [ {"port": 80, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] },
+{   "ip": "192.0.2.2",   "timestamp": "1616000000", "ports": [ {"port": 22, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] },
+{   "ip": "192.0.2.1",   "timestamp": "1616000001", "ports": [ {"port": 443, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 54} ] },
+`
+
+	hosts, err := ReadMasscanJSON(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read the masscan JSON: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("%d hosts were read instead of 2", len(hosts))
+	}
+	if h := hosts[0]; h.Address != "192.0.2.1" || len(h.Ports) != 2 || !h.HasWebPort() {
+		t.Errorf("the entries for the first host were not merged: %+v", h)
+	}
+	if hosts[1].HasWebPort() {
+		t.Errorf("the second host reported a web port: %+v", hosts[1])
+	}
+}
+
+func TestLoadScanImportSpec(t *testing.T) {
+	for _, spec := range []string{"scan.xml", "nessus:scan.xml", "nmap:"} {
+		if _, err := LoadScanImport(spec); err == nil {
+			t.Errorf("the import %s did not return an error", spec)
+		}
+	}
+}
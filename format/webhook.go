@@ -0,0 +1,202 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+	"github.com/caffix/pipeline"
+)
+
+const (
+	// The number of results buffered before new results are dropped
+	webhookQueueSize = 1000
+	// The maximum number of results sent in a single request
+	webhookBatchSize = 50
+	// The longest duration a result waits before the batch is sent
+	webhookFlushInterval = 2 * time.Second
+	// The number of times a request is attempted before the batch is dropped
+	webhookAttempts = 4
+	// The time allowed for each request to complete
+	webhookTimeout = 15 * time.Second
+)
+
+// WebhookPayload is the JSON object posted to the webhook for each batch of results.
+type WebhookPayload struct {
+	Results []*JSONLine `json:"results"`
+}
+
+// WebhookWriter posts the enumeration results to a webhook in batches as they are discovered.
+// Results are dropped, and counted, when the webhook cannot keep up with the enumeration.
+type WebhookWriter struct {
+	url       string
+	headers   map[string]string
+	client    *http.Client
+	results   chan *JSONLine
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+	backoff   time.Duration
+	dropped   uint64
+}
+
+// NewWebhookWriter returns a WebhookWriter that posts results to the URL with the headers provided,
+// through the client when one is provided, such as the client sending the requests through a proxy.
+func NewWebhookWriter(url string, headers map[string]string, client *http.Client) *WebhookWriter {
+	if client == nil {
+		client = amasshttp.DefaultClient
+	}
+
+	w := &WebhookWriter{
+		url:     url,
+		headers: headers,
+		client:  client,
+		results: make(chan *JSONLine, webhookQueueSize),
+		done:    make(chan struct{}),
+		backoff: time.Second,
+	}
+
+	w.wg.Add(1)
+	go w.processResults()
+	return w
+}
+
+// WriteData queues the DNSRequest or AddrRequest provided for the webhook without blocking.
+// Other data types are ignored.
+func (w *WebhookWriter) WriteData(data pipeline.Data) error {
+	result := NewJSONLine(data)
+	if result == nil {
+		return nil
+	}
+
+	select {
+	case <-w.done:
+		return fmt.Errorf("the webhook writer has been closed")
+	default:
+	}
+
+	select {
+	case w.results <- result:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped returns the number of results that were not delivered to the webhook.
+func (w *WebhookWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close sends the results remaining in the queue and stops the writer.
+func (w *WebhookWriter) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+	})
+}
+
+func (w *WebhookWriter) processResults() {
+	defer w.wg.Done()
+
+	t := time.NewTicker(webhookFlushInterval)
+	defer t.Stop()
+
+	batch := make([]*JSONLine, 0, webhookBatchSize)
+	for {
+		select {
+		case <-w.done:
+			// Send the results remaining in the queue before returning
+			for len(w.results) > 0 {
+				batch = append(batch, <-w.results)
+				if len(batch) >= webhookBatchSize {
+					w.sendBatch(batch)
+					batch = batch[:0]
+				}
+			}
+			if len(batch) > 0 {
+				w.sendBatch(batch)
+			}
+			return
+		case result := <-w.results:
+			batch = append(batch, result)
+			if len(batch) < webhookBatchSize {
+				continue
+			}
+		case <-t.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		w.sendBatch(batch)
+		batch = batch[:0]
+	}
+}
+
+func (w *WebhookWriter) sendBatch(batch []*JSONLine) {
+	body, err := json.Marshal(&WebhookPayload{Results: batch})
+	if err != nil {
+		atomic.AddUint64(&w.dropped, uint64(len(batch)))
+		return
+	}
+
+	backoff := w.backoff
+	for i := 0; i < webhookAttempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		retry, err := w.post(body)
+		if err == nil {
+			return
+		}
+		if !retry {
+			break
+		}
+	}
+	atomic.AddUint64(&w.dropped, uint64(len(batch)))
+}
+
+// post sends the body to the webhook. The boolean reports whether a failed request
+// should be attempted again, which is the case for network errors and overloaded endpoints.
+func (w *WebhookWriter) post(body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("the webhook request returned status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
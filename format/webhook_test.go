@@ -0,0 +1,98 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestWebhookWriter(t *testing.T) {
+	var lock sync.Mutex
+	var received int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("The webhook request used the %s method", r.Method)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("The webhook request did not provide the configured header")
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("The webhook request has the content type %s", r.Header.Get("Content-Type"))
+		}
+
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("The webhook request contained invalid JSON: %v", err)
+		}
+		if len(payload.Results) > webhookBatchSize {
+			t.Errorf("The webhook request contained %d results", len(payload.Results))
+		}
+
+		lock.Lock()
+		received += len(payload.Results)
+		lock.Unlock()
+	}))
+	defer srv.Close()
+
+	w := NewWebhookWriter(srv.URL, map[string]string{"Authorization": "Bearer secret"}, nil)
+	for i := 0; i < 500; i++ {
+		_ = w.WriteData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS})
+	}
+	_ = w.WriteData(&requests.AddrRequest{Address: "192.168.1.1", Domain: "owasp.org", Tag: requests.DNS})
+	w.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if total := uint64(received) + w.Dropped(); total != 501 {
+		t.Errorf("Expected 501 results to be posted or dropped, but %d were accounted for", total)
+	}
+	if w.Dropped() != 0 {
+		t.Errorf("%d results were dropped", w.Dropped())
+	}
+	if err := w.WriteData(&requests.DNSRequest{Name: "owasp.org", Domain: "owasp.org"}); err == nil {
+		t.Errorf("WriteData did not return an error after the writer was closed")
+	}
+}
+
+func TestWebhookWriterRetries(t *testing.T) {
+	var lock sync.Mutex
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		attempts++
+		n := attempts
+		lock.Unlock()
+
+		if n == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	w := NewWebhookWriter(srv.URL, nil, nil)
+	w.backoff = 10 * time.Millisecond
+	_ = w.WriteData(&requests.DNSRequest{Name: "www.owasp.org", Domain: "owasp.org", Tag: requests.DNS})
+	w.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	// The rate limited request is attempted again, but the rejected request is not
+	if attempts != 2 {
+		t.Errorf("Expected the webhook request to be attempted twice, but it was attempted %d times", attempts)
+	}
+	if w.Dropped() != 1 {
+		t.Errorf("Expected the rejected result to be counted as dropped, but %d were dropped", w.Dropped())
+	}
+}
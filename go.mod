@@ -12,6 +12,7 @@ require (
 	github.com/caffix/resolve v0.0.0-20211103235133-2517e4ff9a2a
 	github.com/caffix/service v0.0.0-20210920205156-38bde8eb0503
 	github.com/caffix/stringset v0.0.0-20211127022128-4574bae71adb
+	github.com/cayleygraph/cayley v0.7.7-0.20210618132536-7ef662d4c347
 	github.com/cayleygraph/quad v1.2.4
 	github.com/chromedp/cdproto v0.0.0-20211025030258-2570df970243 // indirect
 	github.com/cjoudrey/gluaurl v0.0.0-20161028222611-31cbb9bef199
@@ -27,9 +28,12 @@ require (
 	github.com/miekg/dns v1.1.43
 	github.com/stretchr/testify v1.7.0 // indirect
 	github.com/temoto/robotstxt v1.1.2 // indirect
+	github.com/tylertreat/BoomFilters v0.0.0-20210315201527-1a82519a3e43
 	github.com/yl2chen/cidranger v1.0.2
 	github.com/yuin/gopher-lua v0.0.0-20210529063254-f4c35e4016d9
 	golang.org/x/net v0.0.0-20211123203042-d83791d6bcd9
 	golang.org/x/oauth2 v0.0.0-20211028175245-ba495a64dcb5
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
+	google.golang.org/grpc v1.42.0
 	layeh.com/gopher-json v0.0.0-20201124131017-552bb3c4c3bf
 )
@@ -82,6 +82,7 @@ func (c *Collection) HostedDomains(ctx context.Context) error {
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithCancel(ctx)
 	ctx = context.WithValue(ctx, requests.ContextConfig, c.Config)
+	ctx = c.Config.ProxyContext(ctx)
 	ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
 	c.ctx = ctx
 	defer cancel()
@@ -256,6 +257,7 @@ func (c *Collection) ReverseWhois() error {
 
 	// Setup the context used throughout the collection
 	ctx := context.WithValue(context.Background(), requests.ContextConfig, c.Config)
+	ctx = c.Config.ProxyContext(ctx)
 	c.ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
 
 	// Send the whois requests to the data sources
@@ -265,6 +267,12 @@ func (c *Collection) ReverseWhois() error {
 		}
 	}
 
+	c.waitForWhois()
+	return nil
+}
+
+// waitForWhois closes the output channel once the data sources stop returning whois information.
+func (c *Collection) waitForWhois() {
 	last := time.Now()
 	t := time.NewTicker(2 * time.Second)
 	defer t.Stop()
@@ -284,7 +292,6 @@ loop:
 		}
 	}
 	close(c.Output)
-	return nil
 }
 
 func (c *Collection) collect(req *requests.WhoisRequest) {
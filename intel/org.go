@@ -0,0 +1,297 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package intel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	rdapBaseURL = "https://rdap.arin.net/registry/"
+	rdapSource  = "ARIN RDAP"
+	cacheSource = "ASN Cache"
+	// The largest range of autonomous system numbers accepted from a single RDAP autnum object
+	maxAutnumRange = 64
+)
+
+// OrgCandidate is an organization that matched the name searched for, along with the
+// root domain names, autonomous systems and netblocks registered to the organization.
+type OrgCandidate struct {
+	// The RDAP handle of the organization, or the first ASN for the AS description matches
+	ID        string
+	Name      string
+	Source    string
+	Domains   []string
+	ASNs      []int
+	Netblocks []string
+}
+
+// OrganizationCandidates returns the organizations with a name that matches the org parameter,
+// found using the ARIN RDAP entity search and the AS descriptions in the ASN cache.
+func (c *Collection) OrganizationCandidates(ctx context.Context, org string) ([]*OrgCandidate, error) {
+	org = strings.TrimSpace(org)
+	if org == "" {
+		return nil, errors.New("the organization name was not provided")
+	}
+
+	cands := c.rdapEntitySearch(ctx, org)
+	byName := make(map[string]*OrgCandidate)
+	for _, entry := range c.Sys.Cache().DescriptionSearch(org) {
+		key := strings.ToLower(entry.Description)
+
+		cand, found := byName[key]
+		if !found {
+			cand = &OrgCandidate{
+				ID:     "AS" + strconv.Itoa(entry.ASN),
+				Name:   entry.Description,
+				Source: cacheSource,
+			}
+			byName[key] = cand
+			cands = append(cands, cand)
+		}
+		cand.ASNs = append(cand.ASNs, entry.ASN)
+		cand.Netblocks = append(cand.Netblocks, entry.Netblocks...)
+	}
+	return cands, nil
+}
+
+// SelectOrgCandidate returns the candidate with the provided ID, or the only candidate when the
+// ID is empty. An error is returned when the ID is empty and the candidates are ambiguous.
+func SelectOrgCandidate(cands []*OrgCandidate, id string) (*OrgCandidate, error) {
+	if id = strings.TrimSpace(id); id != "" {
+		for _, cand := range cands {
+			if strings.EqualFold(cand.ID, id) {
+				return cand, nil
+			}
+		}
+		return nil, fmt.Errorf("no organization matched the ID %s", id)
+	}
+
+	switch len(cands) {
+	case 0:
+		return nil, errors.New("no organizations matched the name")
+	case 1:
+		return cands[0], nil
+	}
+	return nil, fmt.Errorf("%d organizations matched the name", len(cands))
+}
+
+// OrganizationScope obtains the root domain names, autonomous systems and netblocks registered to
+// the organization, and adds them to the scope of the configuration, so they can seed an enumeration.
+func (c *Collection) OrganizationScope(ctx context.Context, cand *OrgCandidate) {
+	if cand.Source == rdapSource {
+		c.rdapEntityDetails(ctx, cand)
+	}
+
+	for _, asn := range cand.ASNs {
+		req := c.Sys.Cache().ASNSearch(asn)
+		if req == nil {
+			systems.PopulateCache(ctx, asn, c.Sys)
+			req = c.Sys.Cache().ASNSearch(asn)
+		}
+		if req != nil {
+			cand.Netblocks = append(cand.Netblocks, req.Netblocks...)
+		}
+	}
+	cand.Netblocks = uniqueStrings(cand.Netblocks)
+	cand.Domains = uniqueStrings(cand.Domains)
+
+	c.Config.ASNs = append(c.Config.ASNs, cand.ASNs...)
+	for _, cidr := range cand.Netblocks {
+		if _, ipnet, err := net.ParseCIDR(cidr); err == nil {
+			c.Config.CIDRs = append(c.Config.CIDRs, ipnet)
+		}
+	}
+	c.Config.AddDomains(cand.Domains...)
+}
+
+// OrganizationDomains returns the root domain names registered to the organization, which are
+// the domains found by OrganizationScope and the domains returned by reverse whois on the name.
+func (c *Collection) OrganizationDomains(cand *OrgCandidate) error {
+	if err := c.Config.CheckSettings(); err != nil {
+		return err
+	}
+
+	c.Bus.Subscribe(requests.NewWhoisTopic, c.collect)
+	defer c.Bus.Unsubscribe(requests.NewWhoisTopic, c.collect)
+
+	// Setup the context used throughout the collection
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, c.Config)
+	ctx = c.Config.ProxyContext(ctx)
+	c.ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
+
+	c.collect(&requests.WhoisRequest{
+		Company:    cand.Name,
+		NewDomains: cand.Domains,
+		Tag:        requests.RIR,
+		Source:     cand.Source,
+	})
+	// Send the reverse whois requests for the organization name to the data sources
+	for _, src := range c.srcs {
+		src.Request(c.ctx, &requests.WhoisRequest{Company: cand.Name})
+	}
+
+	c.waitForWhois()
+	return nil
+}
+
+type rdapEntity struct {
+	Handle   string        `json:"handle"`
+	Vcard    []interface{} `json:"vcardArray"`
+	Entities []*rdapEntity `json:"entities"`
+	Networks []struct {
+		Cidrs []struct {
+			V4Prefix string `json:"v4prefix"`
+			V6Prefix string `json:"v6prefix"`
+			Length   int    `json:"length"`
+		} `json:"cidr0_cidrs"`
+	} `json:"networks"`
+	Autnums []struct {
+		Start int `json:"startAutnum"`
+		End   int `json:"endAutnum"`
+	} `json:"autnums"`
+}
+
+func (c *Collection) rdapEntitySearch(ctx context.Context, org string) []*OrgCandidate {
+	u := rdapBaseURL + "entities?fn=" + url.QueryEscape(org+"*")
+
+	var m struct {
+		Results []*rdapEntity `json:"entitySearchResults"`
+	}
+	if !c.rdapQuery(ctx, u, &m) {
+		return nil
+	}
+
+	var cands []*OrgCandidate
+	for _, entity := range m.Results {
+		if entity.Handle == "" {
+			continue
+		}
+
+		name := entity.Handle
+		if names := vcardValues(entity.Vcard, "fn"); len(names) > 0 {
+			name = names[0]
+		}
+		cands = append(cands, &OrgCandidate{
+			ID:     entity.Handle,
+			Name:   name,
+			Source: rdapSource,
+		})
+	}
+
+	sort.Slice(cands, func(i, j int) bool {
+		return cands[i].Name < cands[j].Name
+	})
+	return cands
+}
+
+func (c *Collection) rdapEntityDetails(ctx context.Context, cand *OrgCandidate) {
+	var entity rdapEntity
+	if !c.rdapQuery(ctx, rdapBaseURL+"entity/"+url.PathEscape(cand.ID), &entity) {
+		return
+	}
+
+	for _, n := range entity.Networks {
+		for _, cidr := range n.Cidrs {
+			prefix := cidr.V4Prefix
+			if prefix == "" {
+				prefix = cidr.V6Prefix
+			}
+			if prefix != "" {
+				cand.Netblocks = append(cand.Netblocks, prefix+"/"+strconv.Itoa(cidr.Length))
+			}
+		}
+	}
+
+	for _, a := range entity.Autnums {
+		end := a.End
+		if end < a.Start || end-a.Start >= maxAutnumRange {
+			end = a.Start
+		}
+		for asn := a.Start; asn > 0 && asn <= end; asn++ {
+			cand.ASNs = append(cand.ASNs, asn)
+		}
+	}
+
+	// The contacts of the organization are expected to use its own domain names
+	for _, e := range append([]*rdapEntity{&entity}, entity.Entities...) {
+		for _, email := range vcardValues(e.Vcard, "email") {
+			parts := strings.Split(strings.ToLower(strings.TrimSpace(email)), "@")
+			if len(parts) != 2 {
+				continue
+			}
+			if d, err := publicsuffix.EffectiveTLDPlusOne(parts[1]); err == nil {
+				cand.Domains = append(cand.Domains, d)
+			}
+		}
+	}
+}
+
+func (c *Collection) rdapQuery(ctx context.Context, u string, v interface{}) bool {
+	headers := map[string]string{"Accept": "application/rdap+json"}
+
+	page, err := http.RequestWebPage(ctx, u, nil, headers, nil)
+	if err == nil {
+		err = json.Unmarshal([]byte(page), v)
+	}
+	if err != nil {
+		c.Config.Leveled().Warn(fmt.Sprintf("%s: %s: %v", rdapSource, u, err), config.SourceField(rdapSource))
+		return false
+	}
+	return true
+}
+
+// vcardValues returns the text values of the property in the jCard provided by RDAP.
+func vcardValues(vcard []interface{}, prop string) []string {
+	if len(vcard) != 2 {
+		return nil
+	}
+
+	props, ok := vcard[1].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, p := range props {
+		fields, ok := p.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		if name, ok := fields[0].(string); !ok || name != prop {
+			continue
+		}
+		if value, ok := fields[3].(string); ok && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func uniqueStrings(list []string) []string {
+	var unique []string
+
+	seen := make(map[string]struct{}, len(list))
+	for _, s := range list {
+		if _, found := seen[s]; !found {
+			seen[s] = struct{}{}
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
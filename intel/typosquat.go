@@ -0,0 +1,280 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package intel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// DefaultTypoSquatLimit is the largest number of permutations generated for each root domain name
+	DefaultTypoSquatLimit = 1000
+	typoSquatSource       = "Typosquat"
+	// The number of permutations checked concurrently when the configuration does not limit the DNS queries
+	typoSquatWorkers = 100
+)
+
+// The top-level domains the registered label is moved to, in addition to the public suffix of the domain
+var typoSquatTLDs = []string{"com", "net", "org", "info", "biz", "co", "io", "us", "uk", "de", "cn", "ru", "xyz", "online", "site"}
+
+// The characters that appear similar to each character in the ASCII and Unicode forms
+var typoSquatHomoglyphs = map[rune][]string{
+	'a': {"4", "а"},
+	'b': {"d", "lb"},
+	'c': {"e", "с"},
+	'd': {"b", "cl"},
+	'e': {"3", "е"},
+	'g': {"q", "9"},
+	'h': {"lh"},
+	'i': {"1", "l", "і"},
+	'k': {"lc"},
+	'l': {"1", "i"},
+	'm': {"rn", "nn"},
+	'n': {"m", "r"},
+	'o': {"0", "о"},
+	'p': {"р"},
+	'q': {"g"},
+	's': {"5", "ѕ"},
+	'u': {"v"},
+	'v': {"u"},
+	'w': {"vv"},
+	'x': {"х"},
+	'y': {"у"},
+	'z': {"2"},
+}
+
+// The adjacent keys of each key on a QWERTY keyboard
+var typoSquatKeyboard = map[rune]string{
+	'1': "2q", '2': "13wq", '3': "24ew", '4': "35re", '5': "46tr", '6': "57yt", '7': "68uy", '8': "79iu", '9': "80oi", '0': "9po",
+	'q': "12wa", 'w': "3qase2", 'e': "4wsdr3", 'r': "5edft4", 't': "6rfgy5", 'y': "7tghu6", 'u': "8yhji7", 'i': "9ujko8", 'o': "0iklp9", 'p': "0ol",
+	'a': "qwsz", 's': "edxzaw", 'd': "rfcxse", 'f': "tgvcdr", 'g': "yhbvft", 'h': "ujnbgy", 'j': "ikmnhu", 'k': "olmji", 'l': "pko",
+	'z': "asx", 'x': "zsdc", 'c': "xdfv", 'v': "cfgb", 'b': "vghn", 'n': "bhjm", 'm': "njk",
+}
+
+var typoSquatLabelRE = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// TypoSquatPermutations returns the typo-squatting permutations of the registered domain name, produced
+// by omitting, repeating, swapping and replacing characters with adjacent keys and homoglyphs, inserting
+// hyphens and moving the label to other top-level domains. The permutations are returned in the punycode
+// form, and at most max permutations are returned, starting with the techniques most often registered.
+func TypoSquatPermutations(domain string, max int) []string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if a, err := amassdns.ToASCII(domain); err == nil {
+		domain = a
+	}
+
+	registered, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return nil
+	}
+	suffix, _ := publicsuffix.PublicSuffix(registered)
+	label := strings.TrimSuffix(registered, "."+suffix)
+
+	if max <= 0 {
+		max = DefaultTypoSquatLimit
+	}
+
+	filter := stringset.New(registered)
+	defer filter.Close()
+
+	var results []string
+	add := func(l, s string) bool {
+		if len(results) >= max {
+			return false
+		}
+		if a, err := amassdns.ToASCII(l); err == nil {
+			l = a
+		}
+		if !typoSquatLabelRE.MatchString(l) {
+			return true
+		}
+
+		name := l + "." + s
+		if !filter.Has(name) {
+			filter.Insert(name)
+			results = append(results, name)
+		}
+		return true
+	}
+
+	for _, tld := range typoSquatTLDs {
+		if !add(label, tld) {
+			return results
+		}
+	}
+
+	runes := []rune(label)
+	for _, gen := range []func([]rune) []string{
+		typoOmissions, typoTranspositions, typoRepetitions, typoHomoglyphs, typoHyphenations, typoReplacements,
+	} {
+		for _, l := range gen(runes) {
+			if !add(l, suffix) {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+func typoOmissions(label []rune) []string {
+	var results []string
+
+	for i := range label {
+		results = append(results, string(label[:i])+string(label[i+1:]))
+	}
+	return results
+}
+
+func typoTranspositions(label []rune) []string {
+	var results []string
+
+	for i := 0; i < len(label)-1; i++ {
+		if label[i] == label[i+1] {
+			continue
+		}
+
+		l := append([]rune(nil), label...)
+		l[i], l[i+1] = l[i+1], l[i]
+		results = append(results, string(l))
+	}
+	return results
+}
+
+func typoRepetitions(label []rune) []string {
+	var results []string
+
+	for i, c := range label {
+		results = append(results, string(label[:i+1])+string(c)+string(label[i+1:]))
+	}
+	return results
+}
+
+func typoHomoglyphs(label []rune) []string {
+	var results []string
+
+	for i, c := range label {
+		for _, g := range typoSquatHomoglyphs[c] {
+			results = append(results, string(label[:i])+g+string(label[i+1:]))
+		}
+	}
+	return results
+}
+
+func typoHyphenations(label []rune) []string {
+	var results []string
+
+	for i := 1; i < len(label); i++ {
+		results = append(results, string(label[:i])+"-"+string(label[i:]))
+	}
+	return results
+}
+
+func typoReplacements(label []rune) []string {
+	var results []string
+
+	for i, c := range label {
+		for _, k := range typoSquatKeyboard[c] {
+			results = append(results, string(label[:i])+string(k)+string(label[i+1:]))
+		}
+	}
+	return results
+}
+
+// TypoSquats checks the registration and resolution of the typo-squatting permutations of the root domain
+// names in the configuration, and sends the permutations that are registered, or that resolve, to the
+// output channel. A permutation is considered registered when the name servers of the domain are found.
+func (c *Collection) TypoSquats(ctx context.Context, max int) error {
+	if c.Output == nil {
+		return errors.New("the intelligence collection did not have an output channel")
+	} else if err := c.Config.CheckSettings(); err != nil {
+		return err
+	}
+	defer close(c.Output)
+
+	ctx = context.WithValue(ctx, requests.ContextConfig, c.Config)
+	ctx = c.Config.ProxyContext(ctx)
+	c.ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
+
+	names := make(chan string)
+	go func() {
+		defer close(names)
+
+		for _, domain := range c.Config.Domains() {
+			for _, name := range TypoSquatPermutations(domain, max) {
+				select {
+				case <-c.ctx.Done():
+					return
+				case names <- name:
+				}
+			}
+		}
+	}()
+
+	workers := c.Config.MaxDNSQueries
+	if workers <= 0 || workers > typoSquatWorkers {
+		workers = typoSquatWorkers
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for name := range names {
+				if out := c.checkTypoSquat(c.ctx, name); out != nil && !c.filter.Has(name) {
+					c.filter.Insert(name)
+					c.Output <- out
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// checkTypoSquat returns the Output for the permutation when the domain is registered or resolves.
+func (c *Collection) checkTypoSquat(ctx context.Context, name string) *requests.Output {
+	registered := len(c.typoSquatAnswers(ctx, name, dns.TypeNS)) > 0
+
+	var addrs []requests.AddressInfo
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		for _, a := range c.typoSquatAnswers(ctx, name, qtype) {
+			if ip := net.ParseIP(strings.TrimSpace(a.Data)); ip != nil {
+				addrs = append(addrs, requests.AddressInfo{Address: ip})
+			}
+		}
+	}
+
+	if !registered && len(addrs) == 0 {
+		return nil
+	}
+	return &requests.Output{
+		Name:      name,
+		Domain:    name,
+		Addresses: addrs,
+		Tag:       requests.DNS,
+		Sources:   []string{typoSquatSource},
+	}
+}
+
+func (c *Collection) typoSquatAnswers(ctx context.Context, name string, qtype uint16) []*resolve.ExtractedAnswer {
+	resp, err := c.Sys.Pool().Query(ctx, resolve.QueryMsg(name, qtype), resolve.PriorityLow, resolve.PoolRetryPolicy)
+	if err != nil || resp == nil {
+		return nil
+	}
+	return resolve.AnswersByType(resolve.ExtractAnswers(resp), qtype)
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package intel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/caffix/stringset"
+)
+
+func TestTypoSquatPermutations(t *testing.T) {
+	perms := TypoSquatPermutations("www.OWASP.org", 0)
+	set := stringset.New(perms...)
+	defer set.Close()
+
+	if set.Len() != len(perms) {
+		t.Errorf("%d of the %d permutations were duplicates", len(perms)-set.Len(), len(perms))
+	}
+	if set.Has("owasp.org") || set.Has("www.owasp.org") {
+		t.Error("The domain was returned as one of its permutations")
+	}
+
+	for _, tt := range []struct {
+		technique string
+		name      string
+	}{
+		{"other top-level domain", "owasp.com"},
+		{"omission", "wasp.org"},
+		{"transposition", "woasp.org"},
+		{"repetition", "owwasp.org"},
+		{"homoglyph", "0wasp.org"},
+		{"hyphenation", "o-wasp.org"},
+		{"adjacent key", "iwasp.org"},
+	} {
+		if !set.Has(tt.name) {
+			t.Errorf("The %s permutation %s was not generated", tt.technique, tt.name)
+		}
+	}
+
+	var punycode bool
+	for _, p := range perms {
+		if strings.Count(p, ".") != 1 {
+			t.Errorf("The permutation %s is not a registered domain name", p)
+		}
+		if label := strings.Split(p, ".")[0]; !typoSquatLabelRE.MatchString(label) {
+			t.Errorf("The permutation %s does not have a valid label", p)
+		}
+		if strings.HasPrefix(p, "xn--") {
+			punycode = true
+		}
+	}
+	if !punycode {
+		t.Error("The Unicode homoglyph permutations were not returned in the punycode form")
+	}
+}
+
+func TestTypoSquatPermutationsLimit(t *testing.T) {
+	expected := []string{"owasp.com", "owasp.net", "owasp.info", "owasp.biz", "owasp.co"}
+
+	if got := TypoSquatPermutations("owasp.org", len(expected)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("TypoSquatPermutations() = %v, want %v", got, expected)
+	}
+	if got := TypoSquatPermutations("owasp.org", 0); len(got) > DefaultTypoSquatLimit {
+		t.Errorf("%d permutations were generated without a limit", len(got))
+	}
+	if got := TypoSquatPermutations("org", 0); got != nil {
+		t.Errorf("Permutations were generated for the public suffix: %v", got)
+	}
+}
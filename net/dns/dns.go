@@ -32,8 +32,9 @@ func AnySubdomainRegex() *regexp.Regexp {
 }
 
 // AnySubdomainRegexString returns a regular expression string to match any DNS subdomain name.
+// The top-level domain can be an internationalized label in the punycode form.
 func AnySubdomainRegexString() string {
-	return SUBRE + "[a-zA-Z]{2,61}"
+	return SUBRE + "(xn--[a-zA-Z0-9-]{1,59}|[a-zA-Z]{2,61})"
 }
 
 // CopyString return a new string variable with the same value as the parameter.
@@ -45,6 +45,8 @@ func TestAnySubdomainRegex(t *testing.T) {
 		{"sub..owasp.org", false},
 		{".sub-d.sub-domain.owasp.com", false},
 		{"sub-.owasp.org", false},
+		{"xn--e1afmkfd.xn--p1ai", true},
+		{"www.xn--bcher-kva.de", true},
 	}
 
 	re := AnySubdomainRegex()
@@ -0,0 +1,65 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// ToASCII returns the lowercase ASCII form of the DNS name, with each label containing characters
+// outside of ASCII converted to punycode, so the Unicode and punycode forms of an internationalized
+// name are the same string. Labels that are already ASCII, including underscore and asterisk labels,
+// are only lowercased. An error is returned when a label is not a valid internationalized label.
+func ToASCII(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if isASCII(name) {
+		return strings.ToLower(name), nil
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			labels[i] = strings.ToLower(label)
+			continue
+		}
+
+		l, err := idna.Lookup.ToASCII(label)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = l
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// ToUnicode returns the Unicode form of the DNS name for display, with each punycode label decoded.
+// Labels that cannot be decoded are returned unchanged.
+func ToUnicode(name string) string {
+	if !strings.Contains(strings.ToLower(name), "xn--") {
+		return name
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), "xn--") {
+			continue
+		}
+		if l, err := idna.Display.ToUnicode(label); err == nil {
+			labels[i] = l
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"ASCII name", " WWW.OWASP.org ", "www.owasp.org", false},
+		{"service label", "_sip._tcp.owasp.org", "_sip._tcp.owasp.org", false},
+		{"Unicode label", "www.Bücher.de", "www.xn--bcher-kva.de", false},
+		{"punycode label", "www.xn--bcher-kva.de", "www.xn--bcher-kva.de", false},
+		{"Unicode TLD", "пример.рф", "xn--e1afmkfd.xn--p1ai", false},
+		{"mixed script label", "pаypal.com", "xn--pypal-4ve.com", false},
+		{"emoji label", "💩.la", "xn--ls8h.la", false},
+		{"invalid label", "bü_cher.de", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ToASCII(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ToASCII(%q) returned the error %v", tt.name, tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: ToASCII(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"www.owasp.org", "www.owasp.org"},
+		{"www.xn--bcher-kva.de", "www.bücher.de"},
+		{"xn--ls8h.la", "💩.la"},
+		{"xn--pypal-4ve.com", "pаypal.com"},
+		// Labels that cannot be decoded are not changed
+		{"xn--a.owasp.org", "xn--a.owasp.org"},
+	}
+
+	for _, tt := range tests {
+		if got := ToUnicode(tt.input); got != tt.want {
+			t.Errorf("ToUnicode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/caffix/stringset"
+)
+
+// The largest response body read when fingerprinting a web server
+const maxFingerprintBody = 1 << 20
+
+// Fingerprint identifies the response of a web server, allowing the responses for different names to be compared.
+type Fingerprint struct {
+	StatusCode int
+	Title      string
+	BodyHash   string
+	// The technologies announced by the Server and X-Powered-By headers, and the generator meta tag,
+	// which are not compared by Equal
+	Technologies []string
+}
+
+// Equal returns true when the fingerprints identify the same response.
+func (f *Fingerprint) Equal(other *Fingerprint) bool {
+	return f != nil && other != nil && f.StatusCode == other.StatusCode &&
+		f.Title == other.Title && f.BodyHash == other.BodyHash
+}
+
+// ResponseFingerprint requests the root page of the host using the scheme provided and returns
+// the fingerprint of the response. Occurrences of the host name are removed from the body before
+// it is hashed, since catch-all servers often include the requested name in the page.
+func ResponseFingerprint(ctx context.Context, scheme, host string) (*Fingerprint, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", scheme+"://"+host+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", Accept)
+	req.Header.Set("Accept-Language", AcceptLang)
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFingerprintBody))
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.ReplaceAll(bytes.ToLower(body), []byte(strings.ToLower(host)), nil)
+
+	var title string
+	techs := stringset.New()
+	defer techs.Close()
+
+	if doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body)); err == nil {
+		title = strings.TrimSpace(doc.Find("title").First().Text())
+		if gen, found := doc.Find(`meta[name="generator"]`).First().Attr("content"); found {
+			techs.Insert(strings.TrimSpace(gen))
+		}
+	}
+	for _, header := range []string{"Server", "X-Powered-By"} {
+		for _, v := range resp.Header.Values(header) {
+			techs.Insert(strings.TrimSpace(v))
+		}
+	}
+	techs.Remove("")
+
+	hash := sha256.Sum256(body)
+	fp := &Fingerprint{
+		StatusCode:   resp.StatusCode,
+		Title:        title,
+		BodyHash:     hex.EncodeToString(hash[:]),
+		Technologies: techs.Slice(),
+	}
+	sort.Strings(fp.Technologies)
+	return fp, nil
+}
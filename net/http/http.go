@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	amassnet "github.com/OWASP/Amass/v3/net"
@@ -58,13 +59,44 @@ type BasicAuth struct {
 	Password string
 }
 
+// HeaderProfile contains the HTTP headers configured for the requests of a data source.
+type HeaderProfile struct {
+	// The headers set on each request, replacing the default values and those of the data source
+	Headers map[string]string
+	// The User-Agent values rotated across the requests, which take precedence over the headers
+	UserAgents []string
+}
+
+type headerProfileKey struct{}
+
+// The position in the User-Agent values of the profiles, shared by all the requests
+var userAgentIndex uint32
+
+// WithHeaderProfile returns a copy of the context carrying the profile applied by RequestWebPage.
+func WithHeaderProfile(ctx context.Context, hp *HeaderProfile) context.Context {
+	if hp == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, headerProfileKey{}, hp)
+}
+
+func (hp *HeaderProfile) apply(req *http.Request) {
+	for k, v := range hp.Headers {
+		req.Header.Set(k, v)
+	}
+	if num := len(hp.UserAgents); num > 0 {
+		i := atomic.AddUint32(&userAgentIndex, 1) - 1
+		req.Header.Set("User-Agent", hp.UserAgents[int(i%uint32(num))])
+	}
+}
+
 func init() {
 	jar, _ := cookiejar.New(nil)
 	DefaultClient = &http.Client{
 		Timeout: httpTimeout,
 		Transport: &http.Transport{
-			Proxy:                 http.ProxyFromEnvironment,
-			DialContext:           amassnet.DialContext,
+			Proxy:                 amassnet.ProxyURL,
+			DialContext:           amassnet.DirectDialContext,
 			MaxIdleConns:          200,
 			MaxConnsPerHost:       50,
 			IdleConnTimeout:       90 * time.Second,
@@ -85,6 +117,31 @@ func init() {
 	}
 }
 
+// ProxyClient returns a client sharing the connections and cookies of the DefaultClient, which sends
+// the requests through the proxy regardless of the contexts provided with the requests. This suits
+// the client libraries that do not accept a context. The DefaultClient is returned for a nil proxy.
+func ProxyClient(p *amassnet.Proxy) *http.Client {
+	if p == nil {
+		return DefaultClient
+	}
+
+	return &http.Client{
+		Timeout:   DefaultClient.Timeout,
+		Transport: &proxyTransport{proxy: p, rt: DefaultClient.Transport},
+		Jar:       DefaultClient.Jar,
+	}
+}
+
+type proxyTransport struct {
+	proxy *amassnet.Proxy
+	rt    http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.rt.RoundTrip(req.WithContext(amassnet.WithProxy(req.Context(), t.proxy)))
+}
+
 // CopyCookies copies cookies from one domain to another. Some of our data
 // sources rely on shared auth tokens and this avoids sending extra requests
 // to have the site reissue cookies for the other domains.
@@ -130,6 +187,9 @@ func RequestWebPage(ctx context.Context, u string, body io.Reader, hvals map[str
 	for k, v := range hvals {
 		req.Header.Set(k, v)
 	}
+	if hp, ok := ctx.Value(headerProfileKey{}).(*HeaderProfile); ok && hp != nil {
+		hp.apply(req)
+	}
 
 	var in string
 	resp, err := DefaultClient.Do(req)
@@ -168,7 +228,7 @@ func Crawl(ctx context.Context, u string, scope []string, max int, f *stringset.
 		RetryTimes:     2,
 		RetryHTTPCodes: []int{408, 500, 502, 503, 504, 522, 524},
 	})
-	g.Client.Client = http.DefaultClient
+	g.Client.Client = DefaultClient
 
 	done := make(chan struct{}, 2)
 	go func() {
@@ -265,26 +325,55 @@ func whichDomain(name string, scope []string) string {
 // PullCertificateNames attempts to pull a cert from one or more ports on an IP.
 func PullCertificateNames(ctx context.Context, addr string, ports []int) []string {
 	var names []string
+	// Create the new requests from names found within the certs
+	for _, cert := range PullCertificates(ctx, addr, ports) {
+		names = append(names, NamesFromCert(cert)...)
+	}
+	return names
+}
+
+// PullCertificates attempts to pull the certificate presented on each of the ports of an IP.
+func PullCertificates(ctx context.Context, addr string, ports []int) []*x509.Certificate {
+	var certs []*x509.Certificate
 	// Check hosts for certificates that contain subdomain names
 	for _, port := range ports {
 		if c, err := TLSConn(ctx, addr, port); err == nil {
 			// Get the correct certificate in the chain
-			certChain := c.ConnectionState().PeerCertificates
-			// Create the new requests from names found within the cert
-			names = append(names, namesFromCert(certChain[0])...)
+			if certChain := c.ConnectionState().PeerCertificates; len(certChain) > 0 {
+				certs = append(certs, certChain[0])
+			}
 		}
 
 		select {
 		case <-ctx.Done():
-			return names
+			return certs
 		default:
 		}
 	}
-	return names
+	return certs
 }
 
 // TLSConn attempts to make a TLS connection with the host on given port
 func TLSConn(ctx context.Context, host string, port int) (*tls.Conn, error) {
+	return tlsConn(ctx, host, port, "")
+}
+
+// PeerCertificate returns the certificate presented by the host on the given port
+// when the server name provided is sent in the TLS ClientHello.
+func PeerCertificate(ctx context.Context, host string, port int, serverName string) (*x509.Certificate, error) {
+	c, err := tlsConn(ctx, host, port, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := c.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate was presented")
+	}
+	return certs[0], nil
+}
+
+func tlsConn(ctx context.Context, host string, port int, serverName string) (*tls.Conn, error) {
 	// Set the maximum time allowed for making the connection
 	tCtx, cancel := context.WithTimeout(ctx, handshakeTimeout)
 	defer cancel()
@@ -295,7 +384,10 @@ func TLSConn(ctx context.Context, host string, port int) (*tls.Conn, error) {
 	}
 	defer conn.Close()
 
-	c := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	c := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
 	// Attempt to acquire the certificate chain
 	errChan := make(chan error, 2)
 	go func() {
@@ -314,7 +406,8 @@ func TLSConn(ctx context.Context, host string, port int) (*tls.Conn, error) {
 	return c, err
 }
 
-func namesFromCert(cert *x509.Certificate) []string {
+// NamesFromCert returns the subdomain names found in the subject common name and the DNS names of the certificate.
+func NamesFromCert(cert *x509.Certificate) []string {
 	var cn string
 
 	for _, name := range cert.Subject.Names {
@@ -344,6 +437,14 @@ func namesFromCert(cert *x509.Certificate) []string {
 	return subdomains.Slice()
 }
 
+// CertOrganizations returns the subject organizations and organizational units of the certificate.
+func CertOrganizations(cert *x509.Certificate) []string {
+	var orgs []string
+
+	orgs = append(orgs, cert.Subject.Organization...)
+	return append(orgs, cert.Subject.OrganizationalUnit...)
+}
+
 // CleanName will clean up the names scraped from the web.
 func CleanName(name string) string {
 	clean, err := strconv.Unquote("\"" + strings.TrimSpace(name) + "\"")
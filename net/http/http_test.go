@@ -136,6 +136,33 @@ func TestRequestWebPage(t *testing.T) {
 	}
 }
 
+func TestRequestWebPageHeaderProfile(t *testing.T) {
+	var agents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		fmt.Fprint(w, r.Header.Get("X-Api-Version")+" "+r.Header.Get("Accept"))
+	}))
+	defer ts.Close()
+
+	ctx := WithHeaderProfile(context.Background(), &HeaderProfile{
+		Headers:    map[string]string{"X-Api-Version": "2", "Accept": "application/json"},
+		UserAgents: []string{"agent-one", "agent-two"},
+	})
+	for i := 0; i < 3; i++ {
+		resp, err := RequestWebPage(ctx, ts.URL, nil, map[string]string{"Accept": "text/html"}, nil)
+		if err != nil || resp != "2 application/json" {
+			t.Errorf("The configured headers were not applied to the request: %s", resp)
+		}
+	}
+	if len(agents) != 3 || agents[0] == agents[1] || agents[0] != agents[2] {
+		t.Errorf("The User-Agent values were not rotated across the requests: %v", agents)
+	}
+
+	if _, err := RequestWebPage(context.Background(), ts.URL, nil, nil, nil); err != nil || len(agents) != 4 || agents[3] != UserAgent {
+		t.Errorf("The default User-Agent was not used without a profile: %v", agents)
+	}
+}
+
 func TestCrawl(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -284,3 +311,29 @@ func TestCleanName(t *testing.T) {
 		}
 	}
 }
+
+func TestResponseFingerprint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<html><head><title>Parked</title></head><body>%s is for sale</body></html>", r.Host)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	_, port, _ := net.SplitHostPort(u.Host)
+
+	first, err := ResponseFingerprint(context.Background(), "http", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint the response: %v", err)
+	}
+	if first.StatusCode != http.StatusOK || first.Title != "parked" {
+		t.Errorf("the fingerprint had the status code %d and title %s", first.StatusCode, first.Title)
+	}
+
+	second, err := ResponseFingerprint(context.Background(), "http", "localhost:"+port)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint the response: %v", err)
+	}
+	if !first.Equal(second) {
+		t.Errorf("the responses that only differed by the host name had different fingerprints")
+	}
+}
@@ -54,8 +54,18 @@ func init() {
 	}
 }
 
-// DialContext performs the dial using global variables (e.g. LocalAddr).
+// DialContext performs the dial using global variables (e.g. LocalAddr). TCP connections
+// are established through the proxy when the context carries one provided by WithProxy.
 func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if p := ContextProxy(ctx); p != nil && strings.HasPrefix(network, "tcp") {
+		return p.DialContext(ctx, network, addr)
+	}
+	return DirectDialContext(ctx, network, addr)
+}
+
+// DirectDialContext performs the dial using global variables (e.g. LocalAddr) without the proxy.
+// It is used by HTTP transports that send requests to the proxy selected by ProxyURL.
+func DirectDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
 	d := &net.Dialer{DualStack: true}
 
 	_, p, err := net.SplitHostPort(addr)
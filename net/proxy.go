@@ -0,0 +1,212 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// Proxy establishes the TCP connections and selects the proxy of the HTTP requests made through a
+// socks5:// or http(s):// proxy. Each configuration keeps its own Proxy, which is carried by the
+// contexts of the connections and requests, so the enumerations in a process can use different proxies.
+type Proxy struct {
+	url    *url.URL
+	dialer proxy.ContextDialer
+}
+
+type proxyKey struct{}
+
+// NewProxy returns the Proxy for the URL of a socks5:// or http(s):// proxy. Credentials can be provided
+// as the user information of the URL. Nil is returned for an empty string, since no proxy is used.
+func NewProxy(rawurl string) (*Proxy, error) {
+	if rawurl == "" {
+		return nil, nil
+	}
+
+	u, err := ParseProxyURL(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	var d proxy.ContextDialer
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		pd, err := proxy.FromURL(u, directDialer{})
+		if err != nil {
+			return nil, err
+		}
+
+		cd, ok := pd.(proxy.ContextDialer)
+		if !ok {
+			return nil, errors.New("the SOCKS5 dialer does not support contexts")
+		}
+		d = cd
+	case "http", "https":
+		d = &connectDialer{proxy: u}
+	}
+
+	return &Proxy{url: u, dialer: d}, nil
+}
+
+// ParseProxyURL parses and validates the URL of a socks5:// or http(s):// proxy.
+func ParseProxyURL(rawurl string) (*url.URL, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "socks5", "socks5h", "http", "https":
+	default:
+		return nil, fmt.Errorf("%s is not a supported proxy URL", rawurl)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%s is missing the proxy host", rawurl)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	return u, nil
+}
+
+// URL returns the URL of the proxy, which can be returned by the Proxy field of an http.Transport.
+func (p *Proxy) URL() *url.URL {
+	return p.url
+}
+
+// DialContext establishes the TCP connection through the proxy.
+func (p *Proxy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if !strings.HasPrefix(network, "tcp") {
+		return nil, fmt.Errorf("the %s network cannot be used through the proxy", network)
+	}
+	return p.dialer.DialContext(ctx, network, addr)
+}
+
+// WithProxy returns a copy of the context carrying the proxy used by DialContext and ProxyURL.
+func WithProxy(ctx context.Context, p *Proxy) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyKey{}, p)
+}
+
+// ContextProxy returns the proxy carried by the context, or nil when the context has no proxy.
+func ContextProxy(ctx context.Context) *Proxy {
+	if ctx == nil {
+		return nil
+	}
+
+	p, _ := ctx.Value(proxyKey{}).(*Proxy)
+	return p
+}
+
+// ProxyURL returns the proxy carried by the context of the request, and the proxy selected by the
+// environment variables otherwise. It can be assigned to the Proxy field of an http.Transport.
+func ProxyURL(req *http.Request) (*url.URL, error) {
+	if p := ContextProxy(req.Context()); p != nil {
+		return p.url, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+type directDialer struct{}
+
+// Dial implements the proxy.Dialer interface.
+func (directDialer) Dial(network, addr string) (net.Conn, error) {
+	return DirectDialContext(context.Background(), network, addr)
+}
+
+// DialContext implements the proxy.ContextDialer interface.
+func (directDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return DirectDialContext(ctx, network, addr)
+}
+
+// connectDialer establishes TCP connections through an HTTP proxy using the CONNECT method.
+type connectDialer struct {
+	proxy *url.URL
+}
+
+// DialContext implements the proxy.ContextDialer interface.
+func (c *connectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if !strings.HasPrefix(network, "tcp") {
+		return nil, fmt.Errorf("the %s network cannot be used through an HTTP proxy", network)
+	}
+
+	host := c.proxy.Host
+	if c.proxy.Port() == "" {
+		port := "80"
+		if c.proxy.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(c.proxy.Hostname(), port)
+	}
+
+	conn, err := DirectDialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if c.proxy.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: c.proxy.Hostname()})
+	}
+
+	// The deadline of the context applies while the tunnel is established
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := c.proxy.User; user != nil {
+		pass, _ := user.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + pass))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("the proxy refused the connection to %s: %s", addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn returns the data read ahead while establishing the tunnel before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+)
+
+const (
+	// The longest DNS name and label in the presentation form, without the trailing dot
+	maxNameLength  = 253
+	maxLabelLength = 63
+)
+
+// NormalizeName returns the form of the DNS name handled by the enumeration: lowercase, in the
+// punycode form when internationalized, and without the wildcard label, surrounding whitespace
+// or leading and trailing dots. An error is returned when the name contains control characters or
+// whitespace, cannot be converted to the punycode form, or exceeds the length limits of the DNS names
+// and labels. NormalizeName has no side effects, and the names returned are left unchanged by it.
+func NormalizeName(name string) (string, error) {
+	if strings.IndexFunc(name, unicode.IsControl) != -1 {
+		return "", errors.New("the name contains control characters")
+	}
+
+	n, err := normalizeNameForm(name)
+	if err != nil {
+		return "", fmt.Errorf("the name %q cannot be converted to the ASCII form: %v", name, err)
+	}
+	if n == "" {
+		return "", errors.New("the name is empty")
+	}
+	if strings.IndexFunc(n, unicode.IsSpace) != -1 {
+		return "", fmt.Errorf("the name %q contains whitespace", n)
+	}
+	if len(n) > maxNameLength {
+		return "", fmt.Errorf("the name exceeds %d characters", maxNameLength)
+	}
+
+	for _, label := range strings.Split(n, ".") {
+		if label == "" {
+			return "", fmt.Errorf("the name %s contains an empty label", n)
+		}
+		if len(label) > maxLabelLength {
+			return "", fmt.Errorf("the name %s contains a label exceeding %d characters", n, maxLabelLength)
+		}
+	}
+	return n, nil
+}
+
+// NormalizeAddress returns the canonical form of the IP address, such as the dotted form of an
+// IPv4-mapped IPv6 address, and an error when the address cannot be parsed.
+func NormalizeAddress(addr string) (string, error) {
+	if strings.IndexFunc(addr, unicode.IsControl) != -1 {
+		return "", errors.New("the address contains control characters")
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return "", fmt.Errorf("%q is not a valid IP address", addr)
+	}
+	return ip.String(), nil
+}
+
+// normalizeNameForm returns the cleaned up name, which remains in the Unicode form when the punycode conversion fails.
+func normalizeNameForm(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = amassdns.RemoveAsteriskLabel(name)
+	name = strings.Trim(name, ".")
+	// Internationalized names are handled in the punycode form
+	a, err := amassdns.ToASCII(name)
+	if err != nil {
+		return name, err
+	}
+	return a, nil
+}
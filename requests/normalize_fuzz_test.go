@@ -0,0 +1,84 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func FuzzNormalizeName(f *testing.F) {
+	for _, seed := range []string{
+		"www.example.com",
+		"  WWW.Example.COM.  ",
+		"*.dev.example.com",
+		"www.Bücher.de",
+		"xn--bcher-kva.de",
+		"xn--.example.com",
+		"www.-ü.example.com",
+		"exаmple.com",
+		strings.Repeat("a", 63) + ".example.com",
+		strings.Repeat("a", 64) + ".example.com",
+		strings.Repeat("abcdefghi.", 26) + "com",
+		"www..example.com",
+		"www\x00.example.com",
+		"example.com\x00",
+		"\xff\xfe.example.com",
+		"*. .example.com",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		name, err := NormalizeName(input)
+		if err != nil {
+			return
+		}
+
+		if name == "" || len(name) > maxNameLength {
+			t.Fatalf("NormalizeName(%q) returned %q with an invalid length", input, name)
+		}
+		for i := 0; i < len(name); i++ {
+			if c := name[i]; c >= 0x80 || (c >= 'A' && c <= 'Z') {
+				t.Fatalf("NormalizeName(%q) returned %q, which is not in the lowercase ASCII form", input, name)
+			}
+		}
+		if again, err := NormalizeName(name); err != nil || again != name {
+			t.Fatalf("NormalizeName(%q) = %q, but normalizing it again returned %q, %v", input, name, again, err)
+		}
+	})
+}
+
+func FuzzNormalizeAddress(f *testing.F) {
+	for _, seed := range []string{
+		"192.168.1.1",
+		" 10.0.0.1 ",
+		"2001:db8::1",
+		"::ffff:192.168.1.1",
+		"fe80::1%eth0",
+		"256.1.1.1",
+		"192.168.1.1\x00",
+		"www.example.com",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		addr, err := NormalizeAddress(input)
+		if err != nil {
+			return
+		}
+
+		if net.ParseIP(addr) == nil {
+			t.Fatalf("NormalizeAddress(%q) returned %q, which is not an IP address", input, addr)
+		}
+		if again, err := NormalizeAddress(addr); err != nil || again != addr {
+			t.Fatalf("NormalizeAddress(%q) = %q, but normalizing it again returned %q, %v", input, addr, again, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "mixed case and whitespace", input: "  WWW.Example.COM  ", expected: "www.example.com"},
+		{name: "trailing dot", input: "www.example.com.", expected: "www.example.com"},
+		{name: "wildcard label", input: "*.dev.example.com", expected: "dev.example.com"},
+		{name: "internationalized name", input: "www.Bücher.de", expected: "www.xn--bcher-kva.de"},
+		{name: "punycode name", input: "WWW.XN--BCHER-KVA.DE", expected: "www.xn--bcher-kva.de"},
+		{name: "longest label", input: strings.Repeat("a", 63) + ".example.com", expected: strings.Repeat("a", 63) + ".example.com"},
+		{name: "empty name", input: " . ", wantErr: true},
+		{name: "overlong label", input: strings.Repeat("a", 64) + ".example.com", wantErr: true},
+		{name: "overlong name", input: strings.Repeat("abcdefghi.", 26) + "com", wantErr: true},
+		{name: "empty label", input: "www..example.com", wantErr: true},
+		{name: "embedded null", input: "www\x00.example.com", wantErr: true},
+		{name: "embedded newline", input: "www.example.com\nevil.com", wantErr: true},
+		{name: "embedded space", input: "www example.com", wantErr: true},
+		{name: "invalid internationalized label", input: "www.-ü.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeName(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "IPv4 address", input: " 192.168.1.1 ", expected: "192.168.1.1"},
+		{name: "IPv6 address", input: "2001:DB8::1", expected: "2001:db8::1"},
+		{name: "IPv4-mapped IPv6 address", input: "::ffff:192.168.1.1", expected: "192.168.1.1"},
+		{name: "name instead of an address", input: "www.example.com", wantErr: true},
+		{name: "address with a zone", input: "fe80::1%eth0", wantErr: true},
+		{name: "embedded null", input: "192.168.1.1\x00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAddress(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeAddress(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeAddress(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
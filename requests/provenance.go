@@ -0,0 +1,83 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import "context"
+
+// DiscoveryStep is a single technique in the chain of techniques that led to a discovered name,
+// such as a name found in a certificate transparency log, brute forced beneath the subdomain of
+// that name, and then altered.
+type DiscoveryStep struct {
+	Tag    string `json:"tag"`
+	Source string `json:"source"`
+	// The name that the technique derived the new name from, which is empty for the first step
+	From string `json:"from,omitempty"`
+}
+
+type discoveryParent struct {
+	name  string
+	depth int
+	chain []DiscoveryStep
+}
+
+// ProvenanceChain returns the discovery steps that led to the name with the tag, source and recorded
+// provenance provided. Names provided directly by a data source have no recorded provenance, and
+// their chain is the single step of the tag and source.
+func ProvenanceChain(tag, source string, provenance []DiscoveryStep) []DiscoveryStep {
+	if len(provenance) > 0 {
+		return provenance
+	}
+	return []DiscoveryStep{{Tag: tag, Source: source}}
+}
+
+// DeriveProvenance returns the provenance of a name derived from the parent name, which is
+// the chain of the parent followed by the step of the technique that derived the name.
+func DeriveProvenance(parent string, chain []DiscoveryStep, tag, source string) []DiscoveryStep {
+	steps := make([]DiscoveryStep, 0, len(chain)+1)
+
+	steps = append(steps, chain...)
+	return append(steps, DiscoveryStep{
+		Tag:    tag,
+		Source: source,
+		From:   parent,
+	})
+}
+
+// WithDiscoveryParent returns a context identifying the name, and its depth and provenance chain,
+// that the names generated using the context are derived from.
+func WithDiscoveryParent(ctx context.Context, name string, depth int, chain []DiscoveryStep) context.Context {
+	return context.WithValue(ctx, ContextDiscoveryParent, &discoveryParent{
+		name:  name,
+		depth: depth,
+		chain: chain,
+	})
+}
+
+// ContextProvenance returns the provenance of a name generated by the technique identified by the
+// tag and source, using the context provided. Nil is returned when the context does not identify
+// the name the new name was derived from.
+func ContextProvenance(ctx context.Context, tag, source string) []DiscoveryStep {
+	p, ok := ctx.Value(ContextDiscoveryParent).(*discoveryParent)
+	if !ok || p == nil {
+		return nil
+	}
+	return DeriveProvenance(p.name, p.chain, tag, source)
+}
+
+// ContextDepth returns the depth of a name generated using the context provided, which is one
+// generation beyond the name it was derived from. Zero is returned when the context does not
+// identify the name the new name was derived from.
+func ContextDepth(ctx context.Context) int {
+	p, ok := ctx.Value(ContextDiscoveryParent).(*discoveryParent)
+	if !ok || p == nil {
+		return 0
+	}
+	return p.depth + 1
+}
+
+// OriginStep returns the first discovery step of the name with the tag, source and recorded provenance
+// provided, which identifies the data source that the chain of derived names originated from.
+func OriginStep(tag, source string, provenance []DiscoveryStep) DiscoveryStep {
+	return ProvenanceChain(tag, source, provenance)[0]
+}
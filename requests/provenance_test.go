@@ -0,0 +1,77 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextProvenance(t *testing.T) {
+	ctx := context.Background()
+	if p := ContextProvenance(ctx, BRUTE, "Brute Forcing"); p != nil {
+		t.Errorf("Provenance was returned for a context without a parent name: %v", p)
+	}
+
+	// A subdomain found in a certificate is brute forced, and the brute forced name is altered
+	ctx = WithDiscoveryParent(ctx, "dev.owasp.org", 0, ProvenanceChain(CERT, "Crtsh", nil))
+	brute := ContextProvenance(ctx, BRUTE, "Brute Forcing")
+	ctx = WithDiscoveryParent(context.Background(), "api.dev.owasp.org", 1, ProvenanceChain(BRUTE, "Brute Forcing", brute))
+	alt := ContextProvenance(ctx, ALT, "Alterations")
+
+	expected := []DiscoveryStep{
+		{Tag: CERT, Source: "Crtsh"},
+		{Tag: BRUTE, Source: "Brute Forcing", From: "dev.owasp.org"},
+		{Tag: ALT, Source: "Alterations", From: "api.dev.owasp.org"},
+	}
+	if len(alt) != len(expected) {
+		t.Fatalf("Expected %d discovery steps, but got %v", len(expected), alt)
+	}
+	for i, step := range expected {
+		if alt[i] != step {
+			t.Errorf("Discovery step %d was %v, expected %v", i, alt[i], step)
+		}
+	}
+	// Deriving a name must not modify the chain of its parent
+	if len(brute) != 2 {
+		t.Errorf("The chain of the parent name was modified: %v", brute)
+	}
+}
+
+func TestDNSRequestCloneProvenance(t *testing.T) {
+	req := &DNSRequest{
+		Name:       "api.dev.owasp.org",
+		Provenance: []DiscoveryStep{{Tag: CERT, Source: "Crtsh"}},
+	}
+
+	c := req.Clone().(*DNSRequest)
+	c.Provenance[0].Source = "Censys"
+	if req.Provenance[0].Source != "Crtsh" {
+		t.Errorf("The clone shares the provenance of the original request")
+	}
+}
+
+func TestContextDepth(t *testing.T) {
+	ctx := context.Background()
+	if d := ContextDepth(ctx); d != 0 {
+		t.Errorf("A depth of %d was returned for a context without a parent name", d)
+	}
+
+	ctx = WithDiscoveryParent(ctx, "dev.owasp.org", 0, ProvenanceChain(CERT, "Crtsh", nil))
+	if d := ContextDepth(ctx); d != 1 {
+		t.Errorf("The name derived from a source name had a depth of %d", d)
+	}
+
+	brute := ContextProvenance(ctx, BRUTE, "Brute Forcing")
+	ctx = WithDiscoveryParent(context.Background(), "api.dev.owasp.org", 1, brute)
+	if d := ContextDepth(ctx); d != 2 {
+		t.Errorf("The name derived from a derived name had a depth of %d", d)
+	}
+	if origin := OriginStep(BRUTE, "Brute Forcing", brute); origin.Tag != CERT || origin.Source != "Crtsh" {
+		t.Errorf("The origin of the chain was %v", origin)
+	}
+	if origin := OriginStep(CERT, "Crtsh", nil); origin.Tag != CERT || origin.Source != "Crtsh" {
+		t.Errorf("The origin of a source name was %v", origin)
+	}
+}
@@ -27,13 +27,30 @@ const (
 	AXFR     = "axfr"
 	BRUTE    = "brute"
 	CERT     = "cert"
+	CNAME    = "cname"
 	CRAWL    = "crawl"
 	DNS      = "dns"
 	RIR      = "rir"
 	EXTERNAL = "ext"
+	LOCAL    = "local"
 	SCRAPE   = "scrape"
 )
 
+// DNSSEC validation statuses of the DNS answers.
+const (
+	DNSSECSecure   = "secure"
+	DNSSECInsecure = "insecure"
+	DNSSECBogus    = "bogus"
+)
+
+// The results of probing a data source service with the credentials of the configuration.
+const (
+	CredentialsValid       = "valid"
+	CredentialsInvalid     = "invalid"
+	CredentialsUnreachable = "unreachable"
+	CredentialsUnchecked   = "unchecked"
+)
+
 // ContextKey is the type used for context value keys.
 type ContextKey int
 
@@ -41,6 +58,7 @@ type ContextKey int
 const (
 	ContextConfig ContextKey = iota
 	ContextEventBus
+	ContextDiscoveryParent
 )
 
 // Request Pub/Sub topics used across Amass.
@@ -54,6 +72,8 @@ const (
 	NewWhoisTopic      = "amass:whoisinfo"
 	LogTopic           = "amass:log"
 	OutputTopic        = "amass:output"
+	// Published with a root domain name added to the configuration and the reason it was added
+	NewScopeTopic = "amass:newscope"
 )
 
 // ContextConfigBus extracts the Config and EventBus references from the Context argument.
@@ -89,6 +109,10 @@ type DNSAnswer struct {
 	Type int    `json:"type"`
 	TTL  int    `json:"TTL"`
 	Data string `json:"data"`
+	// Set when the answer was provided by an authoritative server of the zone
+	Authoritative bool `json:"authoritative,omitempty"`
+	// The DNSSEC validation status of the answer, which is empty when the validation is not performed
+	DNSSEC string `json:"dnssec,omitempty"`
 }
 
 // DNSRequest handles data needed throughout Service processing of a DNS name.
@@ -98,16 +122,22 @@ type DNSRequest struct {
 	Records []DNSAnswer
 	Tag     string
 	Source  string
+	// The discovery steps that derived the name, which is empty for names provided directly by a data source
+	Provenance []DiscoveryStep
+	// The number of generations the name was derived from a name provided directly by a data source
+	Depth int
 }
 
 // Clone implements pipeline Data.
 func (d *DNSRequest) Clone() pipeline.Data {
 	return &DNSRequest{
-		Name:    d.Name,
-		Domain:  d.Domain,
-		Records: append([]DNSAnswer(nil), d.Records...),
-		Tag:     d.Tag,
-		Source:  d.Source,
+		Name:       d.Name,
+		Domain:     d.Domain,
+		Records:    append([]DNSAnswer(nil), d.Records...),
+		Tag:        d.Tag,
+		Source:     d.Source,
+		Provenance: append([]DiscoveryStep(nil), d.Provenance...),
+		Depth:      d.Depth,
 	}
 }
 
@@ -131,21 +161,25 @@ func (d *DNSRequest) Valid() bool {
 // ResolvedRequest allows services to identify DNS names that have been resolved.
 
 type ResolvedRequest struct {
-	Name    string
-	Domain  string
-	Records []DNSAnswer
-	Tag     string
-	Source  string
+	Name       string
+	Domain     string
+	Records    []DNSAnswer
+	Tag        string
+	Source     string
+	Provenance []DiscoveryStep
+	Depth      int
 }
 
 // Clone implements pipeline Data.
 func (r *ResolvedRequest) Clone() pipeline.Data {
 	return &ResolvedRequest{
-		Name:    r.Name,
-		Domain:  r.Domain,
-		Records: append([]DNSAnswer(nil), r.Records...),
-		Tag:     r.Tag,
-		Source:  r.Source,
+		Name:       r.Name,
+		Domain:     r.Domain,
+		Records:    append([]DNSAnswer(nil), r.Records...),
+		Tag:        r.Tag,
+		Source:     r.Source,
+		Provenance: append([]DiscoveryStep(nil), r.Provenance...),
+		Depth:      r.Depth,
 	}
 }
 
@@ -168,22 +202,26 @@ func (r *ResolvedRequest) Valid() bool {
 
 // SubdomainRequest handles subdomain data processed by enumeration.
 type SubdomainRequest struct {
-	Name    string
-	Domain  string
-	Records []DNSAnswer
-	Tag     string
-	Source  string
-	Times   int
+	Name       string
+	Domain     string
+	Records    []DNSAnswer
+	Tag        string
+	Source     string
+	Times      int
+	Provenance []DiscoveryStep
+	Depth      int
 }
 
 // Clone implements pipeline Data.
 func (s *SubdomainRequest) Clone() pipeline.Data {
 	return &SubdomainRequest{
-		Name:    s.Name,
-		Domain:  s.Domain,
-		Records: append([]DNSAnswer(nil), s.Records...),
-		Tag:     s.Tag,
-		Source:  s.Source,
+		Name:       s.Name,
+		Domain:     s.Domain,
+		Records:    append([]DNSAnswer(nil), s.Records...),
+		Tag:        s.Tag,
+		Source:     s.Source,
+		Provenance: append([]DiscoveryStep(nil), s.Provenance...),
+		Depth:      s.Depth,
 	}
 }
 
@@ -332,16 +370,39 @@ type Output struct {
 	Addresses []AddressInfo `json:"addresses"`
 	Tag       string        `json:"tag"`
 	Sources   []string      `json:"sources"`
+	// The Unicode form of an internationalized name, which is provided in the punycode form by Name
+	UnicodeName string `json:"unicode_name,omitempty"`
+	// Set when HTTP verification suspects that the name is a DNS wildcard or parking hit
+	Suspect bool `json:"suspect,omitempty"`
+	// The RFC3339 times when the name was first and last observed across the enumerations
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	// Ranks the name by the interesting keywords in its labels and the signals collected for it
+	Score int `json:"score,omitempty"`
+	// The discovery steps that derived the name, which is empty for names provided directly by a data source
+	Provenance []DiscoveryStep `json:"provenance,omitempty"`
+	// Set when the name was resolved using an authoritative server of the zone
+	Authoritative bool `json:"authoritative,omitempty"`
+	// The DNSSEC validation status of the records resolved for the name
+	DNSSEC string `json:"dnssec,omitempty"`
 }
 
 // Clone implements pipeline Data.
 func (o *Output) Clone() pipeline.Data {
 	return &Output{
-		Name:      o.Name,
-		Domain:    o.Domain,
-		Addresses: append([]AddressInfo(nil), o.Addresses...),
-		Tag:       o.Tag,
-		Sources:   append([]string(nil), o.Sources...),
+		Name:          o.Name,
+		UnicodeName:   o.UnicodeName,
+		Domain:        o.Domain,
+		Addresses:     append([]AddressInfo(nil), o.Addresses...),
+		Tag:           o.Tag,
+		Sources:       append([]string(nil), o.Sources...),
+		Suspect:       o.Suspect,
+		FirstSeen:     o.FirstSeen,
+		LastSeen:      o.LastSeen,
+		Score:         o.Score,
+		Provenance:    append([]DiscoveryStep(nil), o.Provenance...),
+		Authoritative: o.Authoritative,
+		DNSSEC:        o.DNSSEC,
 	}
 }
 
@@ -383,20 +444,35 @@ type AddressInfo struct {
 // TrustedTag returns true when the tag parameter is of a type that should be trusted even
 // facing DNS wildcards.
 func TrustedTag(tag string) bool {
-	if tag == ARCHIVE || tag == AXFR || tag == CERT || tag == CRAWL || tag == DNS {
+	if tag == ARCHIVE || tag == AXFR || tag == CERT || tag == CNAME || tag == CRAWL || tag == DNS {
 		return true
 	}
 	return false
 }
 
+// TrustedSource returns true when names and addresses from the data source should be trusted.
+// The TrustedSources and UntrustedSources settings override the decision made by TrustedTag.
+func TrustedSource(cfg *config.Config, tag, source string) bool {
+	if cfg != nil {
+		if cfg.IsUntrustedSource(source) {
+			return false
+		}
+		if cfg.IsTrustedSource(source) {
+			return true
+		}
+	}
+	return TrustedTag(tag)
+}
+
 // SanitizeDNSRequest cleans the Name and Domain elements of the receiver.
 func SanitizeDNSRequest(req *DNSRequest) {
-	req.Name = strings.ToLower(req.Name)
-	req.Name = strings.TrimSpace(req.Name)
-	req.Name = amassdns.RemoveAsteriskLabel(req.Name)
-	req.Name = strings.Trim(req.Name, ".")
+	// The name is kept in the Unicode form when the conversion fails
+	req.Name, _ = normalizeNameForm(req.Name)
 
 	req.Domain = strings.ToLower(req.Domain)
 	req.Domain = strings.TrimSpace(req.Domain)
 	req.Domain = strings.Trim(req.Domain, ".")
+	if d, err := amassdns.ToASCII(req.Domain); err == nil {
+		req.Domain = d
+	}
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/OWASP/Amass/v3/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -23,8 +24,10 @@ func TestTrustedTag(t *testing.T) {
 		{AXFR, true},
 		{BRUTE, false},
 		{CERT, true},
+		{CNAME, true},
 		{DNS, true},
 		{EXTERNAL, false},
+		{LOCAL, false},
 		{SCRAPE, false},
 	}
 
@@ -35,6 +38,30 @@ func TestTrustedTag(t *testing.T) {
 	}
 }
 
+func TestTrustedSource(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TrustedSources = []string{"PrivateDNS"}
+	cfg.UntrustedSources = []string{"Crtsh"}
+
+	tests := []struct {
+		Tag      string
+		Source   string
+		Expected bool
+	}{
+		{API, "PrivateDNS", true},
+		{API, "privatedns", true},
+		{CERT, "Crtsh", false},
+		{CERT, "CertSpotter", true},
+		{SCRAPE, "Bing", false},
+	}
+
+	for _, test := range tests {
+		if r := TrustedSource(cfg, test.Tag, test.Source); r != test.Expected {
+			t.Errorf("%s (%s) returned %t instead of %t", test.Source, test.Tag, r, test.Expected)
+		}
+	}
+}
+
 func TestDNSRequestClone(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -0,0 +1,104 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OWASP/Amass/v3/format"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client drives enumerations executed by a remote Server.
+type Client struct {
+	cc    grpc.ClientConnInterface
+	token string
+}
+
+// NewClient returns a Client that sends requests over the provided connection.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// SetToken provides the token sent with each request to a Server that requires it.
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+func (c *Client) withToken(ctx context.Context) context.Context {
+	if c.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, TokenKey, c.token)
+}
+
+// ResultStream receives the results of an enumeration started by the Client.
+type ResultStream struct {
+	stream grpc.ClientStream
+}
+
+// Start begins a new enumeration on the server and returns the enumeration ID along with
+// the stream of results. Cancelling ctx terminates the remote enumeration.
+func (c *Client) Start(ctx context.Context, req *StartRequest) (string, *ResultStream, error) {
+	stream, err := c.cc.NewStream(c.withToken(ctx), &serviceDesc.Streams[0], startMethod, grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return "", nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return "", nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return "", nil, err
+	}
+
+	md, err := stream.Header()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ids := md.Get(EnumerationIDKey)
+	if len(ids) == 0 {
+		// The server returned an error before the enumeration was started
+		if err := stream.RecvMsg(new(format.JSONLine)); err != nil {
+			return "", nil, err
+		}
+		return "", nil, errors.New("the server did not provide the enumeration ID")
+	}
+	return ids[0], &ResultStream{stream: stream}, nil
+}
+
+// Recv returns the next result. The io.EOF error is returned once the enumeration has completed.
+func (rs *ResultStream) Recv() (*format.JSONLine, error) {
+	line := new(format.JSONLine)
+
+	if err := rs.stream.RecvMsg(line); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// Pause blocks the release of new data into the remote enumeration.
+func (c *Client) Pause(ctx context.Context, id string) error {
+	return c.control(ctx, pauseMethod, &ControlRequest{ID: id})
+}
+
+// Resume releases a remote enumeration previously paused.
+func (c *Client) Resume(ctx context.Context, id string) error {
+	return c.control(ctx, resumeMethod, &ControlRequest{ID: id})
+}
+
+// Stop terminates the remote enumeration. When flush is true, the queued data is allowed
+// to complete the pipeline before the call returns.
+func (c *Client) Stop(ctx context.Context, id string, flush bool) error {
+	return c.control(ctx, stopMethod, &ControlRequest{
+		ID:    id,
+		Flush: flush,
+	})
+}
+
+func (c *Client) control(ctx context.Context, method string, req *ControlRequest) error {
+	return c.cc.Invoke(c.withToken(ctx), method, req, new(ControlResponse), grpc.CallContentSubtype(CodecName))
+}
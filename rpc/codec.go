@@ -0,0 +1,37 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the gRPC content-subtype used by the enumeration service.
+// Clients written in other languages send requests as "application/grpc+json".
+const CodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec encodes the service messages as JSON, which removes the need for
+// generated protocol buffer types.
+type jsonCodec struct{}
+
+// Marshal implements the encoding.Codec interface.
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements the encoding.Codec interface.
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name implements the encoding.Codec interface.
+func (jsonCodec) Name() string {
+	return CodecName
+}
@@ -0,0 +1,380 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/pipeline"
+	"github.com/go-ini/ini"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The number of results buffered before the enumeration waits for the client
+const resultBufferSize = 100
+
+// SystemFunc returns the System used by an enumeration started with the configuration.
+type SystemFunc func(cfg *config.Config) (systems.System, error)
+
+// The settings accepted from the client configurations, by section. The other settings name files,
+// directories or listeners on the server, or the proxy and the URLs the server would send requests to,
+// such as the scope URL, the webhooks and the DNS-over-HTTPS resolvers.
+var clientSettings = map[string][]string{
+	"": {
+		"mode", "maximum_dns_queries", "ipv4_only", "ipv6_only", "filter_type", "filter_ttl", "filter_size",
+		"filter_fp_rate", "minimum_wait_for_data", "maximum_wait_for_data", "max_duration", "ct_tail",
+		"wildcard_cache_ttl", "reverse_dns", "reverse_dns_max_prefix", "vhost_brute", "dns_cache_ttl",
+		"negative_cache_ttl", "dns_cache_size", "ecs_subnets", "query_types", "resolve_repeat",
+		"resolve_repeat_interval", "shared_filter", "shared_filter_ttl", "http_verify", "interesting_keywords",
+		"deterministic", "phased", "zone_transfer_timeout", "request_timeout", "dns_retries", "max_concurrency",
+		"passive_concurrency", "active_concurrency", "max_queries_per_server", "query_authoritative",
+		"validate_dnssec", "dnssec_drop_bogus", "skip_dead_zones", "resolver_eject_threshold",
+		"detect_poisoned_resolvers",
+	},
+	"resolvers":         {"resolver", "exclude_types"},
+	"scope":             {"address", "cidr", "asn", "expand_by_asn", "expand_asn", "cert_org", "port"},
+	"scope.domains":     {"domain"},
+	"scope.blacklisted": {"subdomain"},
+	"scope.excluded":    {"pattern"},
+	"scope.regex":       {"pattern"},
+	"alterations": {
+		"enabled", "flip_words", "add_words", "flip_numbers", "add_numbers", "minimum_for_word_flip",
+		"edit_distance", "template_limit", "template",
+	},
+	"bruteforce": {
+		"enabled", "recursive", "minimum_for_recursive", "max_depth", "max_candidates", "max_rate",
+		"token_expansion", "token_expansion_limit",
+	},
+	"domain_settings.*": {"brute_forcing", "alterations"},
+	"alerts":            {"pattern"},
+	"output":            {"compress", "max_label_depth", "redact"},
+	"data_sources":      {"minimum_ttl", "tier_threshold", "trusted_only", "max_recursion_depth_untrusted", "header", "user_agent"},
+	"data_sources.*":    {"ttl", "rate_limit", "tier", "header", "user_agent", "data_source"},
+	"data_sources.*.*":  {"username", "password", "apikey", "secret"},
+}
+
+// clientSectionKeys returns the settings accepted from the clients in the section. The sections of
+// the domains and data sources share the settings accepted in each of them.
+func clientSectionKeys(name string) ([]string, bool) {
+	name = strings.ToLower(name)
+	if name == strings.ToLower(ini.DefaultSection) {
+		name = ""
+	}
+
+	switch {
+	case strings.HasPrefix(name, "domain_settings."):
+		name = "domain_settings.*"
+	case strings.HasPrefix(name, "data_sources."):
+		// The sections of the data sources and their credentials
+		name = "data_sources" + strings.Repeat(".*", strings.Count(name, "."))
+	}
+
+	keys, found := clientSettings[name]
+	return keys, found
+}
+
+// Server implements the gRPC enumeration service.
+type Server struct {
+	sync.Mutex
+	newSystem SystemFunc
+	token     string
+	sessions  map[string]*session
+}
+
+type session struct {
+	sync.Mutex
+	enum    *enum.Enumeration
+	cancel  context.CancelFunc
+	stopped bool
+}
+
+// NewServer returns a Server that creates a LocalSystem with all the data sources for each enumeration.
+func NewServer() *Server {
+	return NewServerWithSystem(localSystem)
+}
+
+// NewServerWithSystem returns a Server that obtains the System for each enumeration from f.
+func NewServerWithSystem(f SystemFunc) *Server {
+	return &Server{
+		newSystem: f,
+		sessions:  make(map[string]*session),
+	}
+}
+
+func localSystem(cfg *config.Config) (systems.System, error) {
+	sys, err := systems.NewLocalSystem(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sys.SetDataSources(datasrcs.GetAllSources(sys))
+	return sys, nil
+}
+
+// SetToken requires the clients to provide the token in the request metadata under TokenKey.
+func (s *Server) SetToken(token string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.token = token
+}
+
+func (s *Server) authorize(ctx context.Context) error {
+	s.Lock()
+	token := s.token
+	s.Unlock()
+
+	if token == "" {
+		return nil
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, t := range md.Get(TokenKey) {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.Unauthenticated, "the request did not provide a valid token")
+}
+
+// clientConfig returns the configuration provided by a client. Only the settings in clientSettings are
+// accepted, so the clients cannot read or write the files of the server, or have the server send
+// requests to the addresses of their choosing.
+func clientConfig(data []byte) (*config.Config, error) {
+	cfg := config.NewConfig()
+	if len(data) == 0 {
+		return cfg, nil
+	}
+
+	f, err := ini.LoadSources(ini.LoadOptions{
+		Insensitive:  true,
+		AllowShadows: true,
+	}, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the configuration file: %v", err)
+	}
+
+	for _, sec := range f.Sections() {
+		allowed, found := clientSectionKeys(sec.Name())
+		if !found {
+			return nil, fmt.Errorf("the %s section is not accepted from clients", sec.Name())
+		}
+
+	keys:
+		for _, key := range sec.KeyStrings() {
+			for _, a := range allowed {
+				if key == a {
+					continue keys
+				}
+			}
+			return nil, fmt.Errorf("the %s setting is not accepted from clients", key)
+		}
+	}
+
+	if err := cfg.LoadSettingsData(data); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Register adds the enumeration service to the gRPC server.
+func (s *Server) Register(gs *grpc.Server) {
+	gs.RegisterService(&serviceDesc, s)
+}
+
+// Start executes a new enumeration and streams the results to the client until the
+// enumeration completes, the client calls Stop, or the stream context is cancelled.
+// The ID of the enumeration is sent in the header metadata under EnumerationIDKey.
+// The client configuration cannot name files or directories on the server.
+func (s *Server) Start(req *StartRequest, stream grpc.ServerStream) error {
+	if err := s.authorize(stream.Context()); err != nil {
+		return err
+	}
+
+	cfg, err := clientConfig(req.Config)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	cfg.AddDomains(req.Domains...)
+	if len(cfg.Domains()) == 0 && cfg.ScopeURL == "" {
+		return status.Error(codes.InvalidArgument, "no root domain names were provided")
+	}
+	if err := cfg.CheckSettings(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sys, err := s.newSystem(cfg)
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	defer func() { _ = sys.Shutdown() }()
+
+	e := enum.NewEnumeration(cfg, sys)
+	if e == nil {
+		return status.Error(codes.Internal, "failed to setup the enumeration")
+	}
+	defer e.Close()
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	results := make(chan pipeline.Data, resultBufferSize)
+	e.AddOutputHook(func(data pipeline.Data) {
+		select {
+		case results <- data:
+		case <-ctx.Done():
+		}
+	})
+
+	id := cfg.UUID.String()
+	sess := &session{
+		enum:   e,
+		cancel: cancel,
+	}
+	s.addSession(id, sess)
+	defer s.removeSession(id)
+
+	if err := stream.SendHeader(metadata.Pairs(EnumerationIDKey, id)); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- e.Start(ctx) }()
+
+	for {
+		select {
+		case data := <-results:
+			if err := sendResult(stream, cfg, data); err != nil {
+				cancel()
+				<-done
+				return err
+			}
+		case err := <-done:
+			// Deliver the results produced before the enumeration returned
+			for len(results) > 0 {
+				if err := sendResult(stream, cfg, <-results); err != nil {
+					return err
+				}
+			}
+
+			if serr := stream.Context().Err(); serr != nil {
+				return status.FromContextError(serr).Err()
+			}
+			if err != nil && !sess.wasStopped() {
+				return status.Error(codes.Internal, err.Error())
+			}
+			return nil
+		}
+	}
+}
+
+// sendResult streams the result with the labels matching the redact patterns of the configuration replaced.
+func sendResult(stream grpc.ServerStream, cfg *config.Config, data pipeline.Data) error {
+	if line := format.NewJSONLine(format.RedactData(cfg, data)); line != nil {
+		return stream.SendMsg(line)
+	}
+	return nil
+}
+
+// Pause blocks the release of new data into the enumeration pipeline.
+func (s *Server) Pause(ctx context.Context, req *ControlRequest) (*ControlResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	sess, err := s.getSession(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.enum.Pause()
+	return &ControlResponse{}, nil
+}
+
+// Resume releases an enumeration previously paused.
+func (s *Server) Resume(ctx context.Context, req *ControlRequest) (*ControlResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	sess, err := s.getSession(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.enum.Resume()
+	return &ControlResponse{}, nil
+}
+
+// Stop terminates the enumeration. When Flush is requested, the call returns after the
+// queued data has completed the pipeline, or terminates the enumeration when ctx expires.
+func (s *Server) Stop(ctx context.Context, req *ControlRequest) (*ControlResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	sess, err := s.getSession(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.markStopped()
+	if req.Flush {
+		sess.enum.StopAndFlush(ctx)
+	} else {
+		sess.cancel()
+	}
+	return &ControlResponse{}, nil
+}
+
+func (s *Server) addSession(id string, sess *session) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.sessions[id] = sess
+}
+
+func (s *Server) removeSession(id string) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.sessions, id)
+}
+
+func (s *Server) getSession(id string) (*session, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if sess, found := s.sessions[id]; found {
+		return sess, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "enumeration %s was not found", id)
+}
+
+func (sess *session) markStopped() {
+	sess.Lock()
+	defer sess.Unlock()
+
+	sess.stopped = true
+}
+
+func (sess *session) wasStopped() bool {
+	sess.Lock()
+	defer sess.Unlock()
+
+	return sess.stopped
+}
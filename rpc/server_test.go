@@ -0,0 +1,162 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setupTestServer(t *testing.T, f SystemFunc) (*Client, func()) {
+	return setupServer(t, NewServerWithSystem(f))
+}
+
+func setupServer(t *testing.T, s *Server) (*Client, func()) {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	s.Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) { return lis.Dial() }
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Failed to dial the test server: %v", err)
+	}
+
+	return NewClient(conn), func() {
+		conn.Close()
+		gs.Stop()
+	}
+}
+
+func TestStartErrors(t *testing.T) {
+	failed := func(cfg *config.Config) (systems.System, error) {
+		return nil, errors.New("no resolvers")
+	}
+
+	client, cleanup := setupTestServer(t, failed)
+	defer cleanup()
+
+	tests := []struct {
+		name string
+		req  *StartRequest
+		code codes.Code
+	}{
+		{"no domains", &StartRequest{}, codes.InvalidArgument},
+		{"invalid config", &StartRequest{Config: []byte("[scope"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"system failure", &StartRequest{Domains: []string{"owasp.org"}}, codes.FailedPrecondition},
+		{"server path", &StartRequest{Config: []byte("output_directory = /etc"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"server file", &StartRequest{Config: []byte("[output]\nsink = json:/tmp/amass.json"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"wordlist", &StartRequest{Config: []byte("[bruteforce]\nwordlist_file = /etc/passwd"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"graph database", &StartRequest{Config: []byte("[graphdbs]\ntype = local"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		// The settings that have the server send requests to the addresses chosen by the client
+		{"proxy", &StartRequest{Config: []byte("proxy = http://10.0.0.1:3128"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"scope url", &StartRequest{Config: []byte("[scope]\nurl = http://169.254.169.254/"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"webhook", &StartRequest{Config: []byte("[webhook]\nurl = http://10.0.0.1/"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"alert webhook", &StartRequest{Config: []byte("[alerts]\nwebhook = http://10.0.0.1/"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"doh resolver", &StartRequest{Config: []byte("[resolvers]\ndoh_resolver = https://10.0.0.1/"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"listener", &StartRequest{Config: []byte("api_addr = 0.0.0.0:8080"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"unknown section", &StartRequest{Config: []byte("[elasticsearch]\nurl = http://10.0.0.1:9200"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"domain wordlist", &StartRequest{Config: []byte("[domain_settings.owasp.org]\nwordlist_file = /etc/passwd"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		{"unknown setting", &StartRequest{Config: []byte("unknown_setting = 1"), Domains: []string{"owasp.org"}}, codes.InvalidArgument},
+		// The settings accepted from the clients reach the creation of the system
+		{"allowed settings", &StartRequest{Config: []byte("mode = passive\n[scope]\naddress = 192.0.2.1\n" +
+			"[domain_settings.owasp.org]\nbrute_forcing = false\n[data_sources]\n[data_sources.Shodan]\nttl = 60\n" +
+			"[data_sources.Shodan.Credentials]\napikey = secret\n[resolvers]\nresolver = 8.8.8.8"),
+			Domains: []string{"owasp.org"}}, codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		_, _, err := client.Start(context.Background(), tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: Start returned %v, expected the %s code", tt.name, err, tt.code)
+		}
+	}
+}
+
+func TestControlUnknownEnumeration(t *testing.T) {
+	client, cleanup := setupTestServer(t, nil)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.Pause(ctx, "unknown"); status.Code(err) != codes.NotFound {
+		t.Errorf("Pause returned %v for an unknown enumeration", err)
+	}
+	if err := client.Resume(ctx, "unknown"); status.Code(err) != codes.NotFound {
+		t.Errorf("Resume returned %v for an unknown enumeration", err)
+	}
+	if err := client.Stop(ctx, "unknown", true); status.Code(err) != codes.NotFound {
+		t.Errorf("Stop returned %v for an unknown enumeration", err)
+	}
+}
+
+func TestTokenRequired(t *testing.T) {
+	s := NewServerWithSystem(nil)
+	s.SetToken("secret")
+
+	client, cleanup := setupServer(t, s)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.Pause(ctx, "unknown"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Pause returned %v without the token", err)
+	}
+	if _, _, err := client.Start(ctx, &StartRequest{Domains: []string{"owasp.org"}}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Start returned %v without the token", err)
+	}
+
+	client.SetToken("wrong")
+	if err := client.Resume(ctx, "unknown"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Resume returned %v with the wrong token", err)
+	}
+
+	client.SetToken("secret")
+	if err := client.Stop(ctx, "unknown", false); status.Code(err) != codes.NotFound {
+		t.Errorf("Stop returned %v with the token", err)
+	}
+}
+
+// recordedStream records the messages sent to the client.
+type recordedStream struct {
+	grpc.ServerStream
+	msgs []interface{}
+}
+
+func (s *recordedStream) SendMsg(m interface{}) error {
+	s.msgs = append(s.msgs, m)
+	return nil
+}
+
+func TestSendResultRedacted(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.RedactPatterns = []string{"*-int"}
+
+	stream := new(recordedStream)
+	req := &requests.DNSRequest{Name: "vpn-int.owasp.org", Domain: "owasp.org", Tag: requests.DNS, Source: "DNS"}
+	if err := sendResult(stream, cfg, req); err != nil {
+		t.Fatalf("sendResult failed: %v", err)
+	}
+
+	if len(stream.msgs) != 1 {
+		t.Fatalf("%d messages were sent for the result", len(stream.msgs))
+	}
+	if line := stream.msgs[0].(*format.JSONLine); line.Name != "redacted.owasp.org" {
+		t.Errorf("the result was streamed with the name %s", line.Name)
+	}
+	if req.Name != "vpn-int.owasp.org" {
+		t.Errorf("the result shared with the other outputs was modified as %s", req.Name)
+	}
+}
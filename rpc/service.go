@@ -0,0 +1,106 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	serviceName = "amass.Enumeration"
+
+	startMethod  = "/" + serviceName + "/Start"
+	pauseMethod  = "/" + serviceName + "/Pause"
+	resumeMethod = "/" + serviceName + "/Resume"
+	stopMethod   = "/" + serviceName + "/Stop"
+
+	// EnumerationIDKey is the header metadata key that carries the ID of a started enumeration.
+	EnumerationIDKey = "amass-enumeration-id"
+
+	// TokenKey is the request metadata key that carries the token required by the Server.
+	TokenKey = "amass-token"
+)
+
+// StartRequest is the message sent to begin a new enumeration.
+type StartRequest struct {
+	// The contents of an .ini configuration file
+	Config []byte `json:"config,omitempty"`
+
+	// Root domain names added to the domains in the configuration
+	Domains []string `json:"domains,omitempty"`
+}
+
+// ControlRequest is the message sent to pause, resume or stop an enumeration.
+type ControlRequest struct {
+	ID string `json:"id"`
+
+	// Stop the enumeration after the queued data has completed the pipeline
+	Flush bool `json:"flush,omitempty"`
+}
+
+// ControlResponse is the message returned by the control methods.
+type ControlResponse struct{}
+
+type controlFunc func(s *Server, ctx context.Context, req *ControlRequest) (*ControlResponse, error)
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Pause",
+			Handler:    controlHandler(pauseMethod, (*Server).Pause),
+		},
+		{
+			MethodName: "Resume",
+			Handler:    controlHandler(resumeMethod, (*Server).Resume),
+		},
+		{
+			MethodName: "Stop",
+			Handler:    controlHandler(stopMethod, (*Server).Stop),
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Start",
+			Handler:       startHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+func startHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(StartRequest)
+
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(*Server).Start(req, stream)
+}
+
+func controlHandler(method string, f controlFunc) func(interface{}, context.Context,
+	func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := new(ControlRequest)
+
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return f(srv.(*Server), ctx, req)
+		}
+
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: method,
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return f(srv.(*Server), ctx, req.(*ControlRequest))
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+)
+
+// authoritativeResolver is a Resolver that sends the queries for the names in each zone of the scope
+// to the authoritative servers of the zone, which are discovered by resolving the NS records of the
+// zone and the addresses of the servers using the wrapped resolver. The queries are performed by the
+// wrapped resolver when the zone has no reachable servers, or the servers fail to answer.
+type authoritativeResolver struct {
+	resolve.Resolver
+	sync.Mutex
+	zoneOf    func(name string) string
+	newServer func(addr string) resolve.Resolver
+	zones     map[string]resolve.Resolver
+}
+
+func newAuthoritativeResolver(r resolve.Resolver, zoneOf func(name string) string,
+	proxy *amassnet.Proxy, logger *log.Logger) *authoritativeResolver {
+	return &authoritativeResolver{
+		Resolver: r,
+		zoneOf:   zoneOf,
+		newServer: func(addr string) resolve.Resolver {
+			return newUpstreamResolver(addr, nil, proxy, config.DefaultQueriesPerBaselineResolver, logger)
+		},
+		zones: make(map[string]resolve.Resolver),
+	}
+}
+
+// Stop implements the Resolver interface.
+func (ar *authoritativeResolver) Stop() {
+	ar.Lock()
+	for _, r := range ar.zones {
+		if r != nil {
+			r.Stop()
+		}
+	}
+	ar.zones = make(map[string]resolve.Resolver)
+	ar.Unlock()
+
+	ar.Resolver.Stop()
+}
+
+// Query implements the Resolver interface.
+func (ar *authoritativeResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if len(msg.Question) != 1 {
+		return ar.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	zone := strings.ToLower(ar.zoneOf(resolve.RemoveLastDot(msg.Question[0].Name)))
+	if zone == "" {
+		return ar.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	if servers := ar.servers(ctx, zone); servers != nil {
+		resp, err := servers.Query(ctx, msg, priority, retry)
+		// A nonexistent name is an answer provided by the authoritative servers
+		if err == nil && resp != nil && (resp.Rcode == dns.RcodeSuccess || resp.Rcode == dns.RcodeNameError) {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return ar.Resolver.Query(ctx, msg, priority, retry)
+}
+
+// servers returns the resolver that sends queries to the authoritative servers of the zone,
+// or nil when none of the servers were discovered.
+func (ar *authoritativeResolver) servers(ctx context.Context, zone string) resolve.Resolver {
+	ar.Lock()
+	r, found := ar.zones[zone]
+	ar.Unlock()
+	if found {
+		return r
+	}
+
+	// The servers are discovered without holding the lock
+	addrs := ar.serverAddrs(ctx, zone)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	var resolvers []resolve.Resolver
+	for _, addr := range addrs {
+		if s := ar.newServer(addr); s != nil {
+			resolvers = append(resolvers, s)
+		}
+	}
+	if len(resolvers) > 0 {
+		r = resolve.NewResolverPool(resolvers, nil, 1, nil)
+	}
+
+	ar.Lock()
+	defer ar.Unlock()
+
+	if existing, found := ar.zones[zone]; found {
+		if r != nil {
+			r.Stop()
+		}
+		return existing
+	}
+	ar.zones[zone] = r
+	return r
+}
+
+func (ar *authoritativeResolver) serverAddrs(ctx context.Context, zone string) []string {
+	resp, err := ar.Resolver.Query(ctx, resolve.QueryMsg(zone, dns.TypeNS), resolve.PriorityHigh, resolve.PoolRetryPolicy)
+	if err != nil || resp == nil {
+		return nil
+	}
+
+	addrs := stringset.New()
+	defer addrs.Close()
+
+	for _, a := range resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypeNS) {
+		host := resolve.RemoveLastDot(strings.TrimSpace(a.Data))
+		if host == "" {
+			continue
+		}
+
+		for _, t := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			resp, err := ar.Resolver.Query(ctx, resolve.QueryMsg(host, t), resolve.PriorityHigh, resolve.PoolRetryPolicy)
+			if err != nil || resp == nil {
+				continue
+			}
+
+			for _, rr := range resolve.AnswersByType(resolve.ExtractAnswers(resp), t) {
+				addrs.Insert(strings.TrimSpace(rr.Data))
+			}
+		}
+	}
+
+	servers := addrs.Slice()
+	sort.Strings(servers)
+	return servers
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// zoneServer answers the queries as the authoritative server at the address, or fails them.
+type zoneServer struct {
+	addr string
+	fail bool
+}
+
+func (r *zoneServer) String() string { return r.addr }
+func (r *zoneServer) Len() int       { return 0 }
+func (r *zoneServer) Stop()          {}
+func (r *zoneServer) Stopped() bool  { return false }
+
+func (r *zoneServer) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if r.fail {
+		return nil, errors.New("the server did not respond")
+	}
+
+	resp := msg.Copy()
+	resp.Response = true
+	resp.Authoritative = true
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   []byte{10, 0, 0, 1},
+	})
+	return resp, nil
+}
+
+func (r *zoneServer) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+// recursiveResolver answers the NS and address queries for the servers of owasp.org.
+type recursiveResolver struct {
+	nsResolver
+}
+
+func (r *recursiveResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	q := msg.Question[0]
+	if q.Qtype == dns.TypeA && q.Name == "ns1.owasp.org." {
+		resp := msg.Copy()
+		resp.Response = true
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   []byte{192, 168, 1, 53},
+		})
+		return resp, nil
+	}
+	return r.nsResolver.Query(ctx, msg, priority, retry)
+}
+
+func TestAuthoritativeResolver(t *testing.T) {
+	r := &recursiveResolver{nsResolver{servers: map[string][]string{"owasp.org": {"ns1.owasp.org"}}}}
+
+	for _, fail := range []bool{false, true} {
+		var addrs []string
+		ar := newAuthoritativeResolver(r, zoneOf, nil, nil)
+		ar.newServer = func(addr string) resolve.Resolver {
+			addrs = append(addrs, addr)
+			return &zoneServer{addr: addr, fail: fail}
+		}
+
+		resp, err := ar.Query(context.Background(), resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+		if err != nil || resp == nil {
+			t.Fatalf("Fail %t: the query for the in-scope name failed: %v", fail, err)
+		}
+		if len(addrs) != 1 || addrs[0] != "192.168.1.53" {
+			t.Errorf("Fail %t: the authoritative servers of the zone were not discovered: %v", fail, addrs)
+		}
+		if resp.Authoritative == fail {
+			t.Errorf("Fail %t: the query was answered by the wrong resolver", fail)
+		}
+
+		// The names outside the scope are not sent to the authoritative servers
+		resp, err = ar.Query(context.Background(), resolve.QueryMsg("www.google.com", dns.TypeA), resolve.PriorityNormal, nil)
+		if err != nil || resp == nil || resp.Authoritative {
+			t.Errorf("Fail %t: the query for the out of scope name was not performed by the resolvers", fail)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/limits"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const (
+	// DefaultBenchmarkQueries is the number of queries sent to each resolver by a benchmark
+	DefaultBenchmarkQueries = 100
+	// The number of benchmark queries each resolver has in flight
+	benchmarkInFlight = 10
+)
+
+// BenchmarkResult provides the throughput, loss and latency measured for a resolver,
+// or for all the resolvers of the pool together.
+type BenchmarkResult struct {
+	Resolver string
+	Queries  int
+	Lost     int
+	Elapsed  time.Duration
+	// The answered queries per second
+	QPS float64
+	// The fraction of the queries that failed or timed out
+	Loss float64
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+}
+
+// BenchmarkResolvers sends a burst of queries for random names that do not exist to each resolver
+// the configuration would use in the resolver pool, and returns the result measured for each resolver,
+// sorted by the resolver addresses, along with the aggregate result of the pool. The resolvers are
+// measured concurrently, so the aggregate result reflects the throughput the pool sustains.
+func BenchmarkResolvers(ctx context.Context, c *config.Config, queries int) ([]*BenchmarkResult, *BenchmarkResult, error) {
+	if err := c.CheckSettings(); err != nil {
+		return nil, nil, err
+	}
+	proxy, err := c.ProxyDialer()
+	if err != nil {
+		return nil, nil, err
+	}
+	if queries <= 0 {
+		queries = DefaultBenchmarkQueries
+	}
+
+	resolvers := benchmarkResolverSetup(c, proxy)
+	if len(resolvers) == 0 {
+		return nil, nil, errors.New("the system was unable to build the pool of resolvers")
+	}
+	defer func() {
+		for _, r := range resolvers {
+			r.Stop()
+		}
+	}()
+
+	results, total := benchmarkResolvers(ctx, resolvers, queries, canaryName)
+	return results, total, nil
+}
+
+// benchmarkResolverSetup returns the resolvers that the pool built for the configuration would contain.
+func benchmarkResolverSetup(c *config.Config, proxy *amassnet.Proxy) []resolve.Resolver {
+	max := int(float64(limits.GetFileLimit()) * 0.7)
+
+	if len(c.Resolvers) == 0 && len(c.DoHResolvers) == 0 {
+		addrs := config.PublicResolvers
+		if len(addrs) == 0 {
+			addrs = config.DefaultBaselineResolvers
+		}
+		return setupResolvers(addrs, max, config.DefaultQueriesPerPublicResolver, nil, proxy, c.Log)
+	}
+
+	var resolvers []resolve.Resolver
+	for _, addr := range c.Resolvers {
+		if r := newUpstreamResolver(addr, nil, proxy, config.DefaultQueriesPerPublicResolver, c.Log); r != nil {
+			resolvers = append(resolvers, r)
+		}
+	}
+	for _, u := range c.DoHResolvers {
+		if r := NewDoHResolver(u, proxy, config.DefaultQueriesPerPublicResolver, c.Log); r != nil {
+			resolvers = append(resolvers, r)
+		}
+	}
+	return resolvers
+}
+
+func benchmarkResolvers(ctx context.Context, resolvers []resolve.Resolver,
+	queries int, name func() string) ([]*BenchmarkResult, *BenchmarkResult) {
+	var wg sync.WaitGroup
+	results := make([]*BenchmarkResult, len(resolvers))
+	latencies := make([][]time.Duration, len(resolvers))
+
+	start := time.Now()
+	for i, r := range resolvers {
+		wg.Add(1)
+		go func(i int, r resolve.Resolver) {
+			defer wg.Done()
+
+			results[i], latencies[i] = benchmarkResolver(ctx, r, queries, name)
+		}(i, r)
+	}
+	wg.Wait()
+
+	total := &BenchmarkResult{Elapsed: time.Since(start)}
+	var all []time.Duration
+	for i, res := range results {
+		total.Queries += res.Queries
+		total.Lost += res.Lost
+		all = append(all, latencies[i]...)
+	}
+	measureBenchmark(total, all)
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Resolver < results[j].Resolver
+	})
+	return results, total
+}
+
+// benchmarkResolver returns the result measured for the resolver and the latencies of the answered queries.
+func benchmarkResolver(ctx context.Context, r resolve.Resolver, queries int, name func() string) (*BenchmarkResult, []time.Duration) {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var latencies []time.Duration
+	res := &BenchmarkResult{
+		Resolver: r.String(),
+		Queries:  queries,
+	}
+
+	sem := make(chan struct{}, benchmarkInFlight)
+	start := time.Now()
+	for i := 0; i < queries; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem }()
+			defer wg.Done()
+
+			qstart := time.Now()
+			_, err := r.Query(ctx, resolve.QueryMsg(name(), dns.TypeA), resolve.PriorityHigh, nil)
+			latency := time.Since(qstart)
+
+			lock.Lock()
+			defer lock.Unlock()
+			// The nonexistent names are answered successfully with NXDOMAIN responses
+			if queryFailed(err) {
+				res.Lost++
+				return
+			}
+			latencies = append(latencies, latency)
+		}()
+	}
+	wg.Wait()
+
+	res.Elapsed = time.Since(start)
+	measureBenchmark(res, latencies)
+	return res, latencies
+}
+
+// measureBenchmark computes the throughput, loss and latency percentiles of the result
+// from the queries, losses and elapsed time already recorded.
+func measureBenchmark(res *BenchmarkResult, latencies []time.Duration) {
+	if res.Queries > 0 {
+		res.Loss = float64(res.Lost) / float64(res.Queries)
+	}
+	if secs := res.Elapsed.Seconds(); secs > 0 {
+		res.QPS = float64(res.Queries-res.Lost) / secs
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	res.P50 = percentile(latencies, 0.5)
+	res.P90 = percentile(latencies, 0.9)
+	res.P99 = percentile(latencies, 0.99)
+}
+
+// percentile returns the latency at the percentile p of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
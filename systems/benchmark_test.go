@@ -0,0 +1,49 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+)
+
+func TestBenchmarkResolvers(t *testing.T) {
+	good := &flakyResolver{name: "good"}
+	bad := &flakyResolver{name: "bad", failing: true}
+
+	results, total := benchmarkResolvers(context.Background(), []resolve.Resolver{good, bad}, 20, canaryName)
+	if len(results) != 2 || results[0].Resolver != "bad" || results[1].Resolver != "good" {
+		t.Fatalf("The results were not returned for each resolver: %+v", results)
+	}
+
+	if res := results[0]; res.Queries != 20 || res.Lost != 20 || res.Loss != 1 || res.QPS != 0 {
+		t.Errorf("The failing resolver was measured as %+v", res)
+	}
+	if res := results[1]; res.Lost != 0 || res.Loss != 0 || res.QPS <= 0 {
+		t.Errorf("The healthy resolver was measured as %+v", res)
+	}
+	if total.Queries != 40 || total.Lost != 20 || total.Loss != 0.5 || total.QPS <= 0 {
+		t.Errorf("The aggregate result was measured as %+v", total)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+
+	if p := percentile(sorted, 0.5); p != 50*time.Millisecond {
+		t.Errorf("The 50th percentile was %v", p)
+	}
+	if p := percentile(sorted, 0.99); p != 99*time.Millisecond {
+		t.Errorf("The 99th percentile was %v", p)
+	}
+	if p := percentile(nil, 0.5); p != 0 {
+		t.Errorf("The percentile of no latencies was %v", p)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+type bypassCacheKey struct{}
+
+// BypassQueryCache returns a context that causes the DNS queries performed with it to reach the
+// resolvers, even when a cached response exists. The fresh response replaces the cached one.
+func BypassQueryCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func bypassingQueryCache(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+type cacheKey struct {
+	name  string
+	qtype uint16
+	// Responses to queries requesting DNSSEC records differ from the others
+	dnssec bool
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	resp    *dns.Msg
+	err     error
+	expires time.Time
+}
+
+// QueryCacheStats provides the counters of the DNS query cache.
+type QueryCacheStats struct {
+	// The queries answered from the cache, and those sent to the resolvers
+	Hits   int
+	Misses int
+	// The NXDOMAIN verdicts reused from the cache, and those returned by the resolvers
+	NegativeHits   int
+	NegativeMisses int
+}
+
+// queryCache is a Resolver that reuses the responses of the wrapped Resolver for the TTL, so
+// names generated by several stages of the enumeration are only resolved once. NXDOMAIN
+// responses are cached for the negative TTL, or the shorter negative caching TTL of the zone,
+// while transient errors are not cached.
+type queryCache struct {
+	sync.Mutex
+	resolve.Resolver
+	ttl     time.Duration
+	negTTL  time.Duration
+	size    int
+	order   *list.List
+	entries map[cacheKey]*list.Element
+	stats   QueryCacheStats
+}
+
+func newQueryCache(r resolve.Resolver, ttl, negTTL time.Duration, size int) *queryCache {
+	return &queryCache{
+		Resolver: r,
+		ttl:      ttl,
+		negTTL:   negTTL,
+		size:     size,
+		order:    list.New(),
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+// Stats returns the counters of the cache. Nil is returned when the cache is not used.
+func (qc *queryCache) Stats() *QueryCacheStats {
+	if qc == nil {
+		return nil
+	}
+
+	qc.Lock()
+	defer qc.Unlock()
+
+	stats := qc.stats
+	return &stats
+}
+
+// Query implements the Resolver interface.
+func (qc *queryCache) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if len(msg.Question) != 1 {
+		return qc.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	key := queryCacheKey(msg)
+	if !bypassingQueryCache(ctx) {
+		if resp, err, found := qc.get(key); found {
+			if resp != nil {
+				resp.Id = msg.Id
+			}
+			return resp, err
+		}
+	}
+
+	resp, err := qc.Resolver.Query(ctx, msg, priority, retry)
+	nxdomain := nxdomainError(err)
+	qc.count(func(stats *QueryCacheStats) {
+		stats.Misses++
+		if nxdomain {
+			stats.NegativeMisses++
+		}
+	})
+
+	if nxdomain {
+		if ttl := negativeTTL(resp, qc.negTTL); ttl > 0 {
+			qc.put(key, resp, err, ttl)
+		}
+	} else if err == nil && resp != nil && qc.ttl > 0 {
+		qc.put(key, resp, err, qc.ttl)
+	}
+	return resp, err
+}
+
+// negativeTTL returns the duration that the NXDOMAIN response can be cached, which is the maximum
+// provided, or the negative caching TTL of the SOA record in the response when it is shorter.
+func negativeTTL(resp *dns.Msg, max time.Duration) time.Duration {
+	if resp == nil {
+		return max
+	}
+
+	for _, rr := range resp.Ns {
+		soa, ok := rr.(*dns.SOA)
+		if !ok {
+			continue
+		}
+		// The negative caching TTL is the smaller of the SOA TTL and MINIMUM fields (RFC 2308)
+		secs := soa.Minttl
+		if soa.Hdr.Ttl < secs {
+			secs = soa.Hdr.Ttl
+		}
+		if ttl := time.Duration(secs) * time.Second; ttl < max {
+			return ttl
+		}
+		break
+	}
+	return max
+}
+
+func (qc *queryCache) count(f func(stats *QueryCacheStats)) {
+	qc.Lock()
+	defer qc.Unlock()
+
+	f(&qc.stats)
+}
+
+func queryCacheKey(msg *dns.Msg) cacheKey {
+	var dnssec bool
+	if opt := msg.IsEdns0(); opt != nil {
+		dnssec = opt.Do()
+	}
+
+	return cacheKey{
+		name:   strings.ToLower(dns.Fqdn(msg.Question[0].Name)),
+		qtype:  msg.Question[0].Qtype,
+		dnssec: dnssec,
+	}
+}
+
+func (qc *queryCache) get(key cacheKey) (*dns.Msg, error, bool) {
+	qc.Lock()
+	defer qc.Unlock()
+
+	elem, found := qc.entries[key]
+	if !found {
+		return nil, nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		qc.order.Remove(elem)
+		delete(qc.entries, key)
+		return nil, nil, false
+	}
+
+	qc.stats.Hits++
+	if entry.err != nil {
+		qc.stats.NegativeHits++
+	}
+	qc.order.MoveToFront(elem)
+	if entry.resp == nil {
+		return nil, entry.err, true
+	}
+	// Callers are free to modify the responses they receive
+	return entry.resp.Copy(), entry.err, true
+}
+
+func (qc *queryCache) put(key cacheKey, resp *dns.Msg, err error, ttl time.Duration) {
+	qc.Lock()
+	defer qc.Unlock()
+
+	entry := &cacheEntry{
+		key:     key,
+		err:     err,
+		expires: time.Now().Add(ttl),
+	}
+	if resp != nil {
+		entry.resp = resp.Copy()
+	}
+
+	if elem, found := qc.entries[key]; found {
+		elem.Value = entry
+		qc.order.MoveToFront(elem)
+		return
+	}
+
+	qc.entries[key] = qc.order.PushFront(entry)
+	for qc.order.Len() > qc.size {
+		oldest := qc.order.Back()
+		qc.order.Remove(oldest)
+		delete(qc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// nxdomainError returns true when the query failed because the name does not exist.
+func nxdomainError(err error) bool {
+	var re *resolve.ResolveError
+
+	return errors.As(err, &re) && re.Rcode == dns.RcodeNameError
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// countingResolver returns the rcode for each query and counts the queries received.
+type countingResolver struct {
+	sync.Mutex
+	rcode   int
+	queries int
+	// The SOA record returned in the authority section of the NXDOMAIN responses
+	soa *dns.SOA
+}
+
+func (r *countingResolver) String() string { return "counting" }
+func (r *countingResolver) Len() int       { return 0 }
+func (r *countingResolver) Stop()          {}
+func (r *countingResolver) Stopped() bool  { return false }
+
+func (r *countingResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.queries++
+	resp := msg.Copy()
+	resp.Response = true
+	resp.Rcode = r.rcode
+	if r.rcode == dns.RcodeNameError && r.soa != nil {
+		resp.Ns = append(resp.Ns, r.soa)
+	}
+	if r.rcode != dns.RcodeSuccess {
+		return resp, &resolve.ResolveError{Err: dns.RcodeToString[r.rcode], Rcode: r.rcode}
+	}
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   []byte{192, 168, 1, 1},
+	})
+	return resp, nil
+}
+
+func (r *countingResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func (r *countingResolver) count() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.queries
+}
+
+func TestQueryCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		rcode   int
+		queries int
+	}{
+		{"answers are cached", dns.RcodeSuccess, 1},
+		{"NXDOMAIN is cached", dns.RcodeNameError, 1},
+		{"SERVFAIL is not cached", dns.RcodeServerFailure, 3},
+	}
+
+	for _, tt := range tests {
+		r := &countingResolver{rcode: tt.rcode}
+		qc := newQueryCache(r, time.Minute, time.Minute, 10)
+
+		for i := 0; i < 3; i++ {
+			msg := resolve.QueryMsg("WWW.owasp.org", dns.TypeA)
+			resp, err := qc.Query(context.Background(), msg, resolve.PriorityNormal, nil)
+
+			if (err == nil) != (tt.rcode == dns.RcodeSuccess) {
+				t.Errorf("%s: query %d returned the error %v", tt.name, i, err)
+			}
+			if resp == nil || resp.Id != msg.Id {
+				t.Errorf("%s: query %d did not return a response with the request ID", tt.name, i)
+			}
+		}
+
+		if got := r.count(); got != tt.queries {
+			t.Errorf("%s: the resolver received %d queries, expected %d", tt.name, got, tt.queries)
+		}
+	}
+}
+
+func TestQueryCacheBounds(t *testing.T) {
+	r := &countingResolver{}
+	qc := newQueryCache(r, 50*time.Millisecond, 50*time.Millisecond, 2)
+	ctx := context.Background()
+
+	for _, name := range []string{"a.owasp.org", "b.owasp.org", "c.owasp.org", "a.owasp.org"} {
+		_, _ = qc.Query(ctx, resolve.QueryMsg(name, dns.TypeA), resolve.PriorityNormal, nil)
+	}
+	// The first name was evicted when the third was cached
+	if got := r.count(); got != 4 {
+		t.Errorf("the resolver received %d queries, expected the evicted name to be queried again", got)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	_, _ = qc.Query(ctx, resolve.QueryMsg("a.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	if got := r.count(); got != 5 {
+		t.Errorf("the resolver received %d queries, expected the expired name to be queried again", got)
+	}
+}
+
+func TestQueryCacheBypass(t *testing.T) {
+	r := &countingResolver{}
+	qc := newQueryCache(r, time.Minute, time.Minute, 10)
+	ctx := context.Background()
+
+	_, _ = qc.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	_, _ = qc.Query(BypassQueryCache(ctx), resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	if got := r.count(); got != 2 {
+		t.Errorf("the resolver received %d queries, expected the bypassing query to reach it", got)
+	}
+
+	_, _ = qc.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	if got := r.count(); got != 2 {
+		t.Errorf("the resolver received %d queries, expected the fresh response to be cached", got)
+	}
+}
+
+func TestQueryCacheCopiesResponses(t *testing.T) {
+	qc := newQueryCache(&countingResolver{}, time.Minute, time.Minute, 10)
+	ctx := context.Background()
+
+	resp, _ := qc.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	resp.Answer = nil
+
+	resp, _ = qc.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	if len(resp.Answer) != 1 {
+		t.Errorf("modifying a response changed the cached copy")
+	}
+}
+
+func TestQueryCacheNegativeTTL(t *testing.T) {
+	soa := func(ttl, minttl uint32) *dns.SOA {
+		return &dns.SOA{
+			Hdr:    dns.RR_Header{Name: "owasp.org.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: ttl},
+			Ns:     "ns1.owasp.org.",
+			Mbox:   "admin.owasp.org.",
+			Minttl: minttl,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		soa     *dns.SOA
+		negTTL  time.Duration
+		queries int
+	}{
+		{"the maximum TTL is used without an SOA record", nil, time.Minute, 1},
+		{"the longer SOA TTL is bounded by the maximum", soa(3600, 3600), 50 * time.Millisecond, 2},
+		{"the shorter SOA MINIMUM is honored", soa(3600, 0), time.Minute, 3},
+		{"the shorter SOA TTL is honored", soa(0, 3600), time.Minute, 3},
+		{"negative caching is disabled", nil, 0, 3},
+	}
+
+	for _, tt := range tests {
+		r := &countingResolver{rcode: dns.RcodeNameError, soa: tt.soa}
+		qc := newQueryCache(r, time.Minute, tt.negTTL, 10)
+
+		for i := 0; i < 3; i++ {
+			if i == 2 {
+				time.Sleep(100 * time.Millisecond)
+			}
+			_, _ = qc.Query(context.Background(), resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+		}
+		if got := r.count(); got != tt.queries {
+			t.Errorf("%s: the resolver received %d queries, expected %d", tt.name, got, tt.queries)
+		}
+
+		stats := qc.Stats()
+		if stats.Misses != tt.queries || stats.NegativeMisses != tt.queries ||
+			stats.Hits != 3-tt.queries || stats.NegativeHits != 3-tt.queries {
+			t.Errorf("%s: the cache counters were %+v", tt.name, stats)
+		}
+	}
+
+	var qc *queryCache
+	if qc.Stats() != nil {
+		t.Error("Counters were returned without a cache")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The in-flight query limit that adaptive concurrency starts with
+	adaptiveInitialLimit = 10
+	// The in-flight query limit is never lowered below this value
+	adaptiveMinLimit = 1
+	// The fraction of failed queries in a window that causes the limit to be lowered
+	adaptiveLossThreshold = 0.05
+	// The limit is lowered when the average latency of a window exceeds the lowest observed by this factor
+	adaptiveLatencyFactor = 2.0
+	// The factor applied to the limit when backing off
+	adaptiveBackoff = 0.7
+	// The fewest queries that complete a window
+	adaptiveMinWindow = 10
+)
+
+// ConcurrencyStats provides the current state of the adaptive concurrency controller.
+type ConcurrencyStats struct {
+	// The number of queries allowed to be in flight at once
+	Limit int
+	// The ceiling provided by the configuration
+	Max int
+	// The number of queries currently in flight
+	InFlight int
+	// The fraction of queries that failed during the last window
+	ErrorRate float64
+	// The average query latency during the last window
+	Latency time.Duration
+}
+
+// adaptiveResolver is a Resolver that limits the queries in flight, raising and lowering the
+// limit like TCP congestion control: the limit doubles for each window of queries until the
+// first loss, then grows by one for each window with a low error rate and latency, and shrinks
+// multiplicatively when the error rate or latency of a window shows the resolvers are overloaded.
+type adaptiveResolver struct {
+	resolve.Resolver
+	sync.Mutex
+	cond      *sync.Cond
+	max       int
+	limit     float64
+	slowStart bool
+	inflight  int
+	// The measurements for the window in progress
+	count int
+	errs  int
+	total time.Duration
+	// The baseline latency and the measurements of the last window
+	minLat  time.Duration
+	lastErr float64
+	lastLat time.Duration
+}
+
+func newAdaptiveResolver(r resolve.Resolver, max int) *adaptiveResolver {
+	limit := adaptiveInitialLimit
+	if limit > max {
+		limit = max
+	}
+
+	a := &adaptiveResolver{
+		Resolver:  r,
+		max:       max,
+		limit:     float64(limit),
+		slowStart: true,
+	}
+	a.cond = sync.NewCond(&a.Mutex)
+	return a
+}
+
+// Query implements the Resolver interface.
+func (a *adaptiveResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if !a.acquire(ctx) {
+		return nil, &resolve.ResolveError{Err: ctx.Err().Error(), Rcode: resolve.TimeoutRcode}
+	}
+
+	start := time.Now()
+	resp, err := a.Resolver.Query(ctx, msg, priority, retry)
+	a.release(time.Since(start), transientError(err))
+	return resp, err
+}
+
+// acquire blocks until a query can be sent without exceeding the limit, or the context expires.
+func (a *adaptiveResolver) acquire(ctx context.Context) bool {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.inflight < int(a.limit) {
+		a.inflight++
+		return true
+	}
+	// Wake the waiting queries when the context expires, so they can give up their place
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			a.Lock()
+			a.cond.Broadcast()
+			a.Unlock()
+		case <-stop:
+		}
+	}()
+
+	for a.inflight >= int(a.limit) {
+		if ctx.Err() != nil {
+			return false
+		}
+		a.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	a.inflight++
+	return true
+}
+
+// release records the outcome of a query and adjusts the limit at the end of each window.
+func (a *adaptiveResolver) release(latency time.Duration, failed bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.inflight--
+	a.count++
+	a.total += latency
+	if failed {
+		a.errs++
+	}
+
+	if a.count >= a.window() {
+		a.adjust()
+	}
+	a.cond.Broadcast()
+}
+
+// window returns the number of queries measured before the limit is adjusted, which
+// is roughly the queries sent during one round trip at the current limit.
+func (a *adaptiveResolver) window() int {
+	if w := int(a.limit); w > adaptiveMinWindow {
+		return w
+	}
+	return adaptiveMinWindow
+}
+
+func (a *adaptiveResolver) adjust() {
+	a.lastErr = float64(a.errs) / float64(a.count)
+	a.lastLat = a.total / time.Duration(a.count)
+	if a.minLat == 0 || a.lastLat < a.minLat {
+		a.minLat = a.lastLat
+	}
+
+	if slow := float64(a.lastLat) > float64(a.minLat)*adaptiveLatencyFactor; slow || a.lastErr > adaptiveLossThreshold {
+		a.slowStart = false
+		a.limit *= adaptiveBackoff
+		// Move the baseline toward latency that persists, so a slower network is not treated as congestion forever
+		if slow {
+			a.minLat += (a.lastLat - a.minLat) / 8
+		}
+	} else if a.slowStart {
+		a.limit *= 2
+	} else {
+		a.limit++
+	}
+
+	if a.limit < adaptiveMinLimit {
+		a.limit = adaptiveMinLimit
+	}
+	if m := float64(a.max); a.limit > m {
+		a.limit = m
+	}
+	a.count, a.errs, a.total = 0, 0, 0
+}
+
+// Stats returns the current state of the controller.
+func (a *adaptiveResolver) Stats() *ConcurrencyStats {
+	if a == nil {
+		return nil
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	return &ConcurrencyStats{
+		Limit:     int(a.limit),
+		Max:       a.max,
+		InFlight:  a.inflight,
+		ErrorRate: a.lastErr,
+		Latency:   a.lastLat,
+	}
+}
+
+// limitResolver is a Resolver that never allows more than a fixed number of queries in flight.
+type limitResolver struct {
+	resolve.Resolver
+	sem chan struct{}
+}
+
+func newLimitResolver(r resolve.Resolver, max int) *limitResolver {
+	return &limitResolver{
+		Resolver: r,
+		sem:      make(chan struct{}, max),
+	}
+}
+
+// Query implements the Resolver interface.
+func (l *limitResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	select {
+	case <-ctx.Done():
+		return nil, &resolve.ResolveError{Err: ctx.Err().Error(), Rcode: resolve.TimeoutRcode}
+	case l.sem <- struct{}{}:
+	}
+	defer func() { <-l.sem }()
+
+	return l.Resolver.Query(ctx, msg, priority, retry)
+}
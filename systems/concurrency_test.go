@@ -0,0 +1,145 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// peakResolver records the largest number of queries in flight at once.
+type peakResolver struct {
+	sync.Mutex
+	inflight int
+	peak     int
+}
+
+func (r *peakResolver) String() string { return "peak" }
+func (r *peakResolver) Len() int       { return 0 }
+func (r *peakResolver) Stop()          {}
+func (r *peakResolver) Stopped() bool  { return false }
+
+func (r *peakResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	r.inflight++
+	if r.inflight > r.peak {
+		r.peak = r.inflight
+	}
+	r.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	r.Lock()
+	r.inflight--
+	r.Unlock()
+
+	resp := msg.Copy()
+	resp.Response = true
+	return resp, nil
+}
+
+func (r *peakResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestAdaptiveResolverLimits(t *testing.T) {
+	r := &peakResolver{}
+	a := newAdaptiveResolver(r, 20)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = a.Query(context.Background(), resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+		}()
+	}
+	wg.Wait()
+
+	if s := a.Stats(); s.InFlight != 0 {
+		t.Errorf("%d queries remained in flight", s.InFlight)
+	}
+	if r.peak > 20 {
+		t.Errorf("%d queries were in flight with a maximum concurrency of 20", r.peak)
+	}
+}
+
+// completeWindow sends the queries of a window, failing every nth query when n is positive.
+func completeWindow(a *adaptiveResolver, latency time.Duration, n int) {
+	a.Lock()
+	w := a.window()
+	a.Unlock()
+
+	for i := 1; i <= w; i++ {
+		_ = a.acquire(context.Background())
+		a.release(latency, n > 0 && i%n == 0)
+	}
+}
+
+func TestAdaptiveResolverAdjust(t *testing.T) {
+	a := newAdaptiveResolver(&peakResolver{}, 100)
+
+	// The limit doubles for each successful window until the ceiling is reached
+	for i := 0; i < 5; i++ {
+		completeWindow(a, 10*time.Millisecond, 0)
+	}
+	if s := a.Stats(); s.Limit != 100 {
+		t.Errorf("the limit was %d after the successful windows", s.Limit)
+	}
+
+	completeWindow(a, 10*time.Millisecond, 5)
+	if s := a.Stats(); s.Limit != 70 || s.ErrorRate != 0.2 {
+		t.Errorf("the loss did not lower the limit: %+v", s)
+	}
+
+	// Growth is additive once the loss has been observed
+	completeWindow(a, 10*time.Millisecond, 0)
+	if s := a.Stats(); s.Limit != 71 {
+		t.Errorf("the limit was %d after a successful window following the loss", s.Limit)
+	}
+
+	completeWindow(a, 50*time.Millisecond, 0)
+	if s := a.Stats(); s.Limit >= 71 {
+		t.Errorf("the increased latency did not lower the limit: %+v", s)
+	}
+}
+
+func TestAdaptiveResolverCancel(t *testing.T) {
+	a := newAdaptiveResolver(&peakResolver{}, 1)
+	// Occupy the only place for a query in flight
+	if !a.acquire(context.Background()) {
+		t.Fatal("the first query was not permitted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := a.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil); err == nil {
+		t.Error("the query waiting for a place did not fail when the context expired")
+	}
+}
+
+func TestLimitResolver(t *testing.T) {
+	r := &peakResolver{}
+	l := newLimitResolver(r, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = l.Query(context.Background(), resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+		}()
+	}
+	wg.Wait()
+
+	if r.peak > 5 {
+		t.Errorf("%d queries were in flight with an active concurrency of 5", r.peak)
+	}
+}
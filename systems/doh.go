@@ -0,0 +1,242 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const dohMediaType = "application/dns-message"
+
+// dohResolver performs DNS queries against a DNS-over-HTTPS endpoint, as described in RFC 8484.
+type dohResolver struct {
+	sync.Mutex
+	url       string
+	proxy     *amassnet.Proxy
+	client    *http.Client
+	log       *log.Logger
+	done      chan struct{}
+	stopped   bool
+	inflight  int
+	limiter   *time.Ticker
+	wildcards *wildcardDetector
+}
+
+// NewDoHResolver returns a Resolver that sends DNS queries to the DNS-over-HTTPS endpoint at url,
+// through the proxy when one is provided.
+func NewDoHResolver(url string, p *amassnet.Proxy, perSec int, logger *log.Logger) resolve.Resolver {
+	if !strings.HasPrefix(strings.ToLower(url), "https://") {
+		return nil
+	}
+	if perSec <= 0 {
+		perSec = 1
+	}
+
+	r := &dohResolver{
+		url:   url,
+		proxy: p,
+		client: &http.Client{
+			Timeout: resolve.QueryTimeout,
+			Transport: &http.Transport{
+				Proxy:               amassnet.ProxyURL,
+				DialContext:         amassnet.DirectDialContext,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+		log:     logger,
+		done:    make(chan struct{}),
+		limiter: time.NewTicker(time.Second / time.Duration(perSec)),
+	}
+
+	r.wildcards = newWildcardDetector(r, 0, config.NewStdLogger(logger))
+	return r
+}
+
+// String implements the Stringer interface.
+func (r *dohResolver) String() string {
+	return r.url
+}
+
+// Len implements the Resolver interface.
+func (r *dohResolver) Len() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.inflight
+}
+
+// Stop implements the Resolver interface.
+func (r *dohResolver) Stop() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	r.stopped = true
+	r.limiter.Stop()
+	close(r.done)
+}
+
+// Stopped implements the Resolver interface.
+func (r *dohResolver) Stopped() bool {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.stopped
+}
+
+// Query implements the Resolver interface.
+func (r *dohResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if priority != resolve.PriorityCritical && priority != resolve.PriorityHigh &&
+		priority != resolve.PriorityNormal && priority != resolve.PriorityLow {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: invalid priority parameter: %d", priority),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+	if r.Stopped() {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s has been stopped", r.String()),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	var err error
+	var resp *dns.Msg
+	for times := 1; ; times++ {
+		select {
+		case <-ctx.Done():
+			return nil, &resolve.ResolveError{
+				Err:   "Resolver: the request context expired",
+				Rcode: resolve.ResolverErrRcode,
+			}
+		case <-r.done:
+			return nil, &resolve.ResolveError{
+				Err:   fmt.Sprintf("Resolver: %s has been stopped", r.String()),
+				Rcode: resolve.ResolverErrRcode,
+			}
+		case <-r.limiter.C:
+		}
+
+		resp, err = r.exchange(ctx, msg)
+		if err == nil || retry == nil {
+			break
+		}
+
+		failed := resp
+		if failed == nil {
+			failed = msg.Copy()
+			failed.Rcode = err.(*resolve.ResolveError).Rcode
+		}
+		if !retry(times, priority, failed) {
+			break
+		}
+	}
+
+	return resp, err
+}
+
+func (r *dohResolver) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	r.Lock()
+	r.inflight++
+	r.Unlock()
+	defer func() {
+		r.Lock()
+		r.inflight--
+		r.Unlock()
+	}()
+
+	// The DNS message ID should be set to zero to maximize HTTP cache friendliness
+	req := msg.Copy()
+	req.Id = 0
+	wire, err := req.Pack()
+	if err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: failed to pack the DNS message: %v", err),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolve.QueryTimeout)
+	defer cancel()
+
+	hreq, err := http.NewRequestWithContext(amassnet.WithProxy(ctx, r.proxy), http.MethodPost, r.url, bytes.NewReader(wire))
+	if err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: %v", r.url, err),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+	hreq.Header.Set("Content-Type", dohMediaType)
+	hreq.Header.Set("Accept", dohMediaType)
+
+	hresp, err := r.client.Do(hreq)
+	if err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: the query timed out: %v", r.url, err),
+			Rcode: resolve.TimeoutRcode,
+		}
+	}
+	defer hresp.Body.Close()
+
+	if hresp.StatusCode != http.StatusOK {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s returned status code %d", r.url, hresp.StatusCode),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(hresp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: failed to read the response: %v", r.url, err),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	resp := new(dns.Msg)
+	if err := resp.Unpack(body); err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: failed to unpack the response: %v", r.url, err),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	resp.Id = msg.Id
+	if resp.Rcode != dns.RcodeSuccess {
+		return resp, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s returned the %s rcode", r.url, dns.RcodeToString[resp.Rcode]),
+			Rcode: resp.Rcode,
+		}
+	}
+	return resp, nil
+}
+
+// WildcardType implements the Resolver interface.
+func (r *dohResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return r.wildcards.WildcardType(ctx, msg, domain)
+}
+
+// InvalidateWildcard implements the WildcardInvalidator interface.
+func (r *dohResolver) InvalidateWildcard(zone string) {
+	r.wildcards.InvalidateWildcard(zone)
+}
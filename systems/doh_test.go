@@ -0,0 +1,70 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+func TestDoHResolverQuery(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != dohMediaType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		msg := new(dns.Msg)
+		if err := msg.Unpack(body); err != nil || msg.Id != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		resp := new(dns.Msg)
+		resp.SetReply(msg)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP("192.168.1.1"),
+		})
+
+		wire, _ := resp.Pack()
+		w.Header().Set("Content-Type", dohMediaType)
+		_, _ = w.Write(wire)
+	}))
+	defer srv.Close()
+
+	r := NewDoHResolver(srv.URL, nil, 100, log.New(ioutil.Discard, "", 0))
+	if r == nil {
+		t.Fatal("Failed to create the DoH resolver")
+	}
+	defer r.Stop()
+	r.(*dohResolver).client = srv.Client()
+
+	msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+	resp, err := r.Query(context.Background(), msg, resolve.PriorityNormal, nil)
+	if err != nil {
+		t.Fatalf("The DoH query failed: %v", err)
+	}
+	if resp.Id != msg.Id {
+		t.Errorf("The response ID %d did not match the query ID %d", resp.Id, msg.Id)
+	}
+	if ans := resolve.ExtractAnswers(resp); len(ans) != 1 || ans[0].Data != "192.168.1.1" {
+		t.Errorf("The DoH query returned unexpected answers: %v", resp.Answer)
+	}
+}
+
+func TestNewDoHResolverRequiresHTTPS(t *testing.T) {
+	if r := NewDoHResolver("http://dns.google/dns-query", nil, 10, log.New(ioutil.Discard, "", 0)); r != nil {
+		t.Errorf("A DoH resolver was created for a URL without the https scheme")
+	}
+}
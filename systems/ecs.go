@@ -0,0 +1,98 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"net"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// ecsResolver is a Resolver that performs the address queries of the wrapped Resolver again
+// with the EDNS Client Subnet option set to each of the subnets, and returns the union of the
+// records. Targets that answer differently for each client geography reveal their regional
+// infrastructure this way, when the resolvers forward the option to the authoritative servers.
+type ecsResolver struct {
+	resolve.Resolver
+	subnets []*net.IPNet
+}
+
+func newECSResolver(r resolve.Resolver, subnets []string) resolve.Resolver {
+	var nets []*net.IPNet
+
+	for _, s := range subnets {
+		if _, ipnet, err := net.ParseCIDR(s); err == nil {
+			nets = append(nets, ipnet)
+		}
+	}
+
+	return &ecsResolver{
+		Resolver: r,
+		subnets:  nets,
+	}
+}
+
+// Query implements the Resolver interface.
+func (er *ecsResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp, err := er.Resolver.Query(ctx, msg, priority, retry)
+	if len(msg.Question) != 1 || (msg.Question[0].Qtype != dns.TypeA && msg.Question[0].Qtype != dns.TypeAAAA) {
+		return resp, err
+	}
+
+	for _, subnet := range er.subnets {
+		select {
+		case <-ctx.Done():
+			return resp, err
+		default:
+		}
+
+		r, e := er.Resolver.Query(ctx, ecsMsg(msg, subnet), priority, retry)
+		if e != nil || r == nil {
+			continue
+		}
+		// The first successful response is used when the query without the option failed
+		if err != nil || resp == nil {
+			resp, err = r, nil
+			resp.Id = msg.Id
+			continue
+		}
+
+		resp.Answer = dns.Dedup(append(resp.Answer, r.Answer...), nil)
+	}
+	return resp, err
+}
+
+// ecsMsg returns a copy of the message with the EDNS Client Subnet option set to the subnet.
+func ecsMsg(msg *dns.Msg, subnet *net.IPNet) *dns.Msg {
+	m := msg.Copy()
+	m.Id = dns.Id()
+
+	family, addr := uint16(1), subnet.IP.To4()
+	if addr == nil {
+		family, addr = 2, subnet.IP.To16()
+	}
+	ones, _ := subnet.Mask.Size()
+
+	opt := m.IsEdns0()
+	if opt == nil {
+		m.SetEdns0(dns.DefaultMsgSize, false)
+		opt = m.IsEdns0()
+	}
+
+	var options []dns.EDNS0
+	for _, o := range opt.Option {
+		if o.Option() != dns.EDNS0SUBNET {
+			options = append(options, o)
+		}
+	}
+	opt.Option = append(options, &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        family,
+		SourceNetmask: uint8(ones),
+		Address:       addr,
+	})
+	return m
+}
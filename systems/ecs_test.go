@@ -0,0 +1,79 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// geoResolver answers address queries with the address assigned to the client subnet.
+type geoResolver struct {
+	answers map[string]string
+}
+
+func (r *geoResolver) String() string { return "geo" }
+func (r *geoResolver) Len() int       { return 0 }
+func (r *geoResolver) Stop()          {}
+func (r *geoResolver) Stopped() bool  { return false }
+
+func (r *geoResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	subnet := "0.0.0.0"
+	if opt := msg.IsEdns0(); opt != nil {
+		for _, o := range opt.Option {
+			if e, ok := o.(*dns.EDNS0_SUBNET); ok {
+				subnet = e.Address.String()
+			}
+		}
+	}
+
+	resp := msg.Copy()
+	resp.Response = true
+	if addr, found := r.answers[subnet]; found {
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP(addr),
+		})
+	}
+	return resp, nil
+}
+
+func (r *geoResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestECSResolver(t *testing.T) {
+	r := &geoResolver{answers: map[string]string{
+		"0.0.0.0":      "192.0.2.1",
+		"198.51.100.0": "192.0.2.2",
+		"203.0.113.0":  "192.0.2.1",
+	}}
+	er := newECSResolver(r, []string{"198.51.100.0/24", "203.0.113.0/24"})
+
+	msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+	resp, err := er.Query(context.Background(), msg, resolve.PriorityNormal, nil)
+	if err != nil {
+		t.Fatalf("the query returned the error %v", err)
+	}
+	if resp.Id != msg.Id {
+		t.Errorf("the response did not have the request ID")
+	}
+
+	got := make(map[string]bool)
+	for _, a := range resolve.ExtractAnswers(resp) {
+		got[a.Data] = true
+	}
+	if len(resp.Answer) != 2 || !got["192.0.2.1"] || !got["192.0.2.2"] {
+		t.Errorf("expected the union of the records for each subnet, got %v", resp.Answer)
+	}
+
+	msg = resolve.QueryMsg("www.owasp.org", dns.TypeMX)
+	if resp, _ := er.Query(context.Background(), msg, resolve.PriorityNormal, nil); len(resp.Answer) != 1 {
+		t.Errorf("queries for other types were performed with the client subnets")
+	}
+}
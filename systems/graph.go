@@ -0,0 +1,69 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"fmt"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/netmap"
+	"github.com/cayleygraph/cayley/graph"
+	csql "github.com/cayleygraph/cayley/graph/sql"
+)
+
+// The netmap package opens the SQL graph databases with the sql quad store and the flavor option,
+// while the cayley SQL backend only registers a quad store for each of the flavors
+const sqlQuadStore = "sql"
+
+func init() {
+	if graph.IsRegistered(sqlQuadStore) {
+		return
+	}
+
+	graph.RegisterQuadStore(sqlQuadStore, graph.QuadStoreRegistration{
+		NewFunc: func(addr string, opts graph.Options) (graph.QuadStore, error) {
+			flavor, err := opts.StringKey("flavor", "")
+			if err != nil {
+				return nil, err
+			}
+			return csql.New(flavor, addr, opts)
+		},
+		InitFunc: func(addr string, opts graph.Options) error {
+			flavor, err := opts.StringKey("flavor", "")
+			if err != nil {
+				return err
+			}
+			return csql.Init(flavor, addr, opts)
+		},
+		IsPersistent: true,
+	})
+}
+
+// OpenGraphDatabase returns the graph stored by the backend that the Database identifies, which is
+// the in-memory graph, the local file based graph, a SQLite graph database file, or a PostgreSQL
+// or MySQL graph database.
+func OpenGraphDatabase(db *config.Database) (*netmap.Graph, error) {
+	var cayley *netmap.CayleyGraph
+
+	switch db.System {
+	case "memory":
+		cayley = netmap.NewCayleyGraphMemory()
+	case "sqlite":
+		var err error
+		if cayley, err = newSQLiteGraph(db); err != nil {
+			return nil, err
+		}
+	default:
+		cayley = netmap.NewCayleyGraph(db.System, db.URL, db.Options)
+	}
+	if cayley == nil {
+		return nil, fmt.Errorf("failed to create the %s graph", db.System)
+	}
+
+	g := netmap.NewGraph(cayley)
+	if g == nil {
+		return nil, fmt.Errorf("failed to create the %s graph", db.System)
+	}
+	return g, nil
+}
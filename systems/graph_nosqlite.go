@@ -0,0 +1,19 @@
+//go:build !cgo
+// +build !cgo
+
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"errors"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/netmap"
+)
+
+// newSQLiteGraph returns an error, since the SQLite driver requires cgo.
+func newSQLiteGraph(db *config.Database) (*netmap.CayleyGraph, error) {
+	return nil, errors.New("the sqlite graph database requires a build with cgo enabled")
+}
@@ -0,0 +1,24 @@
+//go:build cgo
+// +build cgo
+
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/netmap"
+	_ "github.com/cayleygraph/cayley/graph/sql/sqlite" // Registers the sqlite flavor of the cayley SQL backend
+)
+
+// newSQLiteGraph opens the SQLite graph database file at the Database URL. The netmap package only
+// selects the SQL backend for the postgres and mysql systems, so the flavor is replaced by the option,
+// and the graph is named after the postgres system.
+func newSQLiteGraph(db *config.Database) (*netmap.CayleyGraph, error) {
+	options := "flavor=sqlite"
+	if db.Options != "" {
+		options += "," + db.Options
+	}
+	return netmap.NewCayleyGraph("postgres", db.URL, options), nil
+}
@@ -0,0 +1,48 @@
+//go:build cgo
+// +build cgo
+
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestOpenGraphDatabaseSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graph")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db := &config.Database{System: "sqlite", URL: filepath.Join(dir, "amass.sqlite")}
+	g, err := OpenGraphDatabase(db)
+	if err != nil {
+		t.Fatalf("The sqlite graph was not opened: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := g.UpsertFQDN(ctx, "www.owasp.org", "DNS", "test"); err != nil {
+		t.Errorf("The name was not inserted into the sqlite graph: %v", err)
+	}
+	g.Close()
+
+	// The graph is kept in the database file
+	g, err = OpenGraphDatabase(db)
+	if err != nil {
+		t.Fatalf("The sqlite graph was not opened again: %v", err)
+	}
+	defer g.Close()
+
+	if _, err := g.ReadNode(ctx, "www.owasp.org", "fqdn"); err != nil {
+		t.Errorf("The name was not read from the sqlite graph: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestOpenGraphDatabase(t *testing.T) {
+	g, err := OpenGraphDatabase(&config.Database{System: "memory"})
+	if err != nil {
+		t.Fatalf("The memory graph was not opened: %v", err)
+	}
+	defer g.Close()
+
+	ctx := context.Background()
+	if _, err := g.UpsertFQDN(ctx, "www.owasp.org", "DNS", "test"); err != nil {
+		t.Errorf("The name was not inserted into the memory graph: %v", err)
+	}
+	if _, err := g.ReadNode(ctx, "www.owasp.org", "fqdn"); err != nil {
+		t.Errorf("The name was not read from the memory graph: %v", err)
+	}
+
+	if _, err := OpenGraphDatabase(&config.Database{System: "neo4j", URL: "bolt://localhost"}); err == nil {
+		t.Errorf("The unsupported graph database was opened")
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/netmap"
+)
+
+// The attempts made to complete a write with the endpoint before failing over to the next one
+const graphWriteAttempts int = 3
+
+// The most writes buffered while none of the graph database endpoints can be reached
+const maxBufferedGraphWrites int = 1000
+
+// The delay before a failed write is attempted again, which doubles with each attempt
+var graphRetryDelay = time.Second
+
+// The function that opens the graph database endpoints, which is replaced by the tests
+var openGraphEndpoint = OpenGraphDatabase
+
+// GraphWrite is an operation writing to the graph database provided.
+type GraphWrite func(ctx context.Context, g *netmap.Graph) error
+
+// GraphWriter writes to the first endpoint of a graph database that can be reached. The transient
+// failures are attempted again, and the writes fail over to the next endpoint once the current one
+// has been lost. The writes made while none of the endpoints can be reached are buffered, up to a
+// bound, and are completed in order once an endpoint can be reached again.
+type GraphWriter struct {
+	sync.Mutex
+	dbs      []*config.Database
+	current  int
+	graph    *netmap.Graph
+	log      config.Logger
+	buffered []GraphWrite
+	dropped  int
+	flushing bool
+	cause    error
+}
+
+// NewGraphWriter returns a GraphWriter connected with the first of the endpoints that can be reached.
+// An error is returned when none of the endpoints can be reached.
+func NewGraphWriter(dbs []*config.Database, logger config.Logger) (*GraphWriter, error) {
+	if len(dbs) == 0 {
+		return nil, errors.New("no graph database endpoints were provided")
+	}
+
+	w := &GraphWriter{
+		dbs: dbs,
+		log: logger,
+	}
+
+	for i, db := range dbs {
+		g, err := openGraphEndpoint(db)
+		if err == nil {
+			w.current = i
+			w.graph = g
+			return w, nil
+		}
+
+		w.log.Warn(fmt.Sprintf("The %s could not be reached: %v", w.endpoint(i), err))
+	}
+	return nil, fmt.Errorf("none of the %d %s graph database endpoints could be reached", len(dbs), dbs[0].System)
+}
+
+// String returns the name of the graph database endpoint currently written to.
+func (w *GraphWriter) String() string {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.endpoint(w.current)
+}
+
+// endpoint describes the endpoint without the DSN, which can include the credentials.
+func (w *GraphWriter) endpoint(i int) string {
+	return fmt.Sprintf("%s graph database endpoint %d of %d", w.dbs[i].System, i+1, len(w.dbs))
+}
+
+// Graph returns the graph of the endpoint currently written to.
+func (w *GraphWriter) Graph() *netmap.Graph {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.graph
+}
+
+// Buffered returns the number of writes waiting for an endpoint to be reached.
+func (w *GraphWriter) Buffered() int {
+	w.Lock()
+	defer w.Unlock()
+
+	return len(w.buffered)
+}
+
+// Dropped returns the number of writes that were not buffered, since the bound had been reached.
+func (w *GraphWriter) Dropped() int {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.dropped
+}
+
+// Write performs the write with the current endpoint, once the buffered writes have been completed.
+// The write is buffered when it failed with every endpoint that could be reached, and the error is
+// returned. The writes that failed for reasons other than the connection are not buffered. The lock
+// is not held while the write is attempted again, so the other writes are not delayed by the backoff.
+func (w *GraphWriter) Write(ctx context.Context, op GraphWrite) error {
+	w.Lock()
+	if len(w.buffered) > 0 {
+		// The buffered writes are completed first, so the order of the writes is kept
+		w.buffer(op, w.cause)
+		w.Unlock()
+		return w.flush(ctx)
+	}
+	w.Unlock()
+
+	err := w.write(ctx, op)
+	if w.retryable(ctx, err) {
+		w.Lock()
+		w.buffer(op, err)
+		w.Unlock()
+	}
+	return err
+}
+
+// Flush completes the buffered writes, attempting to reach the endpoints again until the context expires.
+func (w *GraphWriter) Flush(ctx context.Context) error {
+	delay := graphRetryDelay
+
+	for w.Buffered() > 0 {
+		err := w.flush(ctx)
+		if err == nil {
+			continue
+		}
+		if !w.retryable(ctx, err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		if delay < time.Minute {
+			delay *= 2
+		}
+	}
+	return nil
+}
+
+// Close closes the graph of the endpoint currently written to.
+func (w *GraphWriter) Close() {
+	w.Lock()
+	defer w.Unlock()
+
+	if num := len(w.buffered); num > 0 {
+		w.log.Error(fmt.Sprintf("%d buffered writes were not completed with the %s graph database", num, w.dbs[0].System))
+	}
+	w.graph.Close()
+}
+
+// flush completes the buffered writes in order, and returns the error that stopped them while the
+// endpoints still cannot be reached. Only one caller completes the buffered writes at a time, and the
+// others are returned the error that caused the writes to be buffered.
+func (w *GraphWriter) flush(ctx context.Context) error {
+	w.Lock()
+	if w.flushing {
+		err := w.cause
+		w.Unlock()
+		return err
+	}
+	w.flushing = true
+	w.Unlock()
+
+	var num int
+	for {
+		w.Lock()
+		if len(w.buffered) == 0 {
+			w.flushing = false
+			w.Unlock()
+			break
+		}
+		op := w.buffered[0]
+		w.Unlock()
+
+		// The write stays buffered when the endpoints were lost again or the context expired
+		err := w.write(ctx, op)
+		if err != nil && (ctx.Err() != nil || transientGraphError(err)) {
+			w.Lock()
+			w.flushing = false
+			if ctx.Err() == nil {
+				w.cause = err
+			}
+			w.Unlock()
+			return err
+		}
+		if err != nil {
+			w.log.Error(fmt.Sprintf("A buffered write failed with the %s: %v", w.String(), err))
+		}
+
+		w.Lock()
+		w.buffered = w.buffered[1:]
+		w.Unlock()
+		num++
+	}
+
+	if num > 0 {
+		w.log.Info(fmt.Sprintf("The %d buffered writes were completed with the %s", num, w.String()))
+	}
+	return nil
+}
+
+// buffer keeps the write until an endpoint can be reached, and counts the write as dropped once the
+// bound has been reached. The lock must be held by the caller.
+func (w *GraphWriter) buffer(op GraphWrite, cause error) {
+	if len(w.buffered) >= maxBufferedGraphWrites {
+		if w.dropped == 0 {
+			w.log.Error(fmt.Sprintf("The %d buffered writes for the %s graph database have reached the bound, "+
+				"so the later writes are dropped", len(w.buffered), w.dbs[0].System))
+		}
+		w.dropped++
+		return
+	}
+
+	w.buffered = append(w.buffered, op)
+	w.cause = cause
+}
+
+// write performs the write with the current endpoint, and fails over to the other endpoints in turn
+// while the write fails with a transient error.
+func (w *GraphWriter) write(ctx context.Context, op GraphWrite) error {
+	g := w.Graph()
+	err := w.attempt(ctx, op, g)
+
+	for failovers := 0; failovers < len(w.dbs) && w.retryable(ctx, err); failovers++ {
+		var ok bool
+		if g, ok = w.failover(g, err); !ok {
+			break
+		}
+		err = w.attempt(ctx, op, g)
+	}
+	return err
+}
+
+func (w *GraphWriter) attempt(ctx context.Context, op GraphWrite, g *netmap.Graph) error {
+	delay := graphRetryDelay
+
+	var err error
+	for i := 1; i <= graphWriteAttempts; i++ {
+		if err = op(ctx, g); !w.retryable(ctx, err) || i == graphWriteAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// failover connects with the endpoints that follow the current one in turn, including the current
+// endpoint last, and returns false when none of them could be reached. The graph of the current
+// endpoint is returned when another write has already failed over from the graph that failed.
+func (w *GraphWriter) failover(failed *netmap.Graph, cause error) (*netmap.Graph, bool) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.graph != failed {
+		return w.graph, true
+	}
+
+	for i := 1; i <= len(w.dbs); i++ {
+		next := (w.current + i) % len(w.dbs)
+
+		g, err := openGraphEndpoint(w.dbs[next])
+		if err != nil {
+			w.log.Warn(fmt.Sprintf("The %s could not be reached: %v", w.endpoint(next), err))
+			continue
+		}
+
+		if next == w.current {
+			w.log.Warn(fmt.Sprintf("Reconnected with the %s after the writes failed: %v", w.endpoint(next), cause))
+		} else {
+			w.log.Warn(fmt.Sprintf("The %s failed, so the writes fail over to the %s: %v",
+				w.endpoint(w.current), w.endpoint(next), cause))
+		}
+
+		w.graph.Close()
+		w.current = next
+		w.graph = g
+		return g, true
+	}
+
+	w.log.Error(fmt.Sprintf("None of the %s graph database endpoints can be reached, so the writes are buffered: %v",
+		w.dbs[0].System, cause))
+	return failed, false
+}
+
+func (w *GraphWriter) retryable(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && transientGraphError(err)
+}
+
+// transientGraphError returns true for the errors caused by the connection with the graph database,
+// which are expected to succeed once the connection has been established again.
+func transientGraphError(err error) bool {
+	var nerr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &nerr) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"connection", "broken pipe", "timeout", "timed out"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/netmap"
+)
+
+// testEndpoints opens in-memory graphs for the endpoints that are up, identified by their URLs.
+type testEndpoints struct {
+	sync.Mutex
+	down   map[string]bool
+	graphs map[*netmap.Graph]string
+}
+
+func newTestEndpoints(t *testing.T) *testEndpoints {
+	te := &testEndpoints{
+		down:   make(map[string]bool),
+		graphs: make(map[*netmap.Graph]string),
+	}
+
+	origOpen, origDelay := openGraphEndpoint, graphRetryDelay
+	t.Cleanup(func() { openGraphEndpoint, graphRetryDelay = origOpen, origDelay })
+
+	graphRetryDelay = 0
+	openGraphEndpoint = func(db *config.Database) (*netmap.Graph, error) {
+		te.Lock()
+		defer te.Unlock()
+
+		if te.down[db.URL] {
+			return nil, fmt.Errorf("dial tcp %s: connection refused", db.URL)
+		}
+
+		g, err := OpenGraphDatabase(&config.Database{System: "memory"})
+		if err == nil {
+			te.graphs[g] = db.URL
+		}
+		return g, err
+	}
+	return te
+}
+
+func (te *testEndpoints) setDown(url string, down bool) {
+	te.Lock()
+	defer te.Unlock()
+
+	te.down[url] = down
+}
+
+// write returns a GraphWrite that records the endpoints written to, and fails while the endpoint is down.
+func (te *testEndpoints) write(written *[]string) GraphWrite {
+	return func(ctx context.Context, g *netmap.Graph) error {
+		te.Lock()
+		defer te.Unlock()
+
+		url := te.graphs[g]
+		if te.down[url] {
+			return errors.New("write: connection reset by peer")
+		}
+
+		*written = append(*written, url)
+		return nil
+	}
+}
+
+// numbered returns a GraphWrite that records the endpoint and the number of the write once completed.
+func (te *testEndpoints) numbered(written *[]string, num int) GraphWrite {
+	var url []string
+
+	write := te.write(&url)
+	return func(ctx context.Context, g *netmap.Graph) error {
+		if err := write(ctx, g); err != nil {
+			return err
+		}
+
+		*written = append(*written, fmt.Sprintf("%s:%d", url[0], num))
+		return nil
+	}
+}
+
+func testGraphEndpoints(urls ...string) []*config.Database {
+	var dbs []*config.Database
+
+	for _, u := range urls {
+		dbs = append(dbs, &config.Database{System: "postgres", URL: u})
+	}
+	return dbs
+}
+
+func TestGraphWriterFailover(t *testing.T) {
+	te := newTestEndpoints(t)
+	te.setDown("db1", true)
+
+	w, err := NewGraphWriter(testGraphEndpoints("db1", "db2", "db3"), config.NewStdLogger(nil))
+	if err != nil {
+		t.Fatalf("The writer did not connect with the endpoint that is up: %v", err)
+	}
+	defer w.Close()
+
+	if w.String() != "postgres graph database endpoint 2 of 3" {
+		t.Errorf("The writer was connected with the %s", w.String())
+	}
+
+	var written []string
+	ctx := context.Background()
+	if err := w.Write(ctx, te.write(&written)); err != nil {
+		t.Errorf("The write failed: %v", err)
+	}
+
+	te.setDown("db2", true)
+	if err := w.Write(ctx, te.write(&written)); err != nil {
+		t.Errorf("The write did not fail over to the next endpoint: %v", err)
+	}
+	if len(written) != 2 || written[0] != "db2" || written[1] != "db3" {
+		t.Errorf("The writes were completed with the endpoints %v", written)
+	}
+
+	// The writes that did not fail because of the connection are not attempted again
+	var attempts int
+	permanent := func(ctx context.Context, g *netmap.Graph) error {
+		attempts++
+		return errors.New("the node could not be found")
+	}
+	if err := w.Write(ctx, permanent); err == nil || attempts != 1 {
+		t.Errorf("The write failing permanently was attempted %d times", attempts)
+	}
+}
+
+func TestGraphWriterBuffering(t *testing.T) {
+	te := newTestEndpoints(t)
+
+	w, err := NewGraphWriter(testGraphEndpoints("db1", "db2"), config.NewStdLogger(nil))
+	if err != nil {
+		t.Fatalf("The writer did not connect with the endpoints: %v", err)
+	}
+	defer w.Close()
+
+	te.setDown("db1", true)
+	te.setDown("db2", true)
+
+	var written []string
+	ctx := context.Background()
+	for i := 0; i < maxBufferedGraphWrites+2; i++ {
+		if err := w.Write(ctx, te.numbered(&written, i)); err == nil {
+			t.Fatal("The write succeeded while the endpoints were down")
+		}
+	}
+	if w.Buffered() != maxBufferedGraphWrites || w.Dropped() != 2 {
+		t.Errorf("%d writes were buffered and %d dropped", w.Buffered(), w.Dropped())
+	}
+
+	te.setDown("db2", false)
+	if err := w.Flush(ctx); err != nil {
+		t.Errorf("The buffered writes were not completed: %v", err)
+	}
+	if w.Buffered() != 0 || len(written) != maxBufferedGraphWrites {
+		t.Fatalf("%d of the buffered writes were completed", len(written))
+	}
+	for i, num := range written {
+		if num != fmt.Sprintf("db2:%d", i) {
+			t.Fatalf("The buffered write %d was completed as %s", i, num)
+		}
+	}
+	if w.String() != "postgres graph database endpoint 2 of 2" {
+		t.Errorf("The buffered writes were completed with the %s", w.String())
+	}
+
+	// The writes made once the endpoint can be reached again are not buffered
+	if err := w.Write(ctx, te.numbered(&written, maxBufferedGraphWrites)); err != nil || w.Buffered() != 0 {
+		t.Errorf("The write failed once the endpoint was reachable again: %v", err)
+	}
+}
+
+func TestGraphWriterOrderAfterReconnect(t *testing.T) {
+	te := newTestEndpoints(t)
+
+	w, err := NewGraphWriter(testGraphEndpoints("db1"), config.NewStdLogger(nil))
+	if err != nil {
+		t.Fatalf("The writer did not connect with the endpoint: %v", err)
+	}
+	defer w.Close()
+
+	te.setDown("db1", true)
+
+	var written []string
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := w.Write(ctx, te.numbered(&written, i)); err == nil {
+			t.Fatal("The write succeeded while the endpoint was down")
+		}
+	}
+
+	// The next write completes the buffered writes before its own
+	te.setDown("db1", false)
+	if err := w.Write(ctx, te.numbered(&written, 3)); err != nil {
+		t.Errorf("The write failed once the endpoint was reachable again: %v", err)
+	}
+	if w.Buffered() != 0 || w.Dropped() != 0 {
+		t.Errorf("%d writes remain buffered and %d were dropped", w.Buffered(), w.Dropped())
+	}
+	if want := []string{"db1:0", "db1:1", "db1:2", "db1:3"}; fmt.Sprint(written) != fmt.Sprint(want) {
+		t.Errorf("The writes were completed as %v, not %v", written, want)
+	}
+}
+
+func TestGraphWriterBackoffReleasesLock(t *testing.T) {
+	te := newTestEndpoints(t)
+
+	w, err := NewGraphWriter(testGraphEndpoints("db1"), config.NewStdLogger(nil))
+	if err != nil {
+		t.Fatalf("The writer did not connect with the endpoint: %v", err)
+	}
+	defer w.Close()
+
+	graphRetryDelay = time.Second
+	te.setDown("db1", true)
+
+	var written []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = w.Write(context.Background(), te.write(&written))
+	}()
+
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		_ = w.Buffered()
+	}()
+
+	select {
+	case <-acquired:
+	case <-done:
+		t.Fatal("The write completed before the lock could be acquired")
+	case <-time.After(500 * time.Millisecond):
+		t.Error("The lock was held while the write was attempted again")
+	}
+	<-done
+}
+
+func TestNewGraphWriterUnreachable(t *testing.T) {
+	te := newTestEndpoints(t)
+	te.setDown("db1", true)
+	te.setDown("db2", true)
+
+	if _, err := NewGraphWriter(testGraphEndpoints("db1", "db2"), config.NewStdLogger(nil)); err == nil {
+		t.Error("The writer was returned without an endpoint that could be reached")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const (
+	// The weight given to the latest query when updating the moving averages
+	healthWeight = 0.1
+	// The number of queries observed before a resolver can be ejected
+	healthMinSamples = 20
+	// The duration an ejected resolver waits before being probed for re-admission
+	resolverEjectCooldown = time.Minute
+)
+
+// Identifies the messages logged by the resolver pool
+var resolversField = config.SourceField("Resolvers")
+
+// ResolverScore provides the health metrics tracked for a resolver.
+type ResolverScore struct {
+	Resolver    string
+	SuccessRate float64
+	Latency     time.Duration
+	Queries     int
+	Ejected     bool
+}
+
+// resolverHealth scores a group of resolvers and ejects those with a success rate
+// that falls below the threshold, until they successfully answer a probe.
+type resolverHealth struct {
+	sync.Mutex
+	threshold float64
+	cooldown  time.Duration
+	log       config.Logger
+	resolvers []*healthResolver
+}
+
+func newResolverHealth(threshold float64, cooldown time.Duration, logger config.Logger) *resolverHealth {
+	if logger == nil {
+		logger = config.NewStdLogger(nil)
+	}
+
+	return &resolverHealth{
+		threshold: threshold,
+		cooldown:  cooldown,
+		log:       logger,
+	}
+}
+
+// wrap returns the resolvers with their health tracked by the group.
+// The resolvers are returned unchanged when health tracking is disabled.
+func (h *resolverHealth) wrap(resolvers []resolve.Resolver) []resolve.Resolver {
+	if h == nil {
+		return resolvers
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	wrapped := make([]resolve.Resolver, 0, len(resolvers))
+	for _, r := range resolvers {
+		hr := &healthResolver{
+			Resolver: r,
+			health:   h,
+			success:  1,
+		}
+
+		h.resolvers = append(h.resolvers, hr)
+		wrapped = append(wrapped, hr)
+	}
+	return wrapped
+}
+
+// Scores returns the health metrics for each resolver in the group.
+func (h *resolverHealth) Scores() []ResolverScore {
+	if h == nil {
+		return nil
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	scores := make([]ResolverScore, 0, len(h.resolvers))
+	for _, hr := range h.resolvers {
+		scores = append(scores, ResolverScore{
+			Resolver:    hr.String(),
+			SuccessRate: hr.success,
+			Latency:     time.Duration(hr.latency),
+			Queries:     hr.queries,
+			Ejected:     hr.ejected,
+		})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].Resolver < scores[j].Resolver
+	})
+	return scores
+}
+
+func (h *resolverHealth) record(hr *healthResolver, success bool, latency time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+
+	var s float64
+	if success {
+		s = 1
+	}
+
+	hr.queries++
+	hr.success = (healthWeight * s) + ((1 - healthWeight) * hr.success)
+	if hr.queries == 1 {
+		hr.latency = float64(latency)
+	} else {
+		hr.latency = (healthWeight * float64(latency)) + ((1 - healthWeight) * hr.latency)
+	}
+
+	if hr.ejected || hr.queries < healthMinSamples || hr.success >= h.threshold {
+		return
+	}
+	// Never eject the last admitted resolver, since the pool would be left without one
+	if h.numAdmitted() <= 1 {
+		return
+	}
+
+	hr.ejected = true
+	h.log.Warn(fmt.Sprintf("Resolver %s has been ejected: success rate: %.2f", hr.String(), hr.success), resolversField)
+	time.AfterFunc(h.cooldown, func() { h.probe(hr) })
+}
+
+// The caller must hold the lock.
+func (h *resolverHealth) numAdmitted() int {
+	var num int
+
+	for _, hr := range h.resolvers {
+		if !hr.ejected && !hr.Resolver.Stopped() {
+			num++
+		}
+	}
+	return num
+}
+
+func (h *resolverHealth) probe(hr *healthResolver) {
+	if hr.Resolver.Stopped() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolve.QueryTimeout)
+	defer cancel()
+
+	_, err := hr.Resolver.Query(ctx, resolve.QueryMsg(".", dns.TypeNS), resolve.PriorityHigh, nil)
+	if queryFailed(err) {
+		time.AfterFunc(h.cooldown, func() { h.probe(hr) })
+		return
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	// Provide the resolver a fresh score upon re-admission
+	hr.ejected = false
+	hr.success = 1
+	hr.queries = 0
+	h.log.Info(fmt.Sprintf("Resolver %s has been re-admitted", hr.String()), resolversField)
+}
+
+// healthResolver is a Resolver that reports the outcome of each query to the group
+// and appears stopped to the resolver pool while ejected.
+type healthResolver struct {
+	resolve.Resolver
+	health  *resolverHealth
+	success float64
+	latency float64
+	queries int
+	ejected bool
+}
+
+// Stopped implements the Resolver interface.
+func (hr *healthResolver) Stopped() bool {
+	if hr.Resolver.Stopped() {
+		return true
+	}
+
+	hr.health.Lock()
+	defer hr.health.Unlock()
+
+	return hr.ejected
+}
+
+// Query implements the Resolver interface.
+func (hr *healthResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	start := time.Now()
+	resp, err := hr.Resolver.Query(ctx, msg, priority, retry)
+
+	// Queries abandoned by the caller say nothing about the health of the resolver
+	if ctx.Err() == nil {
+		hr.health.record(hr, !queryFailed(err), time.Since(start))
+	}
+	return resp, err
+}
+
+func queryFailed(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var re *resolve.ResolveError
+	if !errors.As(err, &re) {
+		return true
+	}
+
+	switch re.Rcode {
+	case resolve.TimeoutRcode, resolve.ResolverErrRcode, dns.RcodeServerFailure, dns.RcodeRefused:
+		return true
+	}
+	return false
+}
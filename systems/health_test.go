@@ -0,0 +1,102 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// flakyResolver returns SERVFAIL for every query while failing is set.
+type flakyResolver struct {
+	sync.Mutex
+	name    string
+	failing bool
+}
+
+func (r *flakyResolver) String() string { return r.name }
+func (r *flakyResolver) Len() int       { return 0 }
+func (r *flakyResolver) Stop()          {}
+func (r *flakyResolver) Stopped() bool  { return false }
+
+func (r *flakyResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	resp := msg.Copy()
+	resp.Response = true
+	if r.failing {
+		resp.Rcode = dns.RcodeServerFailure
+		return resp, &resolve.ResolveError{Err: "SERVFAIL", Rcode: dns.RcodeServerFailure}
+	}
+	return resp, nil
+}
+
+func (r *flakyResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func (r *flakyResolver) setFailing(failing bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.failing = failing
+}
+
+func TestResolverHealthEjection(t *testing.T) {
+	bad := &flakyResolver{name: "bad", failing: true}
+	good := &flakyResolver{name: "good"}
+
+	h := newResolverHealth(0.5, 50*time.Millisecond, nil)
+	wrapped := h.wrap([]resolve.Resolver{bad, good})
+
+	msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+	for i := 0; i < healthMinSamples; i++ {
+		for _, r := range wrapped {
+			_, _ = r.Query(context.Background(), msg, resolve.PriorityNormal, nil)
+		}
+	}
+
+	if !wrapped[0].Stopped() {
+		t.Errorf("The failing resolver was not ejected")
+	}
+	if wrapped[1].Stopped() {
+		t.Errorf("The healthy resolver was ejected")
+	}
+
+	scores := h.Scores()
+	if len(scores) != 2 || scores[0].Resolver != "bad" || !scores[0].Ejected || scores[0].SuccessRate >= 0.5 {
+		t.Errorf("The scores did not reflect the ejected resolver: %+v", scores)
+	}
+
+	bad.setFailing(false)
+	deadline := time.Now().Add(2 * time.Second)
+	for wrapped[0].Stopped() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if wrapped[0].Stopped() {
+		t.Errorf("The recovered resolver was not re-admitted after a successful probe")
+	}
+}
+
+func TestResolverHealthKeepsLastResolver(t *testing.T) {
+	bad := &flakyResolver{name: "bad", failing: true}
+
+	h := newResolverHealth(0.5, time.Minute, nil)
+	wrapped := h.wrap([]resolve.Resolver{bad})
+
+	msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+	for i := 0; i < 2*healthMinSamples; i++ {
+		_, _ = wrapped[0].Query(context.Background(), msg, resolve.PriorityNormal, nil)
+	}
+
+	if wrapped[0].Stopped() {
+		t.Errorf("The only resolver in the pool was ejected")
+	}
+}
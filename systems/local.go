@@ -11,6 +11,7 @@ import (
 	"os"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,12 +29,21 @@ import (
 type LocalSystem struct {
 	Cfg               *config.Config
 	pool              resolve.Resolver
+	health            *resolverHealth
+	poison            *poisonDetector
+	adaptive          *adaptiveResolver
+	queryCache        *queryCache
+	queryTypes        *queryTypeResolver
 	graphs            []*netmap.Graph
+	writer            *GraphWriter
 	cache             *requests.ASNCache
 	done              chan struct{}
 	doneAlreadyClosed bool
 	addSource         chan service.Service
 	allSources        chan chan []service.Service
+	// The data sources that failed to start, such as those without valid API keys, and the reasons
+	disabledLock sync.Mutex
+	disabled     map[string]string
 }
 
 // NewLocalSystem returns an initialized LocalSystem object.
@@ -42,21 +52,20 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		return nil, err
 	}
 
-	max := int(float64(limits.GetFileLimit()) * 0.7)
-
-	var pool resolve.Resolver
-	if len(c.Resolvers) == 0 {
-		pool = publicResolverSetup(c, max)
-	} else {
-		pool = customResolverSetup(c, max)
-	}
-	if pool == nil {
-		return nil, errors.New("the system was unable to build the pool of resolvers")
+	// The proxy must be configured before the resolvers and data sources are created
+	rs, err := setupResolverPool(c)
+	if err != nil {
+		return nil, err
 	}
 
 	sys := &LocalSystem{
 		Cfg:        c,
-		pool:       pool,
+		pool:       rs.pool,
+		health:     rs.health,
+		poison:     rs.poison,
+		adaptive:   rs.adaptive,
+		queryCache: rs.cache,
+		queryTypes: rs.types,
 		cache:      requests.NewASNCache(),
 		done:       make(chan struct{}, 2),
 		addSource:  make(chan service.Service),
@@ -83,6 +92,114 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 	return sys, nil
 }
 
+// resolverSetup holds the pool of resolvers built for the configuration and the components
+// tracking the resolvers in the pool.
+type resolverSetup struct {
+	pool     resolve.Resolver
+	health   *resolverHealth
+	poison   *poisonDetector
+	adaptive *adaptiveResolver
+	cache    *queryCache
+	types    *queryTypeResolver
+}
+
+// NewResolverPool returns the pool of resolvers built for the configuration, with the same rate
+// limits, retries, caching and wildcard detection used by the LocalSystem, for the callers that
+// resolve names without the other components of the system. The caller stops the pool.
+func NewResolverPool(c *config.Config) (resolve.Resolver, error) {
+	if err := c.CheckSettings(); err != nil {
+		return nil, err
+	}
+	rs, err := setupResolverPool(c)
+	if err != nil {
+		return nil, err
+	}
+	return rs.pool, nil
+}
+
+func setupResolverPool(c *config.Config) (*resolverSetup, error) {
+	max := int(float64(limits.GetFileLimit()) * 0.7)
+
+	var health *resolverHealth
+	if c.ResolverEjectThreshold > 0 {
+		health = newResolverHealth(c.ResolverEjectThreshold, resolverEjectCooldown, c.Leveled())
+	}
+
+	var poison *poisonDetector
+	if c.DetectPoisonedResolvers {
+		poison = newPoisonDetector(c.Leveled())
+	}
+
+	sources, err := sourceAddresses(c)
+	if err != nil {
+		return nil, err
+	}
+	// The resolvers of the configuration query over TCP through its proxy, which is not shared with
+	// the other configurations in the process
+	proxy, err := c.ProxyDialer()
+	if err != nil {
+		return nil, err
+	}
+
+	var pool resolve.Resolver
+	if len(c.Resolvers) == 0 && len(c.DoHResolvers) == 0 {
+		pool = publicResolverSetup(c, max, sources, proxy, health, poison)
+	} else {
+		pool = customResolverSetup(c, max, sources, proxy, health, poison)
+	}
+	if pool == nil {
+		return nil, errors.New("the system was unable to build the pool of resolvers")
+	}
+	// The queries sent to the authoritative servers are also counted and throttled below
+	if c.QueryAuthoritative {
+		pool = newAuthoritativeResolver(pool, c.WhichDomain, proxy, c.Log)
+	}
+	// Each attempt made by the retries is counted against the in-flight query limit
+	var adaptive *adaptiveResolver
+	if c.MaxConcurrency > 0 {
+		adaptive = newAdaptiveResolver(pool, c.MaxConcurrency)
+		pool = adaptive
+	}
+	if c.ActiveConcurrency > 0 {
+		pool = newLimitResolver(pool, c.ActiveConcurrency)
+	}
+	// The zones sharing an authoritative server are throttled together, including each retry
+	if c.MaxQueriesPerServer > 0 {
+		pool = newServerLimitResolver(pool, c.MaxQueriesPerServer, c.WhichDomain)
+	}
+	// Each attempt made by the retries is counted by the record type queried
+	types := newQueryTypeResolver(pool)
+	pool = types
+	// The wildcard detection queries are also performed again after transient errors
+	if c.DNSRetries > 0 {
+		pool = newRetryResolver(pool, c.DNSRetries)
+	}
+	if len(c.ECSSubnets) > 0 {
+		pool = newECSResolver(pool, c.ECSSubnets)
+	}
+	// Cached responses are only stored once the retries have been performed
+	var cache *queryCache
+	if c.DNSCacheTTL > 0 || c.NegativeCacheTTL > 0 {
+		size := c.DNSCacheSize
+		if size == 0 {
+			size = config.DefaultDNSCacheSize
+		}
+		cache = newQueryCache(pool, c.DNSCacheTTL, c.NegativeCacheTTL, size)
+		pool = cache
+	}
+	// The wildcard verdicts are kept for each record type, and expire when the TTL is provided
+	pool = newWildcardCache(pool, c.WildcardCacheTTL, c.Leveled())
+
+	return &resolverSetup{
+		pool:     pool,
+		health:   health,
+		poison:   poison,
+		adaptive: adaptive,
+		cache:    cache,
+		types:    types,
+	}, nil
+}
+
 // Config implements the System interface.
 func (l *LocalSystem) Config() *config.Config {
 	return l.Cfg
@@ -93,6 +210,36 @@ func (l *LocalSystem) Pool() resolve.Resolver {
 	return l.pool
 }
 
+// ResolverScores returns the health metrics for the resolvers in the pool.
+// Nothing is returned when the resolver eject threshold has not been configured.
+func (l *LocalSystem) ResolverScores() []ResolverScore {
+	return l.health.Scores()
+}
+
+// PoisonedResolvers returns the resolvers ejected from the pool for answering nonexistent names.
+// Nothing is returned when the detection of poisoned resolvers has not been enabled.
+func (l *LocalSystem) PoisonedResolvers() []string {
+	return l.poison.Poisoned()
+}
+
+// Concurrency returns the state of the adaptive concurrency controller for the pool.
+// Nothing is returned when the maximum concurrency has not been configured.
+func (l *LocalSystem) Concurrency() *ConcurrencyStats {
+	return l.adaptive.Stats()
+}
+
+// QueryCache returns the counters of the DNS query cache used by the pool.
+// Nothing is returned when the cache has been disabled.
+func (l *LocalSystem) QueryCache() *QueryCacheStats {
+	return l.queryCache.Stats()
+}
+
+// QueryTypes returns the counters and latencies of the queries sent by the pool for each record type,
+// starting with the most queried type. The retries are counted, while the cached responses are not.
+func (l *LocalSystem) QueryTypes() []QueryTypeStats {
+	return l.queryTypes.Stats()
+}
+
 // Cache implements the System interface.
 func (l *LocalSystem) Cache() *requests.ASNCache {
 	return l.cache
@@ -123,8 +270,15 @@ func (l *LocalSystem) DataSources() []service.Service {
 }
 
 // SetDataSources assigns the data sources that will be used by the system.
+// The data sources that fail to start are disabled, and are reported by DisabledDataSources.
 func (l *LocalSystem) SetDataSources(sources []service.Service) {
-	f := func(src service.Service, ch chan error) { ch <- l.AddAndStart(src) }
+	f := func(src service.Service, ch chan error) {
+		err := l.AddAndStart(src)
+		if err != nil {
+			l.disableDataSource(src.String(), err)
+		}
+		ch <- err
+	}
 
 	ch := make(chan error, len(sources))
 	// Add all the data sources that successfully start to the list
@@ -132,7 +286,8 @@ func (l *LocalSystem) SetDataSources(sources []service.Service) {
 		go f(src, ch)
 	}
 
-	t := time.NewTimer(5 * time.Second)
+	// The data sources that probe their services at startup are also waited for
+	t := time.NewTimer(15 * time.Second)
 	defer t.Stop()
 loop:
 	for i := 0; i < len(sources); i++ {
@@ -144,11 +299,45 @@ loop:
 	}
 }
 
+// DisabledDataSources returns the data sources that failed to start, such as those without valid API keys,
+// and the reason each was disabled.
+func (l *LocalSystem) DisabledDataSources() map[string]string {
+	l.disabledLock.Lock()
+	defer l.disabledLock.Unlock()
+
+	disabled := make(map[string]string, len(l.disabled))
+	for name, reason := range l.disabled {
+		disabled[name] = reason
+	}
+	return disabled
+}
+
+func (l *LocalSystem) disableDataSource(name string, err error) {
+	l.disabledLock.Lock()
+	defer l.disabledLock.Unlock()
+
+	if l.disabled == nil {
+		l.disabled = make(map[string]string)
+	}
+	// The errors returned by the data sources are prefixed with their names
+	l.disabled[name] = strings.TrimPrefix(err.Error(), name+": ")
+}
+
 // GraphDatabases implements the System interface.
 func (l *LocalSystem) GraphDatabases() []*netmap.Graph {
+	// The graph of the endpoint currently written to is used after a failover
+	if l.writer != nil {
+		return []*netmap.Graph{l.writer.Graph()}
+	}
 	return l.graphs
 }
 
+// GraphWriter returns the writer failing over between the endpoints of the selected graph database,
+// which is nil unless the configuration provides more than one endpoint.
+func (l *LocalSystem) GraphWriter() *GraphWriter {
+	return l.writer
+}
+
 // Shutdown implements the System interface.
 func (l *LocalSystem) Shutdown() error {
 	if l.doneAlreadyClosed {
@@ -169,7 +358,10 @@ func (l *LocalSystem) Shutdown() error {
 	wg.Wait()
 	close(l.done)
 
-	for _, g := range l.GraphDatabases() {
+	if l.writer != nil {
+		l.writer.Close()
+	}
+	for _, g := range l.graphs {
 		g.Close()
 	}
 
@@ -197,21 +389,32 @@ func (l *LocalSystem) setupOutputDirectory() error {
 func (l *LocalSystem) setupGraphDBs() error {
 	cfg := l.Config()
 
+	if dbs := cfg.SelectedDatabases(cfg.Dir); len(dbs) > 1 {
+		// The writes fail over between the endpoints of the selected backend
+		w, err := NewGraphWriter(dbs, cfg.Leveled())
+		if err != nil {
+			return fmt.Errorf("System: %v", err)
+		}
+
+		l.writer = w
+		return nil
+	}
+
 	var dbs []*config.Database
-	if db := cfg.LocalDatabaseSettings(cfg.GraphDBs); db != nil {
+	if db := cfg.SelectedDatabase(cfg.Dir); db != nil {
+		// The selected backend is the only graph database used
 		dbs = append(dbs, db)
+	} else {
+		if db := cfg.LocalDatabaseSettings(cfg.GraphDBs); db != nil {
+			dbs = append(dbs, db)
+		}
+		dbs = append(dbs, cfg.GraphDBs...)
 	}
-	dbs = append(dbs, cfg.GraphDBs...)
 
 	for _, db := range dbs {
-		cayley := netmap.NewCayleyGraph(db.System, db.URL, db.Options)
-		if cayley == nil {
-			return fmt.Errorf("System: Failed to create the %s graph", db.System)
-		}
-
-		g := netmap.NewGraph(cayley)
-		if g == nil {
-			return fmt.Errorf("System: Failed to create the %s graph", g.String())
+		g, err := OpenGraphDatabase(db)
+		if err != nil {
+			return fmt.Errorf("System: %v", err)
 		}
 
 		l.graphs = append(l.graphs, g)
@@ -273,8 +476,9 @@ func (l *LocalSystem) loadCacheData() error {
 	return nil
 }
 
-func customResolverSetup(cfg *config.Config, max int) resolve.Resolver {
-	num := len(cfg.Resolvers)
+func customResolverSetup(cfg *config.Config, max int, sources []net.IP,
+	proxy *amassnet.Proxy, health *resolverHealth, poison *poisonDetector) resolve.Resolver {
+	num := len(cfg.Resolvers) + len(cfg.DoHResolvers)
 	if num > max {
 		num = max
 	}
@@ -285,15 +489,24 @@ func customResolverSetup(cfg *config.Config, max int) resolve.Resolver {
 
 	var trusted []resolve.Resolver
 	for _, addr := range cfg.Resolvers {
-		if r := resolve.NewBaseResolver(addr, config.DefaultQueriesPerPublicResolver, cfg.Log); r != nil {
+		if r := newUpstreamResolver(addr, sources, proxy, config.DefaultQueriesPerPublicResolver, cfg.Log); r != nil {
+			trusted = append(trusted, r)
+		}
+	}
+	for _, u := range cfg.DoHResolvers {
+		if r := NewDoHResolver(u, proxy, config.DefaultQueriesPerPublicResolver, cfg.Log); r != nil {
 			trusted = append(trusted, r)
 		}
 	}
+	if len(trusted) == 0 {
+		return nil
+	}
 
-	return resolve.NewResolverPool(trusted, nil, 1, cfg.Log)
+	return newResolverPool(cfg, health.wrap(poison.wrap(trusted)), nil)
 }
 
-func publicResolverSetup(cfg *config.Config, max int) resolve.Resolver {
+func publicResolverSetup(cfg *config.Config, max int, sources []net.IP,
+	proxy *amassnet.Proxy, health *resolverHealth, poison *poisonDetector) resolve.Resolver {
 	num := len(config.PublicResolvers)
 	if num > max {
 		num = max
@@ -307,6 +520,8 @@ func publicResolverSetup(cfg *config.Config, max int) resolve.Resolver {
 		config.DefaultBaselineResolvers,
 		len(config.DefaultBaselineResolvers),
 		config.DefaultQueriesPerBaselineResolver,
+		sources,
+		proxy,
 		cfg.Log,
 	)
 	if len(trusted) == 0 {
@@ -318,12 +533,14 @@ func publicResolverSetup(cfg *config.Config, max int) resolve.Resolver {
 		config.PublicResolvers,
 		len(config.PublicResolvers),
 		config.DefaultQueriesPerPublicResolver,
+		sources,
+		proxy,
 		cfg.Log,
 	)
-	return resolve.NewResolverPool(r, baseline, 1, cfg.Log)
+	return newResolverPool(cfg, health.wrap(poison.wrap(r)), baseline)
 }
 
-func setupResolvers(addrs []string, max, rate int, log *log.Logger) []resolve.Resolver {
+func setupResolvers(addrs []string, max, rate int, sources []net.IP, proxy *amassnet.Proxy, log *log.Logger) []resolve.Resolver {
 	if len(addrs) <= 0 {
 		return nil
 	}
@@ -335,7 +552,7 @@ func setupResolvers(addrs []string, max, rate int, log *log.Logger) []resolve.Re
 
 	for _, addr := range addrs {
 		go func(ip string, ch chan resolve.Resolver) {
-			if n := resolve.NewBaseResolver(ip, rate, log); n != nil {
+			if n := newUpstreamResolver(ip, sources, proxy, rate, log); n != nil {
 				ch <- n
 				return
 			}
@@ -0,0 +1,118 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// serverGroup limits the queries in flight for the zones served by a set of authoritative servers.
+type serverGroup struct {
+	sem chan struct{}
+}
+
+// serverLimitResolver is a Resolver that limits the queries in flight for the names in each zone
+// by the authoritative servers of the zone, which are discovered by resolving its NS records. The
+// zones that share an authoritative server are throttled together, so the queries that resolvers
+// forward to a shared server cannot overwhelm it, regardless of which zone they are for.
+type serverLimitResolver struct {
+	resolve.Resolver
+	sync.Mutex
+	max    int
+	zoneOf func(name string) string
+	zones  map[string]*serverGroup
+	hosts  map[string]*serverGroup
+}
+
+func newServerLimitResolver(r resolve.Resolver, max int, zoneOf func(name string) string) *serverLimitResolver {
+	return &serverLimitResolver{
+		Resolver: r,
+		max:      max,
+		zoneOf:   zoneOf,
+		zones:    make(map[string]*serverGroup),
+		hosts:    make(map[string]*serverGroup),
+	}
+}
+
+// Query implements the Resolver interface.
+func (sl *serverLimitResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if len(msg.Question) != 1 {
+		return sl.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	zone := strings.ToLower(sl.zoneOf(resolve.RemoveLastDot(msg.Question[0].Name)))
+	if zone == "" {
+		return sl.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	g := sl.group(ctx, zone)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case g.sem <- struct{}{}:
+	}
+	defer func() { <-g.sem }()
+
+	return sl.Resolver.Query(ctx, msg, priority, retry)
+}
+
+// group returns the serverGroup of the zone, which is shared with the zones discovered earlier
+// that have one of the same authoritative servers. A zone without discovered servers is throttled alone.
+func (sl *serverLimitResolver) group(ctx context.Context, zone string) *serverGroup {
+	sl.Lock()
+	g, found := sl.zones[zone]
+	sl.Unlock()
+	if found {
+		return g
+	}
+
+	// The NS records are resolved without holding the lock or counting against the limit
+	servers := sl.nameServers(ctx, zone)
+
+	sl.Lock()
+	defer sl.Unlock()
+
+	if g, found := sl.zones[zone]; found {
+		return g
+	}
+	for _, s := range servers {
+		if g, found = sl.hosts[s]; found {
+			break
+		}
+	}
+	if g == nil {
+		g = &serverGroup{sem: make(chan struct{}, sl.max)}
+	}
+
+	sl.zones[zone] = g
+	for _, s := range servers {
+		if _, found := sl.hosts[s]; !found {
+			sl.hosts[s] = g
+		}
+	}
+	return g
+}
+
+func (sl *serverLimitResolver) nameServers(ctx context.Context, zone string) []string {
+	resp, err := sl.Resolver.Query(ctx, resolve.QueryMsg(zone, dns.TypeNS), resolve.PriorityHigh, resolve.PoolRetryPolicy)
+	if err != nil || resp == nil {
+		return nil
+	}
+
+	var servers []string
+	for _, a := range resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypeNS) {
+		if s := strings.ToLower(resolve.RemoveLastDot(strings.TrimSpace(a.Data))); s != "" {
+			servers = append(servers, s)
+		}
+	}
+
+	sort.Strings(servers)
+	return servers
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// nsResolver answers NS queries with the servers of each zone, and holds the queries for the
+// names starting with slow until the release channel is closed.
+type nsResolver struct {
+	servers map[string][]string
+	release chan struct{}
+}
+
+func (r *nsResolver) String() string { return "ns" }
+func (r *nsResolver) Len() int       { return 0 }
+func (r *nsResolver) Stop()          {}
+func (r *nsResolver) Stopped() bool  { return false }
+
+func (r *nsResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	q := msg.Question[0]
+	resp := msg.Copy()
+	resp.Response = true
+
+	if q.Qtype == dns.TypeNS {
+		for _, s := range r.servers[resolve.RemoveLastDot(q.Name)] {
+			resp.Answer = append(resp.Answer, &dns.NS{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: 60},
+				Ns:  dns.Fqdn(s),
+			})
+		}
+		return resp, nil
+	}
+	if strings.HasPrefix(q.Name, "slow.") {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return resp, nil
+}
+
+func (r *nsResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func zoneOf(name string) string {
+	for _, zone := range []string{"owasp.org", "example.com", "example.net"} {
+		if name == zone || strings.HasSuffix(name, "."+zone) {
+			return zone
+		}
+	}
+	return ""
+}
+
+func TestServerLimitResolverSharedServers(t *testing.T) {
+	r := &nsResolver{
+		servers: map[string][]string{
+			"owasp.org":   {"ns1.shared.net", "ns2.owasp.org"},
+			"example.com": {"ns1.shared.net"},
+			"example.net": {"ns1.other.net"},
+		},
+		release: make(chan struct{}),
+	}
+	sl := newServerLimitResolver(r, 1, zoneOf)
+
+	done := make(chan error)
+	go func() {
+		_, err := sl.Query(context.Background(), resolve.QueryMsg("slow.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+		done <- err
+	}()
+	// Wait for the slow query to hold the only slot of the shared servers
+	for {
+		sl.Lock()
+		g := sl.zones["owasp.org"]
+		sl.Unlock()
+		if g != nil && len(g.sem) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The zone sharing the authoritative server must wait for the slot
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := sl.Query(ctx, resolve.QueryMsg("www.example.com", dns.TypeA), resolve.PriorityNormal, nil); err == nil {
+		t.Errorf("The query for the zone sharing the authoritative server was not throttled")
+	}
+	// The zone served by other authoritative servers is not affected
+	if _, err := sl.Query(context.Background(), resolve.QueryMsg("www.example.net", dns.TypeA), resolve.PriorityNormal, nil); err != nil {
+		t.Errorf("The query for the zone with other authoritative servers failed: %v", err)
+	}
+
+	close(r.release)
+	if err := <-done; err != nil {
+		t.Errorf("The slow query failed: %v", err)
+	}
+	if sl.zones["owasp.org"] != sl.zones["example.com"] || sl.zones["owasp.org"] == sl.zones["example.net"] {
+		t.Errorf("The zones were not grouped by their authoritative servers")
+	}
+}
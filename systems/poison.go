@@ -0,0 +1,175 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// The number of answers containing the same address before the resolver is checked for poisoning
+const poisonSuspectAnswers = 25
+
+// poisonDetector ejects the resolvers that answer the queries for nonexistent names with addresses,
+// such as the resolvers of captive portals and censoring networks. Each resolver is checked with a canary
+// name when wrapped, and checked again when it returns the same address for many names.
+type poisonDetector struct {
+	sync.Mutex
+	log       config.Logger
+	canary    func() string
+	resolvers []*poisonResolver
+}
+
+func newPoisonDetector(logger config.Logger) *poisonDetector {
+	if logger == nil {
+		logger = config.NewStdLogger(nil)
+	}
+
+	return &poisonDetector{
+		log:    logger,
+		canary: canaryName,
+	}
+}
+
+// canaryName returns a random name that does not exist beneath a top-level domain.
+func canaryName() string {
+	return fmt.Sprintf("%x%x.com", rand.Int63(), rand.Int63())
+}
+
+// wrap returns the resolvers with the poisoned answers detected, after the canary check of each resolver.
+// The resolvers are returned unchanged when the detection is disabled.
+func (p *poisonDetector) wrap(resolvers []resolve.Resolver) []resolve.Resolver {
+	if p == nil {
+		return resolvers
+	}
+
+	var wg sync.WaitGroup
+	wrapped := make([]resolve.Resolver, 0, len(resolvers))
+	for _, r := range resolvers {
+		pr := &poisonResolver{
+			Resolver: r,
+			detector: p,
+			answers:  make(map[string]int),
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.check(pr)
+		}()
+
+		p.Lock()
+		p.resolvers = append(p.resolvers, pr)
+		p.Unlock()
+		wrapped = append(wrapped, pr)
+	}
+
+	wg.Wait()
+	return wrapped
+}
+
+// Poisoned returns the resolvers that have been ejected for returning poisoned answers.
+func (p *poisonDetector) Poisoned() []string {
+	if p == nil {
+		return nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	var poisoned []string
+	for _, pr := range p.resolvers {
+		if pr.poisoned {
+			poisoned = append(poisoned, pr.String())
+		}
+	}
+
+	sort.Strings(poisoned)
+	return poisoned
+}
+
+// check queries a canary name using the resolver, and ejects the resolver when an address is returned.
+func (p *poisonDetector) check(pr *poisonResolver) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolve.QueryTimeout)
+	defer cancel()
+
+	name := p.canary()
+	resp, err := pr.Resolver.Query(ctx, resolve.QueryMsg(name, dns.TypeA), resolve.PriorityHigh, nil)
+	if err != nil || resp == nil {
+		return
+	}
+
+	addrs := resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypeA)
+	if len(addrs) == 0 {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if !pr.poisoned {
+		pr.poisoned = true
+		p.log.Warn(fmt.Sprintf("Resolver %s has been ejected: the nonexistent name %s was answered with %s",
+			pr.String(), name, strings.TrimSpace(addrs[0].Data)), resolversField)
+	}
+}
+
+// record counts the address provided in an answer by the resolver, and returns true when
+// the resolver has provided the address enough times to be checked for poisoning.
+func (p *poisonDetector) record(pr *poisonResolver, addr string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if pr.poisoned {
+		return false
+	}
+
+	pr.answers[addr]++
+	return pr.answers[addr] == poisonSuspectAnswers
+}
+
+// poisonResolver is a Resolver that reports the addresses it provides to the detector
+// and appears stopped to the resolver pool once ejected.
+type poisonResolver struct {
+	resolve.Resolver
+	detector *poisonDetector
+	poisoned bool
+	answers  map[string]int
+}
+
+// Stopped implements the Resolver interface.
+func (pr *poisonResolver) Stopped() bool {
+	if pr.Resolver.Stopped() {
+		return true
+	}
+
+	pr.detector.Lock()
+	defer pr.detector.Unlock()
+
+	return pr.poisoned
+}
+
+// Query implements the Resolver interface.
+func (pr *poisonResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp, err := pr.Resolver.Query(ctx, msg, priority, retry)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	for _, a := range resolve.AnswersByType(resolve.ExtractAnswers(resp), dns.TypeA) {
+		// The check is performed once for each address, when the address becomes suspicious
+		if pr.detector.record(pr, strings.TrimSpace(a.Data)) {
+			go pr.detector.check(pr)
+		}
+	}
+	return resp, err
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// injectingResolver answers the queries for the canary names with the injected address once
+// poisoned, and answers every other query with the address.
+type injectingResolver struct {
+	sync.Mutex
+	addr     string
+	poisoned bool
+}
+
+func (r *injectingResolver) String() string { return r.addr }
+func (r *injectingResolver) Len() int       { return 0 }
+func (r *injectingResolver) Stop()          {}
+func (r *injectingResolver) Stopped() bool  { return false }
+
+func (r *injectingResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := msg.Copy()
+	resp.Response = true
+
+	r.Lock()
+	poisoned := r.poisoned
+	r.Unlock()
+
+	name := msg.Question[0].Name
+	if strings.HasPrefix(name, "canary.") && !poisoned {
+		resp.Rcode = dns.RcodeNameError
+		return resp, nil
+	}
+
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   []byte{10, 10, 10, 10},
+	})
+	return resp, nil
+}
+
+func (r *injectingResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestPoisonDetectorCanary(t *testing.T) {
+	p := newPoisonDetector(nil)
+	p.canary = func() string { return "canary.com" }
+
+	honest := &injectingResolver{addr: "192.168.1.1"}
+	injecting := &injectingResolver{addr: "192.168.1.2", poisoned: true}
+
+	wrapped := p.wrap([]resolve.Resolver{honest, injecting})
+	if wrapped[0].Stopped() {
+		t.Errorf("The resolver that answered the canary name correctly was ejected")
+	}
+	if !wrapped[1].Stopped() {
+		t.Errorf("The resolver that answered the canary name with an address was not ejected")
+	}
+	if poisoned := p.Poisoned(); len(poisoned) != 1 || poisoned[0] != "192.168.1.2" {
+		t.Errorf("The poisoned resolvers were not reported: %v", poisoned)
+	}
+}
+
+func TestPoisonDetectorSuspiciousAddress(t *testing.T) {
+	p := newPoisonDetector(nil)
+	p.canary = func() string { return "canary.com" }
+
+	r := &injectingResolver{addr: "192.168.1.1"}
+	wrapped := p.wrap([]resolve.Resolver{r})[0]
+	// The resolver starts injecting answers after passing the canary check
+	r.Lock()
+	r.poisoned = true
+	r.Unlock()
+
+	for i := 0; i < poisonSuspectAnswers; i++ {
+		if wrapped.Stopped() {
+			t.Fatalf("The resolver was ejected after %d answers", i)
+		}
+		_, _ = wrapped.Query(context.Background(), resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !wrapped.Stopped() {
+		if time.Now().After(deadline) {
+			t.Fatal("The resolver returning the same address for many names was not ejected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// QueryTypeStats provides the counters and latencies of the queries sent for a DNS record type.
+type QueryTypeStats struct {
+	Type     string
+	Queries  int
+	Failures int
+	// The average and the longest duration of the queries
+	Latency    time.Duration
+	MaxLatency time.Duration
+}
+
+type queryTypeCounters struct {
+	queries  int
+	failures int
+	total    time.Duration
+	max      time.Duration
+}
+
+// queryTypeResolver is a Resolver that tracks the queries sent through it by the record type
+// of the question. The failures are the queries that timed out or that the servers refused
+// or failed to answer, while the answers for names that do not exist are counted as successes.
+type queryTypeResolver struct {
+	resolve.Resolver
+	sync.Mutex
+	types map[uint16]*queryTypeCounters
+}
+
+func newQueryTypeResolver(r resolve.Resolver) *queryTypeResolver {
+	return &queryTypeResolver{
+		Resolver: r,
+		types:    make(map[uint16]*queryTypeCounters),
+	}
+}
+
+// Query implements the Resolver interface.
+func (qt *queryTypeResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if len(msg.Question) != 1 {
+		return qt.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	start := time.Now()
+	resp, err := qt.Resolver.Query(ctx, msg, priority, retry)
+	qt.record(msg.Question[0].Qtype, queryFailed(err), time.Since(start))
+	return resp, err
+}
+
+func (qt *queryTypeResolver) record(qtype uint16, failed bool, latency time.Duration) {
+	qt.Lock()
+	defer qt.Unlock()
+
+	c, found := qt.types[qtype]
+	if !found {
+		c = new(queryTypeCounters)
+		qt.types[qtype] = c
+	}
+
+	c.queries++
+	if failed {
+		c.failures++
+	}
+	c.total += latency
+	if latency > c.max {
+		c.max = latency
+	}
+}
+
+// Stats returns the counters of each record type queried, starting with the most queried type.
+func (qt *queryTypeResolver) Stats() []QueryTypeStats {
+	if qt == nil {
+		return nil
+	}
+
+	qt.Lock()
+	defer qt.Unlock()
+
+	stats := make([]QueryTypeStats, 0, len(qt.types))
+	for qtype, c := range qt.types {
+		stats = append(stats, QueryTypeStats{
+			Type:       dns.Type(qtype).String(),
+			Queries:    c.queries,
+			Failures:   c.failures,
+			Latency:    c.total / time.Duration(c.queries),
+			MaxLatency: c.max,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Queries == stats[j].Queries {
+			return stats[i].Type < stats[j].Type
+		}
+		return stats[i].Queries > stats[j].Queries
+	})
+	return stats
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"testing"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+func TestQueryTypeResolver(t *testing.T) {
+	ctx := context.Background()
+	success := newQueryTypeResolver(&countingResolver{rcode: dns.RcodeSuccess})
+	failure := newQueryTypeResolver(&countingResolver{rcode: dns.RcodeServerFailure})
+	nxdomain := newQueryTypeResolver(&countingResolver{rcode: dns.RcodeNameError})
+
+	for i := 0; i < 3; i++ {
+		_, _ = success.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	}
+	_, _ = success.Query(ctx, resolve.QueryMsg("owasp.org", dns.TypeMX), resolve.PriorityNormal, nil)
+	_, _ = failure.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeAAAA), resolve.PriorityNormal, nil)
+	_, _ = nxdomain.Query(ctx, resolve.QueryMsg("none.owasp.org", dns.TypeCNAME), resolve.PriorityNormal, nil)
+
+	stats := success.Stats()
+	if len(stats) != 2 || stats[0].Type != "A" || stats[0].Queries != 3 || stats[1].Type != "MX" || stats[1].Queries != 1 {
+		t.Fatalf("The query types were not counted in order: %+v", stats)
+	}
+	if stats[0].Failures != 0 || stats[0].MaxLatency < stats[0].Latency {
+		t.Errorf("The A queries were not measured correctly: %+v", stats[0])
+	}
+	if stats := failure.Stats(); len(stats) != 1 || stats[0].Type != "AAAA" || stats[0].Failures != 1 {
+		t.Errorf("The failed query was not counted: %+v", stats)
+	}
+	if stats := nxdomain.Stats(); len(stats) != 1 || stats[0].Type != "CNAME" || stats[0].Failures != 0 {
+		t.Errorf("The NXDOMAIN response was counted as a failure: %+v", stats)
+	}
+
+	var qt *queryTypeResolver
+	if qt.Stats() != nil {
+		t.Error("Counters were returned without a resolver")
+	}
+}
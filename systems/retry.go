@@ -0,0 +1,82 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// retryResolver is a Resolver that performs the queries of the wrapped Resolver again,
+// with exponential backoff, when they fail due to transient errors.
+type retryResolver struct {
+	resolve.Resolver
+	retries int
+	base    time.Duration
+}
+
+func newRetryResolver(r resolve.Resolver, retries int) resolve.Resolver {
+	return &retryResolver{
+		Resolver: r,
+		retries:  retries,
+		base:     retryBaseDelay,
+	}
+}
+
+// Query implements the Resolver interface.
+func (rr *retryResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp, err := rr.Resolver.Query(ctx, msg, priority, retry)
+
+	for attempt := 1; attempt <= rr.retries && transientError(err); attempt++ {
+		t := time.NewTimer(rr.backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return resp, err
+		case <-t.C:
+		}
+
+		resp, err = rr.Resolver.Query(ctx, msg, priority, retry)
+	}
+	return resp, err
+}
+
+// backoff returns the delay before the attempt, which doubles with each attempt. The jitter
+// keeps the retries of queries that failed together from being sent at the same time.
+func (rr *retryResolver) backoff(attempt int) time.Duration {
+	d := rr.base << uint(attempt-1)
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// transientError returns true when the query failed due to a timeout, SERVFAIL or REFUSED.
+// A NXDOMAIN response is an answer, and the query is not performed again.
+func transientError(err error) bool {
+	var re *resolve.ResolveError
+
+	if err == nil || !errors.As(err, &re) {
+		return false
+	}
+
+	switch re.Rcode {
+	case resolve.TimeoutRcode, dns.RcodeServerFailure, dns.RcodeRefused:
+		return true
+	}
+	return false
+}
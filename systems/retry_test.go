@@ -0,0 +1,91 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// rcodeResolver returns each rcode in order, followed by successful responses.
+type rcodeResolver struct {
+	sync.Mutex
+	rcodes  []int
+	queries int
+}
+
+func (r *rcodeResolver) String() string { return "rcode" }
+func (r *rcodeResolver) Len() int       { return 0 }
+func (r *rcodeResolver) Stop()          {}
+func (r *rcodeResolver) Stopped() bool  { return false }
+
+func (r *rcodeResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.queries++
+	resp := msg.Copy()
+	resp.Response = true
+	if len(r.rcodes) == 0 {
+		return resp, nil
+	}
+
+	rcode := r.rcodes[0]
+	r.rcodes = r.rcodes[1:]
+	resp.Rcode = rcode
+	return resp, &resolve.ResolveError{Err: "failed", Rcode: rcode}
+}
+
+func (r *rcodeResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestRetryResolver(t *testing.T) {
+	tests := []struct {
+		name    string
+		rcodes  []int
+		retries int
+		success bool
+		queries int
+	}{
+		{"transient errors", []int{resolve.TimeoutRcode, dns.RcodeServerFailure, dns.RcodeRefused}, 3, true, 4},
+		{"retries exhausted", []int{dns.RcodeServerFailure, dns.RcodeServerFailure}, 1, false, 2},
+		{"name error", []int{dns.RcodeNameError}, 3, false, 1},
+	}
+
+	msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+	for _, tt := range tests {
+		r := &rcodeResolver{rcodes: tt.rcodes}
+		rr := newRetryResolver(r, tt.retries).(*retryResolver)
+		rr.base = time.Millisecond
+
+		_, err := rr.Query(context.Background(), msg, resolve.PriorityNormal, nil)
+		if (err == nil) != tt.success {
+			t.Errorf("%s: Query returned the error %v", tt.name, err)
+		}
+		if r.queries != tt.queries {
+			t.Errorf("%s: %d queries were performed, want %d", tt.name, r.queries, tt.queries)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	rr := &retryResolver{base: 100 * time.Millisecond}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		max := rr.base << uint(attempt-1)
+		if max > retryMaxDelay {
+			max = retryMaxDelay
+		}
+
+		if d := rr.backoff(attempt); d < max/2 || d > max {
+			t.Errorf("Attempt %d backed off for %v, outside of [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+}
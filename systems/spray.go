@@ -0,0 +1,279 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// The addresses assigned to the interfaces of the host, which are replaced by the tests
+var interfaceAddrs = net.InterfaceAddrs
+
+// sprayResolver sends the DNS queries to a resolver from each of the local source addresses in turn,
+// spreading the queries across the egress addresses of the host to avoid the rate limits that the
+// upstream resolvers apply to each source. The truncated responses are requested again via TCP.
+type sprayResolver struct {
+	sync.Mutex
+	addr      string
+	sources   []net.IP
+	next      int
+	log       *log.Logger
+	done      chan struct{}
+	stopped   bool
+	inflight  int
+	limiter   *time.Ticker
+	wildcards *wildcardDetector
+}
+
+// newSprayResolver returns a Resolver that sends queries to the address from the source addresses of
+// the same family. The rate limit applies to each source address. Nil is returned when none of the
+// source addresses can reach the resolver.
+func newSprayResolver(addr string, sources []net.IP, perSec int, logger *log.Logger) resolve.Resolver {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		// Add the default port number to the IP address
+		addr = net.JoinHostPort(addr, "53")
+	}
+
+	host, _, _ := net.SplitHostPort(addr)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+
+	var matched []net.IP
+	for _, src := range sources {
+		if (src.To4() != nil) == (ip.To4() != nil) {
+			matched = append(matched, src)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	if perSec <= 0 {
+		perSec = 1
+	}
+
+	r := &sprayResolver{
+		addr:    addr,
+		sources: matched,
+		log:     logger,
+		done:    make(chan struct{}),
+		limiter: time.NewTicker(time.Second / time.Duration(perSec*len(matched))),
+	}
+
+	r.wildcards = newWildcardDetector(r, 0, config.NewStdLogger(logger))
+	return r
+}
+
+// String implements the Stringer interface.
+func (r *sprayResolver) String() string {
+	return r.addr
+}
+
+// Len implements the Resolver interface.
+func (r *sprayResolver) Len() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.inflight
+}
+
+// Stop implements the Resolver interface.
+func (r *sprayResolver) Stop() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	r.stopped = true
+	r.limiter.Stop()
+	close(r.done)
+}
+
+// Stopped implements the Resolver interface.
+func (r *sprayResolver) Stopped() bool {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.stopped
+}
+
+// Query implements the Resolver interface.
+func (r *sprayResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if priority != resolve.PriorityCritical && priority != resolve.PriorityHigh &&
+		priority != resolve.PriorityNormal && priority != resolve.PriorityLow {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: invalid priority parameter: %d", priority),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+	if r.Stopped() {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s has been stopped", r.String()),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	var err error
+	var resp *dns.Msg
+	for times := 1; ; times++ {
+		select {
+		case <-ctx.Done():
+			return nil, &resolve.ResolveError{
+				Err:   "Resolver: the request context expired",
+				Rcode: resolve.ResolverErrRcode,
+			}
+		case <-r.done:
+			return nil, &resolve.ResolveError{
+				Err:   fmt.Sprintf("Resolver: %s has been stopped", r.String()),
+				Rcode: resolve.ResolverErrRcode,
+			}
+		case <-r.limiter.C:
+		}
+
+		resp, err = r.exchange(msg)
+		if err == nil || retry == nil {
+			break
+		}
+
+		failed := resp
+		if failed == nil {
+			failed = msg.Copy()
+			failed.Rcode = err.(*resolve.ResolveError).Rcode
+		}
+		if !retry(times, priority, failed) {
+			break
+		}
+	}
+
+	return resp, err
+}
+
+// nextSource returns the source address of the next query.
+func (r *sprayResolver) nextSource() net.IP {
+	r.Lock()
+	defer r.Unlock()
+
+	src := r.sources[r.next]
+	r.next = (r.next + 1) % len(r.sources)
+	r.inflight++
+	return src
+}
+
+func (r *sprayResolver) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	src := r.nextSource()
+	defer func() {
+		r.Lock()
+		r.inflight--
+		r.Unlock()
+	}()
+
+	client := &dns.Client{
+		Net:     "udp",
+		UDPSize: dns.DefaultMsgSize,
+		Dialer: &net.Dialer{
+			Timeout:   resolve.QueryTimeout,
+			LocalAddr: &net.UDPAddr{IP: src},
+		},
+	}
+
+	resp, _, err := client.Exchange(msg, r.addr)
+	if err == nil && resp != nil && resp.Truncated {
+		client.Net = "tcp"
+		client.Dialer.LocalAddr = &net.TCPAddr{IP: src}
+		resp, _, err = client.Exchange(msg, r.addr)
+	}
+	if err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: the query from %s timed out: %v", r.addr, src, err),
+			Rcode: resolve.TimeoutRcode,
+		}
+	}
+
+	if resp.Rcode != dns.RcodeSuccess {
+		return resp, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s returned the %s rcode", r.addr, dns.RcodeToString[resp.Rcode]),
+			Rcode: resp.Rcode,
+		}
+	}
+	return resp, nil
+}
+
+// WildcardType implements the Resolver interface.
+func (r *sprayResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return r.wildcards.WildcardType(ctx, msg, domain)
+}
+
+// InvalidateWildcard implements the WildcardInvalidator interface.
+func (r *sprayResolver) InvalidateWildcard(zone string) {
+	r.wildcards.InvalidateWildcard(zone)
+}
+
+// sourceAddresses returns the source addresses of the configuration, once they have been shown to
+// be assigned to the interfaces of the host.
+func sourceAddresses(c *config.Config) ([]net.IP, error) {
+	if len(c.SourceIPs) == 0 {
+		return nil, nil
+	}
+
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain the addresses of the host interfaces: %v", err)
+	}
+
+	var sources []net.IP
+	for _, s := range c.SourceIPs {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("%s is not a valid source IP address", s)
+		}
+
+		var assigned bool
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			return nil, fmt.Errorf("the source IP address %s is not assigned to this host", s)
+		}
+		sources = append(sources, ip)
+	}
+	return sources, nil
+}
+
+// NewResolver returns the Resolver sending queries to the address, over TCP through the proxy when one
+// is provided, for the callers that build the resolvers outside of the pool.
+func NewResolver(addr string, p *amassnet.Proxy, perSec int, logger *log.Logger) resolve.Resolver {
+	return newUpstreamResolver(addr, nil, p, perSec, logger)
+}
+
+// newUpstreamResolver returns the Resolver sending queries to the address, from the source addresses
+// in turn when provided. Otherwise, or when none of them share the family of the address, the queries
+// are sent from the address selected by the operating system. The queries are sent over TCP through
+// the proxy when one is provided, which selects the source address instead.
+func newUpstreamResolver(addr string, sources []net.IP, p *amassnet.Proxy, perSec int, logger *log.Logger) resolve.Resolver {
+	if p != nil {
+		return newTCPProxyResolver(addr, p, perSec, logger)
+	}
+	if len(sources) > 0 {
+		if r := newSprayResolver(addr, sources, perSec, logger); r != nil {
+			return r
+		}
+	}
+	return resolve.NewBaseResolver(addr, perSec, logger)
+}
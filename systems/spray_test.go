@@ -0,0 +1,100 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+func TestSprayResolverSources(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for the DNS queries: %v", err)
+	}
+
+	var lock sync.Mutex
+	seen := make(map[string]int)
+	srv := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		host, _, _ := net.SplitHostPort(w.RemoteAddr().String())
+		lock.Lock()
+		seen[host]++
+		lock.Unlock()
+
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP("192.168.1.1"),
+		})
+		_ = w.WriteMsg(resp)
+	})}
+	go func() { _ = srv.ActivateAndServe() }()
+	defer func() { _ = srv.Shutdown() }()
+
+	sources := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.2"), net.ParseIP("::1")}
+	r := newUpstreamResolver(pc.LocalAddr().String(), sources, nil, 100, log.New(ioutil.Discard, "", 0))
+	defer r.Stop()
+
+	spray, ok := r.(*sprayResolver)
+	if !ok {
+		t.Fatal("The resolver was not bound to the source addresses")
+	}
+	if len(spray.sources) != 2 {
+		t.Errorf("The IPv6 source address was used for the IPv4 resolver: %v", spray.sources)
+	}
+
+	for i := 0; i < 4; i++ {
+		msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+
+		if resp, err := r.Query(context.Background(), msg, resolve.PriorityNormal, nil); err != nil || len(resp.Answer) != 1 {
+			t.Fatalf("The query failed: %v", err)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if seen["127.0.0.1"] != 2 || seen["127.0.0.2"] != 2 {
+		t.Errorf("The queries were not sent from the source addresses in turn: %v", seen)
+	}
+}
+
+func TestSprayResolverFamilies(t *testing.T) {
+	r := newUpstreamResolver("192.0.2.53", []net.IP{net.ParseIP("::1")}, nil, 10, log.New(ioutil.Discard, "", 0))
+	defer r.Stop()
+
+	if _, ok := r.(*sprayResolver); ok {
+		t.Error("The IPv4 resolver was bound to the IPv6 source address")
+	}
+}
+
+func TestSourceAddresses(t *testing.T) {
+	orig := interfaceAddrs
+	defer func() { interfaceAddrs = orig }()
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("192.0.2.1"), Mask: net.CIDRMask(24, 32)},
+			&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(64, 128)},
+		}, nil
+	}
+
+	cfg := config.NewConfig()
+	cfg.SourceIPs = []string{"192.0.2.1", "2001:db8::1"}
+	if sources, err := sourceAddresses(cfg); err != nil || len(sources) != 2 {
+		t.Errorf("The assigned source addresses were not accepted: %v", err)
+	}
+
+	cfg.SourceIPs = []string{"192.0.2.1", "192.0.2.2"}
+	if _, err := sourceAddresses(cfg); err == nil {
+		t.Error("The source address not assigned to the host was accepted")
+	}
+}
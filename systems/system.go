@@ -58,6 +58,7 @@ func PopulateCache(ctx context.Context, asn int, sys System) {
 	defer bus.Unsubscribe(requests.NewASNTopic, cache.Update)
 
 	ctx = context.WithValue(ctx, requests.ContextConfig, sys.Config())
+	ctx = sys.Config().ProxyContext(ctx)
 	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
 
 	// Send the ASN requests to the data sources
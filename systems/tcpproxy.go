@@ -0,0 +1,233 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// The most idle connections kept open through the proxy for each resolver
+const maxIdleProxyConns int = 4
+
+// tcpProxyResolver performs DNS queries over TCP connections established through the proxy, since the
+// queries sent over UDP cannot pass through the socks5:// or http(s):// proxies. The connections are
+// reused for the following queries, as described in RFC 7766.
+type tcpProxyResolver struct {
+	sync.Mutex
+	addr      string
+	proxy     *amassnet.Proxy
+	idle      []*dns.Conn
+	log       *log.Logger
+	done      chan struct{}
+	stopped   bool
+	inflight  int
+	limiter   *time.Ticker
+	wildcards *wildcardDetector
+}
+
+func newTCPProxyResolver(addr string, p *amassnet.Proxy, perSec int, logger *log.Logger) resolve.Resolver {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		// Add the default port number to the IP address
+		addr = net.JoinHostPort(addr, "53")
+	}
+	if p == nil || perSec <= 0 {
+		return nil
+	}
+
+	r := &tcpProxyResolver{
+		addr:    addr,
+		proxy:   p,
+		log:     logger,
+		done:    make(chan struct{}),
+		limiter: time.NewTicker(time.Second / time.Duration(perSec)),
+	}
+
+	r.wildcards = newWildcardDetector(r, 0, config.NewStdLogger(logger))
+	return r
+}
+
+// String implements the Stringer interface.
+func (r *tcpProxyResolver) String() string {
+	return r.addr
+}
+
+// Len implements the Resolver interface.
+func (r *tcpProxyResolver) Len() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.inflight
+}
+
+// Stop implements the Resolver interface.
+func (r *tcpProxyResolver) Stop() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	r.stopped = true
+	r.limiter.Stop()
+	close(r.done)
+	for _, conn := range r.idle {
+		conn.Close()
+	}
+	r.idle = nil
+}
+
+// Stopped implements the Resolver interface.
+func (r *tcpProxyResolver) Stopped() bool {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.stopped
+}
+
+// Query implements the Resolver interface.
+func (r *tcpProxyResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if priority != resolve.PriorityCritical && priority != resolve.PriorityHigh &&
+		priority != resolve.PriorityNormal && priority != resolve.PriorityLow {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: invalid priority parameter: %d", priority),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+	if r.Stopped() {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s has been stopped", r.String()),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+
+	var err error
+	var resp *dns.Msg
+	for times := 1; ; times++ {
+		select {
+		case <-ctx.Done():
+			return nil, &resolve.ResolveError{
+				Err:   "Resolver: the request context expired",
+				Rcode: resolve.ResolverErrRcode,
+			}
+		case <-r.done:
+			return nil, &resolve.ResolveError{
+				Err:   fmt.Sprintf("Resolver: %s has been stopped", r.String()),
+				Rcode: resolve.ResolverErrRcode,
+			}
+		case <-r.limiter.C:
+		}
+
+		resp, err = r.exchange(ctx, msg)
+		if err == nil || retry == nil {
+			break
+		}
+
+		failed := resp
+		if failed == nil {
+			failed = msg.Copy()
+			failed.Rcode = err.(*resolve.ResolveError).Rcode
+		}
+		if !retry(times, priority, failed) {
+			break
+		}
+	}
+
+	return resp, err
+}
+
+func (r *tcpProxyResolver) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	r.Lock()
+	r.inflight++
+	r.Unlock()
+	defer func() {
+		r.Lock()
+		r.inflight--
+		r.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, resolve.QueryTimeout)
+	defer cancel()
+
+	conn, err := r.conn(ctx)
+	if err != nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: failed to connect through the proxy: %v", r.addr, err),
+			Rcode: resolve.TimeoutRcode,
+		}
+	}
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	resp, _, err := new(dns.Client).ExchangeWithConn(msg, conn)
+	if err != nil {
+		conn.Close()
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s: the query timed out: %v", r.addr, err),
+			Rcode: resolve.TimeoutRcode,
+		}
+	}
+	r.release(conn)
+
+	if resp.Rcode != dns.RcodeSuccess {
+		return resp, &resolve.ResolveError{
+			Err:   fmt.Sprintf("Resolver: %s returned the %s rcode", r.addr, dns.RcodeToString[resp.Rcode]),
+			Rcode: resp.Rcode,
+		}
+	}
+	return resp, nil
+}
+
+// conn returns an idle connection with the resolver, or a new connection established through the proxy.
+func (r *tcpProxyResolver) conn(ctx context.Context) (*dns.Conn, error) {
+	r.Lock()
+	if num := len(r.idle); num > 0 {
+		conn := r.idle[num-1]
+		r.idle = r.idle[:num-1]
+		r.Unlock()
+		return conn, nil
+	}
+	r.Unlock()
+
+	c, err := r.proxy.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, err
+	}
+	return &dns.Conn{Conn: c}, nil
+}
+
+// release keeps the connection for the following queries, unless enough connections are idle.
+func (r *tcpProxyResolver) release(conn *dns.Conn) {
+	_ = conn.SetDeadline(time.Time{})
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.stopped || len(r.idle) >= maxIdleProxyConns {
+		conn.Close()
+		return
+	}
+	r.idle = append(r.idle, conn)
+}
+
+// WildcardType implements the Resolver interface.
+func (r *tcpProxyResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return r.wildcards.WildcardType(ctx, msg, domain)
+}
+
+// InvalidateWildcard implements the WildcardInvalidator interface.
+func (r *tcpProxyResolver) InvalidateWildcard(zone string) {
+	r.wildcards.InvalidateWildcard(zone)
+}
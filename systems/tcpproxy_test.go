@@ -0,0 +1,133 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// connectProxy relays the connections requested with the CONNECT method, and counts them.
+type connectProxy struct {
+	sync.Mutex
+	lis     net.Listener
+	targets []string
+}
+
+func newConnectProxy(t *testing.T) *connectProxy {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start the proxy listener: %v", err)
+	}
+
+	p := &connectProxy{lis: lis}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go p.relay(conn)
+		}
+	}()
+	return p
+}
+
+func (p *connectProxy) relay(conn net.Conn) {
+	defer conn.Close()
+
+	rd := bufio.NewReader(conn)
+	req, err := http.ReadRequest(rd)
+	if err != nil || req.Method != http.MethodConnect {
+		return
+	}
+
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer target.Close()
+
+	p.Lock()
+	p.targets = append(p.targets, req.Host)
+	p.Unlock()
+
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n")
+	go func() { _, _ = io.Copy(target, rd) }()
+	_, _ = io.Copy(conn, target)
+}
+
+func (p *connectProxy) connections() []string {
+	p.Lock()
+	defer p.Unlock()
+
+	return append([]string(nil), p.targets...)
+}
+
+func TestTCPProxyResolver(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for the DNS queries: %v", err)
+	}
+
+	srv := &dns.Server{Listener: lis, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP("192.168.1.1"),
+		})
+		_ = w.WriteMsg(resp)
+	})}
+	go func() { _ = srv.ActivateAndServe() }()
+	defer func() { _ = srv.Shutdown() }()
+
+	cp := newConnectProxy(t)
+	defer cp.lis.Close()
+
+	p, err := amassnet.NewProxy("http://" + cp.lis.Addr().String())
+	if err != nil {
+		t.Fatalf("NewProxy failed: %v", err)
+	}
+
+	r := NewResolver(lis.Addr().String(), p, 100, log.New(ioutil.Discard, "", 0))
+	if _, ok := r.(*tcpProxyResolver); !ok {
+		t.Fatal("The resolver does not send the queries through the proxy")
+	}
+	defer r.Stop()
+
+	for i := 0; i < 3; i++ {
+		msg := resolve.QueryMsg("www.owasp.org", dns.TypeA)
+
+		if resp, err := r.Query(context.Background(), msg, resolve.PriorityNormal, nil); err != nil || len(resp.Answer) != 1 {
+			t.Fatalf("The query through the proxy failed: %v", err)
+		}
+	}
+
+	// The connection established through the proxy is reused for the following queries
+	if conns := cp.connections(); len(conns) != 1 || conns[0] != lis.Addr().String() {
+		t.Errorf("The queries were sent over the connections %v", conns)
+	}
+
+	// The resolvers of the configurations without a proxy are not affected
+	if r := NewResolver(lis.Addr().String(), nil, 100, log.New(ioutil.Discard, "", 0)); r != nil {
+		defer r.Stop()
+
+		if _, ok := r.(*tcpProxyResolver); ok {
+			t.Error("The resolver without a proxy sends the queries through one")
+		}
+	}
+}
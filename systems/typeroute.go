@@ -0,0 +1,89 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// typeRoutingResolver is a Resolver that sends the queries for the record types excluded by some of the
+// resolvers to a pool of the remaining resolvers, so a resolver mangling the answers of a record type
+// cannot corrupt the results, while still answering the queries for the other types.
+type typeRoutingResolver struct {
+	resolve.Resolver
+	types map[uint16]resolve.Resolver
+}
+
+// newResolverPool returns the pool of the resolvers, with the queries for each record type routed
+// away from the resolvers that exclude the type in the configuration.
+func newResolverPool(cfg *config.Config, resolvers []resolve.Resolver, baseline resolve.Resolver) resolve.Resolver {
+	pool := resolve.NewResolverPool(resolvers, baseline, 1, cfg.Log)
+	if pool == nil || len(cfg.ResolverTypeExclusions) == 0 {
+		return pool
+	}
+
+	excluded := make(map[uint16]map[int]struct{})
+	for i, r := range resolvers {
+		for _, t := range cfg.ResolverExcludedTypes(r.String()) {
+			if excluded[t] == nil {
+				excluded[t] = make(map[int]struct{})
+			}
+			excluded[t][i] = struct{}{}
+		}
+	}
+	if len(excluded) == 0 {
+		return pool
+	}
+
+	tr := &typeRoutingResolver{
+		Resolver: pool,
+		types:    make(map[uint16]resolve.Resolver),
+	}
+	for t, set := range excluded {
+		var eligible []resolve.Resolver
+		for i, r := range resolvers {
+			if _, found := set[i]; !found {
+				eligible = append(eligible, r)
+			}
+		}
+		if len(eligible) > 0 {
+			tr.types[t] = resolve.NewResolverPool(eligible, baseline, 1, cfg.Log)
+			continue
+		}
+		// The baseline resolvers answer the queries when every resolver excludes the type
+		if baseline != nil {
+			tr.types[t] = baseline
+			continue
+		}
+		tr.types[t] = nil
+		cfg.Leveled().Warn(fmt.Sprintf("All the resolvers exclude the %s record type, so the queries "+
+			"for the type will not be sent", dns.TypeToString[t]), resolversField)
+	}
+	return tr
+}
+
+// Query implements the Resolver interface.
+func (tr *typeRoutingResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	if len(msg.Question) != 1 {
+		return tr.Resolver.Query(ctx, msg, priority, retry)
+	}
+
+	qtype := msg.Question[0].Qtype
+	pool, found := tr.types[qtype]
+	if !found {
+		return tr.Resolver.Query(ctx, msg, priority, retry)
+	}
+	if pool == nil {
+		return nil, &resolve.ResolveError{
+			Err:   fmt.Sprintf("all the resolvers exclude the %s record type", dns.TypeToString[qtype]),
+			Rcode: resolve.ResolverErrRcode,
+		}
+	}
+	return pool.Query(ctx, msg, priority, retry)
+}
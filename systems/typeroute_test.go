@@ -0,0 +1,73 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// answeringResolver records the record types it was queried for.
+type answeringResolver struct {
+	zoneServer
+	sync.Mutex
+	queried map[uint16]int
+}
+
+func (r *answeringResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	r.queried[msg.Question[0].Qtype]++
+	r.Unlock()
+
+	return r.zoneServer.Query(ctx, msg, priority, retry)
+}
+
+func (r *answeringResolver) count(qtype uint16) int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.queried[qtype]
+}
+
+func TestTypeRoutingResolver(t *testing.T) {
+	mangler := &answeringResolver{zoneServer: zoneServer{addr: "192.168.1.1:53"}, queried: make(map[uint16]int)}
+	other := &answeringResolver{zoneServer: zoneServer{addr: "192.168.1.2:53"}, queried: make(map[uint16]int)}
+
+	cfg := config.NewConfig()
+	cfg.AddResolverTypeExclusions("192.168.1.1", "txt", "SRV")
+	pool := newResolverPool(cfg, []resolve.Resolver{mangler, other}, nil)
+	defer pool.Stop()
+
+	for i := 0; i < 20; i++ {
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeTXT, dns.TypeSRV} {
+			if _, err := pool.Query(context.Background(), resolve.QueryMsg("www.owasp.org", qtype), resolve.PriorityLow, nil); err != nil {
+				t.Fatalf("the query failed: %v", err)
+			}
+		}
+	}
+
+	if n := mangler.count(dns.TypeTXT) + mangler.count(dns.TypeSRV); n != 0 {
+		t.Errorf("the resolver was sent %d queries for the excluded types", n)
+	}
+	if other.count(dns.TypeTXT) != 20 || other.count(dns.TypeSRV) != 20 {
+		t.Error("the queries for the excluded types were not sent to the other resolver")
+	}
+	if mangler.count(dns.TypeA)+other.count(dns.TypeA) != 20 {
+		t.Error("the queries for the other types were not sent to the pool")
+	}
+
+	// The queries fail when no resolver is eligible for the type
+	cfg.AddResolverTypeExclusions("192.168.1.2:53", "TXT")
+	pool = newResolverPool(cfg, []resolve.Resolver{mangler, other}, nil)
+	defer pool.Stop()
+
+	if _, err := pool.Query(context.Background(), resolve.QueryMsg("www.owasp.org", dns.TypeTXT), resolve.PriorityLow, nil); err == nil {
+		t.Error("the query was sent to a resolver excluding the type")
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+)
+
+const numOfWildcardTests = 3
+
+// The record types queried for the unlikely names when the wildcards of other types are tested
+var wildcardQueryTypes = []uint16{
+	dns.TypeCNAME,
+	dns.TypeA,
+	dns.TypeAAAA,
+}
+
+// WildcardInvalidator is implemented by resolvers that cache DNS wildcard verdicts.
+type WildcardInvalidator interface {
+	// InvalidateWildcard removes the cached verdicts for the zone and the subdomains within it.
+	InvalidateWildcard(zone string)
+}
+
+// wildcardKey identifies the verdict for the record type of a subdomain. The qtype is zero
+// for the verdict of the combined wildcardQueryTypes.
+type wildcardKey struct {
+	sub   string
+	qtype uint16
+}
+
+// wildcard is the verdict for a subdomain, which is never modified once stored, since the
+// callers of fetchWildcard continue to read it after the verdict is invalidated or replaced.
+type wildcard struct {
+	WildcardType int
+	Answers      map[string]struct{}
+	Expires      time.Time
+}
+
+// wildcardDetector tests subdomains for DNS wildcards using the queries of a Resolver, and caches
+// the verdict for each subdomain and address record type until the TTL has elapsed. The A, AAAA and
+// CNAME records are tested separately, since a zone may only have a wildcard for one address family.
+type wildcardDetector struct {
+	sync.Mutex
+	resolver  resolve.Resolver
+	ttl       time.Duration
+	log       config.Logger
+	wildcards map[wildcardKey]*wildcard
+	// Incremented by each invalidation, so the tests started before it do not store their verdicts
+	gen uint64
+}
+
+func newWildcardDetector(r resolve.Resolver, ttl time.Duration, logger config.Logger) *wildcardDetector {
+	return &wildcardDetector{
+		resolver:  r,
+		ttl:       ttl,
+		log:       logger,
+		wildcards: make(map[wildcardKey]*wildcard),
+	}
+}
+
+// WildcardType returns the DNS wildcard type for the FQDN in the provided message, using the
+// verdict for the record type of the question.
+func (wd *wildcardDetector) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	name := strings.ToLower(resolve.RemoveLastDot(msg.Question[0].Name))
+	qtype := wildcardQueryType(msg.Question[0].Qtype)
+	domain = strings.ToLower(resolve.RemoveLastDot(domain))
+
+	base := len(strings.Split(domain, "."))
+	labels := strings.Split(name, ".")
+	if len(labels) > base {
+		labels = labels[1:]
+	}
+
+	// Check for a DNS wildcard at each label starting with the root domain
+	for i := len(labels) - base; i >= 0; i-- {
+		w := wd.fetchWildcard(ctx, wildcardKey{sub: strings.Join(labels[i:], "."), qtype: qtype})
+
+		if w.WildcardType == resolve.WildcardTypeDynamic {
+			return resolve.WildcardTypeDynamic
+		} else if w.WildcardType == resolve.WildcardTypeStatic {
+			if len(msg.Answer) == 0 {
+				return w.WildcardType
+			}
+
+			for _, a := range resolve.ExtractAnswers(msg) {
+				if _, found := w.Answers[strings.Trim(a.Data, ".")]; found {
+					return w.WildcardType
+				}
+			}
+		}
+	}
+
+	return resolve.WildcardTypeNone
+}
+
+// wildcardQueryType returns the record type tested for the questions of the type,
+// which is zero for the types other than the wildcardQueryTypes.
+func wildcardQueryType(qtype uint16) uint16 {
+	for _, t := range wildcardQueryTypes {
+		if t == qtype {
+			return qtype
+		}
+	}
+	return 0
+}
+
+// InvalidateWildcard implements the WildcardInvalidator interface.
+func (wd *wildcardDetector) InvalidateWildcard(zone string) {
+	zone = strings.ToLower(resolve.RemoveLastDot(strings.TrimSpace(zone)))
+	if zone == "" {
+		return
+	}
+
+	wd.Lock()
+	defer wd.Unlock()
+
+	wd.gen++
+	for k := range wd.wildcards {
+		if k.sub == zone || strings.HasSuffix(k.sub, "."+zone) {
+			delete(wd.wildcards, k)
+		}
+	}
+}
+
+func (wd *wildcardDetector) fetchWildcard(ctx context.Context, k wildcardKey) *wildcard {
+	wd.Lock()
+	w, found := wd.wildcards[k]
+	gen := wd.gen
+	wd.Unlock()
+
+	if found && (w.Expires.IsZero() || time.Now().Before(w.Expires)) {
+		return w
+	}
+
+	w = wd.wildcardTest(ctx, k)
+	if wd.ttl > 0 {
+		w.Expires = time.Now().Add(wd.ttl)
+	}
+
+	wd.Lock()
+	// The verdict could be based on the answers from before the zone was invalidated
+	if wd.gen == gen {
+		wd.wildcards[k] = w
+	}
+	wd.Unlock()
+	return w
+}
+
+func (wd *wildcardDetector) wildcardTest(ctx context.Context, k wildcardKey) *wildcard {
+	sub := k.sub
+	types := wildcardQueryTypes
+	if k.qtype != 0 {
+		types = []uint16{k.qtype}
+	}
+
+	var retRecords bool
+	set := stringset.New()
+	defer set.Close()
+
+	// Query multiple times with unlikely names against this subdomain
+	for i := 0; i < numOfWildcardTests; i++ {
+		var name string
+
+		// Generate the unlikely label / name
+		for j := 0; j < 10; j++ {
+			name = resolve.UnlikelyName(sub)
+			if name != "" {
+				break
+			}
+		}
+
+		ans := stringset.New()
+		for _, t := range types {
+			msg := resolve.QueryMsg(name, t)
+
+			if resp, err := wd.resolver.Query(ctx, msg, resolve.PriorityCritical, resolve.RetryPolicy); err == nil && len(resp.Answer) > 0 {
+				retRecords = true
+				for _, a := range resolve.ExtractAnswers(resp) {
+					ans.Insert(strings.Trim(a.Data, "."))
+				}
+			}
+		}
+
+		if i == 0 {
+			set.Union(ans)
+		} else {
+			set.Intersect(ans)
+		}
+		ans.Close()
+	}
+
+	// Determine whether the subdomain has a DNS wildcard, and if so, which type is it?
+	wildcardType := resolve.WildcardTypeNone
+	if retRecords {
+		wildcardType = resolve.WildcardTypeStatic
+
+		if set.Len() == 0 {
+			wildcardType = resolve.WildcardTypeDynamic
+		}
+
+		rtype := "any"
+		if k.qtype != 0 {
+			rtype = dns.TypeToString[k.qtype]
+		}
+
+		wd.log.Info(fmt.Sprintf("DNS wildcard detected: Resolver %s: %s: type: %d: record type: %s",
+			wd.resolver.String(), "*."+sub, wildcardType, rtype), resolversField, config.ZoneField(sub))
+	}
+
+	answers := make(map[string]struct{}, set.Len())
+	for _, a := range set.Slice() {
+		answers[a] = struct{}{}
+	}
+
+	return &wildcard{
+		WildcardType: wildcardType,
+		Answers:      answers,
+	}
+}
+
+// wildcardCache is a Resolver that replaces the DNS wildcard detection of the wrapped
+// Resolver with verdicts that expire and can be invalidated for a zone.
+type wildcardCache struct {
+	resolve.Resolver
+	detector *wildcardDetector
+}
+
+func newWildcardCache(r resolve.Resolver, ttl time.Duration, logger config.Logger) resolve.Resolver {
+	return &wildcardCache{
+		Resolver: r,
+		detector: newWildcardDetector(r, ttl, logger),
+	}
+}
+
+// WildcardType implements the Resolver interface.
+func (wc *wildcardCache) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return wc.detector.WildcardType(ctx, msg, domain)
+}
+
+// InvalidateWildcard implements the WildcardInvalidator interface.
+func (wc *wildcardCache) InvalidateWildcard(zone string) {
+	wc.detector.InvalidateWildcard(zone)
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/caffix/resolve"
+	"github.com/miekg/dns"
+)
+
+// wildcardResolver answers every query within the zone with the same address.
+type wildcardResolver struct {
+	sync.Mutex
+	zone    string
+	queries int
+}
+
+func (r *wildcardResolver) String() string { return "wildcard" }
+func (r *wildcardResolver) Len() int       { return 0 }
+func (r *wildcardResolver) Stop()          {}
+func (r *wildcardResolver) Stopped() bool  { return false }
+
+func (r *wildcardResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.Lock()
+	r.queries++
+	r.Unlock()
+
+	resp := msg.Copy()
+	resp.Response = true
+	name := msg.Question[0].Name
+	if msg.Question[0].Qtype == dns.TypeA && strings.HasSuffix(name, "."+r.zone+".") {
+		rr, _ := dns.NewRR(name + " 60 IN A 192.168.1.1")
+		resp.Answer = append(resp.Answer, rr)
+	}
+	return resp, nil
+}
+
+func (r *wildcardResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func (r *wildcardResolver) numQueries() int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.queries
+}
+
+func TestWildcardCacheInvalidation(t *testing.T) {
+	r := &wildcardResolver{zone: "owasp.org"}
+	wc := newWildcardCache(r, time.Hour, config.NewStdLogger(nil))
+
+	ctx := context.Background()
+	msg, _ := r.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	if wc.WildcardType(ctx, msg, "owasp.org") != resolve.WildcardTypeStatic {
+		t.Fatalf("The static DNS wildcard was not detected")
+	}
+
+	num := r.numQueries()
+	wc.WildcardType(ctx, msg, "owasp.org")
+	if r.numQueries() != num {
+		t.Errorf("The cached wildcard verdict was not used")
+	}
+
+	wc.(WildcardInvalidator).InvalidateWildcard("owasp.org")
+	wc.WildcardType(ctx, msg, "owasp.org")
+	if r.numQueries() == num {
+		t.Errorf("The zone was not tested again after being invalidated")
+	}
+}
+
+func TestWildcardCacheExpiry(t *testing.T) {
+	r := &wildcardResolver{zone: "owasp.org"}
+	wc := newWildcardCache(r, time.Hour, config.NewStdLogger(nil))
+
+	ctx := context.Background()
+	msg, _ := r.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	wc.WildcardType(ctx, msg, "owasp.org")
+
+	wd := wc.(*wildcardCache).detector
+	wd.Lock()
+	for _, w := range wd.wildcards {
+		w.Expires = time.Now().Add(-time.Minute)
+	}
+	wd.Unlock()
+
+	num := r.numQueries()
+	wc.WildcardType(ctx, msg, "owasp.org")
+	if r.numQueries() == num {
+		t.Errorf("The expired wildcard verdict was not tested again")
+	}
+}
+
+// dynamicResolver answers the AAAA queries within the zone with a new address each time,
+// and the A queries only for the names that exist.
+type dynamicResolver struct {
+	sync.Mutex
+	zone  string
+	names []string
+	next  int
+}
+
+func (r *dynamicResolver) String() string { return "dynamic" }
+func (r *dynamicResolver) Len() int       { return 0 }
+func (r *dynamicResolver) Stop()          {}
+func (r *dynamicResolver) Stopped() bool  { return false }
+
+func (r *dynamicResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	resp := msg.Copy()
+	resp.Response = true
+
+	name := msg.Question[0].Name
+	if !strings.HasSuffix(name, "."+r.zone+".") {
+		return resp, nil
+	}
+
+	switch msg.Question[0].Qtype {
+	case dns.TypeAAAA:
+		r.Lock()
+		r.next++
+		rr, _ := dns.NewRR(fmt.Sprintf("%s 60 IN AAAA 2001:db8::%x", name, r.next))
+		r.Unlock()
+		resp.Answer = append(resp.Answer, rr)
+	case dns.TypeA:
+		for _, n := range r.names {
+			if name == n+"." {
+				rr, _ := dns.NewRR(name + " 60 IN A 192.168.1.1")
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (r *dynamicResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return resolve.WildcardTypeNone
+}
+
+func TestWildcardRecordTypes(t *testing.T) {
+	ctx := context.Background()
+
+	// The zone only has a wildcard for the IPv4 addresses
+	ipv4 := &wildcardResolver{zone: "owasp.org"}
+	wc := newWildcardCache(ipv4, 0, config.NewStdLogger(nil))
+
+	a, _ := ipv4.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+	if wc.WildcardType(ctx, a, "owasp.org") != resolve.WildcardTypeStatic {
+		t.Errorf("The static DNS wildcard for the A records was not detected")
+	}
+
+	aaaa := resolve.QueryMsg("www.owasp.org", dns.TypeAAAA)
+	aaaa.Response = true
+	rr, _ := dns.NewRR("www.owasp.org. 60 IN AAAA 2001:db8::1")
+	aaaa.Answer = append(aaaa.Answer, rr)
+	if wc.WildcardType(ctx, aaaa, "owasp.org") != resolve.WildcardTypeNone {
+		t.Errorf("The AAAA records were matched by the DNS wildcard for the A records")
+	}
+
+	// The zone only has a wildcard for the IPv6 addresses
+	ipv6 := &dynamicResolver{zone: "owasp.org", names: []string{"www.owasp.org"}}
+	wc = newWildcardCache(ipv6, 0, config.NewStdLogger(nil))
+
+	if a, _ = ipv6.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil); len(a.Answer) == 0 {
+		t.Fatalf("The A record of the name was not provided")
+	}
+	if wc.WildcardType(ctx, a, "owasp.org") != resolve.WildcardTypeNone {
+		t.Errorf("The A records were matched by the DNS wildcard for the AAAA records")
+	}
+
+	aaaa, _ = ipv6.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeAAAA), resolve.PriorityNormal, nil)
+	if wc.WildcardType(ctx, aaaa, "owasp.org") != resolve.WildcardTypeDynamic {
+		t.Errorf("The dynamic DNS wildcard for the AAAA records was not detected")
+	}
+
+	wd := wc.(*wildcardCache).detector
+	wd.Lock()
+	defer wd.Unlock()
+	if w := wd.wildcards[wildcardKey{sub: "owasp.org", qtype: dns.TypeA}]; w == nil || w.WildcardType != resolve.WildcardTypeNone {
+		t.Errorf("The verdict for the A records of the zone was not kept separately")
+	}
+	if w := wd.wildcards[wildcardKey{sub: "owasp.org", qtype: dns.TypeAAAA}]; w == nil || w.WildcardType != resolve.WildcardTypeDynamic {
+		t.Errorf("The verdict for the AAAA records of the zone was not kept separately")
+	}
+}
+
+// blockingResolver holds the queries of the wildcard test until it is released.
+type blockingResolver struct {
+	wildcardResolver
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (r *blockingResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolve.Retry) (*dns.Msg, error) {
+	r.once.Do(func() { close(r.started) })
+	<-r.release
+	return r.wildcardResolver.Query(ctx, msg, priority, retry)
+}
+
+func TestWildcardInvalidatedDuringTest(t *testing.T) {
+	r := &blockingResolver{
+		wildcardResolver: wildcardResolver{zone: "owasp.org"},
+		started:          make(chan struct{}),
+		release:          make(chan struct{}),
+	}
+	wd := newWildcardDetector(r, time.Hour, config.NewStdLogger(nil))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wd.fetchWildcard(context.Background(), wildcardKey{sub: "owasp.org", qtype: dns.TypeA})
+	}()
+
+	// The zone is invalidated while the test is querying the resolver
+	<-r.started
+	wd.InvalidateWildcard("owasp.org")
+	close(r.release)
+	<-done
+
+	wd.Lock()
+	defer wd.Unlock()
+	if w, found := wd.wildcards[wildcardKey{sub: "owasp.org", qtype: dns.TypeA}]; found {
+		t.Errorf("The verdict of the test started before the invalidation was stored: %v", w)
+	}
+}
+
+func TestWildcardInvalidationConcurrent(t *testing.T) {
+	r := &wildcardResolver{zone: "owasp.org"}
+	wc := newWildcardCache(r, time.Hour, config.NewStdLogger(nil))
+
+	ctx := context.Background()
+	msg, _ := r.Query(ctx, resolve.QueryMsg("www.owasp.org", dns.TypeA), resolve.PriorityNormal, nil)
+
+	// The verdict held by a caller is not modified when the zone is invalidated
+	wd := wc.(*wildcardCache).detector
+	w := wd.fetchWildcard(ctx, wildcardKey{sub: "owasp.org", qtype: dns.TypeA})
+	wd.InvalidateWildcard("owasp.org")
+	if _, found := w.Answers["192.168.1.1"]; !found {
+		t.Errorf("The answers of the verdict held were removed by the invalidation")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				if wc.WildcardType(ctx, msg, "owasp.org") != resolve.WildcardTypeStatic {
+					t.Error("The static DNS wildcard was not detected")
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		wc.(WildcardInvalidator).InvalidateWildcard("owasp.org")
+	}
+	wg.Wait()
+}
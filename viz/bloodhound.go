@@ -0,0 +1,157 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package viz
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// The source kind identifying the nodes and edges ingested from the Amass graph
+const bloodHoundSourceKind = "Amass"
+
+// The node kinds assigned to each Amass node type. The name servers, mail servers and
+// PTR names are also subdomains, so they receive both kinds.
+var bloodHoundNodeKinds = map[string][]string{
+	"domain":    {"AmassDomain"},
+	"subdomain": {"AmassSubdomain"},
+	"ns":        {"AmassSubdomain", "AmassNameServer"},
+	"mx":        {"AmassSubdomain", "AmassMailServer"},
+	"ptr":       {"AmassSubdomain", "AmassPTR"},
+	"address":   {"AmassAddress"},
+	"netblock":  {"AmassNetblock"},
+	"as":        {"AmassASN"},
+}
+
+// The edge kinds assigned to each Amass relationship
+var bloodHoundEdgeKinds = map[string]string{
+	"root":         "AmassSubdomainOf",
+	"cname_record": "AmassCNAMERecord",
+	"a_record":     "AmassARecord",
+	"aaaa_record":  "AmassAAAARecord",
+	"ptr_record":   "AmassPTRRecord",
+	"service":      "AmassService",
+	"srv_record":   "AmassSRVRecord",
+	"ns_record":    "AmassNSRecord",
+	"mx_record":    "AmassMXRecord",
+	"contains":     "AmassContains",
+	"prefix":       "AmassAnnounces",
+}
+
+type bloodHoundNode struct {
+	ID         string                 `json:"id"`
+	Kinds      []string               `json:"kinds"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type bloodHoundEndpoint struct {
+	Value   string `json:"value"`
+	MatchBy string `json:"match_by"`
+}
+
+type bloodHoundEdge struct {
+	Start      bloodHoundEndpoint     `json:"start"`
+	End        bloodHoundEndpoint     `json:"end"`
+	Kind       string                 `json:"kind"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type bloodHoundGraph struct {
+	Graph struct {
+		Nodes []bloodHoundNode `json:"nodes"`
+		Edges []bloodHoundEdge `json:"edges"`
+	} `json:"graph"`
+	Metadata struct {
+		SourceKind string `json:"source_kind"`
+	} `json:"metadata"`
+}
+
+// WriteBloodHoundData generates a JSON file of the Amass graph in the OpenGraph ingestion schema of BloodHound.
+// Each node is identified by the name, address, netblock or AS number, so the ingested nodes can be correlated
+// with the assets of the other graph datasets.
+func WriteBloodHoundData(output io.Writer, nodes []Node, edges []Edge) error {
+	bh := new(bloodHoundGraph)
+	bh.Metadata.SourceKind = bloodHoundSourceKind
+	bh.Graph.Nodes = []bloodHoundNode{}
+	bh.Graph.Edges = []bloodHoundEdge{}
+
+	ids := make(map[int]string, len(nodes))
+	for _, n := range nodes {
+		kinds, found := bloodHoundNodeKinds[n.Type]
+		if !found {
+			continue
+		}
+
+		id := bloodHoundNodeID(n)
+		ids[n.ID] = id
+		bh.Graph.Nodes = append(bh.Graph.Nodes, bloodHoundNode{
+			ID:         id,
+			Kinds:      append([]string(nil), kinds...),
+			Properties: bloodHoundProperties(n),
+		})
+	}
+
+	for _, e := range edges {
+		from, ok1 := ids[e.From]
+		to, ok2 := ids[e.To]
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		kind, found := bloodHoundEdgeKinds[e.Title]
+		if !found {
+			kind = bloodHoundSourceKind + pascalCase(e.Title)
+		}
+		bh.Graph.Edges = append(bh.Graph.Edges, bloodHoundEdge{
+			Start: bloodHoundEndpoint{Value: from, MatchBy: "id"},
+			End:   bloodHoundEndpoint{Value: to, MatchBy: "id"},
+			Kind:  kind,
+		})
+	}
+
+	enc := json.NewEncoder(output)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bh)
+}
+
+func bloodHoundNodeID(n Node) string {
+	if n.Type == "as" {
+		return "AS" + n.Label
+	}
+	return strings.ToLower(n.Label)
+}
+
+// bloodHoundProperties returns the properties of the node, which are limited to the primitive
+// values and arrays of primitive values accepted by the ingestion schema.
+func bloodHoundProperties(n Node) map[string]interface{} {
+	props := map[string]interface{}{
+		"name":   n.Label,
+		"type":   n.Type,
+		"source": n.Source,
+	}
+
+	if n.FirstSeen != "" {
+		props["first_seen"] = n.FirstSeen
+	}
+	if n.LastSeen != "" {
+		props["last_seen"] = n.LastSeen
+	}
+	if len(n.Ports) > 0 {
+		props["ports"] = n.Ports
+	}
+	if len(n.Technologies) > 0 {
+		props["technologies"] = n.Technologies
+	}
+	return props
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package viz
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validateOpenGraph checks the document against the OpenGraph ingestion schema, and returns the problems found.
+func validateOpenGraph(doc map[string]interface{}) []string {
+	var problems []string
+
+	graph, ok := doc["graph"].(map[string]interface{})
+	if !ok {
+		return []string{"the graph object is missing"}
+	}
+	if meta, ok := doc["metadata"].(map[string]interface{}); !ok || meta["source_kind"] == "" {
+		problems = append(problems, "the metadata does not provide the source kind")
+	}
+
+	nodes, ok1 := graph["nodes"].([]interface{})
+	edges, ok2 := graph["edges"].([]interface{})
+	if !ok1 || !ok2 {
+		return append(problems, "the graph does not provide the nodes and edges arrays")
+	}
+
+	ids := make(map[string]bool)
+	for _, v := range nodes {
+		n, _ := v.(map[string]interface{})
+		id, _ := n["id"].(string)
+		if id == "" || ids[id] {
+			problems = append(problems, "a node has a missing or duplicate id")
+		}
+		ids[id] = true
+
+		if kinds, ok := n["kinds"].([]interface{}); !ok || len(kinds) == 0 {
+			problems = append(problems, "the node "+id+" has no kinds")
+		}
+		props, ok := n["properties"].(map[string]interface{})
+		if !ok {
+			problems = append(problems, "the node "+id+" has no properties")
+		}
+		for key, val := range props {
+			switch p := val.(type) {
+			case string, float64, bool:
+			case []interface{}:
+				for _, elem := range p {
+					switch elem.(type) {
+					case string, float64, bool:
+					default:
+						problems = append(problems, "the property "+key+" contains a nested value")
+					}
+				}
+			default:
+				problems = append(problems, "the property "+key+" is not a primitive value")
+			}
+		}
+	}
+
+	for _, v := range edges {
+		e, _ := v.(map[string]interface{})
+		if kind, _ := e["kind"].(string); kind == "" {
+			problems = append(problems, "an edge has no kind")
+		}
+		for _, end := range []string{"start", "end"} {
+			ep, _ := e[end].(map[string]interface{})
+			if ep["match_by"] != "id" || !ids[ep["value"].(string)] {
+				problems = append(problems, "an edge does not reference a node by the id")
+			}
+		}
+	}
+	return problems
+}
+
+func TestWriteBloodHoundData(t *testing.T) {
+	nodes := append(testNodes(), Node{
+		ID:         2,
+		Type:       "as",
+		Label:      "16509",
+		Title:      "as: 16509, Desc: AMAZON-02",
+		Source:     "RADb",
+		ActualType: "as",
+	})
+	nodes[1].Ports = []int{80, 443}
+	nodes[0].Technologies = []string{"Apache"}
+	edges := append(testEdges(), Edge{From: 2, To: 1, Title: "prefix"}, Edge{From: 0, To: 5, Title: "a_record"})
+
+	buf := bytes.NewBufferString("")
+	err := WriteBloodHoundData(buf, nodes, edges)
+	assert.Nil(t, err)
+
+	var doc map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Empty(t, validateOpenGraph(doc), "The output should conform to the ingestion schema")
+
+	var bh bloodHoundGraph
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &bh))
+	assert.Equal(t, "Amass", bh.Metadata.SourceKind)
+	assert.Len(t, bh.Graph.Nodes, 3)
+	assert.Equal(t, "owasp.org", bh.Graph.Nodes[0].ID)
+	assert.Equal(t, []string{"AmassDomain"}, bh.Graph.Nodes[0].Kinds)
+	assert.Equal(t, []interface{}{"Apache"}, bh.Graph.Nodes[0].Properties["technologies"])
+	assert.Equal(t, []string{"AmassAddress"}, bh.Graph.Nodes[1].Kinds)
+	assert.Equal(t, []interface{}{float64(80), float64(443)}, bh.Graph.Nodes[1].Properties["ports"])
+	assert.Equal(t, "AS16509", bh.Graph.Nodes[2].ID)
+
+	// The edge referencing a missing node is not written
+	assert.Len(t, bh.Graph.Edges, 2)
+	assert.Equal(t, bloodHoundEdge{
+		Start: bloodHoundEndpoint{Value: "owasp.org", MatchBy: "id"},
+		End:   bloodHoundEndpoint{Value: "205.251.199.98", MatchBy: "id"},
+		Kind:  "AmassARecord",
+	}, bh.Graph.Edges[0])
+	assert.Equal(t, "AmassAnnounces", bh.Graph.Edges[1].Kind)
+}
+
+func TestWriteBloodHoundDataEmpty(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	assert.Nil(t, WriteBloodHoundData(buf, nil, nil))
+
+	var doc map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Empty(t, validateOpenGraph(doc), "The empty graph should conform to the ingestion schema")
+}
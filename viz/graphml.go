@@ -0,0 +1,136 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package viz
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	graphmlNS       string = "http://graphml.graphdrawing.org/xmlns"
+	graphmlXSI      string = "http://www.w3.org/2001/XMLSchema-instance"
+	graphmlLocation string = graphmlNS + " " + graphmlNS + "/1.0/graphml.xsd"
+)
+
+type graphmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphml struct {
+	XMLName        xml.Name
+	XSI            string       `xml:"xmlns:xsi,attr,omitempty"`
+	SchemaLocation string       `xml:"xsi:schemaLocation,attr,omitempty"`
+	Keys           []graphmlKey `xml:"key"`
+	Graph          graphmlGraph `xml:"graph"`
+}
+
+// WriteGraphMLData generates a GraphML file to display the Amass graph using tools such as yEd and Cytoscape.
+// The seen times, open ports and technologies of the nodes are provided as keyed attributes when known,
+// and the lists are separated by commas, since GraphML attributes cannot hold multiple values.
+func WriteGraphMLData(output io.Writer, nodes []Node, edges []Edge) error {
+	bufwr := bufio.NewWriter(output)
+
+	if _, err := bufwr.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"); err != nil {
+		return err
+	}
+
+	doc := &graphml{
+		XMLName: xml.Name{
+			Space: graphmlNS,
+			Local: "graphml",
+		},
+		XSI:            graphmlXSI,
+		SchemaLocation: graphmlLocation,
+		Keys: []graphmlKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "title", For: "node", AttrName: "title", AttrType: "string"},
+			{ID: "source", For: "node", AttrName: "source", AttrType: "string"},
+			{ID: "type", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "first_seen", For: "node", AttrName: "first_seen", AttrType: "string"},
+			{ID: "last_seen", For: "node", AttrName: "last_seen", AttrType: "string"},
+			{ID: "ports", For: "node", AttrName: "ports", AttrType: "string"},
+			{ID: "tech", For: "node", AttrName: "tech", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+		},
+		Graph: graphmlGraph{
+			ID:          "OWASP Amass Network Mapping",
+			EdgeDefault: edgeTypeDirected,
+		},
+	}
+
+	for idx, n := range nodes {
+		data := []graphmlData{
+			{Key: "label", Value: n.Label},
+			{Key: "title", Value: n.Title},
+			{Key: "source", Value: n.Source},
+			{Key: "type", Value: n.Type},
+		}
+		if n.FirstSeen != "" {
+			data = append(data, graphmlData{Key: "first_seen", Value: n.FirstSeen})
+		}
+		if n.LastSeen != "" {
+			data = append(data, graphmlData{Key: "last_seen", Value: n.LastSeen})
+		}
+		if len(n.Ports) > 0 {
+			var ports []string
+			for _, port := range n.Ports {
+				ports = append(ports, strconv.Itoa(port))
+			}
+			data = append(data, graphmlData{Key: "ports", Value: strings.Join(ports, ",")})
+		}
+		if len(n.Technologies) > 0 {
+			data = append(data, graphmlData{Key: "tech", Value: strings.Join(n.Technologies, ",")})
+		}
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{
+			ID:   "n" + strconv.Itoa(idx),
+			Data: data,
+		})
+	}
+
+	for idx, e := range edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			ID:     "e" + strconv.Itoa(idx),
+			Source: "n" + strconv.Itoa(e.From),
+			Target: "n" + strconv.Itoa(e.To),
+			Data:   []graphmlData{{Key: "relation", Value: e.Title}},
+		})
+	}
+
+	enc := xml.NewEncoder(bufwr)
+	enc.Indent("", "  ")
+	defer bufwr.Flush()
+	return enc.Encode(doc)
+}
@@ -0,0 +1,77 @@
+package viz
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGraphMLDataHappyPath(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	err := WriteGraphMLData(buf, testNodes(), testEdges())
+	assert.Nil(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, expectedGraphMLOutput, "GraphML output should contain")
+}
+
+func TestWriteGraphMLDataMetadata(t *testing.T) {
+	nodes := testNodes()
+	nodes[0].FirstSeen = "2021-06-01T10:00:00Z"
+	nodes[0].LastSeen = "2021-06-02T10:00:00Z"
+	nodes[0].Technologies = []string{"Apache", "WordPress 5.8"}
+	nodes[1].Ports = []int{80, 443}
+
+	buf := bytes.NewBufferString("")
+	err := WriteGraphMLData(buf, nodes, testEdges())
+	assert.Nil(t, err)
+
+	var doc graphml
+	err = xml.Unmarshal(buf.Bytes(), &doc)
+	assert.Nil(t, err, "GraphML output should be valid XML")
+
+	keys := make(map[string]string)
+	for _, k := range doc.Keys {
+		keys[k.ID] = k.For
+	}
+
+	values := make(map[string]map[string]string)
+	for _, n := range doc.Graph.Nodes {
+		values[n.ID] = make(map[string]string)
+		for _, d := range n.Data {
+			assert.Equal(t, "node", keys[d.Key], "data key %s should be declared for the nodes", d.Key)
+			values[n.ID][d.Key] = d.Value
+		}
+	}
+	for _, e := range doc.Graph.Edges {
+		for _, d := range e.Data {
+			assert.Equal(t, "edge", keys[d.Key], "data key %s should be declared for the edges", d.Key)
+		}
+	}
+
+	assert.Equal(t, "2021-06-01T10:00:00Z", values["n0"]["first_seen"])
+	assert.Equal(t, "2021-06-02T10:00:00Z", values["n0"]["last_seen"])
+	assert.Equal(t, "Apache,WordPress 5.8", values["n0"]["tech"])
+	assert.Equal(t, "80,443", values["n1"]["ports"])
+	assert.NotContains(t, values["n1"], "tech", "nodes without technologies should not have the attribute")
+}
+
+const expectedGraphMLOutput = `<graph id="OWASP Amass Network Mapping" edgedefault="directed">
+    <node id="n0">
+      <data key="label">owasp.org</data>
+      <data key="title">domain: owasp.org</data>
+      <data key="source">DNS</data>
+      <data key="type">domain</data>
+    </node>
+    <node id="n1">
+      <data key="label">205.251.199.98</data>
+      <data key="title">address: 205.251.199.98</data>
+      <data key="source">DNS</data>
+      <data key="type">address</data>
+    </node>
+    <edge id="e0" source="n0" target="n1">
+      <data key="relation">a_record</data>
+    </edge>
+  </graph>`
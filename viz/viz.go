@@ -5,6 +5,8 @@ package viz
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/caffix/netmap"
@@ -27,6 +29,12 @@ type Node struct {
 	Title      string
 	Source     string
 	ActualType string
+	// The RFC3339 times when the asset was first and last observed across the enumerations
+	FirstSeen string
+	LastSeen  string
+	// The open ports found on an address, and the technologies detected on the web server of a name
+	Ports        []int
+	Technologies []string
 }
 
 // VizData returns the current state of the Graph as viz package Nodes and Edges.
@@ -77,6 +85,7 @@ func VizData(ctx context.Context, g *netmap.Graph, uuids []string) ([]Node, []Ed
 			Source:     src,
 			ActualType: ntype,
 		}
+		setNodeMetadata(&n, qs)
 
 		n.ID = idx
 		// Keep track of which indices nodes were assigned to
@@ -103,6 +112,51 @@ func getType(quads []quad.Quad) string {
 	return t
 }
 
+// setNodeMetadata assigns the seen times, open ports and technologies stored as properties of the node.
+func setNodeMetadata(n *Node, quads []quad.Quad) {
+	techs := stringset.New()
+	defer techs.Close()
+
+	for _, q := range quads {
+		obj := valToStr(q.Get(quad.Object))
+		if obj == "" {
+			continue
+		}
+
+		switch valToStr(q.Get(quad.Predicate)) {
+		case "first_seen":
+			if n.FirstSeen == "" || obj < n.FirstSeen {
+				n.FirstSeen = obj
+			}
+		case "last_seen":
+			if obj > n.LastSeen {
+				n.LastSeen = obj
+			}
+		case "port":
+			if port, err := strconv.Atoi(obj); err == nil && !containsPort(n.Ports, port) {
+				n.Ports = append(n.Ports, port)
+			}
+		case "tech":
+			techs.Insert(obj)
+		}
+	}
+
+	sort.Ints(n.Ports)
+	if techs.Len() > 0 {
+		n.Technologies = techs.Slice()
+		sort.Strings(n.Technologies)
+	}
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
 func getASDesc(quads []quad.Quad) string {
 	var desc string
 